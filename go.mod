@@ -13,6 +13,8 @@ require (
 	github.com/stretchr/testify v1.11.1
 	golang.org/x/mod v0.33.0
 	golang.org/x/sync v0.19.0
+	golang.org/x/term v0.3.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -28,6 +30,4 @@ require (
 	github.com/mtibben/percent v0.2.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	golang.org/x/sys v0.38.0 // indirect
-	golang.org/x/term v0.3.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -149,7 +149,7 @@ func (c *Client) ExtractConversationAttachments(ctx context.Context, conversatio
 			downloadLimit = remainingTotal
 		}
 
-		download, err := c.downloadAttachmentToTemp(ctx, candidate.Attachment.DataURL, downloadLimit)
+		download, err := c.downloadAttachmentToTemp(ctx, candidate.Attachment.DataURL, "", downloadLimit)
 		if err != nil {
 			return nil, fmt.Errorf("attachment %d: %w", candidate.Index, err)
 		}
@@ -277,7 +277,9 @@ func AttachmentDisplayName(att Attachment) string {
 	return displayNameFromURL(att.DataURL)
 }
 
-func (c *Client) downloadAttachmentToTemp(ctx context.Context, rawURL string, maxBytes int64) (*attachmentDownload, error) {
+// downloadAttachmentToTemp downloads into a temporary file in tmpDir (or the
+// default temp directory when empty), hashing the content on the way.
+func (c *Client) downloadAttachmentToTemp(ctx context.Context, rawURL, tmpDir string, maxBytes int64) (*attachmentDownload, error) {
 	if !c.skipURLValidation {
 		if err := validation.ValidateChatwootURL(rawURL); err != nil {
 			return nil, err
@@ -303,7 +305,7 @@ func (c *Client) downloadAttachmentToTemp(ctx context.Context, rawURL string, ma
 		return nil, fmt.Errorf("attachment too large: %d bytes exceeds %d", resp.ContentLength, maxBytes)
 	}
 
-	tmp, err := os.CreateTemp("", "chatwoot-attachment-*")
+	tmp, err := os.CreateTemp(tmpDir, "chatwoot-attachment-*")
 	if err != nil {
 		return nil, err
 	}
@@ -420,82 +422,38 @@ func attachmentMIMEType(contentType, name string, head []byte) string {
 		return "text/html"
 	case ".docx":
 		return "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+	case ".odt":
+		return "application/vnd.oasis.opendocument.text"
+	case ".ods":
+		return "application/vnd.oasis.opendocument.spreadsheet"
+	case ".rtf":
+		return "application/rtf"
+	case ".eml":
+		return "message/rfc822"
 	default:
 		return "application/octet-stream"
 	}
 }
 
+// extractAttachmentText reads a downloaded attachment and extracts its text
+// through the document extractor registry.
 func extractAttachmentText(ctx context.Context, filePath, name, mimeType string) (string, string, error) {
-	switch normalizedDocumentType(name, mimeType) {
-	case "pdf":
-		text, err := runPDFToText(ctx, filePath)
-		if err != nil {
-			return "", "", err
-		}
-		return normalizeExtractedText(text), "pdftotext", nil
-	case "docx":
-		text, err := extractDOCXText(filePath)
-		if err != nil {
-			return "", "", err
-		}
-		return normalizeExtractedText(text), "docx-xml", nil
-	case "xlsx":
-		text, err := extractXLSXText(filePath)
-		if err != nil {
-			return "", "", err
-		}
-		return normalizeExtractedText(text), "xlsx-xml", nil
-	case "text":
-		text, err := extractPlainText(filePath)
-		if err != nil {
-			return "", "", err
-		}
-		return normalizeExtractedText(text), "text", nil
-	default:
-		return "", "", fmt.Errorf("unsupported document type %q", mimeType)
-	}
-}
-
-func normalizedDocumentType(name, mimeType string) string {
-	switch strings.ToLower(strings.TrimSpace(mimeType)) {
-	case "application/pdf":
-		return "pdf"
-	case "application/vnd.openxmlformats-officedocument.wordprocessingml.document":
-		return "docx"
-	case "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":
-		return "xlsx"
-	case "text/plain", "text/markdown", "application/json", "text/csv", "application/xml", "text/xml", "text/html":
-		return "text"
-	}
-
-	switch strings.ToLower(filepath.Ext(name)) {
-	case ".pdf":
-		return "pdf"
-	case ".docx":
-		return "docx"
-	case ".xlsx":
-		return "xlsx"
-	case ".txt", ".md", ".markdown", ".json", ".csv", ".xml", ".html", ".htm":
-		return "text"
-	default:
-		return ""
-	}
-}
-
-func extractPlainText(filePath string) (string, error) {
 	data, err := os.ReadFile(filePath)
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
+	return extractDocumentText(ctx, data, name, mimeType)
+}
+
+func extractPlainText(_ context.Context, data []byte) (string, error) {
 	return string(bytes.ToValidUTF8(data, []byte(" "))), nil
 }
 
-func extractDOCXText(filePath string) (string, error) {
-	reader, err := zip.OpenReader(filePath)
+func extractDOCXText(_ context.Context, data []byte) (string, error) {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
 	if err != nil {
 		return "", err
 	}
-	defer func() { _ = reader.Close() }()
 
 	parts := make([]string, 0, len(reader.File))
 	for _, file := range reader.File {
@@ -538,12 +496,11 @@ func extractDOCXText(filePath string) (string, error) {
 	return strings.Join(sections, "\n\n"), nil
 }
 
-func extractXLSXText(filePath string) (string, error) {
-	reader, err := zip.OpenReader(filePath)
+func extractXLSXText(_ context.Context, data []byte) (string, error) {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
 	if err != nil {
 		return "", err
 	}
-	defer func() { _ = reader.Close() }()
 
 	files := make(map[string]*zip.File, len(reader.File))
 	sheets := make([]string, 0, len(reader.File))
@@ -0,0 +1,248 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+)
+
+// maxEmailParts bounds the number of MIME parts walked in one message.
+const maxEmailParts = 200
+
+// extractEmailText renders an RFC 822 message as its main headers, body and
+// the text of its attachments. Attachments are run through the extractor
+// registry, so a PDF forwarded inside an .eml is extracted as well.
+func extractEmailText(ctx context.Context, data []byte) (string, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	dec := new(mime.WordDecoder)
+	for _, key := range []string{"From", "To", "Cc", "Date", "Subject"} {
+		value := msg.Header.Get(key)
+		if value == "" {
+			continue
+		}
+		if decoded, err := dec.DecodeHeader(value); err == nil {
+			value = decoded
+		}
+		fmt.Fprintf(&b, "%s: %s\n", key, value)
+	}
+	b.WriteString("\n")
+
+	w := &emailWalker{ctx: ctx, out: &b}
+	if err := w.part(textproto.MIMEHeader(msg.Header), msg.Body); err != nil {
+		return "", err
+	}
+	for _, att := range w.attachments {
+		b.WriteString("\n\n")
+		b.WriteString(att)
+	}
+	return b.String(), nil
+}
+
+type emailWalker struct {
+	ctx         context.Context
+	out         *strings.Builder
+	attachments []string
+	parts       int
+}
+
+func (w *emailWalker) part(header textproto.MIMEHeader, body io.Reader) error {
+	if err := w.ctx.Err(); err != nil {
+		return err
+	}
+	w.parts++
+	if w.parts > maxEmailParts {
+		return nil
+	}
+
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		mediaType, params = "text/plain", map[string]string{}
+	}
+	body = decodeTransferEncoding(header.Get("Content-Transfer-Encoding"), body)
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		return w.multipart(mediaType, params["boundary"], body)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(body, maxZIPEntrySize))
+	if err != nil {
+		return err
+	}
+	if name := emailPartFilename(header, params); name != "" || isAttachmentDisposition(header) {
+		w.attachment(name, mediaType, data)
+		return nil
+	}
+
+	switch mediaType {
+	case "text/plain":
+		w.out.WriteString(decodeEmailCharset(data, params["charset"]))
+		w.out.WriteString("\n")
+	case "text/html":
+		text, _ := extractHTMLText(w.ctx, []byte(decodeEmailCharset(data, params["charset"])))
+		w.out.WriteString(text)
+		w.out.WriteString("\n")
+	default:
+		w.attachment("", mediaType, data)
+	}
+	return nil
+}
+
+// multipart walks each part; for multipart/alternative only the plain text
+// version (or else the HTML one) is rendered.
+func (w *emailWalker) multipart(mediaType, boundary string, body io.Reader) error {
+	if boundary == "" {
+		return nil
+	}
+	reader := multipart.NewReader(body, boundary)
+	type alternative struct {
+		header textproto.MIMEHeader
+		data   []byte
+	}
+	var alternatives []alternative
+
+	for {
+		p, err := reader.NextRawPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// Truncated or malformed multipart: keep what was read.
+			break
+		}
+		if mediaType != "multipart/alternative" {
+			if err := w.part(p.Header, p); err != nil {
+				return err
+			}
+			continue
+		}
+		data, err := io.ReadAll(io.LimitReader(p, maxZIPEntrySize))
+		if err != nil {
+			return err
+		}
+		alternatives = append(alternatives, alternative{p.Header, data})
+	}
+
+	if len(alternatives) == 0 {
+		return nil
+	}
+	chosen := alternatives[0]
+	for _, preferred := range []string{"text/plain", "text/html", "multipart/"} {
+		found := false
+		for _, alt := range alternatives {
+			if mt, _, _ := mime.ParseMediaType(alt.header.Get("Content-Type")); strings.HasPrefix(mt, preferred) {
+				chosen, found = alt, true
+				break
+			}
+		}
+		if found {
+			break
+		}
+	}
+	return w.part(chosen.header, bytes.NewReader(chosen.data))
+}
+
+func (w *emailWalker) attachment(name, mediaType string, data []byte) {
+	label := name
+	if label == "" {
+		label = mediaType
+	}
+	ctx, ok := nestedExtractContext(w.ctx)
+	if !ok {
+		w.attachments = append(w.attachments, fmt.Sprintf("[Attachment: %s (nested too deeply, not extracted)]", label))
+		return
+	}
+	text, _, err := extractDocumentText(ctx, data, name, mediaType)
+	switch {
+	case err != nil:
+		w.attachments = append(w.attachments, fmt.Sprintf("[Attachment: %s (not extracted)]", label))
+	case text == "":
+		w.attachments = append(w.attachments, fmt.Sprintf("[Attachment: %s (no text)]", label))
+	default:
+		w.attachments = append(w.attachments, fmt.Sprintf("[Attachment: %s]\n%s", label, text))
+	}
+}
+
+func emailPartFilename(header textproto.MIMEHeader, contentTypeParams map[string]string) string {
+	name := ""
+	if _, params, err := mime.ParseMediaType(header.Get("Content-Disposition")); err == nil {
+		name = params["filename"]
+	}
+	if name == "" {
+		name = contentTypeParams["name"]
+	}
+	if decoded, err := new(mime.WordDecoder).DecodeHeader(name); err == nil {
+		name = decoded
+	}
+	return name
+}
+
+func isAttachmentDisposition(header textproto.MIMEHeader) bool {
+	disposition, _, _ := mime.ParseMediaType(header.Get("Content-Disposition"))
+	return disposition == "attachment"
+}
+
+func decodeTransferEncoding(encoding string, body io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, &base64Stripper{r: body})
+	case "quoted-printable":
+		return quotedprintable.NewReader(body)
+	default:
+		return body
+	}
+}
+
+// base64Stripper drops line breaks and other whitespace that
+// base64.NewDecoder rejects beyond \r and \n.
+type base64Stripper struct {
+	r io.Reader
+}
+
+func (s *base64Stripper) Read(p []byte) (int, error) {
+	for {
+		n, err := s.r.Read(p)
+		kept := 0
+		for _, c := range p[:n] {
+			if c != ' ' && c != '\t' && c != '\r' && c != '\n' {
+				p[kept] = c
+				kept++
+			}
+		}
+		if kept > 0 || err != nil {
+			return kept, err
+		}
+	}
+}
+
+// decodeEmailCharset converts a text part to UTF-8. Latin-1 and Windows-1252
+// are converted; other charsets are assumed to be UTF-8 compatible.
+func decodeEmailCharset(data []byte, charset string) string {
+	switch strings.ToLower(strings.TrimSpace(charset)) {
+	case "iso-8859-1", "latin1", "windows-1252", "cp1252", "us-ascii":
+		var b strings.Builder
+		for _, c := range data {
+			if r := decodeCP1252Byte(c); r != 0 || c == '\r' {
+				if r == 0 {
+					r = '\r'
+				}
+				b.WriteRune(r)
+			}
+		}
+		return b.String()
+	default:
+		return strings.ToValidUTF8(string(data), "�")
+	}
+}
@@ -0,0 +1,146 @@
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func makeTestEmail(t *testing.T) []byte {
+	t.Helper()
+
+	docx := base64.StdEncoding.EncodeToString(makeTestDOCX(t, "Contract terms"))
+	forwarded := strings.Join([]string{
+		"From: Supplier <billing@supplier.example>",
+		"Subject: Invoice 42",
+		"Content-Type: multipart/mixed; boundary=inner",
+		"",
+		"--inner",
+		"Content-Type: text/html; charset=utf-8",
+		"",
+		"<p>Please find the <b>invoice</b> attached.</p>",
+		"--inner",
+		"Content-Type: application/vnd.openxmlformats-officedocument.wordprocessingml.document; name=terms.docx",
+		"Content-Transfer-Encoding: base64",
+		"Content-Disposition: attachment; filename=terms.docx",
+		"",
+		docx,
+		"--inner--",
+	}, "\r\n")
+
+	return []byte(strings.Join([]string{
+		"From: =?UTF-8?Q?Ren=C3=A9e?= <renee@example.com>",
+		"To: support@example.com",
+		"Subject: Fwd: Invoice 42",
+		"Date: Mon, 2 Mar 2026 10:00:00 +0000",
+		"MIME-Version: 1.0",
+		"Content-Type: multipart/mixed; boundary=outer",
+		"",
+		"--outer",
+		"Content-Type: multipart/alternative; boundary=alt",
+		"",
+		"--alt",
+		"Content-Type: text/plain; charset=iso-8859-1",
+		"Content-Transfer-Encoding: quoted-printable",
+		"",
+		"Can you check this invoice? Merci d=E9j=E0.",
+		"--alt",
+		"Content-Type: text/html",
+		"",
+		"<p>HTML duplicate</p>",
+		"--alt--",
+		"--outer",
+		"Content-Type: message/rfc822",
+		"Content-Disposition: attachment; filename=forwarded.eml",
+		"",
+		forwarded,
+		"--outer",
+		"Content-Type: image/png; name=logo.png",
+		"Content-Transfer-Encoding: base64",
+		"",
+		"iVBORw0KGgo=",
+		"--outer--",
+	}, "\r\n"))
+}
+
+func TestExtractEmailText(t *testing.T) {
+	text, err := extractEmailText(context.Background(), makeTestEmail(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"From: Renée <renee@example.com>",
+		"Subject: Fwd: Invoice 42",
+		"Can you check this invoice? Merci déjà.",
+		"[Attachment: forwarded.eml]",
+		"Subject: Invoice 42",
+		"Please find the invoice attached.",
+		"[Attachment: terms.docx]\nContract terms",
+		"[Attachment: logo.png (not extracted)]",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("missing %q in:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "HTML duplicate") {
+		t.Errorf("multipart/alternative should render one version:\n%s", text)
+	}
+}
+
+func TestExtractEmailTextNestingLimit(t *testing.T) {
+	ctx := context.Background()
+	for range maxNestedDocumentDepth {
+		ctx, _ = nestedExtractContext(ctx)
+	}
+	text, err := extractEmailText(ctx, makeTestEmail(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(text, "[Attachment: forwarded.eml (nested too deeply, not extracted)]") {
+		t.Fatalf("expected nesting limit note:\n%s", text)
+	}
+}
+
+func TestExtractConversationAttachments_Email(t *testing.T) {
+	eml := makeTestEmail(t)
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/accounts/1/conversations/123/attachments":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"payload":[
+				{"id": 6, "file_type": "file", "data_url": "` + server.URL + `/files/forwarded.eml", "file_size": 12}
+			]}`))
+		case "/files/forwarded.eml":
+			w.Header().Set("Content-Type", "application/octet-stream")
+			_, _ = w.Write(eml)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL, "token", 1)
+	result, err := client.ExtractConversationAttachments(context.Background(), 123, ConversationAttachmentExtractOptions{
+		Limit:         1,
+		MaxBytes:      DefaultDocumentExtractMaxBytes,
+		MaxTotalBytes: DefaultDocumentExtractMaxTotalBytes,
+		MaxChars:      DefaultDocumentExtractMaxChars,
+	})
+	if err != nil {
+		t.Fatalf("ExtractConversationAttachments returned error: %v", err)
+	}
+	if len(result.Items) != 1 {
+		t.Fatalf("expected 1 item, got %d", len(result.Items))
+	}
+	if result.Items[0].Extractor != "email" {
+		t.Fatalf("expected email extractor, got %q", result.Items[0].Extractor)
+	}
+	if !strings.Contains(result.Items[0].Text, "Contract terms") {
+		t.Fatalf("unexpected email text %q", result.Items[0].Text)
+	}
+}
@@ -0,0 +1,401 @@
+package api
+
+import (
+	"context"
+	"html"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// htmlSkippedElements hold content that is never shown as text.
+var htmlSkippedElements = map[string]bool{
+	"script": true, "style": true, "head": true, "noscript": true, "template": true, "svg": true, "title": true,
+}
+
+// htmlBlockElements start and end on their own line.
+var htmlBlockElements = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "br": true, "caption": true,
+	"dd": true, "div": true, "dl": true, "dt": true, "fieldset": true, "figcaption": true, "figure": true,
+	"footer": true, "form": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"header": true, "hr": true, "li": true, "main": true, "nav": true, "ol": true, "p": true, "pre": true,
+	"section": true, "table": true, "tr": true, "ul": true,
+}
+
+// extractHTMLText converts HTML to plain text: markup, scripts and styles are
+// dropped, block elements become line breaks, table cells are tab-separated
+// and list items are bulleted.
+func extractHTMLText(_ context.Context, data []byte) (string, error) {
+	src := strings.ToValidUTF8(string(data), "�")
+	var b htmlTextBuilder
+	skip := ""
+	pre := 0
+
+	for i := 0; i < len(src); {
+		if src[i] != '<' {
+			j := strings.IndexByte(src[i:], '<')
+			if j < 0 {
+				j = len(src) - i
+			}
+			if skip == "" {
+				b.text(html.UnescapeString(src[i:i+j]), pre > 0)
+			}
+			i += j
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(src[i:], "<!--"):
+			end := strings.Index(src[i+4:], "-->")
+			if end < 0 {
+				return b.String(), nil
+			}
+			i += 4 + end + 3
+			continue
+		case strings.HasPrefix(src[i:], "<!"), strings.HasPrefix(src[i:], "<?"):
+			end := strings.IndexByte(src[i:], '>')
+			if end < 0 {
+				return b.String(), nil
+			}
+			i += end + 1
+			continue
+		}
+
+		end := htmlTagEnd(src, i)
+		if end < 0 {
+			// A lone "<" is text.
+			if skip == "" {
+				b.text("<", pre > 0)
+			}
+			i++
+			continue
+		}
+		tag := src[i+1 : end]
+		i = end + 1
+		closing := strings.HasPrefix(tag, "/")
+		name := htmlTagName(strings.TrimPrefix(tag, "/"))
+		if name == "" {
+			if skip == "" {
+				b.text("<", pre > 0)
+			}
+			i = i - len(tag) - 1
+			continue
+		}
+
+		if skip != "" {
+			if closing && name == skip {
+				skip = ""
+			}
+			continue
+		}
+		if htmlSkippedElements[name] {
+			if !closing && !strings.HasSuffix(tag, "/") {
+				skip = name
+			}
+			continue
+		}
+
+		switch {
+		case name == "pre":
+			if closing {
+				pre = max(pre-1, 0)
+			} else {
+				pre++
+			}
+			b.newline()
+		case name == "td" || name == "th":
+			if !closing {
+				b.cell()
+			}
+		case name == "li" && !closing:
+			b.newline()
+			b.raw("- ")
+		case name == "br":
+			b.lineBreak()
+		case htmlBlockElements[name]:
+			b.newline()
+		}
+	}
+	return b.String(), nil
+}
+
+// htmlTagEnd finds the ">" closing the tag opened at src[start], honoring
+// quoted attribute values.
+func htmlTagEnd(src string, start int) int {
+	quote := byte(0)
+	for i := start + 1; i < len(src); i++ {
+		c := src[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '>':
+			return i
+		case c == '<' && i == start+1:
+			return -1
+		}
+	}
+	return -1
+}
+
+func htmlTagName(tag string) string {
+	end := 0
+	for end < len(tag) {
+		c := tag[end]
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == ':') {
+			break
+		}
+		end++
+	}
+	if end == 0 || !(tag[0] >= 'a' && tag[0] <= 'z' || tag[0] >= 'A' && tag[0] <= 'Z') {
+		return ""
+	}
+	return strings.ToLower(tag[:end])
+}
+
+// htmlTextBuilder collapses whitespace the way a browser renders it.
+type htmlTextBuilder struct {
+	b        strings.Builder
+	rowCells int
+}
+
+func (h *htmlTextBuilder) String() string {
+	return h.b.String()
+}
+
+func (h *htmlTextBuilder) last() byte {
+	s := h.b.String()
+	if s == "" {
+		return '\n'
+	}
+	return s[len(s)-1]
+}
+
+func (h *htmlTextBuilder) raw(s string) {
+	h.b.WriteString(s)
+}
+
+func (h *htmlTextBuilder) text(s string, pre bool) {
+	if pre {
+		h.b.WriteString(s)
+		return
+	}
+	for _, field := range strings.FieldsFunc(s, isHTMLSpace) {
+		if c := h.last(); c != '\n' && c != ' ' && c != '\t' {
+			h.b.WriteByte(' ')
+		}
+		h.b.WriteString(field)
+	}
+	if s != "" && isHTMLSpace(rune(s[len(s)-1])) && h.b.Len() > 0 {
+		if c := h.last(); c != '\n' && c != ' ' && c != '\t' {
+			h.b.WriteByte(' ')
+		}
+	}
+}
+
+func (h *htmlTextBuilder) trimSpace() {
+	s := strings.TrimRight(h.b.String(), " ")
+	h.b.Reset()
+	h.b.WriteString(s)
+}
+
+func (h *htmlTextBuilder) newline() {
+	h.trimSpace()
+	h.rowCells = 0
+	if h.b.Len() > 0 && h.last() != '\n' {
+		h.b.WriteByte('\n')
+	}
+}
+
+func (h *htmlTextBuilder) lineBreak() {
+	h.trimSpace()
+	h.b.WriteByte('\n')
+}
+
+func (h *htmlTextBuilder) cell() {
+	h.trimSpace()
+	if h.rowCells > 0 {
+		h.b.WriteByte('\t')
+	} else if h.b.Len() > 0 && h.last() != '\n' {
+		h.b.WriteByte('\n')
+	}
+	h.rowCells++
+}
+
+func isHTMLSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '\f'
+}
+
+// rtfSkippedDestinations are groups whose content is not document text.
+var rtfSkippedDestinations = map[string]bool{
+	"fonttbl": true, "colortbl": true, "stylesheet": true, "info": true, "pict": true, "object": true,
+	"fldinst": true, "themedata": true, "colorschememapping": true, "listtable": true,
+	"listoverridetable": true, "rsidtbl": true, "generator": true, "latentstyles": true,
+	"datastore": true, "xmlnstbl": true, "mmathPr": true, "header": true, "footer": true,
+	"headerl": true, "headerr": true, "headerf": true, "footerl": true, "footerr": true, "footerf": true,
+	"filetbl": true, "revtbl": true, "bkmkstart": true, "bkmkend": true, "nonshppict": true,
+}
+
+// extractRTFText strips RTF control words and groups, keeping paragraph and
+// table structure. Non-ASCII text is decoded from \'hh (Windows-1252) and
+// \uN escapes.
+func extractRTFText(_ context.Context, data []byte) (string, error) {
+	type group struct {
+		skip     bool
+		ucSkip   int
+		seenWord bool
+	}
+	var (
+		b      strings.Builder
+		stack  []group
+		cur    = group{ucSkip: 1}
+		toSkip int // fallback characters still to drop after \uN
+	)
+	emit := func(r rune) {
+		if cur.skip {
+			return
+		}
+		if toSkip > 0 {
+			toSkip--
+			return
+		}
+		b.WriteRune(r)
+	}
+
+	for i := 0; i < len(data); {
+		c := data[i]
+		switch c {
+		case '{':
+			stack = append(stack, cur)
+			cur.seenWord = false
+			toSkip = 0
+			i++
+		case '}':
+			if n := len(stack); n > 0 {
+				cur = stack[n-1]
+				stack = stack[:n-1]
+			}
+			toSkip = 0
+			i++
+		case '\r', '\n':
+			i++
+		case '\\':
+			i++
+			if i >= len(data) {
+				break
+			}
+			c = data[i]
+			switch {
+			case c == '\'':
+				if i+2 < len(data) {
+					if v, err := strconv.ParseUint(string(data[i+1:i+3]), 16, 8); err == nil {
+						if r := decodeCP1252Byte(byte(v)); r != 0 {
+							emit(r)
+						} else if toSkip > 0 {
+							toSkip--
+						}
+					}
+				}
+				i += 3
+			case c == '*':
+				cur.skip = true
+				i++
+			case c == '~':
+				emit(' ')
+				i++
+			case c == '-' || c == '_':
+				i++
+			case c == '{' || c == '}' || c == '\\':
+				emit(rune(c))
+				i++
+			case c == '\r' || c == '\n':
+				emit('\n')
+				i++
+			case c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+				start := i
+				for i < len(data) && (data[i] >= 'a' && data[i] <= 'z' || data[i] >= 'A' && data[i] <= 'Z') {
+					i++
+				}
+				word := string(data[start:i])
+				numStart := i
+				if i < len(data) && data[i] == '-' {
+					i++
+				}
+				for i < len(data) && data[i] >= '0' && data[i] <= '9' {
+					i++
+				}
+				param, hasParam := 0, i > numStart
+				if hasParam {
+					param, _ = strconv.Atoi(string(data[numStart:i]))
+				}
+				if i < len(data) && data[i] == ' ' {
+					i++
+				}
+
+				first := !cur.seenWord
+				cur.seenWord = true
+				if first && rtfSkippedDestinations[word] {
+					cur.skip = true
+					continue
+				}
+				switch word {
+				case "par", "line", "row", "sect", "page":
+					emit('\n')
+				case "tab", "cell":
+					emit('\t')
+				case "emdash":
+					emit('—')
+				case "endash":
+					emit('–')
+				case "bullet":
+					emit('•')
+				case "lquote":
+					emit('‘')
+				case "rquote":
+					emit('’')
+				case "ldblquote":
+					emit('“')
+				case "rdblquote":
+					emit('”')
+				case "uc":
+					if hasParam && param >= 0 {
+						cur.ucSkip = param
+					}
+				case "u":
+					if hasParam {
+						if param < 0 {
+							param += 0x10000
+						}
+						toSkip = 0
+						emit(rune(param))
+						if !cur.skip {
+							toSkip = cur.ucSkip
+						}
+					}
+				case "bin":
+					if hasParam && param > 0 {
+						i = min(i+param, len(data))
+					}
+				}
+				continue
+			default:
+				i++
+			}
+		default:
+			r, size := utf8.DecodeRune(data[i:])
+			if r == utf8.RuneError {
+				r = decodeCP1252Byte(c)
+			}
+			if r != 0 {
+				emit(r)
+			} else if toSkip > 0 {
+				toSkip--
+			}
+			i += size
+		}
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,165 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// readODFContent returns content.xml from an OpenDocument package.
+func readODFContent(data []byte, kind string) ([]byte, error) {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range reader.File {
+		if file.Name != "content.xml" {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+		content, err := io.ReadAll(io.LimitReader(rc, maxZIPEntrySize))
+		_ = rc.Close()
+		return content, err
+	}
+	return nil, fmt.Errorf("%s missing content.xml", kind)
+}
+
+func extractODTText(_ context.Context, data []byte) (string, error) {
+	content, err := readODFContent(data, "odt")
+	if err != nil {
+		return "", err
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	var builder strings.Builder
+	inBody := false
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return "", err
+		}
+
+		switch tok := token.(type) {
+		case xml.StartElement:
+			switch tok.Name.Local {
+			case "body":
+				inBody = true
+			case "p", "h":
+				if builder.Len() > 0 && !strings.HasSuffix(builder.String(), "\n") {
+					builder.WriteString("\n")
+				}
+			case "line-break":
+				builder.WriteString("\n")
+			case "tab":
+				builder.WriteString("\t")
+			case "s":
+				n := 1
+				for _, attr := range tok.Attr {
+					if attr.Name.Local == "c" {
+						if c, err := strconv.Atoi(attr.Value); err == nil && c > 0 && c < 1000 {
+							n = c
+						}
+					}
+				}
+				builder.WriteString(strings.Repeat(" ", n))
+			}
+		case xml.CharData:
+			if inBody {
+				builder.Write(tok)
+			}
+		case xml.EndElement:
+			if (tok.Name.Local == "p" || tok.Name.Local == "h") && !strings.HasSuffix(builder.String(), "\n") {
+				builder.WriteString("\n")
+			}
+		}
+	}
+
+	return builder.String(), nil
+}
+
+func extractODSText(_ context.Context, data []byte) (string, error) {
+	content, err := readODFContent(data, "ods")
+	if err != nil {
+		return "", err
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	var (
+		sections   []string
+		sheetName  string
+		rows       []string
+		currentRow []string
+		cell       strings.Builder
+		inCell     bool
+		inPara     bool
+	)
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return "", err
+		}
+
+		switch tok := token.(type) {
+		case xml.StartElement:
+			switch tok.Name.Local {
+			case "table":
+				sheetName = ""
+				rows = rows[:0]
+				for _, attr := range tok.Attr {
+					if attr.Name.Local == "name" {
+						sheetName = attr.Value
+					}
+				}
+			case "table-row":
+				currentRow = currentRow[:0]
+			case "table-cell", "covered-table-cell":
+				inCell = true
+				cell.Reset()
+			case "p":
+				if inCell && cell.Len() > 0 {
+					cell.WriteString(" ")
+				}
+				inPara = true
+			}
+		case xml.CharData:
+			if inCell && inPara {
+				cell.Write(tok)
+			}
+		case xml.EndElement:
+			switch tok.Name.Local {
+			case "p":
+				inPara = false
+			case "table-cell", "covered-table-cell":
+				// Repeated empty cells (number-columns-repeated) are
+				// collapsed; trailing ones are trimmed with the row.
+				currentRow = append(currentRow, strings.TrimSpace(cell.String()))
+				inCell = false
+			case "table-row":
+				if row := strings.TrimRight(strings.Join(currentRow, "\t"), "\t"); row != "" {
+					rows = append(rows, row)
+				}
+			case "table":
+				if len(rows) > 0 {
+					sections = append(sections, fmt.Sprintf("[%s]\n%s", sheetName, strings.Join(rows, "\n")))
+				}
+			}
+		}
+	}
+
+	return strings.Join(sections, "\n\n"), nil
+}
@@ -0,0 +1,997 @@
+package api
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"encoding/ascii85"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Pure-Go PDF text extraction. Content streams (uncompressed, FlateDecode,
+// ASCIIHex or ASCII85) are decoded and their text-showing operators
+// interpreted, using each font's ToUnicode CMap when present. There is no
+// layout analysis: text comes out in content-stream order with line breaks
+// where the text position moves down. Scanned PDFs and fonts without a usable
+// encoding produce no text, in which case pdftotext is tried next.
+
+var (
+	pdfObjHeaderRe = regexp.MustCompile(`(\d+)\s+\d+\s+obj\b`)
+	pdfLengthRe    = regexp.MustCompile(`/Length\s+(\d+)(\s+\d+\s+R)?`)
+	pdfRefRe       = regexp.MustCompile(`^(\d+)\s+\d+\s+R\b`)
+	pdfRefsRe      = regexp.MustCompile(`(\d+)\s+\d+\s+R\b`)
+	pdfFontEntryRe = regexp.MustCompile(`/([^\s/<>\[\]()%]+)\s+(\d+)\s+\d+\s+R\b`)
+	pdfNameRe      = regexp.MustCompile(`/([A-Za-z0-9]+)`)
+)
+
+// Limits for one PDF. All stream decodes, including object streams, share
+// one decompression budget, so many small bombs are caught as well as one
+// big one. Extracted text beyond maxPDFTextSize is dropped.
+var (
+	maxPDFDecodedSize int64 = maxZIPEntrySize
+	maxPDFTextSize          = 4 * 1024 * 1024
+)
+
+type pdfObject struct {
+	dict      string
+	stream    []byte
+	hasStream bool
+}
+
+type pdfFont struct {
+	cmap *pdfCMap
+	// twoByte marks Identity-encoded fonts without a ToUnicode map; their
+	// glyph IDs cannot be turned into text.
+	twoByte bool
+}
+
+type pdfDocument struct {
+	objects map[int]*pdfObject
+	order   []int
+	fonts   map[int]*pdfFont
+	// budget is the number of decompressed bytes left; err is set once a
+	// decode exceeds it.
+	budget int64
+	err    error
+}
+
+func extractPDFText(ctx context.Context, data []byte) (string, error) {
+	doc := parsePDFDocument(data)
+	if doc.err != nil {
+		return "", doc.err
+	}
+
+	out := pdfTextWriter{limit: maxPDFTextSize}
+	done := map[int]bool{}
+	for _, page := range doc.pages() {
+		fonts := doc.fontsFor(page)
+		for _, ref := range doc.contentRefs(page) {
+			if err := ctx.Err(); err != nil {
+				return "", err
+			}
+			if done[ref] {
+				continue
+			}
+			done[ref] = true
+			if content, ok := doc.decodedStream(ref); ok {
+				out.run(content, fonts)
+				out.newline()
+			}
+			if doc.err != nil {
+				return "", doc.err
+			}
+			if out.full {
+				return out.String(), nil
+			}
+		}
+	}
+	// Form XObjects (and content of PDFs whose page tree could not be read).
+	global := doc.allFonts()
+	for _, num := range doc.order {
+		obj := doc.objects[num]
+		if done[num] || !obj.hasStream || !pdfIsTextStream(obj.dict) {
+			continue
+		}
+		if len(done) > 0 && !strings.Contains(obj.dict, "/Form") {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		done[num] = true
+		if content, ok := doc.decodedStream(num); ok {
+			fonts := global
+			if res := doc.resolve(pdfValue(obj.dict, "Resources")); res != "" {
+				fonts = doc.fontMap(res)
+			}
+			out.run(content, fonts)
+			out.newline()
+		}
+		if doc.err != nil {
+			return "", doc.err
+		}
+		if out.full {
+			break
+		}
+	}
+	return out.String(), nil
+}
+
+// parsePDFDocument scans data for indirect objects, including those packed in
+// object streams. It tolerates broken cross-reference tables by not using them.
+func parsePDFDocument(data []byte) *pdfDocument {
+	doc := &pdfDocument{objects: map[int]*pdfObject{}, fonts: map[int]*pdfFont{}, budget: maxPDFDecodedSize}
+	pos := 0
+	for pos < len(data) {
+		loc := pdfObjHeaderRe.FindSubmatchIndex(data[pos:])
+		if loc == nil {
+			break
+		}
+		num, _ := strconv.Atoi(string(data[pos+loc[2] : pos+loc[3]]))
+		start := pos + loc[1]
+		obj, next := parsePDFObjectBody(data, start)
+		doc.add(num, obj)
+		pos = next
+	}
+
+	for _, num := range append([]int(nil), doc.order...) {
+		obj := doc.objects[num]
+		if obj.hasStream && pdfHasName(obj.dict, "Type", "ObjStm") {
+			doc.expandObjectStream(obj)
+			if doc.err != nil {
+				break
+			}
+		}
+	}
+	return doc
+}
+
+func (d *pdfDocument) add(num int, obj *pdfObject) {
+	if _, ok := d.objects[num]; !ok {
+		d.order = append(d.order, num)
+	}
+	d.objects[num] = obj
+}
+
+// parsePDFObjectBody reads one object starting after "N G obj" and returns
+// it with the offset just past it.
+func parsePDFObjectBody(data []byte, start int) (*pdfObject, int) {
+	rest := data[start:]
+	endobj := bytes.Index(rest, []byte("endobj"))
+	if endobj < 0 {
+		endobj = len(rest)
+	}
+	streamAt := bytes.Index(rest[:endobj], []byte("stream"))
+	if streamAt < 0 {
+		return &pdfObject{dict: string(rest[:endobj])}, start + min(endobj+len("endobj"), len(rest))
+	}
+
+	dict := string(rest[:streamAt])
+	body := streamAt + len("stream")
+	if body < len(rest) && rest[body] == '\r' {
+		body++
+	}
+	if body < len(rest) && rest[body] == '\n' {
+		body++
+	}
+
+	end := -1
+	if m := pdfLengthRe.FindStringSubmatch(dict); m != nil && m[2] == "" {
+		if n, err := strconv.Atoi(m[1]); err == nil && body+n <= len(rest) &&
+			bytes.HasPrefix(bytes.TrimLeft(rest[body+n:], "\r\n \t"), []byte("endstream")) {
+			end = body + n
+		}
+	}
+	if end < 0 {
+		idx := bytes.Index(rest[body:], []byte("endstream"))
+		if idx < 0 {
+			return &pdfObject{dict: dict}, len(data)
+		}
+		end = body + idx
+	}
+	obj := &pdfObject{dict: dict, stream: rest[body:end], hasStream: true}
+	next := end + len("endstream")
+	if e := bytes.Index(rest[next:], []byte("endobj")); e >= 0 && e < 64 {
+		next += e + len("endobj")
+	}
+	return obj, start + next
+}
+
+func (d *pdfDocument) expandObjectStream(obj *pdfObject) {
+	data, ok := d.decodeStream(obj)
+	if !ok {
+		return
+	}
+	n, _ := strconv.Atoi(pdfValue(obj.dict, "N"))
+	first, _ := strconv.Atoi(pdfValue(obj.dict, "First"))
+	if n <= 0 || first <= 0 || first > len(data) {
+		return
+	}
+	fields := strings.Fields(string(data[:first]))
+	type entry struct{ num, off int }
+	entries := make([]entry, 0, n)
+	for i := 0; i+1 < len(fields) && len(entries) < n; i += 2 {
+		num, err1 := strconv.Atoi(fields[i])
+		off, err2 := strconv.Atoi(fields[i+1])
+		if err1 != nil || err2 != nil || first+off > len(data) {
+			return
+		}
+		entries = append(entries, entry{num, first + off})
+	}
+	for i, e := range entries {
+		end := len(data)
+		if i+1 < len(entries) && entries[i+1].off >= e.off {
+			end = entries[i+1].off
+		}
+		if _, exists := d.objects[e.num]; !exists {
+			d.add(e.num, &pdfObject{dict: string(data[e.off:end])})
+		}
+	}
+}
+
+// resolve follows an indirect reference to the referenced object's
+// dictionary; other values are returned unchanged.
+func (d *pdfDocument) resolve(value string) string {
+	for range 8 {
+		m := pdfRefRe.FindStringSubmatch(strings.TrimSpace(value))
+		if m == nil {
+			return value
+		}
+		num, _ := strconv.Atoi(m[1])
+		obj := d.objects[num]
+		if obj == nil {
+			return ""
+		}
+		value = obj.dict
+	}
+	return value
+}
+
+// pages returns page dictionaries in page-tree order.
+func (d *pdfDocument) pages() []string {
+	var root string
+	for _, num := range d.order {
+		if pdfHasName(d.objects[num].dict, "Type", "Catalog") {
+			root = d.objects[num].dict
+			break
+		}
+	}
+	var pages []string
+	seen := map[string]bool{}
+	var walk func(node string, depth int)
+	walk = func(node string, depth int) {
+		if depth > 32 || seen[node] {
+			return
+		}
+		seen[node] = true
+		kids := pdfValue(node, "Kids")
+		if kids == "" {
+			if pdfValue(node, "Contents") != "" {
+				pages = append(pages, node)
+			}
+			return
+		}
+		for _, m := range pdfRefsRe.FindAllStringSubmatch(kids, -1) {
+			num, _ := strconv.Atoi(m[1])
+			if obj := d.objects[num]; obj != nil {
+				walk(obj.dict, depth+1)
+			}
+		}
+	}
+	if root != "" {
+		walk(d.resolve(pdfValue(root, "Pages")), 0)
+	}
+	if len(pages) == 0 {
+		for _, num := range d.order {
+			if dict := d.objects[num].dict; pdfHasName(dict, "Type", "Page") {
+				pages = append(pages, dict)
+			}
+		}
+	}
+	return pages
+}
+
+func (d *pdfDocument) contentRefs(page string) []int {
+	value := pdfValue(page, "Contents")
+	if arr := d.resolve(value); strings.HasPrefix(strings.TrimSpace(arr), "[") {
+		value = arr
+	}
+	var refs []int
+	for _, m := range pdfRefsRe.FindAllStringSubmatch(value, -1) {
+		num, _ := strconv.Atoi(m[1])
+		refs = append(refs, num)
+	}
+	return refs
+}
+
+// fontsFor returns the fonts in a page's (possibly inherited) resources.
+func (d *pdfDocument) fontsFor(page string) map[string]*pdfFont {
+	node := page
+	for range 32 {
+		if res := d.resolve(pdfValue(node, "Resources")); res != "" {
+			return d.fontMap(res)
+		}
+		parent := d.resolve(pdfValue(node, "Parent"))
+		if parent == "" || parent == node {
+			break
+		}
+		node = parent
+	}
+	return d.allFonts()
+}
+
+func (d *pdfDocument) fontMap(resources string) map[string]*pdfFont {
+	fonts := map[string]*pdfFont{}
+	for _, m := range pdfFontEntryRe.FindAllStringSubmatch(d.resolve(pdfValue(resources, "Font")), -1) {
+		num, _ := strconv.Atoi(m[2])
+		fonts[m[1]] = d.font(num)
+	}
+	return fonts
+}
+
+// allFonts maps every font resource name in the file; the first definition
+// of a name wins.
+func (d *pdfDocument) allFonts() map[string]*pdfFont {
+	fonts := map[string]*pdfFont{}
+	for _, num := range d.order {
+		dict := d.objects[num].dict
+		if !strings.Contains(dict, "/Font") {
+			continue
+		}
+		for name, f := range d.fontMap(dict) {
+			if _, ok := fonts[name]; !ok {
+				fonts[name] = f
+			}
+		}
+	}
+	return fonts
+}
+
+func (d *pdfDocument) font(num int) *pdfFont {
+	if f, ok := d.fonts[num]; ok {
+		return f
+	}
+	f := &pdfFont{}
+	d.fonts[num] = f
+	obj := d.objects[num]
+	if obj == nil {
+		return f
+	}
+	if m := pdfRefRe.FindStringSubmatch(strings.TrimSpace(pdfValue(obj.dict, "ToUnicode"))); m != nil {
+		ref, _ := strconv.Atoi(m[1])
+		if data, ok := d.decodedStream(ref); ok {
+			f.cmap = parsePDFCMap(data)
+		}
+	}
+	if f.cmap == nil && strings.Contains(pdfValue(obj.dict, "Encoding"), "Identity") {
+		f.twoByte = true
+	}
+	return f
+}
+
+func (d *pdfDocument) decodedStream(num int) ([]byte, bool) {
+	obj := d.objects[num]
+	if obj == nil || !obj.hasStream {
+		return nil, false
+	}
+	return d.decodeStream(obj)
+}
+
+// pdfIsTextStream filters out images, fonts, metadata and other streams that
+// never hold page content.
+func pdfIsTextStream(dict string) bool {
+	for _, key := range []string{"Length1", "Length2", "Length3", "FunctionType", "ShadingType", "PatternType"} {
+		if pdfValue(dict, key) != "" {
+			return false
+		}
+	}
+	for _, t := range []string{"XRef", "ObjStm", "Metadata", "EmbeddedFile", "XObject"} {
+		if pdfHasName(dict, "Type", t) && !pdfHasName(dict, "Subtype", "Form") {
+			return false
+		}
+	}
+	return !pdfHasName(dict, "Subtype", "Image") && !strings.Contains(dict, "/CIDFontType0C") && !strings.Contains(dict, "/Type1C")
+}
+
+// decodeStream applies the stream's filters, charging inflated output to the
+// document's decompression budget. Exceeding the budget sets d.err.
+func (d *pdfDocument) decodeStream(obj *pdfObject) ([]byte, bool) {
+	if d.err != nil {
+		return nil, false
+	}
+	data := obj.stream
+	filter := pdfValue(obj.dict, "Filter")
+	for _, m := range pdfNameRe.FindAllStringSubmatch(filter, -1) {
+		switch m[1] {
+		case "FlateDecode", "Fl":
+			r, err := zlib.NewReader(bytes.NewReader(data))
+			if err != nil {
+				return nil, false
+			}
+			out, err := io.ReadAll(io.LimitReader(r, d.budget+1))
+			_ = r.Close()
+			if int64(len(out)) > d.budget {
+				d.err = fmt.Errorf("pdf streams decompress to more than %d bytes", maxPDFDecodedSize)
+				return nil, false
+			}
+			d.budget -= int64(len(out))
+			if err != nil && len(out) == 0 {
+				return nil, false
+			}
+			data = out
+		case "ASCIIHexDecode", "AHx":
+			s := strings.Map(func(r rune) rune {
+				if strings.ContainsRune("0123456789abcdefABCDEF", r) {
+					return r
+				}
+				return -1
+			}, strings.SplitN(string(data), ">", 2)[0])
+			if len(s)%2 == 1 {
+				s += "0"
+			}
+			out, err := hex.DecodeString(s)
+			if err != nil {
+				return nil, false
+			}
+			data = out
+		case "ASCII85Decode", "A85":
+			src := bytes.TrimPrefix(bytes.TrimSpace(data), []byte("<~"))
+			if i := bytes.Index(src, []byte("~>")); i >= 0 {
+				src = src[:i]
+			}
+			out := make([]byte, len(src))
+			n, _, err := ascii85.Decode(out, src, true)
+			if err != nil {
+				return nil, false
+			}
+			data = out[:n]
+		default:
+			return nil, false
+		}
+	}
+	return data, true
+}
+
+// pdfValue returns the raw value following /key in a dictionary: a nested
+// dictionary or array, an indirect reference, or a single token.
+func pdfValue(dict, key string) string {
+	needle := "/" + key
+	for from := 0; ; {
+		i := strings.Index(dict[from:], needle)
+		if i < 0 {
+			return ""
+		}
+		i += from
+		end := i + len(needle)
+		from = end
+		if end < len(dict) && !pdfIsDelimiter(dict[end]) {
+			continue // longer name such as /FontDescriptor
+		}
+		v := strings.TrimLeft(dict[end:], " \t\r\n")
+		switch {
+		case v == "":
+			return ""
+		case strings.HasPrefix(v, "<<"):
+			return pdfBalanced(v, "<<", ">>")
+		case strings.HasPrefix(v, "["):
+			return pdfBalanced(v, "[", "]")
+		}
+		if m := pdfRefRe.FindString(v); m != "" {
+			return m
+		}
+		j := 1
+		for j < len(v) && !pdfIsDelimiter(v[j]) {
+			j++
+		}
+		return v[:j]
+	}
+}
+
+func pdfHasName(dict, key, name string) bool {
+	return strings.TrimPrefix(pdfValue(dict, key), "/") == name
+}
+
+func pdfIsDelimiter(c byte) bool {
+	return strings.IndexByte(" \t\r\n\f/<>[]()%", c) >= 0
+}
+
+// pdfBalanced returns the prefix of s up to the close matching its leading
+// open, skipping literal strings.
+func pdfBalanced(s, open, close string) string {
+	depth := 0
+	for i := 0; i < len(s); {
+		switch {
+		case s[i] == '(':
+			i = pdfSkipLiteral(s, i)
+			continue
+		case strings.HasPrefix(s[i:], open):
+			depth++
+			i += len(open)
+			continue
+		case strings.HasPrefix(s[i:], close):
+			depth--
+			i += len(close)
+			if depth == 0 {
+				return s[:i]
+			}
+			continue
+		}
+		i++
+	}
+	return s
+}
+
+func pdfSkipLiteral(s string, i int) int {
+	depth := 0
+	for ; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return i
+}
+
+// pdfCMap is a ToUnicode mapping from character codes to text.
+type pdfCMap struct {
+	widths []int // code lengths in bytes, longest first
+	codes  map[string]string
+}
+
+func parsePDFCMap(data []byte) *pdfCMap {
+	cm := &pdfCMap{codes: map[string]string{}}
+	widths := map[int]bool{}
+	s := string(data)
+	for _, section := range pdfSections(s, "beginbfchar", "endbfchar") {
+		toks := pdfCMapTokens(section)
+		for i := 0; i+1 < len(toks); i += 2 {
+			if src, dst := toks[i], toks[i+1]; len(src) > 0 && !strings.HasPrefix(dst, "[") {
+				cm.codes[src] = pdfUTF16(dst)
+				widths[len(src)] = true
+			}
+		}
+	}
+	for _, section := range pdfSections(s, "beginbfrange", "endbfrange") {
+		toks := pdfCMapTokens(section)
+		for i := 0; i+2 < len(toks); i += 3 {
+			lo, hi, dst := toks[i], toks[i+1], toks[i+2]
+			if len(lo) == 0 || len(lo) != len(hi) || len(lo) > 4 {
+				continue
+			}
+			widths[len(lo)] = true
+			start, end := pdfCodeInt(lo), pdfCodeInt(hi)
+			if end < start || end-start > 0xFFFF {
+				continue
+			}
+			var list []string
+			if strings.HasPrefix(dst, "[") {
+				list = pdfCMapTokens(dst[1:])
+			}
+			base := []rune(pdfUTF16(dst))
+			for code := start; code <= end; code++ {
+				key := pdfCodeBytes(code, len(lo))
+				switch {
+				case list != nil:
+					if k := code - start; k < len(list) {
+						cm.codes[key] = pdfUTF16(list[k])
+					}
+				case len(base) > 0:
+					r := append([]rune(nil), base...)
+					r[len(r)-1] += rune(code - start)
+					cm.codes[key] = string(r)
+				}
+			}
+		}
+	}
+	if len(cm.codes) == 0 {
+		return nil
+	}
+	for w := 4; w >= 1; w-- {
+		if widths[w] {
+			cm.widths = append(cm.widths, w)
+		}
+	}
+	return cm
+}
+
+func pdfSections(s, begin, end string) []string {
+	var out []string
+	for {
+		i := strings.Index(s, begin)
+		if i < 0 {
+			return out
+		}
+		s = s[i+len(begin):]
+		j := strings.Index(s, end)
+		if j < 0 {
+			return append(out, s)
+		}
+		out = append(out, s[:j])
+		s = s[j+len(end):]
+	}
+}
+
+// pdfCMapTokens splits a CMap section into hex codes (as raw bytes) and
+// bracketed arrays (returned with their leading "[").
+func pdfCMapTokens(s string) []string {
+	var toks []string
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '<':
+			j := strings.IndexByte(s[i:], '>')
+			if j < 0 {
+				return toks
+			}
+			toks = append(toks, pdfHexBytes(s[i+1:i+j]))
+			i += j
+		case '[':
+			j := strings.IndexByte(s[i:], ']')
+			if j < 0 {
+				return toks
+			}
+			toks = append(toks, "["+s[i+1:i+j])
+			i += j
+		}
+	}
+	return toks
+}
+
+func pdfHexBytes(s string) string {
+	s = strings.Join(strings.Fields(s), "")
+	if len(s)%2 == 1 {
+		s += "0"
+	}
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+func pdfCodeInt(code string) int {
+	n := 0
+	for i := 0; i < len(code); i++ {
+		n = n<<8 | int(code[i])
+	}
+	return n
+}
+
+func pdfCodeBytes(n, width int) string {
+	b := make([]byte, width)
+	for i := width - 1; i >= 0; i-- {
+		b[i] = byte(n)
+		n >>= 8
+	}
+	return string(b)
+}
+
+// pdfUTF16 decodes UTF-16BE text (CMap destinations, text strings with a BOM).
+func pdfUTF16(b string) string {
+	b = strings.TrimPrefix(b, "\xfe\xff")
+	u := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		u = append(u, uint16(b[i])<<8|uint16(b[i+1]))
+	}
+	return string(utf16.Decode(u))
+}
+
+// pdfTextWriter accumulates shown text, inserting spaces and line breaks from
+// positioning operators. Once limit bytes are written (0 means no limit),
+// further text is dropped and full is set.
+type pdfTextWriter struct {
+	b     []byte
+	limit int
+	full  bool
+	// y is the current line's vertical position; shownY the position of
+	// the last text shown.
+	y, shownY float64
+	shown     bool
+}
+
+func (w *pdfTextWriter) String() string {
+	return string(w.b)
+}
+
+func (w *pdfTextWriter) last() byte {
+	if len(w.b) == 0 {
+		return '\n'
+	}
+	return w.b[len(w.b)-1]
+}
+
+// write appends s, cutting it at a rune boundary if it would pass the limit.
+func (w *pdfTextWriter) write(s string) {
+	if w.full {
+		return
+	}
+	if room := w.limit - len(w.b); w.limit > 0 && len(s) > room {
+		for room > 0 && !utf8.RuneStart(s[room]) {
+			room--
+		}
+		s = s[:room]
+		w.full = true
+	}
+	w.b = append(w.b, s...)
+}
+
+func (w *pdfTextWriter) space() {
+	if c := w.last(); c != ' ' && c != '\n' {
+		w.write(" ")
+	}
+}
+
+func (w *pdfTextWriter) newline() {
+	if len(w.b) > 0 && w.last() != '\n' {
+		w.b = bytes.TrimRight(w.b, " ")
+		w.write("\n")
+	}
+}
+
+// moveTo sets the line position; moves along the same line separate words.
+func (w *pdfTextWriter) moveTo(y float64) {
+	if y == w.y {
+		w.space()
+	}
+	w.y = y
+}
+
+func (w *pdfTextWriter) show(s []byte, font *pdfFont) {
+	text := decodePDFString(s, font)
+	if text == "" {
+		return
+	}
+	if w.shown && w.y != w.shownY {
+		w.newline()
+	}
+	w.shown, w.shownY = true, w.y
+	w.write(text)
+}
+
+func decodePDFString(s []byte, font *pdfFont) string {
+	if len(s) >= 2 && s[0] == 0xFE && s[1] == 0xFF {
+		return pdfUTF16(string(s))
+	}
+	var b strings.Builder
+	switch {
+	case font != nil && font.cmap != nil:
+		for i := 0; i < len(s); {
+			matched := false
+			for _, width := range font.cmap.widths {
+				if i+width > len(s) {
+					continue
+				}
+				if text, ok := font.cmap.codes[string(s[i:i+width])]; ok {
+					b.WriteString(text)
+					i += width
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				i += font.cmap.widths[len(font.cmap.widths)-1]
+			}
+		}
+	case font != nil && font.twoByte:
+		return ""
+	default:
+		for _, c := range s {
+			if r := decodeCP1252Byte(c); r != 0 {
+				b.WriteRune(r)
+			}
+		}
+	}
+	return b.String()
+}
+
+type pdfOperand struct {
+	num   float64
+	str   []byte
+	name  string
+	array []pdfOperand
+	isNum bool
+	isStr bool
+}
+
+// run interprets the text operators of one content stream.
+func (w *pdfTextWriter) run(content []byte, fonts map[string]*pdfFont) {
+	var (
+		stack  []pdfOperand
+		arrays [][]pdfOperand
+		font   *pdfFont
+	)
+	push := func(op pdfOperand) {
+		if n := len(arrays); n > 0 {
+			arrays[n-1] = append(arrays[n-1], op)
+			return
+		}
+		stack = append(stack, op)
+	}
+	num := func(i int) float64 {
+		if i >= 0 && i < len(stack) && stack[i].isNum {
+			return stack[i].num
+		}
+		return 0
+	}
+
+	for i := 0; i < len(content) && !w.full; {
+		c := content[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '\f' || c == 0:
+			i++
+		case c == '%':
+			for i < len(content) && content[i] != '\n' && content[i] != '\r' {
+				i++
+			}
+		case c == '(':
+			s, next := pdfLiteralString(content, i)
+			push(pdfOperand{str: s, isStr: true})
+			i = next
+		case c == '<' && i+1 < len(content) && content[i+1] == '<', c == '>' && i+1 < len(content) && content[i+1] == '>':
+			i += 2
+		case c == '<':
+			end := bytes.IndexByte(content[i:], '>')
+			if end < 0 {
+				return
+			}
+			push(pdfOperand{str: []byte(pdfHexBytes(string(content[i+1 : i+end]))), isStr: true})
+			i += end + 1
+		case c == '[':
+			arrays = append(arrays, nil)
+			i++
+		case c == ']':
+			if n := len(arrays); n > 0 {
+				arr := arrays[n-1]
+				arrays = arrays[:n-1]
+				push(pdfOperand{array: arr})
+			}
+			i++
+		case c == '/':
+			j := i + 1
+			for j < len(content) && !pdfIsDelimiter(content[j]) {
+				j++
+			}
+			push(pdfOperand{name: string(content[i+1 : j])})
+			i = j
+		case c == '+' || c == '-' || c == '.' || (c >= '0' && c <= '9'):
+			j := i + 1
+			for j < len(content) && (content[j] == '.' || (content[j] >= '0' && content[j] <= '9')) {
+				j++
+			}
+			f, _ := strconv.ParseFloat(string(content[i:j]), 64)
+			push(pdfOperand{num: f, isNum: true})
+			i = j
+		default:
+			j := i + 1
+			for j < len(content) && !pdfIsDelimiter(content[j]) && content[j] != '{' && content[j] != '}' {
+				j++
+			}
+			op := string(content[i:j])
+			i = j
+			if op == "BI" {
+				// Skip inline image data.
+				if k := bytes.Index(content[i:], []byte("EI")); k >= 0 {
+					i += k + 2
+				} else {
+					return
+				}
+			}
+			if len(arrays) > 0 {
+				arrays = nil
+			}
+			switch op {
+			case "Tf":
+				if len(stack) >= 2 {
+					font = fonts[stack[len(stack)-2].name]
+				}
+			case "Tj":
+				if n := len(stack); n > 0 && stack[n-1].isStr {
+					w.show(stack[n-1].str, font)
+				}
+			case "TJ":
+				if n := len(stack); n > 0 {
+					for _, el := range stack[n-1].array {
+						switch {
+						case el.isStr:
+							w.show(el.str, font)
+						case el.isNum && el.num < -200:
+							w.space()
+						}
+					}
+				}
+			case "'":
+				w.newline()
+				if n := len(stack); n > 0 && stack[n-1].isStr {
+					w.show(stack[n-1].str, font)
+				}
+			case "\"":
+				w.newline()
+				if n := len(stack); n > 0 && stack[n-1].isStr {
+					w.show(stack[n-1].str, font)
+				}
+			case "T*":
+				w.newline()
+			case "Td", "TD":
+				w.moveTo(w.y + num(1))
+			case "Tm":
+				w.moveTo(num(5))
+			case "BT":
+				w.y = 0
+			case "ET":
+				w.space()
+			}
+			stack = stack[:0]
+		}
+	}
+}
+
+// pdfLiteralString decodes a (...) string starting at content[i].
+func pdfLiteralString(content []byte, i int) ([]byte, int) {
+	var out []byte
+	depth := 0
+	for i < len(content) {
+		c := content[i]
+		switch c {
+		case '(':
+			depth++
+			if depth > 1 {
+				out = append(out, c)
+			}
+		case ')':
+			depth--
+			if depth == 0 {
+				return out, i + 1
+			}
+			out = append(out, c)
+		case '\\':
+			i++
+			if i >= len(content) {
+				return out, i
+			}
+			e := content[i]
+			switch e {
+			case 'n':
+				out = append(out, '\n')
+			case 'r':
+				out = append(out, '\r')
+			case 't':
+				out = append(out, '\t')
+			case 'b', 'f':
+			case '\r':
+				if i+1 < len(content) && content[i+1] == '\n' {
+					i++
+				}
+			case '\n':
+			default:
+				if e >= '0' && e <= '7' {
+					v := 0
+					k := 0
+					for ; k < 3 && i+k < len(content) && content[i+k] >= '0' && content[i+k] <= '7'; k++ {
+						v = v*8 + int(content[i+k]-'0')
+					}
+					out = append(out, byte(v))
+					i += k - 1
+				} else {
+					out = append(out, e)
+				}
+			}
+		default:
+			out = append(out, c)
+		}
+		i++
+	}
+	return out, i
+}
@@ -0,0 +1,173 @@
+package api
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// makeTestPDF assembles objects (numbered from 1) into a PDF file. Streams
+// are given as [dict, data] pairs; data is FlateDecode-compressed when the
+// dict asks for it.
+func makeTestPDF(t *testing.T, objects ...[]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.5\n%\xe2\xe3\xcf\xd3\n")
+	for i, obj := range objects {
+		fmt.Fprintf(&buf, "%d 0 obj\n", i+1)
+		if len(obj) == 1 {
+			buf.WriteString(obj[0])
+		} else {
+			data := []byte(obj[1])
+			if strings.Contains(obj[0], "/FlateDecode") {
+				var z bytes.Buffer
+				w := zlib.NewWriter(&z)
+				if _, err := w.Write(data); err != nil {
+					t.Fatal(err)
+				}
+				if err := w.Close(); err != nil {
+					t.Fatal(err)
+				}
+				data = z.Bytes()
+			}
+			fmt.Fprintf(&buf, "<< %s /Length %d >>\nstream\n", obj[0], len(data))
+			buf.Write(data)
+			buf.WriteString("\nendstream")
+		}
+		buf.WriteString("\nendobj\n")
+	}
+	buf.WriteString("trailer\n<< /Root 1 0 R >>\n%%EOF\n")
+	return buf.Bytes()
+}
+
+func TestExtractPDFText(t *testing.T) {
+	toUnicode := `/CIDInit /ProcSet findresource begin
+begincmap
+1 begincodespacerange <0000> <FFFF> endcodespacerange
+2 beginbfchar
+<0001> <0048>
+<0002> <0069>
+endbfchar
+1 beginbfrange
+<0010> <0012> <00E9>
+endbfrange
+endcmap`
+
+	pdf := makeTestPDF(t,
+		[]string{"<< /Type /Catalog /Pages 2 0 R >>"},
+		[]string{"<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 2 /Resources << /Font << /F1 5 0 R /F2 6 0 R >> >> >>"},
+		// Pages are listed before their content to check page-tree order.
+		[]string{"<< /Type /Page /Parent 2 0 R /Contents 8 0 R >>"},
+		[]string{"<< /Type /Page /Parent 2 0 R /Contents [9 0 R] >>"},
+		[]string{"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>"},
+		[]string{"<< /Type /Font /Subtype /Type0 /BaseFont /Custom /Encoding /Identity-H /ToUnicode 7 0 R >>"},
+		[]string{"/Filter /FlateDecode", toUnicode},
+		[]string{"/Filter /FlateDecode", "BT /F1 12 Tf 72 720 Td (Invoice \\(draft\\)) Tj 0 -14 Td [(Total:) -300 (42) 10 (.00)] TJ ET\n" +
+			"BT /F2 12 Tf 1 0 0 1 72 690 Tm <00010002> Tj 1 0 0 1 72 676 Tm <0010 0011 0012> Tj ET"},
+		[]string{"", "BT /F1 10 Tf 72 720 Td (Caf\\351 \\223page two\\224) Tj T* (last line) Tj ET"},
+	)
+
+	text, err := extractPDFText(context.Background(), pdf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Invoice (draft)\nTotal: 42.00\nHi\néêë\nCafé “page two”\nlast line"
+	if got := normalizeExtractedText(text); got != want {
+		t.Fatalf("extractPDFText = %q, want %q", got, want)
+	}
+}
+
+func TestExtractPDFTextUnreadable(t *testing.T) {
+	// Identity-encoded glyph IDs without a ToUnicode map cannot be decoded,
+	// and a bare header has no content at all: both leave the work to
+	// pdftotext rather than failing.
+	pdf := makeTestPDF(t,
+		[]string{"<< /Type /Catalog /Pages 2 0 R >>"},
+		[]string{"<< /Type /Pages /Kids [3 0 R] /Count 1 >>"},
+		[]string{"<< /Type /Page /Parent 2 0 R /Resources << /Font << /F1 4 0 R >> >> /Contents 5 0 R >>"},
+		[]string{"<< /Type /Font /Subtype /Type0 /Encoding /Identity-H >>"},
+		[]string{"", "BT /F1 12 Tf <00410042> Tj ET"},
+	)
+	for _, data := range [][]byte{pdf, []byte("%PDF-1.4")} {
+		text, err := extractPDFText(context.Background(), data)
+		if err != nil || strings.TrimSpace(text) != "" {
+			t.Fatalf("extractPDFText = %q, %v; want empty", text, err)
+		}
+	}
+}
+
+func TestExtractPDFTextObjectStream(t *testing.T) {
+	// The page (5) and its font (6) live inside the object stream 3.
+	header := "5 0 6 90 "
+	page := "<< /Type /Page /Parent 2 0 R /Contents 4 0 R /Resources << /Font << /F1 6 0 R >> >> >>"
+	objStm := header + page + strings.Repeat(" ", 90-len(page)) + "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>"
+	pdf := makeTestPDF(t,
+		[]string{"<< /Type /Catalog /Pages 2 0 R >>"},
+		[]string{"<< /Type /Pages /Kids [5 0 R] /Count 1 >>"},
+		[]string{fmt.Sprintf("/Type /ObjStm /N 2 /First %d /Filter /FlateDecode", len(header)), objStm},
+		[]string{"/Filter /FlateDecode", "BT /F1 12 Tf 72 720 Td (packed objects) Tj ET"},
+	)
+
+	text, err := extractPDFText(context.Background(), pdf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := normalizeExtractedText(text); got != "packed objects" {
+		t.Fatalf("extractPDFText = %q", got)
+	}
+}
+
+func TestExtractPDFTextSharedDecompressionBudget(t *testing.T) {
+	defer func(size int64) { maxPDFDecodedSize = size }(maxPDFDecodedSize)
+	maxPDFDecodedSize = 1 << 20
+
+	// Each stream inflates to well under the budget; together they exceed
+	// it, whether they are page contents or packed in object streams.
+	bomb := strings.Repeat(" ", 100<<10)
+	objects := [][]string{
+		{"<< /Type /Catalog /Pages 2 0 R >>"},
+		{"<< /Type /Pages /Kids [3 0 R] /Count 1 >>"},
+	}
+	contents := ""
+	for i := range 20 {
+		contents += fmt.Sprintf("%d 0 R ", i+4)
+	}
+	objects = append(objects, []string{"<< /Type /Page /Parent 2 0 R /Contents [" + contents + "] >>"})
+	for range 20 {
+		objects = append(objects, []string{"/Filter /FlateDecode", bomb + "BT (x) Tj ET"})
+	}
+	if _, err := extractPDFText(context.Background(), makeTestPDF(t, objects...)); err == nil {
+		t.Fatal("expected page streams to exhaust the decompression budget")
+	}
+
+	var stms [][]string
+	for range 20 {
+		stms = append(stms, []string{"/Type /ObjStm /N 1 /First 4 /Filter /FlateDecode", "99 0" + bomb})
+	}
+	if _, err := extractPDFText(context.Background(), makeTestPDF(t, stms...)); err == nil {
+		t.Fatal("expected object streams to exhaust the decompression budget")
+	}
+}
+
+func TestExtractPDFTextCapsOutput(t *testing.T) {
+	defer func(size int) { maxPDFTextSize = size }(maxPDFTextSize)
+	maxPDFTextSize = 1000
+
+	pdf := makeTestPDF(t,
+		[]string{"<< /Type /Catalog /Pages 2 0 R >>"},
+		[]string{"<< /Type /Pages /Kids [3 0 R] /Count 1 >>"},
+		[]string{"<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>"},
+		[]string{"/Filter /FlateDecode", strings.Repeat("BT (caf\\351 text) Tj T* ET\n", 500)},
+	)
+	text, err := extractPDFText(context.Background(), pdf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(text) > 1000 || len(text) < 900 || !strings.HasPrefix(text, "café text\n") {
+		t.Fatalf("extracted %d bytes: %q", len(text), text)
+	}
+}
@@ -0,0 +1,188 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// maxNestedDocumentDepth bounds recursion into documents embedded in other
+// documents (attachments of forwarded emails).
+const maxNestedDocumentDepth = 3
+
+// DocumentExtractor converts one document format to plain text.
+//
+// Extractors are selected by MIME type, falling back to the file extension
+// when the MIME type is missing or generic (octet-stream, zip, text/plain).
+// When several extractors claim a format they are tried in order and the first
+// to return non-empty text wins, so a fast pure-Go extractor can be backed by
+// a more thorough one.
+type DocumentExtractor struct {
+	// Name is reported as the attachment's extractor.
+	Name       string
+	MIMETypes  []string
+	Extensions []string
+	// Extract receives the whole (already size-capped) document.
+	Extract func(ctx context.Context, data []byte) (string, error)
+}
+
+var (
+	documentExtractorsMu sync.RWMutex
+	documentExtractors   []DocumentExtractor
+)
+
+// The built-ins are installed in init because the email extractor recurses
+// through the registry.
+func init() {
+	documentExtractors = builtinDocumentExtractors()
+}
+
+// RegisterDocumentExtractor adds an extractor that is tried before the
+// built-in ones for the formats it claims.
+func RegisterDocumentExtractor(e DocumentExtractor) {
+	documentExtractorsMu.Lock()
+	defer documentExtractorsMu.Unlock()
+	documentExtractors = append([]DocumentExtractor{e}, documentExtractors...)
+}
+
+func builtinDocumentExtractors() []DocumentExtractor {
+	return []DocumentExtractor{
+		{Name: "pdf-text", MIMETypes: []string{"application/pdf"}, Extensions: []string{".pdf"}, Extract: extractPDFText},
+		// pdftotext handles fonts and encodings the pure-Go reader cannot.
+		{Name: "pdftotext", MIMETypes: []string{"application/pdf"}, Extensions: []string{".pdf"}, Extract: extractPDFWithRunner},
+		{Name: "docx-xml", MIMETypes: []string{"application/vnd.openxmlformats-officedocument.wordprocessingml.document"}, Extensions: []string{".docx"}, Extract: extractDOCXText},
+		{Name: "xlsx-xml", MIMETypes: []string{"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"}, Extensions: []string{".xlsx"}, Extract: extractXLSXText},
+		{Name: "odt-xml", MIMETypes: []string{"application/vnd.oasis.opendocument.text"}, Extensions: []string{".odt"}, Extract: extractODTText},
+		{Name: "ods-xml", MIMETypes: []string{"application/vnd.oasis.opendocument.spreadsheet"}, Extensions: []string{".ods"}, Extract: extractODSText},
+		{Name: "rtf", MIMETypes: []string{"application/rtf", "text/rtf"}, Extensions: []string{".rtf"}, Extract: extractRTFText},
+		{Name: "html", MIMETypes: []string{"text/html", "application/xhtml+xml"}, Extensions: []string{".html", ".htm", ".xhtml"}, Extract: extractHTMLText},
+		{Name: "email", MIMETypes: []string{"message/rfc822"}, Extensions: []string{".eml"}, Extract: extractEmailText},
+		{Name: "text", MIMETypes: []string{"text/plain", "text/markdown", "application/json", "text/csv", "application/xml", "text/xml"}, Extensions: []string{".txt", ".md", ".markdown", ".json", ".csv", ".xml", ".log"}, Extract: extractPlainText},
+	}
+}
+
+// genericMIMETypes say little about the format, so the extension decides.
+var genericMIMETypes = map[string]bool{
+	"":                             true,
+	"application/octet-stream":     true,
+	"application/zip":              true,
+	"application/x-zip-compressed": true,
+	"text/plain":                   true,
+}
+
+// documentExtractorsFor returns the extractors to try, in order.
+func documentExtractorsFor(name, mimeType string) []DocumentExtractor {
+	mimeType = strings.ToLower(strings.TrimSpace(strings.Split(mimeType, ";")[0]))
+	ext := strings.ToLower(filepath.Ext(name))
+
+	documentExtractorsMu.RLock()
+	defer documentExtractorsMu.RUnlock()
+	var byMIME, byExt []DocumentExtractor
+	for _, e := range documentExtractors {
+		if mimeType != "" && containsFold(e.MIMETypes, mimeType) {
+			byMIME = append(byMIME, e)
+		}
+		if ext != "" && containsFold(e.Extensions, ext) {
+			byExt = append(byExt, e)
+		}
+	}
+	if len(byMIME) == 0 || (genericMIMETypes[mimeType] && len(byExt) > 0) {
+		return byExt
+	}
+	return byMIME
+}
+
+// extractDocumentText runs the matching extractors over data and returns the
+// normalized text and the name of the extractor that produced it.
+func extractDocumentText(ctx context.Context, data []byte, name, mimeType string) (string, string, error) {
+	extractors := documentExtractorsFor(name, mimeType)
+	if len(extractors) == 0 {
+		return "", "", fmt.Errorf("unsupported document type %q", mimeType)
+	}
+
+	var firstErr error
+	emptyFrom := ""
+	for _, e := range extractors {
+		if err := ctx.Err(); err != nil {
+			return "", "", err
+		}
+		text, err := e.Extract(ctx, data)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if text = normalizeExtractedText(text); text != "" {
+			return text, e.Name, nil
+		}
+		if emptyFrom == "" {
+			emptyFrom = e.Name
+		}
+	}
+	if emptyFrom != "" {
+		return "", emptyFrom, nil
+	}
+	return "", "", firstErr
+}
+
+type extractDepthKey struct{}
+
+// nestedExtractContext returns a context for extracting a document embedded
+// in the current one, or false when the nesting limit is reached.
+func nestedExtractContext(ctx context.Context) (context.Context, bool) {
+	depth, _ := ctx.Value(extractDepthKey{}).(int)
+	if depth >= maxNestedDocumentDepth {
+		return ctx, false
+	}
+	return context.WithValue(ctx, extractDepthKey{}, depth+1), true
+}
+
+// extractPDFWithRunner writes data to a temporary file for runPDFToText.
+func extractPDFWithRunner(ctx context.Context, data []byte) (string, error) {
+	tmp, err := os.CreateTemp("", "chatwoot-attachment-*.pdf")
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", err
+	}
+	return runPDFToText(ctx, tmp.Name())
+}
+
+func containsFold(values []string, v string) bool {
+	for _, s := range values {
+		if strings.EqualFold(s, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// cp1252High maps Windows-1252 bytes 0x80-0x9F, which differ from Latin-1.
+// Both PDF (WinAnsiEncoding) and RTF (\ansicpg1252) use this code page.
+var cp1252High = [32]rune{
+	'€', 0, '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', 0, 'Ž', 0,
+	0, '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', 0, 'ž', 'Ÿ',
+}
+
+// decodeCP1252Byte converts a single Windows-1252 byte to a rune, returning 0
+// for control characters.
+func decodeCP1252Byte(b byte) rune {
+	switch {
+	case b >= 0x80 && b < 0xA0:
+		return cp1252High[b-0x80]
+	case b < 0x20 && b != '\n' && b != '\t':
+		return 0
+	default:
+		return rune(b)
+	}
+}
@@ -0,0 +1,156 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDocumentExtractorsFor(t *testing.T) {
+	tests := []struct {
+		name, mimeType string
+		want           []string
+	}{
+		{"report.pdf", "application/pdf", []string{"pdf-text", "pdftotext"}},
+		{"notes.odt", "application/octet-stream", []string{"odt-xml"}},
+		{"invoice.html", "text/plain", []string{"html"}},
+		{"forwarded", "message/rfc822; charset=utf-8", []string{"email"}},
+		{"readme.txt", "text/plain", []string{"text"}},
+		{"archive.zip", "application/zip", nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, e := range documentExtractorsFor(tt.name, tt.mimeType) {
+			got = append(got, e.Name)
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("documentExtractorsFor(%q, %q) = %v, want %v", tt.name, tt.mimeType, got, tt.want)
+		}
+	}
+}
+
+func TestExtractDocumentTextFallsBack(t *testing.T) {
+	saved := documentExtractors
+	t.Cleanup(func() { documentExtractors = saved })
+
+	RegisterDocumentExtractor(DocumentExtractor{
+		Name:       "broken",
+		Extensions: []string{".custom"},
+		Extract: func(context.Context, []byte) (string, error) {
+			return "", errors.New("boom")
+		},
+	})
+	RegisterDocumentExtractor(DocumentExtractor{
+		Name:       "empty",
+		Extensions: []string{".custom"},
+		Extract: func(context.Context, []byte) (string, error) {
+			return "  \n", nil
+		},
+	})
+	if _, _, err := extractDocumentText(context.Background(), nil, "a.custom", ""); err != nil {
+		t.Fatalf("empty text should not be an error: %v", err)
+	}
+
+	RegisterDocumentExtractor(DocumentExtractor{
+		Name:       "custom",
+		Extensions: []string{".custom"},
+		Extract: func(context.Context, []byte) (string, error) {
+			return " custom text \n", nil
+		},
+	})
+	text, extractor, err := extractDocumentText(context.Background(), nil, "a.custom", "")
+	if err != nil || extractor != "custom" || text != "custom text" {
+		t.Fatalf("extractDocumentText = %q, %q, %v", text, extractor, err)
+	}
+
+	if _, _, err := extractDocumentText(context.Background(), nil, "a.bin", "application/octet-stream"); err == nil || !strings.Contains(err.Error(), "unsupported") {
+		t.Fatalf("expected unsupported error, got %v", err)
+	}
+}
+
+func TestExtractHTMLText(t *testing.T) {
+	doc := `<!DOCTYPE html><html><head><title>Invoice</title><style>p{color:red}</style></head>
+<body><script>alert("x")</script><!-- hidden -->
+<h1>Invoice   #42</h1><p>Dear&nbsp;customer,<br>thanks &amp; regards</p>
+<table><tr><th>Item</th><th>Price</th></tr><tr><td>Widget</td><td>&euro;9.99</td></tr></table>
+<ul><li>One</li><li>Two</li></ul><pre>  keep
+  spacing</pre><p>a < b</p></body></html>`
+
+	text, err := extractHTMLText(context.Background(), []byte(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	text = normalizeExtractedText(text)
+	for _, want := range []string{"Invoice #42\n", "Dear customer,\nthanks & regards", "Item\tPrice\nWidget\t€9.99", "- One\n- Two", "  keep\n  spacing", "a < b"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("missing %q in %q", want, text)
+		}
+	}
+	for _, unwanted := range []string{"alert", "color", "hidden", "<title>"} {
+		if strings.Contains(text, unwanted) {
+			t.Errorf("unexpected %q in %q", unwanted, text)
+		}
+	}
+}
+
+func TestExtractRTFText(t *testing.T) {
+	doc := `{\rtf1\ansi\ansicpg1252\deff0{\fonttbl{\f0 Arial;}}{\colortbl;\red0\green0\blue0;}
+{\*\generator Riched20;}\pard Caf\'e9 opening hours\par
+Mon\tab 9\endash 17\line Sun\tab closed\par
+{\uc1 Price: 10\u8364?}\par
+{\info{\title Hidden}}Escaped \{braces\}}`
+
+	text, err := extractRTFText(context.Background(), []byte(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Café opening hours\nMon\t9–17\nSun\tclosed\nPrice: 10€\nEscaped {braces}"
+	if got := normalizeExtractedText(text); got != want {
+		t.Fatalf("extractRTFText = %q, want %q", got, want)
+	}
+}
+
+func TestExtractODFText(t *testing.T) {
+	odt := makeTestODF(t, `<office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0"><office:body><office:text><text:h>Title</text:h><text:p>Hello<text:s text:c="2"/>world<text:tab/>tabbed<text:line-break/>next</text:p></office:text></office:body></office:document-content>`)
+	text, err := extractODTText(context.Background(), odt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := normalizeExtractedText(text), "Title\nHello  world\ttabbed\nnext"; got != want {
+		t.Fatalf("extractODTText = %q, want %q", got, want)
+	}
+
+	ods := makeTestODF(t, `<office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0" xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0"><office:body><office:spreadsheet><table:table table:name="Prices"><table:table-row><table:table-cell><text:p>Product</text:p></table:table-cell><table:table-cell><text:p>Price</text:p></table:table-cell></table:table-row><table:table-row><table:table-cell table:number-columns-repeated="1024"/></table:table-row><table:table-row><table:table-cell><text:p>Chocolate</text:p></table:table-cell><table:table-cell><text:p>9.99</text:p></table:table-cell><table:table-cell table:number-columns-repeated="1022"/></table:table-row></table:table></office:spreadsheet></office:body></office:document-content>`)
+	text, err = extractODSText(context.Background(), ods)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := normalizeExtractedText(text), "[Prices]\nProduct\tPrice\nChocolate\t9.99"; got != want {
+		t.Fatalf("extractODSText = %q, want %q", got, want)
+	}
+
+	if _, err := extractODTText(context.Background(), makeTestDOCX(t, "x")); err == nil {
+		t.Fatal("expected error for package without content.xml")
+	}
+}
+
+func makeTestODF(t *testing.T, content string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zipWriter := zip.NewWriter(&buf)
+	writer, err := zipWriter.Create("content.xml")
+	if err != nil {
+		t.Fatalf("Create(content.xml) failed: %v", err)
+	}
+	if _, err := writer.Write([]byte(content)); err != nil {
+		t.Fatalf("Write(content.xml) failed: %v", err)
+	}
+	if err := zipWriter.Close(); err != nil {
+		t.Fatalf("Close zip failed: %v", err)
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// StoredAttachment is an attachment saved in a content-addressed directory.
+type StoredAttachment struct {
+	SHA256   string `json:"sha256"`
+	Path     string `json:"path"` // relative to the store directory
+	Name     string `json:"name,omitempty"`
+	MIMEType string `json:"mime_type,omitempty"`
+	Bytes    int64  `json:"bytes"`
+}
+
+// StoreAttachment downloads an attachment into dir under its SHA-256
+// (dir/ab/abcdef...), using the hash computed while downloading. Identical
+// files are stored once. maxBytes of 0 means no limit.
+func (c *Client) StoreAttachment(ctx context.Context, rawURL, dir string, maxBytes int64) (*StoredAttachment, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create attachment directory: %w", err)
+	}
+	// Download next to the store so the final rename stays on one filesystem.
+	download, err := c.downloadAttachmentToTemp(ctx, rawURL, dir, maxBytes)
+	if err != nil {
+		return nil, err
+	}
+	defer download.Cleanup()
+
+	rel := filepath.Join(download.SHA256[:2], download.SHA256)
+	dst := filepath.Join(dir, rel)
+	if _, err := os.Stat(dst); os.IsNotExist(err) {
+		if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+			return nil, fmt.Errorf("create attachment directory: %w", err)
+		}
+		if err := os.Rename(download.Path, dst); err != nil {
+			return nil, fmt.Errorf("store attachment: %w", err)
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &StoredAttachment{
+		SHA256:   download.SHA256,
+		Path:     filepath.ToSlash(rel),
+		Name:     download.Name,
+		MIMEType: download.MIMEType,
+		Bytes:    download.Bytes,
+	}, nil
+}
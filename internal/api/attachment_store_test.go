@@ -0,0 +1,65 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStoreAttachment_ContentAddressed(t *testing.T) {
+	content := []byte("quarterly report\n")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write(content)
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL, "token", 1)
+	dir := t.TempDir()
+	sum := sha256.Sum256(content)
+	wantSHA := hex.EncodeToString(sum[:])
+
+	first, err := client.StoreAttachment(context.Background(), server.URL+"/files/report.txt", dir, 0)
+	if err != nil {
+		t.Fatalf("StoreAttachment returned error: %v", err)
+	}
+	if first.SHA256 != wantSHA || first.Path != wantSHA[:2]+"/"+wantSHA || first.Bytes != int64(len(content)) {
+		t.Fatalf("unexpected stored attachment: %+v", first)
+	}
+	if first.Name != "report.txt" || first.MIMEType != "text/plain" {
+		t.Fatalf("unexpected name/mime: %+v", first)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, first.Path))
+	if err != nil || string(data) != string(content) {
+		t.Fatalf("stored file = %q, %v", data, err)
+	}
+
+	// The same content under another URL is stored once.
+	second, err := client.StoreAttachment(context.Background(), server.URL+"/files/copy.txt", dir, 0)
+	if err != nil {
+		t.Fatalf("StoreAttachment returned error: %v", err)
+	}
+	if second.Path != first.Path {
+		t.Fatalf("expected shared path, got %q and %q", first.Path, second.Path)
+	}
+	var files []string
+	_ = filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if len(files) != 1 {
+		t.Fatalf("expected one stored file, got %v", files)
+	}
+
+	if _, err := client.StoreAttachment(context.Background(), server.URL+"/files/report.txt", dir, 4); err == nil || !strings.Contains(err.Error(), "exceeds") {
+		t.Fatalf("expected size limit error, got %v", err)
+	}
+}
@@ -0,0 +1,451 @@
+package api
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Cassettes capture API traffic as one JSON file per request/response pair.
+// Set CW_RECORD=dir to record every request the client makes, or
+// CW_REPLAY=dir to answer requests from a recording without a server.
+const (
+	RecordDirEnv = "CW_RECORD"
+	ReplayDirEnv = "CW_REPLAY"
+)
+
+// redacted replaces credentials in recorded requests and responses.
+const redacted = "REDACTED"
+
+// sensitiveHeaders are never written to a cassette.
+var sensitiveHeaders = []string{"api_access_token", "Authorization", "Cookie", "Set-Cookie", "Proxy-Authorization"}
+
+// sensitiveFields are JSON body fields and query parameters whose values are
+// redacted.
+var sensitiveFields = map[string]bool{
+	"access_token":     true,
+	"api_access_token": true,
+	"pubsub_token":     true,
+	"hmac_token":       true,
+	"token":            true,
+	"password":         true,
+	"secret":           true,
+}
+
+// Interaction is one recorded request and its response.
+type Interaction struct {
+	Request    RecordedRequest  `json:"request"`
+	Response   RecordedResponse `json:"response"`
+	RecordedAt time.Time        `json:"recorded_at"`
+}
+
+// RecordedRequest is a request as stored in a cassette. URL has no scheme or
+// host, so a recording replays against any base URL.
+type RecordedRequest struct {
+	Method       string          `json:"method"`
+	URL          string          `json:"url"`
+	Headers      http.Header     `json:"headers,omitempty"`
+	Body         json.RawMessage `json:"body,omitempty"`
+	BodyEncoding string          `json:"body_encoding,omitempty"`
+}
+
+// RecordedResponse is a response as stored in a cassette.
+type RecordedResponse struct {
+	Status       int             `json:"status"`
+	Headers      http.Header     `json:"headers,omitempty"`
+	Body         json.RawMessage `json:"body,omitempty"`
+	BodyEncoding string          `json:"body_encoding,omitempty"`
+}
+
+// encodeBody keeps JSON bodies readable in the file; other bodies are stored
+// as a "text" string or, if not valid UTF-8, as "base64".
+func encodeBody(data []byte) (body json.RawMessage, encoding string) {
+	if len(data) == 0 {
+		return nil, ""
+	}
+	var buf bytes.Buffer
+	if json.Valid(data) && json.Compact(&buf, data) == nil {
+		return buf.Bytes(), ""
+	}
+	encoding, text := "text", string(data)
+	if !utf8.Valid(data) || bytes.IndexByte(data, 0) >= 0 {
+		encoding, text = "base64", base64.StdEncoding.EncodeToString(data)
+	}
+	body, _ = json.Marshal(text)
+	return body, encoding
+}
+
+func decodeBody(body json.RawMessage, encoding string) ([]byte, error) {
+	switch encoding {
+	case "":
+		return body, nil
+	case "text", "base64":
+		var s string
+		if err := json.Unmarshal(body, &s); err != nil {
+			return nil, err
+		}
+		if encoding == "base64" {
+			return base64.StdEncoding.DecodeString(s)
+		}
+		return []byte(s), nil
+	}
+	return nil, fmt.Errorf("unknown body_encoding %q", encoding)
+}
+
+// cassetteTransport wraps the client's transport according to CW_REPLAY and
+// CW_RECORD. Replay takes precedence; it never touches the network.
+func cassetteTransport(next http.RoundTripper) (rt http.RoundTripper, replaying bool) {
+	if dir := os.Getenv(ReplayDirEnv); dir != "" {
+		return NewCassettePlayer(dir), true
+	}
+	if dir := os.Getenv(RecordDirEnv); dir != "" {
+		return NewCassetteRecorder(dir, next), false
+	}
+	return next, false
+}
+
+// CassetteRecorder is an http.RoundTripper that saves every request and
+// response it passes through, with credentials redacted.
+type CassetteRecorder struct {
+	dir  string
+	next http.RoundTripper
+
+	mu  sync.Mutex
+	seq int
+}
+
+// NewCassetteRecorder records the traffic of next into dir, creating it if
+// needed. Recordings are appended after any already in dir.
+func NewCassetteRecorder(dir string, next http.RoundTripper) *CassetteRecorder {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &CassetteRecorder{dir: dir, next: next}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *CassetteRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	if err != nil {
+		return nil, err
+	}
+
+	it := Interaction{
+		Request: RecordedRequest{
+			Method:  req.Method,
+			URL:     redactURL(req.URL),
+			Headers: redactHeaders(req.Header),
+		},
+		Response: RecordedResponse{
+			Status:  resp.StatusCode,
+			Headers: redactHeaders(resp.Header),
+		},
+		RecordedAt: time.Now().UTC(),
+	}
+	it.Request.Body, it.Request.BodyEncoding = encodeBody(redactBody(reqBody, req.Header.Get("Content-Type")))
+	it.Response.Body, it.Response.BodyEncoding = encodeBody(redactBody(respBody, resp.Header.Get("Content-Type")))
+	// The stored body is reformatted, so its original length no longer applies.
+	it.Response.Headers.Del("Content-Length")
+	if err := r.save(it); err != nil {
+		return nil, fmt.Errorf("record cassette: %w", err)
+	}
+	return resp, nil
+}
+
+var slugRE = regexp.MustCompile(`[^a-zA-Z0-9]+`)
+
+func (r *CassetteRecorder) save(it Interaction) error {
+	data, err := json.MarshalIndent(it, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.seq == 0 {
+		if err := os.MkdirAll(r.dir, 0o700); err != nil {
+			return err
+		}
+		files, err := cassetteFiles(r.dir)
+		if err != nil {
+			return err
+		}
+		if len(files) > 0 {
+			r.seq = cassetteSeq(files[len(files)-1])
+		}
+	}
+	path, _, _ := strings.Cut(it.Request.URL, "?")
+	slug := strings.Trim(slugRE.ReplaceAllString(path, "-"), "-")
+	if len(slug) > 60 {
+		slug = slug[len(slug)-60:]
+	}
+	// Another process may be recording into the same directory.
+	for {
+		r.seq++
+		name := filepath.Join(r.dir, fmt.Sprintf("%04d-%s-%s.json", r.seq, it.Request.Method, slug))
+		f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(data); err != nil {
+			_ = f.Close()
+			return err
+		}
+		return f.Close()
+	}
+}
+
+// CassettePlayer is an http.RoundTripper that answers requests from a
+// recording. Requests match on method, path, query (in any order) and body
+// (JSON compared by value). Identical requests get their recorded responses
+// in order, the last one repeating; a request with no recording fails.
+type CassettePlayer struct {
+	dir string
+
+	once  sync.Once
+	err   error
+	mu    sync.Mutex
+	byKey map[string][]RecordedResponse
+	used  map[string]int
+}
+
+// NewCassettePlayer replays the recording in dir. It is read on the first
+// request.
+func NewCassettePlayer(dir string) *CassettePlayer {
+	return &CassettePlayer{dir: dir}
+}
+
+func (p *CassettePlayer) load() {
+	files, err := cassetteFiles(p.dir)
+	if err != nil {
+		p.err = err
+		return
+	}
+	if len(files) == 0 {
+		p.err = fmt.Errorf("no recordings in %s", p.dir)
+		return
+	}
+	p.byKey = map[string][]RecordedResponse{}
+	p.used = map[string]int{}
+	for _, name := range files {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			p.err = err
+			return
+		}
+		var it Interaction
+		if err := json.Unmarshal(data, &it); err != nil {
+			p.err = fmt.Errorf("%s: %w", name, err)
+			return
+		}
+		body, err := decodeBody(it.Request.Body, it.Request.BodyEncoding)
+		if err != nil {
+			p.err = fmt.Errorf("%s: request body: %w", name, err)
+			return
+		}
+		u, err := url.Parse(it.Request.URL)
+		if err != nil {
+			p.err = fmt.Errorf("%s: %w", name, err)
+			return
+		}
+		key := cassetteKey(it.Request.Method, u, body, it.Request.Headers.Get("Content-Type"))
+		p.byKey[key] = append(p.byKey[key], it.Response)
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (p *CassettePlayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	p.once.Do(p.load)
+	if p.err != nil {
+		return nil, fmt.Errorf("replay cassette: %w", p.err)
+	}
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	contentType := req.Header.Get("Content-Type")
+	u, _ := url.Parse(redactURL(req.URL))
+	key := cassetteKey(req.Method, u, redactBody(body, contentType), contentType)
+
+	p.mu.Lock()
+	responses := p.byKey[key]
+	n := p.used[key]
+	p.used[key]++
+	p.mu.Unlock()
+	if len(responses) == 0 {
+		return nil, fmt.Errorf("replay cassette: no recorded response for %s %s", req.Method, redactURL(req.URL))
+	}
+	rec := responses[min(n, len(responses)-1)]
+
+	respBody, err := decodeBody(rec.Body, rec.BodyEncoding)
+	if err != nil {
+		return nil, fmt.Errorf("replay cassette: response body: %w", err)
+	}
+	header := rec.Headers.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rec.Status, http.StatusText(rec.Status)),
+		StatusCode:    rec.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(respBody)),
+		ContentLength: int64(len(respBody)),
+		Request:       req,
+	}, nil
+}
+
+// cassetteFiles lists the recordings in dir in recording order, by their
+// sequence number prefix.
+func cassetteFiles(dir string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		if a, b := cassetteSeq(files[i]), cassetteSeq(files[j]); a != b {
+			return a < b
+		}
+		return files[i] < files[j]
+	})
+	return files, nil
+}
+
+func cassetteSeq(name string) int {
+	prefix, _, _ := strings.Cut(filepath.Base(name), "-")
+	n, _ := strconv.Atoi(prefix)
+	return n
+}
+
+// cassetteKey identifies a request for matching. The body is compared by
+// value when it is JSON, and with its boundary removed when multipart.
+func cassetteKey(method string, u *url.URL, body []byte, contentType string) string {
+	query := u.Query().Encode() // sorted by key
+	var v any
+	if len(body) > 0 && json.Unmarshal(body, &v) == nil {
+		body, _ = json.Marshal(v)
+	} else if mediaType, params, err := mime.ParseMediaType(contentType); err == nil && strings.HasPrefix(mediaType, "multipart/") {
+		body = bytes.ReplaceAll(body, []byte(params["boundary"]), []byte("BOUNDARY"))
+	}
+	return method + " " + u.EscapedPath() + "?" + query + "\n" + string(body)
+}
+
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+	data, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(data))
+	return data, nil
+}
+
+// redactURL returns the path and query of u with sensitive parameters
+// redacted.
+func redactURL(u *url.URL) string {
+	q := u.Query()
+	for key := range q {
+		if sensitiveFields[strings.ToLower(key)] {
+			q[key] = []string{redacted}
+		}
+	}
+	out := u.EscapedPath()
+	if len(q) > 0 {
+		out += "?" + q.Encode()
+	}
+	return out
+}
+
+func redactHeaders(h http.Header) http.Header {
+	out := h.Clone()
+	for _, name := range sensitiveHeaders {
+		if out.Values(name) != nil {
+			out.Set(name, redacted)
+		}
+	}
+	return out
+}
+
+// redactBody redacts sensitive fields of a JSON body; other bodies are
+// returned unchanged.
+func redactBody(body []byte, contentType string) []byte {
+	if len(body) == 0 || (contentType != "" && !strings.Contains(contentType, "json")) {
+		return body
+	}
+	var v any
+	if json.Unmarshal(body, &v) != nil {
+		return body
+	}
+	if !redactValue(v) {
+		return body
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		return body
+	}
+	return out
+}
+
+// redactValue redacts sensitive fields in place, reporting whether any were
+// found.
+func redactValue(v any) bool {
+	found := false
+	switch v := v.(type) {
+	case map[string]any:
+		for key, val := range v {
+			if sensitiveFields[strings.ToLower(key)] {
+				if s, ok := val.(string); ok && s != "" {
+					v[key] = redacted
+					found = true
+				}
+				continue
+			}
+			if redactValue(val) {
+				found = true
+			}
+		}
+	case []any:
+		for _, val := range v {
+			if redactValue(val) {
+				found = true
+			}
+		}
+	}
+	return found
+}
@@ -0,0 +1,209 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCassetteRecordAndReplay(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/api/v1/profile":
+			_, _ = w.Write([]byte(`{"id": 1, "name": "Ann", "access_token": "user-secret", "pubsub_token": "pubsub-secret"}`))
+		case r.URL.Path == "/api/v1/accounts/1/conversations":
+			_, _ = w.Write([]byte(`{"data": {"meta": {"all_count": 1}, "payload": [{"id": 7, "status": "open"}]}}`))
+		case r.URL.Path == "/api/v1/accounts/1/conversations/7/messages" && r.Method == http.MethodPost:
+			body, _ := io.ReadAll(r.Body)
+			var req map[string]any
+			_ = json.Unmarshal(body, &req)
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": 99, "content": req["content"]})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error": "Resource could not be found"}`))
+		}
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	t.Setenv(RecordDirEnv, dir)
+	rec := newTestClient(server.URL, "test-token", 1)
+	if _, err := rec.Profile().Get(ctx); err != nil {
+		t.Fatalf("Profile: %v", err)
+	}
+	if _, err := rec.Conversations().List(ctx, ListConversationsParams{Status: "open", InboxID: "2"}); err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if _, err := rec.Messages().Create(ctx, 7, "hello", false, "outgoing"); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err := rec.Conversations().Get(ctx, 404); err == nil {
+		t.Fatal("expected 404")
+	}
+
+	files, err := cassetteFiles(dir)
+	if err != nil || len(files) != 4 {
+		t.Fatalf("recorded %d files (%v), want 4", len(files), err)
+	}
+	for _, f := range files {
+		data, _ := os.ReadFile(f)
+		for _, secret := range []string{"test-token", "user-secret", "pubsub-secret"} {
+			if strings.Contains(string(data), secret) {
+				t.Errorf("%s contains %q:\n%s", filepath.Base(f), secret, data)
+			}
+		}
+	}
+	if !strings.HasSuffix(files[0], "0001-GET-api-v1-profile.json") {
+		t.Errorf("first file = %s", filepath.Base(files[0]))
+	}
+
+	// Replay against an unreachable host; no request may reach the server.
+	t.Setenv(RecordDirEnv, "")
+	t.Setenv(ReplayDirEnv, dir)
+	callsBefore := calls
+	replay := New("https://chatwoot.invalid", "other-token", 1)
+
+	profile, err := replay.Profile().Get(ctx)
+	if err != nil {
+		t.Fatalf("replay Profile: %v", err)
+	}
+	if profile.Name != "Ann" || profile.PubsubToken != redacted {
+		t.Errorf("profile = %+v", profile)
+	}
+	list, err := replay.Conversations().List(ctx, ListConversationsParams{InboxID: "2", Status: "open"})
+	if err != nil {
+		t.Fatalf("replay List: %v", err)
+	}
+	if len(list.Data.Payload) != 1 || list.Data.Payload[0].ID != 7 {
+		t.Errorf("conversations = %+v", list.Data.Payload)
+	}
+	msg, err := replay.Messages().Create(ctx, 7, "hello", false, "outgoing")
+	if err != nil {
+		t.Fatalf("replay Create: %v", err)
+	}
+	if msg.ID != 99 {
+		t.Errorf("message id = %d, want 99", msg.ID)
+	}
+	var apiErr *APIError
+	if _, err := replay.Conversations().Get(ctx, 404); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("replay 404: err = %v", err)
+	}
+
+	// A different body, query or path has no recording.
+	for name, call := range map[string]func() error{
+		"body": func() error {
+			_, err := replay.Messages().Create(ctx, 7, "goodbye", false, "outgoing")
+			return err
+		},
+		"query": func() error {
+			_, err := replay.Conversations().List(ctx, ListConversationsParams{Status: "resolved", InboxID: "2"})
+			return err
+		},
+		"path": func() error {
+			_, err := replay.Conversations().Get(ctx, 8)
+			return err
+		},
+	} {
+		if err := call(); err == nil || !strings.Contains(err.Error(), "no recorded response") {
+			t.Errorf("unmatched %s: err = %v", name, err)
+		}
+	}
+	if calls != callsBefore {
+		t.Errorf("replay made %d requests to the server", calls-callsBefore)
+	}
+}
+
+func TestCassettePlayerServesRepeatsInOrder(t *testing.T) {
+	dir := t.TempDir()
+	for i, status := range []string{"open", "resolved"} {
+		it := Interaction{
+			Request:  RecordedRequest{Method: http.MethodGet, URL: "/api/v1/accounts/1/conversations/7"},
+			Response: RecordedResponse{Status: http.StatusOK, Body: json.RawMessage(`{"id": 7, "status": "` + status + `"}`)},
+		}
+		data, _ := json.Marshal(it)
+		name := filepath.Join(dir, []string{"0001-GET-a.json", "0002-GET-a.json"}[i])
+		if err := os.WriteFile(name, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv(ReplayDirEnv, dir)
+	client := New("https://chatwoot.invalid", "", 1)
+
+	for _, want := range []string{"open", "resolved", "resolved"} {
+		conv, err := client.Conversations().Get(context.Background(), 7)
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		if conv.Status != want {
+			t.Errorf("status = %q, want %q", conv.Status, want)
+		}
+	}
+}
+
+func TestCassettePlayerEmptyDir(t *testing.T) {
+	t.Setenv(ReplayDirEnv, t.TempDir())
+	client := New("https://chatwoot.invalid", "", 1)
+	if _, err := client.Conversations().Get(context.Background(), 1); err == nil || !strings.Contains(err.Error(), "no recordings") {
+		t.Errorf("err = %v, want no recordings", err)
+	}
+}
+
+func TestCassetteBodyEncoding(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		encoding string
+	}{
+		{"json", []byte(`{"a": 1}`), ""},
+		{"text", []byte("plain text"), "text"},
+		{"binary", []byte{0x89, 'P', 'N', 'G', 0, 0xff}, "base64"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, enc := encodeBody(tt.data)
+			if enc != tt.encoding {
+				t.Errorf("encoding = %q, want %q", enc, tt.encoding)
+			}
+			got, err := decodeBody(body, enc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.encoding == "" {
+				if string(got) != `{"a":1}` {
+					t.Errorf("decoded = %s", got)
+				}
+			} else if string(got) != string(tt.data) {
+				t.Errorf("decoded = %q, want %q", got, tt.data)
+			}
+		})
+	}
+}
+
+func TestCassetteKeyNormalizesQueryAndJSONBody(t *testing.T) {
+	a, _ := http.NewRequest(http.MethodPost, "https://x.test/p?b=2&a=1", nil)
+	b, _ := http.NewRequest(http.MethodPost, "https://y.test/p?a=1&b=2", nil)
+	keyA := cassetteKey(a.Method, a.URL, []byte(`{"x": 1, "y": [1, 2]}`), "application/json")
+	keyB := cassetteKey(b.Method, b.URL, []byte(`{"y":[1,2],"x":1}`), "application/json")
+	if keyA != keyB {
+		t.Errorf("keys differ:\n%s\n%s", keyA, keyB)
+	}
+
+	multipart := func(boundary string) string {
+		return cassetteKey(http.MethodPost, a.URL, []byte("--"+boundary+"\r\ncontent\r\n--"+boundary+"--"), "multipart/form-data; boundary="+boundary)
+	}
+	if multipart("abc123") != multipart("xyz789") {
+		t.Error("multipart keys should not depend on the boundary")
+	}
+}
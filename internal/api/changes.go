@@ -0,0 +1,357 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Resources a Change can describe.
+const (
+	ResourceConversation = "conversation"
+	ResourceContact      = "contact"
+)
+
+// Change is the state of one conversation or contact just before and just
+// after a mutation. Before and After hold only the fields the mutation set,
+// keyed by their API names: status, snoozed_until, priority, assignee_id,
+// team_id, labels and custom_attributes on conversations; name, email,
+// phone_number, identifier, labels and "custom_attributes.<key>" or
+// "additional_attributes.<key>" on contacts.
+type Change struct {
+	Resource string         `json:"resource"`
+	ID       int            `json:"id"`
+	Before   map[string]any `json:"before"`
+	After    map[string]any `json:"after"`
+}
+
+// Fields returns the names of the fields the change set, sorted.
+func (ch Change) Fields() []string {
+	fields := make([]string, 0, len(ch.After))
+	for f := range ch.After {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// Conflicts returns the fields whose current value differs from the value
+// the change set, i.e. fields someone changed again since.
+func (ch Change) Conflicts(current map[string]any) []string {
+	var changed []string
+	for _, f := range ch.Fields() {
+		if !sameValue(f, ch.After[f], current[f]) {
+			changed = append(changed, f)
+		}
+	}
+	return changed
+}
+
+// Without returns a copy of the change that leaves fields alone. Dropping
+// the status also drops the snooze time recorded with it.
+func (ch Change) Without(fields []string) Change {
+	out := Change{Resource: ch.Resource, ID: ch.ID, Before: map[string]any{}, After: map[string]any{}}
+	for f, v := range ch.Before {
+		out.Before[f] = v
+	}
+	for f, v := range ch.After {
+		out.After[f] = v
+	}
+	for _, f := range fields {
+		delete(out.Before, f)
+		delete(out.After, f)
+		if f == "status" {
+			delete(out.Before, "snoozed_until")
+		}
+	}
+	return out
+}
+
+// ChangeRecorder receives every change made through a Client's
+// conversation and contact mutations, with the prior state fetched just
+// before. It must be safe for concurrent use.
+type ChangeRecorder interface {
+	RecordChange(Change)
+	// SkipChange reports a change that was made but not recorded because
+	// its prior state could not be fetched.
+	SkipChange(resource string, id int, err error)
+}
+
+// track runs mutate and, when the client has a ChangeRecorder, records the
+// fields it set along with their values fetched just before. mutate returns
+// the new values. Nothing is recorded when the mutation fails. Recording is
+// best effort: if the prior state cannot be fetched, the mutation still runs
+// and the recorder is told the change was skipped.
+func (c *Client) track(ctx context.Context, resource string, id int, fields []string, mutate func() (map[string]any, error)) error {
+	if c.Changes == nil || len(fields) == 0 {
+		_, err := mutate()
+		return err
+	}
+	before, stateErr := c.State(ctx, resource, id, fields)
+	after, err := mutate()
+	if err != nil {
+		return err
+	}
+	if stateErr != nil {
+		c.Changes.SkipChange(resource, id, stateErr)
+		return nil
+	}
+	c.Changes.RecordChange(Change{Resource: resource, ID: id, Before: before, After: normalizeState(after)})
+	return nil
+}
+
+// State fetches the current values of fields on a conversation or contact,
+// in the form Change records them.
+func (c *Client) State(ctx context.Context, resource string, id int, fields []string) (map[string]any, error) {
+	state := make(map[string]any, len(fields))
+	var obj map[string]any
+	for _, f := range fields {
+		if f == "labels" {
+			var labels []string
+			var err error
+			if resource == ResourceContact {
+				labels, err = getContactLabels(ctx, c, id)
+			} else {
+				labels, err = getConversationLabels(ctx, c, id)
+			}
+			if err != nil {
+				return nil, err
+			}
+			state[f] = labels
+			continue
+		}
+		if obj == nil {
+			var err error
+			if obj, err = c.rawResource(ctx, resource, id); err != nil {
+				return nil, err
+			}
+		}
+		state[f] = fieldValue(obj, f)
+	}
+	return normalizeState(state), nil
+}
+
+func (c *Client) rawResource(ctx context.Context, resource string, id int) (map[string]any, error) {
+	switch resource {
+	case ResourceConversation:
+		var obj map[string]any
+		if err := c.do(ctx, http.MethodGet, c.accountPath(fmt.Sprintf("/conversations/%d", id)), nil, &obj); err != nil {
+			return nil, err
+		}
+		return obj, nil
+	case ResourceContact:
+		var result struct {
+			Payload map[string]any `json:"payload"`
+		}
+		if err := c.do(ctx, http.MethodGet, c.accountPath(fmt.Sprintf("/contacts/%d", id)), nil, &result); err != nil {
+			return nil, err
+		}
+		return result.Payload, nil
+	}
+	return nil, fmt.Errorf("unknown resource %q", resource)
+}
+
+// fieldValue reads a field from a conversation or contact object. Dotted
+// names address keys of custom_attributes and additional_attributes; the
+// assignee and team fall back to the meta block, where Chatwoot's
+// conversation payloads carry them.
+func fieldValue(obj map[string]any, field string) any {
+	if parent, key, ok := strings.Cut(field, "."); ok {
+		nested, _ := obj[parent].(map[string]any)
+		return nested[key]
+	}
+	if v, ok := obj[field]; ok && v != nil {
+		return v
+	}
+	meta, _ := obj["meta"].(map[string]any)
+	switch field {
+	case "assignee_id":
+		assignee, _ := meta["assignee"].(map[string]any)
+		return assignee["id"]
+	case "team_id":
+		team, _ := meta["team"].(map[string]any)
+		return team["id"]
+	}
+	return nil
+}
+
+// Restore sets the fields of ch back to their Before values. Restores are
+// tracked like any other change, so they can be undone in turn.
+func (c *Client) Restore(ctx context.Context, ch Change) error {
+	switch ch.Resource {
+	case ResourceConversation:
+		return c.restoreConversation(ctx, ch.ID, ch.Before)
+	case ResourceContact:
+		return c.restoreContact(ctx, ch.ID, ch.Before)
+	}
+	return fmt.Errorf("unknown resource %q", ch.Resource)
+}
+
+func (c *Client) restoreConversation(ctx context.Context, id int, before map[string]any) error {
+	conversations := c.Conversations()
+	if v, ok := before["labels"]; ok {
+		if _, err := conversations.AddLabels(ctx, id, stringList(v)); err != nil {
+			return err
+		}
+	}
+	if v, ok := before["status"]; ok {
+		status, _ := v.(string)
+		if _, err := conversations.ToggleStatus(ctx, id, status, unixTime(before["snoozed_until"])); err != nil {
+			return err
+		}
+	}
+	if v, ok := before["priority"]; ok {
+		priority, _ := v.(string)
+		if priority == "" {
+			priority = "none"
+		}
+		if err := conversations.TogglePriority(ctx, id, priority); err != nil {
+			return err
+		}
+	}
+	for _, field := range []string{"team_id", "assignee_id"} {
+		v, ok := before[field]
+		if !ok {
+			continue
+		}
+		err := c.track(ctx, ResourceConversation, id, []string{field}, func() (map[string]any, error) {
+			payload := map[string]any{field: v}
+			return payload, c.do(ctx, http.MethodPost, c.accountPath(fmt.Sprintf("/conversations/%d/assignments", id)), payload, nil)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if v, ok := before["custom_attributes"]; ok {
+		attrs, _ := v.(map[string]any)
+		if attrs == nil {
+			attrs = map[string]any{}
+		}
+		if err := conversations.UpdateCustomAttributes(ctx, id, attrs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Client) restoreContact(ctx context.Context, id int, before map[string]any) error {
+	body := map[string]any{}
+	for field, v := range before {
+		if field == "labels" {
+			continue
+		}
+		if parent, key, ok := strings.Cut(field, "."); ok {
+			nested, _ := body[parent].(map[string]any)
+			if nested == nil {
+				nested = map[string]any{}
+				body[parent] = nested
+			}
+			nested[key] = v
+			continue
+		}
+		body[field] = v
+	}
+	if len(body) > 0 {
+		if _, err := c.Contacts().UpdateFromMap(ctx, id, body); err != nil {
+			return err
+		}
+	}
+	if v, ok := before["labels"]; ok {
+		if _, err := c.Contacts().AddLabels(ctx, id, stringList(v)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// contactFields returns the fields a contact update body sets, with the
+// keys of custom_attributes and additional_attributes as dotted names since
+// Chatwoot merges those objects rather than replacing them.
+func contactFields(body map[string]any) map[string]any {
+	fields := make(map[string]any, len(body))
+	for k, v := range body {
+		if nested, ok := v.(map[string]any); ok && (k == "custom_attributes" || k == "additional_attributes") {
+			for nk, nv := range nested {
+				fields[k+"."+nk] = nv
+			}
+			continue
+		}
+		fields[k] = v
+	}
+	return fields
+}
+
+func fieldNames(values map[string]any) []string {
+	names := make([]string, 0, len(values))
+	for k := range values {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// normalizeState round-trips values through JSON so recorded and fetched
+// states compare equal regardless of the Go types they were built from.
+func normalizeState(state map[string]any) map[string]any {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return state
+	}
+	out := map[string]any{}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return state
+	}
+	for f, v := range out {
+		if f == "labels" && v == nil {
+			out[f] = []any{}
+		}
+	}
+	return out
+}
+
+// sameValue compares two normalized field values. Labels compare as sets.
+func sameValue(field string, a, b any) bool {
+	if field == "labels" {
+		x, y := stringList(a), stringList(b)
+		slices.Sort(x)
+		slices.Sort(y)
+		return slices.Equal(x, y)
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func stringList(v any) []string {
+	switch list := v.(type) {
+	case []string:
+		return list
+	case []any:
+		out := make([]string, 0, len(list))
+		for _, item := range list {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return []string{}
+}
+
+// unixTime converts a recorded timestamp, either Unix seconds or an RFC 3339
+// string, to Unix seconds. It returns 0 when v is neither.
+func unixTime(v any) int64 {
+	switch t := v.(type) {
+	case float64:
+		return int64(t)
+	case string:
+		if parsed, err := time.Parse(time.RFC3339, t); err == nil {
+			return parsed.Unix()
+		}
+	}
+	return 0
+}
@@ -0,0 +1,238 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type changeLog struct {
+	mu      sync.Mutex
+	changes []Change
+	skipped []error
+}
+
+func (l *changeLog) SkipChange(_ string, _ int, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.skipped = append(l.skipped, err)
+}
+
+func (l *changeLog) RecordChange(ch Change) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.changes = append(l.changes, ch)
+}
+
+// fakeState serves a conversation and a contact and logs the writes it gets.
+type fakeState struct {
+	mu     sync.Mutex
+	conv   map[string]any
+	labels []string
+	writes []string
+}
+
+func (f *fakeState) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var body map[string]any
+	if data, _ := io.ReadAll(r.Body); len(data) > 0 {
+		_ = json.Unmarshal(data, &body)
+		f.writes = append(f.writes, r.Method+" "+strings.TrimPrefix(r.URL.Path, "/api/v1/accounts/1")+" "+string(data))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	switch r.Method + " " + r.URL.Path {
+	case "GET /api/v1/accounts/1/conversations/7":
+		_ = json.NewEncoder(w).Encode(f.conv)
+	case "GET /api/v1/accounts/1/conversations/7/labels":
+		_ = json.NewEncoder(w).Encode(map[string]any{"payload": f.labels})
+	case "POST /api/v1/accounts/1/conversations/7/labels":
+		f.labels = stringList(body["labels"])
+		_ = json.NewEncoder(w).Encode(map[string]any{"payload": f.labels})
+	case "POST /api/v1/accounts/1/conversations/7/toggle_status":
+		f.conv["status"] = body["status"]
+		_ = json.NewEncoder(w).Encode(map[string]any{"payload": map[string]any{"success": true, "current_status": body["status"]}})
+	case "POST /api/v1/accounts/1/conversations/7/assignments":
+		for k, v := range body {
+			f.conv[k] = v
+		}
+		_, _ = w.Write([]byte(`{}`))
+	case "POST /api/v1/accounts/1/conversations/8/toggle_status":
+		// Conversation 8 can be changed but not read back.
+		_ = json.NewEncoder(w).Encode(map[string]any{"payload": map[string]any{"success": true, "current_status": body["status"]}})
+	case "GET /api/v1/accounts/1/contacts/3":
+		_, _ = w.Write([]byte(`{"payload": {"id": 3, "name": "Jane", "custom_attributes": {"plan": "free"}}}`))
+	case "PATCH /api/v1/accounts/1/contacts/3":
+		_, _ = w.Write([]byte(`{"payload": {"id": 3}}`))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error": "not found"}`))
+	}
+}
+
+func newChangeTestClient(t *testing.T, f *fakeState) (*Client, *changeLog) {
+	t.Helper()
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+	client := newTestClient(server.URL, "test-token", 1)
+	log := &changeLog{}
+	client.Changes = log
+	return client, log
+}
+
+func TestMutationsRecordPriorState(t *testing.T) {
+	f := &fakeState{
+		conv:   map[string]any{"id": 7, "status": "open", "meta": map[string]any{"assignee": map[string]any{"id": 4}}},
+		labels: []string{"billing"},
+	}
+	client, log := newChangeTestClient(t, f)
+	ctx := context.Background()
+
+	if _, err := client.Conversations().ToggleStatus(ctx, 7, "resolved", 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Conversations().AddLabels(ctx, 7, []string{"billing", "refund"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Conversations().Assign(ctx, 7, 9, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Contacts().UpdateWithOpts(ctx, 3, UpdateContactOpts{Name: "Jane Doe", CustomAttributes: map[string]any{"plan": "pro"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Change{
+		{Resource: ResourceConversation, ID: 7, Before: map[string]any{"status": "open", "snoozed_until": nil}, After: map[string]any{"status": "resolved"}},
+		{Resource: ResourceConversation, ID: 7, Before: map[string]any{"labels": []any{"billing"}}, After: map[string]any{"labels": []any{"billing", "refund"}}},
+		{Resource: ResourceConversation, ID: 7, Before: map[string]any{"assignee_id": float64(4)}, After: map[string]any{"assignee_id": float64(9)}},
+		{Resource: ResourceContact, ID: 3, Before: map[string]any{"name": "Jane", "custom_attributes.plan": "free"}, After: map[string]any{"name": "Jane Doe", "custom_attributes.plan": "pro"}},
+	}
+	if !reflect.DeepEqual(log.changes, want) {
+		t.Errorf("changes =\n%#v\nwant\n%#v", log.changes, want)
+	}
+}
+
+func TestAddLabelsRecordsReturnedLabels(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/accounts/1/conversations/7/labels", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"payload": ["billing"]}`))
+	})
+	mux.HandleFunc("POST /api/v1/accounts/1/conversations/7/labels", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"payload": ["billing", "refund"]}`))
+	})
+	mux.HandleFunc("GET /api/v1/accounts/1/contacts/3/labels", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"labels": ["vip"]}`))
+	})
+	mux.HandleFunc("POST /api/v1/accounts/1/contacts/3/labels", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"labels": ["lead", "vip"]}`))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	client := newTestClient(server.URL, "test-token", 1)
+	log := &changeLog{}
+	client.Changes = log
+
+	if _, err := client.Conversations().AddLabels(context.Background(), 7, []string{"refund"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Contacts().AddLabels(context.Background(), 3, []string{"lead"}); err != nil {
+		t.Fatal(err)
+	}
+	want := []map[string]any{
+		{"labels": []any{"billing", "refund"}},
+		{"labels": []any{"lead", "vip"}},
+	}
+	if len(log.changes) != 2 || !reflect.DeepEqual(log.changes[0].After, want[0]) || !reflect.DeepEqual(log.changes[1].After, want[1]) {
+		t.Errorf("changes = %+v, want After %v", log.changes, want)
+	}
+}
+
+func TestFailedMutationRecordsNothing(t *testing.T) {
+	client, log := newChangeTestClient(t, &fakeState{conv: map[string]any{"id": 7}})
+	if err := client.Conversations().TogglePriority(context.Background(), 7, "high"); err == nil {
+		t.Fatal("expected the unserved toggle_priority to fail")
+	}
+	if len(log.changes) != 0 {
+		t.Errorf("recorded %+v", log.changes)
+	}
+
+	// Without a recorder no prior state is fetched.
+	client.Changes = nil
+	if _, err := client.Contacts().Update(context.Background(), 404, "x", "", ""); err == nil || strings.Contains(err.Error(), "before changing") {
+		t.Errorf("err = %v, want the PATCH error", err)
+	}
+}
+
+func TestUnreadablePriorStateStillMutates(t *testing.T) {
+	f := &fakeState{conv: map[string]any{"id": 7}}
+	client, log := newChangeTestClient(t, f)
+	if _, err := client.Conversations().ToggleStatus(context.Background(), 8, "resolved", 0); err != nil {
+		t.Fatalf("mutation failed: %v", err)
+	}
+	if len(f.writes) != 1 || !strings.HasPrefix(f.writes[0], "POST /conversations/8/toggle_status") {
+		t.Errorf("writes = %v", f.writes)
+	}
+	if len(log.changes) != 0 || len(log.skipped) != 1 {
+		t.Errorf("changes = %+v, skipped = %v; want one skipped change", log.changes, log.skipped)
+	}
+}
+
+func TestRestoreConversation(t *testing.T) {
+	f := &fakeState{
+		conv:   map[string]any{"id": 7, "status": "resolved", "assignee_id": 9, "team_id": 2},
+		labels: []string{"oops"},
+	}
+	client, log := newChangeTestClient(t, f)
+
+	ch := Change{
+		Resource: ResourceConversation,
+		ID:       7,
+		Before:   map[string]any{"labels": []any{"billing"}, "status": "snoozed", "snoozed_until": "2026-10-17T09:00:00Z", "assignee_id": nil},
+		After:    map[string]any{"labels": []any{"oops"}, "status": "resolved", "assignee_id": float64(9)},
+	}
+	if err := client.Restore(context.Background(), ch); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	wantWrites := []string{
+		`POST /conversations/7/labels {"labels":["billing"]}`,
+		`POST /conversations/7/toggle_status {"snoozed_until":1792227600,"status":"snoozed"}`,
+		`POST /conversations/7/assignments {"assignee_id":null}`,
+	}
+	if !reflect.DeepEqual(f.writes, wantWrites) {
+		t.Errorf("writes =\n%s\nwant\n%s", strings.Join(f.writes, "\n"), strings.Join(wantWrites, "\n"))
+	}
+	// The restore is recorded too, so it can be undone.
+	if len(log.changes) != 3 || log.changes[2].Before["assignee_id"] != float64(9) {
+		t.Errorf("restore changes = %+v", log.changes)
+	}
+}
+
+func TestChangeConflictsAndWithout(t *testing.T) {
+	ch := Change{
+		Resource: ResourceConversation,
+		ID:       7,
+		Before:   map[string]any{"labels": []any{}, "status": "snoozed", "snoozed_until": float64(1), "priority": nil},
+		After:    map[string]any{"labels": []any{"a", "b"}, "status": "open", "priority": "high"},
+	}
+	current := map[string]any{"labels": []any{"b", "a"}, "status": "resolved", "priority": "high"}
+	if got := ch.Conflicts(current); !reflect.DeepEqual(got, []string{"status"}) {
+		t.Errorf("Conflicts = %v, want [status]", got)
+	}
+
+	rest := ch.Without([]string{"status"})
+	if _, ok := rest.Before["snoozed_until"]; ok {
+		t.Error("Without(status) should drop snoozed_until")
+	}
+	if !reflect.DeepEqual(rest.Fields(), []string{"labels", "priority"}) {
+		t.Errorf("Fields = %v", rest.Fields())
+	}
+	if len(ch.After) != 3 {
+		t.Error("Without must not modify the original change")
+	}
+}
@@ -50,6 +50,7 @@ type Client struct {
 	WaitForAsync       bool
 	WaitTimeout        time.Duration
 	WaitInterval       time.Duration
+	Changes            ChangeRecorder // receives conversation and contact changes with their prior state; nil disables
 	rateLimitMu        sync.Mutex
 	lastRateLimit      *RateLimitInfo
 }
@@ -78,8 +79,12 @@ func New(baseURL, token string, accountID int) *Client {
 	transport.TLSClientConfig.MinVersion = tls.VersionTLS12
 	transport.TLSClientConfig.InsecureSkipVerify = false
 
+	// Record or replay traffic when CW_RECORD or CW_REPLAY is set. Replay
+	// never reaches the base URL, so it needn't be valid.
+	roundTripper, replaying := cassetteTransport(transport)
+
 	// Allow localhost URLs when CHATWOOT_TESTING=1 is set (for integration tests)
-	skipValidation := os.Getenv("CHATWOOT_TESTING") == "1"
+	skipValidation := os.Getenv("CHATWOOT_TESTING") == "1" || replaying
 
 	retryCfg := DefaultRetryConfig()
 	return &Client{
@@ -90,7 +95,7 @@ func New(baseURL, token string, accountID int) *Client {
 		skipURLValidation: skipValidation,
 		HTTP: &http.Client{
 			Timeout:   DefaultTimeout,
-			Transport: transport,
+			Transport: roundTripper,
 		},
 		circuitBreaker: &circuitBreaker{
 			threshold: retryCfg.CircuitBreakerThreshold,
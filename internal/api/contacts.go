@@ -97,7 +97,23 @@ func createContactFromMap(ctx context.Context, r Requester, body map[string]any)
 
 // Update updates an existing contact.
 func (s ContactsService) Update(ctx context.Context, id int, name, email, phone string) (*Contact, error) {
-	return updateContact(ctx, s, id, name, email, phone)
+	after := map[string]any{}
+	if name != "" {
+		after["name"] = name
+	}
+	if email != "" {
+		after["email"] = email
+	}
+	if phone != "" {
+		after["phone_number"] = phone
+	}
+	var result *Contact
+	err := s.track(ctx, ResourceContact, id, fieldNames(after), func() (map[string]any, error) {
+		var err error
+		result, err = updateContact(ctx, s, id, name, email, phone)
+		return after, err
+	})
+	return result, err
 }
 
 func updateContact(ctx context.Context, r Requester, id int, name, email, phone string) (*Contact, error) {
@@ -120,6 +136,27 @@ func updateContact(ctx context.Context, r Requester, id int, name, email, phone
 	return &result.Payload, nil
 }
 
+// UpdateFromMap updates an existing contact using a map of fields.
+func (s ContactsService) UpdateFromMap(ctx context.Context, id int, body map[string]any) (*Contact, error) {
+	after := contactFields(body)
+	var result *Contact
+	err := s.track(ctx, ResourceContact, id, fieldNames(after), func() (map[string]any, error) {
+		var err error
+		result, err = updateContactFromMap(ctx, s, id, body)
+		return after, err
+	})
+	return result, err
+}
+
+func updateContactFromMap(ctx context.Context, r Requester, id int, body map[string]any) (*Contact, error) {
+	var result ContactResponse
+	path := fmt.Sprintf("/contacts/%d", id)
+	if err := r.do(ctx, http.MethodPatch, r.accountPath(path), body, &result); err != nil {
+		return nil, err
+	}
+	return &result.Payload, nil
+}
+
 // UpdateContactOpts defines options for updating a contact with extended fields.
 type UpdateContactOpts struct {
 	Name             string
@@ -135,10 +172,11 @@ type UpdateContactOpts struct {
 // UpdateWithOpts updates a contact using extended options including company,
 // country, custom attributes, and social profiles.
 func (s ContactsService) UpdateWithOpts(ctx context.Context, id int, opts UpdateContactOpts) (*Contact, error) {
-	return updateContactWithOpts(ctx, s, id, opts)
+	return s.UpdateFromMap(ctx, id, contactOptsBody(opts))
 }
 
-func updateContactWithOpts(ctx context.Context, r Requester, id int, opts UpdateContactOpts) (*Contact, error) {
+// contactOptsBody builds the PATCH body for UpdateContactOpts.
+func contactOptsBody(opts UpdateContactOpts) map[string]any {
 	body := map[string]any{}
 	if opts.Name != "" {
 		body["name"] = opts.Name
@@ -169,13 +207,7 @@ func updateContactWithOpts(ctx context.Context, r Requester, id int, opts Update
 	if len(additionalAttrs) > 0 {
 		body["additional_attributes"] = additionalAttrs
 	}
-
-	var result ContactResponse
-	path := fmt.Sprintf("/contacts/%d", id)
-	if err := r.do(ctx, http.MethodPatch, r.accountPath(path), body, &result); err != nil {
-		return nil, err
-	}
-	return &result.Payload, nil
+	return body
 }
 
 // Delete deletes a contact.
@@ -207,12 +239,21 @@ func searchContacts(ctx context.Context, r Requester, query string, page int) (*
 
 // Filter filters contacts based on custom query payload.
 func (s ContactsService) Filter(ctx context.Context, payload map[string]any) (*ContactList, error) {
-	return filterContacts(ctx, s, payload)
+	return filterContacts(ctx, s, payload, 0)
+}
+
+// FilterPage filters contacts and returns the given page of results.
+func (s ContactsService) FilterPage(ctx context.Context, payload map[string]any, page int) (*ContactList, error) {
+	return filterContacts(ctx, s, payload, page)
 }
 
-func filterContacts(ctx context.Context, r Requester, payload map[string]any) (*ContactList, error) {
+func filterContacts(ctx context.Context, r Requester, payload map[string]any, page int) (*ContactList, error) {
+	path := r.accountPath("/contacts/filter")
+	if page > 0 {
+		path += fmt.Sprintf("?page=%d", page)
+	}
 	var result ContactList
-	if err := r.do(ctx, http.MethodPost, r.accountPath("/contacts/filter"), payload, &result); err != nil {
+	if err := r.do(ctx, http.MethodPost, path, payload, &result); err != nil {
 		return nil, err
 	}
 	return &result, nil
@@ -252,7 +293,13 @@ func getContactLabels(ctx context.Context, r Requester, id int) ([]string, error
 
 // AddLabels adds labels to a contact.
 func (s ContactsService) AddLabels(ctx context.Context, id int, labels []string) ([]string, error) {
-	return addContactLabels(ctx, s, id, labels)
+	var result []string
+	err := s.track(ctx, ResourceContact, id, []string{"labels"}, func() (map[string]any, error) {
+		var err error
+		result, err = addContactLabels(ctx, s, id, labels)
+		return map[string]any{"labels": result}, err
+	})
+	return result, err
 }
 
 func addContactLabels(ctx context.Context, r Requester, id int, labels []string) ([]string, error) {
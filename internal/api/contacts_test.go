@@ -548,6 +548,26 @@ func TestFilterContacts(t *testing.T) {
 	}
 }
 
+func TestFilterContactsPage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/accounts/1/contacts/filter" || r.URL.Query().Get("page") != "3" {
+			t.Errorf("unexpected request %s", r.URL.String())
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"payload": [{"id": 7, "name": "Ann"}], "meta": {"current_page": 3, "total_pages": 3}}`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL, "test-token", 1)
+	result, err := client.Contacts().FilterPage(context.Background(), map[string]any{"payload": []any{}}, 3)
+	if err != nil {
+		t.Fatalf("FilterPage: %v", err)
+	}
+	if len(result.Payload) != 1 || int(result.Meta.TotalPages) != 3 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+}
+
 func TestGetContactConversations(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -93,8 +93,6 @@ func (c *Client) GetConversationContextWithOptions(ctx context.Context, conversa
 	if err != nil {
 		return nil, fmt.Errorf("failed to get messages: %w", err)
 	}
-	messages = sortConversationMessagesChronologically(messages)
-	messages, meta := applyConversationContextOptions(messages, opts)
 
 	// Get contact if available
 	var contact *Contact
@@ -102,6 +100,19 @@ func (c *Client) GetConversationContextWithOptions(ctx context.Context, conversa
 		contact, _ = getContact(ctx, c, conv.ContactID) // Ignore error, contact is optional
 	}
 
+	return buildConversationContext(ctx, conv, contact, messages, opts, c.downloadAndEncode), nil
+}
+
+// BuildConversationContext assembles a ConversationContext from already-fetched
+// data (for example an offline mirror). Images are never embedded.
+func BuildConversationContext(conv *Conversation, contact *Contact, messages []Message, opts ConversationContextOptions) *ConversationContext {
+	return buildConversationContext(context.Background(), conv, contact, messages, opts, nil)
+}
+
+func buildConversationContext(ctx context.Context, conv *Conversation, contact *Contact, messages []Message, opts ConversationContextOptions, embed func(context.Context, string, string) (string, error)) *ConversationContext {
+	messages = sortConversationMessagesChronologically(messages)
+	messages, meta := applyConversationContextOptions(messages, opts)
+
 	// Build messages with embeddings
 	messagesWithEmbeddings := make([]MessageWithEmbeddings, len(messages))
 	for i, msg := range messages {
@@ -128,14 +139,14 @@ func (c *Client) GetConversationContextWithOptions(ctx context.Context, conversa
 			}
 
 			// Embed image data if requested
-			if opts.EmbedImages && isImageType(att.FileType) {
+			if embed != nil && opts.EmbedImages && isImageType(att.FileType) {
 				if att.FileSize > 0 && att.FileSize > maxEmbeddedAttachmentBytes {
 					ioStreams := iocontext.GetIO(ctx)
 					_, _ = fmt.Fprintf(ioStreams.ErrOut, "Warning: skipping image embed (attachment %d exceeds %d bytes)\n", att.ID, maxEmbeddedAttachmentBytes)
 					mwe.Attachments = append(mwe.Attachments, ea)
 					continue
 				}
-				embedded, err := c.downloadAndEncode(ctx, att.DataURL, att.FileType)
+				embedded, err := embed(ctx, att.DataURL, att.FileType)
 				if err == nil {
 					ea.Embedded = embedded
 				} else {
@@ -160,7 +171,7 @@ func (c *Client) GetConversationContextWithOptions(ctx context.Context, conversa
 	// Generate a brief summary
 	result.Summary = generateContextSummary(result)
 
-	return result, nil
+	return result
 }
 
 func sortConversationMessagesChronologically(messages []Message) []Message {
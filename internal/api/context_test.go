@@ -893,3 +893,29 @@ func TestGetConversationContextError(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildConversationContextOffline(t *testing.T) {
+	conv := &Conversation{ID: 7, Status: "open"}
+	contact := &Contact{ID: 3, Name: "Jane", Email: "jane@example.com"}
+	messages := []Message{
+		{ID: 2, Content: " second ", CreatedAt: 20, Private: true},
+		{ID: 1, Content: "first", CreatedAt: 10, Attachments: []Attachment{{ID: 9, FileType: "image", DataURL: "https://example.com/a.png"}}},
+		{ID: 3, Content: "third", CreatedAt: 30},
+	}
+
+	ctx := BuildConversationContext(conv, contact, messages, ConversationContextOptions{EmbedImages: true, PublicOnly: true, Tail: 1})
+	if len(ctx.Messages) != 1 || ctx.Messages[0].ID != 3 {
+		t.Fatalf("unexpected messages: %+v", ctx.Messages)
+	}
+	if ctx.Meta == nil || ctx.Meta.TotalMessages != 3 || !ctx.Meta.Truncated {
+		t.Fatalf("unexpected meta: %+v", ctx.Meta)
+	}
+	if !strings.Contains(ctx.Summary, "Customer: Jane") {
+		t.Fatalf("unexpected summary: %q", ctx.Summary)
+	}
+
+	full := BuildConversationContext(conv, nil, messages, ConversationContextOptions{EmbedImages: true})
+	if full.Messages[0].ID != 1 || len(full.Messages[0].Attachments) != 1 || full.Messages[0].Attachments[0].Embedded != "" {
+		t.Fatalf("expected chronological order without embedding, got %+v", full.Messages[0])
+	}
+}
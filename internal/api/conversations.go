@@ -30,6 +30,9 @@ func buildConversationQuery(params ListConversationsParams, includePage bool) ur
 	if params.Query != "" {
 		query.Set("q", params.Query)
 	}
+	if params.SortBy != "" {
+		query.Set("sort_by", params.SortBy)
+	}
 	if includePage && params.Page > 0 {
 		query.Set("page", fmt.Sprintf("%d", params.Page))
 	}
@@ -55,6 +58,7 @@ type ListConversationsParams struct {
 	TeamID       string
 	Labels       []string
 	Query        string
+	SortBy       string // e.g. last_activity_at_desc
 	Page         int
 }
 
@@ -159,7 +163,19 @@ func getConversationsMeta(ctx context.Context, r Requester, params ListConversat
 
 // ToggleStatus toggles the status of a conversation.
 func (s ConversationsService) ToggleStatus(ctx context.Context, id int, status string, snoozedUntil int64) (*ToggleStatusResponse, error) {
-	return toggleConversationStatus(ctx, s, id, status, snoozedUntil)
+	var result *ToggleStatusResponse
+	err := s.track(ctx, ResourceConversation, id, []string{"status", "snoozed_until"}, func() (map[string]any, error) {
+		var err error
+		if result, err = toggleConversationStatus(ctx, s, id, status, snoozedUntil); err != nil {
+			return nil, err
+		}
+		current := status
+		if result.Payload.CurrentStatus != "" {
+			current = result.Payload.CurrentStatus
+		}
+		return map[string]any{"status": current}, nil
+	})
+	return result, err
 }
 
 func toggleConversationStatus(ctx context.Context, r Requester, id int, status string, snoozedUntil int64) (*ToggleStatusResponse, error) {
@@ -176,7 +192,9 @@ func toggleConversationStatus(ctx context.Context, r Requester, id int, status s
 
 // TogglePriority toggles the priority of a conversation.
 func (s ConversationsService) TogglePriority(ctx context.Context, id int, priority string) error {
-	return toggleConversationPriority(ctx, s, id, priority)
+	return s.track(ctx, ResourceConversation, id, []string{"priority"}, func() (map[string]any, error) {
+		return map[string]any{"priority": priorityValue(priority)}, toggleConversationPriority(ctx, s, id, priority)
+	})
 }
 
 func toggleConversationPriority(ctx context.Context, r Requester, id int, priority string) error {
@@ -186,7 +204,20 @@ func toggleConversationPriority(ctx context.Context, r Requester, id int, priori
 
 // Assign assigns a conversation to an agent and/or team.
 func (s ConversationsService) Assign(ctx context.Context, id, agentID, teamID int) (any, error) {
-	return assignConversation(ctx, s, id, agentID, teamID)
+	after := map[string]any{}
+	if agentID > 0 {
+		after["assignee_id"] = agentID
+	}
+	if teamID > 0 {
+		after["team_id"] = teamID
+	}
+	var result any
+	err := s.track(ctx, ResourceConversation, id, fieldNames(after), func() (map[string]any, error) {
+		var err error
+		result, err = assignConversation(ctx, s, id, agentID, teamID)
+		return after, err
+	})
+	return result, err
 }
 
 func assignConversation(ctx context.Context, r Requester, id, agentID, teamID int) (any, error) {
@@ -222,7 +253,13 @@ func getConversationLabels(ctx context.Context, r Requester, id int) ([]string,
 
 // AddLabels adds labels to a conversation.
 func (s ConversationsService) AddLabels(ctx context.Context, id int, labels []string) ([]string, error) {
-	return addConversationLabels(ctx, s, id, labels)
+	var result []string
+	err := s.track(ctx, ResourceConversation, id, []string{"labels"}, func() (map[string]any, error) {
+		var err error
+		result, err = addConversationLabels(ctx, s, id, labels)
+		return map[string]any{"labels": result}, err
+	})
+	return result, err
 }
 
 func addConversationLabels(ctx context.Context, r Requester, id int, labels []string) ([]string, error) {
@@ -238,7 +275,9 @@ func addConversationLabels(ctx context.Context, r Requester, id int, labels []st
 
 // UpdateCustomAttributes updates custom attributes for a conversation.
 func (s ConversationsService) UpdateCustomAttributes(ctx context.Context, id int, attrs map[string]any) error {
-	return updateConversationCustomAttributes(ctx, s, id, attrs)
+	return s.track(ctx, ResourceConversation, id, []string{"custom_attributes"}, func() (map[string]any, error) {
+		return map[string]any{"custom_attributes": attrs}, updateConversationCustomAttributes(ctx, s, id, attrs)
+	})
 }
 
 func updateConversationCustomAttributes(ctx context.Context, r Requester, id int, attrs map[string]any) error {
@@ -346,7 +385,25 @@ func toggleTypingStatus(ctx context.Context, r Requester, id int, typingOn bool,
 
 // Update updates conversation attributes via PATCH endpoint.
 func (s ConversationsService) Update(ctx context.Context, id int, priority string, slaPolicyID int) (*Conversation, error) {
-	return updateConversation(ctx, s, id, priority, slaPolicyID)
+	after := map[string]any{}
+	if priority != "" {
+		after["priority"] = priorityValue(priority)
+	}
+	var result *Conversation
+	err := s.track(ctx, ResourceConversation, id, fieldNames(after), func() (map[string]any, error) {
+		var err error
+		result, err = updateConversation(ctx, s, id, priority, slaPolicyID)
+		return after, err
+	})
+	return result, err
+}
+
+// priorityValue is the stored priority for a requested one; "none" clears it.
+func priorityValue(priority string) any {
+	if priority == "" || priority == "none" {
+		return nil
+	}
+	return priority
 }
 
 func updateConversation(ctx context.Context, r Requester, id int, priority string, slaPolicyID int) (*Conversation, error) {
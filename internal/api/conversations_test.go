@@ -280,6 +280,24 @@ func TestListConversations(t *testing.T) {
 	}
 }
 
+func TestListConversationsSortBy(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.URL.Query().Get("sort_by")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data": {"meta": {}, "payload": []}}`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL, "test-token", 1)
+	if _, err := client.Conversations().List(context.Background(), ListConversationsParams{SortBy: "last_activity_at_desc"}); err != nil {
+		t.Fatal(err)
+	}
+	if got != "last_activity_at_desc" {
+		t.Errorf("sort_by = %q, want last_activity_at_desc", got)
+	}
+}
+
 func TestGetConversation(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -51,6 +51,40 @@ func TestContactsCreateFromMap(t *testing.T) {
 	}
 }
 
+func TestContactsUpdateFromMap(t *testing.T) {
+	var captured map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch {
+			t.Fatalf("expected PATCH, got %s", r.Method)
+		}
+		if r.URL.Path != "/api/v1/accounts/1/contacts/7" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&captured); err != nil {
+			t.Fatalf("decode request body: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"payload":{"id":7,"name":"Map User","identifier":"crm-7"}}`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL, "token", 1)
+	contact, err := client.Contacts().UpdateFromMap(context.Background(), 7, map[string]any{
+		"identifier":            "crm-7",
+		"additional_attributes": map[string]any{"company_name": "Acme"},
+	})
+	if err != nil {
+		t.Fatalf("UpdateFromMap error: %v", err)
+	}
+	if contact.ID != 7 || contact.Identifier != "crm-7" {
+		t.Fatalf("unexpected contact: %+v", contact)
+	}
+	attrs, ok := captured["additional_attributes"].(map[string]any)
+	if !ok || attrs["company_name"] != "Acme" {
+		t.Fatalf("expected additional_attributes.company_name=Acme, got %#v", captured)
+	}
+}
+
 func TestContextServiceGetConversationWrapper(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"sort"
 )
 
 const maxPaginationIterations = 1000
@@ -147,6 +148,63 @@ func listMessagesWithLimit(ctx context.Context, r Requester, conversationID, lim
 	return allMessages, nil
 }
 
+// ListBetween retrieves messages with IDs greater than after and, unless
+// before is 0, less than before, paging backwards from before (0 starts at
+// the newest message). Results are sorted oldest first.
+//
+// At most maxPages pages are read. If that stops short of after, the
+// messages fetched so far are returned with rest set to the lowest ID among
+// them; the messages between after and rest are left for a later call.
+// Otherwise rest is 0.
+func (s MessagesService) ListBetween(ctx context.Context, conversationID, after, before, maxPages int) (messages []Message, rest int, err error) {
+	return listMessagesBetween(ctx, s, conversationID, after, before, maxPages)
+}
+
+func listMessagesBetween(ctx context.Context, r Requester, conversationID, after, before, maxPages int) ([]Message, int, error) {
+	if maxPages <= 0 {
+		maxPages = maxPaginationIterations
+	}
+
+	var newer []Message
+	seen := make(map[int]bool)
+	cursor := before
+	rest := 0
+
+	for iteration := 0; ; iteration++ {
+		if iteration >= maxPages {
+			rest = cursor
+			break
+		}
+
+		messages, err := listMessagesBefore(ctx, r, conversationID, cursor)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to fetch messages page (before=%d): %w", cursor, err)
+		}
+		if len(messages) == 0 {
+			break
+		}
+
+		for _, m := range messages {
+			if seen[m.ID] {
+				return nil, 0, fmt.Errorf("messages page (before=%d) repeats message %d - API may be returning duplicate data", cursor, m.ID)
+			}
+			seen[m.ID] = true
+			if m.ID > after && (before == 0 || m.ID < before) {
+				newer = append(newer, m)
+			}
+		}
+
+		minID := minMessageID(messages)
+		if minID <= after {
+			break
+		}
+		cursor = minID
+	}
+
+	sort.Slice(newer, func(i, j int) bool { return newer[i].ID < newer[j].ID })
+	return newer, rest, nil
+}
+
 // CreateMessageParams holds parameters for creating a message
 type CreateMessageParams struct {
 	Content     string `json:"content"`
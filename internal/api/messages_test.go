@@ -962,3 +962,68 @@ func TestRetryMessage(t *testing.T) {
 		})
 	}
 }
+
+func TestListMessagesBetween(t *testing.T) {
+	var befores []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		before := r.URL.Query().Get("before")
+		befores = append(befores, before)
+		w.Header().Set("Content-Type", "application/json")
+		switch before {
+		case "":
+			_, _ = w.Write([]byte(`{"payload":[{"id":14},{"id":15},{"id":16}]}`))
+		case "14":
+			_, _ = w.Write([]byte(`{"payload":[{"id":11},{"id":12},{"id":13}]}`))
+		case "11":
+			_, _ = w.Write([]byte(`{"payload":[{"id":8},{"id":9},{"id":10}]}`))
+		case "8":
+			// A page that ignores the cursor.
+			_, _ = w.Write([]byte(`{"payload":[{"id":9},{"id":10},{"id":11}]}`))
+		default:
+			t.Errorf("unexpected page request before=%s", before)
+			_, _ = w.Write([]byte(`{"payload":[]}`))
+		}
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL, "test-token", 1)
+	ids := func(messages []Message) string {
+		var ids []int
+		for _, m := range messages {
+			ids = append(ids, m.ID)
+		}
+		return fmt.Sprint(ids)
+	}
+
+	messages, rest, err := client.Messages().ListBetween(context.Background(), 1, 12, 0, 10)
+	if err != nil {
+		t.Fatalf("ListBetween: %v", err)
+	}
+	if ids(messages) != "[13 14 15 16]" || rest != 0 {
+		t.Fatalf("unexpected ids %s, rest %d", ids(messages), rest)
+	}
+	if len(befores) != 2 {
+		t.Fatalf("expected 2 page requests, got %v", befores)
+	}
+
+	// The page limit returns what was fetched and where to continue.
+	messages, rest, err = client.Messages().ListBetween(context.Background(), 1, 2, 0, 2)
+	if err != nil {
+		t.Fatalf("ListBetween: %v", err)
+	}
+	if ids(messages) != "[11 12 13 14 15 16]" || rest != 11 {
+		t.Fatalf("unexpected ids %s, rest %d", ids(messages), rest)
+	}
+	messages, rest, err = client.Messages().ListBetween(context.Background(), 1, 9, 11, 2)
+	if err != nil {
+		t.Fatalf("ListBetween: %v", err)
+	}
+	if ids(messages) != "[10]" || rest != 0 {
+		t.Fatalf("unexpected ids %s, rest %d", ids(messages), rest)
+	}
+
+	// Only a page that repeats messages is an error.
+	if _, _, err := client.Messages().ListBetween(context.Background(), 1, 2, 11, 5); err == nil || !strings.Contains(err.Error(), "repeats message 9") {
+		t.Fatalf("expected a repeated page error, got %v", err)
+	}
+}
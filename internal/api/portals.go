@@ -5,19 +5,31 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"time"
 )
 
 // Article represents a help center article
 type Article struct {
-	ID         int    `json:"id"`
-	PortalID   int    `json:"portal_id"`
-	CategoryID int    `json:"category_id"`
-	Title      string `json:"title"`
-	Content    string `json:"content"`
-	Slug       string `json:"slug"`
-	Status     string `json:"status"`
-	Views      int    `json:"views"`
-	AccountID  int    `json:"account_id"`
+	ID          int    `json:"id"`
+	PortalID    int    `json:"portal_id"`
+	CategoryID  int    `json:"category_id"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Content     string `json:"content"`
+	Slug        string `json:"slug"`
+	Status      string `json:"status"`
+	Position    int    `json:"position,omitempty"`
+	Locale      string `json:"locale,omitempty"`
+	Views       int    `json:"views"`
+	AccountID   int    `json:"account_id"`
+	// AssociatedArticleID links a translation to its source article.
+	AssociatedArticleID int   `json:"associated_article_id,omitempty"`
+	UpdatedAt           int64 `json:"updated_at,omitempty"`
+}
+
+// UpdatedAtTime returns UpdatedAt as time.Time
+func (a *Article) UpdatedAtTime() time.Time {
+	return time.Unix(a.UpdatedAt, 0)
 }
 
 // Category represents a help center category
@@ -28,6 +40,7 @@ type Category struct {
 	Slug        string `json:"slug"`
 	Description string `json:"description,omitempty"`
 	Position    int    `json:"position"`
+	Locale      string `json:"locale,omitempty"`
 	AccountID   int    `json:"account_id"`
 }
 
@@ -106,38 +119,59 @@ func deletePortal(ctx context.Context, r Requester, portalSlug string) error {
 	return r.do(ctx, http.MethodDelete, r.accountPath(path), nil, nil)
 }
 
-// Articles lists articles in a portal.
+// Articles lists articles in a portal, across all locales.
 func (s PortalsService) Articles(ctx context.Context, portalSlug string) ([]Article, error) {
-	return listPortalArticles(ctx, s, portalSlug)
+	return listPortalArticles(ctx, s, portalSlug, "", "")
+}
+
+// ArticlesInLocale lists articles in one locale of a portal.
+func (s PortalsService) ArticlesInLocale(ctx context.Context, portalSlug, locale string) ([]Article, error) {
+	return listPortalArticles(ctx, s, portalSlug, "", locale)
 }
 
-func listPortalArticles(ctx context.Context, r Requester, portalSlug string) ([]Article, error) {
+func listPortalArticles(ctx context.Context, r Requester, portalSlug, query, locale string) ([]Article, error) {
 	var result []Article
 	path := fmt.Sprintf("/portals/%s/articles", url.PathEscape(portalSlug))
+	params := url.Values{}
+	if query != "" {
+		params.Set("query", query)
+	}
+	if locale != "" {
+		params.Set("locale", locale)
+	}
+	if len(params) > 0 {
+		path += "?" + params.Encode()
+	}
 	err := r.do(ctx, http.MethodGet, r.accountPath(path), nil, &result)
 	return result, err
 }
 
 // SearchArticles searches articles in a portal by query string.
 func (s PortalsService) SearchArticles(ctx context.Context, portalSlug, query string) ([]Article, error) {
-	return searchPortalArticles(ctx, s, portalSlug, query)
+	return listPortalArticles(ctx, s, portalSlug, query, "")
 }
 
-func searchPortalArticles(ctx context.Context, r Requester, portalSlug, query string) ([]Article, error) {
-	var result []Article
-	path := fmt.Sprintf("/portals/%s/articles?query=%s", url.PathEscape(portalSlug), url.QueryEscape(query))
-	err := r.do(ctx, http.MethodGet, r.accountPath(path), nil, &result)
-	return result, err
+// SearchArticlesInLocale searches articles in one locale of a portal.
+func (s PortalsService) SearchArticlesInLocale(ctx context.Context, portalSlug, query, locale string) ([]Article, error) {
+	return listPortalArticles(ctx, s, portalSlug, query, locale)
 }
 
-// Categories lists categories in a portal.
+// Categories lists categories in a portal, across all locales.
 func (s PortalsService) Categories(ctx context.Context, portalSlug string) ([]Category, error) {
-	return listPortalCategories(ctx, s, portalSlug)
+	return listPortalCategories(ctx, s, portalSlug, "")
+}
+
+// CategoriesInLocale lists categories in one locale of a portal.
+func (s PortalsService) CategoriesInLocale(ctx context.Context, portalSlug, locale string) ([]Category, error) {
+	return listPortalCategories(ctx, s, portalSlug, locale)
 }
 
-func listPortalCategories(ctx context.Context, r Requester, portalSlug string) ([]Category, error) {
+func listPortalCategories(ctx context.Context, r Requester, portalSlug, locale string) ([]Category, error) {
 	var result []Category
 	path := fmt.Sprintf("/portals/%s/categories", url.PathEscape(portalSlug))
+	if locale != "" {
+		path += "?locale=" + url.QueryEscape(locale)
+	}
 	err := r.do(ctx, http.MethodGet, r.accountPath(path), nil, &result)
 	return result, err
 }
@@ -257,6 +257,21 @@ type Inbox struct {
 	GreetingEnabled      bool   `json:"greeting_enabled"`
 	GreetingMessage      string `json:"greeting_message,omitempty"`
 	EnableAutoAssignment bool   `json:"enable_auto_assignment"`
+	// Working hours are only populated by endpoints that return full inbox details.
+	WorkingHoursEnabled bool          `json:"working_hours_enabled,omitempty"`
+	Timezone            string        `json:"timezone,omitempty"`
+	WorkingHours        []WorkingHour `json:"working_hours,omitempty"`
+}
+
+// WorkingHour is an inbox's business hours for one day of the week.
+type WorkingHour struct {
+	DayOfWeek    int  `json:"day_of_week"` // 0 = Sunday
+	ClosedAllDay bool `json:"closed_all_day"`
+	OpenAllDay   bool `json:"open_all_day"`
+	OpenHour     int  `json:"open_hour"`
+	OpenMinutes  int  `json:"open_minutes"`
+	CloseHour    int  `json:"close_hour"`
+	CloseMinutes int  `json:"close_minutes"`
 }
 
 // ContactInbox represents a contact's association with an inbox
@@ -0,0 +1,534 @@
+// Package archive writes a resumable on-disk export of conversations: JSONL
+// files of conversations, messages, contacts and attachments, an optional
+// content-addressed attachment folder, and a manifest with record counts and
+// checksums.
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+)
+
+// Version is the archive format version.
+const Version = 1
+
+// Files in an archive directory.
+const (
+	ConversationsFile = "conversations.jsonl"
+	MessagesFile      = "messages.jsonl"
+	ContactsFile      = "contacts.jsonl"
+	AttachmentsFile   = "attachments.jsonl"
+	ManifestFile      = "manifest.json"
+	AttachmentsDir    = "attachments"
+
+	// stateFile records finished conversations and file offsets so an
+	// interrupted export can resume.
+	stateFile = ".export-state.jsonl"
+)
+
+var dataFiles = []string{ConversationsFile, MessagesFile, ContactsFile, AttachmentsFile}
+
+// Source identifies the exported account.
+type Source struct {
+	BaseURL   string `json:"base_url"`
+	AccountID int    `json:"account_id"`
+}
+
+// Filters select the exported conversations. SinceArg and UntilArg keep the
+// flags as given, so "--since 30d" resumes with the window it started with.
+type Filters struct {
+	SinceArg    string    `json:"since_arg,omitempty"`
+	UntilArg    string    `json:"until_arg,omitempty"`
+	Since       time.Time `json:"since,omitzero"`
+	Until       time.Time `json:"until,omitzero"`
+	InboxID     int       `json:"inbox_id,omitempty"`
+	Status      string    `json:"status,omitempty"`
+	Attachments bool      `json:"attachments"`
+}
+
+// sameSelection compares filters by their arguments, not resolved times.
+func (f Filters) sameSelection(o Filters) bool {
+	return f.SinceArg == o.SinceArg && f.UntilArg == o.UntilArg && f.InboxID == o.InboxID &&
+		f.Status == o.Status && f.Attachments == o.Attachments
+}
+
+// Includes reports whether a conversation's last activity falls in the
+// window [Since, Until).
+func (f Filters) Includes(lastActivity time.Time) bool {
+	if !f.Since.IsZero() && lastActivity.Before(f.Since) {
+		return false
+	}
+	return f.Until.IsZero() || lastActivity.Before(f.Until)
+}
+
+// Header describes an export. It is the first line of the state file.
+type Header struct {
+	Version   int       `json:"version"`
+	Source    Source    `json:"source"`
+	Filters   Filters   `json:"filters"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// AttachmentRecord is one line of attachments.jsonl. Stored files live at
+// attachments/<path>; Error is set when the download failed.
+type AttachmentRecord struct {
+	ConversationID int    `json:"conversation_id"`
+	MessageID      int    `json:"message_id"`
+	AttachmentID   int    `json:"attachment_id"`
+	FileType       string `json:"file_type,omitempty"`
+	DataURL        string `json:"data_url,omitempty"`
+	SHA256         string `json:"sha256,omitempty"`
+	Path           string `json:"path,omitempty"`
+	Name           string `json:"name,omitempty"`
+	MIMEType       string `json:"mime_type,omitempty"`
+	Bytes          int64  `json:"bytes,omitempty"`
+	Error          string `json:"error,omitempty"`
+}
+
+// Bundle is everything exported for one conversation.
+type Bundle struct {
+	Conversation api.Conversation
+	Messages     []api.Message
+	// Contact is written unless an earlier bundle already wrote it.
+	Contact     *api.Contact
+	Attachments []AttachmentRecord
+}
+
+// progress is a state line written after each conversation.
+type progress struct {
+	ConversationID int              `json:"conversation_id"`
+	ContactID      int              `json:"contact_id,omitempty"`
+	Offsets        map[string]int64 `json:"offsets"`
+	Records        map[string]int   `json:"records"`
+}
+
+// Writer appends bundles to an archive directory. It is safe for concurrent
+// use.
+type Writer struct {
+	dir    string
+	header Header
+
+	mu       sync.Mutex
+	files    map[string]*os.File
+	offsets  map[string]int64
+	records  map[string]int
+	state    *os.File
+	done     map[int]bool
+	contacts map[int]bool
+}
+
+// Open starts an export in dir, or resumes the one already there. Resuming
+// truncates data written after the last finished conversation. It fails if
+// dir holds an export of another account or with different filters.
+func Open(dir string, header Header) (*Writer, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create export directory: %w", err)
+	}
+	w := &Writer{
+		dir:      dir,
+		header:   header,
+		files:    map[string]*os.File{},
+		offsets:  map[string]int64{},
+		records:  map[string]int{},
+		done:     map[int]bool{},
+		contacts: map[int]bool{},
+	}
+
+	statePath := filepath.Join(dir, stateFile)
+	data, err := os.ReadFile(statePath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("read export state: %w", err)
+	}
+	resumed, err := w.load(data)
+	if err != nil {
+		return nil, err
+	}
+	if !resumed {
+		for _, name := range append([]string{ManifestFile}, dataFiles...) {
+			if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+				return nil, fmt.Errorf("%s already contains %s from another export; use an empty --out directory", dir, name)
+			}
+		}
+	} else if err := os.Remove(filepath.Join(dir, ManifestFile)); err != nil && !os.IsNotExist(err) {
+		// The archive is about to change, so a previous manifest no longer
+		// describes it. Finish writes a new one.
+		return nil, fmt.Errorf("remove stale manifest: %w", err)
+	}
+	// Leftovers from downloads interrupted mid-file.
+	if tmp, _ := filepath.Glob(filepath.Join(dir, AttachmentsDir, "chatwoot-attachment-*")); len(tmp) > 0 {
+		for _, p := range tmp {
+			_ = os.Remove(p)
+		}
+	}
+
+	for _, name := range dataFiles {
+		f, err := os.OpenFile(filepath.Join(dir, name), os.O_CREATE|os.O_RDWR, 0o644)
+		if err != nil {
+			w.closeFiles()
+			return nil, fmt.Errorf("open %s: %w", name, err)
+		}
+		w.files[name] = f
+		info, err := f.Stat()
+		if err != nil {
+			w.closeFiles()
+			return nil, err
+		}
+		if info.Size() < w.offsets[name] {
+			w.closeFiles()
+			return nil, fmt.Errorf("%s is shorter than the export state records; start over in an empty directory", name)
+		}
+		if err := f.Truncate(w.offsets[name]); err != nil {
+			w.closeFiles()
+			return nil, fmt.Errorf("truncate %s: %w", name, err)
+		}
+		if _, err := f.Seek(w.offsets[name], io.SeekStart); err != nil {
+			w.closeFiles()
+			return nil, err
+		}
+	}
+
+	state, err := os.OpenFile(statePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		w.closeFiles()
+		return nil, fmt.Errorf("open export state: %w", err)
+	}
+	w.state = state
+	switch {
+	case !resumed:
+		err = w.writeState(w.header)
+	case data[len(data)-1] != '\n':
+		// Terminate a line torn by an interrupted run before appending.
+		_, err = state.Write([]byte{'\n'})
+	}
+	if err != nil {
+		_ = w.Close()
+		return nil, fmt.Errorf("write export state: %w", err)
+	}
+	return w, nil
+}
+
+// load replays the state file, reporting false when there is none.
+func (w *Writer) load(data []byte) (bool, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+	if !scanner.Scan() {
+		return false, nil
+	}
+	var existing Header
+	if err := json.Unmarshal(scanner.Bytes(), &existing); err != nil || existing.Version == 0 {
+		return false, fmt.Errorf("%s is not an export state file", stateFile)
+	}
+	if existing.Version > Version {
+		return false, fmt.Errorf("export format version %d is newer than supported version %d", existing.Version, Version)
+	}
+	if existing.Source != w.header.Source {
+		return false, fmt.Errorf("%s holds an export of %s account %d; use another --out directory",
+			w.dir, existing.Source.BaseURL, existing.Source.AccountID)
+	}
+	if !existing.Filters.sameSelection(w.header.Filters) {
+		return false, fmt.Errorf("%s holds an export with different filters (%s); use the same flags to resume or another --out directory",
+			w.dir, describeFilters(existing.Filters))
+	}
+	w.header = existing
+
+	for scanner.Scan() {
+		var p progress
+		if err := json.Unmarshal(scanner.Bytes(), &p); err != nil || p.ConversationID == 0 || p.Offsets == nil {
+			continue // torn line: that conversation is exported again
+		}
+		w.done[p.ConversationID] = true
+		if p.ContactID != 0 {
+			w.contacts[p.ContactID] = true
+		}
+		w.offsets, w.records = p.Offsets, p.Records
+	}
+	if w.records == nil {
+		w.records = map[string]int{}
+	}
+	return true, scanner.Err()
+}
+
+func describeFilters(f Filters) string {
+	var parts []string
+	if f.SinceArg != "" {
+		parts = append(parts, "since "+f.SinceArg)
+	}
+	if f.UntilArg != "" {
+		parts = append(parts, "until "+f.UntilArg)
+	}
+	if f.InboxID != 0 {
+		parts = append(parts, fmt.Sprintf("inbox %d", f.InboxID))
+	}
+	if f.Status != "" {
+		parts = append(parts, "status "+f.Status)
+	}
+	if f.Attachments {
+		parts = append(parts, "with attachments")
+	}
+	if len(parts) == 0 {
+		return "everything"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Header returns the export header. When resuming it is the original one,
+// including the resolved time window.
+func (w *Writer) Header() Header {
+	return w.header
+}
+
+// Dir returns the archive directory.
+func (w *Writer) Dir() string {
+	return w.dir
+}
+
+// AttachmentsDir returns where attachment files are stored.
+func (w *Writer) AttachmentsDir() string {
+	return filepath.Join(w.dir, AttachmentsDir)
+}
+
+// Done reports whether a conversation was already exported.
+func (w *Writer) Done(conversationID int) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.done[conversationID]
+}
+
+// HasContact reports whether a contact was already written.
+func (w *Writer) HasContact(contactID int) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.contacts[contactID]
+}
+
+// Write appends a bundle and then records the conversation as done. A bundle
+// for a conversation that is already done is ignored. If writing fails the
+// files are rolled back to where the bundle started.
+func (w *Writer) Write(b Bundle) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.done[b.Conversation.ID] {
+		return nil
+	}
+
+	p := progress{ConversationID: b.Conversation.ID, Offsets: map[string]int64{}, Records: map[string]int{}}
+	for _, name := range dataFiles {
+		p.Offsets[name] = w.offsets[name]
+		p.Records[name] = w.records[name]
+	}
+	if err := w.writeBundle(b, &p); err != nil {
+		for _, name := range dataFiles {
+			w.offsets[name], w.records[name] = p.Offsets[name], p.Records[name]
+			_ = w.files[name].Truncate(w.offsets[name])
+			_, _ = w.files[name].Seek(w.offsets[name], io.SeekStart)
+		}
+		return err
+	}
+	w.done[b.Conversation.ID] = true
+	if p.ContactID != 0 {
+		w.contacts[p.ContactID] = true
+	}
+	return nil
+}
+
+func (w *Writer) writeBundle(b Bundle, p *progress) error {
+	for _, m := range b.Messages {
+		if err := w.appendLine(MessagesFile, m); err != nil {
+			return err
+		}
+	}
+	for _, a := range b.Attachments {
+		if err := w.appendLine(AttachmentsFile, a); err != nil {
+			return err
+		}
+	}
+	if b.Contact != nil && !w.contacts[b.Contact.ID] {
+		if err := w.appendLine(ContactsFile, b.Contact); err != nil {
+			return err
+		}
+		p.ContactID = b.Contact.ID
+	}
+	if err := w.appendLine(ConversationsFile, b.Conversation); err != nil {
+		return err
+	}
+
+	done := *p
+	done.Offsets, done.Records = map[string]int64{}, map[string]int{}
+	for _, name := range dataFiles {
+		done.Offsets[name] = w.offsets[name]
+		done.Records[name] = w.records[name]
+	}
+	if err := w.writeState(done); err != nil {
+		return fmt.Errorf("write export state: %w", err)
+	}
+	return nil
+}
+
+func (w *Writer) appendLine(name string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	n, err := w.files[name].Write(append(data, '\n'))
+	w.offsets[name] += int64(n)
+	if err != nil {
+		return fmt.Errorf("write %s: %w", name, err)
+	}
+	w.records[name]++
+	return nil
+}
+
+func (w *Writer) writeState(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.state.Write(append(data, '\n'))
+	return err
+}
+
+// FileEntry describes one file in the manifest.
+type FileEntry struct {
+	Name    string `json:"name"`
+	Records int    `json:"records"`
+	Bytes   int64  `json:"bytes"`
+	SHA256  string `json:"sha256"`
+}
+
+// AttachmentStats summarizes stored attachments.
+type AttachmentStats struct {
+	Records int   `json:"records"`
+	Files   int   `json:"files"` // distinct contents
+	Bytes   int64 `json:"bytes"`
+	Failed  int   `json:"failed"`
+}
+
+// Manifest is written to manifest.json when an export completes.
+type Manifest struct {
+	Version     int             `json:"version"`
+	Source      Source          `json:"source"`
+	Filters     Filters         `json:"filters"`
+	StartedAt   time.Time       `json:"started_at"`
+	CompletedAt time.Time       `json:"completed_at"`
+	Files       []FileEntry     `json:"files"`
+	Attachments AttachmentStats `json:"attachments"`
+}
+
+// Finish syncs the data files and writes the manifest. The writer stays
+// usable, so a later run can add conversations and finish again.
+func (w *Writer) Finish(now time.Time) (*Manifest, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	m := &Manifest{
+		Version:     Version,
+		Source:      w.header.Source,
+		Filters:     w.header.Filters,
+		StartedAt:   w.header.StartedAt,
+		CompletedAt: now.UTC(),
+	}
+	names := append([]string(nil), dataFiles...)
+	sort.Strings(names)
+	for _, name := range names {
+		f := w.files[name]
+		if err := f.Sync(); err != nil {
+			return nil, fmt.Errorf("sync %s: %w", name, err)
+		}
+		sum, err := hashFile(filepath.Join(w.dir, name))
+		if err != nil {
+			return nil, err
+		}
+		m.Files = append(m.Files, FileEntry{Name: name, Records: w.records[name], Bytes: w.offsets[name], SHA256: sum})
+	}
+	stats, err := w.attachmentStats()
+	if err != nil {
+		return nil, err
+	}
+	m.Attachments = stats
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	tmp := filepath.Join(w.dir, ManifestFile+".tmp")
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return nil, fmt.Errorf("write manifest: %w", err)
+	}
+	if err := os.Rename(tmp, filepath.Join(w.dir, ManifestFile)); err != nil {
+		return nil, fmt.Errorf("write manifest: %w", err)
+	}
+	return m, nil
+}
+
+// attachmentStats reads attachments.jsonl back, since a resumed run only
+// holds its own records in memory.
+func (w *Writer) attachmentStats() (AttachmentStats, error) {
+	var stats AttachmentStats
+	f, err := os.Open(filepath.Join(w.dir, AttachmentsFile))
+	if err != nil {
+		return stats, err
+	}
+	defer func() { _ = f.Close() }()
+	seen := map[string]bool{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+	for scanner.Scan() {
+		var rec AttachmentRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return stats, fmt.Errorf("read %s: %w", AttachmentsFile, err)
+		}
+		stats.Records++
+		switch {
+		case rec.Error != "":
+			stats.Failed++
+		case rec.SHA256 != "" && !seen[rec.SHA256]:
+			seen[rec.SHA256] = true
+			stats.Files++
+			stats.Bytes += rec.Bytes
+		}
+	}
+	return stats, scanner.Err()
+}
+
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Close closes the archive files.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closeFiles()
+	if w.state != nil {
+		return w.state.Close()
+	}
+	return nil
+}
+
+func (w *Writer) closeFiles() {
+	for _, f := range w.files {
+		_ = f.Close()
+	}
+}
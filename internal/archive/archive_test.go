@@ -0,0 +1,185 @@
+package archive_test
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+	"github.com/chatwoot/chatwoot-cli/internal/archive"
+)
+
+func testHeader() archive.Header {
+	return archive.Header{
+		Version: archive.Version,
+		Source:  archive.Source{BaseURL: "https://chat.example.com", AccountID: 1},
+		Filters: archive.Filters{
+			SinceArg:    "30d",
+			Since:       time.Date(2026, 9, 16, 0, 0, 0, 0, time.UTC),
+			Status:      "all",
+			Attachments: true,
+		},
+		StartedAt: time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func bundle(convID, contactID int, attachmentErr string) archive.Bundle {
+	b := archive.Bundle{
+		Conversation: api.Conversation{ID: convID, ContactID: contactID},
+		Messages:     []api.Message{{ID: convID * 10, Content: "hi"}, {ID: convID*10 + 1, Content: "hello"}},
+		Attachments: []archive.AttachmentRecord{{
+			ConversationID: convID, MessageID: convID * 10, SHA256: "aaaa", Path: "aa/aaaa", Bytes: 4, Error: attachmentErr,
+		}},
+	}
+	if contactID != 0 {
+		b.Contact = &api.Contact{ID: contactID, Name: "Jane"}
+	}
+	return b
+}
+
+func countLines(t *testing.T, path string) int {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+	n := 0
+	for s := bufio.NewScanner(f); s.Scan(); {
+		n++
+	}
+	return n
+}
+
+func TestWriterResumeAndManifest(t *testing.T) {
+	dir := t.TempDir()
+	w, err := archive.Open(dir, testHeader())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(bundle(1, 7, "")); err != nil {
+		t.Fatal(err)
+	}
+	_ = w.Close()
+
+	// Simulate a run interrupted halfway through conversation 2.
+	for _, name := range []string{archive.MessagesFile, archive.ConversationsFile} {
+		f, _ := os.OpenFile(filepath.Join(dir, name), os.O_APPEND|os.O_WRONLY, 0)
+		_, _ = f.WriteString(`{"id":99,"partial":`)
+		_ = f.Close()
+	}
+	if err := os.MkdirAll(filepath.Join(dir, archive.AttachmentsDir), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	leftover := filepath.Join(dir, archive.AttachmentsDir, "chatwoot-attachment-123")
+	if err := os.WriteFile(leftover, []byte("partial"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// A resumed "30d" keeps the window resolved by the first run.
+	header := testHeader()
+	header.Filters.Since = header.Filters.Since.Add(24 * time.Hour)
+	w, err = archive.Open(dir, header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = w.Close() }()
+	if !w.Header().Filters.Since.Equal(testHeader().Filters.Since) {
+		t.Fatalf("resumed window = %v", w.Header().Filters.Since)
+	}
+	if !w.Done(1) || w.Done(2) || !w.HasContact(7) {
+		t.Fatal("unexpected resume state")
+	}
+	if _, err := os.Stat(leftover); !os.IsNotExist(err) {
+		t.Fatal("leftover download not removed")
+	}
+	if err := w.Write(bundle(1, 7, "")); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(bundle(2, 7, "download failed")); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(bundle(3, 8, "")); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := w.Finish(time.Date(2026, 10, 16, 1, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	records := map[string]int{}
+	for _, f := range m.Files {
+		records[f.Name] = f.Records
+		if countLines(t, filepath.Join(dir, f.Name)) != f.Records || len(f.SHA256) != 64 {
+			t.Fatalf("manifest entry does not match file: %+v", f)
+		}
+	}
+	want := map[string]int{
+		archive.ConversationsFile: 3,
+		archive.MessagesFile:      6,
+		archive.ContactsFile:      2,
+		archive.AttachmentsFile:   3,
+	}
+	for name, n := range want {
+		if records[name] != n {
+			t.Errorf("%s records = %d, want %d", name, records[name], n)
+		}
+	}
+	if a := m.Attachments; a.Records != 3 || a.Files != 1 || a.Bytes != 4 || a.Failed != 1 {
+		t.Fatalf("attachment stats = %+v", a)
+	}
+	if _, err := os.Stat(filepath.Join(dir, archive.ManifestFile)); err != nil {
+		t.Fatalf("manifest not written: %v", err)
+	}
+}
+
+func TestOpenRejectsMismatchedExport(t *testing.T) {
+	dir := t.TempDir()
+	w, err := archive.Open(dir, testHeader())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = w.Close()
+
+	other := testHeader()
+	other.Filters.InboxID = 4
+	if _, err := archive.Open(dir, other); err == nil || !strings.Contains(err.Error(), "different filters (since 30d, status all, with attachments)") {
+		t.Fatalf("expected filter mismatch error, got %v", err)
+	}
+	other = testHeader()
+	other.Source.AccountID = 2
+	if _, err := archive.Open(dir, other); err == nil || !strings.Contains(err.Error(), "account 1") {
+		t.Fatalf("expected account mismatch error, got %v", err)
+	}
+
+	stray := t.TempDir()
+	if err := os.WriteFile(filepath.Join(stray, archive.MessagesFile), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := archive.Open(stray, testHeader()); err == nil || !strings.Contains(err.Error(), "another export") {
+		t.Fatalf("expected non-empty directory error, got %v", err)
+	}
+}
+
+func TestFiltersIncludes(t *testing.T) {
+	since := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	f := archive.Filters{Since: since, Until: until}
+	tests := map[time.Time]bool{
+		since.Add(-time.Second): false,
+		since:                   true,
+		until.Add(-time.Second): true,
+		until:                   false,
+	}
+	for at, want := range tests {
+		if got := f.Includes(at); got != want {
+			t.Errorf("Includes(%v) = %v, want %v", at, got, want)
+		}
+	}
+	if !(archive.Filters{}).Includes(time.Unix(0, 0)) {
+		t.Error("empty filters should include everything")
+	}
+}
@@ -0,0 +1,98 @@
+package autopilot
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// AuditLog appends decisions to a JSONL file.
+type AuditLog struct {
+	mu   sync.Mutex
+	file *os.File
+	path string
+}
+
+// OpenAudit opens (creating if needed) the audit log at path for appending.
+func OpenAudit(path string) (*AuditLog, error) {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return nil, fmt.Errorf("create audit log directory: %w", err)
+		}
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("open audit log: %w", err)
+	}
+	return &AuditLog{file: f, path: path}, nil
+}
+
+// Path returns the audit log location.
+func (l *AuditLog) Path() string {
+	return l.path
+}
+
+// Write appends one decision as a JSON line.
+func (l *AuditLog) Write(d Decision) error {
+	data, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, err = l.file.Write(append(data, '\n'))
+	return err
+}
+
+// Close closes the underlying file.
+func (l *AuditLog) Close() error {
+	return l.file.Close()
+}
+
+// ReadAudit returns the decisions recorded at path. A missing file yields no
+// decisions; malformed lines are skipped.
+func ReadAudit(path string) ([]Decision, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read audit log: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var out []Decision
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var d Decision
+		if err := json.Unmarshal(scanner.Bytes(), &d); err != nil {
+			continue
+		}
+		out = append(out, d)
+	}
+	if err := scanner.Err(); err != nil {
+		return out, fmt.Errorf("read audit log: %w", err)
+	}
+	return out, nil
+}
+
+// RestoreFired marks every successful non-dry-run decision in the audit log
+// as fired at its logged time so one-shot rules do not repeat after a
+// restart, and remembers the actions that went through in failed ones so
+// only the rest are retried. Decisions older than Options.FiredTTL are
+// skipped.
+func (e *Engine) RestoreFired(decisions []Decision) {
+	for _, d := range decisions {
+		switch {
+		case d.DryRun:
+		case d.Failed():
+			e.markSucceeded(d.Rule, d.ConversationID, d.Time, d.Actions)
+		default:
+			e.markFired(d.Rule, d.ConversationID, d.Time)
+		}
+	}
+}
@@ -0,0 +1,371 @@
+package autopilot_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+	"github.com/chatwoot/chatwoot-cli/internal/autopilot"
+	"github.com/chatwoot/chatwoot-cli/internal/heuristics"
+)
+
+type fakeClient struct {
+	conv     *api.Conversation
+	messages []api.Message
+	contact  *api.Contact
+	calls    []string
+	failOn   string
+}
+
+func (f *fakeClient) GetConversation(_ context.Context, id int) (*api.Conversation, error) {
+	if f.conv == nil || f.conv.ID != id {
+		return nil, errors.New("not found")
+	}
+	c := *f.conv
+	return &c, nil
+}
+
+func (f *fakeClient) ListMessages(context.Context, int) ([]api.Message, error) {
+	return f.messages, nil
+}
+
+func (f *fakeClient) GetContact(context.Context, int) (*api.Contact, error) {
+	f.calls = append(f.calls, "get_contact")
+	return f.contact, nil
+}
+
+func (f *fakeClient) record(call string) error {
+	f.calls = append(f.calls, call)
+	if f.failOn != "" && strings.HasPrefix(call, f.failOn) {
+		return errors.New("boom")
+	}
+	return nil
+}
+
+func (f *fakeClient) SetLabels(_ context.Context, _ int, labels []string) error {
+	return f.record("labels " + strings.Join(labels, ","))
+}
+
+func (f *fakeClient) SetPriority(_ context.Context, _ int, priority string) error {
+	return f.record("priority " + priority)
+}
+
+func (f *fakeClient) Assign(_ context.Context, _ int, agentID, teamID int) error {
+	return f.record(fmt.Sprintf("assign %d %d", agentID, teamID))
+}
+
+func (f *fakeClient) AddNote(_ context.Context, _ int, content string) error {
+	return f.record("note " + content)
+}
+
+func (f *fakeClient) Snooze(context.Context, int, time.Time) error {
+	return f.record("snooze")
+}
+
+var now = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func newConversation() *api.Conversation {
+	return &api.Conversation{ID: 7, InboxID: 3, Status: "open", ContactID: 9, Labels: []string{"existing"}, LastActivityAt: now.Unix()}
+}
+
+func TestParseRules(t *testing.T) {
+	rs, err := autopilot.Parse([]byte(`
+rules:
+  - name: billing
+    when:
+      keywords: [refund]
+      waiting_over: 30m
+    then:
+      add_labels: [billing]
+      snooze: 2h
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rs.Rules[0].When.WaitingOver != 30*time.Minute || rs.Rules[0].Then.Snooze != 2*time.Hour {
+		t.Fatalf("durations not parsed: %+v", rs.Rules[0])
+	}
+
+	for doc, want := range map[string]string{
+		"rules: []": "no rules",
+		"rules:\n  - name: x\n    when: {}\n    then: {}":                                 "no actions",
+		"rules:\n  - name: x\n    when: {urgency: [extreme]}\n    then: {priority: high}": "invalid urgency",
+		"rules:\n  - name: x\n    when: {keywordz: [a]}\n    then: {priority: high}":      "keywordz",
+		"rules:\n  - name: x\n    events: [label.added]\n    then: {priority: high}":      "unknown event",
+		"rules:\n  - name: x\n    then: {priority: p1}":                                   "invalid priority",
+		"rules:\n  - name: x\n    then: {note: a}\n  - name: x\n    then: {note: b}":      "duplicate",
+		"version: 2\nrules:\n  - name: x\n    then: {note: a}":                            "newer",
+	} {
+		if _, err := autopilot.Parse([]byte(doc)); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Parse(%q) error = %v, want %q", doc, err, want)
+		}
+	}
+}
+
+func TestMatchConditions(t *testing.T) {
+	unassigned := true
+	rule := &autopilot.Rule{Name: "r", When: autopilot.Conditions{
+		InboxIDs:          []int{3},
+		Labels:            []string{"existing"},
+		LabelsAbsent:      []string{"billing"},
+		Unassigned:        &unassigned,
+		Urgency:           []string{"high"},
+		Keywords:          []string{"Refund"},
+		WaitingOver:       time.Hour,
+		ContactAttributes: map[string]any{"plan": []any{"enterprise", "premium"}},
+	}}
+	newFacts := func() *autopilot.Facts {
+		return &autopilot.Facts{
+			Conversation: newConversation(),
+			Analysis:     &heuristics.Analysis{Urgency: "high"},
+			Unanswered:   []api.Message{{Content: "I want a REFUND now"}},
+			Waiting:      2 * time.Hour,
+			Contact:      &api.Contact{CustomAttributes: map[string]any{"plan": "Enterprise"}},
+		}
+	}
+
+	reasons, ok := autopilot.Match(rule, newFacts())
+	if !ok {
+		t.Fatal("expected rule to match")
+	}
+	joined := strings.Join(reasons, "; ")
+	for _, want := range []string{"inbox 3", `keyword "Refund"`, "urgency high", "waiting 2h0m0s > 1h0m0s", "contact plan=Enterprise"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("missing reason %q in %q", want, joined)
+		}
+	}
+
+	breakers := map[string]func(f *autopilot.Facts){
+		"inbox":     func(f *autopilot.Facts) { f.Conversation.InboxID = 4 },
+		"label":     func(f *autopilot.Facts) { f.Conversation.Labels = nil },
+		"absent":    func(f *autopilot.Facts) { f.Conversation.Labels = append(f.Conversation.Labels, "Billing") },
+		"assigned":  func(f *autopilot.Facts) { id := 1; f.Conversation.AssigneeID = &id },
+		"urgency":   func(f *autopilot.Facts) { f.Analysis.Urgency = "low" },
+		"keyword":   func(f *autopilot.Facts) { f.Unanswered = nil },
+		"waiting":   func(f *autopilot.Facts) { f.Waiting = time.Minute },
+		"attribute": func(f *autopilot.Facts) { f.Contact.CustomAttributes["plan"] = "free" },
+	}
+	for name, breakIt := range breakers {
+		f := newFacts()
+		breakIt(f)
+		if _, ok := autopilot.Match(rule, f); ok {
+			t.Errorf("%s: expected no match", name)
+		}
+	}
+}
+
+func TestEngineExecutesAndAudits(t *testing.T) {
+	rules, err := autopilot.Parse([]byte(`
+rules:
+  - name: billing
+    when:
+      keywords: [refund]
+      waiting_over: 10m
+    then:
+      add_labels: [billing]
+      priority: urgent
+      assign_team: 2
+      note: triaged
+  - name: tagged
+    when:
+      labels: [billing]
+      contact_attributes: {plan: enterprise}
+    then:
+      snooze: 1h
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &fakeClient{
+		conv: newConversation(),
+		messages: []api.Message{
+			{ID: 1, MessageType: api.MessageTypeIncoming, Content: "hello", CreatedAt: now.Add(-2 * time.Hour).Unix()},
+			{ID: 2, MessageType: api.MessageTypeOutgoing, Content: "hi!", CreatedAt: now.Add(-90 * time.Minute).Unix()},
+			{ID: 4, MessageType: api.MessageTypeIncoming, Content: "still waiting", CreatedAt: now.Add(-5 * time.Minute).Unix()},
+			{ID: 3, MessageType: api.MessageTypeIncoming, Content: "I need a refund", CreatedAt: now.Add(-30 * time.Minute).Unix()},
+			{ID: 5, MessageType: api.MessageTypeOutgoing, Private: true, Content: "internal", CreatedAt: now.Add(-time.Minute).Unix()},
+		},
+		contact: &api.Contact{CustomAttributes: map[string]any{"plan": "enterprise"}},
+	}
+	auditPath := filepath.Join(t.TempDir(), "audit.jsonl")
+	audit, err := autopilot.OpenAudit(auditPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = audit.Close() }()
+
+	engine := autopilot.NewEngine(rules, client, autopilot.Options{Audit: audit, Now: func() time.Time { return now }})
+	decisions, err := engine.Handle(context.Background(), autopilot.EventMessageCreated, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decisions) != 2 || decisions[0].Rule != "billing" || decisions[1].Rule != "tagged" {
+		t.Fatalf("unexpected decisions: %+v", decisions)
+	}
+	if !strings.Contains(strings.Join(decisions[0].Reasons, ";"), "waiting 30m0s") {
+		t.Errorf("waiting should be measured from the oldest unanswered message: %v", decisions[0].Reasons)
+	}
+	want := []string{"labels existing,billing", "priority urgent", "assign 0 2", "note triaged", "get_contact", "snooze"}
+	if strings.Join(client.calls, "|") != strings.Join(want, "|") {
+		t.Fatalf("calls = %v, want %v", client.calls, want)
+	}
+
+	// One-shot rules do not fire again, even after a restart.
+	client.calls = nil
+	if decisions, _ := engine.Handle(context.Background(), autopilot.EventMessageCreated, 7); len(decisions) != 0 {
+		t.Fatalf("expected no repeat, got %+v", decisions)
+	}
+	logged, err := autopilot.ReadAudit(auditPath)
+	if err != nil || len(logged) != 2 {
+		t.Fatalf("audit log = %d entries, %v", len(logged), err)
+	}
+	restarted := autopilot.NewEngine(rules, client, autopilot.Options{Now: func() time.Time { return now }})
+	restarted.RestoreFired(logged)
+	if decisions, _ := restarted.Handle(context.Background(), autopilot.EventMessageCreated, 7); len(decisions) != 0 || len(client.calls) != 0 {
+		t.Fatalf("expected restored state to suppress rules, got %+v %v", decisions, client.calls)
+	}
+}
+
+func TestEngineDryRunAndFailures(t *testing.T) {
+	rules, err := autopilot.Parse([]byte(`
+rules:
+  - name: prio
+    events: [recheck]
+    then:
+      priority: high
+      note: checked
+    repeat: true
+    stop: true
+  - name: skipped
+    events: [recheck]
+    then:
+      note: never
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &fakeClient{conv: newConversation()}
+
+	dry := autopilot.NewEngine(rules, client, autopilot.Options{DryRun: true})
+	if decisions, _ := dry.Handle(context.Background(), autopilot.EventMessageCreated, 7); len(decisions) != 0 {
+		t.Fatal("rule limited to recheck fired on message.created")
+	}
+	decisions, err := dry.Handle(context.Background(), autopilot.EventRecheck, 7)
+	if err != nil || len(decisions) != 1 || !decisions[0].DryRun || len(client.calls) != 0 {
+		t.Fatalf("dry run: decisions=%+v err=%v calls=%v", decisions, err, client.calls)
+	}
+
+	client.failOn = "priority"
+	live := autopilot.NewEngine(rules, client, autopilot.Options{})
+	decisions, err = live.Handle(context.Background(), autopilot.EventRecheck, 7)
+	if err != nil || len(decisions) != 1 {
+		t.Fatalf("decisions=%+v err=%v", decisions, err)
+	}
+	if !decisions[0].Failed() || decisions[0].Actions[0].Error != "boom" || decisions[0].Actions[1].Error != "" {
+		t.Fatalf("expected priority failure recorded and note still attempted: %+v", decisions[0].Actions)
+	}
+
+	if _, err := live.Handle(context.Background(), autopilot.EventRecheck, 99); err == nil {
+		t.Fatal("expected error for missing conversation")
+	}
+}
+
+func TestEngineRetriesOnlyFailedActions(t *testing.T) {
+	rules, err := autopilot.Parse([]byte(`
+rules:
+  - name: triage
+    events: [recheck]
+    then:
+      priority: high
+      note: checked
+      snooze: 1h
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &fakeClient{conv: newConversation(), failOn: "priority"}
+	engine := autopilot.NewEngine(rules, client, autopilot.Options{Now: func() time.Time { return now }})
+	handle := func(e *autopilot.Engine) []autopilot.Decision {
+		t.Helper()
+		decisions, err := e.Handle(context.Background(), autopilot.EventRecheck, 7)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return decisions
+	}
+
+	first := handle(engine)
+	if len(first) != 1 || !first[0].Failed() || len(first[0].Actions) != 3 {
+		t.Fatalf("expected a failed decision with every action, got %+v", first)
+	}
+	// A restart picks up the actions that went through from the audit log.
+	restarted := autopilot.NewEngine(rules, client, autopilot.Options{Now: func() time.Time { return now }})
+	restarted.RestoreFired(first)
+
+	for _, e := range []*autopilot.Engine{engine, restarted} {
+		client.failOn = ""
+		client.calls = nil
+		if d := handle(e); len(d) != 1 || d[0].Failed() || len(d[0].Actions) != 1 || d[0].Actions[0].Action != "priority" {
+			t.Fatalf("expected only the failed action retried, got %+v", d)
+		}
+		if fmt.Sprint(client.calls) != "[priority high]" {
+			t.Fatalf("retry called %v", client.calls)
+		}
+		if d := handle(e); len(d) != 0 {
+			t.Fatalf("rule fired again after success: %+v", d)
+		}
+	}
+}
+
+func TestEngineFiredOnlyAfterSuccessAndExpires(t *testing.T) {
+	rules, err := autopilot.Parse([]byte(`
+rules:
+  - name: prio
+    events: [recheck]
+    then:
+      priority: high
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &fakeClient{conv: newConversation(), failOn: "priority"}
+	clock := now
+	engine := autopilot.NewEngine(rules, client, autopilot.Options{FiredTTL: time.Hour, Now: func() time.Time { return clock }})
+	handle := func() []autopilot.Decision {
+		t.Helper()
+		decisions, err := engine.Handle(context.Background(), autopilot.EventRecheck, 7)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return decisions
+	}
+
+	if d := handle(); len(d) != 1 || !d[0].Failed() {
+		t.Fatalf("expected a failed decision, got %+v", d)
+	}
+	client.failOn = ""
+	if d := handle(); len(d) != 1 || d[0].Failed() {
+		t.Fatalf("failed rule should be retried, got %+v", d)
+	}
+	if d := handle(); len(d) != 0 {
+		t.Fatalf("rule fired again after success: %+v", d)
+	}
+	clock = clock.Add(2 * time.Hour)
+	if d := handle(); len(d) != 1 {
+		t.Fatalf("expected rule to fire again once its entry expired, got %+v", d)
+	}
+
+	restarted := autopilot.NewEngine(rules, client, autopilot.Options{FiredTTL: time.Hour, Now: func() time.Time { return clock }})
+	restarted.RestoreFired([]autopilot.Decision{
+		{Time: clock.Add(-2 * time.Hour), Rule: "prio", ConversationID: 7},
+		{Time: clock, Rule: "prio", ConversationID: 7, Actions: []autopilot.ActionResult{{Action: "priority", Error: "boom"}}},
+	})
+	if d, _ := restarted.Handle(context.Background(), autopilot.EventRecheck, 7); len(d) != 1 {
+		t.Fatalf("expired and failed audit entries should not suppress the rule, got %+v", d)
+	}
+}
@@ -0,0 +1,68 @@
+package autopilot
+
+import (
+	"context"
+	"time"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+)
+
+// Client is the subset of the Chatwoot API the engine reads from and acts
+// through. NewAPIClient adapts *api.Client.
+type Client interface {
+	GetConversation(ctx context.Context, id int) (*api.Conversation, error)
+	ListMessages(ctx context.Context, conversationID int) ([]api.Message, error)
+	GetContact(ctx context.Context, id int) (*api.Contact, error)
+
+	// SetLabels replaces the conversation's labels.
+	SetLabels(ctx context.Context, conversationID int, labels []string) error
+	SetPriority(ctx context.Context, conversationID int, priority string) error
+	Assign(ctx context.Context, conversationID, agentID, teamID int) error
+	AddNote(ctx context.Context, conversationID int, content string) error
+	Snooze(ctx context.Context, conversationID int, until time.Time) error
+}
+
+type apiClient struct {
+	client *api.Client
+}
+
+// NewAPIClient wraps an API client for use by the engine.
+func NewAPIClient(client *api.Client) Client {
+	return apiClient{client: client}
+}
+
+func (c apiClient) GetConversation(ctx context.Context, id int) (*api.Conversation, error) {
+	return c.client.Conversations().Get(ctx, id)
+}
+
+func (c apiClient) ListMessages(ctx context.Context, conversationID int) ([]api.Message, error) {
+	return c.client.Messages().List(ctx, conversationID)
+}
+
+func (c apiClient) GetContact(ctx context.Context, id int) (*api.Contact, error) {
+	return c.client.Contacts().Get(ctx, id)
+}
+
+func (c apiClient) SetLabels(ctx context.Context, conversationID int, labels []string) error {
+	_, err := c.client.Conversations().AddLabels(ctx, conversationID, labels)
+	return err
+}
+
+func (c apiClient) SetPriority(ctx context.Context, conversationID int, priority string) error {
+	return c.client.Conversations().TogglePriority(ctx, conversationID, priority)
+}
+
+func (c apiClient) Assign(ctx context.Context, conversationID, agentID, teamID int) error {
+	_, err := c.client.Conversations().Assign(ctx, conversationID, agentID, teamID)
+	return err
+}
+
+func (c apiClient) AddNote(ctx context.Context, conversationID int, content string) error {
+	_, err := c.client.Messages().Create(ctx, conversationID, content, true, "outgoing")
+	return err
+}
+
+func (c apiClient) Snooze(ctx context.Context, conversationID int, until time.Time) error {
+	_, err := c.client.Conversations().ToggleStatus(ctx, conversationID, "snoozed", until.Unix())
+	return err
+}
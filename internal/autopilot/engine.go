@@ -0,0 +1,508 @@
+package autopilot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+	"github.com/chatwoot/chatwoot-cli/internal/heuristics"
+)
+
+// ErrAudit wraps failures to write the audit log. Callers should treat it as
+// fatal: a daemon that cannot record its decisions should stop acting.
+var ErrAudit = errors.New("write audit log")
+
+// Facts is everything a rule can test for one conversation.
+type Facts struct {
+	Event        string
+	Conversation *api.Conversation
+	Analysis     *heuristics.Analysis
+	// Unanswered holds customer messages after the last public agent reply.
+	Unanswered []api.Message
+	// Waiting is the age of the oldest unanswered customer message.
+	Waiting time.Duration
+	// Contact is loaded only when a rule tests contact attributes.
+	Contact *api.Contact
+}
+
+// Decision records one rule firing on one conversation.
+type Decision struct {
+	Time           time.Time      `json:"time"`
+	Event          string         `json:"event"`
+	ConversationID int            `json:"conversation_id"`
+	Rule           string         `json:"rule"`
+	Reasons        []string       `json:"reasons"`
+	Actions        []ActionResult `json:"actions"`
+	DryRun         bool           `json:"dry_run"`
+}
+
+// Failed reports whether any action returned an error.
+func (d Decision) Failed() bool {
+	for _, a := range d.Actions {
+		if a.Error != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// ActionResult is the outcome of one action within a decision.
+type ActionResult struct {
+	Action string `json:"action"`
+	Detail string `json:"detail"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Options configures an Engine.
+type Options struct {
+	// DryRun evaluates rules and records decisions without calling the API.
+	DryRun bool
+	// Audit receives every decision. May be nil.
+	Audit *AuditLog
+	// Now overrides the clock (tests).
+	Now func() time.Time
+	// FiredTTL is how long a one-shot rule stays fired on a conversation
+	// (default DefaultFiredTTL). Older entries are pruned, so the rule may
+	// fire there again.
+	FiredTTL time.Duration
+}
+
+// DefaultFiredTTL is the default Options.FiredTTL.
+const DefaultFiredTTL = 30 * 24 * time.Hour
+
+// Engine evaluates a RuleSet against conversations.
+type Engine struct {
+	rules  *RuleSet
+	client Client
+	opts   Options
+
+	mu    sync.Mutex
+	fired map[string]time.Time
+	// succeeded holds the actions that went through when a rule last ran on
+	// a conversation with some actions failing, so a retry runs only the rest.
+	succeeded map[string]succeededActions
+	lastPrune time.Time
+}
+
+type succeededActions struct {
+	at      time.Time
+	actions map[string]bool
+}
+
+// NewEngine returns an Engine for rules acting through client.
+func NewEngine(rules *RuleSet, client Client, opts Options) *Engine {
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	if opts.FiredTTL <= 0 {
+		opts.FiredTTL = DefaultFiredTTL
+	}
+	return &Engine{rules: rules, client: client, opts: opts, fired: map[string]time.Time{}, succeeded: map[string]succeededActions{}}
+}
+
+// MarkFired records that rule fired on a conversation now so it is not
+// repeated within Options.FiredTTL.
+func (e *Engine) MarkFired(rule string, conversationID int) {
+	e.markFired(rule, conversationID, e.opts.Now())
+}
+
+func (e *Engine) markFired(rule string, conversationID int, at time.Time) {
+	now := e.opts.Now()
+	if now.Sub(at) >= e.opts.FiredTTL {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	key := firedKey(rule, conversationID)
+	if prev, ok := e.fired[key]; !ok || at.After(prev) {
+		e.fired[key] = at
+	}
+	if s, ok := e.succeeded[key]; ok && !s.at.After(at) {
+		delete(e.succeeded, key)
+	}
+	e.pruneLocked(now)
+}
+
+// markSucceeded records the actions of a partly failed run that went
+// through, so the next run of the rule on the conversation skips them.
+func (e *Engine) markSucceeded(rule string, conversationID int, at time.Time, results []ActionResult) {
+	now := e.opts.Now()
+	if now.Sub(at) >= e.opts.FiredTTL {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	key := firedKey(rule, conversationID)
+	s, ok := e.succeeded[key]
+	if !ok {
+		s = succeededActions{actions: map[string]bool{}}
+	}
+	s.at = maxTime(s.at, at)
+	for _, res := range results {
+		if res.Error == "" {
+			s.actions[res.Action] = true
+		}
+	}
+	e.succeeded[key] = s
+	e.pruneLocked(now)
+}
+
+// pruneLocked sweeps expired entries at most once per tenth of the TTL, so a
+// long-running engine holds only recently fired conversations.
+func (e *Engine) pruneLocked(now time.Time) {
+	if now.Sub(e.lastPrune) < e.opts.FiredTTL/10 {
+		return
+	}
+	for k, t := range e.fired {
+		if now.Sub(t) >= e.opts.FiredTTL {
+			delete(e.fired, k)
+		}
+	}
+	for k, s := range e.succeeded {
+		if now.Sub(s.at) >= e.opts.FiredTTL {
+			delete(e.succeeded, k)
+		}
+	}
+	e.lastPrune = now
+}
+
+func (e *Engine) hasFired(rule string, conversationID int) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	at, ok := e.fired[firedKey(rule, conversationID)]
+	return ok && e.opts.Now().Sub(at) < e.opts.FiredTTL
+}
+
+// succeededActionsFor returns the actions to skip when rule runs again on a
+// conversation.
+func (e *Engine) succeededActionsFor(rule string, conversationID int) map[string]bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	s, ok := e.succeeded[firedKey(rule, conversationID)]
+	if !ok || e.opts.Now().Sub(s.at) >= e.opts.FiredTTL {
+		return nil
+	}
+	done := make(map[string]bool, len(s.actions))
+	for a := range s.actions {
+		done[a] = true
+	}
+	return done
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func firedKey(rule string, conversationID int) string {
+	return fmt.Sprintf("%s\x00%d", rule, conversationID)
+}
+
+// Handle evaluates every rule triggered by event against the conversation
+// and executes the matches in order. Action failures are reported in the
+// returned decisions; the error is set when the conversation or contact could
+// not be loaded, or when the audit log could not be written (ErrAudit).
+func (e *Engine) Handle(ctx context.Context, event string, conversationID int) ([]Decision, error) {
+	var pending []*Rule
+	for i := range e.rules.Rules {
+		r := &e.rules.Rules[i]
+		if r.triggeredBy(event) && (r.Repeat || !e.hasFired(r.Name, conversationID)) {
+			pending = append(pending, r)
+		}
+	}
+	if len(pending) == 0 {
+		return nil, nil
+	}
+
+	facts, err := e.Gather(ctx, event, conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	var decisions []Decision
+	for _, r := range pending {
+		if r.needsContact() && facts.Contact == nil && facts.Conversation.ContactID > 0 {
+			contact, err := e.client.GetContact(ctx, facts.Conversation.ContactID)
+			if err != nil {
+				return decisions, fmt.Errorf("get contact %d: %w", facts.Conversation.ContactID, err)
+			}
+			facts.Contact = contact
+		}
+		reasons, ok := Match(r, facts)
+		if !ok {
+			continue
+		}
+		d := Decision{
+			Time:           e.opts.Now().UTC(),
+			Event:          event,
+			ConversationID: conversationID,
+			Rule:           r.Name,
+			Reasons:        reasons,
+			DryRun:         e.opts.DryRun,
+		}
+		d.Actions = e.execute(ctx, r.Then, facts, e.succeededActionsFor(r.Name, conversationID))
+		// A rule whose actions failed stays pending so the next event
+		// retries the failed ones.
+		if !d.Failed() {
+			e.MarkFired(r.Name, conversationID)
+		} else if !e.opts.DryRun {
+			e.markSucceeded(r.Name, conversationID, d.Time, d.Actions)
+		}
+		if e.opts.Audit != nil {
+			if err := e.opts.Audit.Write(d); err != nil {
+				return append(decisions, d), fmt.Errorf("%w: %v", ErrAudit, err)
+			}
+		}
+		decisions = append(decisions, d)
+		if r.Stop {
+			break
+		}
+	}
+	return decisions, nil
+}
+
+// Gather loads the conversation, its messages, and the heuristics analysis.
+func (e *Engine) Gather(ctx context.Context, event string, conversationID int) (*Facts, error) {
+	conv, err := e.client.GetConversation(ctx, conversationID)
+	if err != nil {
+		return nil, fmt.Errorf("get conversation %d: %w", conversationID, err)
+	}
+	messages, err := e.client.ListMessages(ctx, conversationID)
+	if err != nil {
+		return nil, fmt.Errorf("list messages for conversation %d: %w", conversationID, err)
+	}
+	sort.Slice(messages, func(i, j int) bool { return messages[i].ID < messages[j].ID })
+
+	facts := &Facts{
+		Event:        event,
+		Conversation: conv,
+		Analysis:     heuristics.AnalyzeConversation(conv, messages, nil),
+		Unanswered:   unansweredMessages(messages),
+	}
+	if len(facts.Unanswered) > 0 {
+		oldest := time.Unix(facts.Unanswered[0].CreatedAt, 0)
+		facts.Waiting = max(e.opts.Now().Sub(oldest), 0)
+	}
+	return facts, nil
+}
+
+// unansweredMessages returns incoming messages after the last public
+// outgoing message, oldest first.
+func unansweredMessages(messages []api.Message) []api.Message {
+	start := 0
+	for i := len(messages) - 1; i >= 0; i-- {
+		m := messages[i]
+		if m.MessageType == api.MessageTypeOutgoing && !m.Private {
+			start = i + 1
+			break
+		}
+	}
+	var out []api.Message
+	for _, m := range messages[start:] {
+		if m.MessageType == api.MessageTypeIncoming {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+func (r *Rule) needsContact() bool {
+	return len(r.When.ContactAttributes) > 0
+}
+
+// Match reports whether every condition of the rule holds and returns a
+// human-readable reason per condition.
+func Match(r *Rule, f *Facts) ([]string, bool) {
+	c := r.When
+	conv := f.Conversation
+	var reasons []string
+
+	if len(c.InboxIDs) > 0 {
+		found := false
+		for _, id := range c.InboxIDs {
+			if conv.InboxID == id {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, false
+		}
+		reasons = append(reasons, fmt.Sprintf("inbox %d", conv.InboxID))
+	}
+	if len(c.Status) > 0 {
+		if !oneOf(conv.Status, c.Status) {
+			return nil, false
+		}
+		reasons = append(reasons, "status "+conv.Status)
+	}
+	for _, label := range c.Labels {
+		if !oneOf(label, conv.Labels) {
+			return nil, false
+		}
+		reasons = append(reasons, "label "+label)
+	}
+	for _, label := range c.LabelsAbsent {
+		if oneOf(label, conv.Labels) {
+			return nil, false
+		}
+	}
+	if len(c.LabelsAbsent) > 0 {
+		reasons = append(reasons, "without labels "+strings.Join(c.LabelsAbsent, ","))
+	}
+	if c.Unassigned != nil {
+		unassigned := conv.AssigneeID == nil || *conv.AssigneeID == 0
+		if unassigned != *c.Unassigned {
+			return nil, false
+		}
+		if unassigned {
+			reasons = append(reasons, "unassigned")
+		} else {
+			reasons = append(reasons, "assigned")
+		}
+	}
+	if len(c.Urgency) > 0 {
+		if f.Analysis == nil || !oneOf(f.Analysis.Urgency, c.Urgency) {
+			return nil, false
+		}
+		reasons = append(reasons, "urgency "+f.Analysis.Urgency)
+	}
+	if len(c.Sentiment) > 0 {
+		if f.Analysis == nil || !oneOf(f.Analysis.SentimentHint, c.Sentiment) {
+			return nil, false
+		}
+		reasons = append(reasons, "sentiment "+f.Analysis.SentimentHint)
+	}
+	if len(c.Keywords) > 0 {
+		keyword, ok := matchKeyword(c.Keywords, f.Unanswered)
+		if !ok {
+			return nil, false
+		}
+		reasons = append(reasons, fmt.Sprintf("keyword %q", keyword))
+	}
+	if c.WaitingOver > 0 {
+		if f.Waiting <= c.WaitingOver {
+			return nil, false
+		}
+		reasons = append(reasons, fmt.Sprintf("waiting %s > %s", f.Waiting.Round(time.Second), c.WaitingOver))
+	}
+	if len(c.ContactAttributes) > 0 {
+		if f.Contact == nil {
+			return nil, false
+		}
+		keys := make([]string, 0, len(c.ContactAttributes))
+		for k := range c.ContactAttributes {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			actual, ok := f.Contact.CustomAttributes[k]
+			if !ok || !attributeMatches(actual, c.ContactAttributes[k]) {
+				return nil, false
+			}
+			reasons = append(reasons, fmt.Sprintf("contact %s=%v", k, actual))
+		}
+	}
+	if len(reasons) == 0 {
+		reasons = append(reasons, "no conditions")
+	}
+	return reasons, true
+}
+
+func matchKeyword(keywords []string, messages []api.Message) (string, bool) {
+	for _, m := range messages {
+		content := strings.ToLower(m.Content)
+		for _, k := range keywords {
+			if k = strings.TrimSpace(k); k != "" && strings.Contains(content, strings.ToLower(k)) {
+				return k, true
+			}
+		}
+	}
+	return "", false
+}
+
+// attributeMatches compares values as strings; a list matches any entry.
+func attributeMatches(actual, want any) bool {
+	if list, ok := want.([]any); ok {
+		for _, w := range list {
+			if attributeMatches(actual, w) {
+				return true
+			}
+		}
+		return false
+	}
+	return strings.EqualFold(fmt.Sprint(actual), fmt.Sprint(want))
+}
+
+// execute runs (or, in dry-run mode, describes) the rule's actions, except
+// those in skip, which went through on an earlier run.
+func (e *Engine) execute(ctx context.Context, a Actions, f *Facts, skip map[string]bool) []ActionResult {
+	conv := f.Conversation
+	var results []ActionResult
+	run := func(action, detail string, fn func() error) {
+		if skip[action] {
+			return
+		}
+		res := ActionResult{Action: action, Detail: detail}
+		if !e.opts.DryRun {
+			if err := fn(); err != nil {
+				res.Error = err.Error()
+			}
+		}
+		results = append(results, res)
+	}
+
+	if len(a.AddLabels) > 0 {
+		merged := append([]string(nil), conv.Labels...)
+		for _, l := range a.AddLabels {
+			if !oneOf(l, merged) {
+				merged = append(merged, l)
+			}
+		}
+		run("add_labels", strings.Join(a.AddLabels, ","), func() error {
+			return e.client.SetLabels(ctx, conv.ID, merged)
+		})
+		// Later rules see the labels this one added.
+		conv.Labels = merged
+	}
+	if a.Priority != "" {
+		run("priority", a.Priority, func() error {
+			return e.client.SetPriority(ctx, conv.ID, strings.ToLower(a.Priority))
+		})
+	}
+	if a.AssignAgent > 0 || a.AssignTeam > 0 {
+		var parts []string
+		if a.AssignAgent > 0 {
+			parts = append(parts, fmt.Sprintf("agent %d", a.AssignAgent))
+		}
+		if a.AssignTeam > 0 {
+			parts = append(parts, fmt.Sprintf("team %d", a.AssignTeam))
+		}
+		run("assign", strings.Join(parts, ", "), func() error {
+			return e.client.Assign(ctx, conv.ID, a.AssignAgent, a.AssignTeam)
+		})
+		if a.AssignAgent > 0 {
+			id := a.AssignAgent
+			conv.AssigneeID = &id
+		}
+	}
+	if a.Note != "" {
+		run("note", a.Note, func() error {
+			return e.client.AddNote(ctx, conv.ID, a.Note)
+		})
+	}
+	if a.Snooze > 0 {
+		until := e.opts.Now().Add(a.Snooze)
+		run("snooze", until.UTC().Format(time.RFC3339), func() error {
+			return e.client.Snooze(ctx, conv.ID, until)
+		})
+	}
+	return results
+}
@@ -0,0 +1,204 @@
+// Package autopilot evaluates user-defined triage rules against live
+// conversation events and executes the matching actions through the API.
+//
+// Rules can see things Chatwoot's server-side automation cannot: the
+// heuristics urgency and sentiment, how long the customer has been waiting,
+// and contact attributes synced from external systems.
+package autopilot
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Version is the rules file format version.
+const Version = 1
+
+// Trigger events a rule can react to.
+const (
+	EventMessageCreated      = "message.created"
+	EventConversationCreated = "conversation.created"
+	// EventRecheck is a synthetic event fired when a tracked conversation is
+	// re-evaluated on a timer (for wait-time rules).
+	EventRecheck = "recheck"
+)
+
+// DefaultEvents are used when a rule does not list its own.
+var DefaultEvents = []string{EventMessageCreated, EventConversationCreated, EventRecheck}
+
+// RuleSet is the parsed rules file.
+type RuleSet struct {
+	Version int    `yaml:"version" json:"version"`
+	Rules   []Rule `yaml:"rules" json:"rules"`
+}
+
+// Rule is one condition/action pair. All conditions in When must hold.
+type Rule struct {
+	Name   string     `yaml:"name" json:"name"`
+	Events []string   `yaml:"events,omitempty" json:"events,omitempty"`
+	When   Conditions `yaml:"when" json:"when"`
+	Then   Actions    `yaml:"then" json:"then"`
+	// Repeat lets the rule fire more than once per conversation.
+	Repeat bool `yaml:"repeat,omitempty" json:"repeat,omitempty"`
+	// Stop skips the remaining rules once this one matches.
+	Stop bool `yaml:"stop,omitempty" json:"stop,omitempty"`
+}
+
+// Conditions are ANDed together; empty fields are ignored.
+type Conditions struct {
+	InboxIDs []int    `yaml:"inbox_ids,omitempty" json:"inbox_ids,omitempty"`
+	Status   []string `yaml:"status,omitempty" json:"status,omitempty"`
+	// Labels must all be present; LabelsAbsent must all be missing.
+	Labels       []string `yaml:"labels,omitempty" json:"labels,omitempty"`
+	LabelsAbsent []string `yaml:"labels_absent,omitempty" json:"labels_absent,omitempty"`
+	Unassigned   *bool    `yaml:"unassigned,omitempty" json:"unassigned,omitempty"`
+	// Urgency and Sentiment match heuristics.AnalyzeConversation output.
+	Urgency   []string `yaml:"urgency,omitempty" json:"urgency,omitempty"`
+	Sentiment []string `yaml:"sentiment,omitempty" json:"sentiment,omitempty"`
+	// Keywords match (case-insensitive) any of the unanswered customer messages.
+	Keywords []string `yaml:"keywords,omitempty" json:"keywords,omitempty"`
+	// WaitingOver matches when the oldest unanswered customer message is older.
+	WaitingOver time.Duration `yaml:"waiting_over,omitempty" json:"waiting_over,omitempty"`
+	// ContactAttributes compares custom attribute values as strings; a list
+	// value matches any of its entries.
+	ContactAttributes map[string]any `yaml:"contact_attributes,omitempty" json:"contact_attributes,omitempty"`
+}
+
+// Actions run in field order: labels, priority, assignment, note, snooze.
+type Actions struct {
+	AddLabels   []string      `yaml:"add_labels,omitempty" json:"add_labels,omitempty"`
+	Priority    string        `yaml:"priority,omitempty" json:"priority,omitempty"`
+	AssignAgent int           `yaml:"assign_agent,omitempty" json:"assign_agent,omitempty"`
+	AssignTeam  int           `yaml:"assign_team,omitempty" json:"assign_team,omitempty"`
+	Note        string        `yaml:"note,omitempty" json:"note,omitempty"`
+	Snooze      time.Duration `yaml:"snooze,omitempty" json:"snooze,omitempty"`
+}
+
+// Empty reports whether no action is configured.
+func (a Actions) Empty() bool {
+	return len(a.AddLabels) == 0 && a.Priority == "" && a.AssignAgent == 0 &&
+		a.AssignTeam == 0 && a.Note == "" && a.Snooze == 0
+}
+
+var (
+	validEvents     = []string{EventMessageCreated, EventConversationCreated, EventRecheck}
+	validStatuses   = []string{"open", "pending", "snoozed", "resolved"}
+	validUrgency    = []string{"high", "medium", "low"}
+	validSentiments = []string{"satisfied", "frustrated", "neutral", "unknown"}
+	validPriorities = []string{"urgent", "high", "medium", "low", "none"}
+)
+
+// Load reads and validates a rules file (YAML or JSON).
+func Load(path string) (*RuleSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read rules: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse decodes and validates rules. Unknown fields are rejected so typos in
+// conditions do not silently match everything.
+func Parse(data []byte) (*RuleSet, error) {
+	var rs RuleSet
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&rs); err != nil {
+		return nil, fmt.Errorf("parse rules: %w", err)
+	}
+	if rs.Version == 0 {
+		rs.Version = Version
+	}
+	if rs.Version > Version {
+		return nil, fmt.Errorf("rules version %d is newer than supported version %d", rs.Version, Version)
+	}
+	if err := rs.Validate(); err != nil {
+		return nil, err
+	}
+	return &rs, nil
+}
+
+// Validate checks rule names, enum values, and that every rule does something.
+func (rs *RuleSet) Validate() error {
+	if len(rs.Rules) == 0 {
+		return fmt.Errorf("rules file has no rules")
+	}
+	seen := map[string]bool{}
+	for i := range rs.Rules {
+		r := &rs.Rules[i]
+		r.Name = strings.TrimSpace(r.Name)
+		if r.Name == "" {
+			return fmt.Errorf("rule %d: name is required", i+1)
+		}
+		if seen[r.Name] {
+			return fmt.Errorf("rule %q: duplicate name", r.Name)
+		}
+		seen[r.Name] = true
+		if err := r.validate(); err != nil {
+			return fmt.Errorf("rule %q: %w", r.Name, err)
+		}
+	}
+	return nil
+}
+
+func (r *Rule) validate() error {
+	for _, e := range r.Events {
+		if !oneOf(e, validEvents) {
+			return fmt.Errorf("unknown event %q (valid: %s)", e, strings.Join(validEvents, ", "))
+		}
+	}
+	if err := checkEnum("status", r.When.Status, validStatuses); err != nil {
+		return err
+	}
+	if err := checkEnum("urgency", r.When.Urgency, validUrgency); err != nil {
+		return err
+	}
+	if err := checkEnum("sentiment", r.When.Sentiment, validSentiments); err != nil {
+		return err
+	}
+	if r.When.WaitingOver < 0 {
+		return fmt.Errorf("waiting_over must be positive")
+	}
+	if r.Then.Empty() {
+		return fmt.Errorf("no actions in then")
+	}
+	if r.Then.Priority != "" && !oneOf(r.Then.Priority, validPriorities) {
+		return fmt.Errorf("invalid priority %q (valid: %s)", r.Then.Priority, strings.Join(validPriorities, ", "))
+	}
+	if r.Then.Snooze < 0 {
+		return fmt.Errorf("snooze must be positive")
+	}
+	return nil
+}
+
+// triggeredBy reports whether the rule reacts to event.
+func (r *Rule) triggeredBy(event string) bool {
+	events := r.Events
+	if len(events) == 0 {
+		events = DefaultEvents
+	}
+	return oneOf(event, events)
+}
+
+func checkEnum(field string, values, valid []string) error {
+	for _, v := range values {
+		if !oneOf(v, valid) {
+			return fmt.Errorf("invalid %s %q (valid: %s)", field, v, strings.Join(valid, ", "))
+		}
+	}
+	return nil
+}
+
+func oneOf(v string, set []string) bool {
+	for _, s := range set {
+		if strings.EqualFold(strings.TrimSpace(v), s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,269 @@
+// Package balance plans how to spread unassigned conversations across the
+// agents who can take them, weighing each agent's open conversations,
+// availability and capacity cap.
+package balance
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Strategies.
+const (
+	RoundRobin  = "round-robin"  // take turns, least loaded first
+	LeastLoaded = "least-loaded" // always the agent with the lowest weighted load
+	Skill       = "skill"        // least loaded among agents mapped to the conversation's labels
+)
+
+// Strategies lists the supported strategies.
+var Strategies = []string{RoundRobin, LeastLoaded, Skill}
+
+// Availability statuses as reported by Chatwoot.
+const (
+	Online  = "online"
+	Busy    = "busy"
+	Offline = "offline"
+)
+
+// busyWeight scales the load of busy agents: a busy agent with 2 open
+// conversations counts as if it had 4.
+const busyWeight = 0.5
+
+// Agent is an assignment candidate.
+type Agent struct {
+	ID           int    `json:"id"`
+	Name         string `json:"name"`
+	Availability string `json:"availability"`
+	// Open is the number of open conversations already assigned.
+	Open int `json:"open"`
+	// Cap is the most open conversations the agent may have; 0 means no cap.
+	Cap int `json:"cap,omitempty"`
+}
+
+// Conversation is an unassigned conversation to place.
+type Conversation struct {
+	ID     int
+	Labels []string
+}
+
+// Options tune planning.
+type Options struct {
+	Strategy string
+	// Skills maps a conversation label to the IDs of agents who handle it.
+	// Required by the skill strategy.
+	Skills map[string][]int
+	// IncludeBusy lets busy agents take conversations, at half weight.
+	// Offline agents never do.
+	IncludeBusy bool
+}
+
+// Assignment places one conversation with an agent.
+type Assignment struct {
+	ConversationID int    `json:"conversation_id"`
+	AgentID        int    `json:"agent_id"`
+	AgentName      string `json:"agent_name"`
+	// Reason lists the labels that matched under the skill strategy.
+	Reason string `json:"reason,omitempty"`
+}
+
+// Skipped is a conversation the plan could not place.
+type Skipped struct {
+	ConversationID int    `json:"conversation_id"`
+	Reason         string `json:"reason"`
+}
+
+// Load is an agent's share of the plan.
+type Load struct {
+	Agent
+	Eligible bool `json:"eligible"`
+	Assigned int  `json:"assigned"`
+}
+
+// Plan is the outcome of Build.
+type Plan struct {
+	Strategy    string       `json:"strategy"`
+	Assignments []Assignment `json:"assignments"`
+	Skipped     []Skipped    `json:"skipped"`
+	Agents      []Load       `json:"agents"`
+}
+
+// Build places conversations, in the order given, with agents. Offline
+// agents (and busy ones, unless opts.IncludeBusy) are listed in the plan but
+// receive nothing, as do agents who reach their cap.
+//
+// Under the skill strategy, a conversation whose labels map to agents goes
+// to the least loaded of them, and is skipped when none of them can take it;
+// a conversation with no mapped label goes to the least loaded agent.
+func Build(agents []Agent, conversations []Conversation, opts Options) (*Plan, error) {
+	strategy := opts.Strategy
+	if strategy == "" {
+		strategy = LeastLoaded
+	}
+	switch strategy {
+	case RoundRobin, LeastLoaded:
+	case Skill:
+		if len(opts.Skills) == 0 {
+			return nil, fmt.Errorf("the skill strategy needs a labels-to-agents map")
+		}
+	default:
+		return nil, fmt.Errorf("unknown strategy %q (use %s)", strategy, strings.Join(Strategies, ", "))
+	}
+
+	plan := &Plan{Strategy: strategy, Assignments: []Assignment{}, Skipped: []Skipped{}}
+	loads := make([]*Load, 0, len(agents))
+	byID := make(map[int]*Load, len(agents))
+	for _, a := range agents {
+		l := &Load{Agent: a, Eligible: eligible(a.Availability, opts.IncludeBusy)}
+		loads = append(loads, l)
+		byID[a.ID] = l
+	}
+	sort.SliceStable(loads, func(i, j int) bool { return loads[i].ID < loads[j].ID })
+
+	skills := make(map[string][]*Load, len(opts.Skills))
+	for label, ids := range opts.Skills {
+		key := strings.ToLower(strings.TrimSpace(label))
+		// Keep the label even if none of its agents are in the pool, so its
+		// conversations are skipped rather than given to anyone.
+		if _, ok := skills[key]; !ok {
+			skills[key] = nil
+		}
+		for _, id := range ids {
+			if l, ok := byID[id]; ok {
+				skills[key] = append(skills[key], l)
+			}
+		}
+	}
+
+	// Round-robin takes turns in order of load at the start, so the least
+	// loaded agents are first in line.
+	var rotation []*Load
+	next := 0
+	if strategy == RoundRobin {
+		rotation = append(rotation, loads...)
+		sort.SliceStable(rotation, func(i, j int) bool { return score(rotation[i]) < score(rotation[j]) })
+	}
+
+	for _, conv := range conversations {
+		var (
+			pick   *Load
+			reason string
+		)
+		switch strategy {
+		case RoundRobin:
+			for i := 0; i < len(rotation); i++ {
+				l := rotation[(next+i)%len(rotation)]
+				if available(l) {
+					pick = l
+					next = (next + i + 1) % len(rotation)
+					break
+				}
+			}
+		case LeastLoaded:
+			pick = leastLoaded(loads)
+		case Skill:
+			candidates, label := skilledAgents(skills, conv.Labels)
+			if label == "" {
+				pick = leastLoaded(loads)
+				break
+			}
+			pick = leastLoaded(candidates)
+			reason = label
+			if pick == nil {
+				plan.Skipped = append(plan.Skipped, Skipped{ConversationID: conv.ID, Reason: fmt.Sprintf("agents for %s are unavailable or at capacity", label)})
+				continue
+			}
+		}
+		if pick == nil {
+			plan.Skipped = append(plan.Skipped, Skipped{ConversationID: conv.ID, Reason: "no available agent with capacity"})
+			continue
+		}
+		pick.Assigned++
+		plan.Assignments = append(plan.Assignments, Assignment{ConversationID: conv.ID, AgentID: pick.ID, AgentName: pick.Name, Reason: reason})
+	}
+
+	plan.Agents = make([]Load, 0, len(loads))
+	for _, l := range loads {
+		plan.Agents = append(plan.Agents, *l)
+	}
+	return plan, nil
+}
+
+// ParseStrategy normalizes a strategy name, accepting a few spellings.
+func ParseStrategy(s string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "least-loaded", "least_loaded", "leastloaded", "ll":
+		return LeastLoaded, nil
+	case "round-robin", "round_robin", "roundrobin", "rr":
+		return RoundRobin, nil
+	case "skill", "skills", "skill-based":
+		return Skill, nil
+	default:
+		return "", fmt.Errorf("unknown strategy %q (use %s)", s, strings.Join(Strategies, ", "))
+	}
+}
+
+func eligible(availability string, includeBusy bool) bool {
+	switch strings.ToLower(availability) {
+	case Online:
+		return true
+	case Busy:
+		return includeBusy
+	default:
+		return false
+	}
+}
+
+func available(l *Load) bool {
+	if !l.Eligible {
+		return false
+	}
+	return l.Cap <= 0 || l.Open+l.Assigned < l.Cap
+}
+
+// score is an agent's weighted load: open conversations plus those planned
+// so far, scaled up for busy agents.
+func score(l *Load) float64 {
+	load := float64(l.Open + l.Assigned)
+	if strings.EqualFold(l.Availability, Busy) {
+		return load / busyWeight
+	}
+	return load
+}
+
+func leastLoaded(loads []*Load) *Load {
+	var best *Load
+	for _, l := range loads {
+		if !available(l) {
+			continue
+		}
+		if best == nil || score(l) < score(best) || (score(l) == score(best) && l.ID < best.ID) {
+			best = l
+		}
+	}
+	return best
+}
+
+// skilledAgents returns the agents mapped to any of labels, and the labels
+// that matched.
+func skilledAgents(skills map[string][]*Load, labels []string) ([]*Load, string) {
+	var (
+		agents  []*Load
+		matched []string
+	)
+	seen := map[int]bool{}
+	for _, label := range labels {
+		mapped, ok := skills[strings.ToLower(strings.TrimSpace(label))]
+		if !ok {
+			continue
+		}
+		matched = append(matched, label)
+		for _, l := range mapped {
+			if !seen[l.ID] {
+				seen[l.ID] = true
+				agents = append(agents, l)
+			}
+		}
+	}
+	return agents, strings.Join(matched, ",")
+}
@@ -0,0 +1,125 @@
+package balance
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func convs(ids ...int) []Conversation {
+	out := make([]Conversation, len(ids))
+	for i, id := range ids {
+		out[i] = Conversation{ID: id}
+	}
+	return out
+}
+
+func assigned(p *Plan) map[int]int {
+	out := map[int]int{}
+	for _, a := range p.Assignments {
+		out[a.ConversationID] = a.AgentID
+	}
+	return out
+}
+
+func TestBuildLeastLoaded(t *testing.T) {
+	agents := []Agent{
+		{ID: 1, Name: "Ann", Availability: Online, Open: 4},
+		{ID: 2, Name: "Bo", Availability: Online, Open: 1},
+		{ID: 3, Name: "Cy", Availability: Offline, Open: 0},
+		{ID: 4, Name: "Di", Availability: Busy, Open: 1},
+	}
+	plan, err := Build(agents, convs(10, 11, 12, 13, 14), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Bo takes conversations until level with Ann; offline Cy and busy Di
+	// (without IncludeBusy) get nothing.
+	want := map[int]int{10: 2, 11: 2, 12: 2, 13: 1, 14: 2}
+	if got := assigned(plan); !reflect.DeepEqual(got, want) {
+		t.Fatalf("assignments = %v, want %v", got, want)
+	}
+	if plan.Strategy != LeastLoaded || plan.Agents[2].Eligible || plan.Agents[2].Assigned != 0 {
+		t.Fatalf("unexpected plan %+v", plan)
+	}
+
+	plan, err = Build(agents, convs(10, 11), Options{IncludeBusy: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Di's one open conversation counts double, tying with Bo after one more.
+	if got := assigned(plan); !reflect.DeepEqual(got, map[int]int{10: 2, 11: 2}) {
+		t.Fatalf("busy weighting: assignments = %v", got)
+	}
+}
+
+func TestBuildRoundRobinWithCaps(t *testing.T) {
+	agents := []Agent{
+		{ID: 1, Availability: Online, Open: 3, Cap: 4},
+		{ID: 2, Availability: Online, Open: 0},
+		{ID: 3, Availability: Online, Open: 1},
+	}
+	plan, err := Build(agents, convs(1, 2, 3, 4, 5, 6), Options{Strategy: RoundRobin})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Turn order by starting load is 2, 3, 1; agent 1 is full after one.
+	want := map[int]int{1: 2, 2: 3, 3: 1, 4: 2, 5: 3, 6: 2}
+	if got := assigned(plan); !reflect.DeepEqual(got, want) {
+		t.Fatalf("assignments = %v, want %v", got, want)
+	}
+
+	agents = []Agent{{ID: 1, Availability: Online, Open: 2, Cap: 2}}
+	plan, err = Build(agents, convs(1), Options{Strategy: RoundRobin})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Assignments) != 0 || len(plan.Skipped) != 1 || !strings.Contains(plan.Skipped[0].Reason, "capacity") {
+		t.Fatalf("expected conversation to be skipped, got %+v", plan)
+	}
+}
+
+func TestBuildSkill(t *testing.T) {
+	agents := []Agent{
+		{ID: 1, Availability: Online, Open: 5},
+		{ID: 2, Availability: Online, Open: 0},
+		{ID: 3, Availability: Online, Open: 2, Cap: 2},
+	}
+	conversations := []Conversation{
+		{ID: 10, Labels: []string{"Billing"}},
+		{ID: 11, Labels: []string{"vip"}},
+		{ID: 12, Labels: []string{"other"}},
+		{ID: 13, Labels: []string{"legal"}},
+	}
+	plan, err := Build(agents, conversations, Options{
+		Strategy: Skill,
+		Skills:   map[string][]int{"billing": {1}, "vip": {3}, "legal": {99}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := assigned(plan); !reflect.DeepEqual(got, map[int]int{10: 1, 12: 2}) {
+		t.Fatalf("assignments = %v", got)
+	}
+	if plan.Assignments[0].Reason != "Billing" {
+		t.Errorf("reason = %q, want the matched label", plan.Assignments[0].Reason)
+	}
+	if len(plan.Skipped) != 2 || plan.Skipped[0].ConversationID != 11 || plan.Skipped[1].ConversationID != 13 {
+		t.Fatalf("skipped = %+v", plan.Skipped)
+	}
+
+	if _, err := Build(agents, conversations, Options{Strategy: Skill}); err == nil {
+		t.Error("skill strategy without a map should fail")
+	}
+}
+
+func TestParseStrategy(t *testing.T) {
+	for in, want := range map[string]string{"": LeastLoaded, "rr": RoundRobin, "Round-Robin": RoundRobin, "skills": Skill} {
+		if got, err := ParseStrategy(in); err != nil || got != want {
+			t.Errorf("ParseStrategy(%q) = %q, %v", in, got, err)
+		}
+	}
+	if _, err := ParseStrategy("random"); err == nil {
+		t.Error("expected error for unknown strategy")
+	}
+}
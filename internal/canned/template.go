@@ -0,0 +1,183 @@
+// Package canned renders canned response templates that use Chatwoot's
+// {{variable}} syntax, such as {{contact.name}} or {{agent.name}}.
+package canned
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+)
+
+// placeholderPattern matches {{ ... }} with optional inner whitespace.
+var placeholderPattern = regexp.MustCompile(`\{\{\s*(.*?)\s*\}\}`)
+
+// defaultFilterPattern matches Chatwoot's "| default: 'text'" filter.
+var defaultFilterPattern = regexp.MustCompile(`^default\s*:\s*(?:'([^']*)'|"([^"]*)")$`)
+
+// Data is what a template is rendered against. Any field may be nil, in
+// which case its variables are unresolved.
+type Data struct {
+	Contact      *api.Contact
+	Agent        *api.Profile
+	Conversation *api.Conversation
+	Inbox        *api.Inbox
+}
+
+// UnresolvedError lists variables that had no value when rendering.
+type UnresolvedError struct {
+	Variables []string
+}
+
+func (e *UnresolvedError) Error() string {
+	return fmt.Sprintf("unresolved template variable(s): %s", strings.Join(e.Variables, ", "))
+}
+
+// Vars flattens d into the variable names a template can use:
+//
+//	contact.id, contact.name, contact.first_name, contact.last_name,
+//	contact.email, contact.phone, contact.identifier,
+//	contact.custom_attribute.<key>
+//	agent.id, agent.name, agent.first_name, agent.last_name, agent.email
+//	conversation.id, conversation.status, conversation.custom_attribute.<key>
+//	inbox.id, inbox.name
+//
+// Empty values are left out so they count as unresolved.
+func (d Data) Vars() map[string]string {
+	vars := map[string]string{}
+	set := func(name, value string) {
+		if value = strings.TrimSpace(value); value != "" {
+			vars[name] = value
+		}
+	}
+	setAttributes := func(prefix string, attrs map[string]any) {
+		for k, v := range attrs {
+			set(prefix+k, formatValue(v))
+		}
+	}
+
+	if c := d.Contact; c != nil {
+		set("contact.id", strconv.Itoa(c.ID))
+		set("contact.name", c.Name)
+		first, last := splitName(c.Name)
+		set("contact.first_name", first)
+		set("contact.last_name", last)
+		set("contact.email", c.Email)
+		set("contact.phone", c.PhoneNumber)
+		set("contact.identifier", c.Identifier)
+		setAttributes("contact.custom_attribute.", c.CustomAttributes)
+	}
+	if a := d.Agent; a != nil {
+		set("agent.id", strconv.Itoa(a.ID))
+		set("agent.name", a.Name)
+		first, last := splitName(a.Name)
+		set("agent.first_name", first)
+		set("agent.last_name", last)
+		set("agent.email", a.Email)
+	}
+	if c := d.Conversation; c != nil {
+		// Chatwoot shows the display ID to agents and customers.
+		id := c.ID
+		if c.DisplayID != nil {
+			id = *c.DisplayID
+		}
+		set("conversation.id", strconv.Itoa(id))
+		set("conversation.status", c.Status)
+		setAttributes("conversation.custom_attribute.", c.CustomAttributes)
+	}
+	if i := d.Inbox; i != nil {
+		set("inbox.id", strconv.Itoa(i.ID))
+		set("inbox.name", i.Name)
+	}
+	return vars
+}
+
+// Variables returns the distinct variable names used in tmpl, in order of
+// first appearance.
+func Variables(tmpl string) []string {
+	var names []string
+	seen := map[string]bool{}
+	for _, m := range placeholderPattern.FindAllStringSubmatch(tmpl, -1) {
+		name, _, _ := strings.Cut(m[1], "|")
+		name = strings.TrimSpace(name)
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Render substitutes variables in tmpl. A "| default: 'text'" filter is used
+// when a variable has no value. Variables that are still unresolved are
+// returned together in an *UnresolvedError, so nothing is sent with a
+// placeholder or a blank left in it.
+func Render(tmpl string, vars map[string]string) (string, error) {
+	var (
+		unresolved []string
+		filterErr  error
+	)
+	out := placeholderPattern.ReplaceAllStringFunc(tmpl, func(match string) string {
+		expr := placeholderPattern.FindStringSubmatch(match)[1]
+		name, filter, hasFilter := strings.Cut(expr, "|")
+		name = strings.TrimSpace(name)
+		var fallback []string
+		if hasFilter {
+			filter = strings.TrimSpace(filter)
+			if fallback = defaultFilterPattern.FindStringSubmatch(filter); fallback == nil {
+				if filterErr == nil {
+					filterErr = fmt.Errorf("unsupported template filter %q in {{%s}}; only default: 'text' is supported", filter, expr)
+				}
+				return match
+			}
+		}
+		if value, ok := vars[name]; ok {
+			return value
+		}
+		if fallback != nil {
+			return fallback[1] + fallback[2]
+		}
+		if !slices.Contains(unresolved, name) {
+			unresolved = append(unresolved, name)
+		}
+		return match
+	})
+	if filterErr != nil {
+		return "", filterErr
+	}
+	if len(unresolved) > 0 {
+		sort.Strings(unresolved)
+		return "", &UnresolvedError{Variables: unresolved}
+	}
+	return out, nil
+}
+
+// splitName splits a full name into first name and the rest.
+func splitName(name string) (string, string) {
+	fields := strings.Fields(name)
+	if len(fields) == 0 {
+		return "", ""
+	}
+	return fields[0], strings.Join(fields[1:], " ")
+}
+
+// formatValue renders a custom attribute value. JSON numbers arrive as
+// float64, so whole numbers are printed without a decimal point.
+func formatValue(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(val)
+	default:
+		return fmt.Sprint(val)
+	}
+}
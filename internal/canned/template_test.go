@@ -0,0 +1,102 @@
+package canned
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+)
+
+func testData() Data {
+	displayID := 42
+	return Data{
+		Contact: &api.Contact{
+			ID:    7,
+			Name:  "Ada Lovelace",
+			Email: "ada@example.com",
+			CustomAttributes: map[string]any{
+				"plan":  "pro",
+				"seats": float64(12),
+				"vip":   true,
+				"notes": "",
+			},
+		},
+		Agent:        &api.Profile{ID: 3, Name: "Grace Hopper", Email: "grace@example.com"},
+		Conversation: &api.Conversation{ID: 1001, DisplayID: &displayID, Status: "open", CustomAttributes: map[string]any{"order_id": "A-17"}},
+		Inbox:        &api.Inbox{ID: 2, Name: "Support"},
+	}
+}
+
+func TestRender(t *testing.T) {
+	vars := testData().Vars()
+	tests := []struct {
+		name string
+		tmpl string
+		want string
+	}{
+		{"plain", "Thanks!", "Thanks!"},
+		{"contact and agent", "Hi {{contact.first_name}}, this is {{agent.name}}.", "Hi Ada, this is Grace Hopper."},
+		{"whitespace", "Hi {{ contact.name }}", "Hi Ada Lovelace"},
+		{"display id", "Ref #{{conversation.id}}", "Ref #42"},
+		{"custom attributes", "{{contact.custom_attribute.plan}} x{{contact.custom_attribute.seats}} vip={{contact.custom_attribute.vip}} order {{conversation.custom_attribute.order_id}}", "pro x12 vip=true order A-17"},
+		{"inbox", "Welcome to {{inbox.name}}", "Welcome to Support"},
+		{"default unused", "Hi {{contact.name | default: 'there'}}", "Hi Ada Lovelace"},
+		{"default used", "Hi {{contact.phone | default: 'there'}}", "Hi there"},
+		{"default double quotes", `Plan: {{contact.custom_attribute.notes | default: "n/a"}}`, "Plan: n/a"},
+		{"unicode", "您好 {{contact.last_name}}", "您好 Lovelace"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Render(tt.tmpl, vars)
+			if err != nil {
+				t.Fatalf("Render: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("Render = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderUnresolved(t *testing.T) {
+	vars := Data{Contact: &api.Contact{ID: 7, Name: "Ada"}}.Vars()
+	_, err := Render("Hi {{contact.name}} ({{contact.email}}), I'm {{agent.name}}. {{contact.email}} {{contact.custom_attribute.plan}}", vars)
+	var unresolved *UnresolvedError
+	if !errors.As(err, &unresolved) {
+		t.Fatalf("expected UnresolvedError, got %v", err)
+	}
+	want := []string{"agent.name", "contact.custom_attribute.plan", "contact.email"}
+	if !reflect.DeepEqual(unresolved.Variables, want) {
+		t.Fatalf("Variables = %v, want %v", unresolved.Variables, want)
+	}
+	if !strings.Contains(err.Error(), "agent.name, contact.custom_attribute.plan, contact.email") {
+		t.Fatalf("unexpected message: %v", err)
+	}
+}
+
+func TestRenderUnsupportedFilter(t *testing.T) {
+	_, err := Render("Hi {{contact.name | upcase}}", testData().Vars())
+	if err == nil || !strings.Contains(err.Error(), `unsupported template filter "upcase"`) {
+		t.Fatalf("expected unsupported filter error, got %v", err)
+	}
+}
+
+func TestVarsWithoutDisplayID(t *testing.T) {
+	vars := Data{Conversation: &api.Conversation{ID: 9}}.Vars()
+	if vars["conversation.id"] != "9" {
+		t.Fatalf("conversation.id = %q, want 9", vars["conversation.id"])
+	}
+	if _, ok := vars["conversation.status"]; ok {
+		t.Fatal("empty status should be left unresolved")
+	}
+}
+
+func TestVariables(t *testing.T) {
+	got := Variables("{{ contact.name }} {{agent.name}} {{contact.name | default: 'x'}} {{conversation.id}}")
+	want := []string{"contact.name", "agent.name", "conversation.id"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Variables = %v, want %v", got, want)
+	}
+}
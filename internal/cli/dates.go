@@ -14,8 +14,13 @@ var relativeAgoRegex = regexp.MustCompile(`^(\d+)(mo|w|d|h|m)\s*ago$`)
 // Matches: "30m", "2h", "1d" (future, for reminders)
 var relativeFutureRegex = regexp.MustCompile(`^(\d+)(mo|w|d|h|m)$`)
 
+// Matches a time of day: "9am", "9:30pm", "17:00"
+var clockRegex = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(am|pm)?$`)
+
 // ParseRelativeTime parses human-friendly time expressions.
-// Supports: "2h ago", "yesterday", "monday", "next tue", "30m", RFC3339.
+// Supports: "2h ago", "yesterday", "monday", "next tue", "30m", RFC3339,
+// and a day followed by a time of day: "tomorrow 9am", "fri at 14:30",
+// "2026-01-27 17:00". A time of day alone means today.
 func ParseRelativeTime(s string, now time.Time) (time.Time, error) {
 	raw := strings.TrimSpace(s)
 	if raw == "" {
@@ -24,6 +29,18 @@ func ParseRelativeTime(s string, now time.Time) (time.Time, error) {
 
 	input := strings.ToLower(raw)
 
+	if day, hour, minute, ok := splitClock(input); ok {
+		base := startOfDay(now)
+		if day != "" {
+			t, err := ParseRelativeTime(day, now)
+			if err != nil {
+				return time.Time{}, fmt.Errorf("invalid time expression %q", raw)
+			}
+			base = t
+		}
+		return time.Date(base.Year(), base.Month(), base.Day(), hour, minute, 0, 0, base.Location()), nil
+	}
+
 	switch input {
 	case "yesterday":
 		return startOfDay(now).AddDate(0, 0, -1), nil
@@ -64,6 +81,56 @@ func ParseRelativeTime(s string, now time.Time) (time.Time, error) {
 	return time.Time{}, fmt.Errorf("invalid time expression %q", raw)
 }
 
+// splitClock splits a trailing time of day off expr, returning the day part
+// (possibly empty) and the hour and minute.
+func splitClock(expr string) (day string, hour, minute int, ok bool) {
+	fields := strings.Fields(expr)
+	if len(fields) == 0 {
+		return "", 0, 0, false
+	}
+	clock := fields[len(fields)-1]
+	fields = fields[:len(fields)-1]
+	// "9 am"
+	if (clock == "am" || clock == "pm") && len(fields) > 0 {
+		clock = fields[len(fields)-1] + clock
+		fields = fields[:len(fields)-1]
+	}
+	switch clock {
+	case "noon":
+		clock = "12:00"
+	case "midnight":
+		clock = "0:00"
+	}
+	m := clockRegex.FindStringSubmatch(clock)
+	// A bare number is not a time of day; require ":mm" or am/pm.
+	if m == nil || (m[2] == "" && m[3] == "") {
+		return "", 0, 0, false
+	}
+	hour, _ = strconv.Atoi(m[1])
+	if m[2] != "" {
+		minute, _ = strconv.Atoi(m[2])
+	}
+	switch m[3] {
+	case "am", "pm":
+		if hour < 1 || hour > 12 {
+			return "", 0, 0, false
+		}
+		if hour == 12 {
+			hour = 0
+		}
+		if m[3] == "pm" {
+			hour += 12
+		}
+	}
+	if hour > 23 || minute > 59 {
+		return "", 0, 0, false
+	}
+	if len(fields) > 0 && fields[len(fields)-1] == "at" {
+		fields = fields[:len(fields)-1]
+	}
+	return strings.Join(fields, " "), hour, minute, true
+}
+
 // Helper functions (from gogcli)
 func startOfDay(t time.Time) time.Time {
 	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
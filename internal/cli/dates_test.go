@@ -78,6 +78,41 @@ func TestParseRelativeTime(t *testing.T) {
 			input: "2026-01-27T10:00:00Z",
 			want:  time.Date(2026, 1, 27, 10, 0, 0, 0, time.UTC),
 		},
+		{
+			name:  "tomorrow at am time",
+			input: "tomorrow 9am",
+			want:  time.Date(2026, 1, 29, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "weekday at 24h time",
+			input: "fri at 14:30",
+			want:  time.Date(2026, 1, 30, 14, 30, 0, 0, time.UTC),
+		},
+		{
+			name:  "next weekday pm with space",
+			input: "Next Monday 5:15 PM",
+			want:  time.Date(2026, 2, 2, 17, 15, 0, 0, time.UTC),
+		},
+		{
+			name:  "date and time",
+			input: "2026-02-10 08:05",
+			want:  time.Date(2026, 2, 10, 8, 5, 0, 0, time.UTC),
+		},
+		{
+			name:  "time only means today",
+			input: "11pm",
+			want:  time.Date(2026, 1, 28, 23, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "noon and midnight",
+			input: "tomorrow noon",
+			want:  time.Date(2026, 1, 29, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "twelve am",
+			input: "today 12am",
+			want:  time.Date(2026, 1, 28, 0, 0, 0, 0, time.UTC),
+		},
 	}
 
 	for _, tt := range tests {
@@ -94,9 +129,10 @@ func TestParseRelativeTime(t *testing.T) {
 }
 
 func TestParseRelativeTime_Invalid(t *testing.T) {
-	_, err := ParseRelativeTime("not-a-date", time.Now())
-	if err == nil {
-		t.Fatal("expected error for invalid input")
+	for _, input := range []string{"not-a-date", "tomorrow 25:00", "13pm", "someday 9am", "tomorrow 9"} {
+		if _, err := ParseRelativeTime(input, time.Now()); err == nil {
+			t.Errorf("expected error for %q", input)
+		}
 	}
 }
 
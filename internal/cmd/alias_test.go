@@ -65,6 +65,14 @@ func buildFullRootCmd() *cobra.Command {
 	root.AddCommand(newRefCmd())
 	root.AddCommand(newSnoozeCmd())
 	root.AddCommand(newHandoffCmd())
+	root.AddCommand(newSyncCmd())
+	root.AddCommand(newMigrateCmd())
+	root.AddCommand(newAutopilotCmd())
+	root.AddCommand(newSLACmd())
+	root.AddCommand(newMCPCmd())
+	root.AddCommand(newDevCmd())
+	root.AddCommand(newHistoryCmd())
+	root.AddCommand(newUndoCmd())
 
 	return root
 }
@@ -25,7 +25,10 @@ func newAssignCmd() *cobra.Command {
 		Long: `Assign a conversation to an agent and/or team.
 
 This is a convenience shortcut for 'cw conversations assign'.
-At least one of --agent or --team must be specified.`,
+At least one of --agent or --team must be specified.
+
+To spread an inbox's unassigned conversations across its online agents,
+see 'cw assign balance'.`,
 		Example: strings.TrimSpace(`
   # Assign to an agent
   cw assign 123 --agent 5
@@ -170,6 +173,8 @@ At least one of --agent or --team must be specified.`,
 	flagAlias(cmd.Flags(), "light", "li")
 	registerCommandContract(cmd, true, true)
 
+	cmd.AddCommand(newAssignBalanceCmd())
+
 	return cmd
 }
 
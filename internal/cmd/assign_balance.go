@@ -0,0 +1,450 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+	"github.com/chatwoot/chatwoot-cli/internal/balance"
+	"github.com/chatwoot/chatwoot-cli/internal/dryrun"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+func newAssignBalanceCmd() *cobra.Command {
+	var (
+		inbox       string
+		team        string
+		strategy    string
+		skillsFile  string
+		skillPairs  []string
+		maxOpen     int
+		capPairs    []string
+		includeBusy bool
+		limit       int
+		force       bool
+		concurrency int
+		progress    bool
+		noProgress  bool
+	)
+
+	cmd := &cobra.Command{
+		Use:     "balance",
+		Aliases: []string{"bal"},
+		Short:   "Spread unassigned open conversations across online agents",
+		Long: strings.TrimSpace(`
+Plan, then apply, the assignment of an inbox's unassigned open conversations
+to its members who are online.
+
+Agents come from the inbox's members (and, with --team, only those who are
+also on the team). Each agent's current open conversations and availability
+come from the live agent report. Offline agents never receive conversations;
+busy agents only do with --include-busy, and their open count weighs double.
+An agent at --max-open (or their --cap) receives nothing more.
+
+Strategies:
+  least-loaded  each conversation goes to the agent with the lowest load
+  round-robin   agents take turns, least loaded first
+  skill         conversations go to the least loaded agent mapped to one of
+                their labels (--skill / --skills); when those agents can't
+                take it, the conversation is left unassigned. Conversations
+                without a mapped label are placed least-loaded.
+
+Conversations are placed oldest first. The plan is printed before anything
+changes; use --dry-run to stop there.
+
+The --skills file maps labels to agent IDs, names or emails (YAML or JSON):
+
+  billing: [ann@example.com, 7]
+  vip: [Bo]
+`),
+		Example: strings.TrimSpace(`
+  # Preview a least-loaded plan for the Support inbox
+  cw assign balance --inbox Support --dry-run
+
+  # Round-robin within a team, capping everyone at 8 open conversations
+  cw assign balance --inbox Support --team Billing --strategy round-robin --max-open 8
+
+  # Skill-based, with a shift cap for one agent
+  cw assign balance --inbox 3 --strategy skill --skill billing=ann@example.com,7 --skill vip=Bo --cap Bo=4
+
+  # Apply without prompting
+  cw assign balance --inbox 3 --skills skills.yaml --strategy skill --force
+`),
+		Args: cobra.NoArgs,
+		RunE: RunE(func(cmd *cobra.Command, _ []string) error {
+			strategyName, err := balance.ParseStrategy(strategy)
+			if err != nil {
+				return err
+			}
+			if maxOpen < 0 {
+				return fmt.Errorf("--max-open must be >= 0")
+			}
+			if limit < 0 {
+				return fmt.Errorf("--limit must be >= 0")
+			}
+			skillSpec, err := loadBalanceSkills(skillsFile, skillPairs)
+			if err != nil {
+				return err
+			}
+			if strategyName == balance.Skill && len(skillSpec) == 0 {
+				return fmt.Errorf("--strategy skill requires --skill or --skills")
+			}
+			capSpec, err := parseBalanceCaps(capPairs)
+			if err != nil {
+				return err
+			}
+
+			client, err := getClient()
+			if err != nil {
+				return err
+			}
+			ctx := cmdContext(cmd)
+
+			inboxID, err := resolveInboxID(ctx, client, inbox)
+			if err != nil {
+				return err
+			}
+			teamID, err := resolveTeamID(ctx, client, team)
+			if err != nil {
+				return err
+			}
+
+			agents, err := loadBalanceAgents(ctx, client, inboxID, teamID)
+			if err != nil {
+				return err
+			}
+			caps, err := resolveBalanceAgentMap(ctx, client, capSpec)
+			if err != nil {
+				return err
+			}
+			for i := range agents {
+				agents[i].Cap = maxOpen
+				if c, ok := caps[agents[i].ID]; ok {
+					agents[i].Cap = c
+				}
+			}
+			skills := make(map[string][]int, len(skillSpec))
+			for label, identifiers := range skillSpec {
+				for _, identifier := range identifiers {
+					id, err := resolveAgentID(ctx, client, identifier)
+					if err != nil {
+						return fmt.Errorf("skill %q: %w", label, err)
+					}
+					skills[label] = append(skills[label], id)
+				}
+			}
+
+			conversations, err := loadUnassignedConversations(ctx, client, inboxID, teamID, limit)
+			if err != nil {
+				return err
+			}
+
+			plan, err := balance.Build(agents, conversations, balance.Options{
+				Strategy:    strategyName,
+				Skills:      skills,
+				IncludeBusy: includeBusy,
+			})
+			if err != nil {
+				return err
+			}
+
+			dryRun := dryrun.IsEnabled(ctx)
+			if len(plan.Assignments) == 0 || dryRun {
+				if isJSON(cmd) {
+					return printJSON(cmd, plan)
+				}
+				printBalancePlan(cmd, plan, len(conversations))
+				return nil
+			}
+
+			if err := requireForceForJSON(cmd, force); err != nil {
+				return err
+			}
+			if !isJSON(cmd) {
+				printBalancePlan(cmd, plan, len(conversations))
+			}
+			ok, err := confirmAction(cmd, confirmOptions{
+				Prompt:              fmt.Sprintf("\nAssign %d conversations? (y/N): ", len(plan.Assignments)),
+				CancelMessage:       "Cancelled.",
+				Force:               force,
+				RequireForceForJSON: true,
+			})
+			if err != nil || !ok {
+				return err
+			}
+
+			agentFor := make(map[int]int, len(plan.Assignments))
+			ids := make([]int, 0, len(plan.Assignments))
+			for _, a := range plan.Assignments {
+				agentFor[a.ConversationID] = a.AgentID
+				ids = append(ids, a.ConversationID)
+			}
+			results := runBulkOperation(
+				ctx,
+				ids,
+				int64(concurrency),
+				bulkProgressEnabled(cmd, progress, noProgress),
+				cmd.ErrOrStderr(),
+				func(ctx context.Context, id int) (any, error) {
+					result, err := client.Conversations().Assign(ctx, id, agentFor[id], 0)
+					if err != nil {
+						_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Failed to assign conversation %d: %v\n", id, err)
+						return nil, err
+					}
+					return result, nil
+				},
+			)
+
+			successCount, failCount := countResults(results)
+			output := buildBulkConversationResultRows(results, func(item map[string]any, r BulkResult) {
+				item["agent_id"] = agentFor[r.ID]
+			})
+			if isJSON(cmd) {
+				return printJSON(cmd, map[string]any{
+					"plan":          plan,
+					"success_count": successCount,
+					"fail_count":    failCount,
+					"results":       output,
+				})
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Assigned %d conversations (%d failed)\n", successCount, failCount)
+			return nil
+		}),
+	}
+
+	cmd.Flags().StringVar(&inbox, "inbox", "", "Inbox ID or name to balance (required)")
+	cmd.Flags().StringVar(&team, "team", "", "Only conversations and agents of this team (ID or name)")
+	cmd.Flags().StringVar(&strategy, "strategy", balance.LeastLoaded, "Strategy: least-loaded|round-robin|skill")
+	cmd.Flags().StringVar(&skillsFile, "skills", "", "YAML/JSON file mapping labels to agent IDs, names or emails")
+	cmd.Flags().StringArrayVar(&skillPairs, "skill", nil, "Map a label to agents: label=agent[,agent...] (repeatable)")
+	cmd.Flags().IntVar(&maxOpen, "max-open", 0, "Most open conversations any agent may have (0 = no cap)")
+	cmd.Flags().StringArrayVar(&capPairs, "cap", nil, "Per-agent cap overriding --max-open: agent=N (repeatable)")
+	cmd.Flags().BoolVar(&includeBusy, "include-busy", false, "Also assign to busy agents (their load weighs double)")
+	cmd.Flags().IntVar(&limit, "limit", 0, "Place at most this many conversations, oldest first (0 = all)")
+	cmd.Flags().BoolVarP(&force, "force", "F", false, "Skip confirmation prompt (required with --output json)")
+	cmd.Flags().IntVar(&concurrency, "concurrency", DefaultConcurrency, "Max concurrent assignments")
+	cmd.Flags().BoolVar(&progress, "progress", true, "Show progress while running")
+	cmd.Flags().BoolVar(&noProgress, "no-progress", false, "Disable progress output")
+	flagAlias(cmd.Flags(), "inbox", "ib")
+	flagAlias(cmd.Flags(), "team", "tm")
+	flagAlias(cmd.Flags(), "strategy", "strat")
+	flagAlias(cmd.Flags(), "skills", "sk")
+	flagAlias(cmd.Flags(), "max-open", "mxo")
+	flagAlias(cmd.Flags(), "include-busy", "ibz")
+	flagAlias(cmd.Flags(), "limit", "lt")
+	flagAlias(cmd.Flags(), "concurrency", "cc")
+	flagAlias(cmd.Flags(), "progress", "prg")
+	flagAlias(cmd.Flags(), "no-progress", "npr")
+	_ = cmd.MarkFlagRequired("inbox")
+	registerCommandContract(cmd, true, true)
+
+	return cmd
+}
+
+// loadBalanceAgents returns the inbox's members (narrowed to the team's, if
+// teamID is set) with their availability and open conversations from the
+// live agent report.
+func loadBalanceAgents(ctx context.Context, client *api.Client, inboxID, teamID int) ([]balance.Agent, error) {
+	members, err := client.Inboxes().ListMembers(ctx, inboxID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list inbox members: %w", err)
+	}
+	if teamID > 0 {
+		teamMembers, err := client.Teams().ListMembers(ctx, teamID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list team members: %w", err)
+		}
+		onTeam := make(map[int]bool, len(teamMembers))
+		for _, m := range teamMembers {
+			onTeam[m.ID] = true
+		}
+		kept := members[:0]
+		for _, m := range members {
+			if onTeam[m.ID] {
+				kept = append(kept, m)
+			}
+		}
+		members = kept
+	}
+	if len(members) == 0 {
+		if teamID > 0 {
+			return nil, fmt.Errorf("no agents to balance across: none of the inbox's members are on the team")
+		}
+		return nil, fmt.Errorf("no agents to balance across: the inbox has no members")
+	}
+
+	metrics, err := client.Reports().AgentMetrics(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load agent workload: %w", err)
+	}
+	byID := make(map[int]api.AgentMetrics, len(metrics))
+	for _, m := range metrics {
+		byID[m.ID] = m
+	}
+
+	agents := make([]balance.Agent, 0, len(members))
+	for _, m := range members {
+		a := balance.Agent{ID: m.ID, Name: m.Name, Availability: m.AvailabilityStatus}
+		if metric, ok := byID[m.ID]; ok {
+			a.Open = metric.Metric.Open
+			if metric.Availability != "" {
+				a.Availability = metric.Availability
+			}
+		}
+		if a.Availability == "" {
+			a.Availability = balance.Offline
+		}
+		agents = append(agents, a)
+	}
+	return agents, nil
+}
+
+// loadUnassignedConversations returns the inbox's unassigned open
+// conversations, oldest first, stopping after limit when it is positive.
+func loadUnassignedConversations(ctx context.Context, client *api.Client, inboxID, teamID, limit int) ([]balance.Conversation, error) {
+	params := api.ListConversationsParams{Status: "open", AssigneeType: "unassigned", InboxID: strconv.Itoa(inboxID)}
+	if teamID > 0 {
+		params.TeamID = strconv.Itoa(teamID)
+	}
+	var convs []api.Conversation
+	for page := 1; ; page++ {
+		params.Page = page
+		result, err := client.Conversations().List(ctx, params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list unassigned conversations: %w", err)
+		}
+		items := result.Data.Payload
+		for _, c := range items {
+			// The list endpoint's unassigned filter is trusted, but a
+			// conversation assigned since is never reassigned.
+			if c.AssigneeID == nil || *c.AssigneeID == 0 {
+				convs = append(convs, c)
+			}
+		}
+		totalPages := int(result.Data.Meta.TotalPages)
+		if len(items) == 0 || totalPages == 0 || page >= totalPages {
+			break
+		}
+	}
+
+	sort.SliceStable(convs, func(i, j int) bool { return convs[i].CreatedAt < convs[j].CreatedAt })
+	if limit > 0 && len(convs) > limit {
+		convs = convs[:limit]
+	}
+	out := make([]balance.Conversation, 0, len(convs))
+	for _, c := range convs {
+		out = append(out, balance.Conversation{ID: c.ID, Labels: c.Labels})
+	}
+	return out, nil
+}
+
+// loadBalanceSkills merges the --skills file with --skill label=agents pairs
+// into a map of label to agent identifiers.
+func loadBalanceSkills(path string, pairs []string) (map[string][]string, error) {
+	skills := map[string][]string{}
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read --skills file: %w", err)
+		}
+		var fromFile map[string][]string
+		if err := yaml.Unmarshal(data, &fromFile); err != nil {
+			return nil, fmt.Errorf("invalid --skills file %s: %w", path, err)
+		}
+		for label, agents := range fromFile {
+			skills[label] = append(skills[label], agents...)
+		}
+	}
+	for _, pair := range pairs {
+		label, agents, ok := strings.Cut(pair, "=")
+		label = strings.TrimSpace(label)
+		if !ok || label == "" || strings.TrimSpace(agents) == "" {
+			return nil, fmt.Errorf("invalid --skill %q (use label=agent[,agent...])", pair)
+		}
+		for _, agent := range strings.Split(agents, ",") {
+			if agent = strings.TrimSpace(agent); agent != "" {
+				skills[label] = append(skills[label], agent)
+			}
+		}
+	}
+	return skills, nil
+}
+
+// parseBalanceCaps parses --cap agent=N pairs into agent identifier → cap.
+func parseBalanceCaps(pairs []string) (map[string]int, error) {
+	caps := make(map[string]int, len(pairs))
+	for _, pair := range pairs {
+		agent, value, ok := strings.Cut(pair, "=")
+		agent = strings.TrimSpace(agent)
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if !ok || agent == "" || err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid --cap %q (use agent=N)", pair)
+		}
+		caps[agent] = n
+	}
+	return caps, nil
+}
+
+// resolveBalanceAgentMap resolves the agent identifiers of caps to IDs.
+func resolveBalanceAgentMap(ctx context.Context, client *api.Client, caps map[string]int) (map[int]int, error) {
+	out := make(map[int]int, len(caps))
+	for identifier, n := range caps {
+		id, err := resolveAgentID(ctx, client, identifier)
+		if err != nil {
+			return nil, fmt.Errorf("--cap %s: %w", identifier, err)
+		}
+		out[id] = n
+	}
+	return out, nil
+}
+
+func printBalancePlan(cmd *cobra.Command, plan *balance.Plan, unassigned int) {
+	out := cmd.OutOrStdout()
+	_, _ = fmt.Fprintf(out, "Strategy %s: %d of %d unassigned conversations placed across %d agents\n\n",
+		plan.Strategy, len(plan.Assignments), unassigned, len(plan.Agents))
+
+	w := newTabWriterFromCmd(cmd)
+	_, _ = fmt.Fprintln(w, "AGENT\tSTATUS\tOPEN\tCAP\tASSIGN\tAFTER")
+	for _, a := range plan.Agents {
+		status := a.Availability
+		if !a.Eligible {
+			status += " (skipped)"
+		}
+		capacity := "-"
+		if a.Cap > 0 {
+			capacity = strconv.Itoa(a.Cap)
+		}
+		_, _ = fmt.Fprintf(w, "%s (%d)\t%s\t%d\t%s\t+%d\t%d\n", a.Name, a.ID, status, a.Open, capacity, a.Assigned, a.Open+a.Assigned)
+	}
+	_ = w.Flush()
+
+	if len(plan.Assignments) > 0 {
+		names := make(map[int]string, len(plan.Agents))
+		for _, a := range plan.Agents {
+			names[a.ID] = a.Name
+		}
+		_, _ = fmt.Fprintln(out)
+		w = newTabWriterFromCmd(cmd)
+		_, _ = fmt.Fprintln(w, "CONVERSATION\tAGENT\tLABELS")
+		for _, a := range plan.Assignments {
+			reason := a.Reason
+			if reason == "" {
+				reason = "-"
+			}
+			_, _ = fmt.Fprintf(w, "#%d\t%s (%d)\t%s\n", a.ConversationID, names[a.AgentID], a.AgentID, reason)
+		}
+		_ = w.Flush()
+	}
+
+	if len(plan.Skipped) > 0 {
+		_, _ = fmt.Fprintf(out, "\n%s\n", yellow(fmt.Sprintf("%d conversations left unassigned:", len(plan.Skipped))))
+		for _, s := range plan.Skipped {
+			_, _ = fmt.Fprintf(out, "  #%d  %s\n", s.ConversationID, s.Reason)
+		}
+	}
+}
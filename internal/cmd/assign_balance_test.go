@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// balanceHandler mocks an inbox with three members (one offline), their
+// workload, and three unassigned conversations, recording assignments.
+func balanceHandler(assigned map[int]int, mu *sync.Mutex) *routeHandler {
+	h := newRouteHandler().
+		On("GET", "/api/v1/accounts/1/inbox_members/3", jsonResponse(200, `{"payload": [
+			{"id": 1, "name": "Ann", "availability_status": "online"},
+			{"id": 2, "name": "Bo", "availability_status": "online"},
+			{"id": 4, "name": "Cy", "availability_status": "offline"}
+		]}`)).
+		On("GET", "/api/v2/accounts/1/reports/conversations", jsonResponse(200, `[
+			{"id": 1, "name": "Ann", "availability": "online", "metric": {"open": 3, "unattended": 0}},
+			{"id": 2, "name": "Bo", "availability": "online", "metric": {"open": 1, "unattended": 0}},
+			{"id": 4, "name": "Cy", "availability": "offline", "metric": {"open": 0, "unattended": 0}}
+		]`)).
+		On("GET", "/api/v1/accounts/1/conversations", func(w http.ResponseWriter, r *http.Request) {
+			q := r.URL.Query()
+			if q.Get("status") != "open" || q.Get("assignee_type") != "unassigned" || q.Get("inbox_id") != "3" {
+				http.Error(w, "unexpected query "+r.URL.RawQuery, http.StatusBadRequest)
+				return
+			}
+			jsonResponse(200, `{"data": {"meta": {"total_pages": 1}, "payload": [
+				{"id": 12, "inbox_id": 3, "status": "open", "created_at": 1700000300, "labels": ["billing"]},
+				{"id": 10, "inbox_id": 3, "status": "open", "created_at": 1700000100},
+				{"id": 11, "inbox_id": 3, "status": "open", "created_at": 1700000200}
+			]}}`)(w, r)
+		})
+	for _, id := range []int{10, 11, 12} {
+		h.On("POST", fmt.Sprintf("/api/v1/accounts/1/conversations/%d/assignments", id), func(w http.ResponseWriter, r *http.Request) {
+			var body struct {
+				AssigneeID int `json:"assignee_id"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			mu.Lock()
+			assigned[id] = body.AssigneeID
+			mu.Unlock()
+			jsonResponse(200, fmt.Sprintf(`{"id": %d, "name": "agent"}`, body.AssigneeID))(w, r)
+		})
+	}
+	return h
+}
+
+func TestAssignBalance_DryRunPrintsPlan(t *testing.T) {
+	var mu sync.Mutex
+	assigned := map[int]int{}
+	setupTestEnvWithHandler(t, balanceHandler(assigned, &mu))
+
+	output := captureStdout(t, func() {
+		if err := Execute(context.Background(), []string{"assign", "balance", "--inbox", "3", "--dry-run"}); err != nil {
+			t.Fatalf("assign balance failed: %v", err)
+		}
+	})
+
+	if len(assigned) != 0 {
+		t.Fatalf("dry run assigned conversations: %v", assigned)
+	}
+	for _, want := range []string{"least-loaded: 3 of 3", "Bo (2)", "offline (skipped)", "#10"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output missing %q:\n%s", want, output)
+		}
+	}
+}
+
+func TestAssignBalance_AppliesPlan(t *testing.T) {
+	var mu sync.Mutex
+	assigned := map[int]int{}
+	setupTestEnvWithHandler(t, balanceHandler(assigned, &mu))
+
+	output := captureStdout(t, func() {
+		err := Execute(context.Background(), []string{
+			"assign", "balance", "--inbox", "3", "--max-open", "3", "--force", "--no-progress", "-o", "json",
+		})
+		if err != nil {
+			t.Fatalf("assign balance failed: %v", err)
+		}
+	})
+
+	// Oldest first: Bo levels up to Ann's cap of 3, then nobody has room.
+	want := map[int]int{10: 2, 11: 2}
+	if fmt.Sprint(assigned) != fmt.Sprint(want) {
+		t.Fatalf("assignments = %v, want %v", assigned, want)
+	}
+	var result struct {
+		Plan struct {
+			Skipped []struct {
+				ConversationID int `json:"conversation_id"`
+			} `json:"skipped"`
+		} `json:"plan"`
+		SuccessCount int `json:"success_count"`
+	}
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, output)
+	}
+	if result.SuccessCount != 2 || len(result.Plan.Skipped) != 1 || result.Plan.Skipped[0].ConversationID != 12 {
+		t.Fatalf("unexpected result: %s", output)
+	}
+}
+
+func TestAssignBalance_SkillStrategy(t *testing.T) {
+	var mu sync.Mutex
+	assigned := map[int]int{}
+	setupTestEnvWithHandler(t, balanceHandler(assigned, &mu))
+
+	skills := filepath.Join(t.TempDir(), "skills.yaml")
+	if err := os.WriteFile(skills, []byte("billing: [1]\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	captureStdout(t, func() {
+		err := Execute(context.Background(), []string{
+			"assign", "balance", "--inbox", "3", "--strategy", "skill", "--skills", skills, "--force", "--no-progress",
+		})
+		if err != nil {
+			t.Fatalf("assign balance failed: %v", err)
+		}
+	})
+
+	// The billing conversation goes to Ann despite her load; the rest go
+	// least-loaded to Bo.
+	want := map[int]int{10: 2, 11: 2, 12: 1}
+	if fmt.Sprint(assigned) != fmt.Sprint(want) {
+		t.Fatalf("assignments = %v, want %v", assigned, want)
+	}
+}
+
+func TestAssignBalance_Validation(t *testing.T) {
+	setupTestEnvWithHandler(t, newRouteHandler())
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--strategy", "random"}, "unknown strategy"},
+		{[]string{"--strategy", "skill"}, "requires --skill or --skills"},
+		{[]string{"--skill", "billing"}, "invalid --skill"},
+		{[]string{"--cap", "Ann=many"}, "invalid --cap"},
+	}
+	for _, tt := range tests {
+		args := append([]string{"assign", "balance", "--inbox", "3"}, tt.args...)
+		err := Execute(context.Background(), args)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%v: error = %v, want %q", tt.args, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,490 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+	"github.com/chatwoot/chatwoot-cli/internal/autopilot"
+	"github.com/chatwoot/chatwoot-cli/internal/dryrun"
+)
+
+func newAutopilotCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "autopilot",
+		Aliases: []string{"pilot"},
+		Short:   "Rule-based auto-triage driven by the real-time event stream",
+		Long: strings.TrimSpace(`
+Evaluate triage rules against live conversation events and act on matches.
+
+Rules can test the inbox, status, labels, assignment, heuristics urgency and
+sentiment, keywords in unanswered customer messages, how long the customer has
+been waiting, and contact attributes. Matching rules add labels, set priority,
+assign, add a private note, or snooze. Every decision is appended to an audit
+log.
+
+Rules file (YAML or JSON):
+
+  version: 1
+  rules:
+    - name: urgent-billing
+      when:
+        inbox_ids: [3]
+        keywords: [refund, chargeback]
+        urgency: [high]
+        labels_absent: [billing]
+      then:
+        add_labels: [billing]
+        priority: urgent
+        assign_team: 2
+        note: "Autopilot: urgent billing request"
+    - name: vip-waiting
+      events: [recheck]
+      when:
+        status: [open]
+        waiting_over: 30m
+        contact_attributes: {plan: [enterprise, premium]}
+      then:
+        priority: high
+
+Each rule fires at most once per conversation (within 30 days) unless
+"repeat: true" is set; when some of a rule's actions fail, the next event
+retries only those. "stop: true" skips the remaining rules after a match.
+`),
+	}
+
+	cmd.AddCommand(newAutopilotRunCmd())
+	cmd.AddCommand(newAutopilotCheckCmd())
+	cmd.AddCommand(newAutopilotLogCmd())
+	return cmd
+}
+
+func defaultAutopilotAuditPath() string {
+	return filepath.Join(resolveCacheDir(), "autopilot-audit.jsonl")
+}
+
+func newAutopilotRunCmd() *cobra.Command {
+	var (
+		rulesPath string
+		auditPath string
+		fromStdin bool
+		recheck   time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "run",
+		Short: "Run the auto-triage daemon",
+		Long: strings.TrimSpace(`
+Connect to the account's real-time event stream (the same WebSocket used by
+"conversations follow --all") and evaluate rules on every new incoming message
+and new conversation. With --stdin, events are read as JSON lines produced by
+"cw conversations follow --all -o jsonl" instead.
+
+With --recheck, conversations seen in the stream are re-evaluated on that
+interval (as the "recheck" event) until they are resolved, so wait-time rules
+fire without a new message.
+
+Use --dry-run to print decisions without changing anything.
+`),
+		Example: strings.TrimSpace(`
+  # Preview decisions
+  cw autopilot run --rules rules.yaml --dry-run
+
+  # Act on live events, re-checking wait times every 5 minutes
+  cw autopilot run --rules rules.yaml --recheck 5m
+
+  # Drive from a filtered follow stream
+  cw conversations follow --all --inbox 3 -o jsonl | cw autopilot run --rules rules.yaml --stdin
+`),
+		Args: cobra.NoArgs,
+		RunE: RunE(func(cmd *cobra.Command, _ []string) error {
+			rules, err := autopilot.Load(rulesPath)
+			if err != nil {
+				return err
+			}
+			if recheck < 0 {
+				return fmt.Errorf("--recheck must be positive")
+			}
+
+			ctx, stop := signal.NotifyContext(cmdContext(cmd), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			cmd.SetContext(ctx)
+
+			client, err := getClient()
+			if err != nil {
+				return err
+			}
+
+			dryRun := dryrun.IsEnabled(ctx)
+			engine, audit, err := newAutopilotEngine(rules, client, auditPath, dryRun)
+			if err != nil {
+				return err
+			}
+			defer func() { _ = audit.Close() }()
+
+			if !isJSON(cmd) {
+				mode := ""
+				if dryRun {
+					mode = " (dry-run)"
+				}
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Autopilot running %d rules%s; audit log: %s (press Ctrl+C to stop)\n", len(rules.Rules), mode, audit.Path())
+			}
+
+			triggers := make(chan autopilotTrigger, 256)
+			sourceErr := make(chan error, 1)
+			go func() {
+				var err error
+				if fromStdin {
+					err = readFollowTriggers(ctx, cmd.InOrStdin(), autopilotTriggerFromEvent, triggers)
+				} else {
+					err = streamFollowTriggers(ctx, cmd, client, autopilotTriggerFromEvent, triggers)
+				}
+				close(triggers)
+				if err != nil {
+					sourceErr <- err
+				}
+			}()
+
+			var recheckC <-chan time.Time
+			if recheck > 0 {
+				ticker := time.NewTicker(recheck)
+				defer ticker.Stop()
+				recheckC = ticker.C
+			}
+
+			tracked := map[int]struct{}{}
+			process := func(event string, conversationID int) error {
+				decisions, err := engine.Handle(ctx, event, conversationID)
+				for _, d := range decisions {
+					if err := printAutopilotDecision(cmd, d); err != nil {
+						return err
+					}
+				}
+				if err != nil {
+					if errors.Is(err, autopilot.ErrAudit) || ctx.Err() != nil {
+						return err
+					}
+					// A single conversation failing to load should not stop the daemon.
+					_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "autopilot: %v\n", err)
+				}
+				return nil
+			}
+
+			for {
+				select {
+				case <-ctx.Done():
+					return nil
+				case err := <-sourceErr:
+					return err
+				case t, ok := <-triggers:
+					if !ok {
+						select {
+						case err := <-sourceErr:
+							return err
+						default:
+							return nil
+						}
+					}
+					if t.Resolved {
+						delete(tracked, t.ConversationID)
+						continue
+					}
+					if t.Event == "" {
+						continue
+					}
+					tracked[t.ConversationID] = struct{}{}
+					if err := process(t.Event, t.ConversationID); err != nil {
+						return err
+					}
+				case <-recheckC:
+					ids := make([]int, 0, len(tracked))
+					for id := range tracked {
+						ids = append(ids, id)
+					}
+					sort.Ints(ids)
+					for _, id := range ids {
+						if err := process(autopilot.EventRecheck, id); err != nil {
+							return err
+						}
+					}
+				}
+			}
+		}),
+	}
+
+	cmd.Flags().StringVar(&rulesPath, "rules", "", "Rules file (YAML or JSON)")
+	cmd.Flags().StringVar(&auditPath, "audit-log", "", "Append decisions to this JSONL file (default <cache-dir>/autopilot-audit.jsonl)")
+	cmd.Flags().BoolVar(&fromStdin, "stdin", false, "Read follow events (JSON lines) from stdin instead of connecting to the WebSocket")
+	cmd.Flags().DurationVar(&recheck, "recheck", 0, "Re-evaluate tracked open conversations on this interval (e.g. 5m; 0 disables)")
+	_ = cmd.MarkFlagRequired("rules")
+	flagAlias(cmd.Flags(), "rules", "rls")
+	flagAlias(cmd.Flags(), "audit-log", "al")
+	flagAlias(cmd.Flags(), "recheck", "rc")
+	registerCommandContract(cmd, true, true)
+	return cmd
+}
+
+func newAutopilotCheckCmd() *cobra.Command {
+	var rulesPath string
+
+	cmd := &cobra.Command{
+		Use:   "check [conversation-id...]",
+		Short: "Validate a rules file and preview decisions for conversations",
+		Long: strings.TrimSpace(`
+Validate the rules file. When conversation IDs are given, evaluate every rule
+against each conversation (as a new incoming message would) and print what
+would happen. Nothing is changed and nothing is written to the audit log.
+`),
+		Example: strings.TrimSpace(`
+  cw autopilot check --rules rules.yaml
+  cw autopilot check --rules rules.yaml 123 456
+`),
+		RunE: RunE(func(cmd *cobra.Command, args []string) error {
+			rules, err := autopilot.Load(rulesPath)
+			if err != nil {
+				return err
+			}
+			ids := make([]int, 0, len(args))
+			for _, arg := range args {
+				id, err := parseIDOrURL(arg, "conversation")
+				if err != nil {
+					return err
+				}
+				ids = append(ids, id)
+			}
+
+			if len(ids) == 0 {
+				if isJSON(cmd) {
+					return printJSON(cmd, map[string]any{"valid": true, "rules": len(rules.Rules)})
+				}
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s: %d rules OK\n", rulesPath, len(rules.Rules))
+				return nil
+			}
+
+			client, err := getClient()
+			if err != nil {
+				return err
+			}
+			engine := autopilot.NewEngine(rules, autopilot.NewAPIClient(client), autopilot.Options{DryRun: true})
+			ctx := cmdContext(cmd)
+			var all []autopilot.Decision
+			for _, id := range ids {
+				decisions, err := engine.Handle(ctx, autopilot.EventMessageCreated, id)
+				if err != nil {
+					return err
+				}
+				all = append(all, decisions...)
+			}
+
+			if isJSON(cmd) {
+				if all == nil {
+					all = []autopilot.Decision{}
+				}
+				return printJSON(cmd, map[string]any{"valid": true, "rules": len(rules.Rules), "decisions": all})
+			}
+			if len(all) == 0 {
+				_, _ = fmt.Fprintln(cmd.OutOrStdout(), "No rules match")
+				return nil
+			}
+			for _, d := range all {
+				if err := printAutopilotDecision(cmd, d); err != nil {
+					return err
+				}
+			}
+			return nil
+		}),
+	}
+
+	cmd.Flags().StringVar(&rulesPath, "rules", "", "Rules file (YAML or JSON)")
+	_ = cmd.MarkFlagRequired("rules")
+	flagAlias(cmd.Flags(), "rules", "rls")
+	return cmd
+}
+
+func newAutopilotLogCmd() *cobra.Command {
+	var (
+		auditPath      string
+		conversationID int
+		limit          int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "log",
+		Short: "Show recent autopilot decisions from the audit log",
+		Example: strings.TrimSpace(`
+  cw autopilot log
+  cw autopilot log --conversation 123 -o json
+`),
+		Args: cobra.NoArgs,
+		RunE: RunE(func(cmd *cobra.Command, _ []string) error {
+			if auditPath == "" {
+				auditPath = defaultAutopilotAuditPath()
+			}
+			decisions, err := autopilot.ReadAudit(auditPath)
+			if err != nil {
+				return err
+			}
+			filtered := make([]autopilot.Decision, 0, len(decisions))
+			for _, d := range decisions {
+				if conversationID > 0 && d.ConversationID != conversationID {
+					continue
+				}
+				filtered = append(filtered, d)
+			}
+			if limit > 0 && len(filtered) > limit {
+				filtered = filtered[len(filtered)-limit:]
+			}
+
+			if isJSON(cmd) {
+				return printJSON(cmd, filtered)
+			}
+			if len(filtered) == 0 {
+				_, _ = fmt.Fprintln(cmd.OutOrStdout(), "No decisions recorded")
+				return nil
+			}
+			w := newTabWriterFromCmd(cmd)
+			_, _ = fmt.Fprintln(w, "TIME\tCONVERSATION\tRULE\tACTIONS\tRESULT")
+			for _, d := range filtered {
+				result := "ok"
+				switch {
+				case d.DryRun:
+					result = "dry-run"
+				case d.Failed():
+					result = "failed"
+				}
+				_, _ = fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\n",
+					d.Time.Local().Format("2006-01-02 15:04:05"), d.ConversationID, d.Rule, autopilotActionSummary(d.Actions), result)
+			}
+			return w.Flush()
+		}),
+	}
+
+	cmd.Flags().StringVar(&auditPath, "audit-log", "", "Audit log file (default <cache-dir>/autopilot-audit.jsonl)")
+	cmd.Flags().IntVar(&conversationID, "conversation", 0, "Only show decisions for this conversation ID")
+	cmd.Flags().IntVar(&limit, "limit", 50, "Show at most this many recent decisions (0 for all)")
+	flagAlias(cmd.Flags(), "audit-log", "al")
+	flagAlias(cmd.Flags(), "conversation", "cv")
+	flagAlias(cmd.Flags(), "limit", "l")
+	return cmd
+}
+
+// newAutopilotEngine opens the audit log and restores one-shot rule state
+// from earlier (non-dry-run) decisions.
+func newAutopilotEngine(rules *autopilot.RuleSet, client *api.Client, auditPath string, dryRun bool) (*autopilot.Engine, *autopilot.AuditLog, error) {
+	if auditPath == "" {
+		auditPath = defaultAutopilotAuditPath()
+	}
+	previous, err := autopilot.ReadAudit(auditPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	audit, err := autopilot.OpenAudit(auditPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	engine := autopilot.NewEngine(rules, autopilot.NewAPIClient(client), autopilot.Options{DryRun: dryRun, Audit: audit})
+	engine.RestoreFired(previous)
+	return engine, audit, nil
+}
+
+func printAutopilotDecision(cmd *cobra.Command, d autopilot.Decision) error {
+	if isJSON(cmd) {
+		return writeStreamJSON(cmd, map[string]any{
+			"kind":     "autopilot.decision",
+			"decision": d,
+		})
+	}
+	prefix := ""
+	if d.DryRun {
+		prefix = "[dry-run] "
+	}
+	_, err := fmt.Fprintf(cmd.OutOrStdout(), "[%s] %s#%d %s (%s): %s\n",
+		d.Time.Local().Format("15:04:05"), prefix, d.ConversationID, d.Rule, strings.Join(d.Reasons, "; "), autopilotActionSummary(d.Actions))
+	if err != nil {
+		return err
+	}
+	for _, a := range d.Actions {
+		if a.Error != "" {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "  %s failed: %s\n", a.Action, a.Error)
+		}
+	}
+	return nil
+}
+
+func autopilotActionSummary(actions []autopilot.ActionResult) string {
+	parts := make([]string, 0, len(actions))
+	for _, a := range actions {
+		parts = append(parts, a.Action+" "+a.Detail)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// autopilotTrigger is one event worth evaluating. Resolved triggers stop
+// rechecking a conversation.
+type autopilotTrigger struct {
+	Event          string
+	ConversationID int
+	Resolved       bool
+}
+
+// autopilotTriggerFromEvent maps a follow/WebSocket event to a trigger. Only
+// new incoming messages and new conversations are evaluated, so the
+// autopilot's own notes and label changes do not re-trigger it.
+func autopilotTriggerFromEvent(event string, data json.RawMessage) (autopilotTrigger, bool) {
+	switch event {
+	case autopilot.EventMessageCreated:
+		var msg struct {
+			ConversationID int             `json:"conversation_id"`
+			MessageType    json.RawMessage `json:"message_type"`
+			Type           string          `json:"type"`
+			Private        bool            `json:"private"`
+		}
+		if err := json.Unmarshal(data, &msg); err != nil || msg.ConversationID <= 0 || msg.Private {
+			return autopilotTrigger{}, false
+		}
+		if !isIncomingMessageType(msg.MessageType, msg.Type) {
+			return autopilotTrigger{}, false
+		}
+		return autopilotTrigger{Event: event, ConversationID: msg.ConversationID}, true
+	case autopilot.EventConversationCreated:
+		id, _, _ := conversationCreatedSummary(data)
+		if id <= 0 {
+			return autopilotTrigger{}, false
+		}
+		return autopilotTrigger{Event: event, ConversationID: id}, true
+	case "conversation.status_changed":
+		id, status := conversationStatusChangedSummary(data)
+		if id <= 0 || status != "resolved" {
+			return autopilotTrigger{}, false
+		}
+		return autopilotTrigger{ConversationID: id, Resolved: true}, true
+	}
+	return autopilotTrigger{}, false
+}
+
+// isIncomingMessageType accepts both the API form (message_type: 0 or
+// "incoming") and the agent summary form (type: "incoming").
+func isIncomingMessageType(raw json.RawMessage, summaryType string) bool {
+	if len(raw) > 0 {
+		var n int
+		if json.Unmarshal(raw, &n) == nil {
+			return n == api.MessageTypeIncoming
+		}
+		var s string
+		if json.Unmarshal(raw, &s) == nil {
+			return s == "incoming"
+		}
+		return false
+	}
+	return summaryType == "incoming"
+}
@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/chatwoot/chatwoot-cli/internal/autopilot"
+)
+
+const autopilotTestRules = `
+rules:
+  - name: refunds
+    when:
+      keywords: [refund]
+      labels_absent: [billing]
+    then:
+      add_labels: [billing]
+      priority: urgent
+`
+
+func setupAutopilotTest(t *testing.T) (rulesPath string, mutations func() []string) {
+	t.Helper()
+	t.Setenv("CHATWOOT_CACHE_DIR", t.TempDir())
+
+	created := time.Now().Add(-time.Hour).Unix()
+	var mu sync.Mutex
+	var recorded []string
+	record := func(body string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			recorded = append(recorded, r.Method+" "+r.URL.Path)
+			mu.Unlock()
+			jsonResponse(200, body)(w, r)
+		}
+	}
+	setupTestEnvWithHandler(t, newRouteHandler().
+		On("GET", "/api/v1/accounts/1/conversations/7", jsonResponse(200, `{"id": 7, "inbox_id": 1, "status": "open", "labels": ["vip"]}`)).
+		On("GET", "/api/v1/accounts/1/conversations/7/messages", jsonResponse(200, `{"payload": [
+			{"id": 1, "conversation_id": 7, "message_type": 0, "content": "I want a refund", "created_at": `+strconv.FormatInt(created, 10)+`}
+		]}`)).
+		On("POST", "/api/v1/accounts/1/conversations/7/labels", record(`{"payload": ["vip", "billing"]}`)).
+		On("POST", "/api/v1/accounts/1/conversations/7/toggle_priority", record(`{}`)))
+
+	rulesPath = filepath.Join(t.TempDir(), "rules.yaml")
+	if err := os.WriteFile(rulesPath, []byte(autopilotTestRules), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return rulesPath, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), recorded...)
+	}
+}
+
+// pipeStdin replaces os.Stdin with the given content for the duration of the test.
+func pipeStdin(t *testing.T, content string) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := os.Stdin
+	os.Stdin = r
+	t.Cleanup(func() { os.Stdin = old })
+	go func() {
+		_, _ = w.Write([]byte(content))
+		_ = w.Close()
+	}()
+}
+
+const autopilotFollowStream = `{"kind":"conversations.follow","type":"message","event":"message.created","conversation_id":7,"item":{"id":1,"conversation_id":7,"message_type":0,"content":"I want a refund"}}
+{"kind":"conversations.follow","type":"message","event":"message.created","conversation_id":7,"item":{"id":2,"conversation_id":7,"message_type":1,"content":"on it"}}
+{"kind":"conversations.follow","type":"event","event":"conversation.status_changed","conversation_id":7,"data":{"id":7,"status":"resolved"}}
+`
+
+func TestAutopilotRunFromStdin(t *testing.T) {
+	rulesPath, mutations := setupAutopilotTest(t)
+	auditPath := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	pipeStdin(t, autopilotFollowStream)
+	output := captureStdout(t, func() {
+		if err := Execute(context.Background(), []string{"autopilot", "run", "--rules", rulesPath, "--stdin", "--audit-log", auditPath}); err != nil {
+			t.Fatalf("autopilot run failed: %v", err)
+		}
+	})
+	if !strings.Contains(output, "#7 refunds") || !strings.Contains(output, "add_labels billing, priority urgent") {
+		t.Fatalf("unexpected output: %s", output)
+	}
+	want := "POST /api/v1/accounts/1/conversations/7/labels|POST /api/v1/accounts/1/conversations/7/toggle_priority"
+	if got := strings.Join(mutations(), "|"); got != want {
+		t.Fatalf("mutations = %s, want %s", got, want)
+	}
+	logged, err := autopilot.ReadAudit(auditPath)
+	if err != nil || len(logged) != 1 || logged[0].Rule != "refunds" || logged[0].DryRun {
+		t.Fatalf("audit log = %+v, %v", logged, err)
+	}
+
+	// A restart does not repeat the one-shot rule.
+	pipeStdin(t, autopilotFollowStream)
+	_ = captureStdout(t, func() {
+		if err := Execute(context.Background(), []string{"autopilot", "run", "--rules", rulesPath, "--stdin", "--audit-log", auditPath}); err != nil {
+			t.Fatalf("second run failed: %v", err)
+		}
+	})
+	if len(mutations()) != 2 {
+		t.Fatalf("rule fired again after restart: %v", mutations())
+	}
+
+	output = captureStdout(t, func() {
+		if err := Execute(context.Background(), []string{"autopilot", "log", "--audit-log", auditPath, "-o", "json"}); err != nil {
+			t.Fatalf("autopilot log failed: %v", err)
+		}
+	})
+	if !strings.Contains(output, `"rule": "refunds"`) {
+		t.Fatalf("unexpected log output: %s", output)
+	}
+}
+
+func TestAutopilotRunDryRun(t *testing.T) {
+	rulesPath, mutations := setupAutopilotTest(t)
+	auditPath := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	pipeStdin(t, autopilotFollowStream)
+	output := captureStdout(t, func() {
+		if err := Execute(context.Background(), []string{"autopilot", "run", "--rules", rulesPath, "--stdin", "--audit-log", auditPath, "--dry-run", "-o", "jsonl"}); err != nil {
+			t.Fatalf("autopilot run failed: %v", err)
+		}
+	})
+	if len(mutations()) != 0 {
+		t.Fatalf("dry run mutated: %v", mutations())
+	}
+	if !strings.Contains(output, `"kind":"autopilot.decision"`) || !strings.Contains(output, `"dry_run":true`) {
+		t.Fatalf("unexpected output: %s", output)
+	}
+	logged, _ := autopilot.ReadAudit(auditPath)
+	if len(logged) != 1 || !logged[0].DryRun {
+		t.Fatalf("expected dry-run decision in audit log, got %+v", logged)
+	}
+}
+
+func TestAutopilotCheck(t *testing.T) {
+	rulesPath, mutations := setupAutopilotTest(t)
+
+	output := captureStdout(t, func() {
+		if err := Execute(context.Background(), []string{"autopilot", "check", "--rules", rulesPath}); err != nil {
+			t.Fatalf("check failed: %v", err)
+		}
+	})
+	if !strings.Contains(output, "1 rules OK") {
+		t.Fatalf("unexpected output: %s", output)
+	}
+
+	output = captureStdout(t, func() {
+		if err := Execute(context.Background(), []string{"autopilot", "check", "--rules", rulesPath, "7"}); err != nil {
+			t.Fatalf("check failed: %v", err)
+		}
+	})
+	if !strings.Contains(output, "[dry-run] #7 refunds") || len(mutations()) != 0 {
+		t.Fatalf("unexpected check output %q / mutations %v", output, mutations())
+	}
+
+	bad := filepath.Join(t.TempDir(), "bad.yaml")
+	if err := os.WriteFile(bad, []byte("rules:\n  - name: x\n    when: {urgency: [critical]}\n    then: {note: hi}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := Execute(context.Background(), []string{"autopilot", "check", "--rules", bad}); err == nil || !strings.Contains(err.Error(), "invalid urgency") {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+}
+
+func TestAutopilotTriggerFromEvent(t *testing.T) {
+	tests := []struct {
+		event string
+		data  string
+		want  autopilotTrigger
+		ok    bool
+	}{
+		{"message.created", `{"conversation_id": 3, "message_type": 0}`, autopilotTrigger{Event: "message.created", ConversationID: 3}, true},
+		{"message.created", `{"conversation_id": 3, "type": "incoming"}`, autopilotTrigger{Event: "message.created", ConversationID: 3}, true},
+		{"message.created", `{"conversation_id": 3, "message_type": 1}`, autopilotTrigger{}, false},
+		{"message.created", `{"conversation_id": 3, "message_type": 0, "private": true}`, autopilotTrigger{}, false},
+		{"conversation.created", `{"id": 4}`, autopilotTrigger{Event: "conversation.created", ConversationID: 4}, true},
+		{"conversation.status_changed", `{"id": 4, "status": "resolved"}`, autopilotTrigger{ConversationID: 4, Resolved: true}, true},
+		{"conversation.status_changed", `{"id": 4, "status": "open"}`, autopilotTrigger{}, false},
+		{"label.added", `{"id": 4}`, autopilotTrigger{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := autopilotTriggerFromEvent(tt.event, []byte(tt.data))
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("%s %s = %+v, %v; want %+v, %v", tt.event, tt.data, got, ok, tt.want, tt.ok)
+		}
+	}
+}
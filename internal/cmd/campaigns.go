@@ -24,6 +24,7 @@ func newCampaignsCmd() *cobra.Command {
 	cmd.AddCommand(newCampaignsCreateCmd())
 	cmd.AddCommand(newCampaignsUpdateCmd())
 	cmd.AddCommand(newCampaignsDeleteCmd())
+	cmd.AddCommand(newCampaignsAudienceCmd())
 
 	return cmd
 }
@@ -193,6 +194,8 @@ func newCampaignsCreateCmd() *cobra.Command {
 		enabled       bool
 		businessHours bool
 		emit          string
+		preview       bool
+		sample        int
 	)
 
 	cmd := &cobra.Command{
@@ -209,10 +212,16 @@ The --audience flag accepts JSON array of audience targets (mutually exclusive w
 
 The --scheduled-at flag accepts relative time or RFC3339 format, e.g.:
   --scheduled-at '30m'
-  --scheduled-at '2025-01-15T10:00:00Z'`,
+  --scheduled-at '2025-01-15T10:00:00Z'
+
+With --preview, the audience is resolved to contacts (see 'cw campaigns audience')
+and printed instead of creating the campaign.`,
 		Example: `  # Create an SMS campaign with label targeting (simple)
   cw campaigns create --title "Promo" --message "50% off today!" --inbox-id 5 --labels 1,2,3 --scheduled-at '2025-01-15T10:00:00Z'
 
+  # See who it would reach first
+  cw campaigns create --title "Promo" --message "50% off today!" --inbox-id 5 --labels 1,2,3 --preview
+
   # Create an SMS campaign with JSON audience (advanced)
   cw campaigns create --title "Promo" --message "50% off today!" --inbox-id 5 --audience '[{"type":"Label","id":1}]' --scheduled-at '2025-01-15T10:00:00Z'`,
 		RunE: RunE(func(cmd *cobra.Command, args []string) error {
@@ -272,6 +281,21 @@ The --scheduled-at flag accepts relative time or RFC3339 format, e.g.:
 				req.Audience = aud
 			}
 
+			if preview {
+				if sample < 0 {
+					return fmt.Errorf("--sample must be non-negative")
+				}
+				aud, err := resolveCampaignAudience(cmdContext(cmd), client, req.InboxID, req.Audience, sample)
+				if err != nil {
+					return err
+				}
+				if isJSON(cmd) {
+					return printJSON(cmd, aud)
+				}
+				printCampaignAudience(cmd.OutOrStdout(), aud)
+				return nil
+			}
+
 			if ok, err := maybeDryRun(cmd, &dryrun.Preview{
 				Operation: "create",
 				Resource:  "campaign",
@@ -311,12 +335,16 @@ The --scheduled-at flag accepts relative time or RFC3339 format, e.g.:
 	cmd.Flags().BoolVar(&enabled, "enabled", true, "Enable the campaign")
 	cmd.Flags().BoolVar(&businessHours, "business-hours", false, "Trigger only during business hours")
 	cmd.Flags().StringVarP(&emit, "emit", "E", "", "Emit: json|id|url (overrides normal text output)")
+	cmd.Flags().BoolVar(&preview, "preview", false, "Resolve and show the audience instead of creating the campaign")
+	cmd.Flags().IntVar(&sample, "sample", 10, "Number of contacts to list with --preview (0 lists all)")
 	flagAlias(cmd.Flags(), "title", "ttl")
 	flagAlias(cmd.Flags(), "sender-id", "sid")
 	flagAlias(cmd.Flags(), "scheduled-at", "sch")
 	flagAlias(cmd.Flags(), "audience", "aud")
 	flagAlias(cmd.Flags(), "enabled", "en")
 	flagAlias(cmd.Flags(), "business-hours", "bh")
+	flagAlias(cmd.Flags(), "preview", "pv")
+	flagAlias(cmd.Flags(), "sample", "smp")
 
 	_ = cmd.MarkFlagRequired("title")
 	_ = cmd.MarkFlagRequired("message")
@@ -0,0 +1,280 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+	"github.com/spf13/cobra"
+)
+
+// campaignAudienceMaxPages bounds the contact filter pagination when
+// resolving an audience (15 contacts per page in Chatwoot).
+const campaignAudienceMaxPages = 1000
+
+// campaignAudience is the resolved set of contacts a campaign would reach.
+type campaignAudience struct {
+	CampaignID  int                      `json:"campaign_id,omitempty"`
+	InboxID     int                      `json:"inbox_id"`
+	InboxName   string                   `json:"inbox_name,omitempty"`
+	ChannelType string                   `json:"channel_type,omitempty"`
+	Requires    string                   `json:"requires,omitempty"`
+	Labels      []string                 `json:"labels"`
+	Matched     int                      `json:"matched"`
+	Duplicates  int                      `json:"duplicates"`
+	Excluded    int                      `json:"excluded"`
+	Reachable   int                      `json:"reachable"`
+	Contacts    []campaignAudienceMember `json:"contacts"`
+	Truncated   bool                     `json:"truncated,omitempty"`
+	Skipped     []campaignAudienceMember `json:"excluded_contacts,omitempty"`
+	Warnings    []string                 `json:"warnings,omitempty"`
+}
+
+type campaignAudienceMember struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name,omitempty"`
+	Email       string `json:"email,omitempty"`
+	PhoneNumber string `json:"phone_number,omitempty"`
+}
+
+// campaignChannelRequirement returns the contact field a campaign on the
+// given channel needs to deliver: "phone_number" for SMS and WhatsApp
+// inboxes, "email" for email inboxes, or "" when there is no single field.
+func campaignChannelRequirement(channelType string) string {
+	switch channelType {
+	case "Channel::Sms", "Channel::TwilioSms", "Channel::Whatsapp":
+		return "phone_number"
+	case "Channel::Email":
+		return "email"
+	}
+	return ""
+}
+
+// buildCampaignAudience dedupes contacts and splits them into reachable and
+// excluded according to the inbox channel. sample limits the listed
+// contacts; 0 lists all of them.
+func buildCampaignAudience(inbox *api.Inbox, labels []string, contacts []api.Contact, sample int) *campaignAudience {
+	aud := &campaignAudience{
+		InboxID:     inbox.ID,
+		InboxName:   inbox.Name,
+		ChannelType: inbox.ChannelType,
+		Requires:    campaignChannelRequirement(inbox.ChannelType),
+		Labels:      labels,
+		Contacts:    []campaignAudienceMember{},
+	}
+	if aud.Requires == "" {
+		aud.Warnings = append(aud.Warnings, fmt.Sprintf("%s inboxes have no contact identifier requirement; no contacts were excluded", inbox.ChannelType))
+	}
+
+	seen := make(map[int]bool, len(contacts))
+	var reachable, excluded []campaignAudienceMember
+	for _, c := range contacts {
+		if seen[c.ID] {
+			aud.Duplicates++
+			continue
+		}
+		seen[c.ID] = true
+		m := campaignAudienceMember{ID: c.ID, Name: c.Name, Email: c.Email, PhoneNumber: c.PhoneNumber}
+		switch {
+		case aud.Requires == "phone_number" && strings.TrimSpace(c.PhoneNumber) == "",
+			aud.Requires == "email" && strings.TrimSpace(c.Email) == "":
+			excluded = append(excluded, m)
+		default:
+			reachable = append(reachable, m)
+		}
+	}
+	sort.Slice(reachable, func(i, j int) bool { return reachable[i].ID < reachable[j].ID })
+	sort.Slice(excluded, func(i, j int) bool { return excluded[i].ID < excluded[j].ID })
+
+	aud.Matched = len(seen)
+	aud.Reachable = len(reachable)
+	aud.Excluded = len(excluded)
+	if sample > 0 {
+		if len(reachable) > sample {
+			reachable, aud.Truncated = reachable[:sample], true
+		}
+		if len(excluded) > sample {
+			excluded, aud.Truncated = excluded[:sample], true
+		}
+	}
+	aud.Contacts = append(aud.Contacts, reachable...)
+	aud.Skipped = excluded
+	if aud.Matched == 0 {
+		aud.Warnings = append(aud.Warnings, "no contacts have these labels")
+	}
+	return aud
+}
+
+// resolveCampaignAudience looks up the contacts targeted by a campaign
+// audience on an inbox using the contacts filter API.
+func resolveCampaignAudience(ctx context.Context, client *api.Client, inboxID int, audience []api.CampaignAudience, sample int) (*campaignAudience, error) {
+	if inboxID == 0 {
+		return nil, fmt.Errorf("campaign has no inbox")
+	}
+	if len(audience) == 0 {
+		return nil, fmt.Errorf("campaign has no audience (use --labels or --audience)")
+	}
+
+	var labelIDs []int
+	for _, a := range audience {
+		if !strings.EqualFold(a.Type, "Label") {
+			return nil, fmt.Errorf("unsupported audience type %q (only Label audiences can be previewed)", a.Type)
+		}
+		labelIDs = append(labelIDs, a.ID)
+	}
+
+	inbox, err := client.Inboxes().Get(ctx, inboxID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get inbox %d: %w", inboxID, err)
+	}
+	labels, err := client.Labels().List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list labels: %w", err)
+	}
+	titles := make(map[int]string, len(labels))
+	for _, l := range labels {
+		titles[l.ID] = l.Title
+	}
+	var names []string
+	for _, id := range labelIDs {
+		title, ok := titles[id]
+		if !ok {
+			return nil, fmt.Errorf("label %d not found", id)
+		}
+		names = append(names, title)
+	}
+
+	payload := map[string]any{
+		"payload": []map[string]any{{
+			"attribute_key":   "labels",
+			"filter_operator": "equal_to",
+			"values":          names,
+			"query_operator":  nil,
+		}},
+	}
+	var contacts []api.Contact
+	for page := 1; ; page++ {
+		if page > campaignAudienceMaxPages {
+			return nil, fmt.Errorf("safety limit reached: fetched %d pages (%d contacts)", campaignAudienceMaxPages, len(contacts))
+		}
+		result, err := client.Contacts().FilterPage(ctx, payload, page)
+		if err != nil {
+			return nil, fmt.Errorf("failed to filter contacts: %w", err)
+		}
+		if len(result.Payload) == 0 {
+			break
+		}
+		contacts = append(contacts, result.Payload...)
+		if totalPages := int(result.Meta.TotalPages); totalPages > 0 && page >= totalPages {
+			break
+		}
+	}
+
+	return buildCampaignAudience(inbox, names, contacts, sample), nil
+}
+
+func printCampaignAudience(out io.Writer, aud *campaignAudience) {
+	if aud.CampaignID != 0 {
+		_, _ = fmt.Fprintf(out, "Campaign #%d audience\n", aud.CampaignID)
+	} else {
+		_, _ = fmt.Fprintln(out, "Campaign audience preview")
+	}
+	inbox := fmt.Sprintf("#%d", aud.InboxID)
+	if aud.InboxName != "" {
+		inbox = fmt.Sprintf("%s (#%d, %s)", aud.InboxName, aud.InboxID, aud.ChannelType)
+	}
+	_, _ = fmt.Fprintf(out, "  Inbox:      %s\n", inbox)
+	_, _ = fmt.Fprintf(out, "  Labels:     %s\n", strings.Join(aud.Labels, ", "))
+	_, _ = fmt.Fprintf(out, "  Matched:    %d contacts", aud.Matched)
+	if aud.Duplicates > 0 {
+		_, _ = fmt.Fprintf(out, " (%d duplicates removed)", aud.Duplicates)
+	}
+	_, _ = fmt.Fprintln(out)
+	if aud.Requires != "" {
+		_, _ = fmt.Fprintf(out, "  Excluded:   %d (missing %s)\n", aud.Excluded, strings.ReplaceAll(aud.Requires, "_", " "))
+	}
+	_, _ = fmt.Fprintf(out, "  Reachable:  %s\n", green(fmt.Sprintf("%d", aud.Reachable)))
+	for _, w := range aud.Warnings {
+		_, _ = fmt.Fprintf(out, "  %s %s\n", yellow("!"), w)
+	}
+
+	printMembers := func(title string, members []campaignAudienceMember, total int) {
+		if len(members) == 0 {
+			return
+		}
+		heading := title
+		if len(members) < total {
+			heading = fmt.Sprintf("%s (showing %d of %d)", title, len(members), total)
+		}
+		_, _ = fmt.Fprintf(out, "\n%s:\n", heading)
+		for _, m := range members {
+			_, _ = fmt.Fprintf(out, "  %-8d %-30s %-30s %s\n", m.ID, m.Name, m.Email, m.PhoneNumber)
+		}
+	}
+	printMembers("Reachable contacts", aud.Contacts, aud.Reachable)
+	printMembers("Excluded contacts", aud.Skipped, aud.Excluded)
+}
+
+func newCampaignsAudienceCmd() *cobra.Command {
+	var (
+		sample int
+		all    bool
+	)
+
+	cmd := &cobra.Command{
+		Use:     "audience <id>",
+		Aliases: []string{"aud"},
+		Short:   "Show the contacts a campaign will reach",
+		Long: `Resolve a campaign's label audience to contacts using the contacts filter API.
+
+Contacts are deduplicated, and contacts missing the identifier the inbox needs
+(phone number for SMS and WhatsApp inboxes, email for email inboxes) are
+excluded. Counts are always shown; contacts are listed as a sample (--sample)
+or in full (--all).`,
+		Example: `  cw campaigns audience 12
+  cw cm aud 12 --all -o json`,
+		Args: cobra.ExactArgs(1),
+		RunE: RunE(func(cmd *cobra.Command, args []string) error {
+			id, err := parseIDOrURL(args[0], "campaign")
+			if err != nil {
+				return err
+			}
+			if sample < 0 {
+				return fmt.Errorf("--sample must be non-negative")
+			}
+			if all {
+				sample = 0
+			}
+
+			client, err := getClient()
+			if err != nil {
+				return err
+			}
+			campaign, err := client.Campaigns().Get(cmdContext(cmd), id)
+			if err != nil {
+				return fmt.Errorf("failed to get campaign: %w", err)
+			}
+
+			aud, err := resolveCampaignAudience(cmdContext(cmd), client, campaign.InboxID, campaign.Audience, sample)
+			if err != nil {
+				return err
+			}
+			aud.CampaignID = campaign.ID
+
+			if isJSON(cmd) {
+				return printJSON(cmd, aud)
+			}
+			printCampaignAudience(cmd.OutOrStdout(), aud)
+			return nil
+		}),
+	}
+
+	cmd.Flags().IntVar(&sample, "sample", 10, "Number of contacts to list (0 lists all)")
+	cmd.Flags().BoolVarP(&all, "all", "a", false, "List every contact instead of a sample")
+	flagAlias(cmd.Flags(), "sample", "smp")
+
+	return cmd
+}
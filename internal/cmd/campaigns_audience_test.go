@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+)
+
+func TestBuildCampaignAudience(t *testing.T) {
+	contacts := []api.Contact{
+		{ID: 3, Name: "No Phone", Email: "np@example.com"},
+		{ID: 1, Name: "Ann", PhoneNumber: "+15550001"},
+		{ID: 2, Name: "Bob", PhoneNumber: "+15550002"},
+		{ID: 1, Name: "Ann", PhoneNumber: "+15550001"},
+	}
+
+	aud := buildCampaignAudience(&api.Inbox{ID: 5, ChannelType: "Channel::Sms"}, []string{"vip"}, contacts, 1)
+	if aud.Requires != "phone_number" || aud.Matched != 3 || aud.Duplicates != 1 || aud.Excluded != 1 || aud.Reachable != 2 {
+		t.Fatalf("unexpected counts %+v", aud)
+	}
+	if len(aud.Contacts) != 1 || aud.Contacts[0].ID != 1 || !aud.Truncated {
+		t.Fatalf("expected a one-contact sample, got %+v", aud.Contacts)
+	}
+	if len(aud.Skipped) != 1 || aud.Skipped[0].ID != 3 {
+		t.Fatalf("unexpected excluded contacts %+v", aud.Skipped)
+	}
+
+	aud = buildCampaignAudience(&api.Inbox{ID: 6, ChannelType: "Channel::Email"}, nil, contacts, 0)
+	if aud.Excluded != 2 || aud.Reachable != 1 || aud.Contacts[0].ID != 3 || aud.Truncated {
+		t.Fatalf("email inbox should require email, got %+v", aud)
+	}
+
+	aud = buildCampaignAudience(&api.Inbox{ID: 7, ChannelType: "Channel::Api"}, nil, contacts, 0)
+	if aud.Reachable != 3 || len(aud.Warnings) != 1 {
+		t.Fatalf("channel without requirement should keep everyone and warn, got %+v", aud)
+	}
+}
+
+func campaignAudienceHandler(filterPayload *map[string]any, created *bool) *routeHandler {
+	return newRouteHandler().
+		On("GET", "/api/v1/accounts/1/campaigns/12", jsonResponse(200, `{
+			"id": 12, "title": "Promo", "inbox_id": 5, "audience": [{"type": "Label", "id": 2}]
+		}`)).
+		On("GET", "/api/v1/accounts/1/inboxes/5", jsonResponse(200, `{"id": 5, "name": "SMS", "channel_type": "Channel::Sms"}`)).
+		On("GET", "/api/v1/accounts/1/labels", jsonResponse(200, `{"payload": [{"id": 1, "title": "trial"}, {"id": 2, "title": "vip"}]}`)).
+		On("POST", "/api/v1/accounts/1/contacts/filter", func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewDecoder(r.Body).Decode(filterPayload)
+			w.Header().Set("Content-Type", "application/json")
+			switch r.URL.Query().Get("page") {
+			case "1":
+				_, _ = w.Write([]byte(`{"payload": [{"id": 1, "name": "Ann", "phone_number": "+15550001"}, {"id": 2, "name": "Bob"}], "meta": {"total_pages": 2}}`))
+			default:
+				_, _ = w.Write([]byte(`{"payload": [{"id": 1, "name": "Ann", "phone_number": "+15550001"}, {"id": 3, "name": "Cy", "phone_number": "+15550003"}], "meta": {"total_pages": 2}}`))
+			}
+		}).
+		On("POST", "/api/v1/accounts/1/campaigns", func(w http.ResponseWriter, r *http.Request) {
+			*created = true
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"id": 13, "title": "Promo"}`))
+		})
+}
+
+func TestCampaignsAudienceCommand(t *testing.T) {
+	var (
+		filter  map[string]any
+		created bool
+	)
+	setupTestEnvWithHandler(t, campaignAudienceHandler(&filter, &created))
+
+	output := captureStdout(t, func() {
+		if err := Execute(context.Background(), []string{"cm", "audience", "12", "--all", "-o", "json"}); err != nil {
+			t.Fatalf("campaigns audience failed: %v", err)
+		}
+	})
+	var aud campaignAudience
+	if err := json.Unmarshal([]byte(output), &aud); err != nil {
+		t.Fatalf("invalid JSON %q: %v", output, err)
+	}
+	if aud.CampaignID != 12 || aud.Matched != 3 || aud.Duplicates != 1 || aud.Excluded != 1 || aud.Reachable != 2 || len(aud.Contacts) != 2 {
+		t.Fatalf("unexpected audience %+v", aud)
+	}
+	conditions, _ := filter["payload"].([]any)
+	if len(conditions) != 1 {
+		t.Fatalf("unexpected filter payload %#v", filter)
+	}
+	cond, _ := conditions[0].(map[string]any)
+	if cond["attribute_key"] != "labels" || !strings.Contains(strings.Join(filterValueStrings(cond["values"]), ","), "vip") {
+		t.Fatalf("filter should target label titles, got %#v", cond)
+	}
+
+	output = captureStdout(t, func() {
+		if err := Execute(context.Background(), []string{"cm", "aud", "12"}); err != nil {
+			t.Fatalf("campaigns audience failed: %v", err)
+		}
+	})
+	for _, want := range []string{"Matched:    3 contacts (1 duplicates removed)", "Excluded:   1 (missing phone number)", "Reachable contacts", "Bob"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output missing %q:\n%s", want, output)
+		}
+	}
+}
+
+func TestCampaignsCreatePreviewDoesNotCreate(t *testing.T) {
+	var (
+		filter  map[string]any
+		created bool
+	)
+	setupTestEnvWithHandler(t, campaignAudienceHandler(&filter, &created))
+
+	output := captureStdout(t, func() {
+		err := Execute(context.Background(), []string{"cm", "create", "--title", "Promo", "-m", "Hi", "-I", "5", "-L", "1,2", "--preview", "--sample", "1", "-o", "json"})
+		if err != nil {
+			t.Fatalf("campaigns create --preview failed: %v", err)
+		}
+	})
+	if created {
+		t.Fatal("--preview must not create the campaign")
+	}
+	var aud campaignAudience
+	if err := json.Unmarshal([]byte(output), &aud); err != nil {
+		t.Fatalf("invalid JSON %q: %v", output, err)
+	}
+	if strings.Join(aud.Labels, ",") != "trial,vip" || aud.Reachable != 2 || len(aud.Contacts) != 1 || !aud.Truncated {
+		t.Fatalf("unexpected preview %+v", aud)
+	}
+
+	err := Execute(context.Background(), []string{"cm", "create", "--title", "Promo", "-m", "Hi", "-I", "5", "-L", "9", "--preview"})
+	if err == nil || !strings.Contains(err.Error(), "label 9 not found") {
+		t.Fatalf("expected unknown label error, got %v", err)
+	}
+}
+
+func filterValueStrings(v any) []string {
+	items, _ := v.([]any)
+	out := make([]string, 0, len(items))
+	for _, it := range items {
+		if s, ok := it.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+	"github.com/chatwoot/chatwoot-cli/internal/canned"
+)
+
+// findCannedResponse looks up a canned response by short code. A leading
+// "/" is ignored, and case only matters when two codes differ by case.
+func findCannedResponse(ctx context.Context, client *api.Client, shortCode string) (*api.CannedResponse, error) {
+	code := strings.TrimPrefix(strings.TrimSpace(shortCode), "/")
+	if code == "" {
+		return nil, fmt.Errorf("--canned requires a short code")
+	}
+	responses, err := client.CannedResponses().List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list canned responses: %w", err)
+	}
+	var folded *api.CannedResponse
+	for i := range responses {
+		if responses[i].ShortCode == code {
+			return &responses[i], nil
+		}
+		if folded == nil && strings.EqualFold(responses[i].ShortCode, code) {
+			folded = &responses[i]
+		}
+	}
+	if folded != nil {
+		return folded, nil
+	}
+	return nil, fmt.Errorf("canned response %q not found (see 'cw canned-responses list')", code)
+}
+
+// renderCannedResponse resolves shortCode and renders it against the
+// conversation, its contact and inbox, and the authenticated agent.
+func renderCannedResponse(ctx context.Context, client *api.Client, shortCode string, conversationID int) (string, error) {
+	response, err := findCannedResponse(ctx, client, shortCode)
+	if err != nil {
+		return "", err
+	}
+	content, err := renderTemplate(ctx, client, response.Content, conversationID)
+	if err != nil {
+		return "", fmt.Errorf("canned response %q: %w", response.ShortCode, err)
+	}
+	return content, nil
+}
+
+// renderTemplate fills {{variables}} in tmpl for a conversation. Only the
+// records the template refers to are fetched.
+func renderTemplate(ctx context.Context, client *api.Client, tmpl string, conversationID int) (string, error) {
+	vars := canned.Variables(tmpl)
+	if len(vars) == 0 {
+		return tmpl, nil
+	}
+	uses := func(prefix string) bool {
+		for _, v := range vars {
+			if strings.HasPrefix(v, prefix+".") {
+				return true
+			}
+		}
+		return false
+	}
+
+	var (
+		data canned.Data
+		err  error
+	)
+	if uses("conversation") || uses("contact") || uses("inbox") {
+		data.Conversation, err = client.Conversations().Get(ctx, conversationID)
+		if err != nil {
+			return "", fmt.Errorf("failed to get conversation %d: %w", conversationID, err)
+		}
+	}
+	if uses("contact") {
+		if contactID, ok := extractContactIDFromConversation(data.Conversation); ok {
+			data.Contact, err = client.Contacts().Get(ctx, contactID)
+			if err != nil {
+				return "", fmt.Errorf("failed to get contact %d: %w", contactID, err)
+			}
+		}
+	}
+	if uses("inbox") && data.Conversation.InboxID > 0 {
+		data.Inbox, err = client.Inboxes().Get(ctx, data.Conversation.InboxID)
+		if err != nil {
+			return "", fmt.Errorf("failed to get inbox %d: %w", data.Conversation.InboxID, err)
+		}
+	}
+	if uses("agent") {
+		data.Agent, err = client.Profile().Get(ctx)
+		if err != nil {
+			return "", fmt.Errorf("failed to get profile: %w", err)
+		}
+	}
+
+	return canned.Render(tmpl, data.Vars())
+}
+
+// withCannedDetail records the canned short code in a dry-run preview.
+func withCannedDetail(details map[string]any, shortCode string) map[string]any {
+	if shortCode != "" {
+		details["canned"] = shortCode
+	}
+	return details
+}
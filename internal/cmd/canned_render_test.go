@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+const cannedTestResponses = `[
+	{"id": 1, "short_code": "greet", "content": "Hi {{contact.first_name}}, {{agent.name}} here about #{{conversation.id}}."},
+	{"id": 2, "short_code": "plan", "content": "You are on {{contact.custom_attribute.plan}}."},
+	{"id": 3, "short_code": "static", "content": "Thanks for waiting!"}
+]`
+
+func cannedTestHandler(sent *map[string]any) *routeHandler {
+	return newRouteHandler().
+		On("GET", "/api/v1/accounts/1/canned_responses", jsonResponse(200, cannedTestResponses)).
+		On("GET", "/api/v1/accounts/1/conversations/123", jsonResponse(200, `{
+			"id": 123, "display_id": 123, "inbox_id": 10, "status": "open", "contact_id": 456
+		}`)).
+		On("GET", "/api/v1/accounts/1/contacts/456", jsonResponse(200, `{
+			"payload": {"id": 456, "name": "Ada Lovelace", "email": "ada@example.com", "created_at": 1700000000}
+		}`)).
+		On("GET", "/api/v1/accounts/1/inboxes/10", jsonResponse(200, `{"id": 10, "name": "Support", "channel_type": "Channel::Api"}`)).
+		On("GET", "/api/v1/profile", jsonResponse(200, `{"id": 3, "name": "Grace Hopper", "email": "grace@example.com"}`)).
+		On("POST", "/api/v1/accounts/1/conversations/123/messages", func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewDecoder(r.Body).Decode(sent)
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"id": 55, "conversation_id": 123, "content": "ok", "message_type": 1}`))
+		})
+}
+
+func TestCommentCanned_RendersAndSends(t *testing.T) {
+	var sent map[string]any
+	setupTestEnvWithHandler(t, cannedTestHandler(&sent))
+
+	_ = captureStdout(t, func() {
+		if err := Execute(context.Background(), []string{"comment", "123", "--canned", "/greet", "-o", "json"}); err != nil {
+			t.Fatalf("comment --canned failed: %v", err)
+		}
+	})
+
+	if got, want := sent["content"], "Hi Ada, Grace Hopper here about #123."; got != want {
+		t.Fatalf("content = %#v, want %q", got, want)
+	}
+}
+
+func TestMessagesCreateCanned_UnresolvedIsError(t *testing.T) {
+	var sent map[string]any
+	setupTestEnvWithHandler(t, cannedTestHandler(&sent))
+
+	err := Execute(context.Background(), []string{"messages", "create", "123", "--canned", "plan"})
+	if err == nil || !strings.Contains(err.Error(), "contact.custom_attribute.plan") {
+		t.Fatalf("expected unresolved variable error, got %v", err)
+	}
+	if sent != nil {
+		t.Fatalf("message should not be sent, got %#v", sent)
+	}
+}
+
+func TestMessagesCreateCanned_StaticContent(t *testing.T) {
+	var sent map[string]any
+	setupTestEnvWithHandler(t, cannedTestHandler(&sent))
+
+	_ = captureStdout(t, func() {
+		if err := Execute(context.Background(), []string{"messages", "create", "123", "--cn", "STATIC", "--private", "-o", "json"}); err != nil {
+			t.Fatalf("messages create --canned failed: %v", err)
+		}
+	})
+
+	if sent["content"] != "Thanks for waiting!" || sent["private"] != true {
+		t.Fatalf("unexpected payload %#v", sent)
+	}
+}
+
+func TestReplyCanned_DryRunPreview(t *testing.T) {
+	var sent map[string]any
+	setupTestEnvWithHandler(t, cannedTestHandler(&sent))
+
+	output := captureStdout(t, func() {
+		err := Execute(context.Background(), []string{"reply", "--conversation-id", "123", "--canned", "greet", "--dry-run", "-o", "json"})
+		if err != nil {
+			t.Fatalf("reply --canned --dry-run failed: %v", err)
+		}
+	})
+
+	var payload map[string]any
+	if err := json.Unmarshal([]byte(output), &payload); err != nil {
+		t.Fatalf("invalid JSON %q: %v", output, err)
+	}
+	if payload["content"] != "Hi Ada, Grace Hopper here about #123." || payload["canned"] != "greet" {
+		t.Fatalf("unexpected preview %#v", payload)
+	}
+	if sent != nil {
+		t.Fatal("dry-run must not send")
+	}
+}
+
+func TestCanned_ConflictsAndUnknownCode(t *testing.T) {
+	var sent map[string]any
+	setupTestEnvWithHandler(t, cannedTestHandler(&sent))
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"comment", "123", "Hello", "--canned", "greet"}, "not both"},
+		{[]string{"reply", "--conversation-id", "123", "--content", "Hi", "--canned", "greet"}, "cannot be used together"},
+		{[]string{"messages", "create", "123", "--content", "Hi", "--canned", "greet"}, "cannot be used together"},
+		{[]string{"comment", "123", "--canned", "nope"}, `canned response "nope" not found`},
+	}
+	for _, tt := range tests {
+		err := Execute(context.Background(), tt.args)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%v: expected error containing %q, got %v", tt.args, tt.want, err)
+		}
+	}
+	if sent != nil {
+		t.Fatalf("nothing should be sent, got %#v", sent)
+	}
+}
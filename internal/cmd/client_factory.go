@@ -29,6 +29,14 @@ func (f *clientFactory) account() (*api.Client, error) {
 	return f.newClient(cfg), nil
 }
 
+func (f *clientFactory) profile(name string) (*api.Client, error) {
+	cfg, err := config.ResolveProfileClientConfig(name)
+	if err != nil {
+		return nil, err
+	}
+	return f.newClient(cfg), nil
+}
+
 func (f *clientFactory) platform(baseURLOverride, tokenOverride string) (*api.Client, error) {
 	cfg, err := config.ResolvePlatformClientConfig(baseURLOverride, tokenOverride)
 	if err != nil {
@@ -67,6 +75,9 @@ func (f *clientFactory) newClient(cfg config.ClientConfig) *api.Client {
 		}
 	}
 	applyRetryOverrides(client)
+	if activeJournal != nil {
+		client.Changes = activeJournal.forAccount(client.BaseURL, client.AccountID)
+	}
 	return client
 }
 
@@ -25,13 +25,14 @@ type CommentResult struct {
 
 func newCommentCmd() *cobra.Command {
 	var (
-		content   string
-		resolve   bool
-		pending   bool
-		labels    []string
-		priority  string
-		snoozeFor string
-		light     bool
+		content    string
+		resolve    bool
+		pending    bool
+		labels     []string
+		priority   string
+		snoozeFor  string
+		cannedCode string
+		light      bool
 	)
 
 	cmd := &cobra.Command{
@@ -55,6 +56,12 @@ This is a convenience shortcut for:
   # Use --content instead of positional text
   cw comment 123 --content "Hello!"
 
+  # Send a canned response rendered for this conversation
+  cw comment 123 --canned greeting
+
+  # Preview the rendered canned response without sending
+  cw comment 123 --canned greeting --dry-run
+
   # Agent-friendly envelope
   cw comment 123 "Hello" --output agent
 `),
@@ -73,12 +80,17 @@ This is a convenience shortcut for:
 			if !cmd.Flags().Changed("content") {
 				content = positional
 			}
-			if content == "" {
-				return fmt.Errorf("message text is required (use --content or provide trailing args)")
+			if cannedCode != "" && content != "" {
+				return fmt.Errorf("provide message text or --canned, not both")
+			}
+			if cannedCode == "" && content == "" {
+				return fmt.Errorf("message text is required (use --content, --canned or provide trailing args)")
 			}
 
-			if err := validation.ValidateMessageContent(content); err != nil {
-				return err
+			if content != "" {
+				if err := validation.ValidateMessageContent(content); err != nil {
+					return err
+				}
 			}
 
 			// Validate side-effect flags before sending so we fail fast.
@@ -96,24 +108,40 @@ This is a convenience shortcut for:
 				}
 			}
 
+			// A canned response is rendered against the live conversation,
+			// so it needs the client before the dry-run preview.
+			var client *api.Client
+			if cannedCode != "" {
+				if client, err = getClient(); err != nil {
+					return err
+				}
+				if content, err = renderCannedResponse(cmdContext(cmd), client, cannedCode, conversationID); err != nil {
+					return err
+				}
+				if err := validation.ValidateMessageContent(content); err != nil {
+					return err
+				}
+			}
+
 			if ok, err := maybeDryRun(cmd, &dryrun.Preview{
 				Operation: "create",
 				Resource:  "message",
-				Details: map[string]any{
+				Details: withCannedDetail(map[string]any{
 					"conversation_id": conversationID,
 					"content":         content,
 					"private":         false,
 					"type":            "outgoing",
 					"resolve":         resolve,
 					"pending":         pending,
-				},
+				}, cannedCode),
 			}); ok {
 				return err
 			}
 
-			client, err := getClient()
-			if err != nil {
-				return err
+			if client == nil {
+				if client, err = getClient(); err != nil {
+					return err
+				}
 			}
 
 			ctx := cmdContext(cmd)
@@ -217,6 +245,8 @@ This is a convenience shortcut for:
 	}
 
 	cmd.Flags().StringVarP(&content, "content", "c", "", "Message content (alternative to positional text)")
+	cmd.Flags().StringVar(&cannedCode, "canned", "", "Send a canned response by short code, rendering {{contact.name}}-style variables")
+	flagAlias(cmd.Flags(), "canned", "cn")
 	cmd.Flags().BoolVarP(&resolve, "resolve", "R", false, "Resolve the conversation after sending")
 	cmd.Flags().BoolVarP(&pending, "pending", "p", false, "Set conversation to pending after sending")
 	cmd.Flags().StringSliceVar(&labels, "label", nil, "Add labels after sending (repeatable)")
@@ -17,6 +17,9 @@ func newConfigCmd() *cobra.Command {
 	cmd.AddCommand(newConfigProfilesCmd())
 	cmd.AddCommand(newConfigDashboardCmd())
 	cmd.AddCommand(newConfigStoreKeysCmd())
+	cmd.AddCommand(newConfigExportCmd())
+	cmd.AddCommand(newConfigDiffCmd())
+	cmd.AddCommand(newConfigApplyCmd())
 
 	return cmd
 }
@@ -0,0 +1,361 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/chatwoot/chatwoot-cli/internal/dryrun"
+	"github.com/chatwoot/chatwoot-cli/internal/manifest"
+)
+
+// manifestKinds lists the kinds accepted by --only, in manifest order.
+var manifestKinds = []string{
+	"labels", "teams", "inboxes", "agent_bots", "canned_responses",
+	"custom_attributes", "custom_filters", "webhooks", "automation_rules",
+}
+
+func newConfigExportCmd() *cobra.Command {
+	var (
+		file   string
+		format string
+		only   []string
+	)
+
+	cmd := &cobra.Command{
+		Use:     "export",
+		Aliases: []string{"exp"},
+		Short:   "Export account configuration as a YAML/JSON manifest",
+		Long: strings.TrimSpace(`
+Export labels, teams, inboxes, agent bots, canned responses, custom attributes,
+custom filters, webhooks, and automation rules into one manifest.
+
+Resources are keyed by name (title, short code, URL, ...) rather than ID, so the
+manifest can be diffed and applied against another account. Custom filters are
+per-user and reflect the authenticated user's filters.
+`),
+		Example: strings.TrimSpace(`
+  # Export everything as YAML
+  cw config export > staging.yaml
+
+  # Export selected kinds as JSON
+  cw config export --only labels,canned_responses --file config.json
+`),
+		Args: cobra.NoArgs,
+		RunE: RunE(func(cmd *cobra.Command, _ []string) error {
+			if format == "" {
+				format = manifest.FormatForPath(file)
+				if isJSON(cmd) && file == "" {
+					format = "json"
+				}
+			}
+			client, err := getClient()
+			if err != nil {
+				return err
+			}
+			m, err := manifest.Export(cmdContext(cmd), client)
+			if err != nil {
+				return err
+			}
+			if err := restrictManifestKinds(m, only); err != nil {
+				return err
+			}
+			data, err := manifest.Encode(m, format)
+			if err != nil {
+				return err
+			}
+			if file == "" || file == "-" {
+				_, err = cmd.OutOrStdout().Write(data)
+				return err
+			}
+			if err := os.WriteFile(file, data, 0o644); err != nil {
+				return fmt.Errorf("failed to write manifest: %w", err)
+			}
+			printAction(cmd, "Exported", "manifest", file, "")
+			return nil
+		}),
+	}
+
+	cmd.Flags().StringVarP(&file, "file", "f", "", "Write the manifest to a file instead of stdout")
+	cmd.Flags().StringVar(&format, "format", "", "Manifest format: yaml or json (default: from --file extension, else yaml)")
+	cmd.Flags().StringSliceVar(&only, "only", nil, "Only export these kinds (comma-separated): "+strings.Join(manifestKinds, ", "))
+	registerStaticCompletions(cmd, "format", []string{"yaml", "json"})
+	registerStaticCompletions(cmd, "only", manifestKinds)
+	flagAlias(cmd.Flags(), "format", "fmt")
+
+	return cmd
+}
+
+func newConfigDiffCmd() *cobra.Command {
+	var prune bool
+
+	cmd := &cobra.Command{
+		Use:   "diff <manifest>",
+		Short: "Show the changes needed to make the account match a manifest",
+		Long: strings.TrimSpace(`
+Compare a manifest with the current account and print a per-resource plan.
+
+Kinds missing from the manifest are ignored, as are empty fields. With --prune,
+resources present in the account but missing from a kind listed in the
+manifest are planned for deletion. Use "-" to read the manifest from stdin.
+`),
+		Example: strings.TrimSpace(`
+  cw config diff staging.yaml
+  cw config diff staging.yaml --prune -o json
+`),
+		Args: cobra.ExactArgs(1),
+		RunE: RunE(func(cmd *cobra.Command, args []string) error {
+			client, err := getClient()
+			if err != nil {
+				return err
+			}
+			desired, err := loadManifestArg(cmd, args[0])
+			if err != nil {
+				return err
+			}
+			current, err := manifest.Export(cmdContext(cmd), client)
+			if err != nil {
+				return err
+			}
+			plan, err := manifest.Diff(current, desired, manifest.DiffOptions{Prune: prune})
+			if err != nil {
+				return err
+			}
+
+			if isJSON(cmd) {
+				return printJSON(cmd, map[string]any{
+					"changes": plan.Changes,
+					"summary": plan.Summary(),
+				})
+			}
+			writeManifestPlan(cmd.OutOrStdout(), plan)
+			return nil
+		}),
+	}
+
+	cmd.Flags().BoolVar(&prune, "prune", false, "Plan deletes for resources not in the manifest")
+
+	return cmd
+}
+
+func newConfigApplyCmd() *cobra.Command {
+	var (
+		prune bool
+		force bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "apply <manifest>",
+		Short: "Apply a manifest to the account",
+		Long: strings.TrimSpace(`
+Compute the same plan as 'cw config diff' and execute it. Creates and updates
+run in dependency order (labels and teams before automation rules); deletes
+run last and require confirmation unless --force or --yes is set.
+
+Use the global --dry-run flag to preview the plan without making changes.
+Automation rules reference teams and inboxes by name, agents by email, and
+labels by title; they are resolved against the target account, and the plan
+fails if one cannot be found there or in the manifest.
+`),
+		Example: strings.TrimSpace(`
+  # Promote staging configuration to production
+  cw config export > staging.yaml
+  CHATWOOT_PROFILE=prod cw config apply staging.yaml --dry-run
+  CHATWOOT_PROFILE=prod cw config apply staging.yaml
+
+  # Also delete resources missing from the manifest
+  cw config apply staging.yaml --prune --force
+`),
+		Args: cobra.ExactArgs(1),
+		RunE: RunE(func(cmd *cobra.Command, args []string) error {
+			client, err := getClient()
+			if err != nil {
+				return err
+			}
+			desired, err := loadManifestArg(cmd, args[0])
+			if err != nil {
+				return err
+			}
+			ctx := cmdContext(cmd)
+			current, err := manifest.Export(ctx, client)
+			if err != nil {
+				return err
+			}
+			plan, err := manifest.Diff(current, desired, manifest.DiffOptions{Prune: prune})
+			if err != nil {
+				return err
+			}
+
+			summary := plan.Summary()
+			if ok, err := maybeDryRun(cmd, &dryrun.Preview{
+				Operation:   "apply",
+				Resource:    "manifest",
+				Description: manifestPlanText(plan),
+				Details: map[string]any{
+					"create":  summary[manifest.ActionCreate],
+					"update":  summary[manifest.ActionUpdate],
+					"delete":  summary[manifest.ActionDelete],
+					"changes": plan.Changes,
+				},
+			}); ok {
+				return err
+			}
+
+			if plan.Empty() {
+				if isJSON(cmd) {
+					return printJSON(cmd, map[string]any{"results": []manifest.Result{}, "summary": summary})
+				}
+				_, _ = fmt.Fprintln(cmd.OutOrStdout(), "No changes. Account matches the manifest.")
+				return nil
+			}
+
+			if deletes := summary[manifest.ActionDelete]; deletes > 0 {
+				ok, err := confirmAction(cmd, confirmOptions{
+					Prompt:              fmt.Sprintf("Apply will delete %d resources. Continue? (y/N): ", deletes),
+					CancelMessage:       "Apply cancelled.",
+					Force:               force,
+					RequireForceForJSON: true,
+				})
+				if err != nil {
+					return err
+				}
+				if !ok {
+					return nil
+				}
+			}
+
+			results, failed := manifest.Apply(ctx, client, plan)
+
+			if isJSON(cmd) {
+				if err := printJSON(cmd, map[string]any{"results": results, "summary": summary, "failed": failed}); err != nil {
+					return err
+				}
+			} else {
+				out := cmd.OutOrStdout()
+				for _, r := range results {
+					switch {
+					case r.Error != "":
+						_, _ = fmt.Fprintf(out, "%s %s %q: failed: %s\n", r.Action, r.Kind, r.Key, r.Error)
+					case r.Skipped:
+						_, _ = fmt.Fprintf(out, "skip %s %q: %s\n", r.Kind, r.Key, strings.Join(r.Warnings, "; "))
+					default:
+						_, _ = fmt.Fprintf(out, "%s %s %q (id %d)\n", pastTense(r.Action), r.Kind, r.Key, r.ID)
+					}
+				}
+				_, _ = fmt.Fprintf(out, "Applied %d changes (%d failed).\n", len(results)-failed, failed)
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d of %d changes failed", failed, len(results))
+			}
+			return nil
+		}),
+	}
+
+	cmd.Flags().BoolVar(&prune, "prune", false, "Delete resources not in the manifest")
+	cmd.Flags().BoolVar(&force, "force", false, "Skip the delete confirmation prompt")
+	flagAlias(cmd.Flags(), "force", "fc")
+	registerCommandContract(cmd, true, true)
+
+	return cmd
+}
+
+func loadManifestArg(cmd *cobra.Command, path string) (*manifest.Manifest, error) {
+	if path != "-" {
+		return manifest.Load(path)
+	}
+	data, err := io.ReadAll(cmd.InOrStdin())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest from stdin: %w", err)
+	}
+	return manifest.Parse(data)
+}
+
+// restrictManifestKinds drops every kind not listed in only.
+func restrictManifestKinds(m *manifest.Manifest, only []string) error {
+	if len(only) == 0 {
+		return nil
+	}
+	keep := make(map[string]bool, len(only))
+	for _, kind := range only {
+		kind = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(kind)), "-", "_")
+		if !slices.Contains(manifestKinds, kind) {
+			return fmt.Errorf("unknown kind %q (valid: %s)", kind, strings.Join(manifestKinds, ", "))
+		}
+		keep[kind] = true
+	}
+	if !keep["labels"] {
+		m.Labels = nil
+	}
+	if !keep["teams"] {
+		m.Teams = nil
+	}
+	if !keep["inboxes"] {
+		m.Inboxes = nil
+	}
+	if !keep["agent_bots"] {
+		m.AgentBots = nil
+	}
+	if !keep["canned_responses"] {
+		m.CannedResponses = nil
+	}
+	if !keep["custom_attributes"] {
+		m.CustomAttributes = nil
+	}
+	if !keep["custom_filters"] {
+		m.CustomFilters = nil
+	}
+	if !keep["webhooks"] {
+		m.Webhooks = nil
+	}
+	if !keep["automation_rules"] {
+		m.AutomationRules = nil
+	}
+	return nil
+}
+
+func writeManifestPlan(w io.Writer, plan manifest.Plan) {
+	if plan.Empty() {
+		_, _ = fmt.Fprintln(w, "No changes. Account matches the manifest.")
+		return
+	}
+	_, _ = fmt.Fprint(w, manifestPlanText(plan))
+}
+
+func manifestPlanText(plan manifest.Plan) string {
+	var b strings.Builder
+	for _, c := range plan.Changes {
+		symbol := map[manifest.Action]string{
+			manifest.ActionCreate: "+",
+			manifest.ActionUpdate: "~",
+			manifest.ActionDelete: "-",
+		}[c.Action]
+		_, _ = fmt.Fprintf(&b, "%s %s %q", symbol, c.Kind, c.Key)
+		if len(c.Fields) > 0 {
+			_, _ = fmt.Fprintf(&b, " (%s)", strings.Join(c.Fields, ", "))
+		}
+		b.WriteString("\n")
+		for _, warning := range c.Warnings {
+			_, _ = fmt.Fprintf(&b, "    ! %s\n", warning)
+		}
+	}
+	s := plan.Summary()
+	_, _ = fmt.Fprintf(&b, "\nPlan: %d to create, %d to update, %d to delete.\n",
+		s[manifest.ActionCreate], s[manifest.ActionUpdate], s[manifest.ActionDelete])
+	return b.String()
+}
+
+func pastTense(action manifest.Action) string {
+	switch action {
+	case manifest.ActionCreate:
+		return "created"
+	case manifest.ActionUpdate:
+		return "updated"
+	case manifest.ActionDelete:
+		return "deleted"
+	}
+	return string(action)
+}
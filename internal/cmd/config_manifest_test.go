@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// newManifestTestHandler serves a small account configuration and records mutations.
+func newManifestTestHandler(mu *sync.Mutex, mutations *[]string) *routeHandler {
+	record := func(status int, body string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			*mutations = append(*mutations, r.Method+" "+r.URL.Path)
+			mu.Unlock()
+			jsonResponse(status, body)(w, r)
+		}
+	}
+	return newRouteHandler().
+		On("GET", "/api/v1/accounts/1/labels", jsonResponse(200, `{"payload": [
+			{"id": 1, "title": "vip", "color": "#FF0000", "show_on_sidebar": true},
+			{"id": 2, "title": "legacy", "show_on_sidebar": false}
+		]}`)).
+		On("GET", "/api/v1/accounts/1/teams", jsonResponse(200, `[{"id": 5, "name": "Support", "description": "L1"}]`)).
+		On("GET", "/api/v1/accounts/1/inboxes", jsonResponse(200, `{"payload": [{"id": 3, "name": "Web", "channel_type": "Channel::WebWidget", "greeting_enabled": false, "enable_auto_assignment": true}]}`)).
+		On("GET", "/api/v1/accounts/1/agent_bots", jsonResponse(200, `[{"id": 8, "name": "Bot", "outgoing_url": "https://bot.example.com", "account_id": 1}, {"id": 99, "name": "System"}]`)).
+		On("GET", "/api/v1/accounts/1/canned_responses", jsonResponse(200, `[{"id": 11, "short_code": "hi", "content": "Hello"}]`)).
+		On("GET", "/api/v1/accounts/1/custom_attribute_definitions", func(w http.ResponseWriter, r *http.Request) {
+			body := `[]`
+			if r.URL.Query().Get("attribute_model") == "1" {
+				body = `[{"id": 21, "attribute_key": "plan", "attribute_display_name": "Plan", "attribute_model": "contact_attribute", "attribute_display_type": "text"}]`
+			}
+			jsonResponse(200, body)(w, r)
+		}).
+		On("GET", "/api/v1/accounts/1/custom_filters", jsonResponse(200, `[]`)).
+		On("GET", "/api/v1/accounts/1/webhooks", jsonResponse(200, `{"payload": {"webhooks": [{"id": 31, "url": "https://hooks.example.com", "subscriptions": ["message_created"]}]}}`)).
+		On("GET", "/api/v1/accounts/1/agents", jsonResponse(200, `[]`)).
+		On("GET", "/api/v1/accounts/1/automation_rules", jsonResponse(200, `{"payload": []}`)).
+		On("POST", "/api/v1/accounts/1/labels", record(200, `{"id": 40, "title": "new"}`)).
+		On("PATCH", "/api/v1/accounts/1/teams/5", record(200, `{"id": 5, "name": "Support"}`)).
+		On("DELETE", "/api/v1/accounts/1/labels/2", record(200, `{}`))
+}
+
+func TestConfigExportCommand(t *testing.T) {
+	var mu sync.Mutex
+	var mutations []string
+	setupTestEnvWithHandler(t, newManifestTestHandler(&mu, &mutations))
+
+	file := filepath.Join(t.TempDir(), "account.yaml")
+	_ = captureStdout(t, func() {
+		if err := Execute(context.Background(), []string{"config", "export", "--file", file}); err != nil {
+			t.Fatalf("export failed: %v", err)
+		}
+	})
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := string(data)
+	for _, want := range []string{"title: vip", "name: Support", "short_code: hi", "key: plan", "url: https://hooks.example.com", "name: Bot"} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("expected %q in manifest:\n%s", want, doc)
+		}
+	}
+	if strings.Contains(doc, "System") {
+		t.Errorf("system bots should not be exported:\n%s", doc)
+	}
+
+	output := captureStdout(t, func() {
+		if err := Execute(context.Background(), []string{"config", "export", "--only", "labels", "-o", "json"}); err != nil {
+			t.Fatalf("export failed: %v", err)
+		}
+	})
+	var m map[string]any
+	if err := json.Unmarshal([]byte(output), &m); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, output)
+	}
+	if m["labels"] == nil || m["teams"] != nil {
+		t.Fatalf("expected only labels, got %v", m)
+	}
+}
+
+func TestConfigDiffAndApplyCommands(t *testing.T) {
+	var mu sync.Mutex
+	var mutations []string
+	setupTestEnvWithHandler(t, newManifestTestHandler(&mu, &mutations))
+
+	file := filepath.Join(t.TempDir(), "desired.yaml")
+	doc := `
+labels:
+  - title: vip
+    color: "#ff0000"
+  - title: new
+teams:
+  - name: Support
+    description: Tier 1
+`
+	if err := os.WriteFile(file, []byte(doc), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	output := captureStdout(t, func() {
+		if err := Execute(context.Background(), []string{"config", "diff", file, "--prune"}); err != nil {
+			t.Fatalf("diff failed: %v", err)
+		}
+	})
+	for _, want := range []string{`+ label "new"`, `~ team "Support" (description)`, `- label "legacy"`, "Plan: 1 to create, 1 to update, 1 to delete."} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in diff output:\n%s", want, output)
+		}
+	}
+
+	output = captureStdout(t, func() {
+		if err := Execute(context.Background(), []string{"config", "apply", file, "--prune", "--dry-run", "-o", "json"}); err != nil {
+			t.Fatalf("dry-run apply failed: %v", err)
+		}
+	})
+	if !strings.Contains(output, `"dry_run": true`) || len(mutations) != 0 {
+		t.Fatalf("expected dry-run preview and no mutations, got %v\n%s", mutations, output)
+	}
+
+	output = captureStdout(t, func() {
+		if err := Execute(context.Background(), []string{"config", "apply", file, "--prune", "--force", "-o", "json"}); err != nil {
+			t.Fatalf("apply failed: %v", err)
+		}
+	})
+	want := []string{
+		"POST /api/v1/accounts/1/labels",
+		"PATCH /api/v1/accounts/1/teams/5",
+		"DELETE /api/v1/accounts/1/labels/2",
+	}
+	if strings.Join(mutations, "|") != strings.Join(want, "|") {
+		t.Fatalf("mutations = %v, want %v", mutations, want)
+	}
+	if !strings.Contains(output, `"failed": 0`) || !strings.Contains(output, `"id": 40`) {
+		t.Fatalf("unexpected apply output: %s", output)
+	}
+
+	// Deletes need --force in JSON mode.
+	err := Execute(context.Background(), []string{"config", "apply", file, "--prune", "-o", "json"})
+	if err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Fatalf("expected --force error, got %v", err)
+	}
+}
+
+// newRuleAccountHandler serves an account with one label, team, inbox, and
+// agent under the given IDs, plus the given automation rules payload.
+func newRuleAccountHandler(labelID, teamID, inboxID, agentID int, rules string) *routeHandler {
+	return newRouteHandler().
+		On("GET", "/api/v1/accounts/1/labels", jsonResponse(200, fmt.Sprintf(`{"payload": [{"id": %d, "title": "vip"}]}`, labelID))).
+		On("GET", "/api/v1/accounts/1/teams", jsonResponse(200, fmt.Sprintf(`[{"id": %d, "name": "Support"}]`, teamID))).
+		On("GET", "/api/v1/accounts/1/inboxes", jsonResponse(200, fmt.Sprintf(`{"payload": [{"id": %d, "name": "Web", "channel_type": "Channel::WebWidget"}]}`, inboxID))).
+		On("GET", "/api/v1/accounts/1/agents", jsonResponse(200, fmt.Sprintf(`[{"id": %d, "name": "Alice", "email": "alice@example.com"}]`, agentID))).
+		On("GET", "/api/v1/accounts/1/agent_bots", jsonResponse(200, `[]`)).
+		On("GET", "/api/v1/accounts/1/canned_responses", jsonResponse(200, `[]`)).
+		On("GET", "/api/v1/accounts/1/custom_attribute_definitions", jsonResponse(200, `[]`)).
+		On("GET", "/api/v1/accounts/1/custom_filters", jsonResponse(200, `[]`)).
+		On("GET", "/api/v1/accounts/1/webhooks", jsonResponse(200, `{"payload": {"webhooks": []}}`)).
+		On("GET", "/api/v1/accounts/1/automation_rules", jsonResponse(200, rules))
+}
+
+func TestConfigApplyResolvesAutomationRuleReferences(t *testing.T) {
+	setupTestEnvWithHandler(t, newRuleAccountHandler(1, 5, 3, 7, `{"payload": [{
+		"id": 9, "name": "Route", "event_name": "conversation_created", "active": true,
+		"conditions": [
+			{"attribute_key": "inbox_id", "filter_operator": "equal_to", "values": [3]},
+			{"attribute_key": "assignee_id", "filter_operator": "equal_to", "values": [{"id": 7, "name": "Alice"}]}
+		],
+		"actions": [
+			{"action_name": "assign_team", "action_params": [5]},
+			{"action_name": "add_label", "action_params": ["vip"]},
+			{"action_name": "send_email_to_team", "action_params": [{"message": "hi", "team_ids": [5]}]}
+		]
+	}]}`))
+
+	file := filepath.Join(t.TempDir(), "source.yaml")
+	_ = captureStdout(t, func() {
+		if err := Execute(context.Background(), []string{"config", "export", "--only", "automation_rules", "--file", file}); err != nil {
+			t.Fatalf("export failed: %v", err)
+		}
+	})
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"- Web", "- alice@example.com", "- Support"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("expected reference %q in manifest:\n%s", want, data)
+		}
+	}
+
+	var created map[string]any
+	target := newRuleAccountHandler(11, 55, 33, 77, `{"payload": []}`).
+		On("POST", "/api/v1/accounts/1/automation_rules", func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewDecoder(r.Body).Decode(&created)
+			jsonResponse(200, `{"id": 90, "name": "Route"}`)(w, r)
+		})
+	setupTestEnvWithHandler(t, target)
+
+	_ = captureStdout(t, func() {
+		if err := Execute(context.Background(), []string{"config", "apply", file}); err != nil {
+			t.Fatalf("apply failed: %v", err)
+		}
+	})
+	got, _ := json.Marshal(map[string]any{"conditions": created["conditions"], "actions": created["actions"]})
+	want := `{"actions":[{"action_name":"assign_team","action_params":[55]},{"action_name":"add_label","action_params":["vip"]},` +
+		`{"action_name":"send_email_to_team","action_params":[{"message":"hi","team_ids":[55]}]}],` +
+		`"conditions":[{"attribute_key":"inbox_id","filter_operator":"equal_to","values":[33]},` +
+		`{"attribute_key":"assignee_id","filter_operator":"equal_to","values":[77]}]}`
+	if string(got) != want {
+		t.Fatalf("created rule =\n%s\nwant\n%s", got, want)
+	}
+
+	// A reference the target account cannot resolve fails the plan.
+	setupTestEnvWithHandler(t, newRuleAccountHandler(11, 55, 33, 77, `{"payload": []}`).
+		On("GET", "/api/v1/accounts/1/agents", jsonResponse(200, `[]`)))
+	err = Execute(context.Background(), []string{"config", "diff", file})
+	if err == nil || !strings.Contains(err.Error(), `agent "alice@example.com" not found`) {
+		t.Fatalf("expected unresolved agent error, got %v", err)
+	}
+}
@@ -40,6 +40,8 @@ func newContactsCmd() *cobra.Command {
 	cmd.AddCommand(newContactsNotesDeleteCmd())
 	cmd.AddCommand(newContactsBulkCmd())
 	cmd.AddCommand(newContactsMergeCmd())
+	cmd.AddCommand(newContactsDedupeCmd())
+	cmd.AddCommand(newContactsImportCmd())
 
 	return cmd
 }
@@ -0,0 +1,330 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+	"github.com/chatwoot/chatwoot-cli/internal/config"
+	"github.com/chatwoot/chatwoot-cli/internal/dedupe"
+	"github.com/chatwoot/chatwoot-cli/internal/dryrun"
+)
+
+// dedupeMerge is the outcome of merging one duplicate into its group's keeper.
+type dedupeMerge struct {
+	KeepID  int      `json:"keep_id"`
+	MergeID int      `json:"merge_id"`
+	Score   int      `json:"score"`
+	Reasons []string `json:"reasons"`
+	Error   string   `json:"error,omitempty"`
+}
+
+func newContactsDedupeCmd() *cobra.Command {
+	var (
+		search      string
+		maxPages    int
+		countryCode string
+		minScore    int
+		apply       bool
+		force       bool
+		undoPath    string
+	)
+
+	cmd := &cobra.Command{
+		Use:     "dedupe",
+		Aliases: []string{"dd"},
+		Short:   "Find and merge duplicate contacts",
+		Long: strings.TrimSpace(`
+Scan contacts (all of them, or the results of --search) for likely duplicates
+and print scored candidate pairs with the reasons they matched:
+
+  email          same address, ignoring case                       +70
+  email_alias    same mailbox after removing +tags and Gmail dots  +60
+  phone          same number in E.164 form                         +70
+  name           same name, ignoring case, accents and word order  +30
+  name_similar   fuzzy name match                                  up to +30
+  email_differs  both have an email and they differ                -20
+  phone_differs  both have a phone and they differ                 -20
+
+Scores are capped at 100. Names Chatwoot generated from an email or phone
+number are ignored. Phone numbers stored without a country code are only
+compared when --country-code is given.
+
+With --apply, pairs scoring at least --min-score are joined into groups and
+every contact in a group is merged into its oldest contact, using the same
+merge as "contacts merge". Before each merge the deleted contact's fields and
+labels are appended to the undo log, since merges cannot be reversed.
+`),
+		Example: strings.TrimSpace(`
+  # List candidate pairs
+  cw contacts dedupe
+
+  # Only contacts matching a search, treating national numbers as UK
+  cw contacts dedupe --search acme --country-code 44
+
+  # Preview, then merge pairs scoring 90 or more
+  cw contacts dedupe --apply --min-score 90 --dry-run
+  cw contacts dedupe --apply --min-score 90
+`),
+		Args: cobra.NoArgs,
+		RunE: RunE(func(cmd *cobra.Command, _ []string) error {
+			if minScore < 0 || minScore > 100 {
+				return fmt.Errorf("--min-score must be between 0 and 100")
+			}
+			if apply && !cmd.Flags().Changed("min-score") {
+				return fmt.Errorf("--apply requires an explicit --min-score")
+			}
+
+			client, err := getClient()
+			if err != nil {
+				return err
+			}
+			ctx := cmdContext(cmd)
+
+			contacts, err := loadDedupeContacts(ctx, client, search, maxPages)
+			if err != nil {
+				return err
+			}
+			pairs := dedupe.Find(contacts, dedupe.Options{CountryCode: countryCode})
+
+			if !apply {
+				shown := make([]dedupe.Pair, 0, len(pairs))
+				for _, p := range pairs {
+					if p.Score >= minScore {
+						shown = append(shown, p)
+					}
+				}
+				return printDedupePairs(cmd, len(contacts), shown)
+			}
+
+			groups := dedupe.Groups(pairs, minScore)
+			dryRun := dryrun.IsEnabled(ctx)
+			if len(groups) == 0 || dryRun {
+				return printDedupePlan(cmd, len(contacts), minScore, groups, dryRun)
+			}
+
+			if err := requireForceForJSON(cmd, force); err != nil {
+				return err
+			}
+			if !isJSON(cmd) {
+				if err := printDedupePlan(cmd, len(contacts), minScore, groups, false); err != nil {
+					return err
+				}
+			}
+			total := 0
+			for _, g := range groups {
+				total += len(g.Merge)
+			}
+			ok, err := confirmAction(cmd, confirmOptions{
+				Prompt:              yellow(fmt.Sprintf("%d contacts will be PERMANENTLY DELETED. Type 'merge' to confirm: ", total)),
+				Expected:            "merge",
+				CancelMessage:       "Merge cancelled.",
+				Force:               force,
+				RequireForceForJSON: true,
+			})
+			if err != nil || !ok {
+				return err
+			}
+
+			if undoPath == "" {
+				undoPath = dedupe.DefaultUndoPath(config.Dir())
+			}
+			undo, err := dedupe.OpenUndoLog(undoPath)
+			if err != nil {
+				return err
+			}
+			defer func() { _ = undo.Close() }()
+
+			results := mergeDedupeGroups(ctx, cmd, client, undo, groups)
+			failed := 0
+			for _, r := range results {
+				if r.Error != "" {
+					failed++
+				}
+			}
+
+			if isJSON(cmd) {
+				if err := printJSON(cmd, map[string]any{
+					"scanned":   len(contacts),
+					"min_score": minScore,
+					"merges":    results,
+					"failed":    failed,
+					"undo_log":  undo.Path(),
+				}); err != nil {
+					return err
+				}
+			} else {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "\nMerged %d of %d contacts. Deleted contacts were recorded in %s\n", len(results)-failed, len(results), undo.Path())
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d of %d merges failed", failed, len(results))
+			}
+			return nil
+		}),
+	}
+
+	cmd.Flags().StringVar(&search, "search", "", "Only scan contacts matching this search query")
+	flagAlias(cmd.Flags(), "search", "sq")
+	cmd.Flags().IntVar(&maxPages, "max-pages", 0, "Stop after this many pages of contacts (0 = all)")
+	flagAlias(cmd.Flags(), "max-pages", "mp")
+	cmd.Flags().StringVar(&countryCode, "country-code", "", "Calling code for phone numbers stored without one (e.g. 1, 44)")
+	cmd.Flags().IntVar(&minScore, "min-score", 0, "Only report (or merge) pairs scoring at least this much (0-100)")
+	flagAlias(cmd.Flags(), "min-score", "ms")
+	cmd.Flags().BoolVar(&apply, "apply", false, "Merge each group of duplicates into its oldest contact")
+	cmd.Flags().BoolVarP(&force, "force", "F", false, "Skip confirmation prompt (required for --apply with --output json)")
+	cmd.Flags().StringVar(&undoPath, "undo-log", "", "Append deleted contacts to this JSONL file (default <config-dir>/contacts-dedupe-undo.jsonl)")
+	flagAlias(cmd.Flags(), "undo-log", "ul")
+	registerCommandContract(cmd, true, true)
+
+	return cmd
+}
+
+// loadDedupeContacts pages through all contacts, or the search results for
+// search.
+func loadDedupeContacts(ctx context.Context, client *api.Client, search string, maxPages int) ([]api.Contact, error) {
+	var contacts []api.Contact
+	for page := 1; maxPages <= 0 || page <= maxPages; page++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		var (
+			list *api.ContactList
+			err  error
+		)
+		if search != "" {
+			list, err = client.Contacts().Search(ctx, search, page)
+		} else {
+			list, err = client.Contacts().List(ctx, api.ListContactsParams{Page: page})
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list contacts: %w", err)
+		}
+		contacts = append(contacts, list.Payload...)
+		if len(list.Payload) == 0 || !contactsMetaHasMore(list.Meta) {
+			break
+		}
+	}
+	return contacts, nil
+}
+
+func mergeDedupeGroups(ctx context.Context, cmd *cobra.Command, client *api.Client, undo *dedupe.UndoLog, groups []dedupe.Group) []dedupeMerge {
+	var results []dedupeMerge
+	for _, g := range groups {
+		for _, m := range g.Merge {
+			r := dedupeMerge{KeepID: g.Keep.ID, MergeID: m.ID}
+			// Report the strongest pair that links this contact into the group.
+			for _, p := range g.Pairs {
+				if (p.Merge.ID == m.ID || p.Keep.ID == m.ID) && p.Score > r.Score {
+					r.Score, r.Reasons = p.Score, p.Reasons
+				}
+			}
+			if err := mergeDuplicate(ctx, client, undo, g.Keep.ID, m.ID, r); err != nil {
+				r.Error = err.Error()
+			}
+			results = append(results, r)
+			if !isJSON(cmd) {
+				if r.Error != "" {
+					_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s #%d into #%d: %s\n", red("FAILED"), m.ID, g.Keep.ID, r.Error)
+				} else {
+					_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Merged #%d into #%d\n", m.ID, g.Keep.ID)
+				}
+			}
+		}
+	}
+	return results
+}
+
+// mergeDuplicate records the contact about to be deleted, then merges it.
+func mergeDuplicate(ctx context.Context, client *api.Client, undo *dedupe.UndoLog, keepID, mergeID int, r dedupeMerge) error {
+	deleted, err := client.Contacts().Get(ctx, mergeID)
+	if err != nil {
+		return fmt.Errorf("failed to get contact %d: %w", mergeID, err)
+	}
+	labels, err := client.Contacts().Labels(ctx, mergeID)
+	if err != nil {
+		return fmt.Errorf("failed to get labels for contact %d: %w", mergeID, err)
+	}
+	if err := undo.Write(dedupe.UndoRecord{
+		Time:      time.Now().UTC(),
+		AccountID: client.AccountID,
+		KeepID:    keepID,
+		Score:     r.Score,
+		Reasons:   r.Reasons,
+		Deleted:   *deleted,
+		Labels:    labels,
+	}); err != nil {
+		return fmt.Errorf("failed to write undo log: %w", err)
+	}
+	if _, err := client.Contacts().Merge(ctx, keepID, mergeID); err != nil {
+		return fmt.Errorf("failed to merge contacts: %w", err)
+	}
+	return nil
+}
+
+func printDedupePairs(cmd *cobra.Command, scanned int, pairs []dedupe.Pair) error {
+	if isJSON(cmd) {
+		return printJSON(cmd, map[string]any{
+			"scanned": scanned,
+			"pairs":   pairs,
+		})
+	}
+
+	if len(pairs) == 0 {
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "No duplicate candidates among %d contacts.\n", scanned)
+		return nil
+	}
+	w := newTabWriterFromCmd(cmd)
+	_, _ = fmt.Fprintln(w, "SCORE\tKEEP\tMERGE\tREASONS\tKEEP CONTACT\tMERGE CONTACT")
+	for _, p := range pairs {
+		_, _ = fmt.Fprintf(w, "%d\t%d\t%d\t%s\t%s\t%s\n",
+			p.Score, p.Keep.ID, p.Merge.ID, strings.Join(p.Reasons, ","),
+			dedupeContactSummary(p.Keep), dedupeContactSummary(p.Merge))
+	}
+	_ = w.Flush()
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "\n%d candidate pairs among %d contacts\n", len(pairs), scanned)
+	return nil
+}
+
+func printDedupePlan(cmd *cobra.Command, scanned, minScore int, groups []dedupe.Group, dryRun bool) error {
+	if isJSON(cmd) {
+		return printJSON(cmd, map[string]any{
+			"dry_run":   dryRun,
+			"scanned":   scanned,
+			"min_score": minScore,
+			"groups":    groups,
+		})
+	}
+
+	out := cmd.OutOrStdout()
+	if len(groups) == 0 {
+		_, _ = fmt.Fprintf(out, "No duplicates scoring %d or more among %d contacts.\n", minScore, scanned)
+		return nil
+	}
+	if dryRun {
+		_, _ = fmt.Fprintln(out, yellow("DRY RUN - Contacts will NOT be merged"))
+		_, _ = fmt.Fprintln(out)
+	}
+	for _, g := range groups {
+		_, _ = fmt.Fprintf(out, "%s #%d %s\n", green("KEEP  "), g.Keep.ID, dedupeContactSummary(g.Keep))
+		for _, m := range g.Merge {
+			_, _ = fmt.Fprintf(out, "%s #%d %s\n", red("DELETE"), m.ID, dedupeContactSummary(m))
+		}
+		_, _ = fmt.Fprintln(out)
+	}
+	return nil
+}
+
+func dedupeContactSummary(c api.Contact) string {
+	parts := []string{displayContactName(c.Name)}
+	if email := strings.TrimSpace(c.Email); email != "" {
+		parts = append(parts, "<"+email+">")
+	}
+	if phone := strings.TrimSpace(c.PhoneNumber); phone != "" {
+		parts = append(parts, phone)
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const dedupeContactsPayload = `{"meta": {"count": 4, "current_page": 1}, "payload": [
+	{"id": 1, "name": "Jane Doe", "email": "jane@example.com", "created_at": 100},
+	{"id": 2, "name": "jane doe", "email": "Jane@Example.com", "created_at": 200},
+	{"id": 3, "name": "Bob Stone", "phone_number": "+442079460958", "created_at": 300},
+	{"id": 4, "name": "Robert Stone", "phone_number": "020 7946 0958", "created_at": 400}
+]}`
+
+func TestContactsDedupeListsPairs(t *testing.T) {
+	setupTestEnvWithHandler(t, newRouteHandler().
+		On("GET", "/api/v1/accounts/1/contacts", jsonResponse(200, dedupeContactsPayload)))
+
+	output := captureStdout(t, func() {
+		if err := Execute(context.Background(), []string{"contacts", "dedupe", "--country-code", "44"}); err != nil {
+			t.Fatalf("contacts dedupe failed: %v", err)
+		}
+	})
+	for _, want := range []string{"100", "email,name", "70", "phone", "2 candidate pairs among 4 contacts"} {
+		if !strings.Contains(output, want) {
+			t.Fatalf("missing %q in output: %s", want, output)
+		}
+	}
+
+	output = captureStdout(t, func() {
+		if err := Execute(context.Background(), []string{"contacts", "dedupe", "--min-score", "90", "-o", "json"}); err != nil {
+			t.Fatalf("contacts dedupe failed: %v", err)
+		}
+	})
+	var result struct {
+		Scanned int `json:"scanned"`
+		Pairs   []struct {
+			Score   int      `json:"score"`
+			Reasons []string `json:"reasons"`
+			Keep    struct{ ID int }
+			Merge   struct{ ID int }
+		} `json:"pairs"`
+	}
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, output)
+	}
+	if result.Scanned != 4 || len(result.Pairs) != 1 || result.Pairs[0].Keep.ID != 1 || result.Pairs[0].Merge.ID != 2 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+}
+
+func TestContactsDedupeApply(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		merges []string
+	)
+	setupTestEnvWithHandler(t, newRouteHandler().
+		On("GET", "/api/v1/accounts/1/contacts", jsonResponse(200, dedupeContactsPayload)).
+		On("GET", "/api/v1/accounts/1/contacts/2", jsonResponse(200, `{"payload": {"id": 2, "name": "jane doe", "email": "Jane@Example.com", "custom_attributes": {"plan": "pro"}}}`)).
+		On("GET", "/api/v1/accounts/1/contacts/2/labels", jsonResponse(200, `{"labels": ["vip"]}`)).
+		On("POST", "/api/v1/accounts/1/actions/contact_merge", func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			mu.Lock()
+			merges = append(merges, string(body))
+			mu.Unlock()
+			jsonResponse(200, `{"id": 1, "name": "Jane Doe"}`)(w, r)
+		}))
+	undoPath := filepath.Join(t.TempDir(), "undo.jsonl")
+
+	if err := Execute(context.Background(), []string{"contacts", "dedupe", "--apply"}); err == nil || !strings.Contains(err.Error(), "--min-score") {
+		t.Fatalf("expected --min-score error, got %v", err)
+	}
+
+	output := captureStdout(t, func() {
+		if err := Execute(context.Background(), []string{"contacts", "dedupe", "--apply", "--min-score", "90", "--dry-run"}); err != nil {
+			t.Fatalf("dry run failed: %v", err)
+		}
+	})
+	if !strings.Contains(output, "DRY RUN") || !strings.Contains(output, "#2") || len(merges) != 0 {
+		t.Fatalf("unexpected dry run (merges %v): %s", merges, output)
+	}
+
+	output = captureStdout(t, func() {
+		if err := Execute(context.Background(), []string{"contacts", "dedupe", "--apply", "--min-score", "90", "--force", "--undo-log", undoPath}); err != nil {
+			t.Fatalf("apply failed: %v", err)
+		}
+	})
+	if !strings.Contains(output, "Merged #2 into #1") {
+		t.Fatalf("unexpected output: %s", output)
+	}
+	if len(merges) != 1 || !strings.Contains(merges[0], `"base_contact_id":1`) || !strings.Contains(merges[0], `"mergee_contact_id":2`) {
+		t.Fatalf("unexpected merges: %v", merges)
+	}
+
+	data, err := os.ReadFile(undoPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"keep_id":1`, `"id":2`, `"plan":"pro"`, `"labels":["vip"]`, `"reasons":["email","name"]`} {
+		if !strings.Contains(string(data), want) {
+			t.Fatalf("undo log missing %s: %s", want, data)
+		}
+	}
+}
+
+func TestContactsDedupeApplyDefaultUndoLog(t *testing.T) {
+	setupTestEnvWithHandler(t, newRouteHandler().
+		On("GET", "/api/v1/accounts/1/contacts", jsonResponse(200, dedupeContactsPayload)).
+		On("GET", "/api/v1/accounts/1/contacts/2", jsonResponse(200, `{"payload": {"id": 2, "name": "jane doe"}}`)).
+		On("GET", "/api/v1/accounts/1/contacts/2/labels", jsonResponse(200, `{"labels": []}`)).
+		On("POST", "/api/v1/accounts/1/actions/contact_merge", jsonResponse(200, `{"id": 1, "name": "Jane Doe"}`)))
+	configDir := t.TempDir()
+	t.Setenv("CW_CONFIG_DIR", configDir)
+	t.Setenv("CHATWOOT_CACHE_DIR", t.TempDir())
+
+	_ = captureStdout(t, func() {
+		if err := Execute(context.Background(), []string{"contacts", "dedupe", "--apply", "--min-score", "90", "--force"}); err != nil {
+			t.Fatalf("apply failed: %v", err)
+		}
+	})
+	data, err := os.ReadFile(filepath.Join(configDir, "contacts-dedupe-undo.jsonl"))
+	if err != nil {
+		t.Fatalf("undo log not in config dir: %v", err)
+	}
+	if !strings.Contains(string(data), `"keep_id":1`) {
+		t.Fatalf("unexpected undo log: %s", data)
+	}
+}
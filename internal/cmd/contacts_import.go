@@ -0,0 +1,341 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+	"github.com/chatwoot/chatwoot-cli/internal/contactimport"
+	"github.com/chatwoot/chatwoot-cli/internal/dryrun"
+)
+
+// defaultImportCheckpointPath keys the checkpoint by account and input hash,
+// so re-running the same import resumes without extra flags.
+func defaultImportCheckpointPath(accountID int, sum string) string {
+	return filepath.Join(resolveCacheDir(), "contacts-import", fmt.Sprintf("%d-%s.jsonl", accountID, sum[:16]))
+}
+
+func defaultImportErrorReportPath(input string) string {
+	return strings.TrimSuffix(input, filepath.Ext(input)) + ".errors.csv"
+}
+
+// importSummary is the outcome of an import run.
+type importSummary struct {
+	Input       string                   `json:"input"`
+	DryRun      bool                     `json:"dry_run,omitempty"`
+	Rows        int                      `json:"rows"`
+	Created     int                      `json:"created"`
+	Updated     int                      `json:"updated"`
+	Skipped     int                      `json:"skipped"`
+	Failed      int                      `json:"failed"`
+	Unmapped    []string                 `json:"unmapped_columns,omitempty"`
+	Checkpoint  string                   `json:"checkpoint,omitempty"`
+	ErrorReport string                   `json:"error_report,omitempty"`
+	Errors      []contactimport.RowError `json:"errors,omitempty"`
+}
+
+func newContactsImportCmd() *cobra.Command {
+	var (
+		mappingPath    string
+		format         string
+		match          []string
+		countryCode    string
+		checkpointPath string
+		reportPath     string
+		concurrency    int
+		progress       bool
+		noProgress     bool
+	)
+
+	cmd := &cobra.Command{
+		Use:     "import <file>",
+		Aliases: []string{"imp"},
+		Short:   "Create or update contacts from a CSV or JSONL file",
+		Long: strings.TrimSpace(`
+Upsert contacts from a CSV file (with a header row) or a JSONL file (one JSON
+object per line). Each row is matched against existing contacts by email,
+phone number or identifier (see --match): a match is updated, otherwise a new
+contact is created. A row matching more than one contact fails.
+
+Without --mapping, columns named like contact fields are imported (name,
+email, phone/phone_number, identifier, custom_attributes.<key>,
+additional_attributes.<key>) and other columns are ignored. A mapping file
+(YAML or JSON) assigns columns explicitly; an empty target ignores a column,
+and attribute targets may end in :number or :bool:
+
+  columns:
+    Full Name: name
+    E-mail: email
+    Mobile: phone_number
+    CRM ID: identifier
+    Plan: custom_attributes.plan
+    Seats: custom_attributes.seats:number
+    Company: additional_attributes.company_name
+    Notes: ""
+
+Empty cells are skipped, so updates never clear existing values. Phone
+numbers are sent in E.164 form; numbers without a country code need
+--country-code.
+
+Imported rows are appended to a checkpoint file (by default in the cache
+directory, keyed by account and file contents), so re-running the same import
+skips them and retries only what failed. Failed rows are written to a CSV
+error report with the original columns, which can be fixed and imported again.
+`),
+		Example: strings.TrimSpace(`
+  # Import a CRM export, treating national numbers as US
+  cw contacts import contacts.csv --country-code 1
+
+  # Explicit column mapping, matching on identifier only
+  cw contacts import export.csv --mapping crm-mapping.yaml --match identifier
+
+  # Preview creates and updates without changing anything
+  cw contacts import contacts.jsonl --dry-run
+
+  # Re-import the fixed error report
+  cw contacts import contacts.errors.csv --mapping crm-mapping.yaml
+`),
+		Args: cobra.ExactArgs(1),
+		RunE: RunE(func(cmd *cobra.Command, args []string) error {
+			input := args[0]
+			if format == "" {
+				format = contactimport.DetectFormat(input)
+			}
+			matchFields, err := contactimport.ParseMatch(match)
+			if err != nil {
+				return err
+			}
+			if len(matchFields) == 0 {
+				return fmt.Errorf("--match needs at least one of: %s", strings.Join(contactimport.MatchFields, ", "))
+			}
+
+			f, err := os.Open(input)
+			if err != nil {
+				return err
+			}
+			in, err := contactimport.Read(f, format)
+			_ = f.Close()
+			if err != nil {
+				return fmt.Errorf("%s: %w", input, err)
+			}
+
+			var (
+				mapping  *contactimport.Mapping
+				unmapped []string
+			)
+			if mappingPath != "" {
+				if mapping, err = contactimport.Load(mappingPath); err != nil {
+					return err
+				}
+			} else {
+				mapping, unmapped = contactimport.DefaultMapping(in.Columns)
+			}
+			if len(mapping.MappedColumns()) == 0 {
+				return fmt.Errorf("no columns map to contact fields; use --mapping")
+			}
+
+			client, err := getClient()
+			if err != nil {
+				return err
+			}
+			ctx := cmdContext(cmd)
+			dryRun := dryrun.IsEnabled(ctx)
+
+			summary := importSummary{Input: input, DryRun: dryRun, Rows: len(in.Records), Unmapped: unmapped}
+			if len(unmapped) > 0 && !isJSON(cmd) {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Ignoring unmapped columns: %s\n", strings.Join(unmapped, ", "))
+			}
+
+			var checkpoint *contactimport.Checkpoint
+			if !dryRun {
+				sum, err := contactimport.HashFile(input)
+				if err != nil {
+					return err
+				}
+				if checkpointPath == "" {
+					checkpointPath = defaultImportCheckpointPath(client.AccountID, sum)
+				}
+				if checkpoint, err = contactimport.OpenCheckpoint(checkpointPath, input, sum, client.AccountID); err != nil {
+					return err
+				}
+				defer func() { _ = checkpoint.Close() }()
+				summary.Checkpoint = checkpoint.Path()
+			}
+
+			var (
+				mu       sync.Mutex
+				rows     []contactimport.Row
+				failures []contactimport.RowError
+			)
+			fail := func(line int, err error) {
+				mu.Lock()
+				failures = append(failures, contactimport.RowError{Line: line, Error: err.Error()})
+				mu.Unlock()
+			}
+			opts := contactimport.Options{CountryCode: countryCode}
+			for _, rec := range in.Records {
+				if checkpoint != nil {
+					if _, done := checkpoint.Done(rec.Line); done {
+						summary.Skipped++
+						continue
+					}
+				}
+				row, err := mapping.Apply(rec, opts)
+				if err != nil {
+					fail(rec.Line, err)
+					continue
+				}
+				rows = append(rows, row)
+			}
+
+			batches := contactimport.Batches(rows)
+			ids := make([]int, len(batches))
+			for i := range batches {
+				ids[i] = i
+			}
+			var created, updated int
+			runBulkOperation(ctx, ids, int64(concurrency), bulkProgressEnabled(cmd, progress, noProgress) && !isJSON(cmd), cmd.ErrOrStderr(),
+				func(ctx context.Context, i int) (any, error) {
+					for _, row := range batches[i] {
+						action, id, err := upsertImportRow(ctx, client, row, matchFields, countryCode, dryRun)
+						if err == nil && checkpoint != nil {
+							if cpErr := checkpoint.Record(contactimport.CheckpointEntry{Line: row.Line, ContactID: id, Action: action}); cpErr != nil {
+								err = fmt.Errorf("imported contact #%d but could not write checkpoint: %w", id, cpErr)
+							}
+						}
+						if err != nil {
+							fail(row.Line, err)
+							continue
+						}
+						mu.Lock()
+						if action == contactimport.ActionCreated {
+							created++
+						} else {
+							updated++
+						}
+						mu.Unlock()
+					}
+					return nil, nil
+				},
+			)
+			summary.Created, summary.Updated, summary.Failed = created, updated, len(failures)
+
+			if len(failures) > 0 {
+				sort.Slice(failures, func(i, j int) bool { return failures[i].Line < failures[j].Line })
+				summary.Errors = failures
+				if reportPath == "" {
+					reportPath = defaultImportErrorReportPath(input)
+				}
+				if err := contactimport.WriteErrorReport(reportPath, in, failures); err != nil {
+					return err
+				}
+				summary.ErrorReport = reportPath
+			}
+
+			if isJSON(cmd) {
+				if err := printJSON(cmd, summary); err != nil {
+					return err
+				}
+			} else {
+				printImportSummary(cmd, summary)
+			}
+			if summary.Failed > 0 {
+				return fmt.Errorf("%d of %d rows failed", summary.Failed, summary.Rows-summary.Skipped)
+			}
+			return nil
+		}),
+	}
+
+	cmd.Flags().StringVar(&mappingPath, "mapping", "", "Column mapping file (YAML or JSON)")
+	flagAlias(cmd.Flags(), "mapping", "map")
+	cmd.Flags().StringVar(&format, "format", "", "Input format: csv or jsonl (default: from file extension, else csv)")
+	registerStaticCompletions(cmd, "format", []string{contactimport.FormatCSV, contactimport.FormatJSONL})
+	flagAlias(cmd.Flags(), "format", "fmt")
+	cmd.Flags().StringSliceVar(&match, "match", contactimport.MatchFields, "Fields that identify an existing contact")
+	flagAlias(cmd.Flags(), "match", "mf")
+	cmd.Flags().StringVar(&countryCode, "country-code", "", "Calling code for phone numbers without one (e.g. 1, 44)")
+	cmd.Flags().StringVar(&checkpointPath, "checkpoint", "", "Checkpoint file for resuming (default: in the cache directory)")
+	flagAlias(cmd.Flags(), "checkpoint", "ckp")
+	cmd.Flags().StringVar(&reportPath, "error-report", "", "CSV file for failed rows (default: <file>.errors.csv)")
+	flagAlias(cmd.Flags(), "error-report", "er")
+	cmd.Flags().IntVar(&concurrency, "concurrency", DefaultConcurrency, "Max concurrent operations")
+	flagAlias(cmd.Flags(), "concurrency", "cc")
+	cmd.Flags().BoolVar(&progress, "progress", true, "Show progress while running")
+	cmd.Flags().BoolVar(&noProgress, "no-progress", false, "Disable progress output")
+	flagAlias(cmd.Flags(), "progress", "prg")
+	flagAlias(cmd.Flags(), "no-progress", "npr")
+	registerCommandContract(cmd, true, true)
+
+	return cmd
+}
+
+// upsertImportRow updates the contact the row matches, or creates one. In
+// dry-run mode only the lookup runs.
+func upsertImportRow(ctx context.Context, client *api.Client, row contactimport.Row, matchFields []string, countryCode string, dryRun bool) (string, int, error) {
+	var existing *api.Contact
+	if payload := row.FilterPayload(matchFields); payload != nil {
+		list, err := client.Contacts().Filter(ctx, payload)
+		if err != nil {
+			return "", 0, fmt.Errorf("lookup: %w", err)
+		}
+		if existing, err = row.PickMatch(matchFields, list.Payload, countryCode); err != nil {
+			return "", 0, err
+		}
+	}
+
+	if existing != nil {
+		if !dryRun {
+			if _, err := client.Contacts().UpdateFromMap(ctx, existing.ID, row.Body); err != nil {
+				return "", 0, fmt.Errorf("update contact #%d: %w", existing.ID, err)
+			}
+		}
+		return contactimport.ActionUpdated, existing.ID, nil
+	}
+	if dryRun {
+		return contactimport.ActionCreated, 0, nil
+	}
+	contact, err := client.Contacts().CreateFromMap(ctx, row.Body)
+	if err != nil {
+		return "", 0, fmt.Errorf("create: %w", err)
+	}
+	return contactimport.ActionCreated, contact.ID, nil
+}
+
+func printImportSummary(cmd *cobra.Command, s importSummary) {
+	out := cmd.OutOrStdout()
+	verb := "Imported"
+	if s.DryRun {
+		verb = "Would import"
+	}
+	_, _ = fmt.Fprintf(out, "%s %d rows: %s created, %s updated", verb, s.Rows-s.Skipped-s.Failed,
+		green(fmt.Sprint(s.Created)), green(fmt.Sprint(s.Updated)))
+	if s.Skipped > 0 {
+		_, _ = fmt.Fprintf(out, ", %d already imported", s.Skipped)
+	}
+	if s.Failed > 0 {
+		_, _ = fmt.Fprintf(out, ", %s failed", red(fmt.Sprint(s.Failed)))
+	}
+	_, _ = fmt.Fprintln(out)
+
+	const shown = 10
+	for i, e := range s.Errors {
+		if i == shown {
+			_, _ = fmt.Fprintf(out, "  ... and %d more\n", len(s.Errors)-shown)
+			break
+		}
+		_, _ = fmt.Fprintf(out, "  line %d: %s\n", e.Line, e.Error)
+	}
+	if s.ErrorReport != "" {
+		_, _ = fmt.Fprintf(out, "Failed rows written to %s\n", s.ErrorReport)
+	}
+	if s.Checkpoint != "" && s.Failed > 0 {
+		_, _ = fmt.Fprintf(out, "Re-run the same command to retry them (checkpoint: %s)\n", s.Checkpoint)
+	}
+}
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestContactsImportUpsertsAndResumes(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		created []map[string]any
+		updated []map[string]any
+		nextID  = 100
+	)
+	record := func(list *[]map[string]any, r *http.Request) {
+		var body map[string]any
+		data, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(data, &body)
+		mu.Lock()
+		*list = append(*list, body)
+		mu.Unlock()
+	}
+	setupTestEnvWithHandler(t, newRouteHandler().
+		On("POST", "/api/v1/accounts/1/contacts/filter", func(w http.ResponseWriter, r *http.Request) {
+			data, _ := io.ReadAll(r.Body)
+			if strings.Contains(string(data), "jane@example.com") {
+				jsonResponse(200, `{"meta": {"count": 1}, "payload": [{"id": 7, "email": "Jane@Example.com"}]}`)(w, r)
+				return
+			}
+			jsonResponse(200, `{"meta": {"count": 0}, "payload": []}`)(w, r)
+		}).
+		On("PATCH", "/api/v1/accounts/1/contacts/7", func(w http.ResponseWriter, r *http.Request) {
+			record(&updated, r)
+			jsonResponse(200, `{"payload": {"id": 7}}`)(w, r)
+		}).
+		On("POST", "/api/v1/accounts/1/contacts", func(w http.ResponseWriter, r *http.Request) {
+			record(&created, r)
+			mu.Lock()
+			nextID++
+			id := nextID
+			mu.Unlock()
+			body, _ := json.Marshal(map[string]any{"payload": map[string]any{"contact": map[string]any{"id": id}}})
+			jsonResponse(200, string(body))(w, r)
+		}))
+
+	dir := t.TempDir()
+	input := filepath.Join(dir, "crm.csv")
+	if err := os.WriteFile(input, []byte("Name,Email,Phone,Plan\n"+
+		"Jane Doe,jane@example.com,,pro\n"+
+		"Bob Stone,bob@example.com,+15550000001,\n"+
+		"Broken,not-an-email,,\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	mapping := filepath.Join(dir, "mapping.yaml")
+	if err := os.WriteFile(mapping, []byte("columns:\n  Name: name\n  Email: email\n  Phone: phone_number\n  Plan: custom_attributes.plan\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	checkpoint := filepath.Join(dir, "checkpoint.jsonl")
+	args := []string{"contacts", "import", input, "--mapping", mapping, "--checkpoint", checkpoint, "--no-progress"}
+
+	output := captureStdout(t, func() {
+		err := Execute(context.Background(), args)
+		if err == nil || !strings.Contains(err.Error(), "1 of 3 rows failed") {
+			t.Errorf("expected row failure error, got %v", err)
+		}
+	})
+	if !strings.Contains(output, "Imported 2 rows") || !strings.Contains(output, "line 4: invalid email format") {
+		t.Fatalf("unexpected output: %s", output)
+	}
+	if len(updated) != 1 || len(created) != 1 {
+		t.Fatalf("expected 1 update and 1 create, got %v / %v", updated, created)
+	}
+	if attrs, _ := updated[0]["custom_attributes"].(map[string]any); attrs["plan"] != "pro" {
+		t.Fatalf("unexpected update body: %v", updated[0])
+	}
+	if created[0]["phone_number"] != "+15550000001" {
+		t.Fatalf("unexpected create body: %v", created[0])
+	}
+
+	report, err := os.ReadFile(filepath.Join(dir, "crm.errors.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(report), "line,error,Name,Email,Phone,Plan\n4,") {
+		t.Fatalf("unexpected error report: %s", report)
+	}
+
+	// The second run skips imported rows and only retries the failure.
+	output = captureStdout(t, func() {
+		_ = Execute(context.Background(), append(args, "-o", "json"))
+	})
+	var summary struct {
+		Rows    int `json:"rows"`
+		Created int `json:"created"`
+		Updated int `json:"updated"`
+		Skipped int `json:"skipped"`
+		Failed  int `json:"failed"`
+	}
+	if err := json.Unmarshal([]byte(output), &summary); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, output)
+	}
+	if summary.Rows != 3 || summary.Skipped != 2 || summary.Failed != 1 || summary.Created+summary.Updated != 0 {
+		t.Fatalf("unexpected resume summary: %+v", summary)
+	}
+	if len(updated) != 1 || len(created) != 1 {
+		t.Fatalf("resume repeated API writes: %v / %v", updated, created)
+	}
+}
+
+func TestContactsImportDryRun(t *testing.T) {
+	var writes int
+	setupTestEnvWithHandler(t, newRouteHandler().
+		On("POST", "/api/v1/accounts/1/contacts/filter", jsonResponse(200, `{"meta": {"count": 0}, "payload": []}`)).
+		On("POST", "/api/v1/accounts/1/contacts", func(w http.ResponseWriter, r *http.Request) {
+			writes++
+			jsonResponse(200, `{"payload": {"contact": {"id": 1}}}`)(w, r)
+		}))
+
+	input := filepath.Join(t.TempDir(), "contacts.jsonl")
+	if err := os.WriteFile(input, []byte(`{"name": "Ann", "email": "ann@example.com", "crm_score": 3}`+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	output := captureStdout(t, func() {
+		if err := Execute(context.Background(), []string{"contacts", "import", input, "--dry-run", "-o", "json"}); err != nil {
+			t.Errorf("dry run failed: %v", err)
+		}
+	})
+	if writes != 0 {
+		t.Fatalf("dry run created %d contacts", writes)
+	}
+	for _, want := range []string{`"dry_run": true`, `"created": 1`, `"crm_score"`} {
+		if !strings.Contains(output, want) {
+			t.Fatalf("missing %s in output: %s", want, output)
+		}
+	}
+}
@@ -1633,6 +1633,17 @@ func newConversationsLabelsAddCmd() *cobra.Command {
 				return fmt.Errorf("invalid labels: %w", err)
 			}
 
+			if ok, err := maybeDryRun(cmd, &dryrun.Preview{
+				Operation: "add labels to",
+				Resource:  "conversation",
+				Details: map[string]any{
+					"conversation_id": id,
+					"labels":          labels,
+				},
+			}); ok {
+				return err
+			}
+
 			client, err := getClient()
 			if err != nil {
 				return err
@@ -1661,6 +1672,7 @@ func newConversationsLabelsAddCmd() *cobra.Command {
 
 	cmd.Flags().StringVar(&labelsStr, "labels", "", "Labels (CSV, whitespace, JSON array; or @- / @path) (required)")
 	flagAlias(cmd.Flags(), "labels", "lb")
+	registerCommandContract(cmd, true, true)
 
 	return cmd
 }
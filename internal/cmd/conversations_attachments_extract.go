@@ -26,6 +26,11 @@ func newConversationsAttachmentsExtractCmd() *cobra.Command {
 		Short: "Extract text from document attachments in a conversation",
 		Long: `Download supported document attachments and extract bounded text for agent analysis.
 
+Supported formats: PDF, DOCX, XLSX, ODT, ODS, RTF, HTML, plain text and
+forwarded emails (.eml), including the documents attached to them. PDFs are
+read in pure Go; pdftotext is used when installed and the built-in reader
+finds no text.
+
 This command is separate from 'ctx' so document extraction stays explicit and token-bounded.`,
 		Example: strings.TrimSpace(`
   # Extract up to 3 document attachments with safe defaults
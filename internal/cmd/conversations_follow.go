@@ -24,6 +24,10 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// followReconnectBackoff is the first wait before re-dialing a dropped
+// socket; it doubles per failed attempt. A variable so tests can shorten it.
+var followReconnectBackoff = 2 * time.Second
+
 func newConversationsFollowCmd() *cobra.Command {
 	var (
 		incomingOnly   bool
@@ -66,6 +70,12 @@ push notifications. No watch server or webhook setup required.
 
 By default, follows a single conversation by ID. Use --all to follow
 all conversations on the account.
+
+When the connection drops, follow reconnects and first backfills the messages
+created while it was down (via the conversations and messages APIs), so each
+message is emitted once. A message whose output or --exec handler failed is
+replayed by the next connection. With --cursor-file the last message written
+in every conversation is saved, and a restart backfills from there too.
 `),
 		Args: cobra.MaximumNArgs(1),
 		RunE: RunE(func(cmd *cobra.Command, args []string) error {
@@ -184,6 +194,12 @@ all conversations on the account.
 			if sinceID > 0 {
 				lastSeenID = sinceID
 			}
+			var (
+				cursorMarks map[int]int
+				// gapStart opens the window to backfill when the next
+				// connection comes up; zero means nothing was missed.
+				gapStart time.Time
+			)
 			if cursorFile != "" && sinceID <= 0 {
 				cur, err := loadFollowCursor(cursorFile)
 				if err != nil {
@@ -192,8 +208,16 @@ all conversations on the account.
 				// Ignore cursors from other accounts.
 				if cur.LastSeenMessageID > 0 && (cur.AccountID == 0 || cur.AccountID == client.AccountID) && (cur.BaseURL == "" || cur.BaseURL == client.BaseURL) {
 					lastSeenID = max(lastSeenID, cur.LastSeenMessageID)
+					cursorMarks = cur.Conversations
+					// Replay what arrived since the previous run stopped.
+					if t := cur.updatedAt(); !t.IsZero() {
+						gapStart = t.Add(-followBackfillSlack)
+					}
 				}
 			}
+			// Conversations are resumed from their own marks only: a message
+			// missed in one conversation can be older than one seen in another.
+			marks := newFollowMarks(max(sinceID, 0), cursorMarks)
 
 			var cw *followCursorWriter
 			if cursorFile != "" {
@@ -201,6 +225,9 @@ all conversations on the account.
 				if err != nil {
 					return err
 				}
+				for id, last := range cursorMarks {
+					w.Conversations[id] = last
+				}
 				cw = w
 				defer func() { _ = cw.Flush() }()
 			}
@@ -229,7 +256,7 @@ all conversations on the account.
 						if minCreatedAt > 0 && m.CreatedAt < minCreatedAt {
 							continue
 						}
-						if m.ID <= lastSeenID {
+						if m.ID <= marks.Last(convID) {
 							continue
 						}
 						if filters.ExcludePrivate && m.Private {
@@ -244,9 +271,9 @@ all conversations on the account.
 						if err := printFollowMessage(cmd, m, "history"); err != nil {
 							return err
 						}
-						lastSeenID = m.ID
+						marks.Mark(convID, m.ID)
 						if cw != nil {
-							cw.Update(lastSeenID)
+							cw.Mark(convID, m.ID)
 						}
 					}
 				}
@@ -278,48 +305,52 @@ all conversations on the account.
 			}
 
 			// Reconnection loop with exponential backoff.
-			backoff := 2 * time.Second
+			backoff := followReconnectBackoff
 			maxBackoff := 30 * time.Second
 			resetThreshold := 60 * time.Second
 
 			for {
 				connectStart := time.Now()
-				onLastSeen := func(id int) {
-					if cw != nil {
-						cw.Update(id)
-					}
-				}
+				live := false
 				wsCfg := followWebSocketConfig{
 					CableURL:       cableURL,
 					ChannelID:      channelID,
 					ConvID:         convID,
 					IncomingOnly:   incomingOnly,
-					LastSeenID:     &lastSeenID,
+					Marks:          marks,
 					AllowedEvents:  allowedEvents,
 					Debounce:       debounce,
 					IncludeRaw:     includeRaw,
 					ContextEnabled: contextEnabled,
 					ContextMsgs:    contextMsgs,
 					MinCreatedAt:   minCreatedAt,
-					OnLastSeen:     onLastSeen,
+					OnMark:         cw.Mark,
 					Filters:        filters,
 					QueueSize:      queueSize,
 					DropWhenFull:   dropWhenFull,
 					MaxBatch:       maxBatch,
 					SnapshotClient: snapshotClient,
 					Hook:           hook,
+					Backfill:       &followBackfill{Client: snapshotClient, Since: gapStart},
+					OnLive:         func() { live = true },
 				}
 				err := followViaWebSocket(ctx, cmd, wsCfg)
 				if ctx.Err() != nil {
 					return nil
 				}
+				// Events broadcast from now until the next connection is live
+				// are lost with the socket. If this connection never got that
+				// far, its window is still open.
+				if live {
+					gapStart = time.Now().Add(-followBackfillSlack)
+				}
 				connectionDuration := time.Since(connectStart)
 				if cw != nil {
 					_ = cw.Flush()
 				}
 				// Reset backoff if the connection was stable for a while.
 				if connectionDuration > resetThreshold {
-					backoff = 2 * time.Second
+					backoff = followReconnectBackoff
 				}
 				if !isJSON(cmd) {
 					_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "disconnected: %v, reconnecting in %s...\n", err, backoff)
@@ -343,7 +374,7 @@ all conversations on the account.
 	cmd.Flags().BoolVar(&includeRaw, "raw", false, "Include raw WebSocket payload (JSON/agent modes only)")
 	cmd.Flags().BoolVar(&withContext, "context", false, "Emit a conversation snapshot on the first event per conversation (default true in agent mode)")
 	cmd.Flags().IntVar(&contextMsgs, "context-messages", 10, "Number of recent messages to include in conversation snapshots")
-	cmd.Flags().StringVar(&cursorFile, "cursor-file", "", "Persist last seen message id to a file for resume/restart")
+	cmd.Flags().StringVar(&cursorFile, "cursor-file", "", "Persist last seen message ids to a file; restarts backfill missed messages from it")
 	cmd.Flags().IntVar(&sinceID, "since-id", 0, "Skip messages with id <= this value (useful for resume)")
 	cmd.Flags().StringVar(&sinceTime, "since-time", "", "Skip messages created before this time (RFC3339, unix seconds, or duration like 24h)")
 	cmd.Flags().IntVar(&filterInbox, "inbox", 0, "Only show events for conversations in this inbox ID")
@@ -421,6 +452,36 @@ func (c followAPISnapshotClient) ListLabels(ctx context.Context, conversationID
 	return c.client.Conversations().Labels(ctx, conversationID)
 }
 
+func (c followAPISnapshotClient) ListConversations(ctx context.Context, params api.ListConversationsParams) (*api.ConversationList, error) {
+	return c.client.Conversations().List(ctx, params)
+}
+
+func (c followAPISnapshotClient) ListMessagesBefore(ctx context.Context, conversationID, before int) ([]api.Message, error) {
+	return c.client.Messages().ListBefore(ctx, conversationID, before)
+}
+
+type followBackfillClient interface {
+	ListConversations(ctx context.Context, params api.ListConversationsParams) (*api.ConversationList, error)
+	ListMessagesBefore(ctx context.Context, conversationID, before int) ([]api.Message, error)
+}
+
+const (
+	// followBackfillSlack widens the backfill window to cover the ping
+	// timeout before a dead socket is noticed and clock skew against the
+	// server's last_activity_at.
+	followBackfillSlack = time.Minute
+	// followBackfillMaxPages bounds how many pages of conversations, and of
+	// messages per conversation, one backfill reads.
+	followBackfillMaxPages = 10
+)
+
+// followBackfill describes the messages a connection replays before it
+// handles live events: those created while no socket was listening.
+type followBackfill struct {
+	Client followBackfillClient
+	Since  time.Time
+}
+
 // followWebSocketConfig holds the parameters for followViaWebSocket,
 // extracted from the original 20-parameter function signature.
 type followWebSocketConfig struct {
@@ -428,20 +489,24 @@ type followWebSocketConfig struct {
 	ChannelID      actioncable.ChannelID
 	ConvID         int
 	IncomingOnly   bool
-	LastSeenID     *int
+	Marks          *followMarks
 	AllowedEvents  map[string]struct{}
 	Debounce       time.Duration
 	IncludeRaw     bool
 	ContextEnabled bool
 	ContextMsgs    int
 	MinCreatedAt   int64
-	OnLastSeen     func(int)
+	OnMark         func(conversationID, messageID int)
 	Filters        followFilters
 	QueueSize      int
 	DropWhenFull   bool
 	MaxBatch       int
 	SnapshotClient followSnapshotClient
 	Hook           *followExecHook
+	// Backfill, if set, is replayed once subscribed, before live events.
+	Backfill *followBackfill
+	// OnLive is called once the backfill is done and live events flow.
+	OnLive func()
 }
 
 // followViaWebSocket connects to ActionCable, subscribes, and processes events
@@ -467,6 +532,25 @@ func followViaWebSocket(ctx context.Context, cmd *cobra.Command, cfg followWebSo
 	emitter := newFollowEmitter(cmd, cfg.QueueSize, cfg.DropWhenFull)
 	defer func() { _ = emitter.CloseAndDrain() }()
 
+	// seen deduplicates messages as they are queued. cfg.Marks trails it
+	// until the emitter has written them, so the next connection replays
+	// whatever failed or was still queued when this one ended.
+	var seen *followMarks
+	if cfg.Marks != nil {
+		seen = cfg.Marks.clone()
+	}
+	// emitted runs on the emitter after msgs were written.
+	emitted := func(msgs ...api.Message) {
+		for _, m := range msgs {
+			if cfg.Marks != nil {
+				cfg.Marks.Mark(m.ConversationID, m.ID)
+			}
+			if cfg.OnMark != nil {
+				cfg.OnMark(m.ConversationID, m.ID)
+			}
+		}
+	}
+
 	dropTicker := time.NewTicker(5 * time.Second)
 	defer dropTicker.Stop()
 
@@ -551,7 +635,13 @@ func followViaWebSocket(ctx context.Context, cmd *cobra.Command, cfg followWebSo
 			return err
 		}
 		return emitter.Emit(func() error {
-			return printFollowMessageBatch(cmd, cfg.Hook, msgs, "ws", cfg.IncludeRaw)
+			if err := printFollowMessageBatch(cmd, cfg.Hook, msgs, "ws", cfg.IncludeRaw); err != nil {
+				return err
+			}
+			for _, m := range msgs {
+				emitted(m.msg)
+			}
+			return nil
 		})
 	}
 
@@ -570,6 +660,90 @@ func followViaWebSocket(ctx context.Context, cmd *cobra.Command, cfg followWebSo
 		return nil
 	}
 
+	// handleMessage filters, deduplicates and emits one message, from the
+	// live stream or a backfill.
+	handleMessage := func(event string, msg api.Message, source string, rawEnvelope json.RawMessage) error {
+		// Filter by conversation ID (WebSocket sends all account events).
+		if cfg.ConvID != 0 && msg.ConversationID != cfg.ConvID {
+			return nil
+		}
+
+		// Filter by created_at threshold (useful for resume).
+		if cfg.MinCreatedAt > 0 && msg.CreatedAt < cfg.MinCreatedAt {
+			return nil
+		}
+
+		// Dedup by message ID per conversation.
+		if seen != nil && !seen.Mark(msg.ConversationID, msg.ID) {
+			return nil
+		}
+
+		// Filter by message type if --incoming-only.
+		if cfg.IncomingOnly && msg.MessageType != api.MessageTypeIncoming {
+			return nil
+		}
+		if cfg.Filters.ExcludePrivate && msg.Private {
+			return nil
+		}
+
+		if cfg.Filters.metaFiltersEnabled() {
+			meta, _ := ensureMeta(msg.ConversationID)
+			if meta == nil || !cfg.Filters.matchMeta(meta) {
+				return nil
+			}
+		}
+
+		// Debounce (batch) rapid live messages per conversation.
+		if cfg.Debounce <= 0 || event != "message.created" || source != "ws" {
+			if err := maybeSnapshot(msg.ConversationID); err != nil {
+				return err
+			}
+			return emitter.Emit(func() error {
+				if err := printFollowMessageWithRaw(cmd, cfg.Hook, event, msg, source, rawEnvelope, cfg.IncludeRaw); err != nil {
+					return err
+				}
+				emitted(msg)
+				return nil
+			})
+		}
+
+		id := msg.ConversationID
+		buf := debounced[id]
+		if buf == nil {
+			buf = &debounceBuf{}
+			debounced[id] = buf
+		}
+		buf.messages = append(buf.messages, followMsg{msg: msg, raw: rawEnvelope})
+		if cfg.MaxBatch > 0 && len(buf.messages) >= cfg.MaxBatch {
+			return flushConv(id)
+		}
+		if buf.timer == nil {
+			// Flush after debounce duration from the first message in the batch.
+			buf.timer = time.AfterFunc(cfg.Debounce, func() {
+				select {
+				case flushCh <- id:
+				case <-done:
+				}
+			})
+		}
+		return nil
+	}
+
+	if cfg.Backfill != nil && !cfg.Backfill.Since.IsZero() {
+		missed, err := followBackfillMessages(ctx, cmd, *cfg.Backfill, cfg.ConvID, seen)
+		if err != nil {
+			return fmt.Errorf("backfill: %w", err)
+		}
+		for _, msg := range missed {
+			if err := handleMessage("message.created", msg, "backfill", nil); err != nil {
+				return err
+			}
+		}
+	}
+	if cfg.OnLive != nil {
+		cfg.OnLive()
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -577,6 +751,8 @@ func followViaWebSocket(ctx context.Context, cmd *cobra.Command, cfg followWebSo
 			return nil
 		case <-dropTicker.C:
 			emitter.MaybeReportDrops()
+		case <-emitter.Failed():
+			return emitter.Err()
 		case id := <-flushCh:
 			if err := flushConv(id); err != nil {
 				return err
@@ -618,77 +794,8 @@ func followViaWebSocket(ctx context.Context, cmd *cobra.Command, cfg followWebSo
 				if err := json.Unmarshal(wsEvent.Data, &msg); err != nil {
 					continue
 				}
-
-				// Filter by conversation ID (WebSocket sends all account events).
-				if cfg.ConvID != 0 && msg.ConversationID != cfg.ConvID {
-					continue
-				}
-
-				// Filter by created_at threshold (useful for resume).
-				if cfg.MinCreatedAt > 0 && msg.CreatedAt < cfg.MinCreatedAt {
-					continue
-				}
-
-				// Dedup by message ID.
-				if cfg.LastSeenID != nil && msg.ID <= *cfg.LastSeenID {
-					continue
-				}
-				if cfg.LastSeenID != nil {
-					*cfg.LastSeenID = msg.ID
-					if cfg.OnLastSeen != nil {
-						cfg.OnLastSeen(*cfg.LastSeenID)
-					}
-				}
-
-				// Filter by message type if --incoming-only.
-				if cfg.IncomingOnly && msg.MessageType != api.MessageTypeIncoming {
-					continue
-				}
-				if cfg.Filters.ExcludePrivate && msg.Private {
-					continue
-				}
-
-				if cfg.Filters.metaFiltersEnabled() {
-					meta, _ := ensureMeta(msg.ConversationID)
-					if meta == nil || !cfg.Filters.matchMeta(meta) {
-						continue
-					}
-				}
-
-				// Debounce (batch) rapid messages per conversation.
-				if cfg.Debounce <= 0 || wsEvent.Event != "message.created" {
-					if err := maybeSnapshot(msg.ConversationID); err != nil {
-						return err
-					}
-					if err := emitter.Emit(func() error {
-						return printFollowMessageWithRaw(cmd, cfg.Hook, wsEvent.Event, msg, "ws", rawEnvelope, cfg.IncludeRaw)
-					}); err != nil {
-						return err
-					}
-					continue
-				}
-
-				id := msg.ConversationID
-				buf := debounced[id]
-				if buf == nil {
-					buf = &debounceBuf{}
-					debounced[id] = buf
-				}
-				buf.messages = append(buf.messages, followMsg{msg: msg, raw: rawEnvelope})
-				if cfg.MaxBatch > 0 && len(buf.messages) >= cfg.MaxBatch {
-					if err := flushConv(id); err != nil {
-						return err
-					}
-					continue
-				}
-				if buf.timer == nil {
-					// Flush after debounce duration from the first message in the batch.
-					buf.timer = time.AfterFunc(cfg.Debounce, func() {
-						select {
-						case flushCh <- id:
-						case <-done:
-						}
-					})
+				if err := handleMessage(wsEvent.Event, msg, "ws", rawEnvelope); err != nil {
+					return err
 				}
 				continue
 			}
@@ -730,6 +837,100 @@ func followViaWebSocket(ctx context.Context, cmd *cobra.Command, cfg followWebSo
 	// unreachable
 }
 
+// followBackfillMessages returns the messages newer than their
+// conversation's mark in the conversations active since b.Since, oldest
+// first. With convID 0 the conversations come from the list endpoint,
+// most recently active first; otherwise only convID is read.
+func followBackfillMessages(ctx context.Context, cmd *cobra.Command, b followBackfill, convID int, marks *followMarks) ([]api.Message, error) {
+	if marks == nil {
+		marks = newFollowMarks(0, nil)
+	}
+	ids := []int{convID}
+	if convID == 0 {
+		var err error
+		if ids, err = followBackfillConversations(ctx, cmd, b); err != nil {
+			return nil, err
+		}
+	}
+
+	// A conversation without a mark of its own, never written or pruned
+	// from the cursor, has only the window to go by.
+	var missed []api.Message
+	for _, id := range ids {
+		after := marks.Last(id)
+		_, known := marks.Conversations[id]
+		byTime := !known || after == 0
+
+		before := 0
+		for page := 0; ; page++ {
+			if page >= followBackfillMaxPages {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "backfill: conversation %d has more than %d pages of missed messages; older ones are skipped\n", id, followBackfillMaxPages)
+				break
+			}
+			msgs, err := b.Client.ListMessagesBefore(ctx, id, before)
+			if err != nil {
+				return nil, fmt.Errorf("list messages of conversation %d: %w", id, err)
+			}
+			if len(msgs) == 0 {
+				break
+			}
+			reached := false
+			oldest := msgs[0].ID
+			for _, m := range msgs {
+				oldest = min(oldest, m.ID)
+				if m.ID <= after || (byTime && m.CreatedAt < b.Since.Unix()) {
+					reached = true
+					continue
+				}
+				if m.ConversationID == 0 {
+					m.ConversationID = id
+				}
+				missed = append(missed, m)
+			}
+			if reached || oldest == before {
+				break
+			}
+			before = oldest
+		}
+	}
+	sort.SliceStable(missed, func(i, j int) bool { return missed[i].ID < missed[j].ID })
+	return missed, nil
+}
+
+// followBackfillConversations lists the IDs of conversations with activity
+// since b.Since.
+func followBackfillConversations(ctx context.Context, cmd *cobra.Command, b followBackfill) ([]int, error) {
+	var ids []int
+	seen := make(map[int]bool)
+	since := b.Since.Unix()
+	for page := 1; page <= followBackfillMaxPages; page++ {
+		list, err := b.Client.ListConversations(ctx, api.ListConversationsParams{
+			Status: "all",
+			SortBy: "last_activity_at_desc",
+			Page:   page,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("list conversations: %w", err)
+		}
+		for _, conv := range list.Data.Payload {
+			if conv.LastActivityAt < since {
+				return ids, nil
+			}
+			// Activity while paging shifts conversations onto the next page.
+			if !seen[conv.ID] {
+				seen[conv.ID] = true
+				ids = append(ids, conv.ID)
+			}
+		}
+		totalPages := int(list.Data.Meta.TotalPages)
+		if len(list.Data.Payload) == 0 || (totalPages > 0 && page >= totalPages) {
+			return ids, nil
+		}
+	}
+	_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "backfill: more than %d pages of active conversations; less recently active ones are skipped\n", followBackfillMaxPages)
+	return ids, nil
+}
+
 // buildCableURL converts a Chatwoot base URL to its ActionCable WebSocket URL.
 func buildCableURL(baseURL string) string {
 	u, err := url.Parse(baseURL)
@@ -999,6 +1200,8 @@ type followEmitter struct {
 	queue        chan func() error
 	dropWhenFull bool
 	done         chan struct{}
+	// failed is closed when writeErr is set.
+	failed chan struct{}
 
 	mu       sync.Mutex
 	writeErr error
@@ -1013,6 +1216,7 @@ func newFollowEmitter(cmd *cobra.Command, queueSize int, dropWhenFull bool) *fol
 		cmd:          cmd,
 		dropWhenFull: dropWhenFull,
 		done:         make(chan struct{}),
+		failed:       make(chan struct{}),
 	}
 	if queueSize == 0 {
 		close(e.done)
@@ -1026,11 +1230,7 @@ func newFollowEmitter(cmd *cobra.Command, queueSize int, dropWhenFull bool) *fol
 				continue
 			}
 			if err := fn(); err != nil {
-				e.mu.Lock()
-				if e.writeErr == nil {
-					e.writeErr = err
-				}
-				e.mu.Unlock()
+				e.fail(err)
 				// Drain remaining items without executing to avoid blocking producers.
 				for range e.queue {
 				}
@@ -1061,11 +1261,7 @@ func (e *followEmitter) Emit(fn func() error) error {
 			return nil
 		}
 		if err := fn(); err != nil {
-			e.mu.Lock()
-			if e.writeErr == nil {
-				e.writeErr = err
-			}
-			e.mu.Unlock()
+			e.fail(err)
 			return err
 		}
 		return nil
@@ -1097,6 +1293,33 @@ func (e *followEmitter) Emit(fn func() error) error {
 	}
 }
 
+func (e *followEmitter) fail(err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.writeErr == nil {
+		e.writeErr = err
+		close(e.failed)
+	}
+}
+
+// Failed returns a channel that is closed once an emitted function fails.
+func (e *followEmitter) Failed() <-chan struct{} {
+	if e == nil {
+		return nil
+	}
+	return e.failed
+}
+
+// Err returns the first error an emitted function returned.
+func (e *followEmitter) Err() error {
+	if e == nil {
+		return nil
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.writeErr
+}
+
 func (e *followEmitter) MaybeReportDrops() {
 	if e == nil || e.cmd == nil || !e.dropWhenFull {
 		return
@@ -1389,12 +1612,68 @@ func anyToInt(v any) int {
 	}
 }
 
+// followMarks tracks the highest message ID handled in each conversation, so
+// messages replayed by a backfill and the live stream are emitted once even
+// when they arrive out of ID order across conversations. IDs at or below
+// Floor (from --since-id) count as handled everywhere.
+type followMarks struct {
+	Floor         int
+	Conversations map[int]int
+}
+
+func newFollowMarks(floor int, conversations map[int]int) *followMarks {
+	m := &followMarks{Floor: floor, Conversations: make(map[int]int, len(conversations))}
+	for id, last := range conversations {
+		m.Conversations[id] = last
+	}
+	return m
+}
+
+func (m *followMarks) clone() *followMarks {
+	return newFollowMarks(m.Floor, m.Conversations)
+}
+
+// Last returns the highest message ID handled in a conversation.
+func (m *followMarks) Last(conversationID int) int {
+	return max(m.Floor, m.Conversations[conversationID])
+}
+
+// Mark records messageID as handled in conversationID. It reports false if
+// the message was handled before.
+func (m *followMarks) Mark(conversationID, messageID int) bool {
+	if messageID <= m.Last(conversationID) {
+		return false
+	}
+	m.Conversations[conversationID] = messageID
+	return true
+}
+
+// followCursorMaxConversations caps the per-conversation marks kept in the
+// cursor file; the conversations with the oldest marks are dropped first.
+const followCursorMaxConversations = 1000
+
 type followCursor struct {
 	Version           int    `json:"version"`
 	BaseURL           string `json:"base_url,omitempty"`
 	AccountID         int    `json:"account_id,omitempty"`
 	LastSeenMessageID int    `json:"last_seen_message_id"`
-	UpdatedAt         string `json:"updated_at,omitempty"`
+	// Conversations maps conversation IDs to the last message ID seen in
+	// each, so a restart can backfill every conversation from its own mark.
+	Conversations map[int]int `json:"conversations,omitempty"`
+	// Unhandled lists message IDs at or below LastSeenMessageID whose
+	// handler failed, so a restart still processes them.
+	Unhandled []int  `json:"unhandled_message_ids,omitempty"`
+	UpdatedAt string `json:"updated_at,omitempty"`
+}
+
+// updatedAt parses UpdatedAt, returning the zero time if it is missing or
+// malformed.
+func (c followCursor) updatedAt() time.Time {
+	t, err := time.Parse(time.RFC3339Nano, c.UpdatedAt)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
 }
 
 func loadFollowCursor(path string) (followCursor, error) {
@@ -1457,30 +1736,72 @@ type followCursorWriter struct {
 	AccountID   int
 	MinInterval time.Duration
 
-	LastSeenID  int
-	LastFlushed int
-	LastFlushAt time.Time
+	LastSeenID    int
+	Conversations map[int]int
+	Unhandled     map[int]bool
+	LastFlushed   int
+	LastFlushAt   time.Time
+	dirty         bool
 }
 
 func newFollowCursorWriter(path, baseURL string, accountID int, initialLastSeen int, minInterval time.Duration) (*followCursorWriter, error) {
 	w := &followCursorWriter{
-		Path:        path,
-		BaseURL:     baseURL,
-		AccountID:   accountID,
-		MinInterval: minInterval,
-		LastSeenID:  initialLastSeen,
+		Path:          path,
+		BaseURL:       baseURL,
+		AccountID:     accountID,
+		MinInterval:   minInterval,
+		LastSeenID:    initialLastSeen,
+		Conversations: make(map[int]int),
+		Unhandled:     make(map[int]bool),
 	}
 	return w, nil
 }
 
 func (w *followCursorWriter) Update(lastSeenID int) {
+	w.Mark(0, lastSeenID)
+}
+
+// Mark records messageID as seen in conversationID (0 when the message's
+// conversation is unknown) and flushes unless the last flush was less than
+// MinInterval ago.
+func (w *followCursorWriter) Mark(conversationID, messageID int) {
 	if w == nil || w.Path == "" {
 		return
 	}
-	if lastSeenID <= w.LastSeenID {
+	changed := false
+	if messageID > w.LastSeenID {
+		w.LastSeenID = messageID
+		changed = true
+	}
+	if conversationID > 0 && messageID > w.Conversations[conversationID] {
+		w.Conversations[conversationID] = messageID
+		changed = true
+	}
+	if !changed {
+		return
+	}
+	w.changed()
+}
+
+// SetUnhandled records whether messageID still needs handling. Unhandled IDs
+// are saved with the cursor so a restart does not skip them even when
+// LastSeenID has moved past them.
+func (w *followCursorWriter) SetUnhandled(messageID int, unhandled bool) {
+	if w == nil || w.Path == "" || w.Unhandled[messageID] == unhandled {
 		return
 	}
-	w.LastSeenID = lastSeenID
+	if unhandled {
+		w.Unhandled[messageID] = true
+	} else {
+		delete(w.Unhandled, messageID)
+	}
+	w.changed()
+}
+
+// changed marks the cursor dirty and flushes unless the last flush was less
+// than MinInterval ago.
+func (w *followCursorWriter) changed() {
+	w.dirty = true
 	if w.MinInterval <= 0 || w.LastFlushAt.IsZero() || time.Since(w.LastFlushAt) >= w.MinInterval {
 		_ = w.Flush()
 	}
@@ -1490,22 +1811,46 @@ func (w *followCursorWriter) Flush() error {
 	if w == nil || w.Path == "" {
 		return nil
 	}
-	if w.LastSeenID <= 0 || w.LastSeenID == w.LastFlushed {
+	if w.LastSeenID <= 0 || (w.LastSeenID == w.LastFlushed && !w.dirty) {
 		return nil
 	}
+	pruneFollowMarks(w.Conversations, followCursorMaxConversations)
 	cur := followCursor{
 		BaseURL:           w.BaseURL,
 		AccountID:         w.AccountID,
 		LastSeenMessageID: w.LastSeenID,
+		Conversations:     w.Conversations,
+	}
+	for id := range w.Unhandled {
+		cur.Unhandled = append(cur.Unhandled, id)
 	}
+	sort.Ints(cur.Unhandled)
 	if err := saveFollowCursor(w.Path, cur); err != nil {
 		return err
 	}
 	w.LastFlushed = w.LastSeenID
 	w.LastFlushAt = time.Now()
+	w.dirty = false
 	return nil
 }
 
+// pruneFollowMarks drops the conversations with the lowest marks until at
+// most limit remain. A restart backfills them by time, from when the cursor
+// was last saved.
+func pruneFollowMarks(marks map[int]int, limit int) {
+	if len(marks) <= limit {
+		return
+	}
+	ids := make([]int, 0, len(marks))
+	for id := range marks {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return marks[ids[i]] > marks[ids[j]] })
+	for _, id := range ids[limit:] {
+		delete(marks, id)
+	}
+}
+
 func parseSinceTime(s string, now time.Time) (time.Time, error) {
 	s = strings.TrimSpace(s)
 	if s == "" {
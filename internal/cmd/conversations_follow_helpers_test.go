@@ -497,3 +497,144 @@ func TestFollowEmitterBehavior(t *testing.T) {
 		}
 	})
 }
+
+func TestFollowMarks(t *testing.T) {
+	m := newFollowMarks(10, map[int]int{1: 20})
+	if m.Mark(2, 9) || m.Mark(1, 15) {
+		t.Fatal("IDs at or below the floor or a conversation's mark must count as handled")
+	}
+	// Conversations are tracked separately, so a lower ID in another
+	// conversation is still new.
+	if !m.Mark(2, 11) || !m.Mark(1, 21) || !m.Mark(3, 12) {
+		t.Fatal("expected new messages to be marked")
+	}
+	if m.Mark(2, 11) {
+		t.Fatal("a message must be marked once")
+	}
+	if m.Last(4) != 10 || m.Last(1) != 21 {
+		t.Fatalf("Last(4)=%d Last(1)=%d", m.Last(4), m.Last(1))
+	}
+
+	// A clone is marked independently.
+	c := m.clone()
+	if !c.Mark(1, 22) || m.Last(1) != 21 {
+		t.Fatalf("clone shares marks: Last(1)=%d", m.Last(1))
+	}
+}
+
+func TestFollowCursorConversationMarks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cursor.json")
+	w, err := newFollowCursorWriter(path, "https://chatwoot.example.com", 1, 10, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Mark(7, 12)
+	w.LastFlushAt = time.Now()
+	// A lower ID in another conversation still changes the cursor.
+	w.Mark(8, 11)
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	cur, err := loadFollowCursor(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cur.LastSeenMessageID != 12 || cur.Conversations[7] != 12 || cur.Conversations[8] != 11 {
+		t.Fatalf("unexpected cursor: %#v", cur)
+	}
+	if cur.updatedAt().IsZero() {
+		t.Fatalf("updated_at not parsed: %q", cur.UpdatedAt)
+	}
+
+	marks := map[int]int{1: 5, 2: 50, 3: 40, 4: 10}
+	pruneFollowMarks(marks, 2)
+	if len(marks) != 2 || marks[2] != 50 || marks[3] != 40 {
+		t.Fatalf("pruneFollowMarks kept %v", marks)
+	}
+}
+
+// stubBackfillClient pages conversations two at a time and messages three at
+// a time, newest first, like the API.
+type stubBackfillClient struct {
+	convs    []api.Conversation
+	messages map[int][]api.Message // oldest first
+	pages    []int
+}
+
+func (s *stubBackfillClient) ListConversations(_ context.Context, params api.ListConversationsParams) (*api.ConversationList, error) {
+	if params.Status != "all" || params.SortBy != "last_activity_at_desc" {
+		return nil, fmt.Errorf("unexpected params %+v", params)
+	}
+	s.pages = append(s.pages, params.Page)
+	var list api.ConversationList
+	list.Data.Meta.TotalPages = api.FlexInt((len(s.convs) + 1) / 2)
+	if start := (params.Page - 1) * 2; start < len(s.convs) {
+		list.Data.Payload = s.convs[start:min(start+2, len(s.convs))]
+	}
+	return &list, nil
+}
+
+func (s *stubBackfillClient) ListMessagesBefore(_ context.Context, conversationID, before int) ([]api.Message, error) {
+	var older []api.Message
+	for _, m := range s.messages[conversationID] {
+		if before == 0 || m.ID < before {
+			older = append(older, m)
+		}
+	}
+	return older[max(0, len(older)-3):], nil
+}
+
+func backfillIDs(msgs []api.Message) []int {
+	ids := make([]int, 0, len(msgs))
+	for _, m := range msgs {
+		ids = append(ids, m.ID)
+	}
+	return ids
+}
+
+func TestFollowBackfillMessages(t *testing.T) {
+	since := time.Unix(1000, 0)
+	msg := func(conv, id int, createdAt int64) api.Message {
+		return api.Message{ID: id, ConversationID: conv, CreatedAt: createdAt}
+	}
+	client := &stubBackfillClient{
+		convs: []api.Conversation{
+			{ID: 1, LastActivityAt: 1300},
+			{ID: 2, LastActivityAt: 1200},
+			{ID: 3, LastActivityAt: 1100},
+			{ID: 4, LastActivityAt: 900}, // quiet since before the gap
+			{ID: 5, LastActivityAt: 800},
+		},
+		messages: map[int][]api.Message{
+			1: {msg(1, 1, 10), msg(1, 2, 20), msg(1, 21, 1001), msg(1, 22, 1002), msg(1, 30, 1010), msg(1, 31, 1011), msg(1, 40, 1300)},
+			2: {msg(2, 5, 30), msg(2, 25, 1005)},
+			3: {msg(3, 6, 40), msg(3, 35, 1100)},
+			4: {msg(4, 7, 50)},
+		},
+	}
+	cmd, _, _ := newFollowTestCmd(outfmt.JSON)
+	b := followBackfill{Client: client, Since: since}
+
+	// Conversations 1 and 2 resume from their own marks; 3 has only the
+	// window to go by.
+	marks := newFollowMarks(0, map[int]int{1: 20, 2: 5})
+	got, err := followBackfillMessages(context.Background(), cmd, b, 0, marks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{21, 22, 25, 30, 31, 35, 40}; fmt.Sprint(backfillIDs(got)) != fmt.Sprint(want) {
+		t.Fatalf("backfill = %v, want %v", backfillIDs(got), want)
+	}
+	if fmt.Sprint(client.pages) != "[1 2]" {
+		t.Fatalf("listed pages %v, want to stop at the first quiet conversation", client.pages)
+	}
+
+	// Without any mark only the window counts.
+	got, err = followBackfillMessages(context.Background(), cmd, b, 2, newFollowMarks(0, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(backfillIDs(got)) != "[25]" {
+		t.Fatalf("single conversation backfill = %v, want [25]", backfillIDs(got))
+	}
+}
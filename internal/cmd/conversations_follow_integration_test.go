@@ -7,11 +7,16 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/chatwoot/chatwoot-cli/internal/actioncable"
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+	"github.com/chatwoot/chatwoot-cli/internal/devserver"
 	"github.com/chatwoot/chatwoot-cli/internal/outfmt"
 	"github.com/coder/websocket"
 	"github.com/spf13/cobra"
@@ -141,7 +146,6 @@ func TestFollowViaWebSocket_RoutesDebounces_JSONL_WithRaw(t *testing.T) {
 		UserID:      1,
 	}
 
-	var lastSeen int
 	errCh := make(chan error, 1)
 	go func() {
 		errCh <- followViaWebSocket(ctx, cmd, followWebSocketConfig{
@@ -149,7 +153,7 @@ func TestFollowViaWebSocket_RoutesDebounces_JSONL_WithRaw(t *testing.T) {
 			ChannelID:     channelID,
 			ConvID:        100,
 			IncomingOnly:  true,
-			LastSeenID:    &lastSeen,
+			Marks:         newFollowMarks(0, nil),
 			AllowedEvents: allowed,
 			Debounce:      250 * time.Millisecond,
 			IncludeRaw:    true,
@@ -244,3 +248,255 @@ func TestFollowViaWebSocket_RoutesDebounces_JSONL_WithRaw(t *testing.T) {
 		t.Fatalf("did not expect assignee.changed (should be filtered), got: %v", events)
 	}
 }
+
+// flakyCable passes requests to the dev server but can cut its ActionCable
+// sockets and refuse new ones, like a network outage would.
+type flakyCable struct {
+	http.Handler
+
+	mu    sync.Mutex
+	down  bool
+	drops []context.CancelFunc
+}
+
+func (f *flakyCable) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/cable" {
+		f.mu.Lock()
+		if f.down {
+			f.mu.Unlock()
+			http.Error(w, "cable down", http.StatusServiceUnavailable)
+			return
+		}
+		ctx, cancel := context.WithCancel(r.Context())
+		f.drops = append(f.drops, cancel)
+		f.mu.Unlock()
+		r = r.WithContext(ctx)
+	}
+	f.Handler.ServeHTTP(w, r)
+}
+
+func (f *flakyCable) setDown(down bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.down = down
+	if down {
+		for _, cancel := range f.drops {
+			cancel()
+		}
+		f.drops = nil
+	}
+}
+
+// followHarness runs cw against a dev server whose cable can be cut.
+type followHarness struct {
+	t      *testing.T
+	url    string
+	cable  *flakyCable
+	client *api.Client
+}
+
+func newFollowHarness(t *testing.T) *followHarness {
+	t.Helper()
+	fake, err := devserver.New(devserver.DefaultFixture(), "test-token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(fake.Close)
+	cable := &flakyCable{Handler: fake}
+	env := setupTestEnvWithHandler(t, cable)
+	old := followReconnectBackoff
+	followReconnectBackoff = 20 * time.Millisecond
+	t.Cleanup(func() { followReconnectBackoff = old })
+	return &followHarness{t: t, url: env.server.URL, cable: cable, client: api.New(env.server.URL, "test-token", 1)}
+}
+
+// send creates a message and returns its ID.
+func (h *followHarness) send(convID int, content, messageType string) int {
+	h.t.Helper()
+	m, err := h.client.Messages().Create(context.Background(), convID, content, false, messageType)
+	if err != nil {
+		h.t.Fatalf("create message: %v", err)
+	}
+	return m.ID
+}
+
+// run starts cw with args in the background. The returned function stops it
+// and waits for it to exit.
+func (h *followHarness) run(args ...string) func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		var runErr error
+		captureStdout(h.t, func() {
+			runErr = Execute(ctx, args)
+		})
+		done <- runErr
+	}()
+	return func() {
+		h.t.Helper()
+		cancel()
+		select {
+		case err := <-done:
+			if err != nil {
+				h.t.Fatalf("cw %s: %v", strings.Join(args, " "), err)
+			}
+		case <-time.After(5 * time.Second):
+			h.t.Fatalf("cw %s did not stop", strings.Join(args, " "))
+		}
+	}
+}
+
+// readExecLog returns the sources of the message records an --exec handler
+// appended to path, by message ID.
+func readExecLog(path string) map[int][]string {
+	data, _ := os.ReadFile(path)
+	got := map[int][]string{}
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var rec struct {
+			Source string `json:"source"`
+			Item   struct {
+				ID int `json:"id"`
+			} `json:"item"`
+		}
+		if json.Unmarshal([]byte(line), &rec) == nil && rec.Item.ID > 0 {
+			got[rec.Item.ID] = append(got[rec.Item.ID], rec.Source)
+		}
+	}
+	return got
+}
+
+func waitForExec(t *testing.T, path string, id int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for len(readExecLog(path)[id]) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("message %d never reached the exec handler; got %v", id, readExecLog(path))
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestFollowBackfillsMessagesMissedWhileDisconnected(t *testing.T) {
+	h := newFollowHarness(t)
+	dir := t.TempDir()
+	execFile := filepath.Join(dir, "exec.jsonl")
+	cursorFile := filepath.Join(dir, "cursor.json")
+	// A cursor from an earlier run; the message after it arrives while no
+	// follow is running.
+	last := h.send(1, "before restart", "outgoing")
+	if err := saveFollowCursor(cursorFile, followCursor{BaseURL: h.url, AccountID: 1, LastSeenMessageID: last}); err != nil {
+		t.Fatal(err)
+	}
+	want := []int{h.send(2, "while stopped", "incoming")}
+
+	stop := h.run("conversations", "follow", "--all", "-o", "jsonl", "--cursor-file", cursorFile, "--exec", "cat >> "+execFile)
+	waitForExec(t, execFile, want[0])
+	want = append(want, h.send(1, "live", "incoming"))
+	waitForExec(t, execFile, want[1])
+
+	h.cable.setDown(true)
+	h.send(3, "agent reply while down", "outgoing")
+	want = append(want, h.send(1, "missed one", "incoming"), h.send(3, "missed two", "incoming"))
+	h.cable.setDown(false)
+	waitForExec(t, execFile, want[3])
+	want = append(want, h.send(2, "live again", "incoming"))
+	waitForExec(t, execFile, want[4])
+	stop()
+
+	got := readExecLog(execFile)
+	if len(got) != len(want) {
+		t.Errorf("exec handler got %v, want messages %v", got, want)
+	}
+	for i, id := range want {
+		sources := got[id]
+		if len(sources) != 1 {
+			t.Errorf("message %d handled %d times (%v), want once", id, len(sources), sources)
+			continue
+		}
+		backfilled := i == 0 || i == 2 || i == 3
+		if backfilled && sources[0] != "backfill" || !backfilled && sources[0] != "ws" {
+			t.Errorf("message %d came from %s", id, sources[0])
+		}
+	}
+
+	cur, err := loadFollowCursor(cursorFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cur.LastSeenMessageID != want[4] || cur.Conversations[3] != want[3] || cur.Conversations[1] != want[2] {
+		t.Errorf("cursor = %+v, want marks for conversations 1 and 3", cur)
+	}
+}
+
+func TestFollowRestartBackfillsFromConversationMark(t *testing.T) {
+	h := newFollowHarness(t)
+	dir := t.TempDir()
+	execFile := filepath.Join(dir, "exec.jsonl")
+	cursorFile := filepath.Join(dir, "cursor.json")
+
+	// The previous run saw seen1 in conversation 1 and seen2 in
+	// conversation 2, but missed the message in between.
+	seen1 := h.send(1, "seen", "incoming")
+	missed := h.send(1, "missed", "incoming")
+	seen2 := h.send(2, "seen too", "incoming")
+	if err := saveFollowCursor(cursorFile, followCursor{
+		BaseURL:           h.url,
+		AccountID:         1,
+		LastSeenMessageID: seen2,
+		Conversations:     map[int]int{1: seen1, 2: seen2},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	stop := h.run("conversations", "follow", "--all", "-o", "jsonl", "--cursor-file", cursorFile, "--exec", "cat >> "+execFile)
+	waitForExec(t, execFile, missed)
+	live := h.send(2, "live", "incoming")
+	waitForExec(t, execFile, live)
+	stop()
+
+	got := readExecLog(execFile)
+	if len(got) != 2 || len(got[missed]) != 1 || got[missed][0] != "backfill" || len(got[live]) != 1 {
+		t.Errorf("exec handler got %v, want %d from the backfill and %d live", got, missed, live)
+	}
+}
+
+func TestFollowReplaysMessagesWhoseExecFailed(t *testing.T) {
+	h := newFollowHarness(t)
+	dir := t.TempDir()
+	execFile := filepath.Join(dir, "exec.jsonl")
+	failed := filepath.Join(dir, "failed")
+	cursorFile := filepath.Join(dir, "cursor.json")
+	// The handler fails the first time it sees the poison message.
+	handler := `ev=$(cat); case "$ev" in *poison*) [ -e ` + failed + ` ] || { touch ` + failed + `; exit 1; } ;; esac; printf '%s\n' "$ev" >> ` + execFile
+
+	if err := saveFollowCursor(cursorFile, followCursor{BaseURL: h.url, AccountID: 1, LastSeenMessageID: 1}); err != nil {
+		t.Fatal(err)
+	}
+	first := h.send(1, "first", "incoming")
+
+	stop := h.run("conversations", "follow", "--all", "-o", "jsonl", "--cursor-file", cursorFile, "--exec", handler, "--exec-fatal")
+	waitForExec(t, execFile, first)
+	poison := h.send(1, "poison", "incoming")
+	// The failure surfaces on the next event and ends the connection; the
+	// reconnect replays both.
+	after := h.send(2, "after", "incoming")
+	waitForExec(t, execFile, after)
+	stop()
+
+	if _, err := os.Stat(failed); err != nil {
+		t.Fatalf("the handler never failed: %v", err)
+	}
+	got := readExecLog(execFile)
+	for _, id := range []int{poison, after} {
+		if len(got[id]) != 1 || got[id][0] != "backfill" {
+			t.Errorf("message %d handled as %v, want once from the backfill", id, got[id])
+		}
+	}
+	cur, err := loadFollowCursor(cursorFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cur.Conversations[1] != poison || cur.Conversations[2] != after {
+		t.Errorf("cursor = %+v, want conversation 1 at %d and 2 at %d", cur, poison, after)
+	}
+}
@@ -36,6 +36,7 @@ func newConversationsListCmd() *cobra.Command {
 	var since string
 	var waiting bool
 	var light bool
+	var offline bool
 
 	cfg := ListConfig[api.Conversation]{
 		Use:               "list",
@@ -66,12 +67,18 @@ func newConversationsListCmd() *cobra.Command {
 
   # Fetch all pages
   cw conversations list --status open --all
+
+  # Answer from the local mirror (see 'cw sync')
+  cw conversations list --status open --offline
 `),
 		AgentTransform: func(ctx context.Context, client *api.Client, items []api.Conversation) (any, error) {
 			if light {
 				return buildLightConversationLookups(items), nil
 			}
 			summaries := agentfmt.ConversationSummaries(items)
+			if offline {
+				return summaries, nil
+			}
 			return resolveConversationSummaries(ctx, client, summaries), nil
 		},
 		JSONTransform: func(_ context.Context, _ *api.Client, items []api.Conversation) (any, error) {
@@ -96,6 +103,24 @@ func newConversationsListCmd() *cobra.Command {
 				return ListResult[api.Conversation]{}, err
 			}
 
+			if offline {
+				items, hasMore, err := listMirrorConversations(client, page, inboxID, offlineConversationFilter{
+					Status:       normalizedStatus,
+					AssigneeType: normalizedAssigneeType,
+					TeamID:       teamID,
+					Labels:       splitCommaList(labels),
+					Query:        search,
+				})
+				if err != nil {
+					return ListResult[api.Conversation]{}, err
+				}
+				items, err = filterConversationsLocally(items, unreadOnly, contactID, since, waiting)
+				if err != nil {
+					return ListResult[api.Conversation]{}, err
+				}
+				return ListResult[api.Conversation]{Items: items, HasMore: hasMore}, nil
+			}
+
 			// Resolve inbox name to ID if provided.
 			resolvedInboxID := inboxID
 			if inboxID != "" {
@@ -125,44 +150,9 @@ func newConversationsListCmd() *cobra.Command {
 			}
 
 			items := result.Data.Payload
-			if unreadOnly {
-				filtered := make([]api.Conversation, 0, len(items))
-				for _, conv := range items {
-					if conv.Unread > 0 {
-						filtered = append(filtered, conv)
-					}
-				}
-				items = filtered
-			}
-			if contactID > 0 {
-				filtered := make([]api.Conversation, 0, len(items))
-				for _, conv := range items {
-					if conversationContactID(conv) == contactID {
-						filtered = append(filtered, conv)
-					}
-				}
-				items = filtered
-			}
-			if since != "" {
-				sinceTime, err := cli.ParseRelativeTime(since, time.Now())
-				if err != nil {
-					return ListResult[api.Conversation]{}, fmt.Errorf("invalid --since value: %w", err)
-				}
-				filtered := make([]api.Conversation, 0, len(items))
-				for _, conv := range items {
-					if conv.LastActivityAtTime().After(sinceTime) || conv.LastActivityAtTime().Equal(sinceTime) {
-						filtered = append(filtered, conv)
-					}
-				}
-				items = filtered
-			}
-			if waiting {
-				// Sort by customer wait time (longest waiting first).
-				// Wait time is approximated by oldest LastActivityAt, since conversations
-				// with older last activity have been waiting longer for a response.
-				sort.Slice(items, func(i, j int) bool {
-					return items[i].LastActivityAt < items[j].LastActivityAt
-				})
+			items, err = filterConversationsLocally(items, unreadOnly, contactID, since, waiting)
+			if err != nil {
+				return ListResult[api.Conversation]{}, err
 			}
 
 			totalPages := int(result.Data.Meta.TotalPages)
@@ -200,6 +190,7 @@ func newConversationsListCmd() *cobra.Command {
 	cmd.Flags().StringVarP(&since, "since", "S", "", "Filter by last activity (e.g., yesterday, 2h ago, 2026-01-30)")
 	cmd.Flags().BoolVar(&waiting, "waiting", false, "Sort by customer wait time (longest first)")
 	cmd.Flags().BoolVar(&light, "light", false, "Return minimal conversation payload for lookup")
+	addOfflineFlag(cmd, &offline)
 	flagAlias(cmd.Flags(), "status", "st")
 	flagAlias(cmd.Flags(), "inbox-id", "iid")
 	flagAlias(cmd.Flags(), "contact-id", "cid")
@@ -215,6 +206,75 @@ func newConversationsListCmd() *cobra.Command {
 	return cmd
 }
 
+// filterConversationsLocally applies the list filters the API doesn't support.
+func filterConversationsLocally(items []api.Conversation, unreadOnly bool, contactID int, since string, waiting bool) ([]api.Conversation, error) {
+	if unreadOnly {
+		filtered := make([]api.Conversation, 0, len(items))
+		for _, conv := range items {
+			if conv.Unread > 0 {
+				filtered = append(filtered, conv)
+			}
+		}
+		items = filtered
+	}
+	if contactID > 0 {
+		filtered := make([]api.Conversation, 0, len(items))
+		for _, conv := range items {
+			if conversationContactID(conv) == contactID {
+				filtered = append(filtered, conv)
+			}
+		}
+		items = filtered
+	}
+	if since != "" {
+		sinceTime, err := cli.ParseRelativeTime(since, time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("invalid --since value: %w", err)
+		}
+		filtered := make([]api.Conversation, 0, len(items))
+		for _, conv := range items {
+			if conv.LastActivityAtTime().After(sinceTime) || conv.LastActivityAtTime().Equal(sinceTime) {
+				filtered = append(filtered, conv)
+			}
+		}
+		items = filtered
+	}
+	if waiting {
+		// Sort by customer wait time (longest waiting first).
+		// Wait time is approximated by oldest LastActivityAt, since conversations
+		// with older last activity have been waiting longer for a response.
+		sort.Slice(items, func(i, j int) bool {
+			return items[i].LastActivityAt < items[j].LastActivityAt
+		})
+	}
+	return items, nil
+}
+
+// listMirrorConversations serves one page of conversations from the offline mirror.
+func listMirrorConversations(client *api.Client, page int, inbox string, f offlineConversationFilter) ([]api.Conversation, bool, error) {
+	if inbox != "" {
+		id, err := strconv.Atoi(strings.TrimSpace(inbox))
+		if err != nil || id <= 0 {
+			return nil, false, fmt.Errorf("--inbox-id must be a numeric ID with --offline")
+		}
+		f.InboxID = id
+	}
+	m, err := syncedMirror(client)
+	if err != nil {
+		return nil, false, err
+	}
+	convs, err := m.Conversations()
+	if err != nil {
+		return nil, false, err
+	}
+	convs, err = filterMirrorConversations(m, convs, f)
+	if err != nil {
+		return nil, false, err
+	}
+	items, hasMore := pageSlice(convs, page, offlinePageSize)
+	return items, hasMore, nil
+}
+
 func conversationContactID(conv api.Conversation) int {
 	if conv.ContactID > 0 {
 		return conv.ContactID
@@ -16,6 +16,7 @@ func newCtxCmd() *cobra.Command {
 	var light bool
 	var publicOnly bool
 	var tail int
+	var offline bool
 
 	cmd := &cobra.Command{
 		Use:     "ctx <conversation-id|url>",
@@ -36,6 +37,9 @@ Accepts a conversation ID or a pasted Chatwoot URL.`,
 
   # Lightweight context (minimal JSON for triage)
   cw ctx 123 --li --cj
+
+  # Answer from the local mirror (see 'cw sync')
+  cw ctx 123 --offline --output agent
 `),
 		Args: cobra.ExactArgs(1),
 		RunE: RunE(func(cmd *cobra.Command, args []string) error {
@@ -47,18 +51,28 @@ Accepts a conversation ID or a pasted Chatwoot URL.`,
 				return fmt.Errorf("--tail must be at least 1")
 			}
 
+			if offline && embedImages {
+				return fmt.Errorf("--embed-images cannot be combined with --offline")
+			}
+
 			client, err := getClient()
 			if err != nil {
 				return err
 			}
 
 			requestEmbeddedImages := embedImages && !light && !excludeAttachments
-			ctx, err := client.Context().GetConversationWithOptions(cmdContext(cmd), id, api.ConversationContextOptions{
+			opts := api.ConversationContextOptions{
 				EmbedImages:        requestEmbeddedImages,
 				Tail:               tail,
 				PublicOnly:         publicOnly,
 				ExcludeAttachments: excludeAttachments,
-			})
+			}
+			var ctx *api.ConversationContext
+			if offline {
+				ctx, err = mirrorConversationContext(client, id, opts)
+			} else {
+				ctx, err = client.Context().GetConversationWithOptions(cmdContext(cmd), id, opts)
+			}
 			if err != nil {
 				return fmt.Errorf("failed to get conversation context: %w", err)
 			}
@@ -72,7 +86,9 @@ Accepts a conversation ID or a pasted Chatwoot URL.`,
 				var detail any
 				if ctx.Conversation != nil {
 					convDetail := agentfmt.ConversationDetailFromConversation(*ctx.Conversation)
-					convDetail = resolveConversationDetail(cmdContext(cmd), client, convDetail)
+					if !offline {
+						convDetail = resolveConversationDetail(cmdContext(cmd), client, convDetail)
+					}
 					detail = convDetail
 				}
 
@@ -83,7 +99,7 @@ Accepts a conversation ID or a pasted Chatwoot URL.`,
 
 				var contactLabels []string
 				var contactInboxes []contextInboxSummary
-				if ctx.Contact != nil && ctx.Contact.ID > 0 {
+				if ctx.Contact != nil && ctx.Contact.ID > 0 && !offline {
 					labels, err := client.Contacts().Labels(cmdContext(cmd), ctx.Contact.ID)
 					if err == nil {
 						contactLabels = labels
@@ -162,6 +178,29 @@ Accepts a conversation ID or a pasted Chatwoot URL.`,
 	flagAlias(cmd.Flags(), "exclude-attachments", "xa")
 	flagAlias(cmd.Flags(), "light", "li")
 	flagAlias(cmd.Flags(), "public-only", "pub")
+	addOfflineFlag(cmd, &offline)
 
 	return cmd
 }
+
+// mirrorConversationContext builds conversation context from the offline mirror.
+func mirrorConversationContext(client *api.Client, id int, opts api.ConversationContextOptions) (*api.ConversationContext, error) {
+	m, err := syncedMirror(client)
+	if err != nil {
+		return nil, err
+	}
+	conv, err := m.Conversation(id)
+	if err != nil {
+		return nil, err
+	}
+	messages, err := m.Messages(id)
+	if err != nil {
+		return nil, err
+	}
+	var contact *api.Contact
+	if contactID := conversationContactID(*conv); contactID > 0 {
+		contact, _ = m.Contact(contactID) // contact is optional, as online
+	}
+	opts.EmbedImages = false
+	return api.BuildConversationContext(conv, contact, messages, opts), nil
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/chatwoot/chatwoot-cli/internal/devserver"
+	"github.com/spf13/cobra"
+)
+
+func newDevCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "dev",
+		Short: "Tools for developing and testing against Chatwoot",
+	}
+	cmd.AddCommand(newDevServerCmd())
+	return cmd
+}
+
+func newDevServerCmd() *cobra.Command {
+	var (
+		listen string
+		seed   string
+		token  string
+	)
+
+	cmd := &cobra.Command{
+		Use:     "server",
+		Aliases: []string{"serve"},
+		Short:   "Run an in-memory fake Chatwoot for offline use and CI",
+		Long: strings.TrimSpace(`
+Run a fake Chatwoot account in memory so cw commands work offline and CI can
+run end-to-end tests without a real instance.
+
+The server implements the account API endpoints the CLI uses for
+conversations, messages, contacts, inboxes, agents, teams, labels and
+reports; other endpoints answer 404. Changes are kept in memory until the
+server stops. An ActionCable endpoint at /cable broadcasts message, status,
+assignment and label events as they happen, so follow and tui work too.
+
+The account is seeded from --seed, a JSON fixture, or a built-in demo account
+with a few agents, inboxes, contacts and conversations. Fixture resources use
+the API's JSON shapes; IDs and timestamps may be omitted. Teams and inboxes
+take a "members" list of agent IDs, contacts a "labels" list, and
+conversations a "messages" list.
+
+Point the CLI at it with the environment printed on startup. Requests must
+carry --token as their API token.
+`),
+		Example: strings.TrimSpace(`
+  # Start the demo account on the default port
+  cw dev server
+
+  # In another shell
+  export CHATWOOT_BASE_URL=http://127.0.0.1:3000 CHATWOOT_API_TOKEN=dev-token \
+    CHATWOOT_ACCOUNT_ID=1 CHATWOOT_ALLOW_PRIVATE=1
+  cw conversations list
+
+  # Seed from a fixture on a random port
+  cw dev server --seed testdata/seed.json --listen 127.0.0.1:0
+`),
+		Args: cobra.NoArgs,
+		RunE: RunE(func(cmd *cobra.Command, _ []string) error {
+			fixture := devserver.DefaultFixture()
+			if seed != "" {
+				var err error
+				if fixture, err = devserver.LoadFixture(seed); err != nil {
+					return err
+				}
+			}
+			fake, err := devserver.New(fixture, token)
+			if err != nil {
+				return err
+			}
+			defer fake.Close()
+
+			ctx, stop := signal.NotifyContext(cmdContext(cmd), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			ln, err := net.Listen("tcp", listen)
+			if err != nil {
+				return fmt.Errorf("listen on %s: %w", listen, err)
+			}
+			srv := &http.Server{Handler: fake, ReadHeaderTimeout: 10 * time.Second}
+
+			errOut := cmd.ErrOrStderr()
+			_, _ = fmt.Fprintf(errOut, "Dev server listening on http://%s (press Ctrl+C to stop)\n\n", ln.Addr())
+			_, _ = fmt.Fprintf(errOut, "  export CHATWOOT_BASE_URL=http://%s\n", ln.Addr())
+			_, _ = fmt.Fprintf(errOut, "  export CHATWOOT_API_TOKEN=%s\n", token)
+			_, _ = fmt.Fprintf(errOut, "  export CHATWOOT_ACCOUNT_ID=%d\n", fake.AccountID())
+			_, _ = fmt.Fprintf(errOut, "  export CHATWOOT_ALLOW_PRIVATE=1\n")
+
+			serveErr := make(chan error, 1)
+			go func() { serveErr <- srv.Serve(ln) }()
+
+			var runErr error
+			select {
+			case <-ctx.Done():
+			case err := <-serveErr:
+				if !errors.Is(err, http.ErrServerClosed) {
+					runErr = err
+				}
+			}
+
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			_ = srv.Shutdown(shutdownCtx)
+			return runErr
+		}),
+	}
+
+	cmd.Flags().StringVar(&listen, "listen", "127.0.0.1:3000", "Address to listen on")
+	cmd.Flags().StringVar(&seed, "seed", "", "JSON fixture to seed the account from (default: built-in demo account)")
+	cmd.Flags().StringVar(&token, "token", devserver.DefaultToken, "API token requests must carry")
+	flagAlias(cmd.Flags(), "listen", "addr")
+	flagAlias(cmd.Flags(), "seed", "sd")
+	flagAlias(cmd.Flags(), "token", "tok")
+
+	return cmd
+}
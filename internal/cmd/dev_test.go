@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chatwoot/chatwoot-cli/internal/devserver"
+)
+
+func TestDevServerServesAPI(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	stderr := captureStderr(t, func() {
+		go func() { done <- Execute(ctx, []string{"dev", "server", "--listen", addr, "--token", "secret"}) }()
+
+		var body string
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			req, _ := http.NewRequest(http.MethodGet, "http://"+addr+"/api/v1/accounts/1/agents", nil)
+			req.Header.Set("api_access_token", "secret")
+			resp, err := http.DefaultClient.Do(req)
+			if err == nil {
+				data, _ := io.ReadAll(resp.Body)
+				_ = resp.Body.Close()
+				body = string(data)
+				break
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("dev server returned %v", err)
+		}
+		if !strings.Contains(body, `"Ada Lovelace"`) {
+			t.Errorf("agents response = %q, want the seeded agents", body)
+		}
+	})
+
+	for _, want := range []string{"http://" + addr, "CHATWOOT_API_TOKEN=secret", "CHATWOOT_ACCOUNT_ID=1"} {
+		if !strings.Contains(stderr, want) {
+			t.Errorf("stderr missing %q:\n%s", want, stderr)
+		}
+	}
+}
+
+func TestDevServerInvalidSeed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "seed.json")
+	if err := os.WriteFile(path, []byte(`{"conversations":[{"inbox_id":3}]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	err := Execute(context.Background(), []string{"dev", "server", "--seed", path})
+	if err == nil || !strings.Contains(err.Error(), "inbox 3 not found") {
+		t.Errorf("expected seed error, got %v", err)
+	}
+}
+
+// TestDevServerEndToEnd runs commands against the dev server the way a CI
+// job would.
+func TestDevServerEndToEnd(t *testing.T) {
+	fake, err := devserver.New(devserver.DefaultFixture(), "test-token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(fake.Close)
+	setupTestEnvWithHandler(t, fake)
+
+	run := func(args ...string) string {
+		t.Helper()
+		var runErr error
+		out := captureStdout(t, func() {
+			runErr = Execute(context.Background(), args)
+		})
+		if runErr != nil {
+			t.Fatalf("cw %s: %v", strings.Join(args, " "), runErr)
+		}
+		return out
+	}
+
+	if out := run("conversations", "list", "--status", "open", "-o", "json"); !strings.Contains(out, `"id": 1`) {
+		t.Errorf("conversations list missing conversation 1:\n%s", out)
+	}
+
+	run("comment", "1", "Thanks, looking into it")
+	run("close", "1")
+	run("conversations", "labels-add", "1", "--labels", "bug")
+
+	var conv struct {
+		Status string   `json:"status"`
+		Labels []string `json:"labels"`
+	}
+	if err := json.Unmarshal([]byte(run("conversations", "get", "1", "-o", "json")), &conv); err != nil {
+		t.Fatalf("decode conversation: %v", err)
+	}
+	if conv.Status != "resolved" || len(conv.Labels) != 1 || conv.Labels[0] != "bug" {
+		t.Errorf("conversation = %+v, want resolved with label bug", conv)
+	}
+
+	if out := run("messages", "list", "1", "-o", "json"); !strings.Contains(out, "Thanks, looking into it") {
+		t.Errorf("messages list missing the comment:\n%s", out)
+	}
+	if out := run("contacts", "search", "jane", "-o", "json"); !strings.Contains(out, "jane@example.com") {
+		t.Errorf("contacts search missing Jane:\n%s", out)
+	}
+}
+
+func TestDevServerUnknownEndpoint(t *testing.T) {
+	fake, err := devserver.New(devserver.DefaultFixture(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(fake)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/api/v1/accounts/1/campaigns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", resp.StatusCode)
+	}
+}
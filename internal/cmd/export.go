@@ -0,0 +1,357 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+	"github.com/chatwoot/chatwoot-cli/internal/archive"
+	"github.com/chatwoot/chatwoot-cli/internal/cli"
+)
+
+// defaultExportMaxAttachmentBytes bounds a single attachment download.
+const defaultExportMaxAttachmentBytes = int64(100 * 1024 * 1024)
+
+// exportSummary is the result of one export run.
+type exportSummary struct {
+	Dir                string            `json:"dir"`
+	Exported           int               `json:"exported"`
+	AlreadyExported    int               `json:"already_exported"`
+	Failed             int               `json:"failed"`
+	AttachmentFailures int               `json:"attachment_failures,omitempty"`
+	Manifest           *archive.Manifest `json:"manifest,omitempty"`
+}
+
+func newExportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "export",
+		Aliases: []string{"exp"},
+		Short:   "Export account data to local archives",
+	}
+	cmd.AddCommand(newExportConversationsCmd())
+	return cmd
+}
+
+func newExportConversationsCmd() *cobra.Command {
+	var (
+		since         string
+		until         string
+		inbox         string
+		status        string
+		out           string
+		attachments   bool
+		maxAttachment int64
+		maxPages      int
+		concurrency   int
+		progress      bool
+		noProgress    bool
+	)
+
+	cmd := &cobra.Command{
+		Use:     "conversations",
+		Aliases: []string{"conversation", "c"},
+		Short:   "Archive conversations, messages and contacts as JSONL",
+		Long: strings.TrimSpace(`
+Write every conversation whose last activity falls in the --since/--until
+window to an archive directory:
+
+  conversations.jsonl   one conversation per line
+  messages.jsonl        every message of those conversations
+  contacts.jsonl        each conversation's contact, once
+  attachments.jsonl     one line per message attachment (with --attachments)
+  attachments/ab/<sha>  attachment files, named by their SHA-256
+  manifest.json         filters, record counts and SHA-256 of each file
+
+The export can be interrupted and re-run with the same flags: finished
+conversations are skipped and partial output is discarded. The manifest is
+written once every conversation has been exported, so its presence marks a
+complete archive. A "30d" window is fixed when the export starts and kept on
+resume.
+`),
+		Example: strings.TrimSpace(`
+  # Last 30 days, one inbox
+  cw export conversations --since 30d --inbox Support --out archive/
+
+  # A calendar month with attachment files
+  cw export conversations --since 2026-09-01 --until 2026-10-01 --attachments --out 2026-09/
+
+  # Resume after an interruption (same flags)
+  cw export conversations --since 2026-09-01 --until 2026-10-01 --attachments --out 2026-09/
+`),
+		Args: cobra.NoArgs,
+		RunE: RunE(func(cmd *cobra.Command, _ []string) error {
+			if out == "" {
+				return fmt.Errorf("--out is required")
+			}
+			now := time.Now().UTC()
+			sinceTime, err := parseExportTime("--since", since, now)
+			if err != nil {
+				return err
+			}
+			untilTime, err := parseExportTime("--until", until, now)
+			if err != nil {
+				return err
+			}
+			if !sinceTime.IsZero() && !untilTime.IsZero() && !untilTime.After(sinceTime) {
+				return fmt.Errorf("--until must be after --since")
+			}
+
+			client, err := getClient()
+			if err != nil {
+				return err
+			}
+			ctx := cmdContext(cmd)
+
+			inboxID := 0
+			if inbox != "" {
+				if inboxID, err = resolveInboxID(ctx, client, inbox); err != nil {
+					return err
+				}
+			}
+
+			w, err := archive.Open(out, archive.Header{
+				Version: archive.Version,
+				Source:  archive.Source{BaseURL: client.BaseURL, AccountID: client.AccountID},
+				Filters: archive.Filters{
+					SinceArg:    since,
+					UntilArg:    until,
+					Since:       sinceTime,
+					Until:       untilTime,
+					InboxID:     inboxID,
+					Status:      status,
+					Attachments: attachments,
+				},
+				StartedAt: now,
+			})
+			if err != nil {
+				return err
+			}
+			defer func() { _ = w.Close() }()
+			filters := w.Header().Filters
+
+			summary := exportSummary{Dir: out}
+			convs, err := listExportConversations(ctx, client, w, filters, maxPages, &summary)
+			if err != nil {
+				return err
+			}
+
+			ids := make([]int, 0, len(convs))
+			byID := make(map[int]api.Conversation, len(convs))
+			for _, conv := range convs {
+				ids = append(ids, conv.ID)
+				byID[conv.ID] = conv
+			}
+			results := runBulkOperation(ctx, ids, int64(concurrency), bulkProgressEnabled(cmd, progress, noProgress), cmd.ErrOrStderr(),
+				func(ctx context.Context, id int) (int, error) {
+					bundle, err := buildExportBundle(ctx, client, w, byID[id], attachments, maxAttachment)
+					if err != nil {
+						return 0, err
+					}
+					return countAttachmentFailures(bundle.Attachments), w.Write(bundle)
+				},
+			)
+			for _, r := range results {
+				if !r.Success {
+					summary.Failed++
+					_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Failed to export conversation %d: %v\n", r.ID, r.Error)
+					continue
+				}
+				summary.Exported++
+				if n, ok := r.Data.(int); ok {
+					summary.AttachmentFailures += n
+				}
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			if summary.Failed == 0 {
+				if summary.Manifest, err = w.Finish(time.Now()); err != nil {
+					return err
+				}
+			}
+
+			if isJSON(cmd) {
+				if err := printJSON(cmd, summary); err != nil {
+					return err
+				}
+			} else {
+				printExportSummary(cmd, summary)
+			}
+			if summary.Failed > 0 {
+				return fmt.Errorf("failed to export %d conversations; re-run the same command to resume", summary.Failed)
+			}
+			return nil
+		}),
+	}
+
+	cmd.Flags().StringVarP(&since, "since", "S", "", "Only conversations active since this time (e.g. 30d, 2026-09-01, RFC3339)")
+	cmd.Flags().StringVar(&until, "until", "", "Only conversations last active before this time")
+	cmd.Flags().StringVar(&inbox, "inbox", "", "Only conversations in this inbox (ID or name)")
+	cmd.Flags().StringVar(&status, "status", "all", "Conversation status filter (open|resolved|pending|snoozed|all)")
+	registerStaticCompletions(cmd, "status", []string{"open", "resolved", "pending", "snoozed", "all"})
+	cmd.Flags().StringVar(&out, "out", "", "Archive directory (created if needed; re-use to resume)")
+	cmd.Flags().BoolVar(&attachments, "attachments", false, "Download attachment files into the archive")
+	cmd.Flags().Int64Var(&maxAttachment, "max-attachment-bytes", defaultExportMaxAttachmentBytes, "Skip attachments larger than this (0 = no limit)")
+	cmd.Flags().IntVar(&maxPages, "max-pages", 0, "Stop listing conversations after this many pages (0 = all)")
+	cmd.Flags().IntVar(&concurrency, "concurrency", DefaultConcurrency, "Max conversations fetched at once")
+	cmd.Flags().BoolVar(&progress, "progress", true, "Show progress while running")
+	cmd.Flags().BoolVar(&noProgress, "no-progress", false, "Disable progress output")
+	flagAlias(cmd.Flags(), "inbox", "ib")
+	flagAlias(cmd.Flags(), "status", "st")
+	flagAlias(cmd.Flags(), "max-pages", "mp")
+	flagAlias(cmd.Flags(), "concurrency", "cc")
+	flagAlias(cmd.Flags(), "progress", "prg")
+	flagAlias(cmd.Flags(), "no-progress", "npr")
+
+	return cmd
+}
+
+// parseExportTime accepts durations ("30d", "12h") as look-back values, and
+// the expressions cli.ParseRelativeTime understands.
+func parseExportTime(flag, value string, now time.Time) (time.Time, error) {
+	if strings.TrimSpace(value) == "" {
+		return time.Time{}, nil
+	}
+	if d, err := parseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	t, err := cli.ParseRelativeTime(value, now)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s value %q: %w", flag, value, err)
+	}
+	return t.UTC(), nil
+}
+
+// listExportConversations pages through conversations, newest activity
+// first, and returns those in the window that are not exported yet.
+func listExportConversations(ctx context.Context, client *api.Client, w *archive.Writer, filters archive.Filters, maxPages int, summary *exportSummary) ([]api.Conversation, error) {
+	params := api.ListConversationsParams{Status: filters.Status}
+	if filters.InboxID != 0 {
+		params.InboxID = strconv.Itoa(filters.InboxID)
+	}
+	var convs []api.Conversation
+	seen := map[int]bool{}
+	for page := 1; maxPages <= 0 || page <= maxPages; page++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		params.Page = page
+		result, err := client.Conversations().List(ctx, params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list conversations: %w", err)
+		}
+		items := result.Data.Payload
+		reachedSince := false
+		for _, conv := range items {
+			activity := conv.LastActivityAtTime()
+			if !filters.Since.IsZero() && activity.Before(filters.Since) {
+				reachedSince = true
+				continue
+			}
+			if !filters.Includes(activity) || seen[conv.ID] {
+				continue
+			}
+			seen[conv.ID] = true
+			if w.Done(conv.ID) {
+				summary.AlreadyExported++
+				continue
+			}
+			convs = append(convs, conv)
+		}
+		totalPages := int(result.Data.Meta.TotalPages)
+		if len(items) == 0 || reachedSince || totalPages == 0 || page >= totalPages {
+			break
+		}
+	}
+	return convs, nil
+}
+
+// buildExportBundle fetches a conversation's messages, its contact (unless
+// already archived) and, optionally, its attachment files. A failed
+// attachment download is recorded on its line rather than failing the
+// conversation.
+func buildExportBundle(ctx context.Context, client *api.Client, w *archive.Writer, conv api.Conversation, attachments bool, maxAttachment int64) (archive.Bundle, error) {
+	bundle := archive.Bundle{Conversation: conv}
+	msgs, err := client.Messages().ListAll(ctx, conv.ID)
+	if err != nil {
+		return bundle, fmt.Errorf("messages: %w", err)
+	}
+	bundle.Messages = msgs
+
+	if contactID := conversationContactID(conv); contactID > 0 && !w.HasContact(contactID) {
+		contact, err := client.Contacts().Get(ctx, contactID)
+		switch {
+		case err == nil:
+			bundle.Contact = contact
+		case !api.IsNotFoundError(err):
+			return bundle, fmt.Errorf("contact %d: %w", contactID, err)
+		}
+	}
+
+	if !attachments {
+		return bundle, nil
+	}
+	for _, msg := range msgs {
+		for _, att := range msg.Attachments {
+			rec := archive.AttachmentRecord{
+				ConversationID: conv.ID,
+				MessageID:      msg.ID,
+				AttachmentID:   att.ID,
+				FileType:       att.FileType,
+				DataURL:        att.DataURL,
+			}
+			stored, err := client.StoreAttachment(ctx, att.DataURL, w.AttachmentsDir(), maxAttachment)
+			if err != nil {
+				if ctx.Err() != nil {
+					return bundle, ctx.Err()
+				}
+				rec.Error = err.Error()
+			} else {
+				rec.SHA256, rec.Path, rec.Name, rec.MIMEType, rec.Bytes = stored.SHA256, stored.Path, stored.Name, stored.MIMEType, stored.Bytes
+			}
+			bundle.Attachments = append(bundle.Attachments, rec)
+		}
+	}
+	return bundle, nil
+}
+
+func countAttachmentFailures(records []archive.AttachmentRecord) int {
+	n := 0
+	for _, r := range records {
+		if r.Error != "" {
+			n++
+		}
+	}
+	return n
+}
+
+func printExportSummary(cmd *cobra.Command, s exportSummary) {
+	out := cmd.OutOrStdout()
+	_, _ = fmt.Fprintf(out, "Exported %d conversations to %s", s.Exported, s.Dir)
+	if s.AlreadyExported > 0 {
+		_, _ = fmt.Fprintf(out, " (%d already exported)", s.AlreadyExported)
+	}
+	_, _ = fmt.Fprintln(out)
+	if s.AttachmentFailures > 0 {
+		_, _ = fmt.Fprintf(out, "%s %d attachments could not be downloaded (see errors in %s)\n", yellow("!"), s.AttachmentFailures, archive.AttachmentsFile)
+	}
+	if s.Manifest == nil {
+		return
+	}
+	w := newTabWriterFromCmd(cmd)
+	_, _ = fmt.Fprintln(w, "FILE\tRECORDS\tBYTES\tSHA256")
+	for _, f := range s.Manifest.Files {
+		_, _ = fmt.Fprintf(w, "%s\t%d\t%d\t%s\n", f.Name, f.Records, f.Bytes, f.SHA256)
+	}
+	if a := s.Manifest.Attachments; a.Files > 0 {
+		_, _ = fmt.Fprintf(w, "%s/\t%d\t%d\t(content-addressed)\n", archive.AttachmentsDir, a.Files, a.Bytes)
+	}
+	_ = w.Flush()
+	_, _ = fmt.Fprintf(out, "Manifest written to %s\n", bold(archive.ManifestFile))
+}
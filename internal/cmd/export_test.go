@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/chatwoot/chatwoot-cli/internal/archive"
+)
+
+func TestExportConversationsResumesAndWritesManifest(t *testing.T) {
+	ago := func(d time.Duration) string { return strconv.FormatInt(time.Now().Add(-d).Unix(), 10) }
+	var failMessages atomic.Bool
+	failMessages.Store(true)
+	var contactFetches atomic.Int32
+
+	setupTestEnvWithHandler(t, newRouteHandler().
+		On("GET", "/api/v1/accounts/1/conversations", func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("inbox_id") != "3" {
+				t.Errorf("expected inbox_id=3, got %q", r.URL.RawQuery)
+			}
+			jsonResponse(200, `{"data": {"meta": {"total_pages": 1}, "payload": [
+				{"id": 1, "inbox_id": 3, "status": "open", "last_activity_at": `+ago(time.Hour)+`, "meta": {"sender": {"id": 7}}},
+				{"id": 2, "inbox_id": 3, "status": "resolved", "last_activity_at": `+ago(48*time.Hour)+`, "meta": {"sender": {"id": 7}}},
+				{"id": 3, "inbox_id": 3, "status": "resolved", "last_activity_at": `+ago(40*24*time.Hour)+`, "meta": {"sender": {"id": 8}}}
+			]}}`)(w, r)
+		}).
+		On("GET", "/api/v1/accounts/1/conversations/1/messages", func(w http.ResponseWriter, r *http.Request) {
+			jsonResponse(200, `{"payload": [
+				{"id": 11, "conversation_id": 1, "content": "see attached", "attachments": [
+					{"id": 5, "file_type": "file", "data_url": "http://`+r.Host+`/files/a.txt"},
+					{"id": 6, "file_type": "file", "data_url": "http://`+r.Host+`/files/missing.txt"}
+				]}
+			]}`)(w, r)
+		}).
+		On("GET", "/api/v1/accounts/1/conversations/2/messages", func(w http.ResponseWriter, r *http.Request) {
+			if failMessages.Load() {
+				jsonResponse(500, `{"error": "boom"}`)(w, r)
+				return
+			}
+			jsonResponse(200, `{"payload": [{"id": 21, "conversation_id": 2, "content": "thanks"}]}`)(w, r)
+		}).
+		On("GET", "/api/v1/accounts/1/contacts/7", func(w http.ResponseWriter, r *http.Request) {
+			contactFetches.Add(1)
+			jsonResponse(200, `{"payload": {"id": 7, "name": "Jane"}}`)(w, r)
+		}).
+		On("GET", "/files/a.txt", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/plain")
+			_, _ = w.Write([]byte("invoice"))
+		}))
+
+	dir := filepath.Join(t.TempDir(), "archive")
+	args := []string{"export", "conversations", "--since", "30d", "--inbox", "3", "--attachments", "--out", dir, "--no-progress", "--concurrency", "1"}
+
+	output := captureStdout(t, func() {
+		err := Execute(context.Background(), args)
+		if err == nil || !strings.Contains(err.Error(), "re-run the same command to resume") {
+			t.Errorf("expected resume hint, got %v", err)
+		}
+	})
+	if !strings.Contains(output, "Exported 1 conversations") {
+		t.Fatalf("unexpected output: %s", output)
+	}
+	if _, err := os.Stat(filepath.Join(dir, archive.ManifestFile)); !os.IsNotExist(err) {
+		t.Fatal("manifest written for an incomplete export")
+	}
+
+	failMessages.Store(false)
+	output = captureStdout(t, func() {
+		if err := Execute(context.Background(), append(args, "-o", "json")); err != nil {
+			t.Errorf("resume failed: %v", err)
+		}
+	})
+	var summary exportSummary
+	if err := json.Unmarshal([]byte(output), &summary); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, output)
+	}
+	if summary.Exported != 1 || summary.AlreadyExported != 1 || summary.Failed != 0 || summary.Manifest == nil {
+		t.Fatalf("unexpected resume summary: %+v", summary)
+	}
+	if n := contactFetches.Load(); n != 1 {
+		t.Fatalf("contact fetched %d times, want 1", n)
+	}
+
+	records := map[string]int{}
+	for _, f := range summary.Manifest.Files {
+		records[f.Name] = f.Records
+	}
+	if records[archive.ConversationsFile] != 2 || records[archive.MessagesFile] != 2 || records[archive.ContactsFile] != 1 || records[archive.AttachmentsFile] != 2 {
+		t.Fatalf("unexpected manifest records: %v", records)
+	}
+	if a := summary.Manifest.Attachments; a.Files != 1 || a.Failed != 1 {
+		t.Fatalf("unexpected attachment stats: %+v", a)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, archive.AttachmentsFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stored archive.AttachmentRecord
+	if err := json.Unmarshal([]byte(strings.SplitN(string(data), "\n", 2)[0]), &stored); err != nil {
+		t.Fatal(err)
+	}
+	if content, err := os.ReadFile(filepath.Join(dir, archive.AttachmentsDir, stored.Path)); err != nil || string(content) != "invoice" {
+		t.Fatalf("stored attachment = %q, %v", content, err)
+	}
+
+	// Different filters must not be appended to this archive.
+	err = Execute(context.Background(), []string{"export", "conversations", "--since", "7d", "--out", dir, "--no-progress"})
+	if err == nil || !strings.Contains(err.Error(), "different filters") {
+		t.Fatalf("expected filter mismatch error, got %v", err)
+	}
+}
+
+func TestExportConversationsValidatesFlags(t *testing.T) {
+	setupTestEnvWithHandler(t, newRouteHandler())
+	tests := map[string][]string{
+		"--out is required":     {"export", "conversations", "--since", "7d"},
+		"invalid --since":       {"export", "conversations", "--since", "someday", "--out", t.TempDir()},
+		"--until must be after": {"export", "conversations", "--since", "2026-09-02", "--until", "2026-09-01", "--out", t.TempDir()},
+	}
+	for want, args := range tests {
+		if err := Execute(context.Background(), args); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%v: got %v, want error containing %q", args, err, want)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/chatwoot/chatwoot-cli/internal/actioncable"
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+)
+
+// followTriggerFunc maps a follow/WebSocket event and its payload to a
+// trigger; ok is false for events the caller ignores.
+type followTriggerFunc[T any] func(event string, data json.RawMessage) (T, bool)
+
+// readFollowTriggers reads `conversations follow -o jsonl` records.
+func readFollowTriggers[T any](ctx context.Context, r io.Reader, toTrigger followTriggerFunc[T], out chan<- T) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 8*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var rec struct {
+			Event string            `json:"event"`
+			Item  json.RawMessage   `json:"item"`
+			Items []json.RawMessage `json:"items"`
+			Data  json.RawMessage   `json:"data"`
+		}
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			continue
+		}
+		var payloads []json.RawMessage
+		event := rec.Event
+		switch {
+		case event == "message.batch":
+			event = "message.created"
+			payloads = rec.Items
+		case len(rec.Item) > 0:
+			payloads = []json.RawMessage{rec.Item}
+		default:
+			payloads = []json.RawMessage{rec.Data}
+		}
+		// A batch triggers its conversation once.
+		for _, p := range payloads {
+			t, ok := toTrigger(event, p)
+			if !ok {
+				continue
+			}
+			select {
+			case out <- t:
+			case <-ctx.Done():
+				return nil
+			}
+			break
+		}
+	}
+	return scanner.Err()
+}
+
+// streamFollowTriggers follows the account's ActionCable stream,
+// reconnecting with backoff until ctx is cancelled. Like conversations
+// follow, each reconnect first backfills the messages created while the
+// socket was down, so no message trigger is lost or repeated.
+func streamFollowTriggers[T any](ctx context.Context, cmd *cobra.Command, client *api.Client, toTrigger followTriggerFunc[T], out chan<- T) error {
+	profile, err := client.Profile().Get(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get profile (needed for WebSocket auth): %w", err)
+	}
+	if profile.PubsubToken == "" {
+		return fmt.Errorf("profile has no pubsub_token; cannot connect to WebSocket")
+	}
+	cableURL := buildCableURL(client.BaseURL)
+	channelID := actioncable.ChannelID{
+		Channel:     "RoomChannel",
+		PubsubToken: profile.PubsubToken,
+		AccountID:   client.AccountID,
+		UserID:      profile.ID,
+	}
+
+	marks := newFollowMarks(0, nil)
+	var gapStart time.Time
+	backoff := followReconnectBackoff
+	for {
+		connectStart := time.Now()
+		live := false
+		err := listenFollowTriggers(ctx, cmd, cableURL, channelID, followTriggerStream[T]{
+			Backfill:  followBackfill{Client: followAPISnapshotClient{client: client}, Since: gapStart},
+			Marks:     marks,
+			OnLive:    func() { live = true },
+			ToTrigger: toTrigger,
+		}, out)
+		if ctx.Err() != nil {
+			return nil
+		}
+		// See the reconnect loop in conversations follow.
+		if live {
+			gapStart = time.Now().Add(-followBackfillSlack)
+		}
+		if time.Since(connectStart) > 60*time.Second {
+			backoff = followReconnectBackoff
+		}
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "disconnected: %v, reconnecting in %s...\n", err, backoff)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return nil
+		}
+		backoff = min(backoff*2, 30*time.Second)
+	}
+}
+
+// followTriggerStream is the per-connection state of streamFollowTriggers.
+type followTriggerStream[T any] struct {
+	Backfill  followBackfill
+	Marks     *followMarks
+	OnLive    func()
+	ToTrigger followTriggerFunc[T]
+}
+
+func listenFollowTriggers[T any](ctx context.Context, cmd *cobra.Command, cableURL string, channelID actioncable.ChannelID, s followTriggerStream[T], out chan<- T) error {
+	conn, err := actioncable.Connect(ctx, cableURL)
+	if err != nil {
+		return fmt.Errorf("connect: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+	if err := conn.Subscribe(ctx, channelID); err != nil {
+		return fmt.Errorf("subscribe: %w", err)
+	}
+	conn.StartPresence(ctx, 30*time.Second, nil)
+	events := conn.Listen(ctx)
+
+	// send reports false once ctx is cancelled.
+	send := func(event string, data json.RawMessage) bool {
+		t, ok := s.ToTrigger(event, data)
+		if !ok {
+			return true
+		}
+		select {
+		case out <- t:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	if !s.Backfill.Since.IsZero() {
+		missed, err := followBackfillMessages(ctx, cmd, s.Backfill, 0, s.Marks)
+		if err != nil {
+			return fmt.Errorf("backfill: %w", err)
+		}
+		for _, m := range missed {
+			if !s.Marks.Mark(m.ConversationID, m.ID) {
+				continue
+			}
+			data, err := json.Marshal(m)
+			if err != nil {
+				continue
+			}
+			if !send("message.created", data) {
+				return nil
+			}
+		}
+	}
+	if s.OnLive != nil {
+		s.OnLive()
+	}
+
+	for ev := range events {
+		if ev.Err != nil {
+			return ev.Err
+		}
+		var wsEvent chatwootWSEvent
+		if err := json.Unmarshal(ev.Data, &wsEvent); err != nil {
+			continue
+		}
+		if wsEvent.Event == "message.created" {
+			var msg struct {
+				ID             int `json:"id"`
+				ConversationID int `json:"conversation_id"`
+			}
+			if json.Unmarshal(wsEvent.Data, &msg) == nil && msg.ID > 0 && !s.Marks.Mark(msg.ConversationID, msg.ID) {
+				continue
+			}
+		}
+		if !send(wsEvent.Event, wsEvent.Data) {
+			return nil
+		}
+	}
+	return fmt.Errorf("event channel closed")
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/chatwoot/chatwoot-cli/internal/outfmt"
+)
+
+func TestStreamFollowTriggersBackfillsAfterReconnect(t *testing.T) {
+	h := newFollowHarness(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	defer func() {
+		cancel()
+		<-done
+	}()
+	cmd, _, _ := newFollowTestCmd(outfmt.Text)
+
+	messageID := func(event string, data json.RawMessage) (int, bool) {
+		var msg struct {
+			ID int `json:"id"`
+		}
+		if event != "message.created" || json.Unmarshal(data, &msg) != nil {
+			return 0, false
+		}
+		return msg.ID, true
+	}
+	out := make(chan int, 64)
+	go func() {
+		defer close(done)
+		_ = streamFollowTriggers(ctx, cmd, h.client, messageID, out)
+	}()
+
+	got := map[int]int{}
+	// receive collects triggers until id arrives or the wait is over.
+	receive := func(id int, wait time.Duration) bool {
+		deadline := time.After(wait)
+		for got[id] == 0 {
+			select {
+			case m := <-out:
+				got[m]++
+			case <-deadline:
+				return false
+			}
+		}
+		return true
+	}
+
+	// Messages sent before the first subscription are not the stream's;
+	// keep sending until one comes through live.
+	for i := 0; ; i++ {
+		if receive(h.send(1, "hello", "incoming"), 200*time.Millisecond) {
+			break
+		}
+		if i == 20 {
+			t.Fatal("stream never went live")
+		}
+	}
+
+	h.cable.setDown(true)
+	missed := []int{h.send(1, "missed one", "incoming"), h.send(3, "missed two", "incoming")}
+	h.cable.setDown(false)
+	for _, id := range missed {
+		if !receive(id, 5*time.Second) {
+			t.Fatalf("message %d missed while disconnected was not backfilled; got %v", id, got)
+		}
+	}
+	if live := h.send(2, "live again", "incoming"); !receive(live, 5*time.Second) {
+		t.Fatalf("live message %d not received after reconnect", live)
+	}
+
+	receive(0, 200*time.Millisecond) // catch any late duplicates
+	for id, n := range got {
+		if n != 1 {
+			t.Errorf("message %d triggered %d times, want once", id, n)
+		}
+	}
+}
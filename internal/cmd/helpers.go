@@ -49,6 +49,11 @@ func getClient() (*api.Client, error) {
 	return newClientFactory().account()
 }
 
+// getProfileClient creates an account API client for a named profile
+func getProfileClient(profile string) (*api.Client, error) {
+	return newClientFactory().profile(profile)
+}
+
 // getPlatformClient creates a platform API client, allowing optional overrides
 func getPlatformClient(baseURLOverride, tokenOverride string) (*api.Client, error) {
 	return newClientFactory().platform(baseURLOverride, tokenOverride)
@@ -74,6 +79,9 @@ func printJSON(cmd *cobra.Command, v any) error {
 	ioStreams := iocontext.GetIO(cmd.Context())
 	query := outfmt.GetQuery(cmd.Context())
 	compact := outfmt.IsCompact(cmd.Context())
+	if outfmt.IsTabular(cmd.Context()) {
+		return outfmt.WriteTabular(cmd.Context(), ioStreams.Out, v)
+	}
 	if outfmt.IsAgent(cmd.Context()) {
 		if payload, ok := v.(agentfmt.Payload); ok {
 			v = payload.AgentPayload()
@@ -157,6 +165,9 @@ func printRawJSON(cmd *cobra.Command, v any) error {
 	query := outfmt.GetQuery(cmd.Context())
 	compact := outfmt.IsCompact(cmd.Context())
 	light := outfmt.IsLight(cmd.Context())
+	if outfmt.IsTabular(cmd.Context()) {
+		return outfmt.WriteTabular(cmd.Context(), ioStreams.Out, v)
+	}
 	if light && !compact && !flagOrAliasChanged(cmd, "compact-json") {
 		compact = true
 	}
@@ -184,6 +195,13 @@ func isJSON(cmd *cobra.Command) bool {
 	return outfmt.IsJSON(cmd.Context())
 }
 
+// isStructuredOutput checks if the command context wants JSON, CSV or XLSX
+// output. Commands that support tables use it in place of isJSON: printJSON
+// lays the same data out as a table.
+func isStructuredOutput(cmd *cobra.Command) bool {
+	return isJSON(cmd) || outfmt.IsTabular(cmd.Context())
+}
+
 func isAgent(cmd *cobra.Command) bool {
 	return outfmt.IsAgent(cmd.Context())
 }
@@ -23,6 +23,7 @@ func TestIsJSON(t *testing.T) {
 	}{
 		{"text output", outfmt.Text, false},
 		{"json output", outfmt.JSON, true},
+		{"csv output", outfmt.CSV, false},
 	}
 
 	for _, tt := range tests {
@@ -39,6 +40,31 @@ func TestIsJSON(t *testing.T) {
 	}
 }
 
+func TestIsStructuredOutput(t *testing.T) {
+	tests := []struct {
+		mode     outfmt.Mode
+		expected bool
+	}{
+		{outfmt.Text, false},
+		{outfmt.JSON, true},
+		{outfmt.JSONL, true},
+		{outfmt.Agent, true},
+		{outfmt.CSV, true},
+		{outfmt.XLSX, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode.String(), func(t *testing.T) {
+			cmd := &cobra.Command{}
+			cmd.SetContext(outfmt.WithMode(context.Background(), tt.mode))
+
+			if got := isStructuredOutput(cmd); got != tt.expected {
+				t.Errorf("isStructuredOutput() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
 func TestIsJSON_NoContext(t *testing.T) {
 	cmd := &cobra.Command{}
 	cmd.SetContext(context.Background())
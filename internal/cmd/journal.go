@@ -0,0 +1,289 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+	"github.com/chatwoot/chatwoot-cli/internal/config"
+	"github.com/chatwoot/chatwoot-cli/internal/journal"
+	"github.com/spf13/cobra"
+)
+
+// activeJournal collects the changes the running command makes. Like flags,
+// it is reset at the start of every Execute() call; it is nil when the
+// journal is disabled with CW_JOURNAL=off.
+var activeJournal *journalRecorder
+
+// journalStore opens the mutation journal under the config dir.
+func journalStore() *journal.Store {
+	return journal.NewStore(journal.DefaultPath(config.Dir()))
+}
+
+// journalEnabled reports whether changes should be journaled. Only an
+// explicit false value of CW_JOURNAL turns it off.
+func journalEnabled() bool {
+	value := strings.TrimSpace(os.Getenv("CW_JOURNAL"))
+	return value == "" || parseBoolEnv("CW_JOURNAL")
+}
+
+// journalSaveInterval is how often a running command's new changes are
+// saved, so commands that run until stopped (follow, autopilot, queue run)
+// keep their record without a journal write per change.
+var journalSaveInterval = 30 * time.Second
+
+// journalRecorder gathers one command's changes in memory into operations
+// per account, of at most journal.MaxChanges changes each. They are saved
+// when the command finishes, every journalSaveInterval, and on SIGINT or
+// SIGTERM (see autosave).
+type journalRecorder struct {
+	command string
+	undoOf  string
+	// errOut receives warnings about changes that could not be recorded.
+	errOut io.Writer
+
+	mu    sync.Mutex
+	ops   []*journal.Operation
+	dirty bool
+}
+
+func newJournalRecorder(args []string) *journalRecorder {
+	if !journalEnabled() {
+		return nil
+	}
+	return &journalRecorder{command: commandLine(args)}
+}
+
+// forAccount returns a recorder for the changes a client of the given
+// account makes.
+func (r *journalRecorder) forAccount(baseURL string, accountID int) api.ChangeRecorder {
+	return accountRecorder{r: r, baseURL: baseURL, accountID: accountID}
+}
+
+type accountRecorder struct {
+	r         *journalRecorder
+	baseURL   string
+	accountID int
+}
+
+func (a accountRecorder) RecordChange(ch api.Change) {
+	a.r.mu.Lock()
+	defer a.r.mu.Unlock()
+	a.r.dirty = true
+	for i := len(a.r.ops) - 1; i >= 0; i-- {
+		op := a.r.ops[i]
+		if op.BaseURL != a.baseURL || op.AccountID != a.accountID {
+			continue
+		}
+		if len(op.Changes) < journal.MaxChanges {
+			op.Changes = append(op.Changes, ch)
+			return
+		}
+		break
+	}
+	a.r.ops = append(a.r.ops, &journal.Operation{BaseURL: a.baseURL, AccountID: a.accountID, Changes: []api.Change{ch}})
+}
+
+func (a accountRecorder) SkipChange(resource string, id int, err error) {
+	errOut := a.r.errOut
+	if errOut == nil {
+		errOut = os.Stderr
+	}
+	_, _ = fmt.Fprintf(errOut, "Warning: %s %d was changed but not recorded in the journal, so it cannot be undone: %v\n", resource, id, err)
+}
+
+// save writes any recorded changes not yet in the journal. Commands that
+// changed nothing leave no entry.
+func (r *journalRecorder) save() error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.saveLocked()
+}
+
+// saveLocked adds new operations to the journal and updates the changes of
+// those added by an earlier save. The caller holds r.mu.
+func (r *journalRecorder) saveLocked() error {
+	if !r.dirty {
+		return nil
+	}
+	now := time.Now().UTC()
+	err := journalStore().Update(func(j *journal.Journal) error {
+		for _, op := range r.ops {
+			if op.ID != "" {
+				if stored, err := j.Find(op.ID); err == nil {
+					stored.Changes = op.Changes
+					continue
+				}
+			}
+			op.Command = r.command
+			op.CreatedAt = now
+			op.UndoOf = r.undoOf
+			*op = j.Add(*op)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	r.dirty = false
+	// Full operations are in the journal for good; stop holding them.
+	kept := r.ops[:0]
+	for _, op := range r.ops {
+		if len(op.Changes) < journal.MaxChanges {
+			kept = append(kept, op)
+		}
+	}
+	r.ops = kept
+	return nil
+}
+
+// autosave saves new changes every journalSaveInterval and when the process
+// gets SIGINT or SIGTERM, until the returned stop is called. After saving on
+// a signal it raises the signal again: commands that handle it shut down as
+// usual, and the rest are terminated as they would have been.
+func (r *journalRecorder) autosave() (stop func()) {
+	if r == nil {
+		return func() {}
+	}
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	ticker := time.NewTicker(journalSaveInterval)
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		for {
+			select {
+			case <-ticker.C:
+				// A failed save is retried and reported when the command
+				// finishes.
+				_ = r.save()
+			case sig := <-signals:
+				_ = r.save()
+				signal.Stop(signals)
+				raiseSignal(sig)
+				return
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-finished
+		ticker.Stop()
+		signal.Stop(signals)
+	}
+}
+
+// raiseSignal sends sig to the current process, exiting where that is not
+// supported.
+func raiseSignal(sig os.Signal) {
+	p, err := os.FindProcess(os.Getpid())
+	if err == nil {
+		err = p.Signal(sig)
+	}
+	if err != nil {
+		os.Exit(130)
+	}
+}
+
+// saveJournal saves the command's changes, warning rather than failing the
+// command: the changes themselves have already been made.
+func saveJournal(errOut io.Writer, r *journalRecorder) {
+	if err := r.save(); err != nil {
+		_, _ = fmt.Fprintf(errOut, "Warning: could not record changes in the journal: %v\n", err)
+	}
+}
+
+// commandLine renders args as the user would type them.
+func commandLine(args []string) string {
+	parts := []string{"cw"}
+	for _, arg := range args {
+		if arg == "" || strings.ContainsAny(arg, " \t\n\"'\\") {
+			arg = strconv.Quote(arg)
+		}
+		parts = append(parts, arg)
+	}
+	return strings.Join(parts, " ")
+}
+
+func newHistoryCmd() *cobra.Command {
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:     "history",
+		Aliases: []string{"hist"},
+		Short:   "List recent changes recorded in the journal",
+		Long: strings.TrimSpace(`
+List the operations recorded in the local mutation journal for the current
+account, newest first.
+
+Commands that change a conversation's status, priority, assignee, team,
+labels or custom attributes, or a contact's fields or labels, record the
+values from just before the change. Undo an operation with 'cw undo'.
+`),
+		Example: strings.TrimSpace(`
+  # Recent operations
+  cw history
+
+  # Everything, as JSON
+  cw history --limit 0 -o json
+`),
+		Args: cobra.NoArgs,
+		RunE: RunE(func(cmd *cobra.Command, _ []string) error {
+			if limit < 0 {
+				return fmt.Errorf("--limit must be >= 0")
+			}
+			client, err := getClient()
+			if err != nil {
+				return err
+			}
+			j, err := journalStore().Load()
+			if err != nil {
+				return err
+			}
+			ops := j.Recent(client.BaseURL, client.AccountID, limit)
+
+			if isJSON(cmd) {
+				return printJSON(cmd, ops)
+			}
+			if len(ops) == 0 {
+				_, _ = fmt.Fprintln(cmd.OutOrStdout(), "No recorded operations")
+				return nil
+			}
+			w := newTabWriterFromCmd(cmd)
+			defer func() { _ = w.Flush() }()
+			_, _ = fmt.Fprintln(w, "ID\tTIME\tCHANGES\tSTATE\tCOMMAND")
+			for _, op := range ops {
+				state := ""
+				switch {
+				case op.Undone():
+					state = "undone by " + op.UndoneBy
+				case op.UndoOf != "":
+					state = "undo of " + op.UndoOf
+				}
+				command := op.Command
+				if len([]rune(command)) > 60 {
+					command = string([]rune(command)[:57]) + "..."
+				}
+				_, _ = fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n", op.ID, formatTimestamp(op.CreatedAt), len(op.Changes), state, command)
+			}
+			return nil
+		}),
+	}
+
+	cmd.Flags().IntVar(&limit, "limit", 20, "Maximum operations to list (0 = all)")
+	flagAlias(cmd.Flags(), "limit", "lt")
+	return cmd
+}
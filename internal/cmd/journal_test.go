@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+	"github.com/chatwoot/chatwoot-cli/internal/journal"
+)
+
+func TestJournalDisabled(t *testing.T) {
+	run := setupJournalTest(t)
+	t.Setenv("CW_JOURNAL", "off")
+
+	if _, err := run("close", "1"); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if _, err := os.Stat(journal.DefaultPath(os.Getenv("CW_CONFIG_DIR"))); !os.IsNotExist(err) {
+		t.Errorf("journal file written with CW_JOURNAL=off: %v", err)
+	}
+	out, err := run("history")
+	if err != nil || !strings.Contains(out, "No recorded operations") {
+		t.Errorf("history = %q, %v", out, err)
+	}
+}
+
+func TestCommandLine(t *testing.T) {
+	got := commandLine([]string{"comment", "1", "hello world", "--private", ""})
+	want := `cw comment 1 "hello world" --private ""`
+	if got != want {
+		t.Errorf("commandLine = %s, want %s", got, want)
+	}
+}
+
+func TestJournalRecorderSavesInBatches(t *testing.T) {
+	t.Setenv("CW_CONFIG_DIR", t.TempDir())
+	t.Setenv("CW_JOURNAL", "")
+	r := newJournalRecorder([]string{"autopilot", "run"})
+	rec := r.forAccount("https://chat.example.com", 1)
+	change := func(id int) api.Change {
+		return api.Change{Resource: api.ResourceConversation, ID: id, Before: map[string]any{"status": "open"}, After: map[string]any{"status": "resolved"}}
+	}
+	operations := func() []journal.Operation {
+		t.Helper()
+		j, err := journalStore().Load()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return j.Operations
+	}
+
+	// Recording a change does not touch the journal file.
+	rec.RecordChange(change(7))
+	if ops := operations(); len(ops) != 0 {
+		t.Fatalf("journal written per change: %+v", ops)
+	}
+	if err := r.save(); err != nil {
+		t.Fatal(err)
+	}
+	rec.RecordChange(change(8))
+	if err := r.save(); err != nil {
+		t.Fatal(err)
+	}
+	if ops := operations(); len(ops) != 1 || len(ops[0].Changes) != 2 || ops[0].Command != "cw autopilot run" {
+		t.Fatalf("after two saves: journal = %+v", ops)
+	}
+
+	// An operation holds at most journal.MaxChanges changes; later ones
+	// roll over into a new operation, and full ones are let go once saved.
+	for id := 9; id < journal.MaxChanges+13; id++ {
+		rec.RecordChange(change(id))
+	}
+	if err := r.save(); err != nil {
+		t.Fatal(err)
+	}
+	ops := operations()
+	if len(ops) != 2 || len(ops[0].Changes) != journal.MaxChanges || len(ops[1].Changes) != 6 {
+		t.Fatalf("after rollover: %d operations", len(ops))
+	}
+	if len(r.ops) != 1 || r.ops[0].ID != ops[1].ID {
+		t.Fatalf("recorder still holds %d operations", len(r.ops))
+	}
+}
+
+func TestJournalAutosave(t *testing.T) {
+	t.Setenv("CW_CONFIG_DIR", t.TempDir())
+	t.Setenv("CW_JOURNAL", "")
+	interval := journalSaveInterval
+	journalSaveInterval = 10 * time.Millisecond
+	t.Cleanup(func() { journalSaveInterval = interval })
+
+	r := newJournalRecorder([]string{"conversations", "follow"})
+	stop := r.autosave()
+	defer stop()
+	r.forAccount("https://chat.example.com", 1).RecordChange(api.Change{Resource: api.ResourceConversation, ID: 7, After: map[string]any{"status": "resolved"}})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		j, err := journalStore().Load()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(j.Operations) == 1 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("change was not saved on the timer")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -52,7 +52,7 @@ type ListConfig[T any] struct {
 	MinLimit int
 	// DefaultMaxPages overrides the default max-pages flag value (defaults to 100).
 	DefaultMaxPages int
-	// AfterOutput runs after table output (text mode only).
+	// AfterOutput runs after table output (text mode only; not CSV/XLSX).
 	AfterOutput func(cmd *cobra.Command, summary ListSummary) error
 	// AgentTransform overrides agent-mode item transformation.
 	AgentTransform func(ctx context.Context, client *api.Client, items []T) (any, error)
@@ -172,6 +172,10 @@ func NewListCommand[T any](cfg ListConfig[T], getClient func(context.Context) (*
 
 			ioStreams := iocontext.GetIO(ctx)
 			f := outfmt.NewFormatter(ctx, ioStreams.Out, ioStreams.ErrOut)
+			// CSV/XLSX use the table columns, unless --fields picks columns
+			// from the JSON items instead.
+			tabular := outfmt.IsTabular(ctx)
+			fieldColumns := tabular && len(outfmt.GetFields(ctx)) > 0
 
 			if cfg.DisablePagination || !all {
 				result, err := cfg.Fetch(ctx, client, page, pageSize)
@@ -191,7 +195,7 @@ func NewListCommand[T any](cfg ListConfig[T], getClient func(context.Context) (*
 					return nil
 				}
 
-				if mode == outfmt.JSON || mode == outfmt.Agent {
+				if mode == outfmt.JSON || mode == outfmt.Agent || fieldColumns {
 					summaryPageSize := pageSize
 					if cfg.DisablePagination {
 						summaryPageSize = len(result.Items)
@@ -251,7 +255,7 @@ func NewListCommand[T any](cfg ListConfig[T], getClient func(context.Context) (*
 					return f.Output(payload)
 				}
 
-				if len(result.Items) == 0 {
+				if len(result.Items) == 0 && !tabular {
 					if cfg.EmptyMessage != "" {
 						f.Empty(cfg.EmptyMessage)
 					}
@@ -266,7 +270,7 @@ func NewListCommand[T any](cfg ListConfig[T], getClient func(context.Context) (*
 					return err
 				}
 
-				if cfg.AfterOutput != nil {
+				if cfg.AfterOutput != nil && !tabular {
 					if err := cfg.AfterOutput(cmd, ListSummary{
 						Page:         page,
 						PageSize:     pageSize,
@@ -335,7 +339,7 @@ func NewListCommand[T any](cfg ListConfig[T], getClient func(context.Context) (*
 				return nil
 			}
 
-			if mode == outfmt.Text {
+			if mode == outfmt.Text || (tabular && !fieldColumns) {
 				currentPage := page
 				pagesFetched := 0
 				totalItems := 0
@@ -388,17 +392,20 @@ func NewListCommand[T any](cfg ListConfig[T], getClient func(context.Context) (*
 				}
 
 				if !started {
-					if cfg.EmptyMessage != "" {
-						f.Empty(cfg.EmptyMessage)
+					if !tabular {
+						if cfg.EmptyMessage != "" {
+							f.Empty(cfg.EmptyMessage)
+						}
+						return nil
 					}
-					return nil
+					f.StartTable(cfg.Headers)
 				}
 
 				if err := f.EndTable(); err != nil {
 					return err
 				}
 
-				if cfg.AfterOutput != nil {
+				if cfg.AfterOutput != nil && !tabular {
 					return cfg.AfterOutput(cmd, ListSummary{
 						Page:         page,
 						PageSize:     pageSize,
@@ -558,3 +558,78 @@ func TestListCommand_AllPagesBreaksOnExcessiveEmptyPages(t *testing.T) {
 type ioDiscard struct{}
 
 func (ioDiscard) Write(p []byte) (int, error) { return len(p), nil }
+
+func TestListCommand_CSVOutput(t *testing.T) {
+	cfg := ListConfig[testItem]{
+		Use:     "list",
+		Short:   "List items",
+		Headers: []string{"ID", "NAME"},
+		RowFunc: func(item testItem) []string { return []string{fmt.Sprintf("%d", item.ID), item.Name} },
+		Fetch: func(ctx context.Context, client *api.Client, page, pageSize int) (ListResult[testItem], error) {
+			if page > 1 {
+				return ListResult[testItem]{}, nil
+			}
+			return ListResult[testItem]{Items: []testItem{{ID: 1, Name: "a, b"}, {ID: 2, Name: "c"}}, HasMore: true}, nil
+		},
+		AfterOutput: func(cmd *cobra.Command, _ ListSummary) error {
+			_, _ = fmt.Fprintln(iocontext.GetIO(cmd.Context()).Out, "footer")
+			return nil
+		},
+	}
+
+	run := func(t *testing.T, ctx context.Context, args ...string) string {
+		t.Helper()
+		cmd := NewListCommand(cfg, func(ctx context.Context) (*api.Client, error) { return nil, nil })
+		var out bytes.Buffer
+		cmd.SetContext(iocontext.WithIO(ctx, &iocontext.IO{Out: &out, ErrOut: ioDiscard{}, In: nil}))
+		if err := cmd.ParseFlags(args); err != nil {
+			t.Fatal(err)
+		}
+		if err := cmd.RunE(cmd, []string{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return out.String()
+	}
+
+	ctx := outfmt.WithMode(context.Background(), outfmt.CSV)
+	want := "ID,NAME\n1,\"a, b\"\n2,c\n"
+	if got := run(t, ctx); got != want {
+		t.Errorf("table columns: got %q, want %q", got, want)
+	}
+	if got := run(t, ctx, "--all"); got != want {
+		t.Errorf("table columns with --all: got %q, want %q", got, want)
+	}
+
+	ctx = outfmt.WithFields(ctx, []string{"Name"})
+	want = "Name\n\"a, b\"\nc\n"
+	if got := run(t, ctx); got != want {
+		t.Errorf("--fields columns: got %q, want %q", got, want)
+	}
+	if got := run(t, ctx, "--all"); got != want {
+		t.Errorf("--fields columns with --all: got %q, want %q", got, want)
+	}
+}
+
+func TestListCommand_CSVOutputEmpty(t *testing.T) {
+	cfg := ListConfig[testItem]{
+		Use:          "list",
+		Short:        "List items",
+		Headers:      []string{"ID", "NAME"},
+		RowFunc:      func(item testItem) []string { return []string{fmt.Sprintf("%d", item.ID), item.Name} },
+		EmptyMessage: "No items found",
+		Fetch: func(ctx context.Context, client *api.Client, page, pageSize int) (ListResult[testItem], error) {
+			return ListResult[testItem]{}, nil
+		},
+	}
+	cmd := NewListCommand(cfg, func(ctx context.Context) (*api.Client, error) { return nil, nil })
+
+	var out bytes.Buffer
+	ctx := outfmt.WithMode(context.Background(), outfmt.CSV)
+	cmd.SetContext(iocontext.WithIO(ctx, &iocontext.IO{Out: &out, ErrOut: ioDiscard{}, In: nil}))
+	if err := cmd.RunE(cmd, []string{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.String() != "ID,NAME\n" {
+		t.Errorf("expected header-only CSV, got %q", out.String())
+	}
+}
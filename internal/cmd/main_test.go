@@ -11,11 +11,18 @@ import (
 func TestMain(m *testing.M) {
 	// Ensure tests use text output by default (prevents CHATWOOT_OUTPUT=agent from shell affecting tests)
 	_ = os.Setenv("CHATWOOT_OUTPUT", "text")
+	// Keep local state such as the journal out of the user's config dir.
+	configDir, err := os.MkdirTemp("", "cw-test-config-*")
+	if err != nil {
+		panic(err)
+	}
+	_ = os.Setenv("CW_CONFIG_DIR", configDir)
 
 	cleanup := config.SetOpenKeyring(func(cfg keyring.Config) (keyring.Keyring, error) {
 		return keyring.NewArrayKeyring(nil), nil
 	})
 	code := m.Run()
 	cleanup()
+	_ = os.RemoveAll(configDir)
 	os.Exit(code)
 }
@@ -0,0 +1,509 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/chatwoot/chatwoot-cli/internal/dryrun"
+	"github.com/chatwoot/chatwoot-cli/internal/iocontext"
+	"github.com/chatwoot/chatwoot-cli/internal/mcp"
+	"github.com/chatwoot/chatwoot-cli/internal/schema"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// mcpToolSpec maps an MCP tool to the command it runs.
+type mcpToolSpec struct {
+	name     string
+	path     string // command path below the root
+	resource string // schema registry resource the tool returns, if any
+}
+
+// mcpToolSpecs are the commands exposed as MCP tools. Whether a tool mutates
+// and supports dry-run comes from the command's contract.
+var mcpToolSpecs = []mcpToolSpec{
+	{name: "conversations_list", path: "conversations list", resource: "conversation"},
+	{name: "conversations_get", path: "conversations get", resource: "conversation"},
+	{name: "conversations_search", path: "conversations search", resource: "conversation"},
+	{name: "messages_list", path: "messages list", resource: "message"},
+	{name: "messages_create", path: "messages create", resource: "message"},
+	{name: "contacts_list", path: "contacts list", resource: "contact"},
+	{name: "contacts_get", path: "contacts get", resource: "contact"},
+	{name: "contacts_search", path: "contacts search", resource: "contact"},
+	{name: "search", path: "search"},
+	{name: "context", path: "ctx"},
+	{name: "assign", path: "assign"},
+	{name: "label", path: "conversations labels-add"},
+	{name: "snooze", path: "snooze"},
+	{name: "note", path: "note"},
+}
+
+// mcpSkippedFlags are command flags that make no sense over MCP: prompts,
+// progress bars, stdin, local files, and alternative output shapes.
+var mcpSkippedFlags = map[string]bool{
+	"force":       true,
+	"progress":    true,
+	"no-progress": true,
+	"stdin":       true,
+	"select":      true,
+	"select-raw":  true,
+	"url":         true,
+	"emit":        true,
+	"transcript":  true,
+	"attachment":  true,
+}
+
+func newMCPCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "mcp",
+		Short: "Model Context Protocol server for AI agents",
+	}
+	cmd.AddCommand(newMCPServeCmd())
+	return cmd
+}
+
+func newMCPServeCmd() *cobra.Command {
+	var allow []string
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve CLI operations as MCP tools over stdio",
+		Long: strings.TrimSpace(`
+Speak the Model Context Protocol on stdin/stdout so agents can call CLI
+operations as typed tools instead of shelling out and parsing text.
+
+Tools cover conversations, messages, contacts, search, context, assign,
+label, snooze and note. Their parameters come from the commands' flags and
+arguments, and every call runs the command in-process with JSON output
+against the active profile (set CHATWOOT_PROFILE to pin one).
+
+Read-only tools are always available. Mutating tools are only offered when
+allowlisted with --allow (or CHATWOOT_MCP_ALLOW); use --allow all to offer
+every one. Mutating tools take a dry_run parameter to preview a change, and
+with the global --dry-run every mutating call is a preview.
+
+Logs and errors go to stderr; stdout carries only protocol messages.
+`),
+		Example: strings.TrimSpace(`
+  # Read-only tools
+  cw mcp serve
+
+  # Also let the agent reply, add notes and labels
+  cw mcp serve --allow messages_create,note,label
+
+  # Every tool, but mutations are only previewed
+  cw mcp serve --allow all --dry-run
+
+  # MCP client configuration
+  {"mcpServers": {"chatwoot": {"command": "cw", "args": ["mcp", "serve", "--allow", "note"]}}}
+`),
+		Args: cobra.NoArgs,
+		RunE: RunE(func(cmd *cobra.Command, _ []string) error {
+			if !cmd.Flags().Changed("allow") {
+				if env := strings.TrimSpace(os.Getenv("CHATWOOT_MCP_ALLOW")); env != "" {
+					allow = strings.Split(env, ",")
+				}
+			}
+			ctx := cmdContext(cmd)
+			handler, err := newMCPHandler(cmd.Root(), allow, dryrun.IsEnabled(ctx))
+			if err != nil {
+				return err
+			}
+			server := &mcp.Server{
+				Name:         "chatwoot-cli",
+				Version:      version,
+				Instructions: handler.instructions(),
+				Handler:      handler,
+			}
+			ioStreams := iocontext.GetIO(ctx)
+			return server.Serve(ctx, ioStreams.In, ioStreams.Out)
+		}),
+	}
+
+	cmd.Flags().StringSliceVar(&allow, "allow", nil, "Mutating tools to offer (comma-separated or repeatable; 'all' for every one; env CHATWOOT_MCP_ALLOW)")
+	flagAlias(cmd.Flags(), "allow", "alw")
+
+	return cmd
+}
+
+// mcpParam maps a tool input property to a flag or positional argument.
+type mcpParam struct {
+	property string
+	flag     string // flag name, or "" for a positional argument
+	array    bool
+	required bool
+}
+
+type mcpTool struct {
+	spec     mcpToolSpec
+	tool     mcp.Tool
+	params   []mcpParam
+	mutates  bool
+	dryRun   bool // supports dry-run
+	disabled bool // mutating and not allowlisted
+}
+
+// mcpHandler serves the MCP tools by running their commands in-process.
+// Calls are handled one at a time, as commands share global flag state.
+type mcpHandler struct {
+	tools       []*mcpTool
+	byName      map[string]*mcpTool
+	previewOnly bool
+}
+
+func newMCPHandler(root *cobra.Command, allow []string, previewOnly bool) (*mcpHandler, error) {
+	h := &mcpHandler{byName: map[string]*mcpTool{}, previewOnly: previewOnly}
+	for _, spec := range mcpToolSpecs {
+		c, _, err := root.Find(strings.Fields(spec.path))
+		if err != nil || c == root {
+			return nil, fmt.Errorf("mcp tool %s: command %q not found", spec.name, spec.path)
+		}
+		t := buildMCPTool(spec, c)
+		h.tools = append(h.tools, t)
+		h.byName[spec.name] = t
+	}
+
+	allowed := map[string]bool{}
+	all := false
+	for _, name := range allow {
+		name = strings.TrimSpace(name)
+		switch {
+		case name == "":
+		case name == "all" || name == "*":
+			all = true
+		case h.byName[name] == nil:
+			return nil, fmt.Errorf("--allow: unknown tool %q (tools: %s)", name, strings.Join(h.toolNames(), ", "))
+		default:
+			allowed[name] = true
+		}
+	}
+	for _, t := range h.tools {
+		if t.mutates && !all && !allowed[t.spec.name] {
+			// Under server-wide dry-run nothing can change, so every tool
+			// that can preview is safe to offer.
+			t.disabled = !(previewOnly && t.dryRun)
+		}
+	}
+	return h, nil
+}
+
+func (h *mcpHandler) toolNames() []string {
+	names := make([]string, 0, len(h.tools))
+	for _, t := range h.tools {
+		names = append(names, t.spec.name)
+	}
+	return names
+}
+
+func (h *mcpHandler) instructions() string {
+	var b strings.Builder
+	b.WriteString("Chatwoot support tools. Each tool runs a chatwoot-cli command and returns its JSON output. ")
+	b.WriteString("Conversation and contact arguments accept IDs or Chatwoot URLs; agents, teams and inboxes accept IDs or names.")
+	var mutating []string
+	for _, t := range h.tools {
+		if t.mutates && !t.disabled {
+			mutating = append(mutating, t.spec.name)
+		}
+	}
+	switch {
+	case len(mutating) == 0:
+		b.WriteString(" This server is read-only.")
+	case h.previewOnly:
+		fmt.Fprintf(&b, " Changes are previewed only (dry-run): %s.", strings.Join(mutating, ", "))
+	default:
+		fmt.Fprintf(&b, " These tools change data: %s; pass dry_run to preview first.", strings.Join(mutating, ", "))
+	}
+	return b.String()
+}
+
+// ListTools implements mcp.Handler.
+func (h *mcpHandler) ListTools(context.Context) []mcp.Tool {
+	tools := make([]mcp.Tool, 0, len(h.tools))
+	for _, t := range h.tools {
+		if !t.disabled {
+			tools = append(tools, t.tool)
+		}
+	}
+	return tools
+}
+
+// CallTool implements mcp.Handler.
+func (h *mcpHandler) CallTool(ctx context.Context, name string, arguments json.RawMessage) (*mcp.CallResult, error) {
+	t, ok := h.byName[name]
+	if !ok {
+		return nil, mcp.ErrUnknownTool
+	}
+	if t.disabled {
+		return mcp.ErrorResult("%s changes data and is not allowlisted; restart the server with --allow %s", name, name), nil
+	}
+
+	args := map[string]any{}
+	if len(bytes.TrimSpace(arguments)) > 0 && string(bytes.TrimSpace(arguments)) != "null" {
+		dec := json.NewDecoder(bytes.NewReader(arguments))
+		dec.UseNumber()
+		if err := dec.Decode(&args); err != nil {
+			return mcp.ErrorResult("arguments must be an object: %v", err), nil
+		}
+	}
+
+	preview := h.previewOnly
+	if v, ok := args["dry_run"]; ok && t.dryRun {
+		b, err := mcpBool(v)
+		if err != nil {
+			return mcp.ErrorResult("dry_run: %v", err), nil
+		}
+		preview = preview || b
+		delete(args, "dry_run")
+	}
+	if preview && t.mutates && !t.dryRun {
+		return mcp.ErrorResult("%s cannot preview changes, and this server only previews", name), nil
+	}
+
+	argv, err := t.argv(args, preview)
+	if err != nil {
+		return mcp.ErrorResult("%v", err), nil
+	}
+	return runMCPCommand(ctx, argv), nil
+}
+
+// argv builds the command line for a call. Positional arguments follow "--"
+// so values starting with "-" are never read as flags.
+func (t *mcpTool) argv(args map[string]any, preview bool) ([]string, error) {
+	for property := range args {
+		if !t.hasParam(property) {
+			return nil, fmt.Errorf("unknown argument %q", property)
+		}
+	}
+
+	argv := strings.Fields(t.spec.path)
+	var positional []string
+	for _, p := range t.params {
+		v, ok := args[p.property]
+		if !ok || v == nil {
+			if p.required {
+				return nil, fmt.Errorf("missing required argument %q", p.property)
+			}
+			continue
+		}
+		values, err := mcpValues(v, p.array)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", p.property, err)
+		}
+		for _, value := range values {
+			// Flags read @path and @- values from local files and stdin;
+			// an MCP client must not reach either.
+			if strings.HasPrefix(strings.TrimSpace(value), "@") {
+				return nil, fmt.Errorf("%s: values starting with @ read local files and are not accepted over MCP", p.property)
+			}
+			if p.flag == "" {
+				positional = append(positional, value)
+			} else {
+				argv = append(argv, "--"+p.flag+"="+value)
+			}
+		}
+	}
+
+	argv = append(argv, "--output", "json", "--no-input")
+	if t.mutates {
+		argv = append(argv, "--yes")
+	}
+	if preview {
+		argv = append(argv, "--dry-run")
+	}
+	if len(positional) > 0 {
+		argv = append(argv, "--")
+		argv = append(argv, positional...)
+	}
+	return argv, nil
+}
+
+func (t *mcpTool) hasParam(property string) bool {
+	for _, p := range t.params {
+		if p.property == property {
+			return true
+		}
+	}
+	return false
+}
+
+func runMCPCommand(ctx context.Context, argv []string) *mcp.CallResult {
+	var stdout, stderr bytes.Buffer
+	ctx = iocontext.WithIO(ctx, &iocontext.IO{Out: &stdout, ErrOut: &stderr, In: strings.NewReader("")})
+	if err := Execute(ctx, argv); err != nil {
+		// JSON-mode commands write structured errors to stderr.
+		if msg := strings.TrimSpace(stderr.String()); msg != "" && json.Valid([]byte(msg)) {
+			return mcp.ErrorResult("%s", msg)
+		}
+		return mcp.ErrorResult("%v", err)
+	}
+
+	text := strings.TrimSpace(stdout.String())
+	result := mcp.TextResult(text)
+	var structured map[string]any
+	if strings.HasPrefix(text, "{") && json.Unmarshal([]byte(text), &structured) == nil {
+		result.StructuredContent = structured
+	}
+	return result
+}
+
+func buildMCPTool(spec mcpToolSpec, c *cobra.Command) *mcpTool {
+	t := &mcpTool{
+		spec:    spec,
+		mutates: commandMutates(c),
+		dryRun:  commandSupportsDryRun(c),
+	}
+	props := map[string]*schema.Schema{}
+	var required []string
+
+	c.LocalFlags().VisitAll(func(f *pflag.Flag) {
+		if f.Hidden || f.Name == "help" || mcpSkippedFlags[f.Name] {
+			return
+		}
+		property := mcpPropertyName(f.Name)
+		s, array := mcpFlagSchema(f)
+		props[property] = s
+		p := mcpParam{property: property, flag: f.Name, array: array, required: flagIsRequired(f)}
+		if p.required {
+			required = append(required, property)
+		}
+		t.params = append(t.params, p)
+	})
+
+	// A positional argument that duplicates a flag (contacts search's query)
+	// is taken through the flag.
+	for _, arg := range argsForCommand(c) {
+		name, _, _ := strings.Cut(arg.Name, "|")
+		property := mcpPropertyName(name)
+		if _, dup := props[property]; dup {
+			continue
+		}
+		desc := strings.ReplaceAll(arg.Name, "|", " or ")
+		if arg.Variadic {
+			props[property] = schema.Array(schema.String(""), desc)
+		} else {
+			props[property] = schema.String(desc)
+		}
+		if arg.Required {
+			required = append(required, property)
+		}
+		t.params = append(t.params, mcpParam{property: property, array: arg.Variadic, required: arg.Required})
+	}
+
+	if t.dryRun {
+		props["dry_run"] = schema.Bool("Preview the change without making it")
+	}
+
+	sort.Strings(required)
+	t.tool = mcp.Tool{
+		Name:        spec.name,
+		Title:       c.Short,
+		Description: mcpToolDescription(spec, c, t.mutates),
+		InputSchema: schema.Object("", props, required...),
+		Annotations: &mcp.ToolAnnotations{ReadOnlyHint: !t.mutates},
+	}
+	return t
+}
+
+func mcpToolDescription(spec mcpToolSpec, c *cobra.Command, mutates bool) string {
+	parts := []string{c.Short + "."}
+	if long := strings.TrimSpace(c.Long); long != "" && long != c.Short {
+		parts = append(parts, long)
+	}
+	if spec.resource != "" {
+		if s, err := schema.Get(spec.resource); err == nil && len(s.Properties) > 0 {
+			fields := make([]string, 0, len(s.Properties))
+			for name := range s.Properties {
+				fields = append(fields, name)
+			}
+			sort.Strings(fields)
+			parts = append(parts, fmt.Sprintf("Returns %s data (fields: %s).", spec.resource, strings.Join(fields, ", ")))
+		}
+	}
+	if mutates {
+		parts = append(parts, "Changes data in Chatwoot.")
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+func mcpPropertyName(name string) string {
+	return strings.ReplaceAll(name, "-", "_")
+}
+
+// mcpFlagSchema types a flag for a tool's input schema, reporting whether it
+// takes a list.
+func mcpFlagSchema(f *pflag.Flag) (*schema.Schema, bool) {
+	desc := f.Usage
+	switch f.DefValue {
+	case "", "false", "0", "[]":
+	default:
+		desc += fmt.Sprintf(" (default %s)", f.DefValue)
+	}
+	switch f.Value.Type() {
+	case "bool":
+		return schema.Bool(desc), false
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64":
+		return schema.Int(desc), false
+	case "float32", "float64":
+		return &schema.Schema{Type: "number", Description: desc}, false
+	case "stringSlice", "stringArray":
+		return schema.Array(schema.String(""), desc), true
+	case "intSlice", "int64Slice", "uintSlice":
+		return schema.Array(schema.Int(""), desc), true
+	default:
+		return schema.String(desc), false
+	}
+}
+
+// mcpValues converts an argument to command-line values. Lists are only
+// accepted for list parameters, where a single value is also fine.
+func mcpValues(v any, array bool) ([]string, error) {
+	if list, ok := v.([]any); ok {
+		if !array {
+			return nil, fmt.Errorf("expected a single value, got a list")
+		}
+		values := make([]string, 0, len(list))
+		for _, item := range list {
+			s, err := mcpScalar(item)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, s)
+		}
+		return values, nil
+	}
+	s, err := mcpScalar(v)
+	if err != nil {
+		return nil, err
+	}
+	return []string{s}, nil
+}
+
+func mcpScalar(v any) (string, error) {
+	switch val := v.(type) {
+	case string:
+		return val, nil
+	case json.Number:
+		return val.String(), nil
+	case bool:
+		return strconv.FormatBool(val), nil
+	default:
+		return "", fmt.Errorf("expected a string, number or boolean")
+	}
+}
+
+func mcpBool(v any) (bool, error) {
+	switch val := v.(type) {
+	case bool:
+		return val, nil
+	case string:
+		return strconv.ParseBool(val)
+	default:
+		return false, fmt.Errorf("expected a boolean")
+	}
+}
@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/chatwoot/chatwoot-cli/internal/iocontext"
+)
+
+// runMCPSession runs `cw mcp serve` with the given requests on stdin and
+// returns the decoded responses.
+func runMCPSession(t *testing.T, args []string, requests ...string) []map[string]any {
+	t.Helper()
+	var out, errOut bytes.Buffer
+	ctx := iocontext.WithIO(context.Background(), &iocontext.IO{
+		In:     strings.NewReader(strings.Join(requests, "\n") + "\n"),
+		Out:    &out,
+		ErrOut: &errOut,
+	})
+	if err := Execute(ctx, append([]string{"mcp", "serve"}, args...)); err != nil {
+		t.Fatalf("mcp serve failed: %v (stderr: %s)", err, errOut.String())
+	}
+	var responses []map[string]any
+	dec := json.NewDecoder(&out)
+	for dec.More() {
+		var resp map[string]any
+		if err := dec.Decode(&resp); err != nil {
+			t.Fatalf("invalid response: %v\n%s", err, out.String())
+		}
+		responses = append(responses, resp)
+	}
+	return responses
+}
+
+func mcpCall(id int, tool string, arguments string) string {
+	return fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":"tools/call","params":{"name":%q,"arguments":%s}}`, id, tool, arguments)
+}
+
+func mcpToolsByName(t *testing.T, resp map[string]any) map[string]map[string]any {
+	t.Helper()
+	result, ok := resp["result"].(map[string]any)
+	if !ok {
+		t.Fatalf("tools/list failed: %v", resp)
+	}
+	tools := map[string]map[string]any{}
+	for _, raw := range result["tools"].([]any) {
+		tool := raw.(map[string]any)
+		tools[tool["name"].(string)] = tool
+	}
+	return tools
+}
+
+func mcpResult(t *testing.T, resp map[string]any) (string, map[string]any) {
+	t.Helper()
+	result, ok := resp["result"].(map[string]any)
+	if !ok {
+		t.Fatalf("tools/call failed: %v", resp)
+	}
+	text := result["content"].([]any)[0].(map[string]any)["text"].(string)
+	if result["isError"] == true {
+		return "", map[string]any{"error": text}
+	}
+	structured, _ := result["structuredContent"].(map[string]any)
+	return text, structured
+}
+
+func TestMCPServe_ListsTypedTools(t *testing.T) {
+	setupTestEnvWithHandler(t, newRouteHandler())
+
+	list := `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`
+	tools := mcpToolsByName(t, runMCPSession(t, nil, list)[0])
+
+	if _, ok := tools["note"]; ok {
+		t.Error("mutating tools should not be listed without --allow")
+	}
+	convList, ok := tools["conversations_list"]
+	if !ok {
+		t.Fatalf("conversations_list missing from %v", tools)
+	}
+	props := convList["inputSchema"].(map[string]any)["properties"].(map[string]any)
+	if props["status"].(map[string]any)["type"] != "string" || props["page"].(map[string]any)["type"] != "integer" || props["all"].(map[string]any)["type"] != "boolean" {
+		t.Errorf("unexpected property types: %v", props)
+	}
+	if !strings.Contains(convList["description"].(string), "Returns conversation data") {
+		t.Errorf("description should describe the returned resource: %s", convList["description"])
+	}
+	if convList["annotations"].(map[string]any)["readOnlyHint"] != true {
+		t.Error("conversations_list should be read-only")
+	}
+
+	tools = mcpToolsByName(t, runMCPSession(t, []string{"--allow", "note,snooze"}, list)[0])
+	note, ok := tools["note"]
+	if !ok {
+		t.Fatal("note should be listed when allowlisted")
+	}
+	schema := note["inputSchema"].(map[string]any)
+	props = schema["properties"].(map[string]any)
+	for _, name := range []string{"conversation_id", "text", "dry_run", "mention"} {
+		if _, ok := props[name]; !ok {
+			t.Errorf("note schema missing %q: %v", name, props)
+		}
+	}
+	if props["text"].(map[string]any)["type"] != "array" {
+		t.Errorf("variadic text should be an array: %v", props["text"])
+	}
+	if fmt.Sprint(schema["required"]) != "[conversation_id]" {
+		t.Errorf("required = %v", schema["required"])
+	}
+	if snooze := tools["snooze"]["inputSchema"].(map[string]any); fmt.Sprint(snooze["required"]) != "[conversation_id for]" {
+		t.Errorf("snooze required = %v", snooze["required"])
+	}
+	if _, ok := tools["messages_create"]; ok {
+		t.Error("messages_create is not allowlisted")
+	}
+}
+
+func TestMCPServe_CallsReadTool(t *testing.T) {
+	var gotQuery string
+	setupTestEnvWithHandler(t, newRouteHandler().
+		On("GET", "/api/v1/accounts/1/conversations", func(w http.ResponseWriter, r *http.Request) {
+			gotQuery = r.URL.RawQuery
+			jsonResponse(200, `{"data": {"meta": {"all_count": 1, "total_pages": 1}, "payload": [{"id": 7, "status": "open", "inbox_id": 1}]}}`)(w, r)
+		}))
+
+	responses := runMCPSession(t, nil,
+		mcpCall(1, "conversations_list", `{"status": "open", "page": 1}`),
+		mcpCall(2, "conversations_list", `{"bogus": true}`),
+		mcpCall(3, "conversations_get", `{}`),
+	)
+
+	text, structured := mcpResult(t, responses[0])
+	if !strings.Contains(gotQuery, "status=open") {
+		t.Errorf("status not passed through: %q", gotQuery)
+	}
+	if !strings.Contains(text, `"id": 7`) && !strings.Contains(text, `"id":7`) {
+		t.Errorf("expected conversation in output: %s", text)
+	}
+	if structured == nil {
+		t.Error("expected structured content for JSON object output")
+	}
+	if _, failed := mcpResult(t, responses[1]); !strings.Contains(fmt.Sprint(failed["error"]), `unknown argument "bogus"`) {
+		t.Errorf("expected unknown argument error, got %v", failed)
+	}
+	if _, failed := mcpResult(t, responses[2]); !strings.Contains(fmt.Sprint(failed["error"]), `missing required argument "id"`) {
+		t.Errorf("expected missing argument error, got %v", failed)
+	}
+}
+
+func TestMCPServe_MutatingToolsHonorAllowlistAndDryRun(t *testing.T) {
+	posted := 0
+	setupTestEnvWithHandler(t, newRouteHandler().
+		On("POST", "/api/v1/accounts/1/conversations/123/messages", func(w http.ResponseWriter, r *http.Request) {
+			posted++
+			jsonResponse(200, `{"id": 9, "content": "-1 for this", "private": true}`)(w, r)
+		}))
+
+	responses := runMCPSession(t, nil, mcpCall(1, "note", `{"conversation_id": 123, "text": ["hi"]}`))
+	if _, failed := mcpResult(t, responses[0]); !strings.Contains(fmt.Sprint(failed["error"]), "--allow note") {
+		t.Errorf("expected allowlist error, got %v", failed)
+	}
+
+	responses = runMCPSession(t, []string{"--allow", "note"},
+		mcpCall(1, "note", `{"conversation_id": 123, "text": "hi", "dry_run": true}`),
+		mcpCall(2, "note", `{"conversation_id": "123", "text": ["-1 for this"]}`),
+	)
+	_, preview := mcpResult(t, responses[0])
+	if preview["dry_run"] != true {
+		t.Errorf("expected dry-run preview, got %v", preview)
+	}
+	if _, created := mcpResult(t, responses[1]); created == nil || created["error"] != nil {
+		t.Errorf("note failed: %v", created)
+	}
+	if posted != 1 {
+		t.Errorf("expected exactly one note to be posted, got %d", posted)
+	}
+
+	// The global --dry-run turns every mutating call into a preview.
+	responses = runMCPSession(t, []string{"--dry-run"},
+		`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`,
+		mcpCall(2, "note", `{"conversation_id": 123, "text": "hi"}`),
+	)
+	if _, ok := mcpToolsByName(t, responses[0])["note"]; !ok {
+		t.Error("previews are safe to offer under --dry-run")
+	}
+	if _, preview := mcpResult(t, responses[1]); preview["dry_run"] != true {
+		t.Errorf("expected dry-run preview, got %v", preview)
+	}
+	if posted != 1 {
+		t.Errorf("dry-run server posted a note")
+	}
+}
+
+func TestMCPServe_RejectsUnknownAllowlistEntry(t *testing.T) {
+	setupTestEnvWithHandler(t, newRouteHandler())
+	err := Execute(context.Background(), []string{"mcp", "serve", "--allow", "delete_everything"})
+	if err == nil || !strings.Contains(err.Error(), `unknown tool "delete_everything"`) {
+		t.Fatalf("expected unknown tool error, got %v", err)
+	}
+}
+
+func TestMCPServe_RejectsAtFileValues(t *testing.T) {
+	requests := 0
+	setupTestEnvWithHandler(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+
+	responses := runMCPSession(t, []string{"--allow", "label"},
+		mcpCall(1, "label", `{"id": 123, "labels": "@/etc/passwd"}`),
+		mcpCall(2, "label", `{"id": 123, "labels": " @-"}`),
+	)
+	for i, resp := range responses {
+		if _, failed := mcpResult(t, resp); !strings.Contains(fmt.Sprint(failed["error"]), "values starting with @") {
+			t.Errorf("call %d: expected @ value to be rejected, got %v", i+1, failed)
+		}
+	}
+	if requests != 0 {
+		t.Errorf("expected no API requests, got %d", requests)
+	}
+}
@@ -34,6 +34,8 @@ func newMessagesCmd() *cobra.Command {
 	cmd.AddCommand(newMessagesTranslateCmd())
 	cmd.AddCommand(newMessagesRetryCmd())
 	cmd.AddCommand(newMessagesBatchSendCmd())
+	cmd.AddCommand(newMessagesScheduleCmd())
+	cmd.AddCommand(newMessagesQueueCmd())
 
 	return cmd
 }
@@ -51,6 +53,7 @@ func newMessagesListCmd() *cobra.Command {
 	var publicOnly bool
 	var keyword string
 	var light bool
+	var offline bool
 
 	cmd := &cobra.Command{
 		Use:     "list <conversation-id>",
@@ -82,7 +85,10 @@ end of the array. Use --tail to return the last N messages after filtering.`,
   cw messages list 123 --keyword refund
 
   # Use conversation URL from browser
-  cw messages list https://app.chatwoot.com/app/accounts/1/conversations/123`,
+  cw messages list https://app.chatwoot.com/app/accounts/1/conversations/123
+
+  # Read from the local mirror (see 'cw sync')
+  cw messages list 123 --offline --tail 10`,
 		Args: cobra.ExactArgs(1),
 		RunE: RunE(func(cmd *cobra.Command, args []string) error {
 			conversationID, err := parseIDOrURL(args[0], "conversation")
@@ -112,8 +118,34 @@ end of the array. Use --tail to return the last N messages after filtering.`,
 				return err
 			}
 
+			getConversation := func() (*api.Conversation, error) {
+				return client.Conversations().Get(cmdContext(cmd), conversationID)
+			}
+
 			var messages []api.Message
-			if limit > 0 {
+			if offline {
+				m, err := syncedMirror(client)
+				if err != nil {
+					return err
+				}
+				getConversation = func() (*api.Conversation, error) {
+					return m.Conversation(conversationID)
+				}
+				messages, err = m.Messages(conversationID)
+				if err != nil {
+					return err
+				}
+				// Match the online defaults: one page (20) unless --all or --limit.
+				keep := 20
+				if limit > 0 {
+					keep = limit
+				} else if all {
+					keep = len(messages)
+				}
+				if len(messages) > keep {
+					messages = messages[len(messages)-keep:]
+				}
+			} else if limit > 0 {
 				messages, err = client.Messages().ListWithLimit(cmdContext(cmd), conversationID, limit, maxPages)
 			} else if all {
 				messages, err = client.Messages().ListAllWithMaxPages(cmdContext(cmd), conversationID, maxPages)
@@ -193,7 +225,7 @@ end of the array. Use --tail to return the last N messages after filtering.`,
 			}
 
 			if transcript {
-				conv, err := client.Conversations().Get(cmdContext(cmd), conversationID)
+				conv, err := getConversation()
 				if err != nil {
 					slog.Debug("Failed to fetch conversation metadata for transcript", "error", err)
 				}
@@ -204,12 +236,14 @@ end of the array. Use --tail to return the last N messages after filtering.`,
 			if isAgent(cmd) {
 				var conversationDetail *agentfmt.ConversationDetail
 				if flags.ResolveNames {
-					conv, err := client.Conversations().Get(cmdContext(cmd), conversationID)
+					conv, err := getConversation()
 					if err != nil {
 						return fmt.Errorf("failed to resolve conversation %d: %w", conversationID, err)
 					}
 					detail := agentfmt.ConversationDetailFromConversation(*conv)
-					detail = resolveConversationDetail(cmdContext(cmd), client, detail)
+					if !offline {
+						detail = resolveConversationDetail(cmdContext(cmd), client, detail)
+					}
 					conversationDetail = &detail
 				}
 
@@ -310,6 +344,7 @@ end of the array. Use --tail to return the last N messages after filtering.`,
 	flagAlias(cmd.Flags(), "keyword", "kw")
 	cmd.Flags().BoolVar(&light, "light", false, "Return minimal message payload for lookup")
 	flagAlias(cmd.Flags(), "light", "li")
+	addOfflineFlag(cmd, &offline)
 
 	return cmd
 }
@@ -322,6 +357,7 @@ func newMessagesCreateCmd() *cobra.Command {
 		messageType string
 		attachments []string
 		mentions    []string
+		cannedCode  string
 		light       bool
 	)
 
@@ -353,6 +389,9 @@ func newMessagesCreateCmd() *cobra.Command {
 
   # Send attachment only (no text)
   cw messages create 123 --attachment screenshot.png
+
+  # Send a canned response with {{contact.name}}-style variables filled in
+  cw messages create 123 --canned refund-policy
 `),
 		Args: cobra.ExactArgs(1),
 		RunE: RunE(func(cmd *cobra.Command, args []string) error {
@@ -361,8 +400,11 @@ func newMessagesCreateCmd() *cobra.Command {
 				return err
 			}
 
-			if content == "" && len(attachments) == 0 {
-				return fmt.Errorf("either --content or --attachment is required")
+			if cannedCode != "" && content != "" {
+				return fmt.Errorf("--content and --canned cannot be used together")
+			}
+			if content == "" && cannedCode == "" && len(attachments) == 0 {
+				return fmt.Errorf("either --content or --attachment is required (or use --canned)")
 			}
 
 			client, err := getClient()
@@ -370,6 +412,12 @@ func newMessagesCreateCmd() *cobra.Command {
 				return err
 			}
 
+			if cannedCode != "" {
+				if content, err = renderCannedResponse(cmdContext(cmd), client, cannedCode, conversationID); err != nil {
+					return err
+				}
+			}
+
 			// Resolve mentions to user IDs and build mention prefix
 			if len(mentions) > 0 {
 				if !private {
@@ -437,13 +485,13 @@ func newMessagesCreateCmd() *cobra.Command {
 				if ok, err := maybeDryRun(cmd, &dryrun.Preview{
 					Operation: "create",
 					Resource:  "message",
-					Details: map[string]any{
+					Details: withCannedDetail(map[string]any{
 						"conversation_id": conversationID,
 						"content":         content,
 						"private":         private,
 						"type":            messageType,
 						"attachments":     attachmentNames,
-					},
+					}, cannedCode),
 				}); ok {
 					return err
 				}
@@ -460,12 +508,12 @@ func newMessagesCreateCmd() *cobra.Command {
 				if ok, err := maybeDryRun(cmd, &dryrun.Preview{
 					Operation: "create",
 					Resource:  "message",
-					Details: map[string]any{
+					Details: withCannedDetail(map[string]any{
 						"conversation_id": conversationID,
 						"content":         content,
 						"private":         private,
 						"type":            messageType,
-					},
+					}, cannedCode),
 				}); ok {
 					return err
 				}
@@ -518,10 +566,12 @@ func newMessagesCreateCmd() *cobra.Command {
 	cmd.Flags().StringVar(&messageType, "type", "outgoing", "Message type: outgoing|incoming")
 	cmd.Flags().StringArrayVar(&attachments, "attachment", nil, "File path to attach (can be repeated)")
 	cmd.Flags().StringArrayVar(&mentions, "mention", nil, "Agent to mention/tag (name or email, can be repeated). Requires --private")
+	cmd.Flags().StringVar(&cannedCode, "canned", "", "Send a canned response by short code, rendering {{contact.name}}-style variables")
 	flagAlias(cmd.Flags(), "content", "message")
 	flagAlias(cmd.Flags(), "type", "ty")
 	flagAlias(cmd.Flags(), "attachment", "att")
 	flagAlias(cmd.Flags(), "mention", "mt")
+	flagAlias(cmd.Flags(), "canned", "cn")
 	cmd.Flags().BoolVar(&light, "light", false, "Return minimal mutation payload (defaults to compact JSON; override with --cj=false)")
 	flagAlias(cmd.Flags(), "light", "li")
 	registerCommandContract(cmd, true, true)
@@ -0,0 +1,389 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/chatwoot/chatwoot-cli/internal/cli"
+	"github.com/chatwoot/chatwoot-cli/internal/config"
+	"github.com/chatwoot/chatwoot-cli/internal/dryrun"
+	"github.com/chatwoot/chatwoot-cli/internal/schedule"
+	"github.com/chatwoot/chatwoot-cli/internal/validation"
+	"github.com/spf13/cobra"
+)
+
+// scheduleStore opens the scheduled message queue under the config dir.
+func scheduleStore() *schedule.Store {
+	return schedule.NewStore(schedule.DefaultPath(config.Dir()))
+}
+
+func newMessagesScheduleCmd() *cobra.Command {
+	var (
+		content        string
+		at             string
+		private        bool
+		skipIfResolved bool
+		skipIfReplied  bool
+	)
+
+	cmd := &cobra.Command{
+		Use:     "schedule <conversation-id>",
+		Aliases: []string{"sched"},
+		Short:   "Schedule a message to be sent later",
+		Long: strings.TrimSpace(`
+Queue a message to be sent at a later time. The queue is a local file under
+the config directory (CW_CONFIG_DIR overrides it); nothing is stored in
+Chatwoot until the message is delivered by 'cw messages queue run', which you
+can run from cron or keep running with --watch.
+
+Each queued message gets an idempotency key, and the worker checks the
+conversation before retrying an attempt whose outcome is unknown, so a retry
+never sends the message twice.
+`),
+		Example: strings.TrimSpace(`
+  # Send tomorrow morning
+  cw m schedule 123 --at "tomorrow 9am" -c "Good morning! Any update on your order?"
+
+  # Follow up in 2 hours unless the customer answers first
+  cw m schedule 123 --at 2h -c "Just checking in" --skip-if-replied
+
+  # Private reminder note on Friday afternoon, unless resolved by then
+  cw m schedule 123 --at "fri 15:00" -P -c "Check refund status" --skip-if-resolved
+
+  # Deliver due messages every minute from cron
+  * * * * * cw m queue run
+`),
+		Args: cobra.ExactArgs(1),
+		RunE: RunE(func(cmd *cobra.Command, args []string) error {
+			conversationID, err := parseIDOrURL(args[0], "conversation")
+			if err != nil {
+				return err
+			}
+			if strings.TrimSpace(content) == "" {
+				return fmt.Errorf("--content is required")
+			}
+			if err := validation.ValidateMessageContent(content); err != nil {
+				return err
+			}
+			if strings.TrimSpace(at) == "" {
+				return fmt.Errorf("--at is required (e.g. \"tomorrow 9am\", 2h, 2026-10-20T09:00:00Z)")
+			}
+			now := time.Now()
+			sendAt, err := cli.ParseRelativeTime(at, now)
+			if err != nil {
+				return fmt.Errorf("invalid --at: %w", err)
+			}
+			if !sendAt.After(now) {
+				return fmt.Errorf("--at %q is in the past (%s)", at, formatTimestampWithZone(sendAt))
+			}
+
+			if ok, err := maybeDryRun(cmd, &dryrun.Preview{
+				Operation: "schedule",
+				Resource:  "message",
+				Details: map[string]any{
+					"conversation_id":  conversationID,
+					"content":          content,
+					"private":          private,
+					"send_at":          sendAt.Format(time.RFC3339),
+					"skip_if_resolved": skipIfResolved,
+					"skip_if_replied":  skipIfReplied,
+				},
+			}); ok {
+				return err
+			}
+
+			client, err := getClient()
+			if err != nil {
+				return err
+			}
+
+			var item schedule.Item
+			err = scheduleStore().Update(func(q *schedule.Queue) error {
+				item = q.Add(schedule.Item{
+					BaseURL:        client.BaseURL,
+					AccountID:      client.AccountID,
+					ConversationID: conversationID,
+					Content:        content,
+					Private:        private,
+					SendAt:         sendAt,
+					CreatedAt:      now,
+					SkipIfResolved: skipIfResolved,
+					SkipIfReplied:  skipIfReplied,
+					IdempotencyKey: newIdempotencyKey(),
+				})
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			if isJSON(cmd) {
+				return printJSON(cmd, item)
+			}
+			printAction(cmd, "Scheduled", "message", item.ID, "")
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Conversation: %d\n", conversationID)
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Send at: %s (in %s)\n", formatTimestampWithZone(sendAt), formatDuration(int64(sendAt.Sub(now).Seconds())))
+			_, _ = fmt.Fprintln(cmd.OutOrStdout(), "Delivered by: cw messages queue run")
+			return nil
+		}),
+	}
+
+	cmd.Flags().StringVarP(&content, "content", "c", "", "Message content (required)")
+	cmd.Flags().StringVar(&at, "at", "", "When to send: \"tomorrow 9am\", \"fri 14:30\", 2h, or RFC3339 (required)")
+	cmd.Flags().BoolVarP(&private, "private", "P", false, "Send as a private note")
+	cmd.Flags().BoolVar(&skipIfResolved, "skip-if-resolved", false, "Do not send if the conversation is resolved by then")
+	cmd.Flags().BoolVar(&skipIfReplied, "skip-if-replied", false, "Do not send if the customer replies before then")
+	flagAlias(cmd.Flags(), "content", "message")
+	flagAlias(cmd.Flags(), "skip-if-resolved", "sir")
+	flagAlias(cmd.Flags(), "skip-if-replied", "sirp")
+	registerCommandContract(cmd, true, true)
+
+	return cmd
+}
+
+func newMessagesQueueCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "queue",
+		Aliases: []string{"qu"},
+		Short:   "Manage scheduled messages",
+		Long:    "List, cancel and deliver messages queued with 'cw messages schedule'",
+	}
+	cmd.AddCommand(newMessagesQueueListCmd())
+	cmd.AddCommand(newMessagesQueueCancelCmd())
+	cmd.AddCommand(newMessagesQueueRunCmd())
+	return cmd
+}
+
+func newMessagesQueueListCmd() *cobra.Command {
+	var all bool
+
+	cmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List scheduled messages",
+		Long:    "List queued messages. Sent, skipped, canceled and failed messages are shown with --all.",
+		Args:    cobra.NoArgs,
+		RunE: RunE(func(cmd *cobra.Command, args []string) error {
+			q, err := scheduleStore().Load()
+			if err != nil {
+				return err
+			}
+			items := make([]schedule.Item, 0, len(q.Items))
+			for _, it := range q.Sorted() {
+				if all || !it.Done() {
+					items = append(items, it)
+				}
+			}
+
+			if isJSON(cmd) {
+				return printJSON(cmd, items)
+			}
+			if len(items) == 0 {
+				_, _ = fmt.Fprintln(cmd.OutOrStdout(), "No scheduled messages")
+				return nil
+			}
+			w := newTabWriterFromCmd(cmd)
+			defer func() { _ = w.Flush() }()
+			_, _ = fmt.Fprintln(w, "ID\tCONVERSATION\tSEND_AT\tSTATUS\tTYPE\tCONTENT")
+			for _, it := range items {
+				kind := "reply"
+				if it.Private {
+					kind = "note"
+				}
+				status := string(it.Status)
+				if it.Error != "" {
+					status += ": " + it.Error
+				}
+				content := strings.Join(strings.Fields(it.Content), " ")
+				if len([]rune(content)) > 40 {
+					content = string([]rune(content)[:37]) + "..."
+				}
+				_, _ = fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%s\n", it.ID, it.ConversationID, formatTimestamp(it.SendAt), status, kind, content)
+			}
+			return nil
+		}),
+	}
+
+	cmd.Flags().BoolVarP(&all, "all", "a", false, "Include sent, skipped, canceled and failed messages")
+	return cmd
+}
+
+func newMessagesQueueCancelCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cancel <id>...",
+		Short: "Cancel scheduled messages",
+		Long:  "Cancel queued messages by ID (a unique prefix is enough).",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: RunE(func(cmd *cobra.Command, args []string) error {
+			if ok, err := maybeDryRun(cmd, &dryrun.Preview{
+				Operation: "cancel",
+				Resource:  "scheduled message",
+				Details:   map[string]any{"ids": args},
+			}); ok {
+				return err
+			}
+
+			var canceled []schedule.Item
+			err := scheduleStore().Update(func(q *schedule.Queue) error {
+				now := time.Now()
+				for _, id := range args {
+					item, err := q.Cancel(id, now)
+					if err != nil {
+						return err
+					}
+					canceled = append(canceled, item)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			if isJSON(cmd) {
+				return printJSON(cmd, canceled)
+			}
+			for _, it := range canceled {
+				printAction(cmd, "Canceled", "scheduled message", it.ID, "")
+			}
+			return nil
+		}),
+	}
+	registerCommandContract(cmd, true, true)
+	return cmd
+}
+
+func newMessagesQueueRunCmd() *cobra.Command {
+	var (
+		watch    bool
+		interval time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "run",
+		Short: "Deliver scheduled messages that are due",
+		Long: strings.TrimSpace(`
+Send every queued message for the current profile whose time has come, then
+exit. With --watch, keep checking every --interval until interrupted.
+
+Failed sends are retried on later runs (up to 5 attempts); client errors such
+as a deleted conversation fail immediately. Messages scheduled with
+--skip-if-resolved or --skip-if-replied are checked just before sending.
+`),
+		Example: strings.TrimSpace(`
+  # One pass, e.g. from cron
+  cw m queue run
+
+  # Keep delivering in the foreground
+  cw m queue run --watch --interval 30s
+`),
+		Args: cobra.NoArgs,
+		RunE: RunE(func(cmd *cobra.Command, args []string) error {
+			if watch && interval <= 0 {
+				return fmt.Errorf("--interval must be positive")
+			}
+			if ok, err := maybeDryRun(cmd, &dryrun.Preview{
+				Operation: "deliver",
+				Resource:  "scheduled messages",
+				Details:   map[string]any{"queue": scheduleStore().Path()},
+			}); ok {
+				return err
+			}
+
+			client, err := getClient()
+			if err != nil {
+				return err
+			}
+			worker := &schedule.Worker{
+				Store:   scheduleStore(),
+				Sender:  schedule.NewAPISender(client),
+				Account: schedule.Account{BaseURL: client.BaseURL, AccountID: client.AccountID},
+			}
+
+			if !watch {
+				results, err := worker.RunOnce(cmdContext(cmd))
+				if isJSON(cmd) {
+					if results == nil {
+						results = []schedule.Result{}
+					}
+					if printErr := printJSON(cmd, results); printErr != nil {
+						return printErr
+					}
+				} else {
+					printQueueResults(cmd, results)
+					if len(results) == 0 && err == nil {
+						_, _ = fmt.Fprintln(cmd.OutOrStdout(), "No scheduled messages due")
+					}
+				}
+				return err
+			}
+
+			ctx, stop := signal.NotifyContext(cmdContext(cmd), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			if !isJSON(cmd) {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Delivering scheduled messages every %s (press Ctrl+C to stop)\n", interval)
+			}
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				if err := runQueuePass(ctx, cmd, worker); err != nil {
+					return err
+				}
+				select {
+				case <-ctx.Done():
+					return nil
+				case <-ticker.C:
+				}
+			}
+		}),
+	}
+
+	cmd.Flags().BoolVarP(&watch, "watch", "w", false, "Keep running and deliver messages as they become due")
+	cmd.Flags().DurationVar(&interval, "interval", 30*time.Second, "How often to check the queue with --watch")
+	flagAlias(cmd.Flags(), "interval", "iv")
+	registerCommandContract(cmd, true, true)
+	return cmd
+}
+
+// runQueuePass runs one worker pass in watch mode, printing results as JSON
+// lines or text. Queue file errors stop the watch; API errors are recorded
+// per message by the worker.
+func runQueuePass(ctx context.Context, cmd *cobra.Command, worker *schedule.Worker) error {
+	results, err := worker.RunOnce(ctx)
+	if isJSON(cmd) {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetEscapeHTML(false)
+		for _, r := range results {
+			if err := enc.Encode(r); err != nil {
+				return err
+			}
+		}
+	} else {
+		printQueueResults(cmd, results)
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+func printQueueResults(cmd *cobra.Command, results []schedule.Result) {
+	out := cmd.OutOrStdout()
+	for _, r := range results {
+		switch {
+		case r.Status == schedule.StatusSent:
+			_, _ = fmt.Fprintf(out, "%s Sent %s to conversation %d (message %d)\n", green("✓"), r.ID, r.ConversationID, r.MessageID)
+		case r.Status == schedule.StatusSkipped:
+			_, _ = fmt.Fprintf(out, "%s Skipped %s for conversation %d: %s\n", yellow("-"), r.ID, r.ConversationID, r.Error)
+		case r.Retry:
+			_, _ = fmt.Fprintf(out, "%s Will retry %s for conversation %d (attempt %d/%d): %s\n", yellow("!"), r.ID, r.ConversationID, r.Attempts, schedule.MaxAttempts, r.Error)
+		default:
+			_, _ = fmt.Fprintf(out, "%s Failed %s for conversation %d: %s\n", red("✗"), r.ID, r.ConversationID, r.Error)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chatwoot/chatwoot-cli/internal/schedule"
+)
+
+func TestMessagesScheduleQueueRun(t *testing.T) {
+	t.Setenv("CW_CONFIG_DIR", t.TempDir())
+
+	var (
+		sent    map[string]any
+		idemKey string
+	)
+	handler := newRouteHandler().
+		On("GET", "/api/v1/accounts/1/conversations/123/messages", jsonResponse(200, `{"payload": []}`)).
+		On("POST", "/api/v1/accounts/1/conversations/123/messages", func(w http.ResponseWriter, r *http.Request) {
+			idemKey = r.Header.Get("Idempotency-Key")
+			_ = json.NewDecoder(r.Body).Decode(&sent)
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"id": 55, "conversation_id": 123, "content": "Checking in", "message_type": 1}`))
+		})
+	setupTestEnvWithHandler(t, handler)
+
+	output := captureStdout(t, func() {
+		err := Execute(context.Background(), []string{"m", "schedule", "123", "--at", "2h", "-c", "Checking in", "--skip-if-replied", "-o", "json"})
+		if err != nil {
+			t.Fatalf("schedule failed: %v", err)
+		}
+	})
+	var item schedule.Item
+	if err := json.Unmarshal([]byte(output), &item); err != nil {
+		t.Fatalf("invalid JSON %q: %v", output, err)
+	}
+	if item.Status != schedule.StatusPending || !item.SkipIfReplied || !strings.HasPrefix(item.IdempotencyKey, "cwcli_") {
+		t.Fatalf("unexpected item %+v", item)
+	}
+	if d := time.Until(item.SendAt); d < time.Hour || d > 2*time.Hour {
+		t.Fatalf("send_at %s is not about 2h from now", item.SendAt)
+	}
+
+	output = captureStdout(t, func() {
+		if err := Execute(context.Background(), []string{"m", "queue", "ls"}); err != nil {
+			t.Fatalf("queue ls failed: %v", err)
+		}
+	})
+	if !strings.Contains(output, item.ID) || !strings.Contains(output, "pending") {
+		t.Fatalf("queue ls output missing item:\n%s", output)
+	}
+
+	// Not due yet: nothing is sent.
+	output = captureStdout(t, func() {
+		if err := Execute(context.Background(), []string{"m", "queue", "run"}); err != nil {
+			t.Fatalf("queue run failed: %v", err)
+		}
+	})
+	if sent != nil || !strings.Contains(output, "No scheduled messages due") {
+		t.Fatalf("nothing should be sent yet, got %#v / %q", sent, output)
+	}
+
+	// Move the message into the past and deliver it.
+	store := scheduleStore()
+	err := store.Update(func(q *schedule.Queue) error {
+		q.Items[0].SendAt = time.Now().Add(-time.Minute)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	output = captureStdout(t, func() {
+		if err := Execute(context.Background(), []string{"m", "qu", "run", "-o", "json"}); err != nil {
+			t.Fatalf("queue run failed: %v", err)
+		}
+	})
+	var payload struct {
+		Items []schedule.Result `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(output), &payload); err != nil {
+		t.Fatalf("invalid JSON %q: %v", output, err)
+	}
+	results := payload.Items
+	if len(results) != 1 || results[0].Status != schedule.StatusSent || results[0].MessageID != 55 {
+		t.Fatalf("unexpected results %+v", results)
+	}
+	if sent["content"] != "Checking in" || idemKey != item.IdempotencyKey {
+		t.Fatalf("unexpected send payload=%#v key=%q", sent, idemKey)
+	}
+
+	err = Execute(context.Background(), []string{"m", "queue", "cancel", item.ID})
+	if err == nil || !strings.Contains(err.Error(), "already sent") {
+		t.Fatalf("expected already sent error, got %v", err)
+	}
+}
+
+func TestMessagesScheduleCancelAndValidation(t *testing.T) {
+	t.Setenv("CW_CONFIG_DIR", t.TempDir())
+	setupTestEnvWithHandler(t, newRouteHandler())
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"m", "schedule", "123", "-c", "Hi"}, "--at is required"},
+		{[]string{"m", "schedule", "123", "--at", "2h"}, "--content is required"},
+		{[]string{"m", "schedule", "123", "--at", "someday", "-c", "Hi"}, "invalid --at"},
+		{[]string{"m", "schedule", "123", "--at", "2020-01-01", "-c", "Hi"}, "in the past"},
+		{[]string{"m", "queue", "cancel", "deadbeef"}, "not found"},
+	}
+	for _, tt := range tests {
+		err := Execute(context.Background(), tt.args)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%v: expected error containing %q, got %v", tt.args, tt.want, err)
+		}
+	}
+
+	_ = captureStdout(t, func() {
+		if err := Execute(context.Background(), []string{"m", "sched", "123", "--at", "tomorrow 9am", "-c", "Hi", "-P"}); err != nil {
+			t.Fatalf("schedule failed: %v", err)
+		}
+	})
+	q, err := scheduleStore().Load()
+	if err != nil || len(q.Items) != 1 || !q.Items[0].Private {
+		t.Fatalf("unexpected queue %+v (err %v)", q, err)
+	}
+	id := q.Items[0].ID
+
+	_ = captureStdout(t, func() {
+		if err := Execute(context.Background(), []string{"m", "queue", "cancel", id[:4]}); err != nil {
+			t.Fatalf("cancel failed: %v", err)
+		}
+	})
+	output := captureStdout(t, func() {
+		if err := Execute(context.Background(), []string{"m", "queue", "ls"}); err != nil {
+			t.Fatalf("queue ls failed: %v", err)
+		}
+	})
+	if !strings.Contains(output, "No scheduled messages") {
+		t.Fatalf("canceled message should be hidden without --all:\n%s", output)
+	}
+	output = captureStdout(t, func() {
+		if err := Execute(context.Background(), []string{"m", "queue", "ls", "--all"}); err != nil {
+			t.Fatalf("queue ls --all failed: %v", err)
+		}
+	})
+	if !strings.Contains(output, "canceled") {
+		t.Fatalf("queue ls --all should show canceled message:\n%s", output)
+	}
+}
@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/chatwoot/chatwoot-cli/internal/config"
+	"github.com/chatwoot/chatwoot-cli/internal/dryrun"
+	"github.com/chatwoot/chatwoot-cli/internal/migrate"
+)
+
+func newMigrateCmd() *cobra.Command {
+	var (
+		from        string
+		to          string
+		kinds       []string
+		mappingPath string
+	)
+
+	kindNames := make([]string, len(migrate.AllKinds))
+	for i, k := range migrate.AllKinds {
+		kindNames[i] = string(k)
+	}
+
+	cmd := &cobra.Command{
+		Use:     "migrate",
+		Aliases: []string{"mig"},
+		Short:   "Copy resources from one profile's account to another",
+		Long: strings.TrimSpace(`
+Copy labels, custom attribute definitions, canned responses, portals (with
+their categories and articles), and automation rules between two profiles.
+
+Resources that already exist in the target are linked, not duplicated: labels
+by title, attributes by key, canned responses by short code, portals and
+categories by slug, articles by slug (or title), and rules by name.
+
+IDs inside automation rules are remapped: teams and inboxes by name, agents by
+email. Article category IDs are remapped to the migrated categories. Every
+source -> target ID is written to the mapping file as it happens, so re-running
+the same command resumes where it stopped.
+`),
+		Example: strings.TrimSpace(`
+  # Preview what would be copied
+  cw migrate --from staging --to prod --dry-run
+
+  # Copy only canned responses and labels
+  cw migrate --from staging --to prod --kinds canned_responses,labels
+
+  # Resume an interrupted run with an explicit mapping file
+  cw migrate --from staging --to prod --mapping staging-prod.json
+`),
+		Args: cobra.NoArgs,
+		RunE: RunE(func(cmd *cobra.Command, _ []string) error {
+			if from == "" || to == "" {
+				return fmt.Errorf("--from and --to are required")
+			}
+			if from == to {
+				return fmt.Errorf("--from and --to must be different profiles")
+			}
+			selected, err := migrate.ParseKinds(kinds)
+			if err != nil {
+				return err
+			}
+
+			source, err := getProfileClient(from)
+			if err != nil {
+				return fmt.Errorf("source profile: %w", err)
+			}
+			target, err := getProfileClient(to)
+			if err != nil {
+				return fmt.Errorf("target profile: %w", err)
+			}
+			if source.BaseURL == target.BaseURL && source.AccountID == target.AccountID {
+				return fmt.Errorf("profiles %q and %q point at the same account", from, to)
+			}
+
+			if mappingPath == "" {
+				mappingPath = fmt.Sprintf("cw-migrate-%s-to-%s.json", from, to)
+			}
+			mapping, err := migrate.LoadMapping(mappingPath,
+				migrate.Endpoint{BaseURL: source.BaseURL, AccountID: source.AccountID},
+				migrate.Endpoint{BaseURL: target.BaseURL, AccountID: target.AccountID},
+			)
+			if err != nil {
+				return err
+			}
+
+			dryRun := dryrun.IsEnabled(cmd.Context())
+			text := !isJSON(cmd)
+			tw := newTabWriterFromCmd(cmd)
+			if text {
+				_, _ = fmt.Fprintln(tw, "RESOURCE\tKEY\tSOURCE\tTARGET\tSTATUS")
+			}
+			m := migrate.New(source, target, mapping, migrate.Options{
+				Kinds:  selected,
+				DryRun: dryRun,
+				OnItem: func(item migrate.Item) {
+					if !text {
+						return
+					}
+					status := string(item.Status)
+					if item.Error != "" {
+						status += ": " + item.Error
+					}
+					targetID := "-"
+					if item.TargetID != 0 {
+						targetID = fmt.Sprintf("%d", item.TargetID)
+					}
+					_, _ = fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\n", item.Resource, item.Key, item.SourceID, targetID, status)
+					for _, w := range item.Warnings {
+						_, _ = fmt.Fprintf(tw, "\t  ! %s\t\t\t\n", w)
+					}
+				},
+			})
+			items, runErr := m.Run(cmdContext(cmd))
+			_ = tw.Flush()
+
+			summary := map[string]int{}
+			failed := 0
+			for _, item := range items {
+				summary[string(item.Status)]++
+				if item.Status == migrate.StatusFailed {
+					failed++
+				}
+			}
+
+			if isJSON(cmd) {
+				out := map[string]any{
+					"from":    from,
+					"to":      to,
+					"dry_run": dryRun,
+					"items":   items,
+					"summary": summary,
+				}
+				if !dryRun {
+					out["mapping_file"] = mapping.Path()
+				}
+				if err := printJSON(cmd, out); err != nil {
+					return err
+				}
+			} else {
+				out := cmd.OutOrStdout()
+				_, _ = fmt.Fprintf(out, "\n%d created, %d already present, %d previously migrated, %d planned, %d failed\n",
+					summary[string(migrate.StatusCreated)],
+					summary[string(migrate.StatusExists)],
+					summary[string(migrate.StatusMapped)],
+					summary[string(migrate.StatusPlanned)],
+					failed,
+				)
+				if dryRun {
+					_, _ = fmt.Fprintln(out, "No changes made (dry-run mode)")
+				} else {
+					_, _ = fmt.Fprintf(out, "Mapping saved to %s\n", mapping.Path())
+				}
+			}
+
+			if runErr != nil {
+				return runErr
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d resources failed to migrate; fix the errors and re-run to resume", failed)
+			}
+			return nil
+		}),
+	}
+
+	cmd.Flags().StringVar(&from, "from", "", "Source profile (required)")
+	cmd.Flags().StringVar(&to, "to", "", "Target profile (required)")
+	cmd.Flags().StringSliceVar(&kinds, "kinds", nil, "Kinds to copy (comma-separated): "+strings.Join(kindNames, ", ")+" (default all)")
+	cmd.Flags().StringVar(&mappingPath, "mapping", "", "Mapping file for resume (default cw-migrate-<from>-to-<to>.json)")
+	registerStaticCompletions(cmd, "kinds", kindNames)
+	flagAlias(cmd.Flags(), "kinds", "kd")
+	flagAlias(cmd.Flags(), "mapping", "map")
+	_ = cmd.RegisterFlagCompletionFunc("from", completeProfileNames)
+	_ = cmd.RegisterFlagCompletionFunc("to", completeProfileNames)
+	registerCommandContract(cmd, true, true)
+
+	return cmd
+}
+
+func completeProfileNames(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	profiles, err := config.ListProfiles()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return profiles, cobra.ShellCompDirectiveNoFileComp
+}
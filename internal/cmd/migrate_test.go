@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/chatwoot/chatwoot-cli/internal/config"
+)
+
+func setupMigrateProfiles(t *testing.T) (mutations *[]string) {
+	t.Helper()
+	withPersistentKeyring(t)
+	t.Setenv("CHATWOOT_TESTING", "1")
+
+	source := httptest.NewServer(newRouteHandler().
+		On("GET", "/api/v1/accounts/1/labels", jsonResponse(200, `{"payload": [{"id": 1, "title": "vip"}, {"id": 2, "title": "billing"}]}`)).
+		On("GET", "/api/v1/accounts/1/canned_responses", jsonResponse(200, `[{"id": 11, "short_code": "hi", "content": "Hello"}]`)))
+	t.Cleanup(source.Close)
+
+	var mu sync.Mutex
+	var recorded []string
+	record := func(body string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			recorded = append(recorded, r.Method+" "+r.URL.Path)
+			mu.Unlock()
+			jsonResponse(200, body)(w, r)
+		}
+	}
+	target := httptest.NewServer(newRouteHandler().
+		On("GET", "/api/v1/accounts/2/labels", jsonResponse(200, `{"payload": [{"id": 100, "title": "VIP"}]}`)).
+		On("GET", "/api/v1/accounts/2/canned_responses", jsonResponse(200, `[]`)).
+		On("POST", "/api/v1/accounts/2/labels", record(`{"id": 101, "title": "billing"}`)).
+		On("POST", "/api/v1/accounts/2/canned_responses", record(`{"id": 111, "short_code": "hi"}`)))
+	t.Cleanup(target.Close)
+
+	if err := config.SaveProfile("staging", config.Account{BaseURL: source.URL, APIToken: "s", AccountID: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.SaveProfile("prod", config.Account{BaseURL: target.URL, APIToken: "p", AccountID: 2}); err != nil {
+		t.Fatal(err)
+	}
+	return &recorded
+}
+
+func TestMigrateCommand(t *testing.T) {
+	mutations := setupMigrateProfiles(t)
+	mapping := filepath.Join(t.TempDir(), "map.json")
+
+	output := captureStdout(t, func() {
+		err := Execute(context.Background(), []string{"migrate", "--from", "staging", "--to", "prod", "--kinds", "labels,canned_responses", "--mapping", mapping, "--dry-run"})
+		if err != nil {
+			t.Fatalf("dry run failed: %v", err)
+		}
+	})
+	if len(*mutations) != 0 {
+		t.Fatalf("dry run mutated target: %v", *mutations)
+	}
+	for _, want := range []string{"planned", "exists", "No changes made"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in dry-run output:\n%s", want, output)
+		}
+	}
+
+	output = captureStdout(t, func() {
+		err := Execute(context.Background(), []string{"migrate", "--from", "staging", "--to", "prod", "--kinds", "labels,canned_responses", "--mapping", mapping, "-o", "json"})
+		if err != nil {
+			t.Fatalf("migrate failed: %v", err)
+		}
+	})
+	if got := strings.Join(*mutations, "|"); got != "POST /api/v1/accounts/2/labels|POST /api/v1/accounts/2/canned_responses" {
+		t.Fatalf("unexpected mutations: %s", got)
+	}
+	if !strings.Contains(output, `"created": 2`) || !strings.Contains(output, `"exists": 1`) {
+		t.Fatalf("unexpected summary: %s", output)
+	}
+	if _, err := os.Stat(mapping); err != nil {
+		t.Fatalf("mapping file not written: %v", err)
+	}
+
+	// Re-running resumes from the mapping file.
+	output = captureStdout(t, func() {
+		err := Execute(context.Background(), []string{"migrate", "--from", "staging", "--to", "prod", "--kinds", "labels,canned_responses", "--mapping", mapping, "-o", "json"})
+		if err != nil {
+			t.Fatalf("resume failed: %v", err)
+		}
+	})
+	if len(*mutations) != 2 || !strings.Contains(output, `"mapped": 3`) {
+		t.Fatalf("expected resume without new writes, got %v\n%s", *mutations, output)
+	}
+}
+
+func TestMigrateCommandValidation(t *testing.T) {
+	setupMigrateProfiles(t)
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"migrate", "--from", "staging"}, "--from and --to are required"},
+		{[]string{"migrate", "--from", "prod", "--to", "prod"}, "must be different"},
+		{[]string{"migrate", "--from", "staging", "--to", "missing"}, `profile "missing" not found`},
+		{[]string{"migrate", "--from", "staging", "--to", "prod", "--kinds", "teams"}, "unknown kind"},
+	}
+	for _, tt := range tests {
+		err := Execute(context.Background(), tt.args)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%v: expected error containing %q, got %v", tt.args, tt.want, err)
+		}
+	}
+}
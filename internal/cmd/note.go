@@ -235,6 +235,7 @@ This is a convenience shortcut for:
 	flagAlias(cmd.Flags(), "snooze-for", "for")
 	cmd.Flags().BoolVar(&light, "light", false, "Return minimal mutation payload")
 	flagAlias(cmd.Flags(), "light", "li")
+	registerCommandContract(cmd, true, true)
 
 	return cmd
 }
@@ -0,0 +1,277 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+	"github.com/chatwoot/chatwoot-cli/internal/mirror"
+)
+
+// offlinePageSize mirrors the Chatwoot conversations page size so --page
+// behaves the same online and offline.
+const offlinePageSize = 25
+
+func addOfflineFlag(cmd *cobra.Command, offline *bool) {
+	cmd.Flags().BoolVar(offline, "offline", false, "Answer from the local mirror (see 'cw sync') instead of the API")
+	flagAlias(cmd.Flags(), "offline", "off")
+}
+
+// openMirror returns the offline mirror for the client's server and account.
+func openMirror(client *api.Client) (*mirror.Mirror, error) {
+	dir := resolveCacheDir()
+	if dir == "" {
+		return nil, fmt.Errorf("could not determine cache directory")
+	}
+	return mirror.Open(dir, client.BaseURL, client.AccountID), nil
+}
+
+// syncedMirror returns the offline mirror, failing if it has never been synced.
+func syncedMirror(client *api.Client) (*mirror.Mirror, error) {
+	m, err := openMirror(client)
+	if err != nil {
+		return nil, err
+	}
+	if !m.Synced() {
+		return nil, mirror.ErrNotSynced
+	}
+	return m, nil
+}
+
+// offlineConversationFilter holds the conversations list filters that can be
+// evaluated locally.
+type offlineConversationFilter struct {
+	Status       string
+	InboxID      int
+	AssigneeType string
+	TeamID       int
+	Labels       []string
+	Query        string
+}
+
+func filterMirrorConversations(m *mirror.Mirror, convs []api.Conversation, f offlineConversationFilter) ([]api.Conversation, error) {
+	if f.AssigneeType == "me" {
+		return nil, fmt.Errorf("--assignee-type me is not supported with --offline")
+	}
+	out := make([]api.Conversation, 0, len(convs))
+	for _, conv := range convs {
+		if f.Status != "" && f.Status != "all" && conv.Status != f.Status {
+			continue
+		}
+		if f.InboxID > 0 && conv.InboxID != f.InboxID {
+			continue
+		}
+		if f.TeamID > 0 && (conv.TeamID == nil || *conv.TeamID != f.TeamID) {
+			continue
+		}
+		switch f.AssigneeType {
+		case "assigned":
+			if conversationAssigneeID(conv) == 0 {
+				continue
+			}
+		case "unassigned":
+			if conversationAssigneeID(conv) != 0 {
+				continue
+			}
+		}
+		if !hasAllLabels(conv.Labels, f.Labels) {
+			continue
+		}
+		if f.Query != "" {
+			ok, err := mirrorConversationMatches(m, conv, f.Query)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+		}
+		out = append(out, conv)
+	}
+	return out, nil
+}
+
+func conversationAssigneeID(conv api.Conversation) int {
+	if conv.AssigneeID != nil {
+		return *conv.AssigneeID
+	}
+	if assignee, ok := conv.Meta["assignee"].(map[string]any); ok {
+		return anyToInt(assignee["id"])
+	}
+	return 0
+}
+
+func hasAllLabels(have, want []string) bool {
+	for _, w := range want {
+		found := false
+		for _, h := range have {
+			if strings.EqualFold(h, w) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// mirrorConversationMatches reports whether query matches the conversation ID,
+// the contact's name/email/phone, or any mirrored message content.
+func mirrorConversationMatches(m *mirror.Mirror, conv api.Conversation, query string) (bool, error) {
+	q := strings.ToLower(strings.TrimSpace(query))
+	if q == "" {
+		return true, nil
+	}
+	if strconv.Itoa(conv.ID) == q {
+		return true, nil
+	}
+	if sender, ok := conv.Meta["sender"].(map[string]any); ok {
+		for _, key := range []string{"name", "email", "phone_number"} {
+			if s, ok := sender[key].(string); ok && strings.Contains(strings.ToLower(s), q) {
+				return true, nil
+			}
+		}
+	}
+	msgs, err := m.Messages(conv.ID)
+	if err != nil {
+		return false, err
+	}
+	for _, msg := range msgs {
+		if strings.Contains(strings.ToLower(msg.Content), q) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// searchMirrorContacts matches contacts by name, email, phone, or identifier.
+func searchMirrorContacts(contacts []api.Contact, query string) []api.Contact {
+	q := strings.ToLower(strings.TrimSpace(query))
+	var out []api.Contact
+	for _, c := range contacts {
+		for _, field := range []string{c.Name, c.Email, c.PhoneNumber, c.Identifier} {
+			if field != "" && strings.Contains(strings.ToLower(field), q) {
+				out = append(out, c)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// pageSlice returns the 1-based page of items and whether more pages exist.
+func pageSlice[T any](items []T, page, size int) ([]T, bool) {
+	if page < 1 {
+		page = 1
+	}
+	start := (page - 1) * size
+	if start >= len(items) {
+		return []T{}, false
+	}
+	end := min(start+size, len(items))
+	return items[start:end], end < len(items)
+}
+
+// searchMirror fills results for each search type from the offline mirror.
+// Conversation matching mirrors the API's list query: contact fields and message content.
+func searchMirror(m *mirror.Mirror, query string, types []string, limit int, results *SearchResults) error {
+	for _, st := range types {
+		switch st {
+		case "contacts":
+			contacts, err := m.Contacts()
+			if err != nil {
+				return err
+			}
+			matches := searchMirrorContacts(contacts, query)
+			if limit > 0 && len(matches) > limit {
+				matches = matches[:limit]
+			}
+			if matches != nil {
+				results.Contacts = matches
+			}
+			results.Summary["contacts"] = len(results.Contacts)
+
+		case "conversations":
+			convs, err := m.Conversations()
+			if err != nil {
+				return err
+			}
+			matches, err := filterMirrorConversations(m, convs, offlineConversationFilter{Query: query})
+			if err != nil {
+				return err
+			}
+			if limit > 0 && len(matches) > limit {
+				matches = matches[:limit]
+			}
+			results.Conversations = matches
+			results.Summary["conversations"] = len(results.Conversations)
+
+		case "senders":
+			senders, err := searchMirrorSenders(m, query, limit)
+			if err != nil {
+				return err
+			}
+			if senders != nil {
+				results.Senders = senders
+			}
+			results.Summary["senders"] = len(results.Senders)
+		}
+	}
+	return nil
+}
+
+// searchMirrorSenders finds message sender names (including LINE-style
+// "[Name] ..." prefixes) matching query across mirrored conversations.
+func searchMirrorSenders(m *mirror.Mirror, query string, limit int) ([]SenderMatch, error) {
+	convs, err := m.Conversations()
+	if err != nil {
+		return nil, err
+	}
+	q := strings.ToLower(query)
+	var out []SenderMatch
+	for _, conv := range convs {
+		msgs, err := m.Messages(conv.ID)
+		if err != nil {
+			return nil, err
+		}
+		contactName := ""
+		if sender, ok := conv.Meta["sender"].(map[string]any); ok {
+			contactName, _ = sender["name"].(string)
+		}
+		byName := make(map[string]*SenderMatch)
+		var order []string
+		note := func(name string, at int64) {
+			if name == "" || !strings.Contains(strings.ToLower(name), q) {
+				return
+			}
+			match, ok := byName[name]
+			if !ok {
+				match = &SenderMatch{Name: name, ContactID: conversationContactID(conv), ContactName: contactName, ConversationID: conv.ID}
+				byName[name] = match
+				order = append(order, name)
+			}
+			match.MessageCount++
+			match.LastMessageAt = max(match.LastMessageAt, at)
+		}
+		for _, msg := range msgs {
+			if msg.Sender != nil {
+				note(msg.Sender.Name, msg.CreatedAt)
+			}
+			if matches := bracketedNameRegex.FindStringSubmatch(msg.Content); len(matches) > 1 {
+				note(matches[1], msg.CreatedAt)
+			}
+		}
+		for _, name := range order {
+			out = append(out, *byName[name])
+			if limit > 0 && len(out) >= limit {
+				return out, nil
+			}
+		}
+	}
+	return out, nil
+}
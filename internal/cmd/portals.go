@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/chatwoot/chatwoot-cli/internal/api"
 	"github.com/chatwoot/chatwoot-cli/internal/dryrun"
+	"github.com/chatwoot/chatwoot-cli/internal/helpcenter"
 	"github.com/spf13/cobra"
 )
 
@@ -19,6 +21,14 @@ func validateArticleStatus(status int) error {
 	return nil
 }
 
+// orDash returns s, or "-" when it is empty.
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
 func newPortalsCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:     "portals",
@@ -37,6 +47,8 @@ func newPortalsCmd() *cobra.Command {
 	cmd.AddCommand(newPortalsSSLStatusCmd())
 	cmd.AddCommand(newPortalsArticlesCmd())
 	cmd.AddCommand(newPortalsCategoriesCmd())
+	cmd.AddCommand(newPortalsSyncCmd())
+	cmd.AddCommand(newPortalsTranslationsCmd())
 
 	return cmd
 }
@@ -452,11 +464,20 @@ func newPortalsArticlesCmd() *cobra.Command {
 }
 
 func newPortalsArticlesListCmd() *cobra.Command {
-	return &cobra.Command{
+	var locale string
+
+	cmd := &cobra.Command{
 		Use:     "list <portal-slug>",
 		Aliases: []string{"ls"},
 		Short:   "List articles in a portal",
-		Args:    cobra.ExactArgs(1),
+		Example: strings.TrimSpace(`
+  # All articles
+  cw portals articles list help
+
+  # Traditional Chinese articles only
+  cw portals articles list help --locale zh_TW
+`),
+		Args: cobra.ExactArgs(1),
 		RunE: RunE(func(cmd *cobra.Command, args []string) error {
 			portalSlug := args[0]
 			if err := validateSlug(portalSlug); err != nil {
@@ -468,7 +489,7 @@ func newPortalsArticlesListCmd() *cobra.Command {
 				return err
 			}
 
-			articles, err := client.Portals().Articles(cmdContext(cmd), portalSlug)
+			articles, err := client.Portals().ArticlesInLocale(cmdContext(cmd), portalSlug, helpcenter.NormalizeLocale(locale))
 			if err != nil {
 				return err
 			}
@@ -477,19 +498,31 @@ func newPortalsArticlesListCmd() *cobra.Command {
 				return printJSON(cmd, articles)
 			}
 
-			w := newTabWriterFromCmd(cmd)
-			defer func() { _ = w.Flush() }()
-			_, _ = fmt.Fprintln(w, "ID\tTITLE\tSLUG\tSTATUS\tVIEWS")
-			for _, article := range articles {
-				_, _ = fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%d\n", article.ID, article.Title, article.Slug, article.Status, article.Views)
-			}
+			printPortalArticles(cmd, articles)
 			return nil
 		}),
 	}
+
+	cmd.Flags().StringVar(&locale, "locale", "", "Only list articles in this locale (e.g., en, zh_TW)")
+	flagAlias(cmd.Flags(), "locale", "lc")
+	return cmd
+}
+
+// printPortalArticles writes the article table shared by list and search.
+func printPortalArticles(cmd *cobra.Command, articles []api.Article) {
+	w := newTabWriterFromCmd(cmd)
+	defer func() { _ = w.Flush() }()
+	_, _ = fmt.Fprintln(w, "ID\tLOCALE\tSLUG\tSTATUS\tVIEWS\tTITLE")
+	for _, article := range articles {
+		_, _ = fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%d\t%s\n", article.ID, orDash(article.Locale), article.Slug, article.Status, article.Views, article.Title)
+	}
 }
 
 func newPortalsArticlesSearchCmd() *cobra.Command {
-	var includeBody bool
+	var (
+		includeBody bool
+		locale      string
+	)
 
 	cmd := &cobra.Command{
 		Use:     "search <portal-slug> <query>",
@@ -501,6 +534,9 @@ func newPortalsArticlesSearchCmd() *cobra.Command {
 
   # Include article body in output
   cw portals articles search help-center "shipping" --include-body
+
+  # Search the Traditional Chinese articles
+  cw portals articles search help-center "退款" --locale zh-TW
 `),
 		Args: cobra.ExactArgs(2),
 		RunE: RunE(func(cmd *cobra.Command, args []string) error {
@@ -515,7 +551,7 @@ func newPortalsArticlesSearchCmd() *cobra.Command {
 				return err
 			}
 
-			articles, err := client.Portals().SearchArticles(cmdContext(cmd), portalSlug, query)
+			articles, err := client.Portals().SearchArticlesInLocale(cmdContext(cmd), portalSlug, query, helpcenter.NormalizeLocale(locale))
 			if err != nil {
 				return err
 			}
@@ -536,12 +572,7 @@ func newPortalsArticlesSearchCmd() *cobra.Command {
 					_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\n\n", article.Content)
 				}
 			} else {
-				w := newTabWriterFromCmd(cmd)
-				defer func() { _ = w.Flush() }()
-				_, _ = fmt.Fprintln(w, "ID\tTITLE\tSLUG\tSTATUS\tVIEWS")
-				for _, article := range articles {
-					_, _ = fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%d\n", article.ID, article.Title, article.Slug, article.Status, article.Views)
-				}
+				printPortalArticles(cmd, articles)
 			}
 			return nil
 		}),
@@ -549,6 +580,8 @@ func newPortalsArticlesSearchCmd() *cobra.Command {
 
 	cmd.Flags().BoolVar(&includeBody, "include-body", false, "Include article body content in output")
 	flagAlias(cmd.Flags(), "include-body", "ib")
+	cmd.Flags().StringVar(&locale, "locale", "", "Only search articles in this locale (e.g., en, zh_TW)")
+	flagAlias(cmd.Flags(), "locale", "lc")
 	return cmd
 }
 
@@ -587,6 +620,12 @@ func newPortalsArticlesGetCmd() *cobra.Command {
 			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Title: %s\n", article.Title)
 			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Slug: %s\n", article.Slug)
 			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Status: %s\n", article.Status)
+			if article.Locale != "" {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Locale: %s\n", article.Locale)
+			}
+			if article.AssociatedArticleID != 0 {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Translation of: %d\n", article.AssociatedArticleID)
+			}
 			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Views: %d\n", article.Views)
 			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "\nContent:\n%s\n", article.Content)
 
@@ -597,19 +636,26 @@ func newPortalsArticlesGetCmd() *cobra.Command {
 
 func newPortalsArticlesCreateCmd() *cobra.Command {
 	var (
-		title      string
-		content    string
-		slug       string
-		categoryID int
-		status     int
+		title         string
+		content       string
+		slug          string
+		categoryID    int
+		status        int
+		locale        string
+		translationOf int
 	)
 
 	cmd := &cobra.Command{
 		Use:     "create <portal-slug>",
 		Aliases: []string{"mk"},
 		Short:   "Create a new article",
-		Example: `  cw portals articles create help --title "Getting Started" --content "..." --category-id 1`,
-		Args:    cobra.ExactArgs(1),
+		Example: strings.TrimSpace(`
+  cw portals articles create help --title "Getting Started" --content "..." --category-id 1
+
+  # Add a Traditional Chinese translation of article 12
+  cw portals articles create help --title "開始使用" --content "..." --locale zh_TW --translation-of 12
+`),
+		Args: cobra.ExactArgs(1),
 		RunE: RunE(func(cmd *cobra.Command, args []string) error {
 			portalSlug := args[0]
 			if err := validateSlug(portalSlug); err != nil {
@@ -641,6 +687,15 @@ func newPortalsArticlesCreateCmd() *cobra.Command {
 				}
 				params["status"] = status
 			}
+			if locale != "" {
+				params["locale"] = helpcenter.NormalizeLocale(locale)
+			}
+			if translationOf != 0 {
+				if translationOf < 0 {
+					return fmt.Errorf("invalid translation-of %d: must be an article ID", translationOf)
+				}
+				params["associated_article_id"] = translationOf
+			}
 
 			client, err := getClient()
 			if err != nil {
@@ -682,6 +737,10 @@ func newPortalsArticlesCreateCmd() *cobra.Command {
 	flagAlias(cmd.Flags(), "slug", "sl")
 	flagAlias(cmd.Flags(), "status", "st")
 	flagAlias(cmd.Flags(), "category-id", "cid")
+	cmd.Flags().StringVar(&locale, "locale", "", "Locale (e.g., en, zh_TW)")
+	flagAlias(cmd.Flags(), "locale", "lc")
+	cmd.Flags().IntVar(&translationOf, "translation-of", 0, "ID of the article this one translates")
+	flagAlias(cmd.Flags(), "translation-of", "tof")
 
 	return cmd
 }
@@ -692,6 +751,7 @@ func newPortalsArticlesUpdateCmd() *cobra.Command {
 		content string
 		slug    string
 		status  int
+		locale  string
 	)
 
 	cmd := &cobra.Command{
@@ -726,6 +786,9 @@ func newPortalsArticlesUpdateCmd() *cobra.Command {
 				}
 				params["status"] = status
 			}
+			if locale != "" {
+				params["locale"] = helpcenter.NormalizeLocale(locale)
+			}
 
 			if len(params) == 0 {
 				return fmt.Errorf("at least one field must be specified")
@@ -770,6 +833,8 @@ func newPortalsArticlesUpdateCmd() *cobra.Command {
 	flagAlias(cmd.Flags(), "content", "ct")
 	flagAlias(cmd.Flags(), "slug", "sl")
 	flagAlias(cmd.Flags(), "status", "st")
+	cmd.Flags().StringVar(&locale, "locale", "", "Locale (e.g., en, zh_TW)")
+	flagAlias(cmd.Flags(), "locale", "lc")
 
 	return cmd
 }
@@ -884,7 +949,9 @@ func newPortalsCategoriesCmd() *cobra.Command {
 }
 
 func newPortalsCategoriesListCmd() *cobra.Command {
-	return &cobra.Command{
+	var locale string
+
+	cmd := &cobra.Command{
 		Use:     "list <portal-slug>",
 		Aliases: []string{"ls"},
 		Short:   "List categories in a portal",
@@ -900,7 +967,7 @@ func newPortalsCategoriesListCmd() *cobra.Command {
 				return err
 			}
 
-			categories, err := client.Portals().Categories(cmdContext(cmd), portalSlug)
+			categories, err := client.Portals().CategoriesInLocale(cmdContext(cmd), portalSlug, helpcenter.NormalizeLocale(locale))
 			if err != nil {
 				return err
 			}
@@ -911,13 +978,17 @@ func newPortalsCategoriesListCmd() *cobra.Command {
 
 			w := newTabWriterFromCmd(cmd)
 			defer func() { _ = w.Flush() }()
-			_, _ = fmt.Fprintln(w, "ID\tNAME\tSLUG\tPOSITION")
+			_, _ = fmt.Fprintln(w, "ID\tLOCALE\tSLUG\tPOSITION\tNAME")
 			for _, category := range categories {
-				_, _ = fmt.Fprintf(w, "%d\t%s\t%s\t%d\n", category.ID, category.Name, category.Slug, category.Position)
+				_, _ = fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%s\n", category.ID, orDash(category.Locale), category.Slug, category.Position, category.Name)
 			}
 			return nil
 		}),
 	}
+
+	cmd.Flags().StringVar(&locale, "locale", "", "Only list categories in this locale (e.g., en, zh_TW)")
+	flagAlias(cmd.Flags(), "locale", "lc")
+	return cmd
 }
 
 func newPortalsCategoriesGetCmd() *cobra.Command {
@@ -1001,7 +1072,7 @@ func newPortalsCategoriesCreateCmd() *cobra.Command {
 				params["position"] = position
 			}
 			if locale != "" {
-				params["locale"] = locale
+				params["locale"] = helpcenter.NormalizeLocale(locale)
 			}
 
 			client, err := getClient()
@@ -1039,7 +1110,7 @@ func newPortalsCategoriesCreateCmd() *cobra.Command {
 	cmd.Flags().StringVar(&description, "description", "", "Category description")
 	flagAlias(cmd.Flags(), "description", "desc")
 	cmd.Flags().IntVar(&position, "position", 0, "Sort position")
-	cmd.Flags().StringVar(&locale, "locale", "", "Locale (e.g., en)")
+	cmd.Flags().StringVar(&locale, "locale", "", "Locale (e.g., en, zh_TW)")
 	flagAlias(cmd.Flags(), "name", "nm")
 	flagAlias(cmd.Flags(), "slug", "sl")
 	flagAlias(cmd.Flags(), "position", "pos")
@@ -0,0 +1,231 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/chatwoot/chatwoot-cli/internal/dryrun"
+	"github.com/chatwoot/chatwoot-cli/internal/helpcenter"
+)
+
+func newPortalsSyncCmd() *cobra.Command {
+	var (
+		dir   string
+		pull  bool
+		prune bool
+		force bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "sync <portal-slug>",
+		Short: "Sync portal categories and articles with a Markdown directory",
+		Long: strings.TrimSpace(`
+Keep a help center portal in a directory of Markdown files:
+
+  docs/
+    getting-started.md        article without a category
+    billing/                  category "billing"
+      _category.yaml          name, slug, locale, description, position
+      refunds.md              article with front matter
+
+Each article starts with YAML front matter:
+
+  ---
+  title: Refunds
+  slug: refunds
+  status: published          # draft, published or archived
+  position: 2                # order within the category
+  locale: en
+  ---
+
+  Article body in Markdown.
+
+By default sync pushes: it compares the directory with the portal, prints
+the plan with content diffs, and applies it. Articles are matched by slug and
+categories by slug and locale. --pull writes the portal to the directory
+instead, leaving unchanged files untouched.
+
+Nothing is deleted unless --prune is set: a push then deletes portal articles
+and categories missing from the directory (after confirmation), and a pull
+deletes local files missing from the portal.
+`),
+		Example: strings.TrimSpace(`
+  # Start from the current portal
+  cw po sync help --dir docs/ --pull
+
+  # Preview, then publish edits from Git
+  cw po sync help --dir docs/ --dry-run
+  cw po sync help --dir docs/
+
+  # Also delete portal articles removed from the directory
+  cw po sync help --dir docs/ --prune --force
+`),
+		Args: cobra.ExactArgs(1),
+		RunE: RunE(func(cmd *cobra.Command, args []string) error {
+			portalSlug := args[0]
+			if err := validateSlug(portalSlug); err != nil {
+				return err
+			}
+			if dir == "" {
+				return fmt.Errorf("--dir is required")
+			}
+
+			client, err := getClient()
+			if err != nil {
+				return err
+			}
+			ctx := cmdContext(cmd)
+			categories, err := client.Portals().Categories(ctx, portalSlug)
+			if err != nil {
+				return fmt.Errorf("failed to list categories: %w", err)
+			}
+			articles, err := client.Portals().Articles(ctx, portalSlug)
+			if err != nil {
+				return fmt.Errorf("failed to list articles: %w", err)
+			}
+			remote := helpcenter.FromPortal(categories, articles)
+
+			if pull {
+				res, err := helpcenter.WriteDir(dir, remote, prune, dryrun.IsEnabled(ctx))
+				if err != nil {
+					return err
+				}
+				return printPortalPull(cmd, dir, res, dryrun.IsEnabled(ctx))
+			}
+
+			if _, err := os.Stat(dir); err != nil {
+				return fmt.Errorf("read %s: %w (use --pull to create it from the portal)", dir, err)
+			}
+			local, err := helpcenter.ReadDir(dir)
+			if err != nil {
+				return err
+			}
+			plan, err := helpcenter.Diff(remote, local, helpcenter.DiffOptions{Prune: prune})
+			if err != nil {
+				return err
+			}
+
+			summary := plan.Summary()
+			if ok, err := maybeDryRun(cmd, &dryrun.Preview{
+				Operation:   "sync",
+				Resource:    "portal",
+				Description: plan.Text(),
+				Details: map[string]any{
+					"portal_slug": portalSlug,
+					"summary":     summary,
+					"changes":     plan.Changes,
+					"untracked":   plan.Untracked,
+				},
+			}); ok {
+				return err
+			}
+
+			if plan.Empty() {
+				if isJSON(cmd) {
+					return printJSON(cmd, map[string]any{"results": []helpcenter.Result{}, "summary": summary, "untracked": plan.Untracked})
+				}
+				_, _ = fmt.Fprintln(cmd.OutOrStdout(), "No changes. Portal matches the directory.")
+				if len(plan.Untracked) > 0 {
+					_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%d portal items are not in the directory (kept; use --prune to delete): %s\n",
+						len(plan.Untracked), strings.Join(plan.Untracked, ", "))
+				}
+				return nil
+			}
+			if !isJSON(cmd) {
+				_, _ = fmt.Fprintln(cmd.OutOrStdout(), plan.Text())
+			}
+
+			if deletes := summary[helpcenter.ActionDelete]; deletes > 0 {
+				ok, err := confirmAction(cmd, confirmOptions{
+					Prompt:              fmt.Sprintf("Sync will delete %d articles or categories from %s. Continue? (y/N): ", deletes, portalSlug),
+					CancelMessage:       "Sync cancelled.",
+					Force:               force,
+					RequireForceForJSON: true,
+				})
+				if err != nil {
+					return err
+				}
+				if !ok {
+					return nil
+				}
+			}
+
+			results, failed := helpcenter.Apply(ctx, client, portalSlug, plan)
+
+			if isJSON(cmd) {
+				if err := printJSON(cmd, map[string]any{"results": results, "summary": summary, "failed": failed, "untracked": plan.Untracked}); err != nil {
+					return err
+				}
+			} else {
+				out := cmd.OutOrStdout()
+				for _, r := range results {
+					switch {
+					case r.Error != "":
+						_, _ = fmt.Fprintf(out, "%s %s %q: failed: %s\n", r.Action, r.Kind, r.Key, r.Error)
+					case r.ID != 0:
+						_, _ = fmt.Fprintf(out, "%s %s %q (id %d)\n", portalSyncPastTense(r.Action), r.Kind, r.Key, r.ID)
+					default:
+						_, _ = fmt.Fprintf(out, "%s %s %q\n", portalSyncPastTense(r.Action), r.Kind, r.Key)
+					}
+				}
+				_, _ = fmt.Fprintf(out, "Applied %d changes (%d failed).\n", len(results)-failed, failed)
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d of %d changes failed", failed, len(results))
+			}
+			return nil
+		}),
+	}
+
+	cmd.Flags().StringVar(&dir, "dir", "", "Markdown directory (required)")
+	cmd.Flags().BoolVar(&pull, "pull", false, "Write the portal to the directory instead of pushing")
+	cmd.Flags().BoolVar(&prune, "prune", false, "Delete what the other side no longer has")
+	cmd.Flags().BoolVar(&force, "force", false, "Skip the delete confirmation prompt")
+	flagAlias(cmd.Flags(), "force", "fc")
+	registerCommandContract(cmd, true, true)
+
+	return cmd
+}
+
+func printPortalPull(cmd *cobra.Command, dir string, res *helpcenter.PullResult, dryRun bool) error {
+	if isJSON(cmd) {
+		return printJSON(cmd, map[string]any{"dir": dir, "dry_run": dryRun, "written": res.Written, "unchanged": res.Unchanged, "removed": res.Removed, "stale": res.Stale})
+	}
+	out := cmd.OutOrStdout()
+	verb, removed := "Wrote", "Removed"
+	if dryRun {
+		verb, removed = "Would write", "Would remove"
+	}
+	for _, p := range res.Written {
+		_, _ = fmt.Fprintf(out, "  %s\n", p)
+	}
+	for _, p := range res.Removed {
+		_, _ = fmt.Fprintf(out, "- %s\n", p)
+	}
+	_, _ = fmt.Fprintf(out, "%s %d files to %s (%d unchanged).\n", verb, len(res.Written), dir, res.Unchanged)
+	if len(res.Removed) > 0 {
+		_, _ = fmt.Fprintf(out, "%s %d files.\n", removed, len(res.Removed))
+	}
+	if len(res.Stale) > 0 {
+		_, _ = fmt.Fprintf(out, "%s %d local files are not in the portal (kept; use --prune to delete): %s\n",
+			yellow("!"), len(res.Stale), strings.Join(res.Stale, ", "))
+	}
+	return nil
+}
+
+func portalSyncPastTense(action helpcenter.Action) string {
+	switch action {
+	case helpcenter.ActionCreate:
+		return "created"
+	case helpcenter.ActionUpdate:
+		return "updated"
+	case helpcenter.ActionReorder:
+		return "reordered"
+	case helpcenter.ActionDelete:
+		return "deleted"
+	}
+	return string(action)
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const portalSyncCategories = `[{"id": 1, "name": "Billing", "slug": "billing", "locale": "en", "position": 1}]`
+
+const portalSyncArticles = `[
+	{"id": 10, "category_id": 1, "title": "Refunds", "slug": "refunds", "status": "published", "position": 1, "locale": "en", "content": "Old text."},
+	{"id": 11, "title": "Welcome", "slug": "welcome", "status": "published", "content": "Hi."}
+]`
+
+func TestPortalsSyncPullThenPush(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		calls []string
+	)
+	record := func(body string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			data, _ := io.ReadAll(r.Body)
+			mu.Lock()
+			calls = append(calls, r.Method+" "+strings.TrimPrefix(r.URL.Path, "/api/v1/accounts/1/portals/help")+" "+string(data))
+			mu.Unlock()
+			jsonResponse(200, body)(w, r)
+		}
+	}
+	setupTestEnvWithHandler(t, newRouteHandler().
+		On("GET", "/api/v1/accounts/1/portals/help/categories", jsonResponse(200, portalSyncCategories)).
+		On("GET", "/api/v1/accounts/1/portals/help/articles", jsonResponse(200, portalSyncArticles)).
+		On("POST", "/api/v1/accounts/1/portals/help/articles", record(`{"id": 12, "slug": "invoices"}`)).
+		On("PATCH", "/api/v1/accounts/1/portals/help/articles/10", record(`{"id": 10}`)).
+		On("POST", "/api/v1/accounts/1/portals/help/articles/reorder", record(`{}`)).
+		On("DELETE", "/api/v1/accounts/1/portals/help/articles/11", record(``)))
+
+	dir := filepath.Join(t.TempDir(), "docs")
+	output := captureStdout(t, func() {
+		if err := Execute(context.Background(), []string{"portals", "sync", "help", "--dir", dir, "--pull"}); err != nil {
+			t.Errorf("pull failed: %v", err)
+		}
+	})
+	if !strings.Contains(output, "Wrote 3 files") {
+		t.Fatalf("unexpected pull output: %s", output)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "billing", "refunds.md")); err != nil {
+		t.Fatalf("article not written: %v", err)
+	}
+
+	// Edit an article, add one ahead of it, and remove the uncategorized one.
+	if err := os.WriteFile(filepath.Join(dir, "billing", "refunds.md"),
+		[]byte("---\ntitle: Refunds\nslug: refunds\nstatus: published\nposition: 2\n---\n\nNew text.\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "billing", "invoices.md"), []byte("---\ntitle: Invoices\nposition: 1\n---\nPDFs.\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(dir, "welcome.md")); err != nil {
+		t.Fatal(err)
+	}
+
+	output = captureStdout(t, func() {
+		if err := Execute(context.Background(), []string{"portals", "sync", "help", "--dir", dir, "--dry-run"}); err != nil {
+			t.Errorf("dry run failed: %v", err)
+		}
+	})
+	if len(calls) != 0 {
+		t.Fatalf("dry run made changes: %v", calls)
+	}
+	for _, want := range []string{`~ article "refunds" (content)`, "-Old text.", "+New text.", `+ article "invoices"`, "article welcome is not in the directory"} {
+		if !strings.Contains(output, want) {
+			t.Fatalf("dry run output missing %q:\n%s", want, output)
+		}
+	}
+
+	output = captureStdout(t, func() {
+		if err := Execute(context.Background(), []string{"portals", "sync", "help", "--dir", dir, "--prune", "--force"}); err != nil {
+			t.Errorf("push failed: %v", err)
+		}
+	})
+	if !strings.Contains(output, "Applied 4 changes (0 failed)") {
+		t.Fatalf("unexpected push output: %s", output)
+	}
+	if len(calls) != 4 {
+		t.Fatalf("expected 4 API writes, got %v", calls)
+	}
+	var created map[string]any
+	if err := json.Unmarshal([]byte(strings.SplitN(calls[0], " ", 3)[2]), &created); err != nil {
+		t.Fatal(err)
+	}
+	if created["category_id"] != float64(1) || created["status"] != float64(0) || created["locale"] != "en" || created["content"] != "PDFs." {
+		t.Fatalf("unexpected create body: %v", created)
+	}
+	if !strings.HasPrefix(calls[1], `PATCH /articles/10 {"content":"New text."}`) {
+		t.Fatalf("unexpected update: %s", calls[1])
+	}
+	if !strings.HasPrefix(calls[2], `POST /articles/reorder {"article_ids":[12,10]}`) {
+		t.Fatalf("unexpected reorder: %s", calls[2])
+	}
+	if !strings.HasPrefix(calls[3], "DELETE /articles/11") {
+		t.Fatalf("unexpected delete: %s", calls[3])
+	}
+}
+
+func TestPortalsSyncPushRequiresDirectory(t *testing.T) {
+	setupTestEnvWithHandler(t, newRouteHandler().
+		On("GET", "/api/v1/accounts/1/portals/help/categories", jsonResponse(200, `[]`)).
+		On("GET", "/api/v1/accounts/1/portals/help/articles", jsonResponse(200, `[]`)))
+
+	err := Execute(context.Background(), []string{"portals", "sync", "help", "--dir", filepath.Join(t.TempDir(), "missing")})
+	if err == nil || !strings.Contains(err.Error(), "use --pull") {
+		t.Fatalf("expected missing directory error, got %v", err)
+	}
+}
@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/chatwoot/chatwoot-cli/internal/helpcenter"
+)
+
+func newPortalsTranslationsCmd() *cobra.Command {
+	var (
+		source  string
+		locales []string
+		all     bool
+	)
+
+	cmd := &cobra.Command{
+		Use:     "translations <portal-slug>",
+		Aliases: []string{"tl"},
+		Short:   "Report missing and stale article translations",
+		Long: strings.TrimSpace(`
+Pair a portal's articles across locales and flag the translations that are
+missing or older than their source.
+
+Articles linked as translations (created with --translation-of) are paired
+by that link. Other articles pair by slug, ignoring a locale suffix, so
+"refunds" (en) and "refunds-zh-tw" (zh_TW) are the same article.
+
+A translation is stale when the source article was updated after it. The
+source locale defaults to the locale with the most articles.
+`),
+		Example: strings.TrimSpace(`
+  # Everything that needs translating
+  cw po translations help
+
+  # Compare English against Traditional and Simplified Chinese
+  cw po translations help --source en --locales zh_TW,zh_CN
+
+  # Include complete articles, as JSON
+  cw po translations help --all -o json
+`),
+		Args: cobra.ExactArgs(1),
+		RunE: RunE(func(cmd *cobra.Command, args []string) error {
+			portalSlug := args[0]
+			if err := validateSlug(portalSlug); err != nil {
+				return err
+			}
+
+			client, err := getClient()
+			if err != nil {
+				return err
+			}
+			articles, err := client.Portals().Articles(cmdContext(cmd), portalSlug)
+			if err != nil {
+				return fmt.Errorf("failed to list articles: %w", err)
+			}
+
+			report := helpcenter.Translations(articles, helpcenter.TranslationOptions{Source: source, Locales: locales})
+			if !all {
+				groups := report.Groups[:0:0]
+				for _, g := range report.Groups {
+					if !g.OK() {
+						groups = append(groups, g)
+					}
+				}
+				report.Groups = groups
+			}
+
+			if isJSON(cmd) {
+				return printJSON(cmd, report)
+			}
+			return printTranslationReport(cmd, report)
+		}),
+	}
+
+	cmd.Flags().StringVar(&source, "source", "", "Locale translations are compared against (default: the locale with the most articles)")
+	cmd.Flags().StringSliceVar(&locales, "locales", nil, "Locales every article should exist in (default: all locales in the portal)")
+	cmd.Flags().BoolVar(&all, "all", false, "Also list articles that are fully translated")
+	flagAlias(cmd.Flags(), "source", "src")
+	flagAlias(cmd.Flags(), "locales", "lcs")
+
+	return cmd
+}
+
+func printTranslationReport(cmd *cobra.Command, report *helpcenter.TranslationReport) error {
+	out := cmd.OutOrStdout()
+	if report.Source == "" {
+		_, _ = fmt.Fprintln(out, "No articles with a locale found.")
+		return nil
+	}
+
+	if len(report.Groups) > 0 {
+		w := newTabWriterFromCmd(cmd)
+		header := []string{"KEY"}
+		for _, l := range report.Locales {
+			header = append(header, strings.ToUpper(l))
+		}
+		header = append(header, "TITLE")
+		_, _ = fmt.Fprintln(w, strings.Join(header, "\t"))
+
+		for _, g := range report.Groups {
+			stale := map[string]int64{}
+			for _, s := range g.Stale {
+				stale[s.Locale] = s.BehindSeconds
+			}
+			row := []string{g.Key}
+			for _, l := range report.Locales {
+				a, ok := g.Articles[l]
+				switch {
+				case !ok:
+					row = append(row, "missing")
+				case stale[l] > 0:
+					row = append(row, fmt.Sprintf("#%d stale %s", a.ID, formatDuration(stale[l])))
+				default:
+					row = append(row, fmt.Sprintf("#%d", a.ID))
+				}
+			}
+			// Titles go last: CJK titles are wider than tabwriter assumes.
+			title := g.Articles[report.Source].Title
+			if title == "" {
+				for _, l := range report.Locales {
+					if a, ok := g.Articles[l]; ok {
+						title = a.Title
+						break
+					}
+				}
+			}
+			row = append(row, title)
+			_, _ = fmt.Fprintln(w, strings.Join(row, "\t"))
+		}
+		_ = w.Flush()
+		_, _ = fmt.Fprintln(out)
+	}
+
+	s := report.Summary
+	_, _ = fmt.Fprintf(out, "%d articles across %s (source %s): %d complete, %d missing translations, %d stale.\n",
+		s.Groups, strings.Join(report.Locales, ", "), report.Source, s.Complete, s.Missing, s.Stale)
+	return nil
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+const portalTranslationArticles = `[
+	{"id": 1, "title": "Refunds", "slug": "refunds", "status": "published", "locale": "en", "updated_at": 1700086400},
+	{"id": 2, "title": "退款", "slug": "refunds-zh-tw", "status": "published", "locale": "zh_TW", "updated_at": 1700000000},
+	{"id": 3, "title": "Invoices", "slug": "invoices", "status": "published", "locale": "en", "updated_at": 1700000000},
+	{"id": 4, "title": "發票", "slug": "fa-piao", "status": "draft", "locale": "zh_TW", "associated_article_id": 3, "updated_at": 1700000000},
+	{"id": 5, "title": "Shipping", "slug": "shipping", "status": "published", "locale": "en", "updated_at": 1700000000}
+]`
+
+func TestPortalsTranslationsReport(t *testing.T) {
+	setupTestEnvWithHandler(t, newRouteHandler().
+		On("GET", "/api/v1/accounts/1/portals/help/articles", jsonResponse(200, portalTranslationArticles)))
+
+	output := captureStdout(t, func() {
+		if err := Execute(context.Background(), []string{"portals", "translations", "help"}); err != nil {
+			t.Errorf("translations failed: %v", err)
+		}
+	})
+	for _, want := range []string{"KEY", "ZH_TW", "#2 stale 24h", "missing", "Shipping",
+		"3 articles across en, zh_TW (source en): 1 complete, 1 missing translations, 1 stale."} {
+		if !strings.Contains(output, want) {
+			t.Fatalf("output missing %q:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "invoices") {
+		t.Fatalf("complete article listed without --all:\n%s", output)
+	}
+
+	output = captureStdout(t, func() {
+		if err := Execute(context.Background(), []string{"po", "translations", "help", "--all", "-o", "json"}); err != nil {
+			t.Errorf("translations json failed: %v", err)
+		}
+	})
+	var report struct {
+		Source string `json:"source_locale"`
+		Groups []struct {
+			Key      string                    `json:"key"`
+			Articles map[string]map[string]any `json:"articles"`
+		} `json:"groups"`
+	}
+	if err := json.Unmarshal([]byte(output), &report); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, output)
+	}
+	if report.Source != "en" || len(report.Groups) != 3 || report.Groups[0].Key != "invoices" || report.Groups[0].Articles["zh_TW"]["id"] != float64(4) {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+}
+
+func TestPortalsArticlesListLocale(t *testing.T) {
+	var gotLocale string
+	setupTestEnvWithHandler(t, newRouteHandler().
+		On("GET", "/api/v1/accounts/1/portals/help/articles", func(w http.ResponseWriter, r *http.Request) {
+			gotLocale = r.URL.Query().Get("locale")
+			jsonResponse(200, `[{"id": 2, "title": "退款", "slug": "refunds-zh-tw", "status": "published", "locale": "zh_TW"}]`)(w, r)
+		}))
+
+	output := captureStdout(t, func() {
+		if err := Execute(context.Background(), []string{"po", "articles", "ls", "help", "--locale", "zh-tw"}); err != nil {
+			t.Errorf("list failed: %v", err)
+		}
+	})
+	if gotLocale != "zh_TW" {
+		t.Fatalf("locale query = %q, want zh_TW", gotLocale)
+	}
+	if !strings.Contains(output, "LOCALE") || !strings.Contains(output, "zh_TW") || !strings.Contains(output, "退款") {
+		t.Fatalf("unexpected output:\n%s", output)
+	}
+}
@@ -13,8 +13,10 @@ import (
 	"github.com/spf13/cobra"
 )
 
-// replySideEffects holds optional post-send side-effect parameters for reply.
+// replySideEffects holds optional post-send side-effect parameters for reply,
+// plus the canned response to render once the conversation is known.
 type replySideEffects struct {
+	canned    string
 	labels    []string
 	priority  string
 	snoozeFor string
@@ -33,6 +35,7 @@ func newReplyCmd() *cobra.Command {
 		labels         []string
 		priority       string
 		snoozeFor      string
+		cannedCode     string
 	)
 
 	cmd := &cobra.Command{
@@ -60,16 +63,24 @@ If multiple open conversations exist for the contact, disambiguation is required
 
   # Send a private note (internal, not visible to customer)
   cw reply "welgrow" --content "Internal note" --private
+
+  # Reply with a canned response, filling in {{contact.name}} and friends
+  cw reply "welgrow" --canned thanks --dry-run
 `),
 		Args: cobra.MaximumNArgs(1),
 		RunE: RunE(func(cmd *cobra.Command, args []string) error {
 			// Validate inputs
-			if content == "" {
-				return fmt.Errorf("--content is required")
+			if content != "" && cannedCode != "" {
+				return fmt.Errorf("--content and --canned cannot be used together")
+			}
+			if content == "" && cannedCode == "" {
+				return fmt.Errorf("--content is required (or use --canned)")
 			}
 
-			if err := validation.ValidateMessageContent(content); err != nil {
-				return err
+			if content != "" {
+				if err := validation.ValidateMessageContent(content); err != nil {
+					return err
+				}
 			}
 
 			// Validate side-effect flags before sending so we fail fast.
@@ -88,7 +99,7 @@ If multiple open conversations exist for the contact, disambiguation is required
 				}
 			}
 
-			se := replySideEffects{labels: labels, priority: priority, snoozeFor: snoozeFor, pending: pending}
+			se := replySideEffects{canned: cannedCode, labels: labels, priority: priority, snoozeFor: snoozeFor, pending: pending}
 
 			// Determine the mode: direct conversation, contact ID, or search
 			if conversationID == 0 && contactID == 0 && len(args) == 0 {
@@ -160,7 +171,9 @@ If multiple open conversations exist for the contact, disambiguation is required
 		}),
 	}
 
-	cmd.Flags().StringVarP(&content, "content", "c", "", "Message content (required)")
+	cmd.Flags().StringVarP(&content, "content", "c", "", "Message content (required unless --canned)")
+	cmd.Flags().StringVar(&cannedCode, "canned", "", "Send a canned response by short code, rendering {{contact.name}}-style variables")
+	flagAlias(cmd.Flags(), "canned", "cn")
 	cmd.Flags().BoolVarP(&resolve, "resolve", "R", false, "Resolve the conversation after replying")
 	cmd.Flags().BoolVarP(&pending, "pending", "p", false, "Set conversation to pending after replying")
 	cmd.Flags().IntVarP(&contactID, "contact-id", "C", 0, "Skip search, use specific contact ID")
@@ -251,9 +264,19 @@ func isDryRun(cmd *cobra.Command) bool {
 func replyToConversation(cmd *cobra.Command, client *api.Client, conversationID int, content string, private, resolve bool, contact *api.TriageContact, se replySideEffects) error {
 	ctx := cmdContext(cmd)
 
+	if se.canned != "" {
+		var err error
+		if content, err = renderCannedResponse(ctx, client, se.canned, conversationID); err != nil {
+			return err
+		}
+		if err := validation.ValidateMessageContent(content); err != nil {
+			return err
+		}
+	}
+
 	// Check for dry-run mode BEFORE sending
 	if isDryRun(cmd) {
-		return printReplyDryRun(cmd, client, conversationID, content, private, contact)
+		return printReplyDryRun(cmd, client, conversationID, content, private, contact, se.canned)
 	}
 
 	// Send the message
@@ -406,7 +429,7 @@ func outputDisambiguation(cmd *cobra.Command, disambiguationType string, contact
 }
 
 // printReplyDryRun displays a preview of the message without sending it
-func printReplyDryRun(cmd *cobra.Command, client *api.Client, conversationID int, content string, private bool, contact *api.TriageContact) error {
+func printReplyDryRun(cmd *cobra.Command, client *api.Client, conversationID int, content string, private bool, contact *api.TriageContact, cannedCode string) error {
 	ctx := cmdContext(cmd)
 
 	// Fetch conversation to get inbox info
@@ -476,6 +499,9 @@ func printReplyDryRun(cmd *cobra.Command, client *api.Client, conversationID int
 			"private":         private,
 			"character_count": charCount,
 		}
+		if cannedCode != "" {
+			payload["canned"] = cannedCode
+		}
 		if contact != nil {
 			payload["contact"] = map[string]any{
 				"id":    contact.ID,
@@ -516,6 +542,9 @@ func printReplyDryRun(cmd *cobra.Command, client *api.Client, conversationID int
 	}
 	_, _ = fmt.Fprintf(out, "Conversation: %d\n", conversationID)
 	_, _ = fmt.Fprintf(out, "Type: %s\n", messageType)
+	if cannedCode != "" {
+		_, _ = fmt.Fprintf(out, "Canned response: %s\n", cannedCode)
+	}
 
 	_, _ = fmt.Fprintln(out)
 	_, _ = fmt.Fprintln(out, "Message Preview:")
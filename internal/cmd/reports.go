@@ -9,6 +9,7 @@ import (
 
 	"github.com/chatwoot/chatwoot-cli/internal/api"
 	"github.com/chatwoot/chatwoot-cli/internal/cli"
+	"github.com/chatwoot/chatwoot-cli/internal/outfmt"
 	"github.com/spf13/cobra"
 )
 
@@ -31,6 +32,7 @@ Available report types:
   inbox-label-matrix - Conversation counts grouped by inbox and label
   response-time      - First response time distribution by channel
   outgoing-messages  - Outgoing message counts (by agent/team/inbox/label)
+  serve              - Serve live and summary metrics for Prometheus
 
 Date parameters use Unix timestamps. Use --from and --to flags with dates like
 "2024-01-01" or relative expressions like "yesterday"; values are converted
@@ -49,6 +51,7 @@ to timestamps automatically.`,
 	cmd.AddCommand(newReportsInboxLabelMatrixCmd())
 	cmd.AddCommand(newReportsResponseTimeCmd())
 	cmd.AddCommand(newReportsOutgoingMessagesCmd())
+	cmd.AddCommand(newReportsServeCmd())
 
 	return cmd
 }
@@ -111,6 +114,7 @@ func newReportsSummaryCmd() *cobra.Command {
 	var from string
 	var to string
 	var id string
+	var compare string
 
 	cmd := &cobra.Command{
 		Use:   "summary",
@@ -122,9 +126,16 @@ Report types:
   agent   - Specific agent summary (requires --id)
   inbox   - Specific inbox summary (requires --id)
   label   - Specific label summary (requires --id)
-  team    - Specific team summary (requires --id)`,
+  team    - Specific team summary (requires --id)
+
+With --compare, the report is also fetched for a second period and each metric
+is shown with its absolute and percentage change:
+  previous  - the period of the same length just before --from
+  yoy       - the same dates one year earlier
+  FROM..TO  - an explicit range, e.g. 2024-01-01..2024-01-31`,
 		Example: `  cw reports summary --type account --from 2024-01-01 --to 2024-01-31
-  cw reports summary --type agent --id 123 --from 2024-01-01 --to 2024-01-31`,
+  cw reports summary --type agent --id 123 --from 2024-01-01 --to 2024-01-31
+  cw reports summary --type account --from 2024-02-05 --to 2024-02-12 --compare previous`,
 		RunE: RunE(func(cmd *cobra.Command, args []string) error {
 			if reportType == "" {
 				return fmt.Errorf("--type is required (account, agent, inbox, label, or team)")
@@ -136,6 +147,26 @@ Report types:
 				return fmt.Errorf("--to is required (format: YYYY-MM-DD)")
 			}
 
+			if compare != "" {
+				current, previous, err := reportComparePeriods(from, to, compare)
+				if err != nil {
+					return err
+				}
+				client, err := getClient()
+				if err != nil {
+					return err
+				}
+				cur, err := client.Reports().Summary(cmdContext(cmd), reportType, current.sinceTS(), current.untilTS(), id)
+				if err != nil {
+					return err
+				}
+				prev, err := client.Reports().Summary(cmdContext(cmd), reportType, previous.sinceTS(), previous.untilTS(), id)
+				if err != nil {
+					return fmt.Errorf("previous period: %w", err)
+				}
+				return renderReportSummaryComparison(cmd, current, previous, cur, prev)
+			}
+
 			sinceTS, err := parseDate(from)
 			if err != nil {
 				return err
@@ -155,7 +186,7 @@ Report types:
 				return err
 			}
 
-			if isJSON(cmd) {
+			if isStructuredOutput(cmd) {
 				return printJSON(cmd, report)
 			}
 
@@ -180,10 +211,12 @@ Report types:
 	cmd.Flags().StringVar(&from, "from", "", "Start date (YYYY-MM-DD or relative) (required)")
 	cmd.Flags().StringVar(&to, "to", "", "End date (YYYY-MM-DD or relative) (required)")
 	cmd.Flags().StringVar(&id, "id", "", "ID of agent/inbox/label/team (required for non-account types)")
+	cmd.Flags().StringVar(&compare, "compare", "", compareFlagUsage)
 	flagAlias(cmd.Flags(), "type", "ty")
 	flagAlias(cmd.Flags(), "from", "fr")
 	flagAlias(cmd.Flags(), "to", "t2")
 	flagAlias(cmd.Flags(), "id", "rid")
+	flagAlias(cmd.Flags(), "compare", "cmp")
 
 	return cmd
 }
@@ -249,7 +282,7 @@ Report types:
 				return err
 			}
 
-			if isJSON(cmd) {
+			if isStructuredOutput(cmd) {
 				return printJSON(cmd, report)
 			}
 
@@ -301,7 +334,7 @@ func newReportsLiveCmd() *cobra.Command {
 				return err
 			}
 
-			if isJSON(cmd) {
+			if isStructuredOutput(cmd) {
 				return printJSON(cmd, metrics)
 			}
 
@@ -336,7 +369,7 @@ func newReportsAgentsCmd() *cobra.Command {
 				return err
 			}
 
-			if isJSON(cmd) {
+			if isStructuredOutput(cmd) {
 				return printJSON(cmd, agents)
 			}
 
@@ -367,17 +400,43 @@ func newReportsChannelsCmd() *cobra.Command {
 	var from string
 	var to string
 	var businessHours bool
+	var compare string
 
 	cmd := &cobra.Command{
 		Use:   "channels",
 		Short: "Get conversation statistics grouped by channel type",
 		Long: `Get conversation statistics grouped by channel type.
 
-Date parameters use YYYY-MM-DD or relative expressions and are converted to Unix timestamps.`,
+Date parameters use YYYY-MM-DD or relative expressions and are converted to Unix timestamps.
+
+With --compare (previous, yoy, or FROM..TO), each channel is shown with the
+change of every count against the other period.`,
 		Example: `  cw reports channels --from 2024-01-01 --to 2024-01-31
   cw reports channels --business-hours
-  cw reports channels -o json`,
+  cw reports channels -o json
+  cw reports channels --from 2024-02-05 --to 2024-02-12 --compare previous`,
 		RunE: RunE(func(cmd *cobra.Command, _ []string) error {
+			if compare != "" {
+				current, previous, err := reportComparePeriods(from, to, compare)
+				if err != nil {
+					return err
+				}
+				client, err := getClient()
+				if err != nil {
+					return err
+				}
+				businessHoursPtr := boolPtrIfChanged(cmd, "business-hours", businessHours)
+				cur, err := client.Reports().ChannelSummary(cmdContext(cmd), current.sinceTS(), current.untilTS(), businessHoursPtr)
+				if err != nil {
+					return err
+				}
+				prev, err := client.Reports().ChannelSummary(cmdContext(cmd), previous.sinceTS(), previous.untilTS(), businessHoursPtr)
+				if err != nil {
+					return fmt.Errorf("previous period: %w", err)
+				}
+				return renderGroupedComparison(cmd, "CHANNEL", current, previous, channelComparisonRows(cur, prev), "No channel summary data found")
+			}
+
 			sinceTS, untilTS, err := parseOptionalDateRange(from, to)
 			if err != nil {
 				return err
@@ -393,7 +452,10 @@ Date parameters use YYYY-MM-DD or relative expressions and are converted to Unix
 				return err
 			}
 
-			if isJSON(cmd) {
+			if outfmt.IsTabular(cmdContext(cmd)) {
+				return printJSON(cmd, channelSummaryRows(channelSummary))
+			}
+			if isStructuredOutput(cmd) {
 				return printJSON(cmd, channelSummary)
 			}
 
@@ -423,15 +485,33 @@ Date parameters use YYYY-MM-DD or relative expressions and are converted to Unix
 	cmd.Flags().StringVar(&from, "from", "", "Start date (YYYY-MM-DD or relative)")
 	cmd.Flags().StringVar(&to, "to", "", "End date (YYYY-MM-DD or relative)")
 	cmd.Flags().BoolVar(&businessHours, "business-hours", false, "Restrict to business hours")
+	cmd.Flags().StringVar(&compare, "compare", "", compareFlagUsage)
 	flagAlias(cmd.Flags(), "from", "fr")
 	flagAlias(cmd.Flags(), "to", "t2")
 	flagAlias(cmd.Flags(), "business-hours", "bh")
+	flagAlias(cmd.Flags(), "compare", "cmp")
 
 	return cmd
 }
 
+// channelSummaryRow is one channel of a channel summary, laid out as a row
+// for CSV and XLSX output.
+type channelSummaryRow struct {
+	Channel string `json:"channel"`
+	api.ChannelSummary
+}
+
+func channelSummaryRows(summary map[string]api.ChannelSummary) []channelSummaryRow {
+	rows := make([]channelSummaryRow, 0, len(summary))
+	for channel, s := range summary {
+		rows = append(rows, channelSummaryRow{Channel: channel, ChannelSummary: s})
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Channel < rows[j].Channel })
+	return rows
+}
+
 func renderSummaryReportEntries(cmd *cobra.Command, entries []api.SummaryReportEntry, emptyMessage string) error {
-	if isJSON(cmd) {
+	if isStructuredOutput(cmd) {
 		return printJSON(cmd, entries)
 	}
 
@@ -460,6 +540,7 @@ func newReportsAgentSummaryCmd() *cobra.Command {
 	var from string
 	var to string
 	var businessHours bool
+	var compare string
 
 	cmd := &cobra.Command{
 		Use:     "agent-summary",
@@ -467,10 +548,25 @@ func newReportsAgentSummaryCmd() *cobra.Command {
 		Short:   "Get summary report grouped by agent",
 		Long: `Get summary report grouped by agent.
 
-Date parameters use YYYY-MM-DD or relative expressions and are converted to Unix timestamps.`,
+Date parameters use YYYY-MM-DD or relative expressions and are converted to Unix timestamps.
+
+With --compare (previous, yoy, or FROM..TO), each agent is shown with the change
+of every metric against the other period.`,
 		Example: `  cw reports agent-summary --from 2024-01-01 --to 2024-01-31
-  cw reports agent-summary --business-hours`,
+  cw reports agent-summary --business-hours
+  cw reports agent-summary --from 2024-02-01 --to 2024-02-29 --compare yoy`,
 		RunE: RunE(func(cmd *cobra.Command, _ []string) error {
+			if compare != "" {
+				client, err := getClient()
+				if err != nil {
+					return err
+				}
+				businessHoursPtr := boolPtrIfChanged(cmd, "business-hours", businessHours)
+				return runSummaryEntriesComparison(cmd, from, to, compare, "No agent summary data found", func(since, until string) ([]api.SummaryReportEntry, error) {
+					return client.Reports().SummaryByAgent(cmdContext(cmd), since, until, businessHoursPtr)
+				})
+			}
+
 			sinceTS, untilTS, err := parseOptionalDateRange(from, to)
 			if err != nil {
 				return err
@@ -493,9 +589,11 @@ Date parameters use YYYY-MM-DD or relative expressions and are converted to Unix
 	cmd.Flags().StringVar(&from, "from", "", "Start date (YYYY-MM-DD or relative)")
 	cmd.Flags().StringVar(&to, "to", "", "End date (YYYY-MM-DD or relative)")
 	cmd.Flags().BoolVar(&businessHours, "business-hours", false, "Restrict to business hours")
+	cmd.Flags().StringVar(&compare, "compare", "", compareFlagUsage)
 	flagAlias(cmd.Flags(), "from", "fr")
 	flagAlias(cmd.Flags(), "to", "t2")
 	flagAlias(cmd.Flags(), "business-hours", "bh")
+	flagAlias(cmd.Flags(), "compare", "cmp")
 
 	return cmd
 }
@@ -504,16 +602,32 @@ func newReportsInboxesCmd() *cobra.Command {
 	var from string
 	var to string
 	var businessHours bool
+	var compare string
 
 	cmd := &cobra.Command{
 		Use:   "inboxes",
 		Short: "Get summary report grouped by inbox",
 		Long: `Get summary report grouped by inbox.
 
-Date parameters use YYYY-MM-DD or relative expressions and are converted to Unix timestamps.`,
+Date parameters use YYYY-MM-DD or relative expressions and are converted to Unix timestamps.
+
+With --compare (previous, yoy, or FROM..TO), each inbox is shown with the change
+of every metric against the other period.`,
 		Example: `  cw reports inboxes --from 2024-01-01 --to 2024-01-31
-  cw reports inboxes --business-hours`,
+  cw reports inboxes --business-hours
+  cw reports inboxes --from 2024-02-01 --to 2024-02-29 --compare yoy`,
 		RunE: RunE(func(cmd *cobra.Command, _ []string) error {
+			if compare != "" {
+				client, err := getClient()
+				if err != nil {
+					return err
+				}
+				businessHoursPtr := boolPtrIfChanged(cmd, "business-hours", businessHours)
+				return runSummaryEntriesComparison(cmd, from, to, compare, "No inbox summary data found", func(since, until string) ([]api.SummaryReportEntry, error) {
+					return client.Reports().SummaryByInbox(cmdContext(cmd), since, until, businessHoursPtr)
+				})
+			}
+
 			sinceTS, untilTS, err := parseOptionalDateRange(from, to)
 			if err != nil {
 				return err
@@ -536,9 +650,11 @@ Date parameters use YYYY-MM-DD or relative expressions and are converted to Unix
 	cmd.Flags().StringVar(&from, "from", "", "Start date (YYYY-MM-DD or relative)")
 	cmd.Flags().StringVar(&to, "to", "", "End date (YYYY-MM-DD or relative)")
 	cmd.Flags().BoolVar(&businessHours, "business-hours", false, "Restrict to business hours")
+	cmd.Flags().StringVar(&compare, "compare", "", compareFlagUsage)
 	flagAlias(cmd.Flags(), "from", "fr")
 	flagAlias(cmd.Flags(), "to", "t2")
 	flagAlias(cmd.Flags(), "business-hours", "bh")
+	flagAlias(cmd.Flags(), "compare", "cmp")
 
 	return cmd
 }
@@ -547,16 +663,32 @@ func newReportsTeamsCmd() *cobra.Command {
 	var from string
 	var to string
 	var businessHours bool
+	var compare string
 
 	cmd := &cobra.Command{
 		Use:   "teams",
 		Short: "Get summary report grouped by team",
 		Long: `Get summary report grouped by team.
 
-Date parameters use YYYY-MM-DD or relative expressions and are converted to Unix timestamps.`,
+Date parameters use YYYY-MM-DD or relative expressions and are converted to Unix timestamps.
+
+With --compare (previous, yoy, or FROM..TO), each team is shown with the change
+of every metric against the other period.`,
 		Example: `  cw reports teams --from 2024-01-01 --to 2024-01-31
-  cw reports teams --business-hours`,
+  cw reports teams --business-hours
+  cw reports teams --from 2024-02-01 --to 2024-02-29 --compare yoy`,
 		RunE: RunE(func(cmd *cobra.Command, _ []string) error {
+			if compare != "" {
+				client, err := getClient()
+				if err != nil {
+					return err
+				}
+				businessHoursPtr := boolPtrIfChanged(cmd, "business-hours", businessHours)
+				return runSummaryEntriesComparison(cmd, from, to, compare, "No team summary data found", func(since, until string) ([]api.SummaryReportEntry, error) {
+					return client.Reports().SummaryByTeam(cmdContext(cmd), since, until, businessHoursPtr)
+				})
+			}
+
 			sinceTS, untilTS, err := parseOptionalDateRange(from, to)
 			if err != nil {
 				return err
@@ -579,9 +711,11 @@ Date parameters use YYYY-MM-DD or relative expressions and are converted to Unix
 	cmd.Flags().StringVar(&from, "from", "", "Start date (YYYY-MM-DD or relative)")
 	cmd.Flags().StringVar(&to, "to", "", "End date (YYYY-MM-DD or relative)")
 	cmd.Flags().BoolVar(&businessHours, "business-hours", false, "Restrict to business hours")
+	cmd.Flags().StringVar(&compare, "compare", "", compareFlagUsage)
 	flagAlias(cmd.Flags(), "from", "fr")
 	flagAlias(cmd.Flags(), "to", "t2")
 	flagAlias(cmd.Flags(), "business-hours", "bh")
+	flagAlias(cmd.Flags(), "compare", "cmp")
 
 	return cmd
 }
@@ -626,7 +760,7 @@ Useful for understanding which labels are most common across different inboxes.`
 				return err
 			}
 
-			if isJSON(cmd) {
+			if isStructuredOutput(cmd) {
 				return printJSON(cmd, entries)
 			}
 
@@ -694,7 +828,7 @@ Time buckets: 0-1h, 1-4h, 4-8h, 8-24h, 24h+`,
 				return err
 			}
 
-			if isJSON(cmd) {
+			if isStructuredOutput(cmd) {
 				return printJSON(cmd, dist)
 			}
 
@@ -785,7 +919,7 @@ Group by options:
 				return err
 			}
 
-			if isJSON(cmd) {
+			if isStructuredOutput(cmd) {
 				return printJSON(cmd, entries)
 			}
 
@@ -873,7 +1007,7 @@ func newReportingEventsCmd() *cobra.Command {
 				return err
 			}
 
-			if isJSON(cmd) {
+			if isStructuredOutput(cmd) {
 				return printJSON(cmd, events)
 			}
 
@@ -914,7 +1048,7 @@ func newReportingEventsCmd() *cobra.Command {
 				return err
 			}
 
-			if isJSON(cmd) {
+			if isStructuredOutput(cmd) {
 				return printJSON(cmd, events)
 			}
 
@@ -0,0 +1,377 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+	"github.com/spf13/cobra"
+)
+
+const compareFlagUsage = "Compare with another period: previous, yoy, or FROM..TO"
+
+// reportPeriod is a report date range.
+type reportPeriod struct {
+	Since time.Time `json:"since"`
+	Until time.Time `json:"until"`
+}
+
+func (p reportPeriod) sinceTS() string { return strconv.FormatInt(p.Since.Unix(), 10) }
+func (p reportPeriod) untilTS() string { return strconv.FormatInt(p.Until.Unix(), 10) }
+
+func (p reportPeriod) String() string {
+	return p.Since.Format("2006-01-02") + " – " + p.Until.Format("2006-01-02")
+}
+
+// reportComparePeriods returns the current period from --from/--to and the
+// period to compare it with:
+//
+//	previous    the same length immediately before --from
+//	yoy         the same dates one year earlier
+//	FROM..TO    an explicit range
+func reportComparePeriods(from, to, mode string) (reportPeriod, reportPeriod, error) {
+	if from == "" || to == "" {
+		return reportPeriod{}, reportPeriod{}, fmt.Errorf("--compare requires --from and --to")
+	}
+	since, err := parseDateTime(from)
+	if err != nil {
+		return reportPeriod{}, reportPeriod{}, err
+	}
+	until, err := parseDateTime(to)
+	if err != nil {
+		return reportPeriod{}, reportPeriod{}, err
+	}
+	if until.Before(since) {
+		return reportPeriod{}, reportPeriod{}, fmt.Errorf("--to date (%s) must be on or after --from date (%s)", to, from)
+	}
+	current := reportPeriod{Since: since, Until: until}
+
+	mode = strings.TrimSpace(mode)
+	switch strings.ToLower(mode) {
+	case "previous", "prev":
+		length := until.Sub(since)
+		return current, reportPeriod{Since: since.Add(-length), Until: since}, nil
+	case "yoy":
+		return current, reportPeriod{Since: since.AddDate(-1, 0, 0), Until: until.AddDate(-1, 0, 0)}, nil
+	}
+
+	start, end, ok := strings.Cut(mode, "..")
+	if !ok || strings.TrimSpace(start) == "" || strings.TrimSpace(end) == "" {
+		return reportPeriod{}, reportPeriod{}, fmt.Errorf("invalid --compare %q (use previous, yoy, or FROM..TO)", mode)
+	}
+	prevSince, err := parseDateTime(strings.TrimSpace(start))
+	if err != nil {
+		return reportPeriod{}, reportPeriod{}, fmt.Errorf("invalid --compare range: %w", err)
+	}
+	prevUntil, err := parseDateTime(strings.TrimSpace(end))
+	if err != nil {
+		return reportPeriod{}, reportPeriod{}, fmt.Errorf("invalid --compare range: %w", err)
+	}
+	if prevUntil.Before(prevSince) {
+		return reportPeriod{}, reportPeriod{}, fmt.Errorf("invalid --compare range %q: end is before start", mode)
+	}
+	return current, reportPeriod{Since: prevSince, Until: prevUntil}, nil
+}
+
+// reportMetric is one named metric value; Value is nil when the report has
+// no data for it (e.g. no average without conversations).
+type reportMetric struct {
+	Name  string
+	Label string
+	Value *float64
+}
+
+// metricDelta is the change of one metric between two periods. Percent is
+// nil when the previous value is zero or missing.
+type metricDelta struct {
+	Current  *float64 `json:"current"`
+	Previous *float64 `json:"previous"`
+	Change   *float64 `json:"change"`
+	Percent  *float64 `json:"percent"`
+}
+
+func compareMetric(current, previous *float64) metricDelta {
+	d := metricDelta{Current: current, Previous: previous}
+	if current == nil || previous == nil {
+		return d
+	}
+	change := *current - *previous
+	d.Change = &change
+	if *previous != 0 {
+		pct := math.Round(change/math.Abs(*previous)*1000) / 10
+		d.Percent = &pct
+	}
+	return d
+}
+
+func compareMetrics(current, previous []reportMetric) map[string]metricDelta {
+	prev := make(map[string]*float64, len(previous))
+	for _, m := range previous {
+		prev[m.Name] = m.Value
+	}
+	out := make(map[string]metricDelta, len(current))
+	for _, m := range current {
+		out[m.Name] = compareMetric(m.Value, prev[m.Name])
+	}
+	return out
+}
+
+// formatDeltaIndicator renders a delta as "▲ 12.5%", "▼ 3", "=" or "-".
+func formatDeltaIndicator(d metricDelta) string {
+	switch {
+	case d.Change == nil:
+		return "-"
+	case *d.Change == 0:
+		return "="
+	}
+	arrow := "▲"
+	if *d.Change < 0 {
+		arrow = "▼"
+	}
+	if d.Percent != nil {
+		return fmt.Sprintf("%s %.1f%%", arrow, math.Abs(*d.Percent))
+	}
+	return fmt.Sprintf("%s %s", arrow, formatValue(math.Abs(*d.Change)))
+}
+
+func formatMetricValue(v *float64) string {
+	if v == nil {
+		return "-"
+	}
+	return formatValue(*v)
+}
+
+func floatPtr(v float64) *float64 { return &v }
+
+func flexFloatPtr(v *api.FlexFloat) *float64 {
+	if v == nil {
+		return nil
+	}
+	return floatPtr(float64(*v))
+}
+
+func flexStringFloat(v api.FlexString) *float64 {
+	s := strings.TrimSpace(string(v))
+	if s == "" {
+		return nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil
+	}
+	return &f
+}
+
+func reportSummaryMetrics(r *api.ReportSummary) []reportMetric {
+	return []reportMetric{
+		{"conversations_count", "Conversations", floatPtr(float64(r.ConversationsCount))},
+		{"resolutions_count", "Resolutions", floatPtr(float64(r.ResolutionsCount))},
+		{"incoming_messages_count", "Incoming Messages", floatPtr(float64(r.IncomingMessagesCount))},
+		{"outgoing_messages_count", "Outgoing Messages", floatPtr(float64(r.OutgoingMessagesCount))},
+		{"avg_first_response_time", "Avg First Response Time", flexStringFloat(r.AvgFirstResponseTime)},
+		{"avg_resolution_time", "Avg Resolution Time", flexStringFloat(r.AvgResolutionTime)},
+	}
+}
+
+func summaryEntryMetrics(e api.SummaryReportEntry) []reportMetric {
+	return []reportMetric{
+		{"conversations_count", "CONVERSATIONS", floatPtr(float64(e.ConversationsCount))},
+		{"resolved_conversations_count", "RESOLVED", floatPtr(float64(e.ResolvedConversationsCount))},
+		{"avg_first_response_time", "AVG_FIRST_RESPONSE", flexFloatPtr(e.AvgFirstResponseTime)},
+		{"avg_resolution_time", "AVG_RESOLUTION", flexFloatPtr(e.AvgResolutionTime)},
+		{"avg_reply_time", "AVG_REPLY", flexFloatPtr(e.AvgReplyTime)},
+	}
+}
+
+func channelSummaryMetrics(s api.ChannelSummary) []reportMetric {
+	return []reportMetric{
+		{"open", "OPEN", floatPtr(float64(s.Open))},
+		{"resolved", "RESOLVED", floatPtr(float64(s.Resolved))},
+		{"pending", "PENDING", floatPtr(float64(s.Pending))},
+		{"snoozed", "SNOOZED", floatPtr(float64(s.Snoozed))},
+		{"total", "TOTAL", floatPtr(float64(s.Total))},
+	}
+}
+
+// reportComparison is the JSON shape of a single-report comparison.
+type reportComparison struct {
+	Periods  map[string]reportPeriod `json:"periods"`
+	Current  any                     `json:"current"`
+	Previous any                     `json:"previous"`
+	Delta    map[string]metricDelta  `json:"delta"`
+}
+
+// reportComparisonRow is one group (agent, inbox, team or channel) of a
+// grouped comparison.
+type reportComparisonRow struct {
+	Key      string                 `json:"-"`
+	ID       int                    `json:"id,omitempty"`
+	Channel  string                 `json:"channel,omitempty"`
+	Current  any                    `json:"current"`
+	Previous any                    `json:"previous"`
+	Delta    map[string]metricDelta `json:"delta"`
+
+	metrics []reportMetric
+}
+
+// groupedReportComparison is the JSON shape of a grouped comparison.
+type groupedReportComparison struct {
+	Periods map[string]reportPeriod `json:"periods"`
+	Items   []reportComparisonRow   `json:"items"`
+}
+
+func comparisonPeriods(current, previous reportPeriod) map[string]reportPeriod {
+	return map[string]reportPeriod{"current": current, "previous": previous}
+}
+
+func renderReportSummaryComparison(cmd *cobra.Command, current, previous reportPeriod, cur, prev *api.ReportSummary) error {
+	curMetrics := reportSummaryMetrics(cur)
+	delta := compareMetrics(curMetrics, reportSummaryMetrics(prev))
+	if isStructuredOutput(cmd) {
+		return printJSON(cmd, reportComparison{
+			Periods:  comparisonPeriods(current, previous),
+			Current:  cur,
+			Previous: prev,
+			Delta:    delta,
+		})
+	}
+
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Report Summary: %s vs %s\n", current, previous)
+	w := newTabWriterFromCmd(cmd)
+	_, _ = fmt.Fprintln(w, "METRIC\tCURRENT\tPREVIOUS\tCHANGE")
+	for _, m := range curMetrics {
+		d := delta[m.Name]
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", m.Label, formatMetricValue(d.Current), formatMetricValue(d.Previous), formatDeltaIndicator(d))
+	}
+	return w.Flush()
+}
+
+func summaryEntryComparisonRows(cur, prev []api.SummaryReportEntry) []reportComparisonRow {
+	byID := func(entries []api.SummaryReportEntry) map[int]api.SummaryReportEntry {
+		m := make(map[int]api.SummaryReportEntry, len(entries))
+		for _, e := range entries {
+			m[e.ID] = e
+		}
+		return m
+	}
+	curByID, prevByID := byID(cur), byID(prev)
+	ids := make([]int, 0, len(curByID)+len(prevByID))
+	for id := range curByID {
+		ids = append(ids, id)
+	}
+	for id := range prevByID {
+		if _, ok := curByID[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+
+	rows := make([]reportComparisonRow, 0, len(ids))
+	for _, id := range ids {
+		// A group missing from a period had no conversations in it.
+		c, ok := curByID[id]
+		if !ok {
+			c = api.SummaryReportEntry{ID: id}
+		}
+		p, ok := prevByID[id]
+		if !ok {
+			p = api.SummaryReportEntry{ID: id}
+		}
+		metrics := summaryEntryMetrics(c)
+		rows = append(rows, reportComparisonRow{
+			ID:       id,
+			Current:  c,
+			Previous: p,
+			Delta:    compareMetrics(metrics, summaryEntryMetrics(p)),
+			metrics:  metrics,
+		})
+	}
+	return rows
+}
+
+func channelComparisonRows(cur, prev map[string]api.ChannelSummary) []reportComparisonRow {
+	keys := make([]string, 0, len(cur)+len(prev))
+	for k := range cur {
+		keys = append(keys, k)
+	}
+	for k := range prev {
+		if _, ok := cur[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	rows := make([]reportComparisonRow, 0, len(keys))
+	for _, k := range keys {
+		c, p := cur[k], prev[k]
+		metrics := channelSummaryMetrics(c)
+		rows = append(rows, reportComparisonRow{
+			Key:      k,
+			Channel:  k,
+			Current:  c,
+			Previous: p,
+			Delta:    compareMetrics(metrics, channelSummaryMetrics(p)),
+			metrics:  metrics,
+		})
+	}
+	return rows
+}
+
+func renderGroupedComparison(cmd *cobra.Command, keyHeader string, current, previous reportPeriod, rows []reportComparisonRow, emptyMessage string) error {
+	if isStructuredOutput(cmd) {
+		return printJSON(cmd, groupedReportComparison{
+			Periods: comparisonPeriods(current, previous),
+			Items:   rows,
+		})
+	}
+	if len(rows) == 0 {
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), emptyMessage)
+		return nil
+	}
+
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s vs %s\n", current, previous)
+	w := newTabWriterFromCmd(cmd)
+	header := []string{keyHeader}
+	for _, m := range rows[0].metrics {
+		header = append(header, m.Label, "Δ")
+	}
+	_, _ = fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, row := range rows {
+		key := row.Key
+		if key == "" {
+			key = strconv.Itoa(row.ID)
+		}
+		cells := []string{key}
+		for _, m := range row.metrics {
+			d := row.Delta[m.Name]
+			cells = append(cells, formatMetricValue(d.Current), formatDeltaIndicator(d))
+		}
+		_, _ = fmt.Fprintln(w, strings.Join(cells, "\t"))
+	}
+	return w.Flush()
+}
+
+// summaryEntriesFunc fetches summary entries grouped by agent, inbox or team.
+type summaryEntriesFunc func(since, until string) ([]api.SummaryReportEntry, error)
+
+// runSummaryEntriesComparison fetches both periods of a grouped summary
+// report and renders the comparison.
+func runSummaryEntriesComparison(cmd *cobra.Command, from, to, compare, emptyMessage string, fetch summaryEntriesFunc) error {
+	current, previous, err := reportComparePeriods(from, to, compare)
+	if err != nil {
+		return err
+	}
+	cur, err := fetch(current.sinceTS(), current.untilTS())
+	if err != nil {
+		return err
+	}
+	prev, err := fetch(previous.sinceTS(), previous.untilTS())
+	if err != nil {
+		return fmt.Errorf("previous period: %w", err)
+	}
+	return renderGroupedComparison(cmd, "ID", current, previous, summaryEntryComparisonRows(cur, prev), emptyMessage)
+}
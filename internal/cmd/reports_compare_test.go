@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReportComparePeriods(t *testing.T) {
+	day := func(s string) time.Time {
+		t.Helper()
+		d, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+
+	tests := []struct {
+		mode       string
+		since, end string
+	}{
+		{"previous", "2024-01-25", "2024-02-01"},
+		{"yoy", "2023-02-01", "2023-02-08"},
+		{"2023-12-01..2023-12-31", "2023-12-01", "2023-12-31"},
+	}
+	for _, tt := range tests {
+		current, previous, err := reportComparePeriods("2024-02-01", "2024-02-08", tt.mode)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.mode, err)
+		}
+		if !current.Since.Equal(day("2024-02-01")) || !current.Until.Equal(day("2024-02-08")) {
+			t.Errorf("%s: unexpected current period %s", tt.mode, current)
+		}
+		if !previous.Since.Equal(day(tt.since)) || !previous.Until.Equal(day(tt.end)) {
+			t.Errorf("%s: previous = %s, want %s – %s", tt.mode, previous, tt.since, tt.end)
+		}
+	}
+
+	for _, mode := range []string{"lastyear", "2024-01-10..", "2024-01-10..2024-01-01"} {
+		if _, _, err := reportComparePeriods("2024-02-01", "2024-02-08", mode); err == nil {
+			t.Errorf("%q: expected error", mode)
+		}
+	}
+	if _, _, err := reportComparePeriods("", "2024-02-08", "previous"); err == nil || !strings.Contains(err.Error(), "requires --from and --to") {
+		t.Errorf("expected missing range error, got %v", err)
+	}
+}
+
+func TestFormatDeltaIndicator(t *testing.T) {
+	tests := []struct {
+		cur, prev *float64
+		want      string
+	}{
+		{floatPtr(120), floatPtr(100), "▲ 20.0%"},
+		{floatPtr(75), floatPtr(100), "▼ 25.0%"},
+		{floatPtr(3), floatPtr(0), "▲ 3"},
+		{floatPtr(5), floatPtr(5), "="},
+		{nil, floatPtr(5), "-"},
+	}
+	for _, tt := range tests {
+		if got := formatDeltaIndicator(compareMetric(tt.cur, tt.prev)); got != tt.want {
+			t.Errorf("formatDeltaIndicator(%v, %v) = %q, want %q", tt.cur, tt.prev, got, tt.want)
+		}
+	}
+}
+
+func TestReportsSummaryCompare(t *testing.T) {
+	current, previous, err := reportComparePeriods("2024-02-01", "2024-02-08", "previous")
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := newRouteHandler().
+		On("GET", "/api/v2/accounts/1/reports/summary", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			switch r.URL.Query().Get("since") {
+			case current.sinceTS():
+				_, _ = w.Write([]byte(`{"conversations_count": 120, "resolutions_count": 90, "avg_first_response_time": "300"}`))
+			case previous.sinceTS():
+				_, _ = w.Write([]byte(`{"conversations_count": 100, "resolutions_count": 100, "avg_first_response_time": "400"}`))
+			default:
+				w.WriteHeader(http.StatusBadRequest)
+			}
+		})
+	setupTestEnvWithHandler(t, handler)
+
+	output := captureStdout(t, func() {
+		err := Execute(context.Background(), []string{"reports", "summary", "--type", "account", "--from", "2024-02-01", "--to", "2024-02-08", "--compare", "previous"})
+		if err != nil {
+			t.Fatalf("summary --compare failed: %v", err)
+		}
+	})
+	for _, want := range []string{"2024-02-01 – 2024-02-08 vs 2024-01-25 – 2024-02-01", "▲ 20.0%", "▼ 10.0%", "▼ 25.0%"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output missing %q:\n%s", want, output)
+		}
+	}
+
+	output = captureStdout(t, func() {
+		err := Execute(context.Background(), []string{"reports", "summary", "--type", "account", "--from", "2024-02-01", "--to", "2024-02-08", "--cmp", "previous", "-o", "json"})
+		if err != nil {
+			t.Fatalf("summary --compare -o json failed: %v", err)
+		}
+	})
+	var payload struct {
+		Current  map[string]any         `json:"current"`
+		Previous map[string]any         `json:"previous"`
+		Delta    map[string]metricDelta `json:"delta"`
+	}
+	if err := json.Unmarshal([]byte(output), &payload); err != nil {
+		t.Fatalf("invalid JSON %q: %v", output, err)
+	}
+	d := payload.Delta["conversations_count"]
+	if payload.Current["conversations_count"] != float64(120) || payload.Previous["conversations_count"] != float64(100) ||
+		d.Change == nil || *d.Change != 20 || d.Percent == nil || *d.Percent != 20 {
+		t.Fatalf("unexpected comparison %+v", payload)
+	}
+}
+
+func TestReportsGroupedCompare(t *testing.T) {
+	current, _, err := reportComparePeriods("2024-02-01", "2024-02-08", "yoy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := newRouteHandler().
+		On("GET", "/api/v2/accounts/1/summary_reports/inbox", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			if r.URL.Query().Get("since") == current.sinceTS() {
+				_, _ = w.Write([]byte(`[{"id": 1, "conversations_count": 10, "resolved_conversations_count": 8}, {"id": 3, "conversations_count": 4, "resolved_conversations_count": 4}]`))
+				return
+			}
+			_, _ = w.Write([]byte(`[{"id": 1, "conversations_count": 5, "resolved_conversations_count": 8}, {"id": 2, "conversations_count": 7, "resolved_conversations_count": 6}]`))
+		}).
+		On("GET", "/api/v2/accounts/1/summary_reports/channel", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			if r.URL.Query().Get("since") == current.sinceTS() {
+				_, _ = w.Write([]byte(`{"Channel::Email": {"open": 3, "total": 10}}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"Channel::Email": {"open": 6, "total": 10}}`))
+		})
+	setupTestEnvWithHandler(t, handler)
+
+	output := captureStdout(t, func() {
+		err := Execute(context.Background(), []string{"reports", "inboxes", "--from", "2024-02-01", "--to", "2024-02-08", "--compare", "yoy", "-o", "json"})
+		if err != nil {
+			t.Fatalf("inboxes --compare failed: %v", err)
+		}
+	})
+	var payload struct {
+		Items []struct {
+			ID    int                    `json:"id"`
+			Delta map[string]metricDelta `json:"delta"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(output), &payload); err != nil {
+		t.Fatalf("invalid JSON %q: %v", output, err)
+	}
+	if len(payload.Items) != 3 {
+		t.Fatalf("expected union of inboxes 1, 2, 3, got %+v", payload.Items)
+	}
+	if d := payload.Items[0].Delta["conversations_count"]; *d.Percent != 100 {
+		t.Errorf("inbox 1 conversations delta = %+v", d)
+	}
+	if d := payload.Items[1].Delta["conversations_count"]; *d.Change != -7 {
+		t.Errorf("inbox 2 (gone) conversations delta = %+v", d)
+	}
+	if d := payload.Items[2].Delta["conversations_count"]; d.Percent != nil || *d.Change != 4 {
+		t.Errorf("inbox 3 (new) conversations delta = %+v", d)
+	}
+
+	output = captureStdout(t, func() {
+		err := Execute(context.Background(), []string{"reports", "channels", "--from", "2024-02-01", "--to", "2024-02-08", "--compare", "yoy"})
+		if err != nil {
+			t.Fatalf("channels --compare failed: %v", err)
+		}
+	})
+	if !strings.Contains(output, "Channel::Email") || !strings.Contains(output, "▼ 50.0%") || !strings.Contains(output, "=") {
+		t.Errorf("unexpected channels comparison:\n%s", output)
+	}
+
+	err = Execute(context.Background(), []string{"reports", "teams", "--compare", "previous"})
+	if err == nil || !strings.Contains(err.Error(), "requires --from and --to") {
+		t.Errorf("expected missing range error, got %v", err)
+	}
+}
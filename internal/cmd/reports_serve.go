@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/chatwoot/chatwoot-cli/internal/metrics"
+	"github.com/spf13/cobra"
+)
+
+func newReportsServeCmd() *cobra.Command {
+	var (
+		listen   string
+		interval time.Duration
+		window   string
+	)
+
+	cmd := &cobra.Command{
+		Use:     "serve",
+		Aliases: []string{"metrics"},
+		Short:   "Serve report metrics for Prometheus",
+		Long: strings.TrimSpace(`
+Run an HTTP server that exposes account reports at /metrics in the Prometheus
+text format (or OpenMetrics, when the scraper asks for it).
+
+Reports are polled every --interval and cached; scrapes are always answered
+from the cache. Live conversation and agent metrics are point-in-time values.
+Inbox, team and CSAT metrics cover the trailing --window. When the API's rate
+limit headers show too few requests left, or the API returns 429, the next
+poll waits for the limit to reset. A section that fails keeps serving its last
+good values; chatwoot_up and chatwoot_exporter_refresh_errors_total report
+the failures.
+
+Metrics:
+  chatwoot_conversations_{open,unattended,unassigned}
+  chatwoot_agent_conversations_{open,unattended}, chatwoot_agent_online   {agent_id, agent}
+  chatwoot_{inbox,team}_conversations, _resolved_conversations,
+    _avg_{first_response,resolution,reply}_time_seconds                 {inbox_id, inbox} / {team_id, team}
+  chatwoot_csat_responses {rating}, chatwoot_csat_average_rating
+  chatwoot_agent_csat_{responses,average_rating}                         {agent_id, agent}
+  chatwoot_exporter_* and chatwoot_api_rate_limit_*
+`),
+		Example: strings.TrimSpace(`
+  # Expose metrics on port 9109
+  cw reports serve --listen :9109
+
+  # Poll every 5 minutes; summaries cover the last 7 days
+  cw reports serve --listen 127.0.0.1:9109 --interval 5m --window 7d
+`),
+		Args: cobra.NoArgs,
+		RunE: RunE(func(cmd *cobra.Command, args []string) error {
+			if interval < 10*time.Second {
+				return fmt.Errorf("--interval must be at least 10s")
+			}
+			windowDur, err := parseDuration(window)
+			if err != nil {
+				return fmt.Errorf("invalid --window: %w", err)
+			}
+			if windowDur <= 0 {
+				return fmt.Errorf("--window must be positive")
+			}
+
+			client, err := getClient()
+			if err != nil {
+				return err
+			}
+
+			ctx, stop := signal.NotifyContext(cmdContext(cmd), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			exporter := &metrics.Exporter{
+				Source:   metrics.NewAPISource(client),
+				Interval: interval,
+				Window:   windowDur,
+			}
+
+			ln, err := net.Listen("tcp", listen)
+			if err != nil {
+				return fmt.Errorf("listen on %s: %w", listen, err)
+			}
+			srv := &http.Server{Handler: exporter.Handler(), ReadHeaderTimeout: 10 * time.Second}
+
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Serving metrics on http://%s/metrics (refresh every %s, window %s; press Ctrl+C to stop)\n", ln.Addr(), interval, window)
+
+			errOut := cmd.ErrOrStderr()
+			go exporter.Run(ctx, func(err error, next time.Duration) {
+				if err != nil {
+					_, _ = fmt.Fprintf(errOut, "[%s] refresh failed: %v\n", time.Now().Format("15:04:05"), err)
+				}
+				if next > interval {
+					_, _ = fmt.Fprintf(errOut, "[%s] rate limited; next refresh in %s\n", time.Now().Format("15:04:05"), next.Round(time.Second))
+				}
+			})
+
+			serveErr := make(chan error, 1)
+			go func() { serveErr <- srv.Serve(ln) }()
+
+			var runErr error
+			select {
+			case <-ctx.Done():
+			case err := <-serveErr:
+				if !errors.Is(err, http.ErrServerClosed) {
+					runErr = err
+				}
+			}
+
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			_ = srv.Shutdown(shutdownCtx)
+			return runErr
+		}),
+	}
+
+	cmd.Flags().StringVar(&listen, "listen", ":9109", "Address to serve /metrics on")
+	cmd.Flags().DurationVar(&interval, "interval", time.Minute, "How often to poll the reports API")
+	cmd.Flags().StringVar(&window, "window", "24h", "Period covered by inbox, team and CSAT metrics (e.g. 24h, 7d)")
+	flagAlias(cmd.Flags(), "listen", "addr")
+	flagAlias(cmd.Flags(), "interval", "iv")
+	flagAlias(cmd.Flags(), "window", "win")
+
+	return cmd
+}
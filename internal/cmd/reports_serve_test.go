@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReportsServeValidation(t *testing.T) {
+	setupTestEnvWithHandler(t, newRouteHandler())
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"reports", "serve", "--interval", "1s"}, "at least 10s"},
+		{[]string{"reports", "serve", "--window", "soon"}, "invalid --window"},
+	}
+	for _, tt := range tests {
+		err := Execute(context.Background(), tt.args)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%v: expected error containing %q, got %v", tt.args, tt.want, err)
+		}
+	}
+}
+
+func TestReportsServeExposesMetrics(t *testing.T) {
+	handler := newRouteHandler().
+		On("GET", "/api/v2/accounts/1/reports/conversations", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			if r.URL.Query().Get("type") == "agent" {
+				_, _ = w.Write([]byte(`[{"id": 4, "name": "Ann", "availability": "online", "metric": {"open": 3, "unattended": 1}}]`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"open": 12, "unattended": 5, "unassigned": 2}`))
+		}).
+		On("GET", "/api/v2/accounts/1/summary_reports/inbox", jsonResponse(200, `[{"id": 1, "conversations_count": 7, "resolved_conversations_count": 6}]`)).
+		On("GET", "/api/v2/accounts/1/summary_reports/team", jsonResponse(200, `[]`)).
+		On("GET", "/api/v1/accounts/1/inboxes", jsonResponse(200, `{"payload": [{"id": 1, "name": "Email"}]}`)).
+		On("GET", "/api/v1/accounts/1/teams", jsonResponse(200, `[]`)).
+		On("GET", "/api/v1/accounts/1/csat_survey_responses", jsonResponse(200, `[]`))
+	setupTestEnvWithHandler(t, handler)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	_ = captureStderr(t, func() {
+		go func() { done <- Execute(ctx, []string{"reports", "serve", "--listen", addr}) }()
+
+		var body string
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			resp, err := http.Get("http://" + addr + "/metrics")
+			if err == nil {
+				data, _ := io.ReadAll(resp.Body)
+				_ = resp.Body.Close()
+				body = string(data)
+				if strings.Contains(body, "chatwoot_conversations_open") {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("serve returned %v", err)
+		}
+
+		for _, want := range []string{
+			"chatwoot_conversations_open 12\n",
+			`chatwoot_agent_conversations_open{agent_id="4",agent="Ann"} 3`,
+			`chatwoot_inbox_conversations{inbox_id="1",inbox="Email"} 7`,
+			"chatwoot_up 1\n",
+		} {
+			if !strings.Contains(body, want) {
+				t.Errorf("metrics missing %q:\n%s", want, body)
+			}
+		}
+	})
+}
@@ -1265,3 +1265,34 @@ func TestReportsOutgoingMessagesCommand_Empty(t *testing.T) {
 		t.Errorf("expected empty message, got: %s", output)
 	}
 }
+
+func TestReportsCSVOutput(t *testing.T) {
+	handler := newRouteHandler().
+		On("GET", "/api/v2/accounts/1/summary_reports/channel", jsonResponse(200, `{
+			"Channel::WebWidget": {"open": 5, "resolved": 10, "pending": 2, "snoozed": 1, "total": 18},
+			"Channel::Email": {"open": 3, "resolved": 7, "pending": 0, "snoozed": 0, "total": 10}
+		}`)).
+		On("GET", "/api/v2/accounts/1/summary_reports/inbox", jsonResponse(200, `[
+			{"id": 1, "conversations_count": 7, "resolved_conversations_count": 6, "avg_resolution_time": 90.5}
+		]`))
+	setupTestEnvWithHandler(t, handler)
+
+	output := captureStdout(t, func() {
+		if err := Execute(context.Background(), []string{"reports", "channels", "-o", "csv"}); err != nil {
+			t.Fatalf("reports channels -o csv failed: %v", err)
+		}
+	})
+	want := "channel,open,resolved,pending,snoozed,total\nChannel::Email,3,7,0,0,10\nChannel::WebWidget,5,10,2,1,18\n"
+	if output != want {
+		t.Errorf("channels CSV = %q, want %q", output, want)
+	}
+
+	output = captureStdout(t, func() {
+		if err := Execute(context.Background(), []string{"reports", "inboxes", "-o", "csv", "--fields", "id,avg_resolution_time"}); err != nil {
+			t.Fatalf("reports inboxes -o csv failed: %v", err)
+		}
+	})
+	if want := "id,avg_resolution_time\n1,90.5\n"; output != want {
+		t.Errorf("inboxes CSV = %q, want %q", output, want)
+	}
+}
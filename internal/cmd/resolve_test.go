@@ -4,8 +4,10 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/chatwoot/chatwoot-cli/internal/validation"
@@ -45,11 +47,26 @@ func TestResolveCommand_ValidatesConversationID(t *testing.T) {
 	assert.Contains(t, err.Error(), "invalid conversation ID")
 }
 
+// serveConversationState answers the journal's fetch of a conversation's
+// state before it is changed.
+func serveConversationState(w http.ResponseWriter, r *http.Request) bool {
+	id, ok := strings.CutPrefix(r.URL.Path, "/api/v1/accounts/1/conversations/")
+	if r.Method != http.MethodGet || !ok || strings.Contains(id, "/") {
+		return false
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = fmt.Fprintf(w, `{"id": %s, "status": "open"}`, id)
+	return true
+}
+
 func TestResolveCommand_AcceptsURLAndPrefixedIDs(t *testing.T) {
 	t.Cleanup(func() { validation.SetAllowPrivate(false) })
 	callCount := 0
 
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if serveConversationState(w, r) {
+			return
+		}
 		callCount++
 		switch r.URL.Path {
 		case "/api/v1/accounts/1/conversations/123/toggle_status",
@@ -88,6 +105,9 @@ func TestResolveCommand_SingleConversation(t *testing.T) {
 	toggleCalled := false
 
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if serveConversationState(w, r) {
+			return
+		}
 		if r.Method == "POST" && r.URL.Path == "/api/v1/accounts/1/conversations/123/toggle_status" {
 			toggleCalled = true
 			var payload map[string]any
@@ -123,6 +143,9 @@ func TestResolveCommand_MultipleConversations(t *testing.T) {
 	callCount := 0
 
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if serveConversationState(w, r) {
+			return
+		}
 		switch r.URL.Path {
 		case "/api/v1/accounts/1/conversations/123/toggle_status",
 			"/api/v1/accounts/1/conversations/456/toggle_status",
@@ -160,6 +183,9 @@ func TestResolveCommand_PartialFailure(t *testing.T) {
 	callCount := 0
 
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if serveConversationState(w, r) {
+			return
+		}
 		callCount++
 		switch r.URL.Path {
 		case "/api/v1/accounts/1/conversations/123/toggle_status":
@@ -207,6 +233,9 @@ func TestResolveCommand_JSONOutput(t *testing.T) {
 	t.Cleanup(func() { validation.SetAllowPrivate(false) })
 
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if serveConversationState(w, r) {
+			return
+		}
 		switch r.URL.Path {
 		case "/api/v1/accounts/1/conversations/123/toggle_status",
 			"/api/v1/accounts/1/conversations/456/toggle_status":
@@ -236,6 +265,9 @@ func TestResolveCommand_JSONOutputWithPartialFailure(t *testing.T) {
 	t.Cleanup(func() { validation.SetAllowPrivate(false) })
 
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if serveConversationState(w, r) {
+			return
+		}
 		switch r.URL.Path {
 		case "/api/v1/accounts/1/conversations/123/toggle_status":
 			w.WriteHeader(http.StatusOK)
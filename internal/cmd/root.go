@@ -16,6 +16,7 @@ import (
 	"github.com/joho/godotenv"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
+	"golang.org/x/term"
 
 	"github.com/chatwoot/chatwoot-cli/internal/api"
 	"github.com/chatwoot/chatwoot-cli/internal/debug"
@@ -28,6 +29,7 @@ import (
 // rootFlags holds global CLI flags
 type rootFlags struct {
 	Output                  string
+	OutputFile              string
 	Color                   string
 	Debug                   bool
 	DryRun                  bool
@@ -191,6 +193,8 @@ func Execute(ctx context.Context, args []string) error {
 	}
 	setTimeLocation(nil)
 	completionsNoCache = false
+	recorder := newJournalRecorder(args)
+	activeJournal = recorder
 
 	root := &cobra.Command{
 		Use:                "cw",
@@ -205,6 +209,17 @@ func Execute(ctx context.Context, args []string) error {
 			ctx := cmd.Context()
 
 			flags.Output = normalizeOutputFormat(flags.Output)
+			// Desire path: -o/--out report.csv (or .xlsx) writes that file in
+			// the format its extension names.
+			if ext := strings.ToLower(filepath.Ext(flags.Output)); ext == ".csv" || ext == ".xlsx" {
+				if flags.OutputFile != "" {
+					return fmt.Errorf("--output %s conflicts with --output-file %s", flags.Output, flags.OutputFile)
+				}
+				flags.OutputFile = flags.Output
+				flags.Output = strings.TrimPrefix(ext, ".")
+			}
+			tabular := flags.Output == "csv" || flags.Output == "xlsx"
+
 			if flags.QueryFile != "" {
 				if flags.Query != "" || flags.JQ != "" {
 					return fmt.Errorf("--query-file cannot be used with --query or --jq")
@@ -229,7 +244,8 @@ func Execute(ctx context.Context, args []string) error {
 				}
 				flags.Output = "json"
 			}
-			needsJSON := flags.Query != "" || flags.JQ != "" || flags.Fields != "" || flags.Template != "" || flags.ItemsOnly
+			// --fields selects columns in CSV/XLSX output, so only it is allowed there.
+			needsJSON := flags.Query != "" || flags.JQ != "" || (flags.Fields != "" && !tabular) || flags.Template != "" || flags.ItemsOnly
 			if needsJSON && flags.Output != "json" && flags.Output != "jsonl" && flags.Output != "agent" {
 				if flagOrAliasChanged(cmd, "output") {
 					return fmt.Errorf("--jq/--query/--query-file/--fields/--template/--items-only/--results-only require --output json, jsonl/ndjson, or agent (or --json)")
@@ -243,6 +259,12 @@ func Execute(ctx context.Context, args []string) error {
 				return err
 			}
 			ctx = outfmt.WithMode(ctx, mode)
+			if flags.OutputFile != "" && !tabular {
+				return fmt.Errorf("--output-file requires --output csv or xlsx")
+			}
+			if tabular {
+				ctx = outfmt.WithOutputFile(ctx, flags.OutputFile)
+			}
 
 			// Set up compact output. Light mode implies compact JSON by default,
 			// but users can explicitly override with --compact-json/--cj.
@@ -254,17 +276,28 @@ func Execute(ctx context.Context, args []string) error {
 			}
 			ctx = outfmt.WithCompact(ctx, compact)
 
-			// Set up IO streams (allow silent/quiet to suppress stderr)
-			ioStreams := iocontext.DefaultIO()
+			// Set up IO streams (allow silent/quiet to suppress stderr). Callers
+			// running commands in-process (such as the MCP server) may supply
+			// their own streams on the context.
+			streams := *iocontext.GetIO(ctx)
+			ioStreams := &streams
 			if flags.Silent || flags.Quiet {
 				ioStreams.ErrOut = io.Discard
 			}
 			if flags.Quiet && mode == outfmt.Text {
 				ioStreams.Out = io.Discard
 			}
+			if mode == outfmt.XLSX && flags.OutputFile == "" {
+				if f, ok := ioStreams.Out.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+					return fmt.Errorf("--output xlsx writes a binary workbook; use --output-file report.xlsx or redirect stdout")
+				}
+			}
 			ctx = iocontext.WithIO(ctx, ioStreams)
 			cmd.SetOut(ioStreams.Out)
 			cmd.SetErr(ioStreams.ErrOut)
+			if activeJournal != nil {
+				activeJournal.errOut = ioStreams.ErrOut
+			}
 
 			allowPrivate := parseBoolEnv("CHATWOOT_ALLOW_PRIVATE") || flags.AllowPrivate
 			validation.SetAllowPrivate(allowPrivate)
@@ -289,7 +322,11 @@ func Execute(ctx context.Context, args []string) error {
 				if err != nil {
 					return err
 				}
-				jqQuery = buildFieldsQuery(fields)
+				if tabular {
+					ctx = outfmt.WithFields(ctx, fields)
+				} else {
+					jqQuery = buildFieldsQuery(fields)
+				}
 			}
 			if flags.ItemsOnly && jqQuery == "" {
 				jqQuery = ".items // .results // ."
@@ -361,7 +398,8 @@ func Execute(ctx context.Context, args []string) error {
 		}
 		defaultHelp(cmd, args)
 	})
-	root.PersistentFlags().StringVarP(&flags.Output, "output", "o", flags.Output, "Output format: text|json|jsonl|ndjson|agent (env CHATWOOT_OUTPUT)")
+	root.PersistentFlags().StringVarP(&flags.Output, "output", "o", flags.Output, "Output format: text|json|jsonl|ndjson|agent|csv|xlsx (env CHATWOOT_OUTPUT)")
+	root.PersistentFlags().StringVar(&flags.OutputFile, "output-file", "", "Write CSV/XLSX output to this file instead of stdout")
 	root.PersistentFlags().BoolVarP(&flags.JSON, "json", "j", false, "Shorthand for --output json")
 	root.PersistentFlags().BoolVar(&flags.HelpJSON, "help-json", false, "Output command help as JSON (for agent discovery)")
 	root.PersistentFlags().StringVar(&flags.Color, "color", flags.Color, "Color output: auto|always|never")
@@ -373,7 +411,7 @@ func Execute(ctx context.Context, args []string) error {
 	root.PersistentFlags().StringVar(&flags.QueryFile, "query-file", "", "Read JQ expression from file ('-' for stdin)")
 	root.PersistentFlags().StringVar(&flags.JQ, "jq", "", "Alias for --query")
 	root.PersistentFlags().BoolVar(&flags.ItemsOnly, "items-only", false, "Output only the items/results array when present (JSON output)")
-	root.PersistentFlags().StringVar(&flags.Fields, "fields", "", "Fields to select in JSON output (CSV/whitespace/JSON array, or @- / @path; path aliases supported) (shorthand for --query)")
+	root.PersistentFlags().StringVar(&flags.Fields, "fields", "", "Fields to select in JSON output, or columns in CSV/XLSX output (CSV/whitespace/JSON array, or @- / @path; path aliases supported) (shorthand for --query)")
 	root.PersistentFlags().BoolVar(&flags.Compact, "compact-json", false, "Compact JSON output (no indentation)")
 	root.PersistentFlags().BoolVarP(&flags.Quiet, "quiet", "Q", false, "Suppress non-essential output")
 	root.PersistentFlags().BoolVar(&flags.Silent, "silent", false, "Suppress non-error output to stderr")
@@ -403,6 +441,7 @@ func Execute(ctx context.Context, args []string) error {
 	flagAlias(root.PersistentFlags(), "server-error-delay", "sedly")
 	flagAlias(root.PersistentFlags(), "json", "j")
 	flagAlias(root.PersistentFlags(), "output", "out")
+	flagAlias(root.PersistentFlags(), "output-file", "of")
 	flagAlias(root.PersistentFlags(), "query", "qr")
 	flagAlias(root.PersistentFlags(), "query-file", "qf")
 	flagAlias(root.PersistentFlags(), "items-only", "io")
@@ -472,6 +511,16 @@ func Execute(ctx context.Context, args []string) error {
 	root.AddCommand(newRefCmd())
 	root.AddCommand(newSnoozeCmd())
 	root.AddCommand(newHandoffCmd())
+	root.AddCommand(newSyncCmd())
+	root.AddCommand(newMigrateCmd())
+	root.AddCommand(newAutopilotCmd())
+	root.AddCommand(newSLACmd())
+	root.AddCommand(newMCPCmd())
+	root.AddCommand(newDevCmd())
+	root.AddCommand(newHistoryCmd())
+	root.AddCommand(newUndoCmd())
+	root.AddCommand(newExportCmd())
+	root.AddCommand(newTUICmd())
 
 	// Handle --help-json in a way that bypasses per-command arg validation.
 	// Cobra runs Args() validation before PersistentPreRunE, so flag-based discovery
@@ -490,7 +539,10 @@ func Execute(ctx context.Context, args []string) error {
 		}
 	}
 
+	stopAutosave := recorder.autosave()
 	targetCmd, err := root.ExecuteC()
+	stopAutosave()
+	saveJournal(root.ErrOrStderr(), recorder)
 	if err != nil {
 		if !errors.Is(err, errAlreadyHandled) {
 			enhanced := enhanceUnknownError(err, root, targetCmd)
@@ -940,3 +940,75 @@ func TestLoadOpenClawEnv_NoFileIsNoop(t *testing.T) {
 	// Should not panic or error when ~/.openclaw/.env doesn't exist
 	loadOpenClawEnv()
 }
+
+func TestExecute_TabularOutput(t *testing.T) {
+	handler := newRouteHandler().
+		On("GET", "/api/v1/accounts/1/inboxes", jsonResponse(200, `{
+			"payload": [
+				{"id": 1, "name": "Email, Support", "channel_type": "Channel::Email", "enable_auto_assignment": true},
+				{"id": 2, "name": "Web Chat", "channel_type": "Channel::WebWidget", "enable_auto_assignment": false}
+			]
+		}`))
+	setupTestEnvWithHandler(t, handler)
+
+	output := captureStdout(t, func() {
+		if err := Execute(context.Background(), []string{"inboxes", "list", "-o", "csv"}); err != nil {
+			t.Fatalf("inboxes list -o csv failed: %v", err)
+		}
+	})
+	if !strings.HasPrefix(output, "ID,NAME,CHANNEL TYPE,AUTO ASSIGN\n1,\"Email, Support\",Channel::Email,") {
+		t.Errorf("unexpected CSV output:\n%s", output)
+	}
+
+	output = captureStdout(t, func() {
+		if err := Execute(context.Background(), []string{"inboxes", "list", "-o", "csv", "--fields", "id,channel_type"}); err != nil {
+			t.Fatalf("inboxes list -o csv --fields failed: %v", err)
+		}
+	})
+	if want := "id,channel_type\n1,Channel::Email\n2,Channel::WebWidget\n"; output != want {
+		t.Errorf("--fields CSV = %q, want %q", output, want)
+	}
+
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"inboxes", "list", "-o", "xlsx", "--output-file", filepath.Join(dir, "a.xlsx")},
+		{"inboxes", "list", "--out", filepath.Join(dir, "b.xlsx")},
+	} {
+		output = captureStdout(t, func() {
+			if err := Execute(context.Background(), args); err != nil {
+				t.Fatalf("%v failed: %v", args, err)
+			}
+		})
+		if output != "" {
+			t.Errorf("%v: expected nothing on stdout, got %q", args, output)
+		}
+	}
+	for _, name := range []string{"a.xlsx", "b.xlsx"} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.HasPrefix(data, []byte("PK")) || !bytes.Contains(data, []byte("xl/worksheets/sheet1.xml")) {
+			t.Errorf("%s is not an XLSX workbook", name)
+		}
+	}
+}
+
+func TestExecute_TabularOutputValidation(t *testing.T) {
+	setupTestEnvWithHandler(t, newRouteHandler())
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"inboxes", "list", "-o", "csv", "--query", ".items"}, "require --output json"},
+		{[]string{"inboxes", "list", "-o", "json", "--output-file", "x.csv"}, "--output-file requires --output csv or xlsx"},
+		{[]string{"inboxes", "list", "-o", "a.csv", "--output-file", "b.csv"}, "conflicts with --output-file"},
+	}
+	for _, tt := range tests {
+		err := Execute(context.Background(), tt.args)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%v: expected error containing %q, got %v", tt.args, tt.want, err)
+		}
+	}
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/chatwoot/chatwoot-cli/internal/agentfmt"
 	"github.com/chatwoot/chatwoot-cli/internal/api"
+	"github.com/chatwoot/chatwoot-cli/internal/mirror"
 	"github.com/chatwoot/chatwoot-cli/internal/outfmt"
 	"github.com/spf13/cobra"
 )
@@ -68,6 +69,7 @@ func newSearchCmd() *cobra.Command {
 		best           bool
 		emit           string
 		light          bool
+		offline        bool
 	)
 
 	cmd := &cobra.Command{
@@ -196,6 +198,19 @@ Light mode (--light/--li) already uses compact JSON output by default.`,
 
 			ctx := cmdContext(cmd)
 
+			var m *mirror.Mirror
+			if offline {
+				m, err = syncedMirror(client)
+				if err != nil {
+					return err
+				}
+				if err := searchMirror(m, query, searchTypes, limit, &results); err != nil {
+					return err
+				}
+				// The mirror answered every type; skip the API fan-out below.
+				searchTypes = nil
+			}
+
 			for _, searchType := range searchTypes {
 				wg.Add(1)
 				go func(st string) {
@@ -461,7 +476,12 @@ Light mode (--light/--li) already uses compact JSON output by default.`,
 			if includeSnippet && len(results.Conversations) > 0 {
 				results.Snippets = make(map[string]SnippetInfo)
 				for _, conv := range results.Conversations {
-					messages, err := client.Messages().List(ctx, conv.ID)
+					var messages []api.Message
+					if m != nil {
+						messages, err = m.Messages(conv.ID)
+					} else {
+						messages, err = client.Messages().List(ctx, conv.ID)
+					}
 					if err != nil {
 						// Skip conversations where we can't fetch messages
 						continue
@@ -599,7 +619,9 @@ Light mode (--light/--li) already uses compact JSON output by default.`,
 					case "conversation":
 						if bestResult.Conversation != nil {
 							item := agentfmt.ConversationDetailFromConversation(*bestResult.Conversation)
-							item = resolveConversationDetail(ctx, client, item)
+							if !offline {
+								item = resolveConversationDetail(ctx, client, item)
+							}
 							payload.Item = agentBest{Type: bestResult.Type, ID: bestID, URL: bestURL, Item: item}
 						}
 					case "sender":
@@ -879,6 +901,7 @@ Light mode (--light/--li) already uses compact JSON output by default.`,
 	cmd.Flags().StringVarP(&emit, "emit", "E", "", "Output format with --best: json (default), id, or url")
 	cmd.Flags().BoolVar(&light, "light", false, "Return minimal search payload for lookup (defaults to compact JSON; override with --cj=false)")
 	flagAlias(cmd.Flags(), "light", "li")
+	addOfflineFlag(cmd, &offline)
 
 	return cmd
 }
@@ -0,0 +1,461 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+	"github.com/chatwoot/chatwoot-cli/internal/sla"
+)
+
+func newSLACmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "sla",
+		Aliases: []string{"sl"},
+		Short:   "Track first-response and next-response deadlines",
+		Long: strings.TrimSpace(`
+Compute response deadlines for open conversations from their message history.
+
+The first-response clock starts when the conversation is created and stops at
+the first agent reply. The next-response clock starts at the oldest customer
+message after the latest agent reply. Private notes and activity messages are
+ignored. When the inbox has working hours enabled, only time inside those
+hours (in the inbox time zone) counts.
+
+Policy file (YAML or JSON); the first matching policy applies:
+
+  version: 1
+  at_risk: 15m
+  policies:
+    - name: vip
+      match:
+        labels: [vip]
+      first_response: 15m
+      next_response: 30m
+    - name: urgent
+      match:
+        priorities: [urgent, high]
+      first_response: 30m
+      next_response: 1h
+      at_risk: 10m
+    - name: email
+      match:
+        inbox_ids: [3]
+      first_response: 4h
+      next_response: 8h
+      business_hours: false
+    - name: default
+      first_response: 8h
+      next_response: 24h
+
+Conversations within at_risk of their deadline are reported as at risk.
+`),
+	}
+
+	cmd.AddCommand(newSLACheckCmd())
+	cmd.AddCommand(newSLAWatchCmd())
+	return cmd
+}
+
+func newSLACheckCmd() *cobra.Command {
+	var (
+		policyPath string
+		inboxID    int
+		all        bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "List breached and at-risk open conversations",
+		Example: strings.TrimSpace(`
+  cw sla check --policy sla.yaml
+  cw sla check --policy sla.yaml --inbox 3 --all
+  cw sla check --policy sla.yaml -o json
+`),
+		Args: cobra.NoArgs,
+		RunE: RunE(func(cmd *cobra.Command, _ []string) error {
+			policies, err := sla.Load(policyPath)
+			if err != nil {
+				return err
+			}
+			client, err := getClient()
+			if err != nil {
+				return err
+			}
+
+			ctx := cmdContext(cmd)
+			checker := sla.NewChecker(policies, sla.NewAPIClient(client), nil)
+			statuses, checked, err := checkOpenConversations(ctx, client, checker, inboxID)
+			if err != nil {
+				return err
+			}
+
+			var breached, atRisk int
+			items := make([]sla.Status, 0, len(statuses))
+			for _, st := range statuses {
+				switch st.State {
+				case sla.StateBreached:
+					breached++
+				case sla.StateAtRisk:
+					atRisk++
+				}
+				if all || st.State != sla.StateOK {
+					items = append(items, st)
+				}
+			}
+
+			if isJSON(cmd) {
+				return printJSON(cmd, map[string]any{
+					"checked":  checked,
+					"pending":  len(statuses),
+					"breached": breached,
+					"at_risk":  atRisk,
+					"items":    items,
+				})
+			}
+			if len(items) == 0 {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "No breached or at-risk conversations (%d open checked)\n", checked)
+				return nil
+			}
+			w := newTabWriterFromCmd(cmd)
+			_, _ = fmt.Fprintln(w, "CONVERSATION\tINBOX\tPOLICY\tTARGET\tSTATE\tREMAINING\tDEADLINE")
+			for _, st := range items {
+				_, _ = fmt.Fprintf(w, "%d\t%d\t%s\t%s\t%s\t%s\t%s\n",
+					st.ConversationID, st.InboxID, st.Policy, st.Target, st.State,
+					formatSLARemaining(st.Remaining), st.Deadline.Local().Format("2006-01-02 15:04"))
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "\n%d breached, %d at risk (%d open checked)\n", breached, atRisk, checked)
+			return nil
+		}),
+	}
+
+	cmd.Flags().StringVar(&policyPath, "policy", "", "SLA policy file (YAML or JSON)")
+	cmd.Flags().IntVar(&inboxID, "inbox", 0, "Only check conversations in this inbox")
+	cmd.Flags().BoolVar(&all, "all", false, "Also list conversations that are within their targets")
+	_ = cmd.MarkFlagRequired("policy")
+	flagAlias(cmd.Flags(), "policy", "pol")
+	flagAlias(cmd.Flags(), "inbox", "ib")
+	return cmd
+}
+
+func newSLAWatchCmd() *cobra.Command {
+	var (
+		policyPath  string
+		inboxID     int
+		fromStdin   bool
+		recheck     time.Duration
+		execHandler string
+		execTimeout time.Duration
+		execFatal   bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "watch",
+		Short: "Re-evaluate deadlines on live events and run a hook on breach",
+		Long: strings.TrimSpace(`
+Check all open conversations, then re-evaluate a conversation whenever the
+real-time event stream reports a new message or a conversation change. Tracked
+deadlines are also re-assessed on the --recheck interval, so a breach is
+reported even when nothing happens in the conversation.
+
+State changes are printed as they happen. With --exec, the command runs once
+per breached deadline with the breach record as JSON on stdin. With --stdin,
+events are read as JSON lines produced by "cw conversations follow --all -o jsonl"
+instead of connecting to the WebSocket.
+`),
+		Example: strings.TrimSpace(`
+  # Print state changes
+  cw sla watch --policy sla.yaml
+
+  # Page someone on every breach
+  cw sla watch --policy sla.yaml --exec './notify-oncall.sh'
+`),
+		Args: cobra.NoArgs,
+		RunE: RunE(func(cmd *cobra.Command, _ []string) error {
+			policies, err := sla.Load(policyPath)
+			if err != nil {
+				return err
+			}
+			if recheck <= 0 {
+				return fmt.Errorf("--recheck must be positive")
+			}
+
+			ctx, stop := signal.NotifyContext(cmdContext(cmd), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			cmd.SetContext(ctx)
+
+			client, err := getClient()
+			if err != nil {
+				return err
+			}
+			checker := sla.NewChecker(policies, sla.NewAPIClient(client), nil)
+			hook := newFollowExecHook(cmd, execHandler, execTimeout, execFatal)
+			w := &slaWatcher{cmd: cmd, checker: checker, hook: hook, inboxID: inboxID,
+				tracked: map[int]sla.Status{}, fired: map[int]string{}}
+
+			statuses, checked, err := checkOpenConversations(ctx, client, checker, inboxID)
+			if err != nil {
+				return err
+			}
+			if !isJSON(cmd) {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Watching %d pending deadlines across %d open conversations (press Ctrl+C to stop)\n", len(statuses), checked)
+			}
+			for _, st := range statuses {
+				if err := w.update(st.ConversationID, st, true); err != nil {
+					return err
+				}
+			}
+
+			triggers := make(chan int, 256)
+			sourceErr := make(chan error, 1)
+			go func() {
+				var err error
+				if fromStdin {
+					err = readFollowTriggers(ctx, cmd.InOrStdin(), slaTriggerFromEvent, triggers)
+				} else {
+					err = streamFollowTriggers(ctx, cmd, client, slaTriggerFromEvent, triggers)
+				}
+				close(triggers)
+				if err != nil {
+					sourceErr <- err
+				}
+			}()
+
+			ticker := time.NewTicker(recheck)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return nil
+				case err := <-sourceErr:
+					return err
+				case id, ok := <-triggers:
+					if !ok {
+						select {
+						case err := <-sourceErr:
+							return err
+						default:
+							return nil
+						}
+					}
+					st, pending, err := checker.CheckID(ctx, id)
+					if err != nil {
+						if ctx.Err() != nil {
+							return nil
+						}
+						// One conversation failing to load should not stop the watch.
+						_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "sla: conversation %d: %v\n", id, err)
+						continue
+					}
+					if err := w.update(id, st, pending); err != nil {
+						return err
+					}
+				case <-ticker.C:
+					if err := w.reassessAll(); err != nil {
+						return err
+					}
+				}
+			}
+		}),
+	}
+
+	cmd.Flags().StringVar(&policyPath, "policy", "", "SLA policy file (YAML or JSON)")
+	cmd.Flags().IntVar(&inboxID, "inbox", 0, "Only watch conversations in this inbox")
+	cmd.Flags().BoolVar(&fromStdin, "stdin", false, "Read follow events (JSON lines) from stdin instead of connecting to the WebSocket")
+	cmd.Flags().DurationVar(&recheck, "recheck", time.Minute, "Re-assess tracked deadlines on this interval")
+	cmd.Flags().StringVar(&execHandler, "exec", "", "Run a command for each breach (breach JSON on stdin)")
+	cmd.Flags().DurationVar(&execTimeout, "exec-timeout", 30*time.Second, "Timeout per --exec invocation")
+	cmd.Flags().BoolVar(&execFatal, "exec-fatal", false, "Treat --exec failures as fatal (default: log to stderr and continue)")
+	_ = cmd.MarkFlagRequired("policy")
+	flagAlias(cmd.Flags(), "policy", "pol")
+	flagAlias(cmd.Flags(), "inbox", "ib")
+	flagAlias(cmd.Flags(), "recheck", "rc")
+	flagAlias(cmd.Flags(), "exec", "ex")
+	flagAlias(cmd.Flags(), "exec-timeout", "et")
+	flagAlias(cmd.Flags(), "exec-fatal", "ef")
+	return cmd
+}
+
+// checkOpenConversations evaluates every open conversation (optionally in one
+// inbox) and returns the pending deadlines, most overdue first.
+func checkOpenConversations(ctx context.Context, client *api.Client, checker *sla.Checker, inboxID int) ([]sla.Status, int, error) {
+	params := api.ListConversationsParams{Status: "open"}
+	if inboxID > 0 {
+		params.InboxID = strconv.Itoa(inboxID)
+	}
+	var statuses []sla.Status
+	checked := 0
+	for page := 1; ; page++ {
+		params.Page = page
+		result, err := client.Conversations().List(ctx, params)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to list open conversations: %w", err)
+		}
+		items := result.Data.Payload
+		for i := range items {
+			st, ok, err := checker.Check(ctx, &items[i])
+			if err != nil {
+				return nil, 0, fmt.Errorf("conversation %d: %w", items[i].ID, err)
+			}
+			checked++
+			if ok {
+				statuses = append(statuses, st)
+			}
+		}
+		totalPages := int(result.Data.Meta.TotalPages)
+		if len(items) == 0 || totalPages == 0 || page >= totalPages {
+			break
+		}
+	}
+	sort.SliceStable(statuses, func(i, j int) bool { return statuses[i].Remaining < statuses[j].Remaining })
+	return statuses, checked, nil
+}
+
+// slaTriggerFromEvent returns the conversation to re-evaluate for events that
+// can start or stop a response clock. Private notes are ignored.
+func slaTriggerFromEvent(event string, data json.RawMessage) (int, bool) {
+	switch event {
+	case "message.created":
+		var msg struct {
+			ConversationID int  `json:"conversation_id"`
+			Private        bool `json:"private"`
+		}
+		if err := json.Unmarshal(data, &msg); err != nil || msg.Private || msg.ConversationID <= 0 {
+			return 0, false
+		}
+		return msg.ConversationID, true
+	case "conversation.created", "conversation.updated", "conversation.status_changed":
+	default:
+		return 0, false
+	}
+	id := conversationIDFromEvent(event, data)
+	return id, id > 0
+}
+
+// slaWatcher tracks pending deadlines and reports state changes.
+type slaWatcher struct {
+	cmd     *cobra.Command
+	checker *sla.Checker
+	hook    *followExecHook
+	inboxID int
+	tracked map[int]sla.Status
+	// fired holds the deadline key the hook last ran for, per conversation.
+	fired map[int]string
+}
+
+type slaRecord struct {
+	Kind   string     `json:"kind"`
+	Time   time.Time  `json:"ts"`
+	Status sla.Status `json:"status"`
+}
+
+func (w *slaWatcher) update(id int, st sla.Status, pending bool) error {
+	if pending && w.inboxID > 0 && st.InboxID != w.inboxID {
+		pending = false
+	}
+	prev, wasTracked := w.tracked[id]
+	if !pending {
+		delete(w.tracked, id)
+		delete(w.fired, id)
+		if wasTracked && prev.State != sla.StateOK {
+			return w.emit("sla.cleared", prev)
+		}
+		return nil
+	}
+	w.tracked[id] = st
+	if wasTracked && prev.Key() == st.Key() && prev.State == st.State {
+		return nil
+	}
+	switch st.State {
+	case sla.StateAtRisk:
+		return w.emit("sla.at_risk", st)
+	case sla.StateBreached:
+		return w.breach(st)
+	default:
+		if wasTracked && prev.State != sla.StateOK {
+			return w.emit("sla.cleared", prev)
+		}
+	}
+	return nil
+}
+
+func (w *slaWatcher) reassessAll() error {
+	ids := make([]int, 0, len(w.tracked))
+	for id := range w.tracked {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	for _, id := range ids {
+		if err := w.update(id, w.checker.Reassess(w.tracked[id]), true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// breach reports st and runs the hook once per deadline.
+func (w *slaWatcher) breach(st sla.Status) error {
+	rec := slaRecord{Kind: "sla.breach", Time: time.Now().UTC(), Status: st}
+	if w.fired[st.ConversationID] != st.Key() {
+		w.fired[st.ConversationID] = st.Key()
+		if err := w.hook.Run(rec); err != nil {
+			if w.hook.fatal {
+				return fmt.Errorf("--exec failed: %w", err)
+			}
+			_, _ = fmt.Fprintf(w.cmd.ErrOrStderr(), "exec hook error: %v\n", err)
+		}
+	}
+	return w.print(rec)
+}
+
+func (w *slaWatcher) emit(kind string, st sla.Status) error {
+	return w.print(slaRecord{Kind: kind, Time: time.Now().UTC(), Status: st})
+}
+
+func (w *slaWatcher) print(rec slaRecord) error {
+	if isJSON(w.cmd) {
+		return writeStreamJSON(w.cmd, rec)
+	}
+	label := map[string]string{"sla.breach": "BREACHED", "sla.at_risk": "AT RISK", "sla.cleared": "CLEARED"}[rec.Kind]
+	detail := formatSLARemaining(rec.Status.Remaining) + " left"
+	switch rec.Kind {
+	case "sla.breach":
+		detail = formatSLARemaining(rec.Status.Remaining)
+	case "sla.cleared":
+		detail = "answered or closed"
+	}
+	_, err := fmt.Fprintf(w.cmd.OutOrStdout(), "[%s] %s #%d %s (%s): %s\n",
+		rec.Time.Local().Format("15:04:05"), label, rec.Status.ConversationID, rec.Status.Target, rec.Status.Policy, detail)
+	return err
+}
+
+// formatSLARemaining renders business time left, or how far past the deadline.
+func formatSLARemaining(d time.Duration) string {
+	prefix := ""
+	if d <= 0 {
+		prefix = "overdue "
+		d = -d
+	}
+	d = d.Truncate(time.Minute)
+	switch {
+	case d < time.Hour:
+		return fmt.Sprintf("%s%dm", prefix, int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%s%dh %dm", prefix, int(d.Hours()), int(d.Minutes())%60)
+	default:
+		return fmt.Sprintf("%s%dd %dh", prefix, int(d.Hours())/24, int(d.Hours())%24)
+	}
+}
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const slaTestPolicy = `
+policies:
+  - name: default
+    first_response: 1h
+    next_response: 4h
+`
+
+// setupSLATest serves two open conversations: #7 is unanswered for two hours
+// (breached) and #8 was created ten minutes ago (within target). Once #7 is
+// fetched individually (as watch does on an event), its messages include an
+// agent reply.
+func setupSLATest(t *testing.T) (policyPath string) {
+	t.Helper()
+	ago := func(d time.Duration) string { return strconv.FormatInt(time.Now().Add(-d).Unix(), 10) }
+	var answered atomic.Bool
+
+	setupTestEnvWithHandler(t, newRouteHandler().
+		On("GET", "/api/v1/accounts/1/conversations", jsonResponse(200, `{"data": {"meta": {"total_pages": 1}, "payload": [
+			{"id": 7, "inbox_id": 1, "status": "open", "created_at": `+ago(2*time.Hour)+`},
+			{"id": 8, "inbox_id": 1, "status": "open", "created_at": `+ago(10*time.Minute)+`}
+		]}}`)).
+		On("GET", "/api/v1/accounts/1/conversations/7", func(w http.ResponseWriter, r *http.Request) {
+			answered.Store(true)
+			jsonResponse(200, `{"id": 7, "inbox_id": 1, "status": "open", "created_at": `+ago(2*time.Hour)+`}`)(w, r)
+		}).
+		On("GET", "/api/v1/accounts/1/conversations/7/messages", func(w http.ResponseWriter, r *http.Request) {
+			messages := `{"id": 1, "conversation_id": 7, "message_type": 0, "content": "hello?", "created_at": ` + ago(2*time.Hour) + `}`
+			if answered.Load() {
+				messages += `, {"id": 2, "conversation_id": 7, "message_type": 1, "content": "hi!", "created_at": ` + ago(0) + `}`
+			}
+			jsonResponse(200, `{"payload": [`+messages+`]}`)(w, r)
+		}).
+		On("GET", "/api/v1/accounts/1/conversations/8/messages", jsonResponse(200, `{"payload": []}`)).
+		On("GET", "/api/v1/accounts/1/inboxes/1", jsonResponse(200, `{"id": 1, "name": "Support"}`)))
+
+	policyPath = filepath.Join(t.TempDir(), "sla.yaml")
+	if err := os.WriteFile(policyPath, []byte(slaTestPolicy), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return policyPath
+}
+
+func TestSLACheck(t *testing.T) {
+	policyPath := setupSLATest(t)
+
+	output := captureStdout(t, func() {
+		if err := Execute(context.Background(), []string{"sla", "check", "--policy", policyPath}); err != nil {
+			t.Fatalf("sla check failed: %v", err)
+		}
+	})
+	if !strings.Contains(output, "first_response") || !strings.Contains(output, "breached") || !strings.Contains(output, "overdue 1h") {
+		t.Fatalf("unexpected output: %s", output)
+	}
+	if strings.Contains(output, "\n8 ") || !strings.Contains(output, "1 breached, 0 at risk (2 open checked)") {
+		t.Fatalf("conversation within target should be hidden without --all: %s", output)
+	}
+
+	output = captureStdout(t, func() {
+		if err := Execute(context.Background(), []string{"sla", "check", "--policy", policyPath, "--all", "-o", "json"}); err != nil {
+			t.Fatalf("sla check failed: %v", err)
+		}
+	})
+	for _, want := range []string{`"checked": 2`, `"breached": 1`, `"conversation_id": 8`, `"state": "ok"`} {
+		if !strings.Contains(output, want) {
+			t.Fatalf("missing %s in output: %s", want, output)
+		}
+	}
+}
+
+func TestSLAWatchFiresHookOnBreach(t *testing.T) {
+	policyPath := setupSLATest(t)
+	hookOut := filepath.Join(t.TempDir(), "breaches.jsonl")
+
+	pipeStdin(t, `{"kind":"conversations.follow","type":"message","event":"message.created","conversation_id":7,"item":{"id":2,"conversation_id":7,"message_type":1,"content":"hi!"}}`+"\n")
+
+	output := captureStdout(t, func() {
+		if err := Execute(context.Background(), []string{"sla", "watch", "--policy", policyPath, "--stdin", "--exec", "cat >> " + hookOut}); err != nil {
+			t.Fatalf("sla watch failed: %v", err)
+		}
+	})
+	if !strings.Contains(output, "BREACHED #7 first_response (default)") || !strings.Contains(output, "CLEARED #7") {
+		t.Fatalf("unexpected output: %s", output)
+	}
+	if strings.Contains(output, "#8") {
+		t.Fatalf("conversation within target should not be reported: %s", output)
+	}
+	data, err := os.ReadFile(hookOut)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(data), `"kind":"sla.breach"`); n != 1 || !strings.Contains(string(data), `"conversation_id":7`) {
+		t.Fatalf("hook should run once for the breach, got %d: %s", n, data)
+	}
+}
+
+func TestSLATriggerFromEvent(t *testing.T) {
+	tests := []struct {
+		event string
+		data  string
+		want  int
+		ok    bool
+	}{
+		{"message.created", `{"id": 12, "conversation_id": 3, "message_type": 1}`, 3, true},
+		{"message.created", `{"conversation_id": 3, "private": true}`, 0, false},
+		{"conversation.updated", `{"id": 4}`, 4, true},
+		{"conversation.status_changed", `{"id": 4, "status": "resolved"}`, 4, true},
+		{"typing.on", `{"conversation": {"id": 4}}`, 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := slaTriggerFromEvent(tt.event, []byte(tt.data))
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("%s %s = %d, %v; want %d, %v", tt.event, tt.data, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestFormatSLARemaining(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		45 * time.Minute:             "45m",
+		90 * time.Minute:             "1h 30m",
+		-(26 * time.Hour):            "overdue 1d 2h",
+		-(30 * time.Second):          "overdue 0m",
+		3*time.Hour + 59*time.Second: "3h 0m",
+	} {
+		if got := formatSLARemaining(d); got != want {
+			t.Errorf("formatSLARemaining(%s) = %q, want %q", d, got, want)
+		}
+	}
+}
@@ -116,6 +116,7 @@ This is a convenience shortcut for:
 	cmd.Flags().StringVar(&note, "note", "", "Add a private note before snoozing")
 	_ = cmd.MarkFlagRequired("for")
 	flagAlias(cmd.Flags(), "note", "nt")
+	registerCommandContract(cmd, true, true)
 
 	return cmd
 }
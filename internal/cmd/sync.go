@@ -0,0 +1,481 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+	"github.com/chatwoot/chatwoot-cli/internal/mirror"
+)
+
+// syncSummary is the result of one `cw sync` run.
+type syncSummary struct {
+	Dir                  string `json:"dir"`
+	Full                 bool   `json:"full"`
+	Pages                int    `json:"pages"`
+	ConversationsScanned int    `json:"conversations_scanned"`
+	ConversationsUpdated int    `json:"conversations_updated"`
+	MessagesAdded        int    `json:"messages_added"`
+	MessageFailures      int    `json:"message_failures"`
+	MessagesTruncated    int    `json:"messages_truncated"`
+	ContactsUpdated      int    `json:"contacts_updated"`
+	Truncated            bool   `json:"truncated"`
+	Labels               int    `json:"labels"`
+	DurationMS           int64  `json:"duration_ms"`
+}
+
+func newSyncCmd() *cobra.Command {
+	var (
+		full            bool
+		maxPages        int
+		maxMessagePages int
+		noMessages      bool
+		noContacts      bool
+		noLabels        bool
+		concurrency     int
+		progress        bool
+		noProgress      bool
+	)
+
+	cmd := &cobra.Command{
+		Use:     "sync",
+		Aliases: []string{"sy"},
+		Short:   "Sync a local offline mirror of conversations, messages, contacts, and labels",
+		Long: strings.TrimSpace(`
+Maintain a durable local mirror of the account under the cache directory.
+
+Each run is incremental: conversations are fetched newest-activity first and
+the scan stops once it reaches conversations whose last_activity_at is already
+mirrored. Only new messages (by message ID) are fetched for changed
+conversations. A conversation with more than --max-message-pages pages of new
+messages keeps the newest ones and picks up the rest on the next run. Use
+--full to rescan everything.
+
+Read commands accept --offline to answer from the mirror instead of the API:
+conversations list, messages list, ctx, and search.
+`),
+		Example: strings.TrimSpace(`
+  # Incremental sync (run from cron or before a batch of lookups)
+  cw sync
+
+  # Rescan everything
+  cw sync --full
+
+  # Conversations and messages only
+  cw sync --no-contacts --no-labels
+
+  # Read from the mirror
+  cw c ls --offline --status open
+  cw ctx 123 --offline -o agent
+`),
+		Args: cobra.NoArgs,
+		RunE: RunE(func(cmd *cobra.Command, args []string) error {
+			client, err := getClient()
+			if err != nil {
+				return err
+			}
+			m, err := openMirror(client)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmdContext(cmd)
+			start := time.Now()
+			state, err := m.State()
+			if err != nil {
+				return err
+			}
+			if full {
+				state.LastActivityAt = 0
+				state.ContactsActivityAt = 0
+			}
+
+			summary := syncSummary{Dir: m.Dir(), Full: full}
+
+			changed, watermark, err := syncConversations(ctx, client, m, state.LastActivityAt, maxPages, &summary)
+			if err != nil {
+				return err
+			}
+
+			var pending []int
+			if !noMessages {
+				// Conversations left with a message gap by an earlier run are
+				// revisited even when they have not changed since.
+				if pending, err = m.MessageGaps(); err != nil {
+					return err
+				}
+				pending = mergeIDs(changed, pending)
+			}
+			if len(pending) > 0 {
+				results := runBulkOperation(
+					ctx,
+					pending,
+					int64(concurrency),
+					bulkProgressEnabled(cmd, progress, noProgress),
+					cmd.ErrOrStderr(),
+					func(ctx context.Context, id int) (syncMessagesResult, error) {
+						return syncConversationMessages(ctx, client, m, id, maxMessagePages)
+					},
+				)
+				for _, r := range results {
+					if !r.Success {
+						summary.MessageFailures++
+						_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Failed to sync messages for conversation %d: %v\n", r.ID, r.Error)
+						continue
+					}
+					if res, ok := r.Data.(syncMessagesResult); ok {
+						summary.MessagesAdded += res.Added
+						if res.Truncated {
+							summary.MessagesTruncated++
+						}
+					}
+				}
+			}
+
+			if !noContacts {
+				contactsWatermark, updated, err := syncContacts(ctx, client, m, state.ContactsActivityAt, maxPages, &summary)
+				if err != nil {
+					return err
+				}
+				summary.ContactsUpdated = updated
+				state.ContactsActivityAt = contactsWatermark
+			}
+
+			if !noLabels {
+				labels, err := client.Labels().List(ctx)
+				if err != nil {
+					return fmt.Errorf("failed to sync labels: %w", err)
+				}
+				if err := m.SetLabels(labels); err != nil {
+					return err
+				}
+				summary.Labels = len(labels)
+			}
+
+			// Only advance the conversation watermark when every changed
+			// conversation's messages were mirrored; otherwise the next run
+			// revisits them.
+			if summary.MessageFailures == 0 {
+				state.LastActivityAt = max(state.LastActivityAt, watermark)
+			}
+			state.LastSyncAt = time.Now().UTC()
+			if err := m.SaveState(state); err != nil {
+				return err
+			}
+			summary.DurationMS = time.Since(start).Milliseconds()
+
+			if isJSON(cmd) {
+				return printJSON(cmd, summary)
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Synced %d conversations (%d updated), %d new messages, %d contacts, %d labels in %s\n",
+				summary.ConversationsScanned,
+				summary.ConversationsUpdated,
+				summary.MessagesAdded,
+				summary.ContactsUpdated,
+				summary.Labels,
+				time.Duration(summary.DurationMS)*time.Millisecond,
+			)
+			if summary.Truncated {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Stopped at --max-pages %d before reaching the last sync point; run again or raise --max-pages to finish\n", maxPages)
+			}
+			if summary.MessagesTruncated > 0 {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Stopped at --max-message-pages %d in %d conversations; run again or raise --max-message-pages to finish\n", maxMessagePages, summary.MessagesTruncated)
+			}
+			if summary.MessageFailures > 0 {
+				return fmt.Errorf("failed to sync messages for %d conversations", summary.MessageFailures)
+			}
+			return nil
+		}),
+	}
+
+	cmd.Flags().BoolVar(&full, "full", false, "Rescan all conversations and contacts instead of stopping at the last sync point")
+	cmd.Flags().IntVarP(&maxPages, "max-pages", "M", 100, "Maximum conversation/contact pages to fetch per run")
+	cmd.Flags().IntVar(&maxMessagePages, "max-message-pages", 100, "Maximum message pages to fetch per conversation")
+	cmd.Flags().BoolVar(&noMessages, "no-messages", false, "Skip message sync")
+	cmd.Flags().BoolVar(&noContacts, "no-contacts", false, "Skip contact sync")
+	cmd.Flags().BoolVar(&noLabels, "no-labels", false, "Skip label sync")
+	cmd.Flags().IntVar(&concurrency, "concurrency", DefaultConcurrency, "Max concurrent message fetches")
+	cmd.Flags().BoolVar(&progress, "progress", true, "Show progress while running")
+	cmd.Flags().BoolVar(&noProgress, "no-progress", false, "Disable progress output")
+	flagAlias(cmd.Flags(), "max-pages", "mp")
+	flagAlias(cmd.Flags(), "max-message-pages", "mmp")
+	flagAlias(cmd.Flags(), "concurrency", "cc")
+	flagAlias(cmd.Flags(), "progress", "prg")
+	flagAlias(cmd.Flags(), "no-progress", "npr")
+
+	cmd.AddCommand(newSyncStatusCmd())
+	cmd.AddCommand(newSyncClearCmd())
+
+	return cmd
+}
+
+func newSyncStatusCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "status",
+		Aliases: []string{"st"},
+		Short:   "Show offline mirror status",
+		Example: "  cw sync status",
+		Args:    cobra.NoArgs,
+		RunE: RunE(func(cmd *cobra.Command, _ []string) error {
+			client, err := getClient()
+			if err != nil {
+				return err
+			}
+			m, err := openMirror(client)
+			if err != nil {
+				return err
+			}
+			state, err := m.State()
+			if err != nil {
+				return err
+			}
+			stats, err := m.Stats()
+			if err != nil {
+				return err
+			}
+
+			if isJSON(cmd) {
+				out := map[string]any{
+					"dir":    m.Dir(),
+					"synced": !state.LastSyncAt.IsZero(),
+					"stats":  stats,
+				}
+				if !state.LastSyncAt.IsZero() {
+					out["last_sync_at"] = state.LastSyncAt.Format(time.RFC3339)
+				}
+				if state.LastActivityAt > 0 {
+					out["last_activity_at"] = state.LastActivityAt
+				}
+				return printJSON(cmd, out)
+			}
+
+			tw := newTabWriterFromCmd(cmd)
+			defer func() { _ = tw.Flush() }()
+			lastSync := "never"
+			if !state.LastSyncAt.IsZero() {
+				lastSync = formatTimestamp(state.LastSyncAt)
+			}
+			_, _ = fmt.Fprintf(tw, "Directory:\t%s\n", m.Dir())
+			_, _ = fmt.Fprintf(tw, "Last sync:\t%s\n", lastSync)
+			_, _ = fmt.Fprintf(tw, "Conversations:\t%d\n", stats.Conversations)
+			_, _ = fmt.Fprintf(tw, "Messages:\t%d\n", stats.Messages)
+			_, _ = fmt.Fprintf(tw, "Contacts:\t%d\n", stats.Contacts)
+			_, _ = fmt.Fprintf(tw, "Labels:\t%d\n", stats.Labels)
+			return nil
+		}),
+	}
+}
+
+func newSyncClearCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "clear",
+		Short: "Delete the offline mirror for the current account",
+		Args:  cobra.NoArgs,
+		RunE: RunE(func(cmd *cobra.Command, _ []string) error {
+			client, err := getClient()
+			if err != nil {
+				return err
+			}
+			m, err := openMirror(client)
+			if err != nil {
+				return err
+			}
+			if err := m.Clear(); err != nil {
+				return fmt.Errorf("failed to clear offline mirror: %w", err)
+			}
+			if isJSON(cmd) {
+				return printJSON(cmd, map[string]any{"cleared": true, "dir": m.Dir()})
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Offline mirror cleared: %s\n", m.Dir())
+			return nil
+		}),
+	}
+	registerCommandContract(cmd, true, false)
+	return cmd
+}
+
+// syncConversations pages through conversations (newest activity first) and upserts
+// changed ones into the mirror. It stops after the first page that reaches
+// conversations older than sinceActivity. Returns the changed IDs and the highest
+// last_activity_at seen.
+func syncConversations(ctx context.Context, client *api.Client, m *mirror.Mirror, sinceActivity int64, maxPages int, summary *syncSummary) ([]int, int64, error) {
+	existing, err := m.Conversations()
+	if err != nil {
+		return nil, 0, err
+	}
+	known := make(map[int]int64, len(existing))
+	for _, c := range existing {
+		known[c.ID] = c.LastActivityAt
+	}
+
+	var changed []int
+	watermark := sinceActivity
+	complete := false
+	for page := 1; maxPages <= 0 || page <= maxPages; page++ {
+		if err := ctx.Err(); err != nil {
+			return nil, 0, err
+		}
+		result, err := client.Conversations().List(ctx, api.ListConversationsParams{Status: "all", Page: page})
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to list conversations: %w", err)
+		}
+		items := result.Data.Payload
+		summary.Pages++
+		summary.ConversationsScanned += len(items)
+
+		var updated []api.Conversation
+		reachedSynced := false
+		for _, conv := range items {
+			watermark = max(watermark, conv.LastActivityAt)
+			if sinceActivity > 0 && conv.LastActivityAt < sinceActivity {
+				reachedSynced = true
+			}
+			if prev, ok := known[conv.ID]; ok && prev >= conv.LastActivityAt && sinceActivity > 0 {
+				continue
+			}
+			known[conv.ID] = conv.LastActivityAt
+			updated = append(updated, conv)
+			changed = append(changed, conv.ID)
+		}
+		if err := m.PutConversations(updated); err != nil {
+			return nil, 0, err
+		}
+		summary.ConversationsUpdated += len(updated)
+
+		totalPages := int(result.Data.Meta.TotalPages)
+		if len(items) == 0 || reachedSynced || totalPages == 0 || page >= totalPages {
+			complete = true
+			break
+		}
+	}
+	// Stopping at --max-pages leaves a gap between the pages scanned and the
+	// last sync point; keep the old watermark so the next run covers it.
+	if !complete {
+		summary.Truncated = true
+		return changed, sinceActivity, nil
+	}
+	return changed, watermark, nil
+}
+
+// syncMessagesResult is what syncConversationMessages did for one conversation.
+type syncMessagesResult struct {
+	Added int
+	// Truncated reports that maxPages left a message gap for the next run.
+	Truncated bool
+}
+
+// syncConversationMessages fetches messages newer than the mirrored high-water
+// mark. A conversation with more than maxPages pages of them keeps the newest
+// and records the gap below; the next call fills the gap before fetching
+// anything newer.
+func syncConversationMessages(ctx context.Context, client *api.Client, m *mirror.Mirror, conversationID, maxPages int) (syncMessagesResult, error) {
+	var res syncMessagesResult
+	gap, ok, err := m.MessageGap(conversationID)
+	if err != nil {
+		return res, err
+	}
+	if !ok {
+		last, err := m.LastMessageID(conversationID)
+		if err != nil {
+			return res, err
+		}
+		gap = mirror.MessageGap{After: last}
+	}
+	msgs, rest, err := client.Messages().ListBetween(ctx, conversationID, gap.After, gap.Before, maxPages)
+	if err != nil {
+		return res, err
+	}
+	next := mirror.MessageGap{}
+	if rest > 0 {
+		next = mirror.MessageGap{After: gap.After, Before: rest}
+		res.Truncated = true
+	}
+	// A new gap is recorded before the messages above it, and an existing
+	// one shrinks only after the messages that filled it are stored, so an
+	// interrupted run never leaves messages looking mirrored when they are not.
+	if !ok && res.Truncated {
+		if err := m.SetMessageGap(conversationID, next); err != nil {
+			return res, err
+		}
+	}
+	if res.Added, err = m.PutMessages(conversationID, msgs); err != nil {
+		return res, err
+	}
+	if ok {
+		if err := m.SetMessageGap(conversationID, next); err != nil {
+			return res, err
+		}
+	}
+	if !ok || res.Truncated {
+		return res, nil
+	}
+	// The gap is closed; fetch what arrived above it.
+	more, err := syncConversationMessages(ctx, client, m, conversationID, maxPages)
+	more.Added += res.Added
+	return more, err
+}
+
+// mergeIDs returns the IDs in a followed by those in b that are not in a.
+func mergeIDs(a, b []int) []int {
+	seen := make(map[int]bool, len(a))
+	merged := append([]int(nil), a...)
+	for _, id := range a {
+		seen[id] = true
+	}
+	for _, id := range b {
+		if !seen[id] {
+			seen[id] = true
+			merged = append(merged, id)
+		}
+	}
+	return merged
+}
+
+// syncContacts pages through contacts by descending last activity and upserts them,
+// stopping once it reaches contacts older than sinceActivity. The watermark
+// only advances when the scan got there (or to the last page) within maxPages.
+func syncContacts(ctx context.Context, client *api.Client, m *mirror.Mirror, sinceActivity int64, maxPages int, summary *syncSummary) (int64, int, error) {
+	watermark := sinceActivity
+	updated := 0
+	complete := false
+	for page := 1; maxPages <= 0 || page <= maxPages; page++ {
+		if err := ctx.Err(); err != nil {
+			return 0, 0, err
+		}
+		list, err := client.Contacts().List(ctx, api.ListContactsParams{Page: page, Sort: "-last_activity_at"})
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to sync contacts: %w", err)
+		}
+		var batch []api.Contact
+		reachedSynced := false
+		for _, c := range list.Payload {
+			activity := int64(0)
+			if c.LastActivityAt != nil {
+				activity = *c.LastActivityAt
+			}
+			if sinceActivity > 0 && activity < sinceActivity {
+				reachedSynced = true
+				continue
+			}
+			watermark = max(watermark, activity)
+			batch = append(batch, c)
+		}
+		if err := m.PutContacts(batch); err != nil {
+			return 0, 0, err
+		}
+		updated += len(batch)
+		if len(list.Payload) == 0 || reachedSynced || !contactsMetaHasMore(list.Meta) {
+			complete = true
+			break
+		}
+	}
+	if !complete {
+		summary.Truncated = true
+		return sinceActivity, updated, nil
+	}
+	return watermark, updated, nil
+}
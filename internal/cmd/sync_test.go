@@ -0,0 +1,258 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// newSyncTestHandler serves one page of conversations, messages, contacts, and labels.
+// Once offline is set, any request fails the test.
+func newSyncTestHandler(t *testing.T, offline *atomic.Bool) http.Handler {
+	t.Helper()
+	routes := newRouteHandler().
+		On("GET", "/api/v1/accounts/1/conversations", jsonResponse(200, `{
+			"data": {
+				"payload": [
+					{"id": 10, "inbox_id": 1, "status": "open", "last_activity_at": 1700000200, "labels": ["vip"],
+					 "meta": {"sender": {"id": 5, "name": "Jane Doe", "email": "jane@example.com"}}},
+					{"id": 11, "inbox_id": 2, "status": "resolved", "last_activity_at": 1700000100,
+					 "meta": {"sender": {"id": 6, "name": "Bob", "email": "bob@example.com"}}}
+				],
+				"meta": {"all_count": 2}
+			}
+		}`)).
+		On("GET", "/api/v1/accounts/1/conversations/10/messages", func(w http.ResponseWriter, r *http.Request) {
+			body := `{"payload": [
+				{"id": 100, "content": "I need a refund", "message_type": 0, "created_at": 1700000150, "sender": {"id": 5, "name": "Jane Doe"}},
+				{"id": 101, "content": "Sure, processing", "message_type": 1, "created_at": 1700000200}
+			]}`
+			if r.URL.Query().Get("before") != "" {
+				body = `{"payload": []}`
+			}
+			jsonResponse(200, body)(w, r)
+		}).
+		On("GET", "/api/v1/accounts/1/conversations/11/messages", jsonResponse(200, `{"payload": []}`)).
+		On("GET", "/api/v1/accounts/1/contacts", jsonResponse(200, `{
+			"payload": [
+				{"id": 5, "name": "Jane Doe", "email": "jane@example.com", "last_activity_at": 1700000200},
+				{"id": 6, "name": "Bob", "email": "bob@example.com", "last_activity_at": 1700000100}
+			],
+			"meta": {"count": 2, "current_page": 1}
+		}`)).
+		On("GET", "/api/v1/accounts/1/labels", jsonResponse(200, `{"payload": [{"id": 1, "title": "vip"}]}`))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if offline.Load() {
+			t.Errorf("unexpected API request in offline mode: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		routes.ServeHTTP(w, r)
+	})
+}
+
+func runSyncForTest(t *testing.T) map[string]any {
+	t.Helper()
+	output := captureStdout(t, func() {
+		if err := Execute(context.Background(), []string{"sync", "--no-progress", "-o", "json"}); err != nil {
+			t.Fatalf("sync failed: %v", err)
+		}
+	})
+	var summary map[string]any
+	if err := json.Unmarshal([]byte(output), &summary); err != nil {
+		t.Fatalf("invalid JSON: %v, output: %s", err, output)
+	}
+	return summary
+}
+
+func TestSyncCommand_ThenOfflineReads(t *testing.T) {
+	var offline atomic.Bool
+	setupTestEnvWithHandler(t, newSyncTestHandler(t, &offline))
+	t.Setenv("CHATWOOT_CACHE_DIR", t.TempDir())
+
+	summary := runSyncForTest(t)
+	if summary["conversations_updated"] != float64(2) || summary["messages_added"] != float64(2) || summary["contacts_updated"] != float64(2) || summary["labels"] != float64(1) {
+		t.Fatalf("unexpected summary: %v", summary)
+	}
+
+	// A second run sees nothing new.
+	summary = runSyncForTest(t)
+	if summary["conversations_updated"] != float64(0) || summary["messages_added"] != float64(0) {
+		t.Fatalf("expected incremental no-op, got %v", summary)
+	}
+
+	offline.Store(true)
+
+	output := captureStdout(t, func() {
+		if err := Execute(context.Background(), []string{"conversations", "list", "--offline", "--status", "open", "-o", "json"}); err != nil {
+			t.Fatalf("offline list failed: %v", err)
+		}
+	})
+	if !strings.Contains(output, `"id": 10`) || strings.Contains(output, `"id": 11`) {
+		t.Fatalf("expected only open conversation 10, got %s", output)
+	}
+
+	output = captureStdout(t, func() {
+		if err := Execute(context.Background(), []string{"messages", "list", "10", "--offline", "-o", "json"}); err != nil {
+			t.Fatalf("offline messages failed: %v", err)
+		}
+	})
+	if !strings.Contains(output, "I need a refund") || !strings.Contains(output, "Sure, processing") {
+		t.Fatalf("expected mirrored messages, got %s", output)
+	}
+
+	output = captureStdout(t, func() {
+		if err := Execute(context.Background(), []string{"ctx", "10", "--offline", "-o", "agent"}); err != nil {
+			t.Fatalf("offline ctx failed: %v", err)
+		}
+	})
+	if !strings.Contains(output, `"kind": "ctx"`) || !strings.Contains(output, "I need a refund") {
+		t.Fatalf("unexpected offline ctx output: %s", output)
+	}
+
+	output = captureStdout(t, func() {
+		if err := Execute(context.Background(), []string{"search", "refund", "--offline", "-o", "json"}); err != nil {
+			t.Fatalf("offline search failed: %v", err)
+		}
+	})
+	if !strings.Contains(output, `"id": 10`) {
+		t.Fatalf("expected conversation 10 in offline search, got %s", output)
+	}
+}
+
+func TestSyncStatusAndClear(t *testing.T) {
+	var offline atomic.Bool
+	setupTestEnvWithHandler(t, newSyncTestHandler(t, &offline))
+	t.Setenv("CHATWOOT_CACHE_DIR", t.TempDir())
+
+	runSyncForTest(t)
+
+	output := captureStdout(t, func() {
+		if err := Execute(context.Background(), []string{"sync", "status", "-o", "json"}); err != nil {
+			t.Fatalf("sync status failed: %v", err)
+		}
+	})
+	if !strings.Contains(output, `"synced": true`) || !strings.Contains(output, `"messages": 2`) {
+		t.Fatalf("unexpected status output: %s", output)
+	}
+
+	_ = captureStdout(t, func() {
+		if err := Execute(context.Background(), []string{"sync", "clear"}); err != nil {
+			t.Fatalf("sync clear failed: %v", err)
+		}
+	})
+
+	err := Execute(context.Background(), []string{"conversations", "list", "--offline"})
+	if err == nil || !strings.Contains(err.Error(), "cw sync") {
+		t.Fatalf("expected not-synced error after clear, got %v", err)
+	}
+}
+
+func TestSyncMaxPagesKeepsWatermark(t *testing.T) {
+	pages := map[string]string{
+		"1": `{"data": {"payload": [{"id": 12, "inbox_id": 1, "status": "open", "last_activity_at": 1700000400}], "meta": {"all_count": 2, "total_pages": 2}}}`,
+		"2": `{"data": {"payload": [{"id": 13, "inbox_id": 1, "status": "open", "last_activity_at": 1700000300}], "meta": {"all_count": 2, "total_pages": 2}}}`,
+	}
+	setupTestEnvWithHandler(t, newRouteHandler().
+		On("GET", "/api/v1/accounts/1/conversations", func(w http.ResponseWriter, r *http.Request) {
+			jsonResponse(200, pages[r.URL.Query().Get("page")])(w, r)
+		}).
+		On("GET", "/api/v1/accounts/1/conversations/12/messages", jsonResponse(200, `{"payload": []}`)).
+		On("GET", "/api/v1/accounts/1/conversations/13/messages", jsonResponse(200, `{"payload": []}`)))
+	t.Setenv("CHATWOOT_CACHE_DIR", t.TempDir())
+
+	syncStatus := func() map[string]any {
+		output := captureStdout(t, func() {
+			if err := Execute(context.Background(), []string{"sync", "status", "-o", "json"}); err != nil {
+				t.Fatalf("sync status failed: %v", err)
+			}
+		})
+		var status map[string]any
+		if err := json.Unmarshal([]byte(output), &status); err != nil {
+			t.Fatalf("invalid JSON: %v, output: %s", err, output)
+		}
+		return status
+	}
+	runSync := func(args ...string) map[string]any {
+		output := captureStdout(t, func() {
+			args = append([]string{"sync", "--no-progress", "--no-contacts", "--no-labels", "-o", "json"}, args...)
+			if err := Execute(context.Background(), args); err != nil {
+				t.Fatalf("sync failed: %v", err)
+			}
+		})
+		var summary map[string]any
+		if err := json.Unmarshal([]byte(output), &summary); err != nil {
+			t.Fatalf("invalid JSON: %v, output: %s", err, output)
+		}
+		return summary
+	}
+
+	if summary := runSync("--max-pages", "1"); summary["truncated"] != true || summary["pages"] != float64(1) {
+		t.Fatalf("expected a truncated one-page sync, got %v", summary)
+	}
+	if status := syncStatus(); status["last_activity_at"] != nil {
+		t.Fatalf("watermark advanced past unscanned pages: %v", status)
+	}
+
+	if summary := runSync(); summary["truncated"] != false || summary["pages"] != float64(2) {
+		t.Fatalf("expected a complete sync, got %v", summary)
+	}
+	if status := syncStatus(); status["last_activity_at"] != float64(1700000400) {
+		t.Fatalf("expected watermark 1700000400, got %v", status)
+	}
+}
+
+func TestSyncMaxMessagePagesResumesGap(t *testing.T) {
+	pages := map[string]string{
+		"":   `{"payload": [{"id": 30, "content": "newest"}, {"id": 31}]}`,
+		"30": `{"payload": [{"id": 20, "content": "oldest"}, {"id": 21}]}`,
+		"20": `{"payload": []}`,
+	}
+	var requests []string
+	setupTestEnvWithHandler(t, newRouteHandler().
+		On("GET", "/api/v1/accounts/1/conversations", jsonResponse(200, `{"data": {"payload": [{"id": 20, "inbox_id": 1, "status": "open", "last_activity_at": 1700000500}], "meta": {"all_count": 1, "total_pages": 1}}}`)).
+		On("GET", "/api/v1/accounts/1/conversations/20/messages", func(w http.ResponseWriter, r *http.Request) {
+			before := r.URL.Query().Get("before")
+			requests = append(requests, before)
+			jsonResponse(200, pages[before])(w, r)
+		}))
+	t.Setenv("CHATWOOT_CACHE_DIR", t.TempDir())
+
+	runSync := func() map[string]any {
+		output := captureStdout(t, func() {
+			if err := Execute(context.Background(), []string{"sync", "--no-progress", "--no-contacts", "--no-labels", "--max-message-pages", "1", "-o", "json"}); err != nil {
+				t.Fatalf("sync failed: %v", err)
+			}
+		})
+		var summary map[string]any
+		if err := json.Unmarshal([]byte(output), &summary); err != nil {
+			t.Fatalf("invalid JSON: %v, output: %s", err, output)
+		}
+		return summary
+	}
+
+	if summary := runSync(); summary["messages_added"] != float64(2) || summary["messages_truncated"] != float64(1) {
+		t.Fatalf("expected the newest page and a gap, got %v", summary)
+	}
+	// The conversation has not changed, but its gap is resumed below the
+	// newest page.
+	requests = nil
+	if summary := runSync(); summary["messages_added"] != float64(2) || summary["messages_truncated"] != float64(1) {
+		t.Fatalf("expected the next page of the gap, got %v", summary)
+	}
+	if strings.Join(requests, ",") != "30" {
+		t.Fatalf("unexpected message page requests %q", requests)
+	}
+	// The gap closes, and nothing newer is found above it.
+	requests = nil
+	if summary := runSync(); summary["messages_added"] != float64(0) || summary["messages_truncated"] != float64(0) {
+		t.Fatalf("expected the gap to close, got %v", summary)
+	}
+	if strings.Join(requests, ",") != "20," {
+		t.Fatalf("unexpected message page requests %q", requests)
+	}
+}
@@ -0,0 +1,319 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/chatwoot/chatwoot-cli/internal/actioncable"
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+	"github.com/chatwoot/chatwoot-cli/internal/iocontext"
+	"github.com/chatwoot/chatwoot-cli/internal/tui"
+)
+
+// tuiMessageLimit caps how many recent messages the message pane loads.
+const tuiMessageLimit = 100
+
+func newTUICmd() *cobra.Command {
+	var (
+		status       string
+		inbox        string
+		assigneeType string
+		teamID       int
+	)
+
+	cmd := &cobra.Command{
+		Use:     "tui",
+		Aliases: []string{"ui"},
+		Short:   "Interactive full-screen inbox",
+		Long: strings.TrimSpace(`
+Work the inbox from the terminal: a conversation list on the left, the
+selected conversation's messages on the right, and a compose box for replies
+and private notes. New messages and conversation changes arrive live over the
+same WebSocket stream as 'cw conversations follow'.
+
+Keys (press ? inside the TUI for the full list):
+
+  j/k      move between conversations    r, Enter  reply
+  p        private note                   a         assign to an agent
+  l        add labels                     s         snooze (e.g. 2h)
+  x        resolve / reopen               f         cycle status filter
+  R        refresh                        q         quit
+
+In the compose box, type /short_code and press Tab to insert a canned
+response; a unique prefix is enough.
+`),
+		Example: strings.TrimSpace(`
+  # Open conversations
+  cw tui
+
+  # My pending conversations in the Support inbox
+  cw tui --status pending --assignee-type me --inbox-id Support
+`),
+		Args: cobra.NoArgs,
+		RunE: RunE(func(cmd *cobra.Command, args []string) error {
+			validStatus, err := validateStatusWithAll(status)
+			if err != nil {
+				return err
+			}
+			validAssigneeType, err := validateAssigneeType(assigneeType)
+			if err != nil {
+				return err
+			}
+
+			ioStreams := iocontext.GetIO(cmd.Context())
+			in, inOK := ioStreams.In.(*os.File)
+			out, outOK := ioStreams.Out.(*os.File)
+			if !inOK || !outOK || !term.IsTerminal(int(in.Fd())) || !term.IsTerminal(int(out.Fd())) {
+				return fmt.Errorf("cw tui needs an interactive terminal; use 'cw conversations watch' or 'cw conversations follow' in scripts")
+			}
+
+			client, err := getClient()
+			if err != nil {
+				return err
+			}
+			ctx, stop := signal.NotifyContext(cmdContext(cmd), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			params := api.ListConversationsParams{AssigneeType: validAssigneeType, Page: 1}
+			if inbox != "" {
+				inboxID, err := resolveInboxID(ctx, client, inbox)
+				if err != nil {
+					return err
+				}
+				params.InboxID = strconv.Itoa(inboxID)
+			}
+			if teamID > 0 {
+				params.TeamID = strconv.Itoa(teamID)
+			}
+
+			state, err := term.MakeRaw(int(in.Fd()))
+			if err != nil {
+				return fmt.Errorf("switch terminal to raw mode: %w", err)
+			}
+			defer func() { _ = term.Restore(int(in.Fd()), state) }()
+
+			return tui.Run(ctx, &tuiBackend{client: client, params: params}, tui.Options{
+				In:  in,
+				Out: out,
+				Size: func() (int, int) {
+					w, h, err := term.GetSize(int(out.Fd()))
+					if err != nil {
+						return 80, 24
+					}
+					return w, h
+				},
+				Status: validStatus,
+				Color:  colorEnabled(),
+			})
+		}),
+	}
+
+	cmd.Flags().StringVarP(&status, "status", "s", "open", "Initial status filter (open|resolved|pending|snoozed|all)")
+	cmd.Flags().StringVarP(&inbox, "inbox-id", "I", "", "Only show conversations in this inbox (ID or name)")
+	cmd.Flags().StringVar(&assigneeType, "assignee-type", "", "Filter by assignee type (me|assigned|unassigned)")
+	cmd.Flags().IntVar(&teamID, "team-id", 0, "Filter by team ID")
+	flagAlias(cmd.Flags(), "status", "st")
+	flagAlias(cmd.Flags(), "inbox-id", "iid")
+	flagAlias(cmd.Flags(), "assignee-type", "at")
+	flagAlias(cmd.Flags(), "team-id", "tid")
+	registerStaticCompletions(cmd, "status", []string{"open", "resolved", "pending", "snoozed", "all"})
+	registerStaticCompletions(cmd, "assignee-type", []string{"me", "assigned", "unassigned"})
+
+	return cmd
+}
+
+// tuiBackend implements tui.Backend with the API client.
+type tuiBackend struct {
+	client *api.Client
+	params api.ListConversationsParams
+}
+
+func (b *tuiBackend) Conversations(ctx context.Context, status string) ([]api.Conversation, error) {
+	params := b.params
+	params.Status = status
+	result, err := b.client.Conversations().List(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	items := result.Data.Payload
+	sort.SliceStable(items, func(i, j int) bool { return items[i].LastActivityAt > items[j].LastActivityAt })
+	return items, nil
+}
+
+func (b *tuiBackend) Messages(ctx context.Context, conversationID int) ([]api.Message, error) {
+	return b.client.Messages().ListWithLimit(ctx, conversationID, tuiMessageLimit, 0)
+}
+
+func (b *tuiBackend) CannedResponses(ctx context.Context) ([]api.CannedResponse, error) {
+	return b.client.CannedResponses().List(ctx)
+}
+
+func (b *tuiBackend) Send(ctx context.Context, conversationID int, content string, private bool) (*api.Message, error) {
+	// Canned responses are inserted as templates; fill them in here so
+	// placeholders are never sent.
+	content, err := renderTemplate(ctx, b.client, content, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	return b.client.Messages().Create(ctx, conversationID, content, private, "outgoing")
+}
+
+func (b *tuiBackend) Assign(ctx context.Context, conversationID int, agent string) (int, error) {
+	agentID, err := resolveAgentID(ctx, b.client, agent)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := b.client.Conversations().Assign(ctx, conversationID, agentID, 0); err != nil {
+		return 0, err
+	}
+	return agentID, nil
+}
+
+func (b *tuiBackend) AddLabels(ctx context.Context, conversationID int, labels []string) ([]string, error) {
+	// The labels endpoint replaces the set, so merge with what is there.
+	existing, err := b.client.Conversations().Labels(ctx, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	return b.client.Conversations().AddLabels(ctx, conversationID, dedupeStrings(append(existing, labels...)))
+}
+
+func (b *tuiBackend) Snooze(ctx context.Context, conversationID int, duration string) (time.Time, error) {
+	until, err := parseSnoozeFor(duration, time.Now())
+	if err != nil {
+		return time.Time{}, err
+	}
+	if _, err := b.client.Conversations().ToggleStatus(ctx, conversationID, "snoozed", until.Unix()); err != nil {
+		return time.Time{}, err
+	}
+	return until, nil
+}
+
+func (b *tuiBackend) SetStatus(ctx context.Context, conversationID int, status string) error {
+	_, err := b.client.Conversations().ToggleStatus(ctx, conversationID, status, 0)
+	return err
+}
+
+// Live streams RoomChannel events, reconnecting with backoff like
+// 'conversations follow'.
+func (b *tuiBackend) Live(ctx context.Context) <-chan tui.LiveEvent {
+	ch := make(chan tui.LiveEvent, 64)
+	send := func(ev tui.LiveEvent) bool {
+		select {
+		case ch <- ev:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	go func() {
+		defer close(ch)
+		profile, err := b.client.Profile().Get(ctx)
+		if err != nil || profile.PubsubToken == "" {
+			send(tui.LiveEvent{Status: "offline"})
+			return
+		}
+		cableURL := buildCableURL(b.client.BaseURL)
+		channelID := actioncable.ChannelID{
+			Channel:     "RoomChannel",
+			PubsubToken: profile.PubsubToken,
+			AccountID:   b.client.AccountID,
+			UserID:      profile.ID,
+		}
+
+		backoff := 2 * time.Second
+		for {
+			connected, _ := b.stream(ctx, cableURL, channelID, send)
+			if ctx.Err() != nil {
+				return
+			}
+			if connected {
+				backoff = 2 * time.Second
+			}
+			if !send(tui.LiveEvent{Status: "reconnecting"}) {
+				return
+			}
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return
+			}
+			backoff = min(backoff*2, 30*time.Second)
+		}
+	}()
+	return ch
+}
+
+// stream runs one WebSocket connection and reports whether it got as far
+// as subscribing.
+func (b *tuiBackend) stream(ctx context.Context, cableURL string, channelID actioncable.ChannelID, send func(tui.LiveEvent) bool) (bool, error) {
+	conn, err := actioncable.Connect(ctx, cableURL)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = conn.Close() }()
+	if err := conn.Subscribe(ctx, channelID); err != nil {
+		return false, err
+	}
+	conn.StartPresence(ctx, 30*time.Second, func(error) {})
+	if !send(tui.LiveEvent{Status: "live"}) {
+		return true, ctx.Err()
+	}
+	for ev := range conn.Listen(ctx) {
+		if ev.Err != nil {
+			return true, ev.Err
+		}
+		if live, ok := tuiLiveEvent(ev.Data); ok && !send(live) {
+			return true, ctx.Err()
+		}
+	}
+	return true, ctx.Err()
+}
+
+// tuiLiveEvent turns a Chatwoot WebSocket payload into a TUI update.
+func tuiLiveEvent(data json.RawMessage) (tui.LiveEvent, bool) {
+	var env chatwootWSEvent
+	if err := json.Unmarshal(data, &env); err != nil {
+		return tui.LiveEvent{}, false
+	}
+	switch env.Event {
+	case "message.created", "message.updated":
+		var msg api.Message
+		if err := json.Unmarshal(env.Data, &msg); err != nil {
+			return tui.LiveEvent{}, false
+		}
+		if msg.ConversationID == 0 {
+			var nested struct {
+				Conversation struct {
+					ID int `json:"id"`
+				} `json:"conversation"`
+			}
+			_ = json.Unmarshal(env.Data, &nested)
+			msg.ConversationID = nested.Conversation.ID
+		}
+		if msg.ID == 0 || msg.ConversationID == 0 {
+			return tui.LiveEvent{}, false
+		}
+		return tui.LiveEvent{Message: &msg}, true
+	case "conversation.created", "conversation.updated", "conversation.status_changed",
+		"conversation.contact_changed", "assignee.changed", "team.changed":
+		var conv api.Conversation
+		if err := json.Unmarshal(env.Data, &conv); err != nil || conv.ID == 0 {
+			return tui.LiveEvent{}, false
+		}
+		return tui.LiveEvent{Conversation: &conv}, true
+	}
+	return tui.LiveEvent{}, false
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestTUIRequiresTerminal(t *testing.T) {
+	setupTestEnvWithHandler(t, newRouteHandler())
+
+	err := Execute(context.Background(), []string{"tui"})
+	if err == nil || !strings.Contains(err.Error(), "interactive terminal") {
+		t.Fatalf("expected terminal error, got %v", err)
+	}
+	err = Execute(context.Background(), []string{"tui", "--status", "bogus"})
+	if err == nil || !strings.Contains(err.Error(), "status") {
+		t.Fatalf("expected status error, got %v", err)
+	}
+}
+
+func TestTUILiveEvent(t *testing.T) {
+	ev, ok := tuiLiveEvent(json.RawMessage(`{"event":"message.created","data":{"id":5,"content":"還在嗎？","message_type":0,"conversation":{"id":12}}}`))
+	if !ok || ev.Message == nil || ev.Message.ConversationID != 12 || ev.Message.Content != "還在嗎？" {
+		t.Fatalf("message event = %+v, %v", ev, ok)
+	}
+	ev, ok = tuiLiveEvent(json.RawMessage(`{"event":"conversation.status_changed","data":{"id":12,"status":"resolved"}}`))
+	if !ok || ev.Conversation == nil || ev.Conversation.Status != "resolved" {
+		t.Fatalf("conversation event = %+v, %v", ev, ok)
+	}
+	if _, ok := tuiLiveEvent(json.RawMessage(`{"event":"presence.update","data":{}}`)); ok {
+		t.Fatal("presence events should be ignored")
+	}
+}
+
+func TestTUIBackendAddLabelsMerges(t *testing.T) {
+	var posted string
+	setupTestEnvWithHandler(t, newRouteHandler().
+		On("GET", "/api/v1/accounts/1/conversations/12/labels", jsonResponse(200, `{"payload":["billing"]}`)).
+		On("POST", "/api/v1/accounts/1/conversations/12/labels", func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			posted = string(body)
+			jsonResponse(200, `{"payload":["billing","vip"]}`)(w, r)
+		}))
+
+	client, err := getClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	labels, err := (&tuiBackend{client: client}).AddLabels(context.Background(), 12, []string{"vip", "billing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if posted != `{"labels":["billing","vip"]}` || strings.Join(labels, ",") != "billing,vip" {
+		t.Fatalf("posted %s, got %v", posted, labels)
+	}
+}
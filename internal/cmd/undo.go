@@ -0,0 +1,221 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+	"github.com/chatwoot/chatwoot-cli/internal/dryrun"
+	"github.com/chatwoot/chatwoot-cli/internal/journal"
+	"github.com/spf13/cobra"
+)
+
+// undoResult is the outcome of restoring one recorded change.
+type undoResult struct {
+	Resource  string   `json:"resource"`
+	ID        int      `json:"id"`
+	Fields    []string `json:"fields"`
+	Status    string   `json:"status"`              // "restored" | "skipped" | "failed"
+	Conflicts []string `json:"conflicts,omitempty"` // fields left alone because they changed since
+	Error     string   `json:"error,omitempty"`
+}
+
+func newUndoCmd() *cobra.Command {
+	var (
+		overwrite   bool
+		concurrency int
+		progress    bool
+		noProgress  bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "undo [operation-id]",
+		Short: "Undo a recorded operation",
+		Long: strings.TrimSpace(`
+Restore the conversations and contacts an operation changed to their state
+from just before it: previous status, priority, assignee, team, labels,
+custom attributes and contact fields. Without an ID, undo the most recent
+operation on the current account that has not been undone; running undo
+again steps further back. A unique ID prefix is enough. List operations with
+'cw history'.
+
+Fields that were changed again since the operation are left alone and
+reported as skipped; --overwrite restores them anyway. The undo is recorded as
+an operation of its own, so undoing it redoes the original change.
+`),
+		Example: strings.TrimSpace(`
+  # Undo the last change
+  cw undo
+
+  # Undo a specific operation
+  cw undo 3f9a
+
+  # See what would be restored
+  cw undo 3f9a --dry-run
+`),
+		Args: cobra.MaximumNArgs(1),
+		RunE: RunE(func(cmd *cobra.Command, args []string) error {
+			client, err := getClient()
+			if err != nil {
+				return err
+			}
+			j, err := journalStore().Load()
+			if err != nil {
+				return err
+			}
+			var op *journal.Operation
+			if len(args) == 1 {
+				op, err = j.Find(args[0])
+			} else {
+				op, err = j.Latest(client.BaseURL, client.AccountID)
+			}
+			if err != nil {
+				return err
+			}
+			if op.BaseURL != client.BaseURL || op.AccountID != client.AccountID {
+				return fmt.Errorf("operation %s was made on account %d at %s, not the current account", op.ID, op.AccountID, op.BaseURL)
+			}
+			if op.Undone() {
+				return fmt.Errorf("operation %s was already undone by %s", op.ID, op.UndoneBy)
+			}
+
+			if ok, err := maybeDryRun(cmd, &dryrun.Preview{
+				Operation:   "undo",
+				Resource:    "operation",
+				Description: fmt.Sprintf("Restore %d changes made by: %s", len(op.Changes), op.Command),
+				Details: map[string]any{
+					"id":      op.ID,
+					"changes": len(op.Changes),
+					"command": op.Command,
+				},
+			}); ok {
+				return err
+			}
+
+			if activeJournal != nil {
+				activeJournal.undoOf = op.ID
+			}
+
+			groups := groupChanges(op.Changes)
+			indexes := make([]int, len(groups))
+			for i := range groups {
+				indexes[i] = i
+			}
+			bulk := runBulkOperation(
+				cmdContext(cmd),
+				indexes,
+				int64(concurrency),
+				bulkProgressEnabled(cmd, progress, noProgress) && len(groups) > 1,
+				cmd.ErrOrStderr(),
+				func(ctx context.Context, i int) ([]undoResult, error) {
+					return undoChanges(ctx, client, groups[i], overwrite), nil
+				},
+			)
+
+			results := []undoResult{}
+			counts := map[string]int{}
+			for _, r := range bulk {
+				rs, _ := r.Data.([]undoResult)
+				for _, res := range rs {
+					counts[res.Status]++
+					results = append(results, res)
+				}
+			}
+
+			if isJSON(cmd) {
+				if err := printJSON(cmd, map[string]any{
+					"operation":      op.ID,
+					"restored_count": counts["restored"],
+					"skipped_count":  counts["skipped"],
+					"fail_count":     counts["failed"],
+					"results":        results,
+				}); err != nil {
+					return err
+				}
+				return undoFailures(counts["failed"], len(results))
+			}
+			out := cmd.OutOrStdout()
+			for _, r := range results {
+				switch {
+				case r.Status == "failed":
+					_, _ = fmt.Fprintf(out, "Failed to restore %s %d: %s\n", r.Resource, r.ID, r.Error)
+				case len(r.Conflicts) > 0:
+					_, _ = fmt.Fprintf(out, "Skipped %s %d %s: changed since (use --overwrite to restore anyway)\n", r.Resource, r.ID, strings.Join(r.Conflicts, ", "))
+				}
+			}
+			_, _ = fmt.Fprintf(out, "Undid operation %s: restored %d of %d changes (%d skipped, %d failed)\n",
+				op.ID, counts["restored"], len(op.Changes), counts["skipped"], counts["failed"])
+			return undoFailures(counts["failed"], len(results))
+		}),
+	}
+
+	cmd.Flags().BoolVar(&overwrite, "overwrite", false, "Restore fields even if they changed since the operation")
+	cmd.Flags().IntVar(&concurrency, "concurrency", DefaultConcurrency, "Max concurrent restores")
+	cmd.Flags().BoolVar(&progress, "progress", true, "Show progress while running")
+	cmd.Flags().BoolVar(&noProgress, "no-progress", false, "Disable progress output")
+	flagAlias(cmd.Flags(), "overwrite", "ow")
+	flagAlias(cmd.Flags(), "concurrency", "cc")
+	flagAlias(cmd.Flags(), "progress", "prg")
+	flagAlias(cmd.Flags(), "no-progress", "npr")
+	registerCommandContract(cmd, true, true)
+	return cmd
+}
+
+func undoFailures(failed, total int) error {
+	if failed > 0 {
+		return fmt.Errorf("%d of %d restores failed", failed, total)
+	}
+	return nil
+}
+
+// groupChanges splits changes by the resource they touch, keeping their
+// order, so each resource's changes can be undone in sequence while
+// different resources are restored concurrently.
+func groupChanges(changes []api.Change) [][]api.Change {
+	var groups [][]api.Change
+	index := map[string]int{}
+	for _, ch := range changes {
+		key := fmt.Sprintf("%s/%d", ch.Resource, ch.ID)
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], ch)
+	}
+	return groups
+}
+
+// undoChanges restores one resource's changes, newest first. Fields that no
+// longer hold the values a change set are left alone unless overwrite is
+// set.
+func undoChanges(ctx context.Context, client *api.Client, changes []api.Change, overwrite bool) []undoResult {
+	results := make([]undoResult, 0, len(changes))
+	for i := len(changes) - 1; i >= 0; i-- {
+		ch := changes[i]
+		res := undoResult{Resource: ch.Resource, ID: ch.ID, Fields: ch.Fields(), Status: "restored"}
+		if !overwrite {
+			current, err := client.State(ctx, ch.Resource, ch.ID, ch.Fields())
+			if err != nil {
+				res.Status, res.Error = "failed", err.Error()
+				results = append(results, res)
+				continue
+			}
+			if conflicts := ch.Conflicts(current); len(conflicts) > 0 {
+				res.Conflicts = conflicts
+				if ch = ch.Without(conflicts); len(ch.After) == 0 {
+					res.Status = "skipped"
+					results = append(results, res)
+					continue
+				}
+			}
+		}
+		if err := client.Restore(ctx, ch); err != nil {
+			res.Status, res.Error = "failed", err.Error()
+		}
+		results = append(results, res)
+	}
+	return results
+}
@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/chatwoot/chatwoot-cli/internal/devserver"
+	"github.com/chatwoot/chatwoot-cli/internal/journal"
+)
+
+// setupJournalTest runs commands against a dev server with the journal
+// enabled in a fresh config dir.
+func setupJournalTest(t *testing.T) func(args ...string) (string, error) {
+	t.Helper()
+	fake, err := devserver.New(devserver.DefaultFixture(), "test-token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(fake.Close)
+	setupTestEnvWithHandler(t, fake)
+	t.Setenv("CW_CONFIG_DIR", t.TempDir())
+	t.Setenv("CW_JOURNAL", "")
+
+	return func(args ...string) (string, error) {
+		var runErr error
+		out := captureStdout(t, func() {
+			runErr = Execute(context.Background(), args)
+		})
+		return out, runErr
+	}
+}
+
+func conversationLabels(t *testing.T, run func(args ...string) (string, error), id string) []string {
+	t.Helper()
+	out, err := run("conversations", "get", id, "-o", "json")
+	if err != nil {
+		t.Fatalf("get conversation %s: %v", id, err)
+	}
+	var conv struct {
+		Labels []string `json:"labels"`
+	}
+	if err := json.Unmarshal([]byte(out), &conv); err != nil {
+		t.Fatalf("decode conversation: %v\n%s", err, out)
+	}
+	slices.Sort(conv.Labels)
+	return conv.Labels
+}
+
+func TestUndoBulkLabels(t *testing.T) {
+	run := setupJournalTest(t)
+
+	before := map[string][]string{}
+	for _, id := range []string{"1", "2", "3"} {
+		before[id] = conversationLabels(t, run, id)
+	}
+	if _, err := run("conversations", "bulk", "add-label", "--ids", "1,2,3", "--labels", "oops"); err != nil {
+		t.Fatalf("bulk add-label: %v", err)
+	}
+	if got := conversationLabels(t, run, "2"); !slices.Contains(got, "oops") {
+		t.Fatalf("labels after bulk = %v", got)
+	}
+
+	out, err := run("history")
+	if err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	if !strings.Contains(out, "cw conversations bulk add-label --ids 1,2,3 --labels oops") {
+		t.Errorf("history missing the bulk command:\n%s", out)
+	}
+
+	out, err = run("undo")
+	if err != nil {
+		t.Fatalf("undo: %v", err)
+	}
+	if !strings.Contains(out, "restored 3 of 3 changes") {
+		t.Errorf("undo output:\n%s", out)
+	}
+	for id, want := range before {
+		if got := conversationLabels(t, run, id); !slices.Equal(got, want) {
+			t.Errorf("conversation %s labels = %v, want %v", id, got, want)
+		}
+	}
+
+	// The bulk operation is marked undone and the undo is an operation of
+	// its own, so a second undo has nothing left.
+	j, err := journalStore().Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(j.Operations) != 2 || !j.Operations[0].Undone() || j.Operations[1].UndoOf != j.Operations[0].ID {
+		t.Errorf("journal = %+v", j.Operations)
+	}
+	if _, err := run("undo"); err == nil || !strings.Contains(err.Error(), "no operations to undo") {
+		t.Errorf("second undo err = %v", err)
+	}
+	if _, err := run("undo", j.Operations[0].ID); err == nil || !strings.Contains(err.Error(), "already undone") {
+		t.Errorf("undo of an undone operation err = %v", err)
+	}
+}
+
+func TestUndoSkipsFieldsChangedSince(t *testing.T) {
+	run := setupJournalTest(t)
+
+	if _, err := run("close", "1"); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if _, err := run("conversations", "toggle-priority", "1", "--priority", "low"); err != nil {
+		t.Fatalf("toggle-priority: %v", err)
+	}
+	if _, err := run("reopen", "1"); err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+
+	j, err := journalStore().Load()
+	if err != nil || len(j.Operations) != 3 {
+		t.Fatalf("journal = %+v, %v", j, err)
+	}
+	closeOp := j.Operations[0].ID
+
+	out, err := run("undo", closeOp)
+	if err != nil {
+		t.Fatalf("undo: %v", err)
+	}
+	if !strings.Contains(out, "Skipped conversation 1 status") || !strings.Contains(out, "restored 0 of 1 changes (1 skipped") {
+		t.Errorf("undo output:\n%s", out)
+	}
+
+	if _, err := run("undo", closeOp, "--overwrite"); err != nil {
+		t.Fatalf("undo --overwrite: %v", err)
+	}
+	out, err = run("conversations", "get", "1", "-o", "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, `"status": "open"`) || !strings.Contains(out, `"priority": "low"`) {
+		t.Errorf("conversation after undo:\n%s", out)
+	}
+}
+
+func TestUndoDryRunChangesNothing(t *testing.T) {
+	run := setupJournalTest(t)
+
+	if _, err := run("assign", "1", "--agent", "2"); err != nil {
+		t.Fatalf("assign: %v", err)
+	}
+	out, err := run("undo", "--dry-run")
+	if err != nil {
+		t.Fatalf("undo --dry-run: %v", err)
+	}
+	if !strings.Contains(out, "cw assign 1 --agent 2") {
+		t.Errorf("dry-run output:\n%s", out)
+	}
+	j, _ := journalStore().Load()
+	if len(j.Operations) != 1 || j.Operations[0].Undone() {
+		t.Errorf("dry run changed the journal: %+v", j.Operations)
+	}
+}
+
+func TestUndoFailedRestoreReturnsError(t *testing.T) {
+	run := setupJournalTest(t)
+
+	if _, err := run("close", "1"); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	// Point the recorded change at a conversation that does not exist.
+	err := journalStore().Update(func(j *journal.Journal) error {
+		j.Operations[0].Changes[0].ID = 99999
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := run("undo", "--overwrite", "-o", "json")
+	if err == nil || !strings.Contains(err.Error(), "1 of 1 restores failed") {
+		t.Fatalf("undo err = %v", err)
+	}
+	if !strings.Contains(out, `"fail_count": 1`) {
+		t.Errorf("undo output:\n%s", out)
+	}
+}
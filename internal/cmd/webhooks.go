@@ -22,6 +22,8 @@ func newWebhooksCmd() *cobra.Command {
 	cmd.AddCommand(newWebhooksCreateCmd())
 	cmd.AddCommand(newWebhooksUpdateCmd())
 	cmd.AddCommand(newWebhooksDeleteCmd())
+	cmd.AddCommand(newWebhooksListenCmd())
+	cmd.AddCommand(newWebhooksReplayCmd())
 
 	return cmd
 }
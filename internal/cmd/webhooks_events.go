@@ -0,0 +1,493 @@
+package cmd
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+	"github.com/spf13/cobra"
+)
+
+const (
+	webhookSignatureHeader = "X-Chatwoot-Signature"
+	webhookTimestampHeader = "X-Chatwoot-Timestamp"
+	webhookDeliveryHeader  = "X-Chatwoot-Delivery"
+)
+
+var (
+	errWebhookSignatureMissing = errors.New("missing " + webhookSignatureHeader + " header")
+	errWebhookTimestampMissing = errors.New("missing " + webhookTimestampHeader + " header")
+	errWebhookSignatureInvalid = errors.New("webhook signature mismatch")
+)
+
+// webhookEventNames maps Chatwoot webhook event names (snake_case) to the
+// dotted event names used by the ActionCable stream and `conversations follow`.
+var webhookEventNames = map[string]string{
+	"conversation_created":        "conversation.created",
+	"conversation_updated":        "conversation.updated",
+	"conversation_status_changed": "conversation.status_changed",
+	"conversation_typing_on":      "conversation.typing_on",
+	"conversation_typing_off":     "conversation.typing_off",
+	"message_created":             "message.created",
+	"message_updated":             "message.updated",
+	"webwidget_triggered":         "webwidget.triggered",
+	"contact_created":             "contact.created",
+	"contact_updated":             "contact.updated",
+}
+
+// normalizeWebhookEventName converts a webhook event name to its follow-stream equivalent.
+// Unknown names are passed through with underscores replaced by the first dot.
+func normalizeWebhookEventName(name string) string {
+	name = strings.TrimSpace(name)
+	if mapped, ok := webhookEventNames[name]; ok {
+		return mapped
+	}
+	if strings.Contains(name, ".") {
+		return name
+	}
+	if i := strings.Index(name, "_"); i > 0 {
+		return name[:i] + "." + name[i+1:]
+	}
+	return name
+}
+
+// signWebhookPayload computes the Chatwoot webhook signature for body.
+// Chatwoot signs "<timestamp>.<body>" with HMAC-SHA256 and sends it as "sha256=<hex>".
+func signWebhookPayload(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	if timestamp != "" {
+		_, _ = mac.Write([]byte(timestamp))
+		_, _ = mac.Write([]byte("."))
+	}
+	_, _ = mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// verifyWebhookSignature checks the HMAC signature headers of a webhook request.
+// The timestamp is required, since a signature over the body alone could be
+// replayed at any time. maxSkew bounds how far it may drift from now (0
+// disables the check).
+func verifyWebhookSignature(secret string, header http.Header, body []byte, now time.Time, maxSkew time.Duration) error {
+	sig := strings.TrimSpace(header.Get(webhookSignatureHeader))
+	if sig == "" {
+		return errWebhookSignatureMissing
+	}
+	timestamp := strings.TrimSpace(header.Get(webhookTimestampHeader))
+	if timestamp == "" {
+		return errWebhookTimestampMissing
+	}
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid %s header %q", webhookTimestampHeader, timestamp)
+	}
+	if maxSkew > 0 {
+		skew := now.Sub(time.Unix(unix, 0))
+		if skew < 0 {
+			skew = -skew
+		}
+		if skew > maxSkew {
+			return fmt.Errorf("webhook timestamp outside allowed skew (%s > %s)", skew.Round(time.Second), maxSkew)
+		}
+	}
+	expected := signWebhookPayload(secret, timestamp, body)
+	if !strings.HasPrefix(sig, "sha256=") {
+		sig = "sha256=" + sig
+	}
+	if !hmac.Equal([]byte(strings.ToLower(sig)), []byte(expected)) {
+		return errWebhookSignatureInvalid
+	}
+	return nil
+}
+
+// webhookTimestamp converts webhook timestamps (ISO-8601 strings or unix numbers) to unix seconds.
+func webhookTimestamp(v any) int64 {
+	switch t := v.(type) {
+	case float64:
+		return int64(t)
+	case int64:
+		return t
+	case int:
+		return int64(t)
+	case string:
+		t = strings.TrimSpace(t)
+		if t == "" {
+			return 0
+		}
+		if i, err := strconv.ParseInt(t, 10, 64); err == nil {
+			return i
+		}
+		for _, layout := range []string{time.RFC3339Nano, time.RFC3339, "2006-01-02 15:04:05 MST", "2006-01-02 15:04:05 -0700"} {
+			if parsed, err := time.Parse(layout, t); err == nil {
+				return parsed.Unix()
+			}
+		}
+	}
+	return 0
+}
+
+func webhookMessageType(v any) int {
+	if s, ok := v.(string); ok {
+		switch strings.ToLower(strings.TrimSpace(s)) {
+		case "incoming":
+			return api.MessageTypeIncoming
+		case "outgoing":
+			return api.MessageTypeOutgoing
+		case "activity":
+			return api.MessageTypeActivity
+		case "template":
+			return api.MessageTypeTemplate
+		}
+	}
+	return anyToInt(v)
+}
+
+// webhookMessageFromPayload builds an api.Message from a message_* webhook payload.
+// Webhook payloads use string message types and ISO timestamps, so they can't be
+// unmarshalled into api.Message directly.
+func webhookMessageFromPayload(p map[string]any) api.Message {
+	msg := api.Message{
+		ID:             anyToInt(p["id"]),
+		ConversationID: anyToInt(p["conversation_id"]),
+		MessageType:    webhookMessageType(p["message_type"]),
+		CreatedAt:      webhookTimestamp(p["created_at"]),
+	}
+	if s, ok := p["content"].(string); ok {
+		msg.Content = s
+	}
+	if s, ok := p["content_type"].(string); ok {
+		msg.ContentType = s
+	}
+	if b, ok := p["private"].(bool); ok {
+		msg.Private = b
+	}
+	if conv, ok := p["conversation"].(map[string]any); ok && msg.ConversationID == 0 {
+		msg.ConversationID = anyToInt(conv["display_id"])
+		if msg.ConversationID == 0 {
+			msg.ConversationID = anyToInt(conv["id"])
+		}
+	}
+	if sender, ok := p["sender"].(map[string]any); ok {
+		s := &api.MessageSender{ID: anyToInt(sender["id"])}
+		if name, ok := sender["name"].(string); ok {
+			s.Name = name
+		}
+		if typ, ok := sender["type"].(string); ok {
+			s.Type = typ
+			msg.SenderType = typ
+		}
+		if s.ID > 0 {
+			id := s.ID
+			msg.SenderID = &id
+		}
+		msg.Sender = s
+	}
+	if atts, ok := p["attachments"].([]any); ok {
+		for _, a := range atts {
+			m, ok := a.(map[string]any)
+			if !ok {
+				continue
+			}
+			att := api.Attachment{ID: anyToInt(m["id"]), FileSize: anyToInt(m["file_size"])}
+			att.FileType, _ = m["file_type"].(string)
+			att.DataURL, _ = m["data_url"].(string)
+			att.ThumbURL, _ = m["thumb_url"].(string)
+			msg.Attachments = append(msg.Attachments, att)
+		}
+	}
+	return msg
+}
+
+// webhookEvent is a decoded webhook delivery normalized to follow-stream naming.
+type webhookEvent struct {
+	Event   string
+	Payload map[string]any
+	Raw     json.RawMessage
+}
+
+func decodeWebhookEvent(body []byte) (webhookEvent, error) {
+	var payload map[string]any
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return webhookEvent{}, fmt.Errorf("invalid webhook JSON: %w", err)
+	}
+	name, _ := payload["event"].(string)
+	if strings.TrimSpace(name) == "" {
+		return webhookEvent{}, fmt.Errorf("webhook payload has no event field")
+	}
+	return webhookEvent{
+		Event:   normalizeWebhookEventName(name),
+		Payload: payload,
+		Raw:     json.RawMessage(body),
+	}, nil
+}
+
+// webhookPipeline filters webhook events and emits them in `conversations follow` format.
+// It is safe for concurrent use; emission is serialized so stdout stays line-oriented.
+type webhookPipeline struct {
+	cmd           *cobra.Command
+	hook          *followExecHook
+	allowedEvents map[string]struct{}
+	incomingOnly  bool
+	filters       followFilters
+	includeRaw    bool
+	source        string
+	cursor        *followCursorWriter
+	// resumeAfter is the cursor file's last seen message ID; deliveries at or
+	// below it were handled by a previous run, except those in unhandled.
+	resumeAfter int
+	unhandled   map[int]bool
+
+	mu   sync.Mutex
+	seen recentSet[int]
+}
+
+// webhookRecentIDLimit bounds how many message IDs a pipeline, or deliveries
+// a store, remembers for dedupe; Chatwoot's retries arrive well within that
+// window.
+const webhookRecentIDLimit = 10000
+
+// recentSet is a bounded set of recently seen IDs. Once full, adding an ID
+// evicts the oldest one. The zero value holds up to webhookRecentIDLimit IDs.
+type recentSet[K comparable] struct {
+	limit int
+	order []K
+	next  int
+	set   map[K]struct{}
+}
+
+func (r *recentSet[K]) Has(id K) bool {
+	_, ok := r.set[id]
+	return ok
+}
+
+func (r *recentSet[K]) Add(id K) {
+	if r.Has(id) {
+		return
+	}
+	if r.set == nil {
+		r.set = make(map[K]struct{})
+	}
+	limit := r.limit
+	if limit <= 0 {
+		limit = webhookRecentIDLimit
+	}
+	if len(r.order) < limit {
+		r.order = append(r.order, id)
+	} else {
+		delete(r.set, r.order[r.next])
+		r.order[r.next] = id
+		r.next = (r.next + 1) % limit
+	}
+	r.set[id] = struct{}{}
+}
+
+// Process emits one webhook delivery. It returns (false, nil) when the event was filtered out.
+func (p *webhookPipeline) Process(ev webhookEvent) (bool, error) {
+	if p.allowedEvents != nil {
+		if _, ok := p.allowedEvents[ev.Event]; !ok {
+			return false, nil
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if ev.Event == "message.created" || ev.Event == "message.updated" {
+		msg := webhookMessageFromPayload(ev.Payload)
+		dedupe := ev.Event == "message.created" && msg.ID > 0
+		if dedupe && (p.seen.Has(msg.ID) || msg.ID <= p.resumeAfter && !p.unhandled[msg.ID]) {
+			return false, nil
+		}
+		filtered := (p.incomingOnly && msg.MessageType != api.MessageTypeIncoming) ||
+			(p.filters.ExcludePrivate && msg.Private) ||
+			(p.filters.InboxID > 0 && webhookInboxID(ev.Payload) != p.filters.InboxID)
+		if !filtered {
+			// A failed handler leaves the message unseen, so Chatwoot's retry
+			// of the delivery is processed again, and records it in the
+			// cursor so a restart does too once later deliveries move the
+			// cursor past it.
+			if err := printFollowMessageWithRaw(p.cmd, p.hook, ev.Event, msg, p.source, ev.Raw, p.includeRaw); err != nil {
+				if dedupe && p.cursor != nil {
+					p.cursor.SetUnhandled(msg.ID, true)
+				}
+				return true, err
+			}
+		}
+		if dedupe {
+			p.seen.Add(msg.ID)
+			delete(p.unhandled, msg.ID)
+			if p.cursor != nil {
+				p.cursor.Update(msg.ID)
+				p.cursor.SetUnhandled(msg.ID, false)
+			}
+		}
+		return !filtered, nil
+	}
+
+	if p.filters.InboxID > 0 && webhookInboxID(ev.Payload) != p.filters.InboxID {
+		return false, nil
+	}
+	data := make(map[string]any, len(ev.Payload))
+	for k, v := range ev.Payload {
+		if k == "event" {
+			continue
+		}
+		data[k] = v
+	}
+	b, err := json.Marshal(data)
+	if err != nil {
+		return false, err
+	}
+	wsEvent := chatwootWSEvent{Event: ev.Event, Data: b}
+	return true, printFollowEvent(p.cmd, p.hook, wsEvent, p.source, ev.Raw, p.includeRaw)
+}
+
+func webhookInboxID(p map[string]any) int {
+	if inbox, ok := p["inbox"].(map[string]any); ok {
+		if id := anyToInt(inbox["id"]); id > 0 {
+			return id
+		}
+	}
+	if id := anyToInt(p["inbox_id"]); id > 0 {
+		return id
+	}
+	if conv, ok := p["conversation"].(map[string]any); ok {
+		return anyToInt(conv["inbox_id"])
+	}
+	return 0
+}
+
+// webhookRecord is one stored webhook delivery (one JSON object per line).
+type webhookRecord struct {
+	ReceivedAt string          `json:"received_at"`
+	Event      string          `json:"event"`
+	DeliveryID string          `json:"delivery_id,omitempty"`
+	Verified   bool            `json:"verified"`
+	Body       json.RawMessage `json:"body"`
+}
+
+// webhookStore appends received deliveries to daily JSONL files.
+type webhookStore struct {
+	dir    string
+	mu     sync.Mutex
+	stored recentSet[string]
+}
+
+func defaultWebhookStoreDir() string {
+	dir := resolveCacheDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "webhooks")
+}
+
+func newWebhookStore(dir string) (*webhookStore, error) {
+	dir = strings.TrimSpace(dir)
+	if dir == "" {
+		return nil, fmt.Errorf("could not determine webhook store directory (use --store-dir)")
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("create webhook store dir: %w", err)
+	}
+	return &webhookStore{dir: dir}, nil
+}
+
+// Append stores rec unless the same delivery was stored recently: Chatwoot
+// retries a delivery with the same delivery ID or, without one, the same body.
+func (s *webhookStore) Append(rec webhookRecord, at time.Time) error {
+	if s == nil {
+		return nil
+	}
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	key := rec.DeliveryID
+	if key == "" {
+		sum := sha256.Sum256(rec.Body)
+		key = "sha256:" + hex.EncodeToString(sum[:])
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stored.Has(key) {
+		return nil
+	}
+	path := filepath.Join(s.dir, "events-"+at.UTC().Format("2006-01-02")+".jsonl")
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("open webhook store: %w", err)
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("write webhook store: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	s.stored.Add(key)
+	return nil
+}
+
+// loadWebhookRecords reads stored deliveries oldest first, skipping records before since.
+func loadWebhookRecords(dir string, since time.Time) ([]webhookRecord, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read webhook store: %w", err)
+	}
+	var names []string
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, "events-") || filepath.Ext(name) != ".jsonl" {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var records []webhookRecord
+	for _, name := range names {
+		f, err := os.Open(filepath.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("open %s: %w", name, err)
+		}
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+			var rec webhookRecord
+			if err := json.Unmarshal([]byte(line), &rec); err != nil {
+				continue // skip torn writes
+			}
+			if !since.IsZero() {
+				if t, err := time.Parse(time.RFC3339Nano, rec.ReceivedAt); err == nil && t.Before(since) {
+					continue
+				}
+			}
+			records = append(records, rec)
+		}
+		err = scanner.Err()
+		_ = f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", name, err)
+		}
+	}
+	return records, nil
+}
@@ -0,0 +1,506 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/chatwoot/chatwoot-cli/internal/config"
+)
+
+const maxWebhookBodyBytes = 5 << 20
+
+// webhookEventFlagSet builds the allowed event set for --events ("all" or "*" disables filtering).
+func webhookEventFlagSet(events []string) map[string]struct{} {
+	allowed := make(map[string]struct{}, len(events))
+	for _, e := range dedupeStrings(events) {
+		e = strings.TrimSpace(e)
+		if e == "" {
+			continue
+		}
+		if e == "all" || e == "*" {
+			return nil
+		}
+		allowed[normalizeWebhookEventName(e)] = struct{}{}
+	}
+	return allowed
+}
+
+// webhookCursorTarget returns the base URL and account ID recorded in cursor files.
+// It is best-effort: webhook listening does not require stored credentials.
+func webhookCursorTarget() (string, int) {
+	account, err := config.LoadAccount()
+	if err != nil {
+		return "", 0
+	}
+	return account.BaseURL, account.AccountID
+}
+
+// newWebhookCursor loads --cursor-file (if set) and returns a writer plus the
+// cursor to resume from, which is empty if it belongs to another account.
+func newWebhookCursor(cursorFile string) (*followCursorWriter, followCursor, error) {
+	if strings.TrimSpace(cursorFile) == "" {
+		return nil, followCursor{}, nil
+	}
+	baseURL, accountID := webhookCursorTarget()
+	cur, err := loadFollowCursor(cursorFile)
+	if err != nil {
+		return nil, followCursor{}, err
+	}
+	if cur.LastSeenMessageID <= 0 || (cur.AccountID != 0 && accountID != 0 && cur.AccountID != accountID) || (cur.BaseURL != "" && baseURL != "" && cur.BaseURL != baseURL) {
+		cur = followCursor{}
+	}
+	w, err := newFollowCursorWriter(cursorFile, baseURL, accountID, cur.LastSeenMessageID, 1*time.Second)
+	if err != nil {
+		return nil, followCursor{}, err
+	}
+	for _, id := range cur.Unhandled {
+		w.Unhandled[id] = true
+	}
+	return w, cur, nil
+}
+
+func idSet(ids []int) map[int]bool {
+	set := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
+
+// webhookHandler is the HTTP handler behind `webhooks listen`.
+type webhookHandler struct {
+	secret   string
+	maxSkew  time.Duration
+	pipeline *webhookPipeline
+	store    *webhookStore
+	errOut   io.Writer
+	now      func() time.Time
+	// fatal receives the first fatal pipeline error (e.g. --exec-fatal), stopping the server.
+	fatal chan error
+}
+
+func (h *webhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBodyBytes+1))
+	if err != nil {
+		http.Error(w, "read body", http.StatusBadRequest)
+		return
+	}
+	if len(body) > maxWebhookBodyBytes {
+		http.Error(w, "payload too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	now := h.now()
+	verified := false
+	if h.secret != "" {
+		if err := verifyWebhookSignature(h.secret, r.Header, body, now, h.maxSkew); err != nil {
+			_, _ = fmt.Fprintf(h.errOut, "rejected webhook from %s: %v\n", r.RemoteAddr, err)
+			http.Error(w, "invalid signature", http.StatusUnauthorized)
+			return
+		}
+		verified = true
+	}
+
+	ev, err := decodeWebhookEvent(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if h.store != nil {
+		rec := webhookRecord{
+			ReceivedAt: now.UTC().Format(time.RFC3339Nano),
+			Event:      ev.Event,
+			DeliveryID: r.Header.Get(webhookDeliveryHeader),
+			Verified:   verified,
+			Body:       ev.Raw,
+		}
+		if err := h.store.Append(rec, now); err != nil {
+			_, _ = fmt.Fprintf(h.errOut, "webhook store error: %v\n", err)
+		}
+	}
+
+	// Acknowledge before running handlers would lose --exec-fatal semantics, so
+	// process inline; Chatwoot retries on non-2xx responses.
+	if _, err := h.pipeline.Process(ev); err != nil {
+		http.Error(w, "handler failed", http.StatusInternalServerError)
+		select {
+		case h.fatal <- err:
+		default:
+		}
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func newWebhooksListenCmd() *cobra.Command {
+	var (
+		addr           string
+		path           string
+		secret         string
+		maxSkew        time.Duration
+		events         []string
+		incomingOnly   bool
+		excludePrivate bool
+		filterInbox    int
+		includeRaw     bool
+		cursorFile     string
+		storeDir       string
+		noStore        bool
+		execHandler    string
+		execTimeout    time.Duration
+		execFatal      bool
+	)
+
+	cmd := &cobra.Command{
+		Use:     "listen",
+		Aliases: []string{"lis"},
+		Short:   "Receive webhooks locally and stream them as follow events",
+		Long: strings.TrimSpace(`
+Run a local HTTP server that receives Chatwoot webhook deliveries.
+
+Each delivery is verified against the webhook secret (HMAC-SHA256 over the
+X-Chatwoot-Timestamp and body, sent in X-Chatwoot-Signature), normalized to the
+same event stream emitted by "conversations follow", and appended to a local
+store so it can be re-delivered later with "webhooks replay". Signed deliveries
+without a timestamp are rejected, and retried deliveries are stored once.
+
+The secret is read from --secret or CW_WEBHOOK_SECRET. Without a secret,
+deliveries are accepted unverified.
+`),
+		Example: strings.TrimSpace(`
+  # Listen on the default address and print incoming messages as JSON lines
+  cw webhooks listen -o json
+
+  # Verify signatures and run a handler per event
+  CW_WEBHOOK_SECRET=... cw webhooks listen --addr :8787 --exec './handle.sh' -o json
+
+  # Accept all events and persist the last seen message ID
+  cw webhooks listen --events all --cursor-file .cw-webhook.cursor -o json
+`),
+		Args: cobra.NoArgs,
+		RunE: RunE(func(cmd *cobra.Command, args []string) error {
+			if secret == "" {
+				secret = strings.TrimSpace(os.Getenv("CW_WEBHOOK_SECRET"))
+			}
+			if !strings.HasPrefix(path, "/") {
+				path = "/" + path
+			}
+
+			ctx, stop := signal.NotifyContext(cmdContext(cmd), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			cmd.SetContext(ctx)
+
+			cw, resume, err := newWebhookCursor(cursorFile)
+			if err != nil {
+				return err
+			}
+			if cw != nil {
+				defer func() { _ = cw.Flush() }()
+			}
+
+			var store *webhookStore
+			if !noStore {
+				if storeDir == "" {
+					storeDir = defaultWebhookStoreDir()
+				}
+				if store, err = newWebhookStore(storeDir); err != nil {
+					return err
+				}
+			}
+
+			pipeline := &webhookPipeline{
+				cmd:           cmd,
+				hook:          newFollowExecHook(cmd, execHandler, execTimeout, execFatal),
+				allowedEvents: webhookEventFlagSet(events),
+				incomingOnly:  incomingOnly,
+				filters:       followFilters{InboxID: filterInbox, ExcludePrivate: excludePrivate},
+				includeRaw:    includeRaw,
+				source:        "webhook",
+				cursor:        cw,
+				resumeAfter:   resume.LastSeenMessageID,
+				unhandled:     idSet(resume.Unhandled),
+			}
+			handler := &webhookHandler{
+				secret:   secret,
+				maxSkew:  maxSkew,
+				pipeline: pipeline,
+				store:    store,
+				errOut:   cmd.ErrOrStderr(),
+				now:      time.Now,
+				fatal:    make(chan error, 1),
+			}
+
+			mux := http.NewServeMux()
+			mux.Handle(path, handler)
+			mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			ln, err := net.Listen("tcp", addr)
+			if err != nil {
+				return fmt.Errorf("listen on %s: %w", addr, err)
+			}
+			srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+			if !isJSON(cmd) {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Listening for webhooks on http://%s%s (press Ctrl+C to stop)...\n", ln.Addr(), path)
+			} else {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "listening on http://%s%s\n", ln.Addr(), path)
+			}
+			if secret == "" {
+				_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "warning: no webhook secret configured; signatures will not be verified")
+			}
+
+			serveErr := make(chan error, 1)
+			go func() { serveErr <- srv.Serve(ln) }()
+
+			var runErr error
+			select {
+			case <-ctx.Done():
+			case runErr = <-handler.fatal:
+			case err := <-serveErr:
+				if !errors.Is(err, http.ErrServerClosed) {
+					runErr = err
+				}
+			}
+
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			_ = srv.Shutdown(shutdownCtx)
+			return runErr
+		}),
+	}
+
+	cmd.Flags().StringVar(&addr, "addr", "127.0.0.1:8787", "Address to listen on")
+	cmd.Flags().StringVar(&path, "path", "/webhooks", "URL path that receives webhook deliveries")
+	cmd.Flags().StringVar(&secret, "secret", "", "Webhook secret for signature verification (default: $CW_WEBHOOK_SECRET)")
+	cmd.Flags().DurationVar(&maxSkew, "max-skew", 5*time.Minute, "Reject signed deliveries whose timestamp is further than this from now (0 disables)")
+	cmd.Flags().StringSliceVar(&events, "events", []string{"message.created"}, "Event types to emit (or 'all'); webhook names like message_created are accepted")
+	cmd.Flags().BoolVar(&incomingOnly, "incoming-only", true, "Only emit incoming (customer) messages")
+	cmd.Flags().BoolVar(&excludePrivate, "exclude-private", false, "Exclude private messages")
+	cmd.Flags().IntVar(&filterInbox, "inbox", 0, "Only emit events for this inbox ID")
+	cmd.Flags().BoolVar(&includeRaw, "raw", false, "Include the raw webhook payload (JSON/agent modes only)")
+	cmd.Flags().StringVar(&cursorFile, "cursor-file", "", "Persist last seen message id to a file and skip already-seen messages")
+	cmd.Flags().StringVar(&storeDir, "store-dir", "", "Directory for received deliveries (default: <cache-dir>/webhooks)")
+	cmd.Flags().BoolVar(&noStore, "no-store", false, "Do not store received deliveries")
+	cmd.Flags().StringVar(&execHandler, "exec", "", "Run a command for each emitted JSON/agent event (event JSON on stdin)")
+	cmd.Flags().DurationVar(&execTimeout, "exec-timeout", 30*time.Second, "Timeout per --exec invocation")
+	cmd.Flags().BoolVar(&execFatal, "exec-fatal", false, "Treat --exec failures as fatal (default: log to stderr and continue)")
+	flagAlias(cmd.Flags(), "events", "ev")
+	flagAlias(cmd.Flags(), "incoming-only", "in")
+	flagAlias(cmd.Flags(), "exclude-private", "pub")
+	flagAlias(cmd.Flags(), "inbox", "ib")
+	flagAlias(cmd.Flags(), "raw", "rw")
+	flagAlias(cmd.Flags(), "cursor-file", "cf")
+	flagAlias(cmd.Flags(), "exec", "ex")
+	flagAlias(cmd.Flags(), "exec-timeout", "et")
+	flagAlias(cmd.Flags(), "exec-fatal", "ef")
+	return cmd
+}
+
+func newWebhooksReplayCmd() *cobra.Command {
+	var (
+		storeDir       string
+		since          string
+		events         []string
+		limit          int
+		incomingOnly   bool
+		excludePrivate bool
+		filterInbox    int
+		includeRaw     bool
+		cursorFile     string
+		targetURL      string
+		secret         string
+		execHandler    string
+		execTimeout    time.Duration
+		execFatal      bool
+	)
+
+	cmd := &cobra.Command{
+		Use:     "replay",
+		Aliases: []string{"rpl"},
+		Short:   "Re-deliver stored webhook events",
+		Long: strings.TrimSpace(`
+Re-deliver webhook events recorded by "webhooks listen".
+
+By default events are emitted to stdout (and --exec) exactly as "webhooks listen"
+emitted them. With --url, the original payloads are POSTed to another endpoint,
+signed with --secret (or CW_WEBHOOK_SECRET) when set.
+`),
+		Example: strings.TrimSpace(`
+  # Replay the last hour of stored events into a handler
+  cw webhooks replay --since 1h --exec './handle.sh' -o json
+
+  # Re-POST stored deliveries to a local service
+  cw webhooks replay --events all --url http://localhost:3000/hooks
+`),
+		Args: cobra.NoArgs,
+		RunE: RunE(func(cmd *cobra.Command, args []string) error {
+			if storeDir == "" {
+				storeDir = defaultWebhookStoreDir()
+			}
+			if storeDir == "" {
+				return fmt.Errorf("could not determine webhook store directory (use --store-dir)")
+			}
+			sinceT, err := parseSinceTime(since, time.Now())
+			if err != nil {
+				return err
+			}
+			records, err := loadWebhookRecords(storeDir, sinceT)
+			if err != nil {
+				return err
+			}
+			allowed := webhookEventFlagSet(events)
+
+			if targetURL != "" {
+				if secret == "" {
+					secret = strings.TrimSpace(os.Getenv("CW_WEBHOOK_SECRET"))
+				}
+				return replayWebhooksToURL(cmd, records, allowed, limit, targetURL, secret)
+			}
+
+			cw, resume, err := newWebhookCursor(cursorFile)
+			if err != nil {
+				return err
+			}
+			if cw != nil {
+				defer func() { _ = cw.Flush() }()
+			}
+			pipeline := &webhookPipeline{
+				cmd:           cmd,
+				hook:          newFollowExecHook(cmd, execHandler, execTimeout, execFatal),
+				allowedEvents: allowed,
+				incomingOnly:  incomingOnly,
+				filters:       followFilters{InboxID: filterInbox, ExcludePrivate: excludePrivate},
+				includeRaw:    includeRaw,
+				source:        "replay",
+				cursor:        cw,
+				resumeAfter:   resume.LastSeenMessageID,
+				unhandled:     idSet(resume.Unhandled),
+			}
+
+			emitted := 0
+			for _, rec := range records {
+				if limit > 0 && emitted >= limit {
+					break
+				}
+				ev, err := decodeWebhookEvent(rec.Body)
+				if err != nil {
+					continue
+				}
+				ok, err := pipeline.Process(ev)
+				if err != nil {
+					return err
+				}
+				if ok {
+					emitted++
+				}
+			}
+			if !isJSON(cmd) {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Replayed %d of %d stored events\n", emitted, len(records))
+			}
+			return nil
+		}),
+	}
+
+	cmd.Flags().StringVar(&storeDir, "store-dir", "", "Directory with stored deliveries (default: <cache-dir>/webhooks)")
+	cmd.Flags().StringVar(&since, "since", "", "Only replay deliveries received after this time (RFC3339, unix seconds, or duration like 24h)")
+	cmd.Flags().StringSliceVar(&events, "events", []string{"message.created"}, "Event types to replay (or 'all')")
+	cmd.Flags().IntVar(&limit, "limit", 0, "Maximum number of events to replay (0 = unlimited)")
+	cmd.Flags().BoolVar(&incomingOnly, "incoming-only", true, "Only emit incoming (customer) messages")
+	cmd.Flags().BoolVar(&excludePrivate, "exclude-private", false, "Exclude private messages")
+	cmd.Flags().IntVar(&filterInbox, "inbox", 0, "Only emit events for this inbox ID")
+	cmd.Flags().BoolVar(&includeRaw, "raw", false, "Include the raw webhook payload (JSON/agent modes only)")
+	cmd.Flags().StringVar(&cursorFile, "cursor-file", "", "Persist last seen message id to a file and skip already-seen messages")
+	cmd.Flags().StringVar(&targetURL, "url", "", "POST stored payloads to this URL instead of emitting them")
+	cmd.Flags().StringVar(&secret, "secret", "", "Sign re-POSTed payloads with this secret (default: $CW_WEBHOOK_SECRET)")
+	cmd.Flags().StringVar(&execHandler, "exec", "", "Run a command for each emitted JSON/agent event (event JSON on stdin)")
+	cmd.Flags().DurationVar(&execTimeout, "exec-timeout", 30*time.Second, "Timeout per --exec invocation")
+	cmd.Flags().BoolVar(&execFatal, "exec-fatal", false, "Treat --exec failures as fatal (default: log to stderr and continue)")
+	flagAlias(cmd.Flags(), "since", "sc")
+	flagAlias(cmd.Flags(), "events", "ev")
+	flagAlias(cmd.Flags(), "limit", "lt")
+	flagAlias(cmd.Flags(), "incoming-only", "in")
+	flagAlias(cmd.Flags(), "exclude-private", "pub")
+	flagAlias(cmd.Flags(), "inbox", "ib")
+	flagAlias(cmd.Flags(), "raw", "rw")
+	flagAlias(cmd.Flags(), "cursor-file", "cf")
+	flagAlias(cmd.Flags(), "exec", "ex")
+	flagAlias(cmd.Flags(), "exec-timeout", "et")
+	flagAlias(cmd.Flags(), "exec-fatal", "ef")
+	return cmd
+}
+
+func replayWebhooksToURL(cmd *cobra.Command, records []webhookRecord, allowed map[string]struct{}, limit int, targetURL, secret string) error {
+	ctx := cmdContext(cmd)
+	httpClient := &http.Client{Timeout: 30 * time.Second}
+	sent, failed := 0, 0
+	for _, rec := range records {
+		if limit > 0 && sent+failed >= limit {
+			break
+		}
+		if allowed != nil {
+			if _, ok := allowed[normalizeWebhookEventName(rec.Event)]; !ok {
+				continue
+			}
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, targetURL, bytes.NewReader(rec.Body))
+		if err != nil {
+			return fmt.Errorf("invalid --url: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if rec.DeliveryID != "" {
+			req.Header.Set(webhookDeliveryHeader, rec.DeliveryID)
+		}
+		if secret != "" {
+			ts := strconv.FormatInt(time.Now().Unix(), 10)
+			req.Header.Set(webhookTimestampHeader, ts)
+			req.Header.Set(webhookSignatureHeader, signWebhookPayload(secret, ts, rec.Body))
+		}
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			failed++
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "replay %s failed: %v\n", rec.Event, err)
+			continue
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			failed++
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "replay %s failed: HTTP %d\n", rec.Event, resp.StatusCode)
+			continue
+		}
+		sent++
+	}
+
+	if isJSON(cmd) {
+		return printJSON(cmd, map[string]any{"url": targetURL, "sent": sent, "failed": failed})
+	}
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Replayed %d events to %s (%d failed)\n", sent, targetURL, failed)
+	if failed > 0 {
+		return fmt.Errorf("%d deliveries failed", failed)
+	}
+	return nil
+}
@@ -0,0 +1,472 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+	"github.com/chatwoot/chatwoot-cli/internal/outfmt"
+)
+
+const testWebhookMessagePayload = `{
+	"event": "message_created",
+	"id": 501,
+	"content": "Hello from webhook",
+	"content_type": "text",
+	"message_type": "incoming",
+	"private": false,
+	"created_at": "2026-01-02T03:04:05.000Z",
+	"conversation": {"id": 9001, "display_id": 42, "inbox_id": 7},
+	"inbox": {"id": 7, "name": "Support"},
+	"sender": {"id": 11, "name": "Jane", "type": "contact"}
+}`
+
+func TestNormalizeWebhookEventName(t *testing.T) {
+	tests := map[string]string{
+		"message_created":             "message.created",
+		"conversation_status_changed": "conversation.status_changed",
+		"webwidget_triggered":         "webwidget.triggered",
+		"message.updated":             "message.updated",
+		"custom_thing_happened":       "custom.thing_happened",
+	}
+	for in, want := range tests {
+		if got := normalizeWebhookEventName(in); got != want {
+			t.Errorf("normalizeWebhookEventName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	body := []byte(`{"event":"message_created"}`)
+	now := time.Unix(1_700_000_000, 0)
+	ts := strconv.FormatInt(now.Unix(), 10)
+
+	header := http.Header{}
+	header.Set(webhookTimestampHeader, ts)
+	header.Set(webhookSignatureHeader, signWebhookPayload("s3cret", ts, body))
+	if err := verifyWebhookSignature("s3cret", header, body, now, time.Minute); err != nil {
+		t.Fatalf("expected valid signature, got %v", err)
+	}
+
+	if err := verifyWebhookSignature("other", header, body, now, time.Minute); err != errWebhookSignatureInvalid {
+		t.Fatalf("expected mismatch, got %v", err)
+	}
+
+	if err := verifyWebhookSignature("s3cret", header, body, now.Add(10*time.Minute), time.Minute); err == nil || !strings.Contains(err.Error(), "skew") {
+		t.Fatalf("expected skew error, got %v", err)
+	}
+
+	if err := verifyWebhookSignature("s3cret", http.Header{}, body, now, time.Minute); err != errWebhookSignatureMissing {
+		t.Fatalf("expected missing signature error, got %v", err)
+	}
+
+	// Body-only signatures (no timestamp header) could be replayed forever.
+	bare := http.Header{}
+	bare.Set(webhookSignatureHeader, strings.TrimPrefix(signWebhookPayload("s3cret", "", body), "sha256="))
+	if err := verifyWebhookSignature("s3cret", bare, body, now, time.Minute); err != errWebhookTimestampMissing {
+		t.Fatalf("expected missing timestamp error, got %v", err)
+	}
+	if err := verifyWebhookSignature("s3cret", bare, body, now, 0); err != errWebhookTimestampMissing {
+		t.Fatalf("expected missing timestamp error without a skew limit, got %v", err)
+	}
+}
+
+func TestWebhookMessageFromPayload(t *testing.T) {
+	ev, err := decodeWebhookEvent([]byte(testWebhookMessagePayload))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if ev.Event != "message.created" {
+		t.Fatalf("event = %q", ev.Event)
+	}
+	msg := webhookMessageFromPayload(ev.Payload)
+	if msg.ID != 501 || msg.ConversationID != 42 {
+		t.Fatalf("unexpected ids: %+v", msg)
+	}
+	if msg.MessageType != api.MessageTypeIncoming {
+		t.Fatalf("message_type = %d", msg.MessageType)
+	}
+	if want := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC).Unix(); msg.CreatedAt != want {
+		t.Fatalf("created_at = %d, want %d", msg.CreatedAt, want)
+	}
+	if msg.Sender == nil || msg.Sender.Name != "Jane" || msg.SenderType != "contact" {
+		t.Fatalf("unexpected sender: %+v", msg.Sender)
+	}
+	if got := webhookInboxID(ev.Payload); got != 7 {
+		t.Fatalf("inbox id = %d", got)
+	}
+}
+
+func TestWebhookPipelineFiltersAndDedupes(t *testing.T) {
+	cmd, out, _ := newFollowTestCmd(outfmt.JSONL)
+	p := &webhookPipeline{
+		cmd:           cmd,
+		allowedEvents: webhookEventFlagSet([]string{"message_created"}),
+		incomingOnly:  true,
+		source:        "webhook",
+	}
+
+	ev, err := decodeWebhookEvent([]byte(testWebhookMessagePayload))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := p.Process(ev); err != nil || !ok {
+		t.Fatalf("first delivery: ok=%v err=%v", ok, err)
+	}
+	// Chatwoot retries deliveries; the same message must not be emitted twice.
+	if ok, err := p.Process(ev); err != nil || ok {
+		t.Fatalf("duplicate delivery: ok=%v err=%v", ok, err)
+	}
+
+	outgoing, _ := decodeWebhookEvent([]byte(strings.Replace(strings.Replace(testWebhookMessagePayload, `"incoming"`, `"outgoing"`, 1), `"id": 501`, `"id": 502`, 1)))
+	if ok, _ := p.Process(outgoing); ok {
+		t.Fatal("expected outgoing message to be filtered")
+	}
+	status, _ := decodeWebhookEvent([]byte(`{"event":"conversation_status_changed","id":42,"status":"resolved"}`))
+	if ok, _ := p.Process(status); ok {
+		t.Fatal("expected conversation event to be filtered by --events")
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected 1 line, got %d: %q", len(lines), out.String())
+	}
+	var rec map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &rec); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if rec["kind"] != "conversations.follow" || rec["source"] != "webhook" || rec["event"] != "message.created" {
+		t.Fatalf("unexpected record: %v", rec)
+	}
+	if rec["conversation_id"] != float64(42) {
+		t.Fatalf("conversation_id = %v", rec["conversation_id"])
+	}
+}
+
+func TestWebhookPipelineDedupesOutOfOrderDeliveries(t *testing.T) {
+	cmd, out, _ := newFollowTestCmd(outfmt.JSONL)
+	p := &webhookPipeline{
+		cmd:           cmd,
+		allowedEvents: webhookEventFlagSet([]string{"all"}),
+		source:        "webhook",
+		resumeAfter:   400,
+	}
+	delivery := func(id int) webhookEvent {
+		ev, err := decodeWebhookEvent([]byte(strings.Replace(testWebhookMessagePayload, `"id": 501`, `"id": `+strconv.Itoa(id), 1)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ev
+	}
+
+	for _, tc := range []struct {
+		id   int
+		want bool
+	}{
+		{501, true},
+		{500, true}, // delivered late, after a higher ID
+		{501, false},
+		{500, false},
+		{400, false}, // handled by a previous run
+	} {
+		if ok, err := p.Process(delivery(tc.id)); err != nil || ok != tc.want {
+			t.Fatalf("delivery %d: ok=%v err=%v, want ok=%v", tc.id, ok, err, tc.want)
+		}
+	}
+	if n := strings.Count(out.String(), "\n"); n != 2 {
+		t.Fatalf("expected 2 lines, got %d: %q", n, out.String())
+	}
+}
+
+func TestRecentSetEvictsOldest(t *testing.T) {
+	r := recentSet[int]{limit: 2}
+	r.Add(1)
+	r.Add(2)
+	r.Add(2)
+	r.Add(3)
+	if r.Has(1) || !r.Has(2) || !r.Has(3) {
+		t.Fatalf("unexpected set after eviction: %v", r.set)
+	}
+	r.Add(4)
+	if r.Has(2) || !r.Has(3) || !r.Has(4) {
+		t.Fatalf("unexpected set after wraparound: %v", r.set)
+	}
+}
+
+func TestWebhookPipelineRetriesFailedHandler(t *testing.T) {
+	cmd, out, _ := newFollowTestCmd(outfmt.JSONL)
+	cursorPath := filepath.Join(t.TempDir(), "cursor.json")
+	cw, err := newFollowCursorWriter(cursorPath, "", 0, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &webhookPipeline{
+		cmd:           cmd,
+		hook:          newFollowExecHook(cmd, "exit 1", time.Second, true),
+		allowedEvents: webhookEventFlagSet([]string{"all"}),
+		source:        "webhook",
+		cursor:        cw,
+	}
+
+	ev, err := decodeWebhookEvent([]byte(testWebhookMessagePayload))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Process(ev); err == nil {
+		t.Fatal("expected fatal hook error")
+	}
+	if cw.LastSeenID != 0 {
+		t.Fatalf("cursor advanced past a failed delivery: %d", cw.LastSeenID)
+	}
+
+	// Chatwoot retries the failed delivery; it must be handled this time.
+	p.hook = nil
+	if ok, err := p.Process(ev); err != nil || !ok {
+		t.Fatalf("retried delivery: ok=%v err=%v", ok, err)
+	}
+	if cw.LastSeenID != 501 {
+		t.Fatalf("cursor = %d, want 501", cw.LastSeenID)
+	}
+	if n := strings.Count(out.String(), "\n"); n != 1 {
+		t.Fatalf("expected 1 line, got %d: %q", n, out.String())
+	}
+}
+
+func TestWebhookPipelineKeepsFailedDeliveryAcrossRestart(t *testing.T) {
+	cursorPath := filepath.Join(t.TempDir(), "cursor.json")
+	delivery := func(id int) webhookEvent {
+		ev, err := decodeWebhookEvent([]byte(strings.Replace(testWebhookMessagePayload, `"id": 501`, `"id": `+strconv.Itoa(id), 1)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ev
+	}
+	newPipeline := func() (*webhookPipeline, *followCursorWriter, *bytes.Buffer) {
+		cmd, out, _ := newFollowTestCmd(outfmt.JSONL)
+		cw, resume, err := newWebhookCursor(cursorPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &webhookPipeline{
+			cmd:           cmd,
+			allowedEvents: webhookEventFlagSet([]string{"all"}),
+			source:        "webhook",
+			cursor:        cw,
+			resumeAfter:   resume.LastSeenMessageID,
+			unhandled:     idSet(resume.Unhandled),
+		}, cw, out
+	}
+
+	// Delivery 500 fails, then 501 succeeds and moves the cursor past it.
+	p, cw, _ := newPipeline()
+	p.hook = newFollowExecHook(p.cmd, "exit 1", time.Second, true)
+	if _, err := p.Process(delivery(500)); err == nil {
+		t.Fatal("expected fatal hook error")
+	}
+	p.hook = nil
+	if ok, err := p.Process(delivery(501)); err != nil || !ok {
+		t.Fatalf("delivery 501: ok=%v err=%v", ok, err)
+	}
+	if err := cw.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	// After a restart, Chatwoot's retry of 500 is still handled.
+	p, cw, out := newPipeline()
+	for _, tc := range []struct {
+		id   int
+		want bool
+	}{
+		{501, false},
+		{500, true},
+		{500, false},
+	} {
+		if ok, err := p.Process(delivery(tc.id)); err != nil || ok != tc.want {
+			t.Fatalf("delivery %d: ok=%v err=%v, want ok=%v", tc.id, ok, err, tc.want)
+		}
+	}
+	if n := strings.Count(out.String(), "\n"); n != 1 {
+		t.Fatalf("expected 1 line, got %d: %q", n, out.String())
+	}
+	if err := cw.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	cur, err := loadFollowCursor(cursorPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cur.LastSeenMessageID != 501 || len(cur.Unhandled) != 0 {
+		t.Fatalf("unexpected cursor: %+v", cur)
+	}
+}
+
+func TestWebhookHandlerVerifiesAndStores(t *testing.T) {
+	cmd, out, errOut := newFollowTestCmd(outfmt.JSONL)
+	store, err := newWebhookStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	h := &webhookHandler{
+		secret:  "s3cret",
+		maxSkew: time.Minute,
+		pipeline: &webhookPipeline{
+			cmd:           cmd,
+			allowedEvents: webhookEventFlagSet([]string{"all"}),
+			source:        "webhook",
+		},
+		store:  store,
+		errOut: errOut,
+		now:    func() time.Time { return now },
+		fatal:  make(chan error, 1),
+	}
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	post := func(sig string) int {
+		req, _ := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader(testWebhookMessagePayload))
+		ts := strconv.FormatInt(now.Unix(), 10)
+		req.Header.Set(webhookTimestampHeader, ts)
+		if sig == "" {
+			sig = signWebhookPayload("s3cret", ts, []byte(testWebhookMessagePayload))
+		}
+		req.Header.Set(webhookSignatureHeader, sig)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if code := post("sha256=deadbeef"); code != http.StatusUnauthorized {
+		t.Fatalf("bad signature: status %d", code)
+	}
+	if code := post(""); code != http.StatusNoContent {
+		t.Fatalf("good signature: status %d", code)
+	}
+	// Chatwoot retries the delivery; it is stored once.
+	if code := post(""); code != http.StatusNoContent {
+		t.Fatalf("retried delivery: status %d", code)
+	}
+	if !strings.Contains(out.String(), `"source":"webhook"`) {
+		t.Fatalf("expected emitted event, got %q", out.String())
+	}
+
+	records, err := loadWebhookRecords(store.dir, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || !records[0].Verified || records[0].Event != "message.created" {
+		t.Fatalf("unexpected stored records: %+v", records)
+	}
+}
+
+func TestWebhookStoreDedupesDeliveries(t *testing.T) {
+	store, err := newWebhookStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	for i, rec := range []webhookRecord{
+		{DeliveryID: "d1", Body: json.RawMessage(`{"event":"message_created","id":1}`)},
+		{DeliveryID: "d1", Body: json.RawMessage(`{"event":"message_created","id":1}`)},
+		{DeliveryID: "d2", Body: json.RawMessage(`{"event":"message_created","id":1}`)},
+		{Body: json.RawMessage(`{"event":"message_created","id":2}`)},
+		{Body: json.RawMessage(`{"event":"message_created","id":2}`)},
+	} {
+		rec.ReceivedAt = now.Add(time.Duration(i) * time.Second).UTC().Format(time.RFC3339Nano)
+		if err := store.Append(rec, now); err != nil {
+			t.Fatal(err)
+		}
+	}
+	records, err := loadWebhookRecords(store.dir, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("expected 3 stored deliveries, got %+v", records)
+	}
+}
+
+func TestWebhooksReplayCommand(t *testing.T) {
+	setupTestEnvWithHandler(t, newRouteHandler())
+
+	dir := t.TempDir()
+	store, err := newWebhookStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	_ = store.Append(webhookRecord{ReceivedAt: now.Add(-48 * time.Hour).UTC().Format(time.RFC3339Nano), Event: "message.created", Body: json.RawMessage(strings.Replace(testWebhookMessagePayload, `"id": 501`, `"id": 500`, 1))}, now.Add(-48*time.Hour))
+	_ = store.Append(webhookRecord{ReceivedAt: now.UTC().Format(time.RFC3339Nano), Event: "message.created", Body: json.RawMessage(testWebhookMessagePayload)}, now)
+
+	cursor := filepath.Join(t.TempDir(), "cursor.json")
+	output := captureStdout(t, func() {
+		err := Execute(context.Background(), []string{"webhooks", "replay", "--store-dir", dir, "--since", "24h", "--cursor-file", cursor, "-o", "jsonl"})
+		if err != nil {
+			t.Fatalf("replay failed: %v", err)
+		}
+	})
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) != 1 || !strings.Contains(lines[0], `"source":"replay"`) || !strings.Contains(lines[0], `"id":501`) {
+		t.Fatalf("unexpected replay output: %q", output)
+	}
+	cur, err := loadFollowCursor(cursor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cur.LastSeenMessageID != 501 {
+		t.Fatalf("cursor last seen = %d", cur.LastSeenMessageID)
+	}
+
+	// A second run resumes from the cursor and emits nothing.
+	output = captureStdout(t, func() {
+		if err := Execute(context.Background(), []string{"webhooks", "replay", "--store-dir", dir, "--cursor-file", cursor, "-o", "jsonl"}); err != nil {
+			t.Fatalf("replay failed: %v", err)
+		}
+	})
+	if strings.TrimSpace(output) != "" {
+		t.Fatalf("expected no output on resume, got %q", output)
+	}
+}
+
+func TestWebhooksReplayToURL(t *testing.T) {
+	setupTestEnvWithHandler(t, newRouteHandler())
+
+	dir := t.TempDir()
+	store, _ := newWebhookStore(dir)
+	now := time.Now()
+	_ = store.Append(webhookRecord{ReceivedAt: now.UTC().Format(time.RFC3339Nano), Event: "message.created", Body: json.RawMessage(testWebhookMessagePayload)}, now)
+
+	var gotSig bool
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotSig = verifyWebhookSignature("s3cret", r.Header, body, time.Now(), time.Minute) == nil
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer target.Close()
+
+	output := captureStdout(t, func() {
+		err := Execute(context.Background(), []string{"webhooks", "replay", "--store-dir", dir, "--url", target.URL, "--secret", "s3cret", "-o", "json"})
+		if err != nil {
+			t.Fatalf("replay failed: %v", err)
+		}
+	})
+	if !gotSig {
+		t.Fatal("expected re-POSTed delivery to carry a valid signature")
+	}
+	if !strings.Contains(output, `"sent": 1`) {
+		t.Fatalf("unexpected output: %q", output)
+	}
+}
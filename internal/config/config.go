@@ -27,6 +27,8 @@ const (
 	envKeyringPasswordLegacy = "CHATWOOT_KEYRING_PASSWORD"
 	envCredentialsDir        = "CW_CREDENTIALS_DIR"
 	envCredentialsDirLegacy  = "CHATWOOT_CREDENTIALS_DIR"
+	envConfigDir             = "CW_CONFIG_DIR"
+	envConfigDirLegacy       = "CHATWOOT_CONFIG_DIR"
 
 	keyringBackendAuto   = "auto"
 	keyringBackendFile   = "file"
@@ -139,19 +141,29 @@ func configureFileBackend(cfg *keyring.Config) {
 func keyringFileDir() string {
 	base := firstNonBlankEnv(envCredentialsDir, envCredentialsDirLegacy)
 	if base == "" {
-		if dir, err := userConfigDir(); err == nil && strings.TrimSpace(dir) != "" {
-			base = filepath.Join(dir, serviceName)
-		}
+		base = defaultDir()
 	}
-	if base == "" {
-		if home, err := os.UserHomeDir(); err == nil && strings.TrimSpace(home) != "" {
-			base = filepath.Join(home, ".config", serviceName)
-		}
+	return filepath.Join(base, "keyring")
+}
+
+// Dir returns the directory for local CLI state such as the scheduled
+// message queue: CW_CONFIG_DIR if set, otherwise chatwoot-cli under the
+// user config directory.
+func Dir() string {
+	if dir := firstNonBlankEnv(envConfigDir, envConfigDirLegacy); dir != "" {
+		return dir
 	}
-	if base == "" {
-		base = filepath.Join(os.TempDir(), serviceName)
+	return defaultDir()
+}
+
+func defaultDir() string {
+	if dir, err := userConfigDir(); err == nil && strings.TrimSpace(dir) != "" {
+		return filepath.Join(dir, serviceName)
 	}
-	return filepath.Join(base, "keyring")
+	if home, err := os.UserHomeDir(); err == nil && strings.TrimSpace(home) != "" {
+		return filepath.Join(home, ".config", serviceName)
+	}
+	return filepath.Join(os.TempDir(), serviceName)
 }
 
 func keyringFilePassword(prompt string) (string, error) {
@@ -300,6 +300,29 @@ func TestResolveAccountClientConfig_FromEnv(t *testing.T) {
 	}
 }
 
+func TestResolveProfileClientConfig_IgnoresEnv(t *testing.T) {
+	withMockKeyring(t, testKeyring(t, nil))
+	t.Setenv("CHATWOOT_BASE_URL", "https://env.example.com")
+	t.Setenv("CHATWOOT_API_TOKEN", "env-token")
+	t.Setenv("CHATWOOT_ACCOUNT_ID", "1")
+
+	if err := SaveProfile("staging", Account{BaseURL: "https://staging.example.com", APIToken: "staging-token", AccountID: 7}); err != nil {
+		t.Fatalf("SaveProfile: %v", err)
+	}
+
+	cfg, err := ResolveProfileClientConfig("staging")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.BaseURL != "https://staging.example.com" || cfg.Token != "staging-token" || cfg.AccountID != 7 {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+
+	if _, err := ResolveProfileClientConfig("missing"); err == nil || !strings.Contains(err.Error(), `profile "missing" not found`) {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+}
+
 func TestResolvePlatformClientConfig_EnvOnly(t *testing.T) {
 	t.Setenv("CHATWOOT_BASE_URL", "https://example.com/")
 	t.Setenv("CHATWOOT_PLATFORM_TOKEN", "platform-token")
@@ -742,6 +765,26 @@ func TestKeyringFileDir_DefaultsToUserConfigDir(t *testing.T) {
 	}
 }
 
+func TestDir(t *testing.T) {
+	t.Setenv(envConfigDirLegacy, "")
+	t.Setenv(envConfigDir, "")
+
+	fakeConfigDir := t.TempDir()
+	original := userConfigDir
+	userConfigDir = func() (string, error) { return fakeConfigDir, nil }
+	t.Cleanup(func() { userConfigDir = original })
+
+	if got, want := Dir(), filepath.Join(fakeConfigDir, serviceName); got != want {
+		t.Fatalf("Dir() = %q, want %q", got, want)
+	}
+
+	override := t.TempDir()
+	t.Setenv(envConfigDir, override)
+	if got := Dir(); got != override {
+		t.Fatalf("Dir() = %q, want %q", got, override)
+	}
+}
+
 func TestKeyringFilePassword_FromEnv(t *testing.T) {
 	t.Setenv(envKeyringPassword, "env-pass")
 	t.Setenv(envKeyringPasswordLegacy, "")
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -26,6 +27,23 @@ func ResolveAccountClientConfig() (ClientConfig, error) {
 	}, nil
 }
 
+// ResolveProfileClientConfig resolves account-scoped client settings for a named
+// profile, ignoring CHATWOOT_* environment overrides.
+func ResolveProfileClientConfig(profile string) (ClientConfig, error) {
+	account, err := LoadProfile(profile)
+	if err != nil {
+		if errors.Is(err, ErrNotConfigured) {
+			return ClientConfig{}, fmt.Errorf("profile %q not found", profile)
+		}
+		return ClientConfig{}, err
+	}
+	return ClientConfig{
+		BaseURL:   account.BaseURL,
+		Token:     account.APIToken,
+		AccountID: account.AccountID,
+	}, nil
+}
+
 // ResolvePlatformClientConfig resolves platform client settings with overrides.
 func ResolvePlatformClientConfig(baseURLOverride, tokenOverride string) (ClientConfig, error) {
 	var cfg ClientConfig
@@ -0,0 +1,159 @@
+package contactimport
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Actions recorded for imported rows.
+const (
+	ActionCreated = "created"
+	ActionUpdated = "updated"
+)
+
+// checkpointHeader is the first line of a checkpoint file.
+type checkpointHeader struct {
+	Version   int    `json:"version"`
+	Input     string `json:"input"`
+	SHA256    string `json:"sha256"`
+	AccountID int    `json:"account_id"`
+}
+
+// CheckpointEntry records one imported row.
+type CheckpointEntry struct {
+	Line      int    `json:"line"`
+	ContactID int    `json:"contact_id"`
+	Action    string `json:"action"`
+}
+
+// Checkpoint is an append-only JSONL file of imported rows. Re-running an
+// import with the same checkpoint skips them; failed rows are not recorded,
+// so they are retried.
+type Checkpoint struct {
+	path string
+	done map[int]CheckpointEntry
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+// HashFile returns the hex SHA-256 of the file at path.
+func HashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// OpenCheckpoint loads the checkpoint at path, or starts one. It fails if the
+// file was written for different input or another account.
+func OpenCheckpoint(path, input, sum string, accountID int) (*Checkpoint, error) {
+	cp := &Checkpoint{path: path, done: map[int]CheckpointEntry{}}
+	header := checkpointHeader{Version: Version, Input: filepath.Base(input), SHA256: sum, AccountID: accountID}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("read checkpoint: %w", err)
+	}
+	resumed, err := cp.load(data, header)
+	if err != nil {
+		return nil, err
+	}
+
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return nil, fmt.Errorf("create checkpoint directory: %w", err)
+		}
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("open checkpoint: %w", err)
+	}
+	cp.file = f
+	switch {
+	case !resumed:
+		err = cp.writeLine(header)
+	case data[len(data)-1] != '\n':
+		// Terminate a line torn by an interrupted run before appending.
+		_, err = f.Write([]byte{'\n'})
+	}
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("write checkpoint: %w", err)
+	}
+	return cp, nil
+}
+
+// load reads an existing checkpoint, reporting false when there is none.
+func (cp *Checkpoint) load(data []byte, want checkpointHeader) (bool, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	if !scanner.Scan() {
+		return false, nil
+	}
+	var got checkpointHeader
+	if err := json.Unmarshal(scanner.Bytes(), &got); err != nil || got.Version == 0 {
+		return false, fmt.Errorf("%s is not an import checkpoint", cp.path)
+	}
+	if got.SHA256 != want.SHA256 || got.AccountID != want.AccountID {
+		return false, fmt.Errorf("checkpoint %s was written for %s in account %d; the input or account has changed (use a different --checkpoint)",
+			cp.path, got.Input, got.AccountID)
+	}
+	for scanner.Scan() {
+		var e CheckpointEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil || e.Line == 0 {
+			// A torn line from an interrupted run; that row is retried.
+			continue
+		}
+		cp.done[e.Line] = e
+	}
+	return true, scanner.Err()
+}
+
+// Path returns the checkpoint location.
+func (cp *Checkpoint) Path() string {
+	return cp.path
+}
+
+// Done reports whether the row starting at line was already imported.
+func (cp *Checkpoint) Done(line int) (CheckpointEntry, bool) {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	e, ok := cp.done[line]
+	return e, ok
+}
+
+// Record appends an imported row. It is safe for concurrent use.
+func (cp *Checkpoint) Record(e CheckpointEntry) error {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	cp.done[e.Line] = e
+	return cp.writeLine(e)
+}
+
+func (cp *Checkpoint) writeLine(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = cp.file.Write(append(data, '\n'))
+	return err
+}
+
+// Close closes the checkpoint file.
+func (cp *Checkpoint) Close() error {
+	return cp.file.Close()
+}
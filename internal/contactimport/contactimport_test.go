@@ -0,0 +1,278 @@
+package contactimport_test
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+	"github.com/chatwoot/chatwoot-cli/internal/contactimport"
+)
+
+const testCSV = "\ufeffFull Name,E-mail,Mobile,CRM ID,Plan,Seats,Notes\n" +
+	"Jane Doe,Jane@Example.com,020 7946 0958,crm-1,pro,5,likes tea\n" +
+	"\"Bob\nStone\",,,crm-2,,,\n" +
+	"No Keys,,,,,,\n" +
+	"Bad Seats,bad@example.com,,,,many,\n"
+
+const testMapping = `
+columns:
+  Full Name: name
+  E-mail: email
+  Mobile: phone_number
+  CRM ID: identifier
+  Plan: custom_attributes.plan
+  Seats: custom_attributes.seats:number
+  Notes: ""
+`
+
+func TestReadCSVAndApplyMapping(t *testing.T) {
+	in, err := contactimport.Read(strings.NewReader(testCSV), contactimport.FormatCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if in.Columns[0] != "Full Name" || len(in.Records) != 4 {
+		t.Fatalf("unexpected input: columns %q, %d records", in.Columns, len(in.Records))
+	}
+	var lines []int
+	for _, rec := range in.Records {
+		lines = append(lines, rec.Line)
+	}
+	if !reflect.DeepEqual(lines, []int{2, 3, 5, 6}) {
+		t.Fatalf("record lines = %v", lines)
+	}
+
+	m, err := contactimport.Parse([]byte(testMapping))
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := contactimport.Options{CountryCode: "44"}
+
+	row, err := m.Apply(in.Records[0], opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"name":              "Jane Doe",
+		"email":             "Jane@Example.com",
+		"phone_number":      "+442079460958",
+		"identifier":        "crm-1",
+		"custom_attributes": map[string]any{"plan": "pro", "seats": int64(5)},
+	}
+	if !reflect.DeepEqual(row.Body, want) {
+		t.Fatalf("body = %#v", row.Body)
+	}
+	if row.Email != "jane@example.com" || row.Phone != "+442079460958" || row.Identifier != "crm-1" {
+		t.Fatalf("match values = %q %q %q", row.Email, row.Phone, row.Identifier)
+	}
+
+	row, err = m.Apply(in.Records[1], opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(row.Body, map[string]any{"name": "Bob\nStone", "identifier": "crm-2"}) {
+		t.Fatalf("empty cells should be skipped: %#v", row.Body)
+	}
+
+	if _, err := m.Apply(in.Records[2], opts); err == nil || !strings.Contains(err.Error(), "no email") {
+		t.Fatalf("expected missing key error, got %v", err)
+	}
+	if _, err := m.Apply(in.Records[3], opts); err == nil || !strings.Contains(err.Error(), "not a number") {
+		t.Fatalf("expected number error, got %v", err)
+	}
+}
+
+func TestParseMappingErrors(t *testing.T) {
+	tests := map[string]string{
+		"columns: {A: nickname}":                 "unknown field",
+		"columns: {A: email, B: email}":          "both map to email",
+		"columns: {A: custom_attributes.x:date}": "unknown type",
+		"columns: {A: name:number}":              "cannot have a type",
+		"version: 9\ncolumns: {A: name}":         "newer",
+		"columns: {}":                            "no columns",
+		"columns: {A: name}\nextra: true":        "parse mapping",
+		"columns: {A: additional_attributes.}":   "needs an attribute key",
+	}
+	for data, want := range tests {
+		if _, err := contactimport.Parse([]byte(data)); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Parse(%q) = %v, want error containing %q", data, err, want)
+		}
+	}
+}
+
+func TestDefaultMappingJSONL(t *testing.T) {
+	data := `{"name": "Ann", "email": "ann@example.com", "external_id": 42, "custom_attributes": {"tier": "gold"}, "mobile": "+15551234567", "phone": "+15550000000", "score": 3}` + "\n\n" +
+		`{"name": "Ben", "identifier": "b-1"}` + "\n"
+	in, err := contactimport.Read(strings.NewReader(data), contactimport.FormatJSONL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(in.Records) != 2 || in.Records[1].Line != 3 {
+		t.Fatalf("unexpected records: %+v", in.Records)
+	}
+	m, unmapped := contactimport.DefaultMapping(in.Columns)
+	// Columns are sorted, so "mobile" claims phone_number before "phone" and
+	// "external_id" claims identifier.
+	if !reflect.DeepEqual(unmapped, []string{"identifier", "phone", "score"}) {
+		t.Fatalf("unmapped = %v", unmapped)
+	}
+	row, err := m.Apply(in.Records[0], contactimport.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if row.Identifier != "42" || row.Phone != "+15551234567" {
+		t.Fatalf("unexpected row: %+v", row)
+	}
+	if attrs := row.Body["custom_attributes"].(map[string]any); attrs["tier"] != "gold" {
+		t.Fatalf("custom attributes = %#v", attrs)
+	}
+
+	if _, err := contactimport.Read(strings.NewReader("{\"a\":1}\nnot json\n"), contactimport.FormatJSONL); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Fatalf("expected line 2 error, got %v", err)
+	}
+}
+
+func TestFilterPayloadAndPickMatch(t *testing.T) {
+	row := contactimport.Row{Email: "jane@example.com", Phone: "+442079460958", Identifier: "crm-1"}
+	fields := []string{"email", "phone_number"}
+
+	got, _ := json.Marshal(row.FilterPayload(fields))
+	want := `{"payload":[{"attribute_key":"email","filter_operator":"equal_to","query_operator":"or","values":["jane@example.com"]},{"attribute_key":"phone_number","filter_operator":"equal_to","values":["+442079460958"]}]}`
+	if string(got) != want {
+		t.Fatalf("payload = %s", got)
+	}
+	if p := row.FilterPayload([]string{"identifier"}); p == nil {
+		t.Fatal("expected identifier payload")
+	}
+	if p := (contactimport.Row{Email: "x@example.com"}).FilterPayload([]string{"identifier"}); p != nil {
+		t.Fatalf("expected nil payload, got %v", p)
+	}
+
+	candidates := []api.Contact{
+		{ID: 9, Email: "other@example.com"},
+		{ID: 3, Email: "JANE@example.com"},
+	}
+	c, err := row.PickMatch(fields, candidates, "")
+	if err != nil || c == nil || c.ID != 3 {
+		t.Fatalf("PickMatch = %+v, %v", c, err)
+	}
+
+	candidates = append(candidates, api.Contact{ID: 7, PhoneNumber: "020 7946 0958"})
+	if _, err := row.PickMatch(fields, candidates, "44"); err == nil || !strings.Contains(err.Error(), "#3, #7") {
+		t.Fatalf("expected ambiguous match error, got %v", err)
+	}
+	if c, err := row.PickMatch([]string{"identifier"}, candidates, ""); c != nil || err != nil {
+		t.Fatalf("expected no match, got %+v, %v", c, err)
+	}
+
+	if _, err := contactimport.ParseMatch([]string{"email", "name"}); err == nil {
+		t.Fatal("expected invalid match field error")
+	}
+	if fields, _ := contactimport.ParseMatch([]string{"phone", "email", "phone_number"}); !reflect.DeepEqual(fields, []string{"phone_number", "email"}) {
+		t.Fatalf("ParseMatch = %v", fields)
+	}
+}
+
+func TestBatches(t *testing.T) {
+	rows := []contactimport.Row{
+		{Line: 2, Email: "a@example.com"},
+		{Line: 3, Phone: "+15550000001"},
+		{Line: 4, Email: "b@example.com", Phone: "+15550000001"},
+		{Line: 5, Identifier: "x"},
+		{Line: 6, Email: "a@example.com", Identifier: "x"},
+		{Line: 7, Email: "c@example.com"},
+	}
+	var got [][]int
+	for _, b := range contactimport.Batches(rows) {
+		var lines []int
+		for _, r := range b {
+			lines = append(lines, r.Line)
+		}
+		got = append(got, lines)
+	}
+	want := [][]int{{2, 5, 6}, {3, 4}, {7}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Batches = %v, want %v", got, want)
+	}
+}
+
+func TestCheckpointResume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cp", "import.jsonl")
+	cp, err := contactimport.OpenCheckpoint(path, "contacts.csv", "abc", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cp.Record(contactimport.CheckpointEntry{Line: 2, ContactID: 10, Action: contactimport.ActionCreated}); err != nil {
+		t.Fatal(err)
+	}
+	_ = cp.Close()
+
+	// Simulate a torn write from an interrupted run.
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	_, _ = f.WriteString(`{"line":3,"contact_id"`)
+	_ = f.Close()
+
+	cp, err = contactimport.OpenCheckpoint(path, "contacts.csv", "abc", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e, ok := cp.Done(2); !ok || e.ContactID != 10 {
+		t.Fatalf("line 2 not resumed: %+v", e)
+	}
+	if _, ok := cp.Done(3); ok {
+		t.Fatal("torn line 3 should be retried")
+	}
+	if err := cp.Record(contactimport.CheckpointEntry{Line: 3, ContactID: 11, Action: contactimport.ActionUpdated}); err != nil {
+		t.Fatal(err)
+	}
+	_ = cp.Close()
+
+	cp, err = contactimport.OpenCheckpoint(path, "contacts.csv", "abc", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e, ok := cp.Done(3); !ok || e.Action != contactimport.ActionUpdated {
+		t.Fatalf("line 3 not recorded after torn line: %+v", e)
+	}
+	_ = cp.Close()
+
+	if _, err := contactimport.OpenCheckpoint(path, "contacts.csv", "changed", 1); err == nil || !strings.Contains(err.Error(), "has changed") {
+		t.Fatalf("expected changed input error, got %v", err)
+	}
+	if _, err := contactimport.OpenCheckpoint(path, "contacts.csv", "abc", 2); err == nil {
+		t.Fatal("expected account mismatch error")
+	}
+}
+
+func TestWriteErrorReport(t *testing.T) {
+	in, err := contactimport.Read(strings.NewReader(testCSV), contactimport.FormatCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "errors.csv")
+	failures := []contactimport.RowError{{Line: 6, Error: "bad seats"}, {Line: 5, Error: "no keys"}}
+	if err := contactimport.WriteErrorReport(path, in, failures); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"line", "error", "Full Name", "E-mail", "Mobile", "CRM ID", "Plan", "Seats", "Notes"},
+		{"5", "no keys", "No Keys", "", "", "", "", "", ""},
+		{"6", "bad seats", "Bad Seats", "bad@example.com", "", "", "", "many", ""},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Fatalf("report = %q", records)
+	}
+}
@@ -0,0 +1,244 @@
+// Package contactimport turns CSV or JSONL records into contact payloads for
+// an upsert, and keeps the checkpoint and error report that make large imports
+// resumable.
+package contactimport
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Version is the mapping file format version.
+const Version = 1
+
+// Contact fields a column can map to. Custom and additional attributes use
+// "custom_attributes.<key>" and "additional_attributes.<key>".
+const (
+	FieldName       = "name"
+	FieldEmail      = "email"
+	FieldPhone      = "phone_number"
+	FieldIdentifier = "identifier"
+
+	customPrefix     = "custom_attributes."
+	additionalPrefix = "additional_attributes."
+)
+
+// MatchFields are the fields an upsert can match existing contacts on.
+var MatchFields = []string{FieldEmail, FieldPhone, FieldIdentifier}
+
+// Value types for attribute columns, written as a ":<type>" suffix on the
+// target ("custom_attributes.seats:number").
+const (
+	TypeString = "string"
+	TypeNumber = "number"
+	TypeBool   = "bool"
+)
+
+// fieldAliases maps normalized column headers to fields when no mapping file
+// is given.
+var fieldAliases = map[string]string{
+	"name":          FieldName,
+	"full_name":     FieldName,
+	"email":         FieldEmail,
+	"email_address": FieldEmail,
+	"phone":         FieldPhone,
+	"phone_number":  FieldPhone,
+	"mobile":        FieldPhone,
+	"identifier":    FieldIdentifier,
+	"external_id":   FieldIdentifier,
+}
+
+// Mapping assigns input columns (CSV headers or JSONL keys) to contact
+// fields. An empty target ignores the column.
+type Mapping struct {
+	Version int               `yaml:"version" json:"version"`
+	Columns map[string]string `yaml:"columns" json:"columns"`
+
+	targets map[string]target
+}
+
+type target struct {
+	field string // top-level field, or the attribute map for attributes
+	key   string // attribute key
+	typ   string
+}
+
+// Load reads and validates a mapping file (YAML or JSON).
+func Load(path string) (*Mapping, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read mapping: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse decodes and validates a mapping file. Unknown fields are rejected.
+func Parse(data []byte) (*Mapping, error) {
+	var m Mapping
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&m); err != nil {
+		return nil, fmt.Errorf("parse mapping: %w", err)
+	}
+	if m.Version == 0 {
+		m.Version = Version
+	}
+	if m.Version > Version {
+		return nil, fmt.Errorf("mapping version %d is newer than supported version %d", m.Version, Version)
+	}
+	if len(m.Columns) == 0 {
+		return nil, fmt.Errorf("mapping has no columns")
+	}
+	if err := m.compile(); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// DefaultMapping maps columns whose names are contact fields ("email",
+// "phone", "custom_attributes.plan", ...) and returns the other columns as
+// unmapped. When several columns name the same field the first one wins.
+func DefaultMapping(columns []string) (*Mapping, []string) {
+	m := &Mapping{Version: Version, Columns: map[string]string{}, targets: map[string]target{}}
+	used := map[string]bool{}
+	var unmapped []string
+	for _, col := range columns {
+		norm := strings.ToLower(strings.TrimSpace(col))
+		spec := ""
+		switch {
+		case strings.HasPrefix(norm, customPrefix), strings.HasPrefix(norm, additionalPrefix):
+			spec = strings.TrimSpace(col)
+		case norm == "custom_attributes", norm == "additional_attributes":
+			spec = norm
+		default:
+			spec = fieldAliases[strings.NewReplacer(" ", "_", "-", "_").Replace(norm)]
+		}
+		t, err := parseTarget(spec)
+		if spec == "" || err != nil || used[t.field+"."+t.key] {
+			unmapped = append(unmapped, col)
+			continue
+		}
+		used[t.field+"."+t.key] = true
+		m.Columns[col] = spec
+		m.targets[col] = t
+	}
+	return m, unmapped
+}
+
+// compile parses the column targets.
+func (m *Mapping) compile() error {
+	m.targets = map[string]target{}
+	seen := map[string]string{}
+	cols := make([]string, 0, len(m.Columns))
+	for col := range m.Columns {
+		cols = append(cols, col)
+	}
+	sort.Strings(cols)
+	for _, col := range cols {
+		raw := strings.TrimSpace(m.Columns[col])
+		if raw == "" || raw == "-" {
+			continue
+		}
+		t, err := parseTarget(raw)
+		if err != nil {
+			return fmt.Errorf("column %q: %w", col, err)
+		}
+		name := t.field + "." + t.key
+		if prev, ok := seen[name]; ok {
+			return fmt.Errorf("columns %q and %q both map to %s", prev, col, strings.TrimSuffix(name, "."))
+		}
+		seen[name] = col
+		m.targets[col] = t
+	}
+	return nil
+}
+
+func parseTarget(raw string) (target, error) {
+	spec, typ, _ := strings.Cut(raw, ":")
+	switch typ {
+	case "":
+		typ = TypeString
+	case TypeString, TypeNumber, TypeBool:
+	default:
+		return target{}, fmt.Errorf("unknown type %q (valid: string, number, bool)", typ)
+	}
+	switch spec {
+	case FieldName, FieldEmail, FieldPhone, FieldIdentifier:
+		if typ != TypeString {
+			return target{}, fmt.Errorf("%s cannot have a type", spec)
+		}
+		return target{field: spec}, nil
+	case "custom_attributes", "additional_attributes":
+		// A JSON object column merged into the attributes.
+		return target{field: spec, typ: "object"}, nil
+	}
+	for _, prefix := range []string{customPrefix, additionalPrefix} {
+		if key, ok := strings.CutPrefix(spec, prefix); ok {
+			if key == "" {
+				return target{}, fmt.Errorf("%s needs an attribute key", spec)
+			}
+			return target{field: strings.TrimSuffix(prefix, "."), key: key, typ: typ}, nil
+		}
+	}
+	return target{}, fmt.Errorf("unknown field %q (valid: name, email, phone_number, identifier, custom_attributes.<key>, additional_attributes.<key>)", spec)
+}
+
+// MappedColumns returns the input columns that map to a field, sorted.
+func (m *Mapping) MappedColumns() []string {
+	cols := make([]string, 0, len(m.targets))
+	for col := range m.targets {
+		cols = append(cols, col)
+	}
+	sort.Strings(cols)
+	return cols
+}
+
+// convert turns a cell into a typed attribute value.
+func convert(v any, typ string) (any, error) {
+	s, isString := v.(string)
+	switch typ {
+	case TypeNumber:
+		if n, ok := v.(json.Number); ok {
+			if i, err := n.Int64(); err == nil {
+				return i, nil
+			}
+			return n.Float64()
+		}
+		if !isString {
+			if _, ok := v.(float64); ok {
+				return v, nil
+			}
+			return nil, fmt.Errorf("not a number: %v", v)
+		}
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return n, nil
+		}
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("not a number: %q", s)
+		}
+		return f, nil
+	case TypeBool:
+		if !isString {
+			if _, ok := v.(bool); ok {
+				return v, nil
+			}
+			return nil, fmt.Errorf("not a boolean: %v", v)
+		}
+		switch strings.ToLower(s) {
+		case "true", "yes", "y", "1":
+			return true, nil
+		case "false", "no", "n", "0":
+			return false, nil
+		}
+		return nil, fmt.Errorf("not a boolean: %q", s)
+	}
+	return v, nil
+}
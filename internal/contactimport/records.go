@@ -0,0 +1,261 @@
+package contactimport
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/chatwoot/chatwoot-cli/internal/dedupe"
+	"github.com/chatwoot/chatwoot-cli/internal/validation"
+)
+
+// Input formats.
+const (
+	FormatCSV   = "csv"
+	FormatJSONL = "jsonl"
+)
+
+// maxJSONLLine bounds a single JSONL record.
+const maxJSONLLine = 1 << 20
+
+// Record is one input row. Line is where it starts in the file, which is how
+// checkpoints and error reports refer to it.
+type Record struct {
+	Line   int
+	Values map[string]any
+	// Raw holds the original CSV fields, or a single JSON line.
+	Raw []string
+}
+
+// Input is a parsed import file.
+type Input struct {
+	Format  string
+	Columns []string // CSV header, or every key seen in JSONL records
+	Records []Record
+}
+
+// DetectFormat picks the format from the file extension; anything that is not
+// .jsonl/.ndjson is treated as CSV.
+func DetectFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jsonl", ".ndjson":
+		return FormatJSONL
+	}
+	return FormatCSV
+}
+
+// Read parses r as format. CSV input needs a header row. A malformed JSONL
+// line fails the whole read, since the line boundaries are no longer known.
+func Read(r io.Reader, format string) (*Input, error) {
+	switch format {
+	case FormatCSV:
+		return readCSV(r)
+	case FormatJSONL:
+		return readJSONL(r)
+	}
+	return nil, fmt.Errorf("unknown format %q (valid: csv, jsonl)", format)
+}
+
+func readCSV(r io.Reader) (*Input, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("CSV file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read CSV header: %w", err)
+	}
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+	in := &Input{Format: FormatCSV, Columns: header}
+	for {
+		fields, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read CSV: %w", err)
+		}
+		line, _ := cr.FieldPos(0)
+		if len(fields) == 1 && strings.TrimSpace(fields[0]) == "" {
+			continue
+		}
+		values := make(map[string]any, len(header))
+		for i, col := range header {
+			if i < len(fields) {
+				values[col] = fields[i]
+			}
+		}
+		in.Records = append(in.Records, Record{Line: line, Values: values, Raw: fields})
+	}
+	return in, nil
+}
+
+func readJSONL(r io.Reader) (*Input, error) {
+	in := &Input{Format: FormatJSONL}
+	seen := map[string]bool{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxJSONLLine)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		dec := json.NewDecoder(strings.NewReader(text))
+		dec.UseNumber()
+		var values map[string]any
+		if err := dec.Decode(&values); err != nil {
+			return nil, fmt.Errorf("line %d: invalid JSON object: %w", line, err)
+		}
+		for k := range values {
+			if !seen[k] {
+				seen[k] = true
+				in.Columns = append(in.Columns, k)
+			}
+		}
+		in.Records = append(in.Records, Record{Line: line, Values: values, Raw: []string{text}})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read JSONL: %w", err)
+	}
+	sort.Strings(in.Columns)
+	return in, nil
+}
+
+// Row is a record mapped to a contact payload.
+type Row struct {
+	Line int
+	Body map[string]any
+	// Match values, normalized: phone in E.164 form.
+	Email      string
+	Phone      string
+	Identifier string
+}
+
+// Options control how records become rows.
+type Options struct {
+	// CountryCode is used for phone numbers without an international prefix.
+	CountryCode string
+}
+
+// Apply maps a record to a row, validating the values. Empty cells are left
+// out of the payload so an update does not clear existing values.
+func (m *Mapping) Apply(rec Record, opts Options) (Row, error) {
+	row := Row{Line: rec.Line, Body: map[string]any{}}
+	attrs := map[string]map[string]any{}
+	for _, col := range m.MappedColumns() {
+		v, ok := rec.Values[col]
+		if !ok || v == nil {
+			continue
+		}
+		t := m.targets[col]
+		if t.typ == "object" {
+			obj, err := objectValue(v)
+			if err != nil {
+				return row, fmt.Errorf("column %q: %w", col, err)
+			}
+			for k, val := range obj {
+				if attrs[t.field] == nil {
+					attrs[t.field] = map[string]any{}
+				}
+				attrs[t.field][k] = val
+			}
+			continue
+		}
+		if s, isString := v.(string); isString {
+			if s = strings.TrimSpace(s); s == "" {
+				continue
+			}
+			v = s
+		}
+		if t.key == "" {
+			s, err := scalarString(v)
+			if err != nil {
+				return row, fmt.Errorf("column %q: %w", col, err)
+			}
+			row.Body[t.field] = s
+			continue
+		}
+		val, err := convert(v, t.typ)
+		if err != nil {
+			return row, fmt.Errorf("column %q: %w", col, err)
+		}
+		if attrs[t.field] == nil {
+			attrs[t.field] = map[string]any{}
+		}
+		attrs[t.field][t.key] = val
+	}
+	for field, values := range attrs {
+		row.Body[field] = values
+	}
+
+	if name, ok := row.Body[FieldName].(string); ok {
+		if err := validation.ValidateName(name); err != nil {
+			return row, err
+		}
+	}
+	if email, ok := row.Body[FieldEmail].(string); ok {
+		if err := validation.ValidateEmail(email); err != nil {
+			return row, err
+		}
+		if err := validation.ValidateEmailFormat(email); err != nil {
+			return row, err
+		}
+		row.Email = strings.ToLower(email)
+	}
+	if phone, ok := row.Body[FieldPhone].(string); ok {
+		normalized := dedupe.NormalizePhone(phone, opts.CountryCode)
+		if normalized == "" {
+			return row, fmt.Errorf("invalid phone number %q (use international format or --country-code)", phone)
+		}
+		row.Body[FieldPhone] = normalized
+		row.Phone = normalized
+	}
+	row.Identifier, _ = row.Body[FieldIdentifier].(string)
+	if row.Email == "" && row.Phone == "" && row.Identifier == "" {
+		return row, fmt.Errorf("no email, phone_number or identifier to match on")
+	}
+	return row, nil
+}
+
+func scalarString(v any) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return fmt.Sprint(v), nil
+	}
+	return "", fmt.Errorf("expected a text value, got %T", v)
+}
+
+// objectValue accepts a JSON object, or a CSV cell holding one.
+func objectValue(v any) (map[string]any, error) {
+	switch v := v.(type) {
+	case map[string]any:
+		return v, nil
+	case string:
+		if strings.TrimSpace(v) == "" {
+			return nil, nil
+		}
+		dec := json.NewDecoder(bytes.NewReader([]byte(v)))
+		dec.UseNumber()
+		var obj map[string]any
+		if err := dec.Decode(&obj); err != nil {
+			return nil, fmt.Errorf("expected a JSON object: %w", err)
+		}
+		return obj, nil
+	}
+	return nil, fmt.Errorf("expected a JSON object, got %T", v)
+}
@@ -0,0 +1,49 @@
+package contactimport
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+)
+
+// RowError is a row that could not be imported.
+type RowError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// WriteErrorReport writes failed rows to a CSV file: the line and error,
+// followed by the original columns (or the JSON record), so the report can be
+// fixed and imported again.
+func WriteErrorReport(path string, in *Input, failures []RowError) error {
+	records := make(map[int]Record, len(in.Records))
+	for _, rec := range in.Records {
+		records[rec.Line] = rec
+	}
+	sorted := append([]RowError(nil), failures...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Line < sorted[j].Line })
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create error report: %w", err)
+	}
+	w := csv.NewWriter(f)
+	header := []string{"line", "error"}
+	if in.Format == FormatCSV {
+		header = append(header, in.Columns...)
+	} else {
+		header = append(header, "record")
+	}
+	_ = w.Write(header)
+	for _, fail := range sorted {
+		_ = w.Write(append([]string{strconv.Itoa(fail.Line), fail.Error}, records[fail.Line].Raw...))
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("write error report: %w", err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,168 @@
+package contactimport
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+	"github.com/chatwoot/chatwoot-cli/internal/dedupe"
+)
+
+// ParseMatch validates a list of match fields ("phone" is accepted for
+// phone_number).
+func ParseMatch(fields []string) ([]string, error) {
+	var out []string
+	seen := map[string]bool{}
+	for _, f := range fields {
+		f = strings.ToLower(strings.TrimSpace(f))
+		if f == "phone" {
+			f = FieldPhone
+		}
+		if f == "" || seen[f] {
+			continue
+		}
+		valid := false
+		for _, m := range MatchFields {
+			valid = valid || f == m
+		}
+		if !valid {
+			return nil, fmt.Errorf("invalid match field %q (valid: %s)", f, strings.Join(MatchFields, ", "))
+		}
+		seen[f] = true
+		out = append(out, f)
+	}
+	return out, nil
+}
+
+// matchValues returns the row's values for the match fields.
+func (r Row) matchValues(fields []string) map[string]string {
+	values := map[string]string{}
+	for _, f := range fields {
+		switch f {
+		case FieldEmail:
+			values[f] = r.Email
+		case FieldPhone:
+			values[f] = r.Phone
+		case FieldIdentifier:
+			values[f] = r.Identifier
+		}
+		if values[f] == "" {
+			delete(values, f)
+		}
+	}
+	return values
+}
+
+// FilterPayload builds a contacts filter request finding contacts that share
+// any match field with the row. It returns nil when the row has none of them.
+func (r Row) FilterPayload(fields []string) map[string]any {
+	values := r.matchValues(fields)
+	var conditions []map[string]any
+	for _, f := range fields {
+		v, ok := values[f]
+		if !ok {
+			continue
+		}
+		if len(conditions) > 0 {
+			conditions[len(conditions)-1]["query_operator"] = "or"
+		}
+		conditions = append(conditions, map[string]any{
+			"attribute_key":   f,
+			"filter_operator": "equal_to",
+			"values":          []string{v},
+		})
+	}
+	if len(conditions) == 0 {
+		return nil
+	}
+	return map[string]any{"payload": conditions}
+}
+
+// PickMatch chooses the existing contact a row updates from filter results.
+// Candidates are re-checked locally (case-insensitive email, E.164 phone,
+// exact identifier). It returns nil when none match, and an error when the
+// row matches more than one contact, since merging them is not an import's
+// call to make.
+func (r Row) PickMatch(fields []string, candidates []api.Contact, countryCode string) (*api.Contact, error) {
+	values := r.matchValues(fields)
+	var matched []api.Contact
+	seen := map[int]bool{}
+	for _, c := range candidates {
+		if seen[c.ID] {
+			continue
+		}
+		hit := false
+		if v, ok := values[FieldEmail]; ok && strings.EqualFold(strings.TrimSpace(c.Email), v) {
+			hit = true
+		}
+		if v, ok := values[FieldPhone]; ok && dedupe.NormalizePhone(c.PhoneNumber, countryCode) == v {
+			hit = true
+		}
+		if v, ok := values[FieldIdentifier]; ok && c.Identifier == v {
+			hit = true
+		}
+		if hit {
+			seen[c.ID] = true
+			matched = append(matched, c)
+		}
+	}
+	switch len(matched) {
+	case 0:
+		return nil, nil
+	case 1:
+		return &matched[0], nil
+	}
+	ids := make([]string, len(matched))
+	sort.Slice(matched, func(i, j int) bool { return matched[i].ID < matched[j].ID })
+	for i, c := range matched {
+		ids[i] = fmt.Sprintf("#%d", c.ID)
+	}
+	return nil, fmt.Errorf("matches %d contacts (%s); merge them first or narrow --match", len(matched), strings.Join(ids, ", "))
+}
+
+// Batches groups rows that share an email, phone or identifier, keeping file
+// order within each batch. Rows in a batch must be imported one after another:
+// run concurrently, the second would try to create the contact the first is
+// creating.
+func Batches(rows []Row) [][]Row {
+	parent := make([]int, len(rows))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	owner := map[string]int{}
+	for i, r := range rows {
+		for _, key := range []string{"e:" + r.Email, "p:" + r.Phone, "i:" + r.Identifier} {
+			if len(key) == 2 {
+				continue
+			}
+			if j, ok := owner[key]; ok {
+				if a, b := find(i), find(j); a != b {
+					parent[max(a, b)] = min(a, b)
+				}
+			} else {
+				owner[key] = i
+			}
+		}
+	}
+	index := map[int]int{}
+	var batches [][]Row
+	for i, r := range rows {
+		root := find(i)
+		b, ok := index[root]
+		if !ok {
+			b = len(batches)
+			index[root] = b
+			batches = append(batches, nil)
+		}
+		batches[b] = append(batches[b], r)
+	}
+	return batches
+}
@@ -0,0 +1,359 @@
+// Package dedupe finds likely duplicate contacts by normalized phone number,
+// email address and name similarity, and scores each candidate pair.
+package dedupe
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+	"github.com/chatwoot/chatwoot-cli/internal/resolve"
+)
+
+// Reasons a pair was matched (or penalized).
+const (
+	ReasonEmail        = "email"         // same address, ignoring case
+	ReasonEmailAlias   = "email_alias"   // same mailbox after removing +tags / Gmail dots
+	ReasonPhone        = "phone"         // same number in E.164 form
+	ReasonName         = "name"          // same words, ignoring case, accents and order
+	ReasonNameSimilar  = "name_similar"  // names above the similarity threshold
+	ReasonEmailDiffers = "email_differs" // both have an email and they differ
+	ReasonPhoneDiffers = "phone_differs" // both have a phone and they differ
+)
+
+// Score weights. Scores are capped at 100; an email or phone match alone is
+// enough for a likely duplicate, a name alone is not.
+const (
+	scoreEmail      = 70
+	scoreEmailAlias = 60
+	scorePhone      = 70
+	scoreName       = 30
+	penaltyConflict = 20
+	maxScore        = 100
+)
+
+// DefaultNameThreshold is the minimum resolve.NameSimilarity for names to
+// count as matching.
+const DefaultNameThreshold = 0.9
+
+// maxNameBlock skips name prefixes shared by more contacts than this, which
+// keeps very common first names from making the scan quadratic.
+const maxNameBlock = 500
+
+// Options tune matching.
+type Options struct {
+	// CountryCode is the calling code (such as "1" or "44") assumed for
+	// phone numbers stored without one. Without it, such numbers are not
+	// compared.
+	CountryCode string
+	// NameThreshold overrides DefaultNameThreshold.
+	NameThreshold float64
+}
+
+// Pair is a scored duplicate candidate. Keep is the older contact, which
+// survives a merge.
+type Pair struct {
+	Score   int         `json:"score"`
+	Reasons []string    `json:"reasons"`
+	Keep    api.Contact `json:"keep"`
+	Merge   api.Contact `json:"merge"`
+}
+
+// Group is a set of contacts connected by qualifying pairs, with the contact
+// that survives merging them all.
+type Group struct {
+	Keep  api.Contact   `json:"keep"`
+	Merge []api.Contact `json:"merge"`
+	Score int           `json:"score"` // lowest pair score in the group
+	Pairs []Pair        `json:"pairs"`
+}
+
+// NormalizePhone returns the number in E.164 form ("+" followed by digits),
+// or "" when it cannot be normalized. Extensions are dropped. Numbers
+// without an international prefix use countryCode, after removing a national
+// trunk prefix of zeros.
+func NormalizePhone(raw, countryCode string) string {
+	raw = strings.TrimSpace(raw)
+	if i := strings.IndexFunc(raw, unicode.IsLetter); i >= 0 {
+		raw = raw[:i] // "ext", "x", ";ext="
+	}
+	if i := strings.IndexAny(raw, ";,"); i >= 0 {
+		raw = raw[:i]
+	}
+	plus := strings.HasPrefix(raw, "+")
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, raw)
+
+	switch {
+	case plus:
+	case strings.HasPrefix(digits, "00"):
+		digits = digits[2:]
+	default:
+		countryCode = strings.TrimPrefix(strings.TrimSpace(countryCode), "+")
+		if countryCode == "" {
+			return ""
+		}
+		digits = countryCode + strings.TrimLeft(digits, "0")
+	}
+	if len(digits) < 7 || len(digits) > 15 || digits[0] == '0' {
+		return ""
+	}
+	return "+" + digits
+}
+
+// NormalizeEmail returns a key identifying the mailbox behind an address:
+// lowercased, without a "+tag", and for Gmail without dots. It returns ""
+// for values that are not addresses.
+func NormalizeEmail(raw string) string {
+	raw = strings.ToLower(strings.TrimSpace(raw))
+	at := strings.LastIndexByte(raw, '@')
+	if at <= 0 || at == len(raw)-1 {
+		return ""
+	}
+	local, domain := raw[:at], raw[at+1:]
+	if i := strings.IndexByte(local, '+'); i > 0 {
+		local = local[:i]
+	}
+	if domain == "googlemail.com" {
+		domain = "gmail.com"
+	}
+	if domain == "gmail.com" {
+		local = strings.ReplaceAll(local, ".", "")
+	}
+	return local + "@" + domain
+}
+
+type entry struct {
+	contact api.Contact
+	email   string
+	phone   string
+	name    string
+	words   []string
+}
+
+// Find returns every candidate pair among contacts, best first.
+func Find(contacts []api.Contact, opts Options) []Pair {
+	threshold := opts.NameThreshold
+	if threshold <= 0 {
+		threshold = DefaultNameThreshold
+	}
+
+	entries := make([]entry, len(contacts))
+	byEmail := map[string][]int{}
+	byPhone := map[string][]int{}
+	byName := map[string][]int{}
+	for i, c := range contacts {
+		e := entry{contact: c, email: NormalizeEmail(c.Email), phone: NormalizePhone(c.PhoneNumber, opts.CountryCode)}
+		if name := strings.TrimSpace(c.Name); !generatedName(name, c) {
+			e.name = name
+			e.words = resolve.NormalizeName(name)
+		}
+		entries[i] = e
+		if e.email != "" {
+			byEmail[e.email] = append(byEmail[e.email], i)
+		}
+		if e.phone != "" {
+			byPhone[e.phone] = append(byPhone[e.phone], i)
+		}
+		for _, key := range nameBlockKeys(e.words) {
+			byName[key] = append(byName[key], i)
+		}
+	}
+
+	seen := map[[2]int]bool{}
+	var pairs []Pair
+	consider := func(block []int) {
+		for x := 0; x < len(block); x++ {
+			for y := x + 1; y < len(block); y++ {
+				i, j := block[x], block[y]
+				if i > j {
+					i, j = j, i
+				}
+				if seen[[2]int{i, j}] || entries[i].contact.ID == entries[j].contact.ID {
+					continue
+				}
+				seen[[2]int{i, j}] = true
+				if p, ok := score(entries[i], entries[j], threshold); ok {
+					pairs = append(pairs, p)
+				}
+			}
+		}
+	}
+	for _, index := range []map[string][]int{byEmail, byPhone, byName} {
+		keys := make([]string, 0, len(index))
+		for k := range index {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if block := index[k]; len(block) > 1 && (len(block) <= maxNameBlock || !strings.HasPrefix(k, "n:")) {
+				consider(block)
+			}
+		}
+	}
+
+	sort.SliceStable(pairs, func(a, b int) bool {
+		if pairs[a].Score != pairs[b].Score {
+			return pairs[a].Score > pairs[b].Score
+		}
+		if pairs[a].Keep.ID != pairs[b].Keep.ID {
+			return pairs[a].Keep.ID < pairs[b].Keep.ID
+		}
+		return pairs[a].Merge.ID < pairs[b].Merge.ID
+	})
+	return pairs
+}
+
+// nameBlockKeys groups contacts whose names share a word prefix, so only
+// those are compared by name.
+func nameBlockKeys(words []string) []string {
+	var keys []string
+	for _, w := range words {
+		if len([]rune(w)) < 2 {
+			continue
+		}
+		r := []rune(w)
+		keys = append(keys, "n:"+string(r[:min(4, len(r))]))
+	}
+	return keys
+}
+
+// generatedName reports names Chatwoot derives from the email or phone
+// number, which carry no extra signal.
+func generatedName(name string, c api.Contact) bool {
+	if name == "" {
+		return true
+	}
+	if strings.EqualFold(name, c.PhoneNumber) || strings.EqualFold(name, c.Email) {
+		return true
+	}
+	if at := strings.IndexByte(c.Email, '@'); at > 0 && strings.EqualFold(name, c.Email[:at]) {
+		return true
+	}
+	return strings.IndexFunc(name, unicode.IsLetter) < 0
+}
+
+func score(a, b entry, threshold float64) (Pair, bool) {
+	total := 0
+	var reasons []string
+	matched := false
+
+	switch {
+	case a.email != "" && a.email == b.email:
+		matched = true
+		if strings.EqualFold(strings.TrimSpace(a.contact.Email), strings.TrimSpace(b.contact.Email)) {
+			total += scoreEmail
+			reasons = append(reasons, ReasonEmail)
+		} else {
+			total += scoreEmailAlias
+			reasons = append(reasons, ReasonEmailAlias)
+		}
+	case a.email != "" && b.email != "":
+		total -= penaltyConflict
+		reasons = append(reasons, ReasonEmailDiffers)
+	}
+
+	switch {
+	case a.phone != "" && a.phone == b.phone:
+		matched = true
+		total += scorePhone
+		reasons = append(reasons, ReasonPhone)
+	case a.phone != "" && b.phone != "":
+		total -= penaltyConflict
+		reasons = append(reasons, ReasonPhoneDiffers)
+	}
+
+	if len(a.words) > 0 && len(b.words) > 0 {
+		if sim := resolve.NameSimilarity(a.name, b.name); sim >= threshold {
+			matched = true
+			total += int(float64(scoreName)*sim + 0.5)
+			if sim == 1 {
+				reasons = append(reasons, ReasonName)
+			} else {
+				reasons = append(reasons, ReasonNameSimilar)
+			}
+		}
+	}
+
+	if !matched || total <= 0 {
+		return Pair{}, false
+	}
+	keep, merge := a.contact, b.contact
+	if olderContact(merge, keep) {
+		keep, merge = merge, keep
+	}
+	return Pair{Score: min(total, maxScore), Reasons: reasons, Keep: keep, Merge: merge}, true
+}
+
+// olderContact orders contacts by creation time, then ID.
+func olderContact(a, b api.Contact) bool {
+	if a.CreatedAt != b.CreatedAt && a.CreatedAt > 0 && b.CreatedAt > 0 {
+		return a.CreatedAt < b.CreatedAt
+	}
+	return a.ID < b.ID
+}
+
+// Groups joins pairs scoring at least minScore into connected groups. Each
+// group keeps its oldest contact and merges the rest into it.
+func Groups(pairs []Pair, minScore int) []Group {
+	parent := map[int]int{}
+	var find func(int) int
+	find = func(id int) int {
+		if p, ok := parent[id]; ok && p != id {
+			parent[id] = find(p)
+			return parent[id]
+		}
+		parent[id] = id
+		return id
+	}
+
+	contacts := map[int]api.Contact{}
+	var qualifying []Pair
+	for _, p := range pairs {
+		if p.Score < minScore {
+			continue
+		}
+		qualifying = append(qualifying, p)
+		contacts[p.Keep.ID], contacts[p.Merge.ID] = p.Keep, p.Merge
+		ra, rb := find(p.Keep.ID), find(p.Merge.ID)
+		if ra != rb {
+			parent[rb] = ra
+		}
+	}
+
+	byRoot := map[int]*Group{}
+	var roots []int
+	for _, p := range qualifying {
+		root := find(p.Keep.ID)
+		g := byRoot[root]
+		if g == nil {
+			g = &Group{Score: p.Score}
+			byRoot[root] = g
+			roots = append(roots, root)
+		}
+		g.Pairs = append(g.Pairs, p)
+		g.Score = min(g.Score, p.Score)
+	}
+
+	groups := make([]Group, 0, len(roots))
+	for _, root := range roots {
+		g := byRoot[root]
+		ids := map[int]bool{}
+		for _, p := range g.Pairs {
+			ids[p.Keep.ID], ids[p.Merge.ID] = true, true
+		}
+		members := make([]api.Contact, 0, len(ids))
+		for id := range ids {
+			members = append(members, contacts[id])
+		}
+		sort.Slice(members, func(i, j int) bool { return olderContact(members[i], members[j]) })
+		g.Keep, g.Merge = members[0], members[1:]
+		groups = append(groups, *g)
+	}
+	return groups
+}
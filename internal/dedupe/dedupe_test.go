@@ -0,0 +1,153 @@
+package dedupe_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+	"github.com/chatwoot/chatwoot-cli/internal/dedupe"
+)
+
+func TestNormalizePhone(t *testing.T) {
+	tests := []struct {
+		raw, country, want string
+	}{
+		{"+1 (415) 555-0100", "", "+14155550100"},
+		{"0044 20 7946 0958", "", "+442079460958"},
+		{"020 7946 0958", "44", "+442079460958"},
+		{"(415) 555-0100 ext. 12", "+1", "+14155550100"},
+		{"415-555-0100", "", ""},
+		{"+12", "", ""},
+		{"", "1", ""},
+	}
+	for _, tt := range tests {
+		if got := dedupe.NormalizePhone(tt.raw, tt.country); got != tt.want {
+			t.Errorf("NormalizePhone(%q, %q) = %q, want %q", tt.raw, tt.country, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeEmail(t *testing.T) {
+	tests := map[string]string{
+		" Jane.Doe@Example.com ":         "jane.doe@example.com",
+		"jane+billing@example.com":       "jane@example.com",
+		"J.A.N.E.doe+x@googlemail.com":   "janedoe@gmail.com",
+		"not-an-address":                 "",
+		"@example.com":                   "",
+		"jane@":                          "",
+		"quoted\"@\"name@example.com":    "quoted\"@\"name@example.com",
+		"UPPER.dots@gmail.com":           "upperdots@gmail.com",
+		"first.last+tag+more@Gmail.COM ": "firstlast@gmail.com",
+	}
+	for raw, want := range tests {
+		if got := dedupe.NormalizeEmail(raw); got != want {
+			t.Errorf("NormalizeEmail(%q) = %q, want %q", raw, got, want)
+		}
+	}
+}
+
+var testContacts = []api.Contact{
+	{ID: 1, Name: "Jane Doe", Email: "jane@example.com", CreatedAt: 100},
+	{ID: 2, Name: "jane doe", Email: "Jane@Example.com", CreatedAt: 200},
+	{ID: 3, Name: "J. Doe", Email: "jane+shop@example.com", PhoneNumber: "+44 20 7946 0958", CreatedAt: 50},
+	{ID: 4, Name: "Bob Stone", PhoneNumber: "020 7946 0958", CreatedAt: 300},
+	{ID: 5, Name: "Jonathan Smith", Email: "jon@one.example", CreatedAt: 400},
+	{ID: 6, Name: "Jonathon Smith", Email: "jon@two.example", CreatedAt: 500},
+	{ID: 7, Name: "+447700900123", PhoneNumber: "+447700900123", CreatedAt: 600},
+	{ID: 8, Name: "+447700900123", PhoneNumber: "+447700900999", CreatedAt: 700},
+}
+
+func TestFind(t *testing.T) {
+	pairs := dedupe.Find(testContacts, dedupe.Options{CountryCode: "44"})
+
+	type result struct {
+		keep, merge, score int
+		reasons            []string
+	}
+	var got []result
+	for _, p := range pairs {
+		got = append(got, result{p.Keep.ID, p.Merge.ID, p.Score, p.Reasons})
+	}
+	want := []result{
+		{1, 2, 100, []string{dedupe.ReasonEmail, dedupe.ReasonName}},
+		{3, 1, 88, []string{dedupe.ReasonEmailAlias, dedupe.ReasonNameSimilar}},
+		{3, 2, 88, []string{dedupe.ReasonEmailAlias, dedupe.ReasonNameSimilar}},
+		{3, 4, 70, []string{dedupe.ReasonPhone}},
+		{5, 6, 9, []string{dedupe.ReasonEmailDiffers, dedupe.ReasonNameSimilar}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Find =\n%v\nwant\n%v", got, want)
+	}
+
+	// Without a default country the national number cannot be compared.
+	for _, p := range dedupe.Find(testContacts, dedupe.Options{}) {
+		if p.Merge.ID == 4 {
+			t.Fatalf("unexpected pair without country code: %+v", p)
+		}
+	}
+}
+
+func TestGroups(t *testing.T) {
+	pairs := dedupe.Find(testContacts, dedupe.Options{CountryCode: "44"})
+
+	groups := dedupe.Groups(pairs, 60)
+	if len(groups) != 1 {
+		t.Fatalf("expected one group, got %+v", groups)
+	}
+	g := groups[0]
+	var merge []int
+	for _, c := range g.Merge {
+		merge = append(merge, c.ID)
+	}
+	if g.Keep.ID != 3 || !reflect.DeepEqual(merge, []int{1, 2, 4}) || g.Score != 70 || len(g.Pairs) != 4 {
+		t.Fatalf("unexpected group: keep %d merge %v score %d pairs %d", g.Keep.ID, merge, g.Score, len(g.Pairs))
+	}
+
+	groups = dedupe.Groups(pairs, 100)
+	if len(groups) != 1 || groups[0].Keep.ID != 1 || len(groups[0].Merge) != 1 || groups[0].Merge[0].ID != 2 {
+		t.Fatalf("unexpected groups at 100: %+v", groups)
+	}
+}
+
+func TestUndoLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "undo.jsonl")
+	log, err := dedupe.OpenUndoLog(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := dedupe.UndoRecord{
+		Time:    time.Unix(1700000000, 0).UTC(),
+		KeepID:  1,
+		Score:   100,
+		Reasons: []string{dedupe.ReasonEmail},
+		Deleted: api.Contact{ID: 2, Name: "jane doe", Email: "Jane@Example.com", CustomAttributes: map[string]any{"plan": "pro"}},
+	}
+	if err := log.Write(rec); err != nil {
+		t.Fatal(err)
+	}
+	if err := log.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+	scanner := bufio.NewScanner(f)
+	if !scanner.Scan() {
+		t.Fatal("undo log is empty")
+	}
+	var got dedupe.UndoRecord
+	if err := json.Unmarshal(scanner.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, rec) {
+		t.Fatalf("round trip = %+v, want %+v", got, rec)
+	}
+}
@@ -0,0 +1,75 @@
+package dedupe
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+)
+
+// UndoFileName is the undo log file name under the config directory, next to
+// the change journal.
+const UndoFileName = "contacts-dedupe-undo.jsonl"
+
+// DefaultUndoPath returns the undo log path under dir.
+func DefaultUndoPath(dir string) string {
+	return filepath.Join(dir, UndoFileName)
+}
+
+// UndoRecord preserves a contact deleted by a merge. Chatwoot cannot undo a
+// merge, but the record holds what is needed to recreate the contact.
+type UndoRecord struct {
+	Time      time.Time   `json:"time"`
+	AccountID int         `json:"account_id,omitempty"`
+	KeepID    int         `json:"keep_id"`
+	Score     int         `json:"score"`
+	Reasons   []string    `json:"reasons,omitempty"`
+	Deleted   api.Contact `json:"deleted"`
+	Labels    []string    `json:"labels,omitempty"`
+}
+
+// UndoLog appends undo records to a JSONL file.
+type UndoLog struct {
+	file *os.File
+	path string
+}
+
+// OpenUndoLog opens (creating if needed) the undo log at path for appending.
+func OpenUndoLog(path string) (*UndoLog, error) {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return nil, fmt.Errorf("create undo log directory: %w", err)
+		}
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("open undo log: %w", err)
+	}
+	return &UndoLog{file: f, path: path}, nil
+}
+
+// Path returns the undo log location.
+func (l *UndoLog) Path() string {
+	return l.path
+}
+
+// Write appends one record as a JSON line and syncs it to disk, since it is
+// the only copy of the deleted contact.
+func (l *UndoLog) Write(r UndoRecord) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	if _, err := l.file.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	return l.file.Sync()
+}
+
+// Close closes the underlying file.
+func (l *UndoLog) Close() error {
+	return l.file.Close()
+}
@@ -0,0 +1,171 @@
+package devserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/coder/websocket"
+)
+
+const cableProtocol = "actioncable-v1-json"
+
+// pingInterval is how often clients are pinged, as ActionCable does.
+var pingInterval = 3 * time.Second
+
+// cableSendBuffer is how many frames may be queued for a client. A client
+// that falls this far behind is disconnected rather than stalling the API.
+const cableSendBuffer = 256
+
+// cableFrame is an ActionCable frame in either direction.
+type cableFrame struct {
+	Type       string          `json:"type,omitempty"`
+	Command    string          `json:"command,omitempty"`
+	Identifier string          `json:"identifier,omitempty"`
+	Message    json.RawMessage `json:"message,omitempty"`
+	Data       string          `json:"data,omitempty"`
+}
+
+// cableHub tracks connected clients and their subscriptions.
+type cableHub struct {
+	mu    sync.Mutex
+	conns map[*cableConn]bool
+}
+
+type cableConn struct {
+	send        chan []byte
+	cancel      context.CancelFunc
+	identifiers map[string]bool // guarded by cableHub.mu
+}
+
+func newCableHub() *cableHub {
+	return &cableHub{conns: map[*cableConn]bool{}}
+}
+
+// enqueue queues f for c without blocking; the caller holds h.mu.
+func (h *cableHub) enqueue(c *cableConn, f cableFrame) {
+	data, _ := json.Marshal(f)
+	select {
+	case c.send <- data:
+	default:
+		delete(h.conns, c)
+		c.cancel()
+	}
+}
+
+// broadcast sends an event to every subscription. It is called with the
+// server's state locked, so it never blocks on a client.
+func (h *cableHub) broadcast(event string, data any) {
+	message, err := json.Marshal(map[string]any{"event": event, "data": data})
+	if err != nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for c := range h.conns {
+		for id := range c.identifiers {
+			h.enqueue(c, cableFrame{Identifier: id, Message: message})
+		}
+	}
+}
+
+func (h *cableHub) closeAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for c := range h.conns {
+		delete(h.conns, c)
+		c.cancel()
+	}
+}
+
+// serveCable speaks enough of the ActionCable protocol for RoomChannel
+// subscriptions: welcome, pings, subscribe and unsubscribe.
+func (s *Server) serveCable(w http.ResponseWriter, r *http.Request) {
+	conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{
+		Subprotocols: []string{cableProtocol},
+	})
+	if err != nil {
+		return
+	}
+	defer func() { _ = conn.CloseNow() }()
+	if conn.Subprotocol() != cableProtocol {
+		_ = conn.Close(websocket.StatusPolicyViolation, "unsupported subprotocol")
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	c := &cableConn{
+		send:        make(chan []byte, cableSendBuffer),
+		cancel:      cancel,
+		identifiers: map[string]bool{},
+	}
+	h := s.cable
+	h.mu.Lock()
+	h.conns[c] = true
+	h.enqueue(c, cableFrame{Type: "welcome"})
+	h.mu.Unlock()
+	defer func() {
+		h.mu.Lock()
+		delete(h.conns, c)
+		h.mu.Unlock()
+	}()
+
+	go func() {
+		defer cancel()
+		ticker := time.NewTicker(pingInterval)
+		defer ticker.Stop()
+		for {
+			var data []byte
+			select {
+			case <-ctx.Done():
+				return
+			case data = <-c.send:
+			case t := <-ticker.C:
+				data, _ = json.Marshal(map[string]any{"type": "ping", "message": t.Unix()})
+			}
+			if err := conn.Write(ctx, websocket.MessageText, data); err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		_, data, err := conn.Read(ctx)
+		if err != nil {
+			return
+		}
+		var f cableFrame
+		if err := json.Unmarshal(data, &f); err != nil {
+			continue
+		}
+		h.mu.Lock()
+		switch f.Command {
+		case "subscribe":
+			if s.validSubscription(f.Identifier) {
+				c.identifiers[f.Identifier] = true
+				h.enqueue(c, cableFrame{Type: "confirm_subscription", Identifier: f.Identifier})
+			} else {
+				h.enqueue(c, cableFrame{Type: "reject_subscription", Identifier: f.Identifier})
+			}
+		case "unsubscribe":
+			delete(c.identifiers, f.Identifier)
+		}
+		h.mu.Unlock()
+	}
+}
+
+// validSubscription accepts RoomChannel identifiers carrying the user's
+// pubsub token.
+func (s *Server) validSubscription(identifier string) bool {
+	var id struct {
+		Channel     string `json:"channel"`
+		PubsubToken string `json:"pubsub_token"`
+	}
+	if err := json.Unmarshal([]byte(identifier), &id); err != nil {
+		return false
+	}
+	return id.Channel == "RoomChannel" && id.PubsubToken == s.pubsubToken
+}
@@ -0,0 +1,92 @@
+package devserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chatwoot/chatwoot-cli/internal/actioncable"
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+)
+
+func connectCable(t *testing.T, ctx context.Context, pubsubToken string) (*actioncable.Client, *api.Client, error) {
+	t.Helper()
+	t.Setenv("CHATWOOT_TESTING", "1")
+	srv, err := New(DefaultFixture(), DefaultToken)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	ts := httptest.NewServer(srv)
+	t.Cleanup(func() {
+		srv.Close()
+		ts.Close()
+	})
+
+	cable, err := actioncable.Connect(ctx, "ws"+strings.TrimPrefix(ts.URL, "http")+"/cable")
+	if err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	t.Cleanup(func() { _ = cable.Close() })
+	err = cable.Subscribe(ctx, actioncable.ChannelID{
+		Channel:     "RoomChannel",
+		PubsubToken: pubsubToken,
+		AccountID:   srv.AccountID(),
+	})
+	return cable, api.New(ts.URL, DefaultToken, srv.AccountID()), err
+}
+
+func TestCableBroadcastsChanges(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	cable, client, err := connectCable(t, ctx, DefaultPubsubToken)
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	events := cable.Listen(ctx)
+
+	if _, err := client.Messages().Create(ctx, 2, "Refund issued", false, "outgoing"); err != nil {
+		t.Fatalf("Create message: %v", err)
+	}
+	if _, err := client.Conversations().ToggleStatus(ctx, 2, "resolved", 0); err != nil {
+		t.Fatalf("ToggleStatus: %v", err)
+	}
+
+	type event struct {
+		Event string         `json:"event"`
+		Data  map[string]any `json:"data"`
+	}
+	var got []event
+	for len(got) < 2 {
+		select {
+		case ev := <-events:
+			if ev.Err != nil {
+				t.Fatalf("listen: %v", ev.Err)
+			}
+			var e event
+			if err := json.Unmarshal(ev.Data, &e); err != nil {
+				t.Fatalf("decode event: %v", err)
+			}
+			got = append(got, e)
+		case <-ctx.Done():
+			t.Fatalf("timed out; got %+v", got)
+		}
+	}
+
+	if got[0].Event != "message.created" || got[0].Data["content"] != "Refund issued" {
+		t.Errorf("first event = %+v, want message.created", got[0])
+	}
+	if got[1].Event != "conversation.status_changed" || got[1].Data["status"] != "resolved" {
+		t.Errorf("second event = %+v, want conversation.status_changed to resolved", got[1])
+	}
+}
+
+func TestCableRejectsWrongPubsubToken(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, _, err := connectCable(t, ctx, "wrong"); err == nil || !strings.Contains(err.Error(), "rejected") {
+		t.Errorf("Subscribe err = %v, want rejection", err)
+	}
+}
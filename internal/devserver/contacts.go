@@ -0,0 +1,395 @@
+package devserver
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+)
+
+func (s *Server) contact(r *http.Request) (*api.Contact, error) {
+	id, err := pathID(r, "id", "contact")
+	if err != nil {
+		return nil, err
+	}
+	c := s.contacts[id]
+	if c == nil {
+		return nil, errNotFound("contact", id)
+	}
+	return c, nil
+}
+
+// contactPage renders one page of list in the list endpoint's shape.
+func contactPage(list []*api.Contact, pageNum int) map[string]any {
+	items, totalPages := page(list, pageNum, contactsPerPage)
+	payload := make([]api.Contact, 0, len(items))
+	for _, c := range items {
+		payload = append(payload, *c)
+	}
+	return map[string]any{
+		"meta": map[string]any{
+			"count":        len(list),
+			"current_page": pageNum,
+			"total_pages":  totalPages,
+		},
+		"payload": payload,
+	}
+}
+
+func (s *Server) sortedContacts(keep func(*api.Contact) bool) []*api.Contact {
+	list := []*api.Contact{}
+	for _, id := range sortedKeys(s.contacts) {
+		if c := s.contacts[id]; keep == nil || keep(c) {
+			list = append(list, c)
+		}
+	}
+	return list
+}
+
+// listContacts sorts by ?sort (name, email, phone_number, created_at or
+// last_activity_at; a leading "-" or ?order=desc reverses it), by name
+// by default.
+func (s *Server) listContacts(r *http.Request) (any, error) {
+	q := r.URL.Query()
+	field := q.Get("sort")
+	desc := strings.HasPrefix(field, "-")
+	field = strings.TrimPrefix(field, "-")
+	switch strings.ToLower(q.Get("order")) {
+	case "asc":
+		desc = false
+	case "desc":
+		desc = true
+	}
+
+	var key func(c *api.Contact) string
+	switch field {
+	case "", "name":
+		key = func(c *api.Contact) string { return strings.ToLower(c.Name) }
+	case "email":
+		key = func(c *api.Contact) string { return strings.ToLower(c.Email) }
+	case "phone_number":
+		key = func(c *api.Contact) string { return c.PhoneNumber }
+	case "created_at":
+		key = func(c *api.Contact) string { return fmt.Sprintf("%020d", c.CreatedAt) }
+	case "last_activity_at":
+		key = func(c *api.Contact) string {
+			if c.LastActivityAt == nil {
+				return ""
+			}
+			return fmt.Sprintf("%020d", *c.LastActivityAt)
+		}
+	default:
+		return nil, errInvalid("invalid sort %q", field)
+	}
+
+	list := s.sortedContacts(nil)
+	sort.SliceStable(list, func(i, j int) bool {
+		if desc {
+			return key(list[i]) > key(list[j])
+		}
+		return key(list[i]) < key(list[j])
+	})
+	return contactPage(list, queryInt(r, "page", 1)), nil
+}
+
+func (s *Server) searchContacts(r *http.Request) (any, error) {
+	term := strings.TrimSpace(r.URL.Query().Get("q"))
+	list := s.sortedContacts(func(c *api.Contact) bool {
+		return term != "" && (containsFold(c.Name, term) || containsFold(c.Email, term) ||
+			containsFold(c.PhoneNumber, term) || containsFold(c.Identifier, term))
+	})
+	return contactPage(list, queryInt(r, "page", 1)), nil
+}
+
+func (s *Server) filterContacts(r *http.Request) (any, error) {
+	var req struct {
+		Payload []filterCondition `json:"payload"`
+	}
+	if err := decodeBody(r, &req); err != nil {
+		return nil, err
+	}
+	var filterErr error
+	list := s.sortedContacts(func(c *api.Contact) bool {
+		ok, err := matchFilter(req.Payload, func(key string) []string {
+			return s.contactAttribute(c, key)
+		})
+		if err != nil {
+			filterErr = err
+		}
+		return ok
+	})
+	if filterErr != nil {
+		return nil, filterErr
+	}
+	return contactPage(list, queryInt(r, "page", 1)), nil
+}
+
+// contactAttribute returns the values of a filterable attribute; other keys
+// are looked up in the custom attributes.
+func (s *Server) contactAttribute(c *api.Contact, key string) []string {
+	switch key {
+	case "id":
+		return []string{strconv.Itoa(c.ID)}
+	case "name":
+		return []string{c.Name}
+	case "email":
+		return []string{c.Email}
+	case "phone_number":
+		return []string{c.PhoneNumber}
+	case "identifier":
+		return []string{c.Identifier}
+	case "labels":
+		return s.contactLabels[c.ID]
+	}
+	if v, ok := c.CustomAttributes[key]; ok {
+		return []string{fmt.Sprint(v)}
+	}
+	return nil
+}
+
+func (s *Server) getContact(r *http.Request) (any, error) {
+	c, err := s.contact(r)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"payload": c}, nil
+}
+
+// checkContactUnique rejects an email or identifier another contact has.
+func (s *Server) checkContactUnique(c *api.Contact) error {
+	for _, other := range s.contacts {
+		if other.ID == c.ID {
+			continue
+		}
+		if c.Email != "" && strings.EqualFold(other.Email, c.Email) {
+			return errInvalid("Email has already been taken")
+		}
+		if c.Identifier != "" && other.Identifier == c.Identifier {
+			return errInvalid("Identifier has already been taken")
+		}
+	}
+	return nil
+}
+
+func (s *Server) createContact(r *http.Request) (any, error) {
+	body := map[string]any{}
+	if err := decodeBody(r, &body); err != nil {
+		return nil, err
+	}
+	inboxID := anyInt(body["inbox_id"])
+	c := &api.Contact{}
+	if err := patch(c, body); err != nil {
+		return nil, err
+	}
+	if err := s.checkContactUnique(c); err != nil {
+		return nil, err
+	}
+	var contactInbox map[string]any
+	if inboxID != 0 {
+		in := s.inboxes[inboxID]
+		if in == nil {
+			return nil, errInvalid("inbox %d not found", inboxID)
+		}
+		contactInbox = map[string]any{"inbox": in}
+	}
+
+	c.ID = s.nextID("contact")
+	c.CreatedAt = s.now().Unix()
+	s.contacts[c.ID] = c
+	if contactInbox != nil {
+		contactInbox["source_id"] = sourceID(c.ID, inboxID)
+	}
+	s.cable.broadcast("contact.created", c)
+	return map[string]any{"payload": map[string]any{"contact": c, "contact_inbox": contactInbox}}, nil
+}
+
+func sourceID(contactID, inboxID int) string {
+	return fmt.Sprintf("dev-%d-%d", contactID, inboxID)
+}
+
+func (s *Server) updateContact(r *http.Request) (any, error) {
+	c, err := s.contact(r)
+	if err != nil {
+		return nil, err
+	}
+	body := map[string]any{}
+	if err := decodeBody(r, &body); err != nil {
+		return nil, err
+	}
+	updated := *c
+	if err := patch(&updated, body); err != nil {
+		return nil, err
+	}
+	if err := s.checkContactUnique(&updated); err != nil {
+		return nil, err
+	}
+	*c = updated
+	s.cable.broadcast("contact.updated", c)
+	return map[string]any{"payload": c}, nil
+}
+
+// deleteContact removes the contact with its conversations, as Chatwoot does.
+func (s *Server) deleteContact(r *http.Request) (any, error) {
+	c, err := s.contact(r)
+	if err != nil {
+		return nil, err
+	}
+	for id, conv := range s.conversations {
+		if conv.ContactID == c.ID {
+			delete(s.conversations, id)
+			delete(s.messages, id)
+		}
+	}
+	delete(s.contacts, c.ID)
+	delete(s.contactLabels, c.ID)
+	delete(s.contactNotes, c.ID)
+	return nil, nil
+}
+
+func (s *Server) contactConversations(r *http.Request) (any, error) {
+	c, err := s.contact(r)
+	if err != nil {
+		return nil, err
+	}
+	list := s.sortedConversations(func(conv *api.Conversation) bool { return conv.ContactID == c.ID })
+	return map[string]any{"payload": s.conversationsJSON(list)}, nil
+}
+
+// contactLabelsJSON renders a contact's labels. Chatwoot's other label
+// endpoints use payload; the CLI reads labels.
+func contactLabelsJSON(labels []string) map[string]any {
+	labels = nonNil(labels)
+	return map[string]any{"payload": labels, "labels": labels}
+}
+
+func (s *Server) contactLabelsHandler(r *http.Request) (any, error) {
+	c, err := s.contact(r)
+	if err != nil {
+		return nil, err
+	}
+	return contactLabelsJSON(s.contactLabels[c.ID]), nil
+}
+
+// setContactLabels replaces the contact's labels, as Chatwoot does.
+func (s *Server) setContactLabels(r *http.Request) (any, error) {
+	c, err := s.contact(r)
+	if err != nil {
+		return nil, err
+	}
+	var req struct {
+		Labels []string `json:"labels"`
+	}
+	if err := decodeBody(r, &req); err != nil {
+		return nil, err
+	}
+	s.contactLabels[c.ID] = uniqueLabels(req.Labels)
+	return contactLabelsJSON(s.contactLabels[c.ID]), nil
+}
+
+// contactableInboxes lists every inbox; offline, any contact can be reached
+// through any of them.
+func (s *Server) contactableInboxes(r *http.Request) (any, error) {
+	c, err := s.contact(r)
+	if err != nil {
+		return nil, err
+	}
+	payload := []map[string]any{}
+	for _, id := range sortedKeys(s.inboxes) {
+		payload = append(payload, map[string]any{"source_id": sourceID(c.ID, id), "inbox": s.inboxes[id]})
+	}
+	return map[string]any{"payload": payload}, nil
+}
+
+func (s *Server) listContactNotes(r *http.Request) (any, error) {
+	c, err := s.contact(r)
+	if err != nil {
+		return nil, err
+	}
+	return nonNil(s.contactNotes[c.ID]), nil
+}
+
+func (s *Server) createContactNote(r *http.Request) (any, error) {
+	c, err := s.contact(r)
+	if err != nil {
+		return nil, err
+	}
+	var req struct {
+		Content string `json:"content"`
+	}
+	if err := decodeBody(r, &req); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(req.Content) == "" {
+		return nil, errInvalid("Content can't be blank")
+	}
+	user := *s.agents[s.userID]
+	note := api.ContactNote{
+		ID:        s.nextID("note"),
+		Content:   req.Content,
+		ContactID: c.ID,
+		UserID:    user.ID,
+		User:      &user,
+		CreatedAt: s.now().UTC().Format(time.RFC3339),
+	}
+	s.contactNotes[c.ID] = append(s.contactNotes[c.ID], note)
+	return note, nil
+}
+
+func (s *Server) deleteContactNote(r *http.Request) (any, error) {
+	c, err := s.contact(r)
+	if err != nil {
+		return nil, err
+	}
+	id, err := pathID(r, "note", "note")
+	if err != nil {
+		return nil, err
+	}
+	notes := s.contactNotes[c.ID]
+	for i, n := range notes {
+		if n.ID == id {
+			s.contactNotes[c.ID] = append(notes[:i:i], notes[i+1:]...)
+			return nil, nil
+		}
+	}
+	return nil, errNotFound("note", id)
+}
+
+// mergeContacts moves the mergee's conversations, labels and notes to the
+// base contact and deletes the mergee.
+func (s *Server) mergeContacts(r *http.Request) (any, error) {
+	var req struct {
+		BaseContactID   int `json:"base_contact_id"`
+		MergeeContactID int `json:"mergee_contact_id"`
+	}
+	if err := decodeBody(r, &req); err != nil {
+		return nil, err
+	}
+	base, mergee := s.contacts[req.BaseContactID], s.contacts[req.MergeeContactID]
+	switch {
+	case base == nil:
+		return nil, errNotFound("contact", req.BaseContactID)
+	case mergee == nil:
+		return nil, errNotFound("contact", req.MergeeContactID)
+	case base == mergee:
+		return nil, errInvalid("cannot merge a contact into itself")
+	}
+	for _, conv := range s.conversations {
+		if conv.ContactID == mergee.ID {
+			conv.ContactID = base.ID
+		}
+	}
+	s.contactLabels[base.ID] = uniqueLabels(append(s.contactLabels[base.ID], s.contactLabels[mergee.ID]...))
+	for _, n := range s.contactNotes[mergee.ID] {
+		n.ContactID = base.ID
+		s.contactNotes[base.ID] = append(s.contactNotes[base.ID], n)
+	}
+	delete(s.contacts, mergee.ID)
+	delete(s.contactLabels, mergee.ID)
+	delete(s.contactNotes, mergee.ID)
+	s.cable.broadcast("contact.updated", base)
+	return base, nil
+}
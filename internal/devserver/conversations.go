@@ -0,0 +1,938 @@
+package devserver
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+)
+
+func validStatus(status string) bool {
+	switch status {
+	case "open", "resolved", "pending", "snoozed":
+		return true
+	}
+	return false
+}
+
+func validPriority(priority string) bool {
+	switch priority {
+	case "urgent", "high", "medium", "low":
+		return true
+	}
+	return false
+}
+
+func (s *Server) conversation(r *http.Request) (*api.Conversation, error) {
+	id, err := pathID(r, "id", "conversation")
+	if err != nil {
+		return nil, err
+	}
+	c := s.conversations[id]
+	if c == nil {
+		return nil, errNotFound("conversation", id)
+	}
+	return c, nil
+}
+
+// conversationJSON renders c with its derived fields: meta, message count
+// and last message.
+func (s *Server) conversationJSON(c *api.Conversation) api.Conversation {
+	out := *c
+	meta := map[string]any{}
+	if contact := s.contacts[c.ContactID]; contact != nil {
+		meta["sender"] = contact
+	}
+	if c.AssigneeID != nil {
+		if a := s.agents[*c.AssigneeID]; a != nil {
+			meta["assignee"] = agentJSON(a)
+		}
+	}
+	if c.TeamID != nil {
+		if t := s.teams[*c.TeamID]; t != nil {
+			meta["team"] = t
+		}
+	}
+	if in := s.inboxes[c.InboxID]; in != nil {
+		meta["channel"] = in.ChannelType
+	}
+	out.Meta = meta
+
+	msgs := s.messages[c.ID]
+	out.MessagesCount = len(msgs)
+	out.LastNonActivityMessage = nil
+	for i := len(msgs) - 1; i >= 0; i-- {
+		if msgs[i].MessageType != api.MessageTypeActivity {
+			out.LastNonActivityMessage = &api.LastNonActivityMessage{Content: msgs[i].Content}
+			break
+		}
+	}
+	return out
+}
+
+func (s *Server) conversationsJSON(list []*api.Conversation) []api.Conversation {
+	out := make([]api.Conversation, 0, len(list))
+	for _, c := range list {
+		out = append(out, s.conversationJSON(c))
+	}
+	return out
+}
+
+// broadcastConversation sends a conversation event. Assignment events also
+// carry the assignee at the top level, where clients look for it.
+func (s *Server) broadcastConversation(event string, c *api.Conversation) {
+	data := toMap(s.conversationJSON(c))
+	if event == "assignee.changed" {
+		data["assignee"] = nil
+		if c.AssigneeID != nil && s.agents[*c.AssigneeID] != nil {
+			data["assignee"] = agentJSON(s.agents[*c.AssigneeID])
+		}
+	}
+	s.cable.broadcast(event, data)
+}
+
+// sortedConversations returns conversations by most recent activity.
+func (s *Server) sortedConversations(keep func(*api.Conversation) bool) []*api.Conversation {
+	var list []*api.Conversation
+	for _, c := range s.conversations {
+		if keep == nil || keep(c) {
+			list = append(list, c)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].LastActivityAt != list[j].LastActivityAt {
+			return list[i].LastActivityAt > list[j].LastActivityAt
+		}
+		return list[i].ID > list[j].ID
+	})
+	return list
+}
+
+// matchConversations applies the list filters except assignee_type.
+func (s *Server) matchConversations(q url.Values) ([]*api.Conversation, error) {
+	status := q.Get("status")
+	if status != "" && status != "all" && !validStatus(status) {
+		return nil, errInvalid("invalid status %q", status)
+	}
+	inboxID, _ := strconv.Atoi(q.Get("inbox_id"))
+	teamID, _ := strconv.Atoi(q.Get("team_id"))
+	var labels []string
+	for _, l := range strings.Split(q.Get("labels"), ",") {
+		if l = strings.TrimSpace(l); l != "" {
+			labels = append(labels, l)
+		}
+	}
+	term := strings.TrimSpace(q.Get("q"))
+
+	return s.sortedConversations(func(c *api.Conversation) bool {
+		switch {
+		case status != "" && status != "all" && c.Status != status:
+			return false
+		case inboxID > 0 && c.InboxID != inboxID:
+			return false
+		case teamID > 0 && (c.TeamID == nil || *c.TeamID != teamID):
+			return false
+		case len(labels) > 0 && !hasAnyLabel(c.Labels, labels):
+			return false
+		case term != "" && !s.conversationMentions(c, term, true):
+			return false
+		}
+		return true
+	}), nil
+}
+
+func hasAnyLabel(have, want []string) bool {
+	for _, w := range want {
+		for _, h := range have {
+			if h == w {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// conversationMentions reports whether term appears in a message of c or,
+// with contact set, in the contact's name or email.
+func (s *Server) conversationMentions(c *api.Conversation, term string, contact bool) bool {
+	if contact {
+		if ct := s.contacts[c.ContactID]; ct != nil && (containsFold(ct.Name, term) || containsFold(ct.Email, term)) {
+			return true
+		}
+	}
+	for _, m := range s.messages[c.ID] {
+		if containsFold(m.Content, term) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) assigneeCounts(list []*api.Conversation) map[string]any {
+	mine, assigned := 0, 0
+	for _, c := range list {
+		if c.AssigneeID != nil {
+			assigned++
+			if *c.AssigneeID == s.userID {
+				mine++
+			}
+		}
+	}
+	return map[string]any{
+		"mine_count":       mine,
+		"assigned_count":   assigned,
+		"unassigned_count": len(list) - assigned,
+		"all_count":        len(list),
+	}
+}
+
+func (s *Server) byAssigneeType(list []*api.Conversation, assigneeType string) ([]*api.Conversation, error) {
+	var keep func(*api.Conversation) bool
+	switch assigneeType {
+	case "", "all":
+		return list, nil
+	case "me":
+		keep = func(c *api.Conversation) bool { return c.AssigneeID != nil && *c.AssigneeID == s.userID }
+	case "assigned":
+		keep = func(c *api.Conversation) bool { return c.AssigneeID != nil }
+	case "unassigned":
+		keep = func(c *api.Conversation) bool { return c.AssigneeID == nil }
+	default:
+		return nil, errInvalid("invalid assignee_type %q", assigneeType)
+	}
+	out := []*api.Conversation{}
+	for _, c := range list {
+		if keep(c) {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+// conversationPage renders one page of list in the list endpoint's shape.
+func (s *Server) conversationPage(list []*api.Conversation, meta map[string]any, pageNum int) map[string]any {
+	items, totalPages := page(list, pageNum, conversationsPerPage)
+	meta["current_page"] = pageNum
+	meta["total_pages"] = totalPages
+	meta["total_count"] = len(list)
+	return map[string]any{"meta": meta, "payload": s.conversationsJSON(items)}
+}
+
+func (s *Server) listConversations(r *http.Request) (any, error) {
+	q := r.URL.Query()
+	matched, err := s.matchConversations(q)
+	if err != nil {
+		return nil, err
+	}
+	list, err := s.byAssigneeType(matched, q.Get("assignee_type"))
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"data": s.conversationPage(list, s.assigneeCounts(matched), queryInt(r, "page", 1))}, nil
+}
+
+func (s *Server) conversationsMeta(r *http.Request) (any, error) {
+	matched, err := s.matchConversations(r.URL.Query())
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"meta": s.assigneeCounts(matched)}, nil
+}
+
+func (s *Server) searchConversations(r *http.Request) (any, error) {
+	term := strings.TrimSpace(r.URL.Query().Get("q"))
+	list := s.sortedConversations(func(c *api.Conversation) bool {
+		return term != "" && s.conversationMentions(c, term, false)
+	})
+	return map[string]any{"data": s.conversationPage(list, s.assigneeCounts(list), queryInt(r, "page", 1))}, nil
+}
+
+func (s *Server) filterConversations(r *http.Request) (any, error) {
+	var req struct {
+		Payload []filterCondition `json:"payload"`
+	}
+	if err := decodeBody(r, &req); err != nil {
+		return nil, err
+	}
+	var filterErr error
+	list := s.sortedConversations(func(c *api.Conversation) bool {
+		ok, err := matchFilter(req.Payload, func(key string) []string {
+			return s.conversationAttribute(c, key)
+		})
+		if err != nil {
+			filterErr = err
+		}
+		return ok
+	})
+	if filterErr != nil {
+		return nil, filterErr
+	}
+	return s.conversationPage(list, s.assigneeCounts(list), queryInt(r, "page", 1)), nil
+}
+
+// conversationAttribute returns the values of a filterable attribute;
+// other keys are looked up in the custom attributes.
+func (s *Server) conversationAttribute(c *api.Conversation, key string) []string {
+	optional := func(p *int) []string {
+		if p == nil {
+			return nil
+		}
+		return []string{strconv.Itoa(*p)}
+	}
+	switch key {
+	case "id", "display_id":
+		return []string{strconv.Itoa(c.ID)}
+	case "status":
+		return []string{c.Status}
+	case "priority":
+		if c.Priority == nil {
+			return nil
+		}
+		return []string{*c.Priority}
+	case "assignee_id":
+		return optional(c.AssigneeID)
+	case "team_id":
+		return optional(c.TeamID)
+	case "inbox_id":
+		return []string{strconv.Itoa(c.InboxID)}
+	case "contact_id":
+		return []string{strconv.Itoa(c.ContactID)}
+	case "labels":
+		return c.Labels
+	}
+	if v, ok := c.CustomAttributes[key]; ok {
+		return []string{fmt.Sprint(v)}
+	}
+	return nil
+}
+
+func (s *Server) getConversation(r *http.Request) (any, error) {
+	c, err := s.conversation(r)
+	if err != nil {
+		return nil, err
+	}
+	return s.conversationJSON(c), nil
+}
+
+func (s *Server) createConversation(r *http.Request) (any, error) {
+	var req struct {
+		InboxID          int            `json:"inbox_id"`
+		ContactID        int            `json:"contact_id"`
+		Status           string         `json:"status"`
+		AssigneeID       *int           `json:"assignee_id"`
+		TeamID           *int           `json:"team_id"`
+		CustomAttributes map[string]any `json:"custom_attributes"`
+		Message          *struct {
+			Content string `json:"content"`
+		} `json:"message"`
+	}
+	if err := decodeBody(r, &req); err != nil {
+		return nil, err
+	}
+	if s.inboxes[req.InboxID] == nil {
+		return nil, errInvalid("inbox %d not found", req.InboxID)
+	}
+	if s.contacts[req.ContactID] == nil {
+		return nil, errInvalid("contact %d not found", req.ContactID)
+	}
+	if req.Status == "" {
+		req.Status = "open"
+	}
+	if !validStatus(req.Status) {
+		return nil, errInvalid("invalid status %q", req.Status)
+	}
+	if req.AssigneeID != nil && s.agents[*req.AssigneeID] == nil {
+		return nil, errInvalid("agent %d not found", *req.AssigneeID)
+	}
+	if req.TeamID != nil && s.teams[*req.TeamID] == nil {
+		return nil, errInvalid("team %d not found", *req.TeamID)
+	}
+
+	now := s.now().Unix()
+	c := &api.Conversation{
+		ID:               s.nextID("conversation"),
+		AccountID:        s.account.ID,
+		InboxID:          req.InboxID,
+		ContactID:        req.ContactID,
+		Status:           req.Status,
+		AssigneeID:       req.AssigneeID,
+		TeamID:           req.TeamID,
+		CustomAttributes: req.CustomAttributes,
+		CreatedAt:        now,
+		LastActivityAt:   now,
+	}
+	s.conversations[c.ID] = c
+	s.broadcastConversation("conversation.created", c)
+	if req.Message != nil && req.Message.Content != "" {
+		m := &api.Message{ID: s.nextID("message"), Content: req.Message.Content, MessageType: api.MessageTypeOutgoing, SenderID: intPtr(s.userID), CreatedAt: now}
+		s.addMessage(c, m)
+		s.cable.broadcast("message.created", s.messageEvent(c, m))
+	}
+	return s.conversationJSON(c), nil
+}
+
+func (s *Server) updateConversation(r *http.Request) (any, error) {
+	c, err := s.conversation(r)
+	if err != nil {
+		return nil, err
+	}
+	var req struct {
+		Priority *string `json:"priority"`
+	}
+	if err := decodeBody(r, &req); err != nil {
+		return nil, err
+	}
+	if req.Priority != nil {
+		if err := setPriority(c, *req.Priority); err != nil {
+			return nil, err
+		}
+		s.broadcastConversation("conversation.updated", c)
+	}
+	return s.conversationJSON(c), nil
+}
+
+func setPriority(c *api.Conversation, priority string) error {
+	switch {
+	case priority == "" || priority == "none":
+		c.Priority = nil
+	case validPriority(priority):
+		c.Priority = &priority
+	default:
+		return errInvalid("invalid priority %q", priority)
+	}
+	return nil
+}
+
+func (s *Server) toggleStatus(r *http.Request) (any, error) {
+	c, err := s.conversation(r)
+	if err != nil {
+		return nil, err
+	}
+	var req struct {
+		Status       string `json:"status"`
+		SnoozedUntil int64  `json:"snoozed_until"`
+	}
+	if err := decodeBody(r, &req); err != nil {
+		return nil, err
+	}
+	status := req.Status
+	if status == "" {
+		// Without a status, Chatwoot toggles between open and resolved.
+		status = "resolved"
+		if c.Status != "open" {
+			status = "open"
+		}
+	}
+	if !validStatus(status) {
+		return nil, errInvalid("invalid status %q", status)
+	}
+	s.setStatus(c, status, req.SnoozedUntil)
+
+	var snoozedUntil *int64
+	if v, ok := s.snoozedUntil[c.ID]; ok {
+		snoozedUntil = &v
+	}
+	return map[string]any{
+		"meta": map[string]any{},
+		"payload": map[string]any{
+			"success":         true,
+			"conversation_id": c.ID,
+			"current_status":  c.Status,
+			"snoozed_until":   snoozedUntil,
+		},
+	}, nil
+}
+
+// setStatus changes a conversation's status and broadcasts the change.
+func (s *Server) setStatus(c *api.Conversation, status string, snoozedUntil int64) {
+	delete(s.snoozedUntil, c.ID)
+	if status == "snoozed" && snoozedUntil > 0 {
+		s.snoozedUntil[c.ID] = snoozedUntil
+	}
+	if status == "resolved" && c.Status != "resolved" {
+		s.resolvedAt[c.ID] = s.now().Unix()
+	}
+	if c.Status == status {
+		return
+	}
+	c.Status = status
+	s.broadcastConversation("conversation.status_changed", c)
+}
+
+func (s *Server) togglePriority(r *http.Request) (any, error) {
+	c, err := s.conversation(r)
+	if err != nil {
+		return nil, err
+	}
+	var req struct {
+		Priority string `json:"priority"`
+	}
+	if err := decodeBody(r, &req); err != nil {
+		return nil, err
+	}
+	if err := setPriority(c, req.Priority); err != nil {
+		return nil, err
+	}
+	s.broadcastConversation("conversation.updated", c)
+	return map[string]any{
+		"meta": map[string]any{},
+		"payload": map[string]any{
+			"success":          true,
+			"conversation_id":  c.ID,
+			"current_priority": c.Priority,
+		},
+	}, nil
+}
+
+// assign sets the team when team_id is given and the assignee otherwise;
+// a missing or zero ID clears it. It returns the new assignee or team.
+func (s *Server) assign(r *http.Request) (any, error) {
+	c, err := s.conversation(r)
+	if err != nil {
+		return nil, err
+	}
+	var req map[string]any
+	if err := decodeBody(r, &req); err != nil {
+		return nil, err
+	}
+	if raw, ok := req["team_id"]; ok {
+		id := anyInt(raw)
+		if id == 0 {
+			c.TeamID = nil
+			s.broadcastConversation("team.changed", c)
+			return nil, nil
+		}
+		t := s.teams[id]
+		if t == nil {
+			return nil, errNotFound("team", id)
+		}
+		c.TeamID = intPtr(id)
+		s.broadcastConversation("team.changed", c)
+		return t, nil
+	}
+
+	id := anyInt(req["assignee_id"])
+	if id == 0 {
+		c.AssigneeID = nil
+		s.broadcastConversation("assignee.changed", c)
+		return nil, nil
+	}
+	a := s.agents[id]
+	if a == nil {
+		return nil, errNotFound("agent", id)
+	}
+	c.AssigneeID = intPtr(id)
+	s.broadcastConversation("assignee.changed", c)
+	return agentJSON(a), nil
+}
+
+func (s *Server) conversationLabels(r *http.Request) (any, error) {
+	c, err := s.conversation(r)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"payload": nonNil(c.Labels)}, nil
+}
+
+// setConversationLabels replaces the conversation's labels, as Chatwoot does.
+func (s *Server) setConversationLabels(r *http.Request) (any, error) {
+	c, err := s.conversation(r)
+	if err != nil {
+		return nil, err
+	}
+	var req struct {
+		Labels []string `json:"labels"`
+	}
+	if err := decodeBody(r, &req); err != nil {
+		return nil, err
+	}
+	c.Labels = uniqueLabels(req.Labels)
+	s.broadcastConversation("conversation.updated", c)
+	return map[string]any{"payload": nonNil(c.Labels)}, nil
+}
+
+func uniqueLabels(labels []string) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, l := range labels {
+		l = strings.TrimSpace(l)
+		if l != "" && !seen[l] {
+			seen[l] = true
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+func nonNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
+
+func (s *Server) setConversationAttributes(r *http.Request) (any, error) {
+	c, err := s.conversation(r)
+	if err != nil {
+		return nil, err
+	}
+	var req struct {
+		CustomAttributes map[string]any `json:"custom_attributes"`
+	}
+	if err := decodeBody(r, &req); err != nil {
+		return nil, err
+	}
+	c.CustomAttributes = req.CustomAttributes
+	s.broadcastConversation("conversation.updated", c)
+	return map[string]any{"custom_attributes": c.CustomAttributes}, nil
+}
+
+func (s *Server) markUnread(r *http.Request) (any, error) {
+	c, err := s.conversation(r)
+	if err != nil {
+		return nil, err
+	}
+	c.Unread = max(c.Unread, 1)
+	return nil, nil
+}
+
+func (s *Server) mute(muted bool) handlerFunc {
+	return func(r *http.Request) (any, error) {
+		c, err := s.conversation(r)
+		if err != nil {
+			return nil, err
+		}
+		c.Muted = muted
+		return nil, nil
+	}
+}
+
+func (s *Server) toggleMute(r *http.Request) (any, error) {
+	c, err := s.conversation(r)
+	if err != nil {
+		return nil, err
+	}
+	var req struct {
+		Status bool `json:"status"`
+	}
+	if err := decodeBody(r, &req); err != nil {
+		return nil, err
+	}
+	c.Muted = req.Status
+	return nil, nil
+}
+
+func (s *Server) toggleTyping(r *http.Request) (any, error) {
+	c, err := s.conversation(r)
+	if err != nil {
+		return nil, err
+	}
+	var req struct {
+		TypingStatus string `json:"typing_status"`
+		IsPrivate    bool   `json:"is_private"`
+	}
+	if err := decodeBody(r, &req); err != nil {
+		return nil, err
+	}
+	event := "conversation.typing_off"
+	if req.TypingStatus == "on" {
+		event = "conversation.typing_on"
+	}
+	s.cable.broadcast(event, map[string]any{
+		"conversation": s.conversationJSON(c),
+		"user":         agentJSON(s.agents[s.userID]),
+		"is_private":   req.IsPrivate,
+	})
+	return nil, nil
+}
+
+func (s *Server) sendTranscript(r *http.Request) (any, error) {
+	_, err := s.conversation(r)
+	return nil, err
+}
+
+func (s *Server) conversationAttachments(r *http.Request) (any, error) {
+	c, err := s.conversation(r)
+	if err != nil {
+		return nil, err
+	}
+	attachments := []api.Attachment{}
+	for _, m := range s.messages[c.ID] {
+		attachments = append(attachments, m.Attachments...)
+	}
+	return map[string]any{"payload": attachments}, nil
+}
+
+// addMessage appends m to c, filling in the sender and updating the
+// conversation's activity, unread count and first reply.
+func (s *Server) addMessage(c *api.Conversation, m *api.Message) {
+	m.ConversationID = c.ID
+	if m.ContentType == "" {
+		m.ContentType = "text"
+	}
+	switch m.MessageType {
+	case api.MessageTypeIncoming:
+		contact := s.contacts[c.ContactID]
+		m.SenderID = intPtr(contact.ID)
+		m.SenderType = "Contact"
+		m.Sender = &api.MessageSender{ID: contact.ID, Name: contact.Name, Type: "contact"}
+		contact.LastActivityAt = &m.CreatedAt
+		c.Unread++
+	case api.MessageTypeOutgoing, api.MessageTypeTemplate:
+		if m.SenderID == nil {
+			m.SenderID = intPtr(s.userID)
+			if c.AssigneeID != nil {
+				m.SenderID = intPtr(*c.AssigneeID)
+			}
+		}
+		agent := s.agents[*m.SenderID]
+		m.SenderType = "User"
+		m.Sender = &api.MessageSender{ID: agent.ID, Name: agent.Name, Type: "user"}
+		if !m.Private {
+			c.Unread = 0
+			if c.FirstReplyCreatedAt == nil {
+				at := m.CreatedAt
+				c.FirstReplyCreatedAt = &at
+			}
+		}
+	}
+	c.LastActivityAt = max(c.LastActivityAt, m.CreatedAt)
+	s.messages[c.ID] = append(s.messages[c.ID], m)
+}
+
+// messageEvent is the data of a message event: the message plus the
+// conversation it belongs to.
+func (s *Server) messageEvent(c *api.Conversation, m *api.Message) map[string]any {
+	data := toMap(m)
+	data["account_id"] = s.account.ID
+	data["inbox_id"] = c.InboxID
+	data["conversation"] = map[string]any{
+		"id":          c.ID,
+		"inbox_id":    c.InboxID,
+		"status":      c.Status,
+		"assignee_id": c.AssigneeID,
+		"contact_id":  c.ContactID,
+	}
+	return data
+}
+
+func (s *Server) message(r *http.Request, c *api.Conversation) (*api.Message, error) {
+	id, err := pathID(r, "message", "message")
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range s.messages[c.ID] {
+		if m.ID == id {
+			return m, nil
+		}
+	}
+	return nil, errNotFound("message", id)
+}
+
+// listMessages returns up to a page of messages older than ?before, oldest
+// first.
+func (s *Server) listMessages(r *http.Request) (any, error) {
+	c, err := s.conversation(r)
+	if err != nil {
+		return nil, err
+	}
+	before := queryInt(r, "before", 0)
+	var older []*api.Message
+	for _, m := range s.messages[c.ID] {
+		if before == 0 || m.ID < before {
+			older = append(older, m)
+		}
+	}
+	if len(older) > messagesPerPage {
+		older = older[len(older)-messagesPerPage:]
+	}
+	payload := make([]api.Message, 0, len(older))
+	for _, m := range older {
+		payload = append(payload, *m)
+	}
+	meta := map[string]any{"labels": nonNil(c.Labels), "contact": s.contacts[c.ContactID]}
+	if c.AssigneeID != nil {
+		meta["assignee"] = agentJSON(s.agents[*c.AssigneeID])
+	}
+	return map[string]any{"meta": meta, "payload": payload}, nil
+}
+
+func (s *Server) createMessage(r *http.Request) (any, error) {
+	c, err := s.conversation(r)
+	if err != nil {
+		return nil, err
+	}
+	var req struct {
+		Content     string `json:"content"`
+		MessageType string `json:"message_type"`
+		Private     bool   `json:"private"`
+	}
+	var attachments []api.Attachment
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "multipart/form-data" {
+		if err := r.ParseMultipartForm(32 << 20); err != nil {
+			return nil, fmt.Errorf("invalid multipart body: %w", err)
+		}
+		req.Content = r.FormValue("content")
+		req.MessageType = r.FormValue("message_type")
+		req.Private, _ = strconv.ParseBool(r.FormValue("private"))
+		for _, fh := range r.MultipartForm.File["attachments[]"] {
+			f, err := fh.Open()
+			if err != nil {
+				return nil, err
+			}
+			data, err := io.ReadAll(f)
+			_ = f.Close()
+			if err != nil {
+				return nil, err
+			}
+			attachments = append(attachments, s.storeBlob(r, fh.Filename, fh.Header.Get("Content-Type"), data))
+		}
+	} else if err := decodeBody(r, &req); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(req.Content) == "" && len(attachments) == 0 {
+		return nil, errInvalid("Content can't be blank")
+	}
+
+	m := &api.Message{
+		ID:          s.nextID("message"),
+		Content:     req.Content,
+		Private:     req.Private,
+		CreatedAt:   s.now().Unix(),
+		Attachments: attachments,
+	}
+	switch req.MessageType {
+	case "", "outgoing":
+		m.MessageType = api.MessageTypeOutgoing
+		m.SenderID = intPtr(s.userID)
+	case "incoming":
+		m.MessageType = api.MessageTypeIncoming
+	default:
+		return nil, errInvalid("invalid message_type %q", req.MessageType)
+	}
+	s.addMessage(c, m)
+	s.cable.broadcast("message.created", s.messageEvent(c, m))
+	// A customer writing back reopens the conversation.
+	if m.MessageType == api.MessageTypeIncoming && (c.Status == "resolved" || c.Status == "snoozed") {
+		s.setStatus(c, "open", 0)
+	}
+	return *m, nil
+}
+
+func (s *Server) updateMessage(r *http.Request) (any, error) {
+	c, err := s.conversation(r)
+	if err != nil {
+		return nil, err
+	}
+	m, err := s.message(r, c)
+	if err != nil {
+		return nil, err
+	}
+	var req struct {
+		Content string `json:"content"`
+	}
+	if err := decodeBody(r, &req); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(req.Content) == "" {
+		return nil, errInvalid("Content can't be blank")
+	}
+	m.Content = req.Content
+	s.cable.broadcast("message.updated", s.messageEvent(c, m))
+	return *m, nil
+}
+
+// deleteMessage blanks the message, as Chatwoot keeps deleted messages in
+// the timeline.
+func (s *Server) deleteMessage(r *http.Request) (any, error) {
+	c, err := s.conversation(r)
+	if err != nil {
+		return nil, err
+	}
+	m, err := s.message(r, c)
+	if err != nil {
+		return nil, err
+	}
+	m.Content = "This message was deleted"
+	m.Attachments = nil
+	s.cable.broadcast("message.updated", s.messageEvent(c, m))
+	return *m, nil
+}
+
+// translateMessage returns the content unchanged; there is no translation
+// service offline.
+func (s *Server) translateMessage(r *http.Request) (any, error) {
+	c, err := s.conversation(r)
+	if err != nil {
+		return nil, err
+	}
+	m, err := s.message(r, c)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"content": m.Content}, nil
+}
+
+func (s *Server) retryMessage(r *http.Request) (any, error) {
+	c, err := s.conversation(r)
+	if err != nil {
+		return nil, err
+	}
+	m, err := s.message(r, c)
+	if err != nil {
+		return nil, err
+	}
+	return *m, nil
+}
+
+// storeBlob keeps an uploaded file and returns its attachment, served from
+// the same host the upload came in on.
+func (s *Server) storeBlob(r *http.Request, filename, contentType string, data []byte) api.Attachment {
+	id := s.nextID("attachment")
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	s.blobs[id] = blob{contentType: contentType, data: data}
+	fileType := "file"
+	for _, kind := range []string{"image", "audio", "video"} {
+		if strings.HasPrefix(contentType, kind+"/") {
+			fileType = kind
+		}
+	}
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return api.Attachment{
+		ID:       id,
+		FileType: fileType,
+		FileSize: len(data),
+		DataURL:  fmt.Sprintf("%s://%s/rails/active_storage/blobs/%d/%s", scheme, r.Host, id, url.PathEscape(path.Base(filename))),
+	}
+}
+
+// blob is an uploaded attachment.
+type blob struct {
+	contentType string
+	data        []byte
+}
+
+func (s *Server) serveBlob(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(r.PathValue("id"))
+	s.mu.Lock()
+	b, ok := s.blobs[id]
+	s.mu.Unlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", b.contentType)
+	_, _ = w.Write(b.data)
+}
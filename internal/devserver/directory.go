@@ -0,0 +1,512 @@
+package devserver
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+)
+
+// agentJSON renders an agent without the zero confirmation time api.Agent
+// would carry.
+func agentJSON(a *api.Agent) map[string]any {
+	if a == nil {
+		return nil
+	}
+	return map[string]any{
+		"id":                  a.ID,
+		"name":                a.Name,
+		"available_name":      a.Name,
+		"email":               a.Email,
+		"role":                a.Role,
+		"availability_status": a.AvailabilityStatus,
+		"thumbnail":           a.Thumbnail,
+		"confirmed":           true,
+	}
+}
+
+func (s *Server) agentsJSON(ids []int) []map[string]any {
+	out := []map[string]any{}
+	for _, id := range ids {
+		if a := s.agents[id]; a != nil {
+			out = append(out, agentJSON(a))
+		}
+	}
+	return out
+}
+
+func (s *Server) getProfile(*http.Request) (any, error) {
+	profile := agentJSON(s.agents[s.userID])
+	profile["account_id"] = s.account.ID
+	profile["pubsub_token"] = s.pubsubToken
+	profile["accounts"] = []map[string]any{{
+		"id":     s.account.ID,
+		"name":   s.account.Name,
+		"locale": s.account.Locale,
+		"role":   s.agents[s.userID].Role,
+	}}
+	return profile, nil
+}
+
+func (s *Server) getAccount(*http.Request) (any, error) {
+	return s.account, nil
+}
+
+func (s *Server) updateAccount(r *http.Request) (any, error) {
+	body := map[string]any{}
+	if err := decodeBody(r, &body); err != nil {
+		return nil, err
+	}
+	if err := patch(&s.account, body); err != nil {
+		return nil, err
+	}
+	return s.account, nil
+}
+
+// Inboxes
+
+func (s *Server) inbox(r *http.Request) (*api.Inbox, error) {
+	id, err := pathID(r, "id", "inbox")
+	if err != nil {
+		return nil, err
+	}
+	in := s.inboxes[id]
+	if in == nil {
+		return nil, errNotFound("inbox", id)
+	}
+	return in, nil
+}
+
+func (s *Server) listInboxes(*http.Request) (any, error) {
+	payload := []*api.Inbox{}
+	for _, id := range sortedKeys(s.inboxes) {
+		payload = append(payload, s.inboxes[id])
+	}
+	return map[string]any{"payload": payload}, nil
+}
+
+func (s *Server) getInbox(r *http.Request) (any, error) {
+	return s.inbox(r)
+}
+
+// channelTypes maps the channel types the API can create to the class
+// names inboxes report.
+var channelTypes = map[string]string{
+	"api":        "Channel::Api",
+	"email":      "Channel::Email",
+	"line":       "Channel::Line",
+	"sms":        "Channel::Sms",
+	"telegram":   "Channel::Telegram",
+	"web_widget": "Channel::WebWidget",
+	"whatsapp":   "Channel::Whatsapp",
+}
+
+func (s *Server) createInbox(r *http.Request) (any, error) {
+	body := map[string]any{}
+	if err := decodeBody(r, &body); err != nil {
+		return nil, err
+	}
+	channelType := "Channel::Api"
+	if channel, ok := body["channel"].(map[string]any); ok {
+		if t, _ := channel["type"].(string); t != "" {
+			if mapped, ok := channelTypes[strings.ToLower(t)]; ok {
+				channelType = mapped
+			} else {
+				return nil, errInvalid("invalid channel type %q", t)
+			}
+		}
+	}
+	delete(body, "channel")
+	in := &api.Inbox{}
+	if err := patch(in, body); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(in.Name) == "" {
+		return nil, errInvalid("Name can't be blank")
+	}
+	in.ID = s.nextID("inbox")
+	in.ChannelType = channelType
+	s.inboxes[in.ID] = in
+	return in, nil
+}
+
+func (s *Server) updateInbox(r *http.Request) (any, error) {
+	in, err := s.inbox(r)
+	if err != nil {
+		return nil, err
+	}
+	body := map[string]any{}
+	if err := decodeBody(r, &body); err != nil {
+		return nil, err
+	}
+	delete(body, "channel_type")
+	if err := patch(in, body); err != nil {
+		return nil, err
+	}
+	return in, nil
+}
+
+func (s *Server) deleteInbox(r *http.Request) (any, error) {
+	in, err := s.inbox(r)
+	if err != nil {
+		return nil, err
+	}
+	for id, c := range s.conversations {
+		if c.InboxID == in.ID {
+			delete(s.conversations, id)
+			delete(s.messages, id)
+		}
+	}
+	delete(s.inboxes, in.ID)
+	delete(s.inboxMembers, in.ID)
+	return nil, nil
+}
+
+func (s *Server) listInboxMembers(r *http.Request) (any, error) {
+	in, err := s.inbox(r)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"payload": s.agentsJSON(s.inboxMembers[in.ID])}, nil
+}
+
+// membersChange says how a member list request changes the members.
+type membersChange int
+
+const (
+	membersAdd membersChange = iota
+	membersSet
+	membersRemove
+)
+
+// applyMembers applies a change to a member list, rejecting unknown agents.
+func (s *Server) applyMembers(members, userIDs []int, change membersChange) ([]int, error) {
+	for _, id := range userIDs {
+		if s.agents[id] == nil {
+			return nil, errNotFound("agent", id)
+		}
+	}
+	switch change {
+	case membersSet:
+		members = nil
+		fallthrough
+	case membersAdd:
+		for _, id := range userIDs {
+			if !slices.Contains(members, id) {
+				members = append(members, id)
+			}
+		}
+	case membersRemove:
+		members = slices.DeleteFunc(slices.Clone(members), func(id int) bool {
+			return slices.Contains(userIDs, id)
+		})
+	}
+	return members, nil
+}
+
+func (s *Server) changeInboxMembers(change membersChange) handlerFunc {
+	return func(r *http.Request) (any, error) {
+		var req struct {
+			InboxID int   `json:"inbox_id"`
+			UserIDs []int `json:"user_ids"`
+		}
+		if err := decodeBody(r, &req); err != nil {
+			return nil, err
+		}
+		if s.inboxes[req.InboxID] == nil {
+			return nil, errNotFound("inbox", req.InboxID)
+		}
+		members, err := s.applyMembers(s.inboxMembers[req.InboxID], req.UserIDs, change)
+		if err != nil {
+			return nil, err
+		}
+		s.inboxMembers[req.InboxID] = members
+		return map[string]any{"payload": s.agentsJSON(members)}, nil
+	}
+}
+
+// Agents
+
+func (s *Server) listAgents(*http.Request) (any, error) {
+	return s.agentsJSON(sortedKeys(s.agents)), nil
+}
+
+func (s *Server) checkAgentEmail(a *api.Agent) error {
+	if strings.TrimSpace(a.Email) == "" {
+		return errInvalid("Email can't be blank")
+	}
+	for _, other := range s.agents {
+		if other.ID != a.ID && strings.EqualFold(other.Email, a.Email) {
+			return errInvalid("Email has already been taken")
+		}
+	}
+	return nil
+}
+
+func (s *Server) createAgent(r *http.Request) (any, error) {
+	body := map[string]any{}
+	if err := decodeBody(r, &body); err != nil {
+		return nil, err
+	}
+	a := &api.Agent{Role: "agent", AvailabilityStatus: "offline"}
+	if err := patch(a, body); err != nil {
+		return nil, err
+	}
+	if err := s.checkAgentEmail(a); err != nil {
+		return nil, err
+	}
+	if a.Name == "" {
+		a.Name, _, _ = strings.Cut(a.Email, "@")
+	}
+	a.ID = s.nextID("agent")
+	s.agents[a.ID] = a
+	return agentJSON(a), nil
+}
+
+func (s *Server) agent(r *http.Request) (*api.Agent, error) {
+	id, err := pathID(r, "id", "agent")
+	if err != nil {
+		return nil, err
+	}
+	a := s.agents[id]
+	if a == nil {
+		return nil, errNotFound("agent", id)
+	}
+	return a, nil
+}
+
+func (s *Server) updateAgent(r *http.Request) (any, error) {
+	a, err := s.agent(r)
+	if err != nil {
+		return nil, err
+	}
+	body := map[string]any{}
+	if err := decodeBody(r, &body); err != nil {
+		return nil, err
+	}
+	updated := *a
+	if err := patch(&updated, body); err != nil {
+		return nil, err
+	}
+	if err := s.checkAgentEmail(&updated); err != nil {
+		return nil, err
+	}
+	*a = updated
+	return agentJSON(a), nil
+}
+
+// deleteAgent removes the agent from the account, its teams and inboxes,
+// and unassigns its conversations.
+func (s *Server) deleteAgent(r *http.Request) (any, error) {
+	a, err := s.agent(r)
+	if err != nil {
+		return nil, err
+	}
+	if a.ID == s.userID {
+		return nil, errInvalid("cannot delete the current user")
+	}
+	for _, c := range s.conversations {
+		if c.AssigneeID != nil && *c.AssigneeID == a.ID {
+			c.AssigneeID = nil
+			s.broadcastConversation("assignee.changed", c)
+		}
+	}
+	for id, members := range s.teamMembers {
+		s.teamMembers[id] = slices.DeleteFunc(members, func(m int) bool { return m == a.ID })
+	}
+	for id, members := range s.inboxMembers {
+		s.inboxMembers[id] = slices.DeleteFunc(members, func(m int) bool { return m == a.ID })
+	}
+	delete(s.agents, a.ID)
+	return nil, nil
+}
+
+// Teams
+
+func (s *Server) team(r *http.Request) (*api.Team, error) {
+	id, err := pathID(r, "id", "team")
+	if err != nil {
+		return nil, err
+	}
+	t := s.teams[id]
+	if t == nil {
+		return nil, errNotFound("team", id)
+	}
+	return t, nil
+}
+
+func (s *Server) listTeams(*http.Request) (any, error) {
+	teams := []*api.Team{}
+	for _, id := range sortedKeys(s.teams) {
+		teams = append(teams, s.teams[id])
+	}
+	return teams, nil
+}
+
+func (s *Server) getTeam(r *http.Request) (any, error) {
+	return s.team(r)
+}
+
+func (s *Server) createTeam(r *http.Request) (any, error) {
+	body := map[string]any{}
+	if err := decodeBody(r, &body); err != nil {
+		return nil, err
+	}
+	t := &api.Team{}
+	if err := patch(t, body); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(t.Name) == "" {
+		return nil, errInvalid("Name can't be blank")
+	}
+	t.ID = s.nextID("team")
+	t.AccountID = s.account.ID
+	s.teams[t.ID] = t
+	return t, nil
+}
+
+func (s *Server) updateTeam(r *http.Request) (any, error) {
+	t, err := s.team(r)
+	if err != nil {
+		return nil, err
+	}
+	body := map[string]any{}
+	if err := decodeBody(r, &body); err != nil {
+		return nil, err
+	}
+	delete(body, "account_id")
+	if err := patch(t, body); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (s *Server) deleteTeam(r *http.Request) (any, error) {
+	t, err := s.team(r)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range s.conversations {
+		if c.TeamID != nil && *c.TeamID == t.ID {
+			c.TeamID = nil
+			s.broadcastConversation("team.changed", c)
+		}
+	}
+	delete(s.teams, t.ID)
+	delete(s.teamMembers, t.ID)
+	return nil, nil
+}
+
+func (s *Server) listTeamMembers(r *http.Request) (any, error) {
+	t, err := s.team(r)
+	if err != nil {
+		return nil, err
+	}
+	return s.agentsJSON(s.teamMembers[t.ID]), nil
+}
+
+func (s *Server) changeTeamMembers(change membersChange) handlerFunc {
+	return func(r *http.Request) (any, error) {
+		t, err := s.team(r)
+		if err != nil {
+			return nil, err
+		}
+		var req struct {
+			UserIDs []int `json:"user_ids"`
+		}
+		if err := decodeBody(r, &req); err != nil {
+			return nil, err
+		}
+		members, err := s.applyMembers(s.teamMembers[t.ID], req.UserIDs, change)
+		if err != nil {
+			return nil, err
+		}
+		s.teamMembers[t.ID] = members
+		return s.agentsJSON(members), nil
+	}
+}
+
+// Labels
+
+func (s *Server) label(r *http.Request) (*api.Label, error) {
+	id, err := pathID(r, "id", "label")
+	if err != nil {
+		return nil, err
+	}
+	l := s.labels[id]
+	if l == nil {
+		return nil, errNotFound("label", id)
+	}
+	return l, nil
+}
+
+func (s *Server) listLabels(*http.Request) (any, error) {
+	payload := []*api.Label{}
+	for _, id := range sortedKeys(s.labels) {
+		payload = append(payload, s.labels[id])
+	}
+	return map[string]any{"payload": payload}, nil
+}
+
+func (s *Server) getLabel(r *http.Request) (any, error) {
+	return s.label(r)
+}
+
+func (s *Server) checkLabelTitle(l *api.Label) error {
+	if strings.TrimSpace(l.Title) == "" {
+		return errInvalid("Title can't be blank")
+	}
+	for _, other := range s.labels {
+		if other.ID != l.ID && strings.EqualFold(other.Title, l.Title) {
+			return errInvalid("Title has already been taken")
+		}
+	}
+	return nil
+}
+
+func (s *Server) createLabel(r *http.Request) (any, error) {
+	body := map[string]any{}
+	if err := decodeBody(r, &body); err != nil {
+		return nil, err
+	}
+	l := &api.Label{}
+	if err := patch(l, body); err != nil {
+		return nil, err
+	}
+	if err := s.checkLabelTitle(l); err != nil {
+		return nil, err
+	}
+	l.ID = s.nextID("label")
+	s.labels[l.ID] = l
+	return l, nil
+}
+
+func (s *Server) updateLabel(r *http.Request) (any, error) {
+	l, err := s.label(r)
+	if err != nil {
+		return nil, err
+	}
+	body := map[string]any{}
+	if err := decodeBody(r, &body); err != nil {
+		return nil, err
+	}
+	updated := *l
+	if err := patch(&updated, body); err != nil {
+		return nil, err
+	}
+	if err := s.checkLabelTitle(&updated); err != nil {
+		return nil, err
+	}
+	*l = updated
+	return l, nil
+}
+
+func (s *Server) deleteLabel(r *http.Request) (any, error) {
+	l, err := s.label(r)
+	if err != nil {
+		return nil, err
+	}
+	delete(s.labels, l.ID)
+	return nil, nil
+}
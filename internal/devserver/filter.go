@@ -0,0 +1,88 @@
+package devserver
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// filterCondition is one condition of a filter request to the conversations
+// or contacts filter endpoint.
+type filterCondition struct {
+	AttributeKey   string `json:"attribute_key"`
+	FilterOperator string `json:"filter_operator"`
+	Values         []any  `json:"values"`
+	QueryOperator  string `json:"query_operator"`
+}
+
+// matchFilter evaluates conditions against a resource whose attribute values
+// attr returns. Each condition's query_operator joins it to the next, and
+// AND binds tighter than OR, as in the SQL Chatwoot builds.
+func matchFilter(conds []filterCondition, attr func(key string) []string) (bool, error) {
+	if len(conds) == 0 {
+		return true, nil
+	}
+	result, group := false, true
+	for i, cond := range conds {
+		ok, err := matchCondition(cond, attr(cond.AttributeKey))
+		if err != nil {
+			return false, err
+		}
+		group = group && ok
+		if i == len(conds)-1 || strings.EqualFold(cond.QueryOperator, "or") {
+			result = result || group
+			group = true
+		}
+	}
+	return result, nil
+}
+
+func matchCondition(cond filterCondition, have []string) (bool, error) {
+	values := make([]string, 0, len(cond.Values))
+	for _, v := range cond.Values {
+		values = append(values, filterValue(v))
+	}
+	matchAny := func(match func(have, want string) bool) bool {
+		for _, h := range have {
+			for _, w := range values {
+				if match(h, w) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	switch cond.FilterOperator {
+	case "equal_to":
+		return matchAny(strings.EqualFold), nil
+	case "not_equal_to":
+		return !matchAny(strings.EqualFold), nil
+	case "contains":
+		return matchAny(containsFold), nil
+	case "does_not_contain":
+		return !matchAny(containsFold), nil
+	case "is_present":
+		return len(have) > 0 && have[0] != "", nil
+	case "is_not_present":
+		return len(have) == 0 || have[0] == "", nil
+	default:
+		return false, errInvalid("filter operator %q is not supported by the dev server", cond.FilterOperator)
+	}
+}
+
+// filterValue converts a filter value to a string. Values picked in the
+// Chatwoot UI are objects with an id.
+func filterValue(v any) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case map[string]any:
+		if id, ok := val["id"]; ok {
+			return filterValue(id)
+		}
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,291 @@
+package devserver
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+)
+
+// Fixture is the initial state of a dev server. Resources use the same JSON
+// shapes as the API; IDs left at zero are assigned, and missing timestamps
+// are spread over the hours before the server starts.
+type Fixture struct {
+	Account api.Account `json:"account"`
+	// UserID is the agent the API token belongs to (default: the first agent).
+	UserID        int                   `json:"user_id,omitempty"`
+	PubsubToken   string                `json:"pubsub_token,omitempty"`
+	Agents        []api.Agent           `json:"agents"`
+	Teams         []FixtureTeam         `json:"teams"`
+	Inboxes       []FixtureInbox        `json:"inboxes"`
+	Labels        []api.Label           `json:"labels"`
+	Contacts      []FixtureContact      `json:"contacts"`
+	Conversations []FixtureConversation `json:"conversations"`
+}
+
+// FixtureTeam is a team and the agent IDs in it.
+type FixtureTeam struct {
+	api.Team
+	Members []int `json:"members,omitempty"`
+}
+
+// FixtureInbox is an inbox and the agent IDs with access to it.
+type FixtureInbox struct {
+	api.Inbox
+	Members []int `json:"members,omitempty"`
+}
+
+// FixtureContact is a contact and its labels.
+type FixtureContact struct {
+	api.Contact
+	Labels []string `json:"labels,omitempty"`
+}
+
+// FixtureConversation is a conversation and its messages, oldest first.
+// Incoming messages are sent by the contact; other messages by sender_id or,
+// if unset, the conversation's assignee.
+type FixtureConversation struct {
+	api.Conversation
+	Messages []api.Message `json:"messages,omitempty"`
+}
+
+//go:embed seed.json
+var defaultSeed []byte
+
+// DefaultFixture returns the built-in demo account: a few agents, teams,
+// inboxes, labels, contacts and conversations in various states.
+func DefaultFixture() *Fixture {
+	f, err := decodeFixture(defaultSeed)
+	if err != nil {
+		panic(fmt.Sprintf("devserver: invalid built-in seed: %v", err))
+	}
+	return f
+}
+
+// LoadFixture reads a fixture from a JSON file. Unknown fields are rejected
+// so typos don't silently seed nothing.
+func LoadFixture(path string) (*Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := decodeFixture(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return f, nil
+}
+
+func decodeFixture(data []byte) (*Fixture, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var f Fixture
+	if err := dec.Decode(&f); err != nil {
+		return nil, fmt.Errorf("invalid fixture: %w", err)
+	}
+	return &f, nil
+}
+
+// seed loads f into an empty server, checking that references resolve.
+func (s *Server) seed(f *Fixture) error {
+	now := s.now()
+
+	s.account = f.Account
+	if s.account.ID == 0 {
+		s.account.ID = 1
+	}
+	if s.account.Name == "" {
+		s.account.Name = "Dev Account"
+	}
+	if s.account.Locale == "" {
+		s.account.Locale = "en"
+	}
+
+	for _, a := range f.Agents {
+		s.reserveID("agent", a.ID)
+	}
+	for i := range f.Agents {
+		a := f.Agents[i]
+		if a.ID == 0 {
+			a.ID = s.nextID("agent")
+		}
+		if s.agents[a.ID] != nil {
+			return fmt.Errorf("fixture: duplicate agent id %d", a.ID)
+		}
+		if a.Role == "" {
+			a.Role = "agent"
+		}
+		if a.AvailabilityStatus == "" {
+			a.AvailabilityStatus = "online"
+		}
+		s.agents[a.ID] = &a
+	}
+	s.userID = f.UserID
+	if s.userID == 0 {
+		if len(s.agents) == 0 {
+			id := s.nextID("agent")
+			s.agents[id] = &api.Agent{ID: id, Name: "Dev User", Email: "dev@example.com", Role: "administrator", AvailabilityStatus: "online"}
+		}
+		s.userID = sortedKeys(s.agents)[0]
+	}
+	if s.agents[s.userID] == nil {
+		return fmt.Errorf("fixture: user_id %d is not an agent", s.userID)
+	}
+	s.pubsubToken = f.PubsubToken
+	if s.pubsubToken == "" {
+		s.pubsubToken = DefaultPubsubToken
+	}
+
+	for _, t := range f.Teams {
+		s.reserveID("team", t.ID)
+	}
+	for _, ft := range f.Teams {
+		t := ft.Team
+		if t.ID == 0 {
+			t.ID = s.nextID("team")
+		}
+		if s.teams[t.ID] != nil {
+			return fmt.Errorf("fixture: duplicate team id %d", t.ID)
+		}
+		t.AccountID = s.account.ID
+		if err := s.checkAgents(fmt.Sprintf("team %d", t.ID), ft.Members); err != nil {
+			return err
+		}
+		s.teams[t.ID] = &t
+		s.teamMembers[t.ID] = append([]int(nil), ft.Members...)
+	}
+
+	for _, in := range f.Inboxes {
+		s.reserveID("inbox", in.ID)
+	}
+	for _, fi := range f.Inboxes {
+		in := fi.Inbox
+		if in.ID == 0 {
+			in.ID = s.nextID("inbox")
+		}
+		if s.inboxes[in.ID] != nil {
+			return fmt.Errorf("fixture: duplicate inbox id %d", in.ID)
+		}
+		if in.ChannelType == "" {
+			in.ChannelType = "Channel::Api"
+		}
+		if err := s.checkAgents(fmt.Sprintf("inbox %d", in.ID), fi.Members); err != nil {
+			return err
+		}
+		s.inboxes[in.ID] = &in
+		s.inboxMembers[in.ID] = append([]int(nil), fi.Members...)
+	}
+
+	for _, l := range f.Labels {
+		s.reserveID("label", l.ID)
+	}
+	for i := range f.Labels {
+		l := f.Labels[i]
+		if l.ID == 0 {
+			l.ID = s.nextID("label")
+		}
+		if s.labels[l.ID] != nil {
+			return fmt.Errorf("fixture: duplicate label id %d", l.ID)
+		}
+		if l.Title == "" {
+			return fmt.Errorf("fixture: label %d has no title", l.ID)
+		}
+		s.labels[l.ID] = &l
+	}
+
+	for _, c := range f.Contacts {
+		s.reserveID("contact", c.ID)
+	}
+	for _, fc := range f.Contacts {
+		c := fc.Contact
+		if c.ID == 0 {
+			c.ID = s.nextID("contact")
+		}
+		if s.contacts[c.ID] != nil {
+			return fmt.Errorf("fixture: duplicate contact id %d", c.ID)
+		}
+		if c.CreatedAt == 0 {
+			c.CreatedAt = now.Add(-7 * 24 * time.Hour).Unix()
+		}
+		s.contacts[c.ID] = &c
+		s.contactLabels[c.ID] = append([]string(nil), fc.Labels...)
+	}
+
+	for _, c := range f.Conversations {
+		s.reserveID("conversation", c.ID)
+		for _, m := range c.Messages {
+			s.reserveID("message", m.ID)
+		}
+	}
+	for i, fc := range f.Conversations {
+		c := fc.Conversation
+		if c.ID == 0 {
+			c.ID = s.nextID("conversation")
+		}
+		if s.conversations[c.ID] != nil {
+			return fmt.Errorf("fixture: duplicate conversation id %d", c.ID)
+		}
+		what := fmt.Sprintf("conversation %d", c.ID)
+		if s.inboxes[c.InboxID] == nil {
+			return fmt.Errorf("fixture: %s: inbox %d not found", what, c.InboxID)
+		}
+		if s.contacts[c.ContactID] == nil {
+			return fmt.Errorf("fixture: %s: contact %d not found", what, c.ContactID)
+		}
+		if c.AssigneeID != nil {
+			if err := s.checkAgents(what, []int{*c.AssigneeID}); err != nil {
+				return err
+			}
+		}
+		if c.TeamID != nil && s.teams[*c.TeamID] == nil {
+			return fmt.Errorf("fixture: %s: team %d not found", what, *c.TeamID)
+		}
+		if c.Status == "" {
+			c.Status = "open"
+		}
+		if !validStatus(c.Status) {
+			return fmt.Errorf("fixture: %s: invalid status %q", what, c.Status)
+		}
+		c.AccountID = s.account.ID
+		if c.CreatedAt == 0 {
+			// Spread conversations over the hours before startup, oldest first.
+			c.CreatedAt = now.Add(-time.Duration(len(f.Conversations)-i) * time.Hour).Unix()
+		}
+		c.LastActivityAt = c.CreatedAt
+		c.Unread = 0
+		s.conversations[c.ID] = &c
+
+		for j := range fc.Messages {
+			m := fc.Messages[j]
+			if m.ID == 0 {
+				m.ID = s.nextID("message")
+			}
+			if m.CreatedAt == 0 {
+				m.CreatedAt = min(c.CreatedAt+int64(j)*120, now.Unix())
+			}
+			if m.SenderID != nil && m.MessageType != api.MessageTypeIncoming {
+				if err := s.checkAgents(what, []int{*m.SenderID}); err != nil {
+					return err
+				}
+			}
+			s.addMessage(&c, &m)
+		}
+		if c.Status == "resolved" {
+			s.resolvedAt[c.ID] = c.LastActivityAt
+		}
+	}
+	return nil
+}
+
+func (s *Server) checkAgents(what string, ids []int) error {
+	for _, id := range ids {
+		if s.agents[id] == nil {
+			return fmt.Errorf("fixture: %s: agent %d not found", what, id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package devserver
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadFixture(t *testing.T) {
+	tests := []struct {
+		name    string
+		fixture string
+		wantErr string
+	}{
+		{
+			name:    "minimal",
+			fixture: `{"account":{"id":7}}`,
+		},
+		{
+			name:    "unknown field",
+			fixture: `{"account":{"id":1},"agnets":[]}`,
+			wantErr: `unknown field "agnets"`,
+		},
+		{
+			name:    "missing inbox",
+			fixture: `{"contacts":[{"id":1,"name":"Jane"}],"conversations":[{"inbox_id":9,"contact_id":1}]}`,
+			wantErr: "conversation 1: inbox 9 not found",
+		},
+		{
+			name:    "missing assignee",
+			fixture: `{"inboxes":[{"name":"Web"}],"contacts":[{"name":"Jane"}],"conversations":[{"inbox_id":1,"contact_id":1,"assignee_id":5}]}`,
+			wantErr: "agent 5 not found",
+		},
+		{
+			name:    "invalid status",
+			fixture: `{"inboxes":[{"name":"Web"}],"contacts":[{"name":"Jane"}],"conversations":[{"inbox_id":1,"contact_id":1,"status":"closed"}]}`,
+			wantErr: `invalid status "closed"`,
+		},
+		{
+			name:    "duplicate id",
+			fixture: `{"labels":[{"id":1,"title":"a"},{"id":1,"title":"b"}]}`,
+			wantErr: "duplicate label id 1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "seed.json")
+			if err := os.WriteFile(path, []byte(tt.fixture), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			f, err := LoadFixture(path)
+			if err == nil {
+				_, err = New(f, "")
+			}
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("err = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSeedAssignsIDsAndDefaults(t *testing.T) {
+	srv, err := New(&Fixture{
+		Inboxes:       []FixtureInbox{{}},
+		Contacts:      []FixtureContact{{}, {}},
+		Conversations: []FixtureConversation{{}},
+	}, "")
+	if err == nil {
+		t.Fatal("expected an error for a conversation without an inbox or contact")
+	}
+
+	f := DefaultFixture()
+	srv, err = New(f, "")
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if srv.AccountID() != 1 || srv.PubsubToken() != DefaultPubsubToken {
+		t.Errorf("account %d, pubsub token %q", srv.AccountID(), srv.PubsubToken())
+	}
+	if got := srv.nextID("conversation"); got != len(f.Conversations)+1 {
+		t.Errorf("next conversation id = %d, want %d", got, len(f.Conversations)+1)
+	}
+	for id, c := range srv.conversations {
+		if c.LastActivityAt < c.CreatedAt || c.CreatedAt > srv.now().Unix() {
+			t.Errorf("conversation %d: created %d, last activity %d", id, c.CreatedAt, c.LastActivityAt)
+		}
+	}
+}
@@ -0,0 +1,309 @@
+package devserver
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+)
+
+// timeRange is a report's [since, until] window in Unix seconds.
+type timeRange struct {
+	since, until int64
+}
+
+func (t timeRange) contains(ts int64) bool {
+	return ts >= t.since && ts <= t.until
+}
+
+// reportRange reads since and until; either may be omitted.
+func reportRange(r *http.Request) (timeRange, error) {
+	t := timeRange{since: 0, until: math.MaxInt64}
+	for name, dst := range map[string]*int64{"since": &t.since, "until": &t.until} {
+		raw := r.URL.Query().Get(name)
+		if raw == "" {
+			continue
+		}
+		v, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return t, errInvalid("invalid %s %q: want a Unix timestamp", name, raw)
+		}
+		*dst = v
+	}
+	return t, nil
+}
+
+// reportScope selects the conversations a report of the given type covers.
+func reportScope(reportType string, id int) (func(*api.Conversation) bool, error) {
+	matchID := func(p *int) bool { return p != nil && *p == id }
+	switch reportType {
+	case "", "account":
+		return func(*api.Conversation) bool { return true }, nil
+	case "inbox":
+		return func(c *api.Conversation) bool { return c.InboxID == id }, nil
+	case "agent":
+		return func(c *api.Conversation) bool { return matchID(c.AssigneeID) }, nil
+	case "team":
+		return func(c *api.Conversation) bool { return matchID(c.TeamID) }, nil
+	}
+	return nil, errInvalid("report type %q is not supported by the dev server", reportType)
+}
+
+// reportMetrics are the measures of the conversations in scope over a window.
+type reportMetrics struct {
+	conversations int
+	incoming      int
+	outgoing      int
+	resolutions   int
+	// Sums and counts for the averages, in seconds.
+	firstResponseTotal, firstResponses int64
+	resolutionTotal, resolved          int64
+}
+
+func (s *Server) measure(scope func(*api.Conversation) bool, window timeRange) reportMetrics {
+	var m reportMetrics
+	for _, c := range s.conversations {
+		if !scope(c) {
+			continue
+		}
+		if window.contains(c.CreatedAt) {
+			m.conversations++
+			if c.FirstReplyCreatedAt != nil {
+				m.firstResponseTotal += *c.FirstReplyCreatedAt - c.CreatedAt
+				m.firstResponses++
+			}
+		}
+		if at, ok := s.resolvedAt[c.ID]; ok && window.contains(at) {
+			m.resolutions++
+			m.resolutionTotal += at - c.CreatedAt
+			m.resolved++
+		}
+		for _, msg := range s.messages[c.ID] {
+			if msg.Private || !window.contains(msg.CreatedAt) {
+				continue
+			}
+			switch msg.MessageType {
+			case api.MessageTypeIncoming:
+				m.incoming++
+			case api.MessageTypeOutgoing:
+				m.outgoing++
+			}
+		}
+	}
+	return m
+}
+
+func average(total, n int64) *float64 {
+	if n == 0 {
+		return nil
+	}
+	avg := float64(total) / float64(n)
+	return &avg
+}
+
+func (m reportMetrics) value(metric string) (any, bool) {
+	zeroIfNil := func(v *float64) float64 {
+		if v == nil {
+			return 0
+		}
+		return *v
+	}
+	switch metric {
+	case "conversations_count":
+		return m.conversations, true
+	case "incoming_messages_count":
+		return m.incoming, true
+	case "outgoing_messages_count":
+		return m.outgoing, true
+	case "resolutions_count":
+		return m.resolutions, true
+	case "avg_first_response_time":
+		return zeroIfNil(average(m.firstResponseTotal, m.firstResponses)), true
+	case "avg_resolution_time":
+		return zeroIfNil(average(m.resolutionTotal, m.resolved)), true
+	}
+	return nil, false
+}
+
+func (s *Server) reportSummary(r *http.Request) (any, error) {
+	window, err := reportRange(r)
+	if err != nil {
+		return nil, err
+	}
+	id, _ := strconv.Atoi(r.URL.Query().Get("id"))
+	scope, err := reportScope(r.URL.Query().Get("type"), id)
+	if err != nil {
+		return nil, err
+	}
+	m := s.measure(scope, window)
+	summary := map[string]any{}
+	for _, metric := range []string{"conversations_count", "incoming_messages_count", "outgoing_messages_count", "resolutions_count", "avg_first_response_time", "avg_resolution_time"} {
+		summary[metric], _ = m.value(metric)
+	}
+	return summary, nil
+}
+
+// reportTimeseries returns one point per day (or ?group_by=hour|week) from
+// since to until.
+func (s *Server) reportTimeseries(r *http.Request) (any, error) {
+	window, err := reportRange(r)
+	if err != nil {
+		return nil, err
+	}
+	if window.since == 0 || window.until == math.MaxInt64 {
+		return nil, errInvalid("since and until are required")
+	}
+	step := int64(86400)
+	switch r.URL.Query().Get("group_by") {
+	case "", "day":
+	case "hour":
+		step = 3600
+	case "week":
+		step = 7 * 86400
+	default:
+		return nil, errInvalid("group_by %q is not supported by the dev server", r.URL.Query().Get("group_by"))
+	}
+	if (window.until-window.since)/step > 1000 {
+		return nil, errInvalid("too many points; narrow the range or group by a longer period")
+	}
+	id, _ := strconv.Atoi(r.URL.Query().Get("id"))
+	scope, err := reportScope(r.URL.Query().Get("type"), id)
+	if err != nil {
+		return nil, err
+	}
+	metric := r.URL.Query().Get("metric")
+	if _, ok := (reportMetrics{}).value(metric); !ok {
+		return nil, errInvalid("invalid metric %q", metric)
+	}
+
+	points := []map[string]any{}
+	for start := window.since - window.since%step; start <= window.until; start += step {
+		bucket := timeRange{since: max(start, window.since), until: min(start+step-1, window.until)}
+		v, _ := s.measure(scope, bucket).value(metric)
+		points = append(points, map[string]any{"value": v, "timestamp": start})
+	}
+	return points, nil
+}
+
+// awaitingReply reports whether the last public message of c is from the
+// contact.
+func (s *Server) awaitingReply(c *api.Conversation) bool {
+	msgs := s.messages[c.ID]
+	for i := len(msgs) - 1; i >= 0; i-- {
+		if msgs[i].Private || msgs[i].MessageType == api.MessageTypeActivity {
+			continue
+		}
+		return msgs[i].MessageType == api.MessageTypeIncoming
+	}
+	return false
+}
+
+// reportConversations returns live open counts for the account, or per
+// agent with type=agent.
+func (s *Server) reportConversations(r *http.Request) (any, error) {
+	q := r.URL.Query()
+	switch q.Get("type") {
+	case "", "account":
+		var open, unattended, unassigned int
+		for _, c := range s.conversations {
+			if c.Status != "open" {
+				continue
+			}
+			open++
+			if s.awaitingReply(c) {
+				unattended++
+			}
+			if c.AssigneeID == nil {
+				unassigned++
+			}
+		}
+		return map[string]int{"open": open, "unattended": unattended, "unassigned": unassigned}, nil
+	case "agent":
+		userID, _ := strconv.Atoi(q.Get("user_id"))
+		out := []map[string]any{}
+		for _, id := range sortedKeys(s.agents) {
+			if userID != 0 && id != userID {
+				continue
+			}
+			a := s.agents[id]
+			var open, unattended int
+			for _, c := range s.conversations {
+				if c.Status == "open" && c.AssigneeID != nil && *c.AssigneeID == id {
+					open++
+					if s.awaitingReply(c) {
+						unattended++
+					}
+				}
+			}
+			out = append(out, map[string]any{
+				"id":           a.ID,
+				"name":         a.Name,
+				"email":        a.Email,
+				"thumbnail":    a.Thumbnail,
+				"availability": a.AvailabilityStatus,
+				"metric":       map[string]int{"open": open, "unattended": unattended},
+			})
+		}
+		return out, nil
+	}
+	return nil, errInvalid("report type %q is not supported by the dev server", q.Get("type"))
+}
+
+// summaryByChannel counts conversations created in the window by channel
+// type and status.
+func (s *Server) summaryByChannel(r *http.Request) (any, error) {
+	window, err := reportRange(r)
+	if err != nil {
+		return nil, err
+	}
+	out := map[string]map[string]int{}
+	for _, c := range s.conversations {
+		in := s.inboxes[c.InboxID]
+		if in == nil || !window.contains(c.CreatedAt) {
+			continue
+		}
+		counts := out[in.ChannelType]
+		if counts == nil {
+			counts = map[string]int{"open": 0, "resolved": 0, "pending": 0, "snoozed": 0, "total": 0}
+			out[in.ChannelType] = counts
+		}
+		counts[c.Status]++
+		counts["total"]++
+	}
+	return out, nil
+}
+
+// summaryByGroup returns one summary entry per inbox, agent or team.
+func (s *Server) summaryByGroup(r *http.Request) (any, error) {
+	window, err := reportRange(r)
+	if err != nil {
+		return nil, err
+	}
+	group := r.PathValue("group")
+	var ids []int
+	switch group {
+	case "inbox":
+		ids = sortedKeys(s.inboxes)
+	case "agent":
+		ids = sortedKeys(s.agents)
+	case "team":
+		ids = sortedKeys(s.teams)
+	default:
+		return nil, errNotFound("summary report", group)
+	}
+	out := []map[string]any{}
+	for _, id := range ids {
+		scope, _ := reportScope(group, id)
+		m := s.measure(scope, window)
+		out = append(out, map[string]any{
+			"id":                           id,
+			"conversations_count":          m.conversations,
+			"resolved_conversations_count": m.resolutions,
+			"avg_resolution_time":          average(m.resolutionTotal, m.resolved),
+			"avg_first_response_time":      average(m.firstResponseTotal, m.firstResponses),
+			"avg_reply_time":               nil,
+		})
+	}
+	return out, nil
+}
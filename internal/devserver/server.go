@@ -0,0 +1,412 @@
+// Package devserver is an in-memory stand-in for the Chatwoot API, so the
+// CLI and scripts built on it can run without a real instance.
+//
+// It serves the account endpoints the CLI uses for conversations, messages,
+// contacts, inboxes, agents, teams, labels and reports, and a minimal
+// ActionCable endpoint at /cable that broadcasts changes as they are made.
+// State lives in memory, seeded from a Fixture, and is lost on exit.
+package devserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+)
+
+// DefaultToken is the API access token the dev server accepts by default.
+const DefaultToken = "dev-token"
+
+// DefaultPubsubToken is the ActionCable token of the fixture's user when the
+// fixture doesn't set one.
+const DefaultPubsubToken = "dev-pubsub-token"
+
+// Page sizes, matching Chatwoot.
+const (
+	conversationsPerPage = 25
+	contactsPerPage      = 15
+	messagesPerPage      = 20
+)
+
+// Server is a fake Chatwoot instance. It is an http.Handler; all state is
+// guarded by one mutex, so requests are applied one at a time.
+type Server struct {
+	token       string // accepted api_access_token; empty accepts any
+	pubsubToken string
+	mux         *http.ServeMux
+	cable       *cableHub
+	now         func() time.Time
+
+	mu            sync.Mutex
+	account       api.Account
+	userID        int
+	agents        map[int]*api.Agent
+	teams         map[int]*api.Team
+	teamMembers   map[int][]int
+	inboxes       map[int]*api.Inbox
+	inboxMembers  map[int][]int
+	labels        map[int]*api.Label
+	contacts      map[int]*api.Contact
+	contactLabels map[int][]string
+	contactNotes  map[int][]api.ContactNote
+	conversations map[int]*api.Conversation
+	messages      map[int][]*api.Message // by conversation, oldest first
+	resolvedAt    map[int]int64
+	snoozedUntil  map[int]int64
+	blobs         map[int]blob
+	lastID        map[string]int
+}
+
+// New returns a server seeded with f. Requests must carry token as their
+// api_access_token; an empty token accepts any.
+func New(f *Fixture, token string) (*Server, error) {
+	s := &Server{
+		token:         token,
+		mux:           http.NewServeMux(),
+		cable:         newCableHub(),
+		now:           time.Now,
+		agents:        map[int]*api.Agent{},
+		teams:         map[int]*api.Team{},
+		teamMembers:   map[int][]int{},
+		inboxes:       map[int]*api.Inbox{},
+		inboxMembers:  map[int][]int{},
+		labels:        map[int]*api.Label{},
+		contacts:      map[int]*api.Contact{},
+		contactLabels: map[int][]string{},
+		contactNotes:  map[int][]api.ContactNote{},
+		conversations: map[int]*api.Conversation{},
+		messages:      map[int][]*api.Message{},
+		resolvedAt:    map[int]int64{},
+		snoozedUntil:  map[int]int64{},
+		blobs:         map[int]blob{},
+		lastID:        map[string]int{},
+	}
+	if err := s.seed(f); err != nil {
+		return nil, err
+	}
+	s.routes()
+	return s, nil
+}
+
+// AccountID returns the ID of the account the server hosts.
+func (s *Server) AccountID() int {
+	return s.account.ID
+}
+
+// PubsubToken returns the token ActionCable subscriptions must present.
+func (s *Server) PubsubToken() string {
+	return s.pubsubToken
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Close disconnects ActionCable clients. http.Server.Shutdown does not wait
+// for hijacked connections, so call it after shutting the server down.
+func (s *Server) Close() {
+	s.cable.closeAll()
+}
+
+// handlerFunc handles an API request, returning the value to send as JSON.
+// A nil value sends an empty 200 response.
+type handlerFunc func(r *http.Request) (any, error)
+
+// apiError is an error response.
+type apiError struct {
+	status  int
+	message string
+}
+
+func (e *apiError) Error() string { return e.message }
+
+func errNotFound(kind string, id any) error {
+	return &apiError{status: http.StatusNotFound, message: fmt.Sprintf("%s %v not found", kind, id)}
+}
+
+func errInvalid(format string, args ...any) error {
+	return &apiError{status: http.StatusUnprocessableEntity, message: fmt.Sprintf(format, args...)}
+}
+
+func (s *Server) routes() {
+	s.mux.HandleFunc("GET /health", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	s.mux.HandleFunc("GET /cable", s.serveCable)
+	s.mux.HandleFunc("GET /rails/active_storage/blobs/{id}/{name}", s.serveBlob)
+	s.handle("GET /api/v1/profile", s.getProfile)
+
+	// Account API.
+	v1 := func(pattern string, h handlerFunc) {
+		method, path, _ := strings.Cut(pattern, " ")
+		s.handle(method+" /api/v1/accounts/{account}"+path, h)
+	}
+	v1("GET ", s.getAccount)
+	v1("PATCH ", s.updateAccount)
+
+	v1("GET /conversations", s.listConversations)
+	v1("POST /conversations", s.createConversation)
+	v1("GET /conversations/meta", s.conversationsMeta)
+	v1("GET /conversations/search", s.searchConversations)
+	v1("POST /conversations/filter", s.filterConversations)
+	v1("GET /conversations/{id}", s.getConversation)
+	v1("PATCH /conversations/{id}", s.updateConversation)
+	v1("POST /conversations/{id}/toggle_status", s.toggleStatus)
+	v1("POST /conversations/{id}/toggle_priority", s.togglePriority)
+	v1("POST /conversations/{id}/assignments", s.assign)
+	v1("GET /conversations/{id}/labels", s.conversationLabels)
+	v1("POST /conversations/{id}/labels", s.setConversationLabels)
+	v1("POST /conversations/{id}/custom_attributes", s.setConversationAttributes)
+	v1("POST /conversations/{id}/unread", s.markUnread)
+	v1("POST /conversations/{id}/mute", s.mute(true))
+	v1("POST /conversations/{id}/unmute", s.mute(false))
+	v1("POST /conversations/{id}/toggle_mute", s.toggleMute)
+	v1("POST /conversations/{id}/toggle_typing_status", s.toggleTyping)
+	v1("POST /conversations/{id}/transcript", s.sendTranscript)
+	v1("GET /conversations/{id}/attachments", s.conversationAttachments)
+	v1("GET /conversations/{id}/messages", s.listMessages)
+	v1("POST /conversations/{id}/messages", s.createMessage)
+	v1("PATCH /conversations/{id}/messages/{message}", s.updateMessage)
+	v1("DELETE /conversations/{id}/messages/{message}", s.deleteMessage)
+	v1("POST /conversations/{id}/messages/{message}/translate", s.translateMessage)
+	v1("POST /conversations/{id}/messages/{message}/retry", s.retryMessage)
+
+	v1("GET /contacts", s.listContacts)
+	v1("POST /contacts", s.createContact)
+	v1("GET /contacts/search", s.searchContacts)
+	v1("POST /contacts/filter", s.filterContacts)
+	v1("GET /contacts/{id}", s.getContact)
+	v1("PATCH /contacts/{id}", s.updateContact)
+	v1("PUT /contacts/{id}", s.updateContact)
+	v1("DELETE /contacts/{id}", s.deleteContact)
+	v1("GET /contacts/{id}/conversations", s.contactConversations)
+	v1("GET /contacts/{id}/labels", s.contactLabelsHandler)
+	v1("POST /contacts/{id}/labels", s.setContactLabels)
+	v1("GET /contacts/{id}/contactable_inboxes", s.contactableInboxes)
+	v1("GET /contacts/{id}/notes", s.listContactNotes)
+	v1("POST /contacts/{id}/notes", s.createContactNote)
+	v1("DELETE /contacts/{id}/notes/{note}", s.deleteContactNote)
+	v1("POST /actions/contact_merge", s.mergeContacts)
+
+	v1("GET /inboxes", s.listInboxes)
+	v1("POST /inboxes", s.createInbox)
+	v1("GET /inboxes/{id}", s.getInbox)
+	v1("PATCH /inboxes/{id}", s.updateInbox)
+	v1("DELETE /inboxes/{id}", s.deleteInbox)
+	v1("GET /inbox_members/{id}", s.listInboxMembers)
+	v1("POST /inbox_members", s.changeInboxMembers(membersAdd))
+	v1("PATCH /inbox_members", s.changeInboxMembers(membersSet))
+	v1("DELETE /inbox_members", s.changeInboxMembers(membersRemove))
+
+	v1("GET /agents", s.listAgents)
+	v1("POST /agents", s.createAgent)
+	v1("PATCH /agents/{id}", s.updateAgent)
+	v1("DELETE /agents/{id}", s.deleteAgent)
+
+	v1("GET /teams", s.listTeams)
+	v1("POST /teams", s.createTeam)
+	v1("GET /teams/{id}", s.getTeam)
+	v1("PATCH /teams/{id}", s.updateTeam)
+	v1("DELETE /teams/{id}", s.deleteTeam)
+	v1("GET /teams/{id}/team_members", s.listTeamMembers)
+	v1("POST /teams/{id}/team_members", s.changeTeamMembers(membersAdd))
+	v1("PATCH /teams/{id}/team_members", s.changeTeamMembers(membersSet))
+	v1("DELETE /teams/{id}/team_members", s.changeTeamMembers(membersRemove))
+
+	v1("GET /labels", s.listLabels)
+	v1("POST /labels", s.createLabel)
+	v1("GET /labels/{id}", s.getLabel)
+	v1("PATCH /labels/{id}", s.updateLabel)
+	v1("DELETE /labels/{id}", s.deleteLabel)
+
+	// Reports API.
+	v2 := func(pattern string, h handlerFunc) {
+		method, path, _ := strings.Cut(pattern, " ")
+		s.handle(method+" /api/v2/accounts/{account}"+path, h)
+	}
+	v2("GET /reports", s.reportTimeseries)
+	v2("GET /reports/summary", s.reportSummary)
+	v2("GET /reports/conversations", s.reportConversations)
+	v2("GET /summary_reports/channel", s.summaryByChannel)
+	v2("GET /summary_reports/{group}", s.summaryByGroup)
+
+	s.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusNotFound, map[string]string{
+			"error": fmt.Sprintf("%s %s is not implemented by the dev server", r.Method, r.URL.Path),
+		})
+	})
+}
+
+// handle registers an authenticated API endpoint.
+func (s *Server) handle(pattern string, h handlerFunc) {
+	s.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		if s.token != "" && r.Header.Get("api_access_token") != s.token {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Invalid Access Token"})
+			return
+		}
+		if account := r.PathValue("account"); account != "" && account != strconv.Itoa(s.account.ID) {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "You are not authorized to access this account"})
+			return
+		}
+
+		// Responses share maps with the state, so encode them under the lock.
+		s.mu.Lock()
+		body, err := h(r)
+		var data []byte
+		if err == nil && body != nil {
+			data, err = json.Marshal(body)
+		}
+		s.mu.Unlock()
+
+		var apiErr *apiError
+		switch {
+		case errors.As(err, &apiErr):
+			key := "error"
+			if apiErr.status == http.StatusUnprocessableEntity {
+				key = "message"
+			}
+			writeJSON(w, apiErr.status, map[string]string{key: apiErr.message})
+		case err != nil:
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		case data == nil:
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			_, _ = w.Write(append(data, '\n'))
+		}
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// decodeBody decodes a JSON request body into v. An empty body leaves v
+// unchanged.
+func decodeBody(r *http.Request, v any) error {
+	data, err := io.ReadAll(io.LimitReader(r.Body, 10<<20))
+	if err != nil {
+		return err
+	}
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("invalid JSON body: %w", err)
+	}
+	return nil
+}
+
+// pathID parses a numeric path wildcard.
+func pathID(r *http.Request, name, kind string) (int, error) {
+	raw := r.PathValue(name)
+	id, err := strconv.Atoi(raw)
+	if err != nil || id <= 0 {
+		return 0, errNotFound(kind, raw)
+	}
+	return id, nil
+}
+
+// queryInt reads a positive integer query parameter, or def.
+func queryInt(r *http.Request, name string, def int) int {
+	if v, err := strconv.Atoi(r.URL.Query().Get(name)); err == nil && v > 0 {
+		return v
+	}
+	return def
+}
+
+// patch overlays the top-level fields of body onto v, which must be a
+// pointer to a struct with JSON tags. IDs can't be changed.
+func patch(v any, body map[string]any) error {
+	delete(body, "id")
+	current, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	merged := map[string]any{}
+	if err := json.Unmarshal(current, &merged); err != nil {
+		return err
+	}
+	for k, val := range body {
+		merged[k] = val
+	}
+	data, err := json.Marshal(merged)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return errInvalid("%v", err)
+	}
+	return nil
+}
+
+// nextID returns an unused ID for kind.
+func (s *Server) nextID(kind string) int {
+	s.lastID[kind]++
+	return s.lastID[kind]
+}
+
+// reserveID records an explicit ID so nextID never hands it out.
+func (s *Server) reserveID(kind string, id int) {
+	if id > s.lastID[kind] {
+		s.lastID[kind] = id
+	}
+}
+
+// page returns the 1-based page of items and the number of pages.
+func page[T any](items []T, pageNum, perPage int) ([]T, int) {
+	total := (len(items) + perPage - 1) / perPage
+	start := (pageNum - 1) * perPage
+	if start >= len(items) {
+		return []T{}, total
+	}
+	end := min(start+perPage, len(items))
+	return items[start:end], total
+}
+
+func sortedKeys[V any](m map[int]V) []int {
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
+
+// containsFold reports whether s contains substr, ignoring case.
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+func intPtr(v int) *int { return &v }
+
+// anyInt converts a decoded JSON number or numeric string to an int.
+func anyInt(v any) int {
+	switch n := v.(type) {
+	case float64:
+		return int(n)
+	case string:
+		i, _ := strconv.Atoi(n)
+		return i
+	}
+	return 0
+}
+
+// toMap converts v to a JSON object, to add fields to a rendered resource.
+func toMap(v any) map[string]any {
+	data, _ := json.Marshal(v)
+	m := map[string]any{}
+	_ = json.Unmarshal(data, &m)
+	return m
+}
@@ -0,0 +1,231 @@
+package devserver
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+)
+
+func newTestServer(t *testing.T) (*Server, *api.Client) {
+	t.Helper()
+	t.Setenv("CHATWOOT_TESTING", "1")
+	srv, err := New(DefaultFixture(), DefaultToken)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	ts := httptest.NewServer(srv)
+	t.Cleanup(func() {
+		srv.Close()
+		ts.Close()
+	})
+	return srv, api.New(ts.URL, DefaultToken, srv.AccountID())
+}
+
+func apiStatus(err error) int {
+	var apiErr *api.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	return 0
+}
+
+func TestAuth(t *testing.T) {
+	srv, _ := newTestServer(t)
+
+	tests := []struct {
+		name  string
+		path  string
+		token string
+		want  int
+	}{
+		{"valid token", "/api/v1/accounts/1/agents", DefaultToken, http.StatusOK},
+		{"wrong token", "/api/v1/accounts/1/agents", "nope", http.StatusUnauthorized},
+		{"other account", "/api/v1/accounts/2/agents", DefaultToken, http.StatusUnauthorized},
+		{"health needs no token", "/health", "", http.StatusOK},
+		{"unknown endpoint", "/api/v1/accounts/1/campaigns", DefaultToken, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set("api_access_token", tt.token)
+			rec := httptest.NewRecorder()
+			srv.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d (body %s)", rec.Code, tt.want, rec.Body)
+			}
+		})
+	}
+}
+
+func TestConversationsListAndFilter(t *testing.T) {
+	_, client := newTestServer(t)
+	ctx := context.Background()
+
+	open, err := client.Conversations().List(ctx, api.ListConversationsParams{Status: "open"})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if got := len(open.Data.Payload); got != 2 {
+		t.Errorf("open conversations = %d, want 2", got)
+	}
+
+	all, err := client.Conversations().List(ctx, api.ListConversationsParams{Status: "all"})
+	if err != nil {
+		t.Fatalf("List all: %v", err)
+	}
+	if got := len(all.Data.Payload); got != 4 {
+		t.Errorf("all conversations = %d, want 4", got)
+	}
+
+	filtered, err := client.Conversations().Filter(ctx, map[string]any{
+		"payload": []map[string]any{{
+			"attribute_key":   "labels",
+			"filter_operator": "equal_to",
+			"values":          []string{"billing"},
+		}},
+	}, 1)
+	if err != nil {
+		t.Fatalf("Filter: %v", err)
+	}
+	if got := len(filtered.Data.Payload); got != 1 || filtered.Data.Payload[0].ID != 2 {
+		t.Errorf("filtered = %+v, want conversation 2", filtered.Data.Payload)
+	}
+
+	_, err = client.Conversations().Filter(ctx, map[string]any{
+		"payload": []map[string]any{{"attribute_key": "status", "filter_operator": "days_before", "values": []string{"3"}}},
+	}, 1)
+	if apiStatus(err) != http.StatusUnprocessableEntity {
+		t.Errorf("unsupported operator: err = %v, want 422", err)
+	}
+}
+
+func TestMessagesAndStatus(t *testing.T) {
+	_, client := newTestServer(t)
+	ctx := context.Background()
+
+	msg, err := client.Messages().Create(ctx, 1, "On it!", false, "outgoing")
+	if err != nil {
+		t.Fatalf("Create message: %v", err)
+	}
+	msgs, err := client.Messages().List(ctx, 1)
+	if err != nil {
+		t.Fatalf("List messages: %v", err)
+	}
+	if last := msgs[len(msgs)-1]; last.ID != msg.ID || last.Content != "On it!" {
+		t.Errorf("last message = %+v, want %d %q", last, msg.ID, "On it!")
+	}
+
+	resp, err := client.Conversations().ToggleStatus(ctx, 1, "resolved", 0)
+	if err != nil {
+		t.Fatalf("ToggleStatus: %v", err)
+	}
+	if resp.Payload.CurrentStatus != "resolved" {
+		t.Errorf("current_status = %q, want resolved", resp.Payload.CurrentStatus)
+	}
+	conv, err := client.Conversations().Get(ctx, 1)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if conv.Status != "resolved" {
+		t.Errorf("status = %q, want resolved", conv.Status)
+	}
+
+	// A reply from the contact reopens the conversation.
+	if _, err := client.Messages().Create(ctx, 1, "Actually, one more thing", false, "incoming"); err != nil {
+		t.Fatalf("Create incoming message: %v", err)
+	}
+	if conv, _ = client.Conversations().Get(ctx, 1); conv.Status != "open" {
+		t.Errorf("status after incoming message = %q, want open", conv.Status)
+	}
+
+	if _, err := client.Conversations().Get(ctx, 999); apiStatus(err) != http.StatusNotFound {
+		t.Errorf("missing conversation: err = %v, want 404", err)
+	}
+}
+
+func TestContactsAndLabels(t *testing.T) {
+	_, client := newTestServer(t)
+	ctx := context.Background()
+
+	created, err := client.Contacts().Create(ctx, "Zed", "zed@example.com", "")
+	if err != nil {
+		t.Fatalf("Create contact: %v", err)
+	}
+	if _, err := client.Contacts().Create(ctx, "Zed again", "ZED@example.com", ""); apiStatus(err) != http.StatusUnprocessableEntity {
+		t.Errorf("duplicate email: err = %v, want 422", err)
+	}
+	got, err := client.Contacts().Get(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("Get contact: %v", err)
+	}
+	if got.Email != "zed@example.com" {
+		t.Errorf("email = %q", got.Email)
+	}
+
+	list, err := client.Contacts().List(ctx, api.ListContactsParams{Page: 1})
+	if err != nil {
+		t.Fatalf("List contacts: %v", err)
+	}
+	if len(list.Payload) != 4 {
+		t.Errorf("contacts = %d, want 4", len(list.Payload))
+	}
+
+	labels, err := client.Conversations().AddLabels(ctx, 1, []string{"bug", "vip", "bug"})
+	if err != nil {
+		t.Fatalf("AddLabels: %v", err)
+	}
+	if len(labels) != 2 {
+		t.Errorf("labels = %v, want [bug vip]", labels)
+	}
+}
+
+func TestReports(t *testing.T) {
+	_, client := newTestServer(t)
+	ctx := context.Background()
+	now := time.Now()
+	since := strconv.FormatInt(now.Add(-7*24*time.Hour).Unix(), 10)
+	until := strconv.FormatInt(now.Add(time.Hour).Unix(), 10)
+
+	summary, err := client.Reports().Summary(ctx, "account", since, until, "")
+	if err != nil {
+		t.Fatalf("Summary: %v", err)
+	}
+	if summary.ConversationsCount != 4 || summary.ResolutionsCount != 1 {
+		t.Errorf("summary = %+v, want 4 conversations and 1 resolution", summary)
+	}
+
+	points, err := client.Reports().TimeSeries(ctx, "conversations_count", "account", since, until, "")
+	if err != nil {
+		t.Fatalf("TimeSeries: %v", err)
+	}
+	total := 0
+	for _, p := range points {
+		n, _ := strconv.Atoi(string(p.Value))
+		total += n
+	}
+	if total != 4 {
+		t.Errorf("time series total = %d, want 4", total)
+	}
+
+	metrics, err := client.Reports().ConversationMetrics(ctx)
+	if err != nil {
+		t.Fatalf("ConversationMetrics: %v", err)
+	}
+	if metrics.Open != 2 || metrics.Unassigned != 1 {
+		t.Errorf("metrics = %+v, want 2 open, 1 unassigned", metrics)
+	}
+
+	byInbox, err := client.Reports().SummaryByInbox(ctx, since, until, nil)
+	if err != nil {
+		t.Fatalf("SummaryByInbox: %v", err)
+	}
+	if len(byInbox) != 2 {
+		t.Errorf("inbox summaries = %d, want 2", len(byInbox))
+	}
+}
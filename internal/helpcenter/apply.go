@@ -0,0 +1,163 @@
+package helpcenter
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+)
+
+// Result is the outcome of applying one Change.
+type Result struct {
+	Change
+	Error string `json:"error,omitempty"`
+}
+
+// Apply executes the plan against a portal in order. It keeps going after
+// failures, skipping articles whose new category could not be created, and
+// returns one Result per change with the number of failures.
+func Apply(ctx context.Context, client *api.Client, portalSlug string, plan Plan) ([]Result, int) {
+	portals := client.Portals()
+	results := make([]Result, 0, len(plan.Changes))
+	failed := 0
+	for _, c := range plan.Changes {
+		r := Result{Change: c}
+		id, err := applyChange(ctx, portals, portalSlug, plan, c)
+		if id != 0 {
+			r.ID = id
+		}
+		if err != nil {
+			r.Error = err.Error()
+			failed++
+		}
+		results = append(results, r)
+	}
+	return results, failed
+}
+
+func applyChange(ctx context.Context, portals api.PortalsService, portalSlug string, plan Plan, c Change) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	switch {
+	case c.Kind == KindCategory && c.Action == ActionCreate:
+		params := map[string]any{"name": c.category.Name, "slug": c.category.Slug, "locale": c.category.Locale}
+		if c.category.Description != "" {
+			params["description"] = c.category.Description
+		}
+		if c.category.Position != 0 {
+			params["position"] = c.category.Position
+		}
+		created, err := portals.CreateCategory(ctx, portalSlug, params)
+		if err != nil {
+			return 0, err
+		}
+		plan.categoryIDs[c.category] = created.ID
+		return created.ID, nil
+
+	case c.Kind == KindCategory && c.Action == ActionUpdate:
+		params := map[string]any{}
+		for _, f := range c.Fields {
+			switch f {
+			case "name":
+				params["name"] = c.category.Name
+			case "description":
+				params["description"] = c.category.Description
+			case "position":
+				params["position"] = c.category.Position
+			}
+		}
+		_, err := portals.UpdateCategory(ctx, portalSlug, c.category.Slug, params)
+		return c.ID, err
+
+	case c.Kind == KindArticle && c.Action == ActionCreate:
+		a := c.article
+		params := map[string]any{"title": a.Title, "slug": a.Slug, "content": a.Content}
+		status := a.Status
+		if status == "" {
+			status = StatusDraft
+		}
+		params["status"] = statusCodes[status]
+		if a.Description != "" {
+			params["description"] = a.Description
+		}
+		if locale := articleLocale(a); locale != "" {
+			params["locale"] = locale
+		}
+		if a.Category != nil {
+			if err := setCategoryID(params, plan, a); err != nil {
+				return 0, err
+			}
+		}
+		created, err := portals.CreateArticle(ctx, portalSlug, params)
+		if err != nil {
+			return 0, err
+		}
+		plan.articleIDs[a.Slug] = created.ID
+		return created.ID, nil
+
+	case c.Kind == KindArticle && c.Action == ActionUpdate:
+		a := c.article
+		params := map[string]any{}
+		for _, f := range c.Fields {
+			switch f {
+			case "title":
+				params["title"] = a.Title
+			case "description":
+				params["description"] = a.Description
+			case "content":
+				params["content"] = a.Content
+			case "status":
+				params["status"] = statusCodes[a.Status]
+			case "locale":
+				params["locale"] = a.Locale
+			case "category":
+				if err := setCategoryID(params, plan, a); err != nil {
+					return c.ID, err
+				}
+			}
+		}
+		_, err := portals.UpdateArticle(ctx, portalSlug, c.ID, params)
+		return c.ID, err
+
+	case c.Action == ActionReorder:
+		ids := make([]int, 0, len(c.Order))
+		for _, slug := range c.Order {
+			id, ok := plan.articleIDs[slug]
+			if !ok {
+				return 0, fmt.Errorf("article %q was not created", slug)
+			}
+			ids = append(ids, id)
+		}
+		return 0, portals.ReorderArticles(ctx, portalSlug, ids)
+
+	case c.Kind == KindArticle && c.Action == ActionDelete:
+		return c.ID, portals.DeleteArticle(ctx, portalSlug, c.ID)
+
+	case c.Kind == KindCategory && c.Action == ActionDelete:
+		return c.ID, portals.DeleteCategory(ctx, portalSlug, c.category.Slug)
+	}
+	return 0, fmt.Errorf("unsupported change %s %s", c.Action, c.Kind)
+}
+
+// articleLocale is the article's own locale, or its category's.
+func articleLocale(a *Article) string {
+	if a.Locale != "" || a.Category == nil {
+		return a.Locale
+	}
+	return a.Category.Locale
+}
+
+// setCategoryID points the article at its category, or at none.
+func setCategoryID(params map[string]any, plan Plan, a *Article) error {
+	if a.Category == nil {
+		params["category_id"] = nil
+		return nil
+	}
+	id, ok := plan.categoryIDs[a.Category]
+	if !ok {
+		return fmt.Errorf("category %q was not created", a.Category.Key())
+	}
+	params["category_id"] = id
+	return nil
+}
@@ -0,0 +1,91 @@
+package helpcenter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change.
+const diffContext = 2
+
+// maxDiffCells bounds the LCS table; larger inputs get a summary instead.
+const maxDiffCells = 4_000_000
+
+// LineDiff returns a unified-style diff of two texts: "-" for removed lines,
+// "+" for added ones, and "@@" between hunks.
+func LineDiff(before, after string) string {
+	a, b := splitLines(before), splitLines(after)
+	if len(a)*len(b) > maxDiffCells {
+		return fmt.Sprintf("@@ %d lines replaced by %d lines @@\n", len(a), len(b))
+	}
+
+	// lcs[i][j] is the LCS length of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	type op struct {
+		kind byte // ' ', '-', '+'
+		text string
+	}
+	var ops []op
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			ops = append(ops, op{' ', a[i]})
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, op{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, op{'+', b[j]})
+			j++
+		}
+	}
+
+	// Keep changed lines and diffContext lines around them.
+	keep := make([]bool, len(ops))
+	for k, o := range ops {
+		if o.kind == ' ' {
+			continue
+		}
+		for c := max(0, k-diffContext); c <= min(len(ops)-1, k+diffContext); c++ {
+			keep[c] = true
+		}
+	}
+	var out strings.Builder
+	gap := false
+	for k, o := range ops {
+		if !keep[k] {
+			gap = true
+			continue
+		}
+		if gap || k == 0 {
+			out.WriteString("@@\n")
+			gap = false
+		}
+		out.WriteByte(o.kind)
+		out.WriteString(o.text)
+		out.WriteByte('\n')
+	}
+	return out.String()
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
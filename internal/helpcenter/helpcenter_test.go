@@ -0,0 +1,207 @@
+package helpcenter_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+	"github.com/chatwoot/chatwoot-cli/internal/helpcenter"
+)
+
+func portalTree() *helpcenter.Tree {
+	return helpcenter.FromPortal(
+		[]api.Category{
+			{ID: 1, Name: "Billing", Slug: "billing", Locale: "en", Position: 1},
+			{ID: 2, Name: "帳單", Slug: "billing", Locale: "zh_TW", Position: 1},
+			{ID: 3, Name: "Legacy", Slug: "legacy", Locale: "en"},
+		},
+		[]api.Article{
+			{ID: 10, CategoryID: 1, Title: "Refunds", Slug: "refunds", Status: "published", Position: 1, Locale: "en", Content: "How to get a refund.\n\nStep one.\n"},
+			{ID: 11, CategoryID: 1, Title: "Invoices", Slug: "invoices", Status: "published", Position: 2, Locale: "en", Content: "Invoices."},
+			{ID: 12, CategoryID: 2, Title: "退款", Slug: "refunds-zh", Status: "draft", Locale: "zh_TW", Content: "如何退款。"},
+			{ID: 13, Title: "Welcome", Slug: "welcome", Status: "published", Content: "Hi."},
+			{ID: 14, CategoryID: 3, Title: "Old", Slug: "old", Status: "archived", Content: "Gone."},
+		},
+	)
+}
+
+func writeFile(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPullRoundTripHasNoChanges(t *testing.T) {
+	dir := t.TempDir()
+	remote := portalTree()
+	res, err := helpcenter.WriteDir(dir, remote, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"billing-en/_category.yaml", "billing-en/invoices.md", "billing-en/refunds.md",
+		"billing-zh_TW/_category.yaml", "billing-zh_TW/refunds-zh.md",
+		"legacy/_category.yaml", "legacy/old.md", "welcome.md",
+	}
+	if !reflect.DeepEqual(res.Written, want) {
+		t.Fatalf("written = %v", res.Written)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, "billing-en", "refunds.md"))
+	if !strings.HasPrefix(string(data), "---\ntitle: Refunds\nslug: refunds\nstatus: published\nposition: 1\nlocale: en\n---\n\nHow to get a refund.") {
+		t.Fatalf("unexpected article file:\n%s", data)
+	}
+
+	local, err := helpcenter.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan, err := helpcenter.Diff(remote, local, helpcenter.DiffOptions{Prune: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !plan.Empty() || len(plan.Untracked) != 0 {
+		t.Fatalf("expected no changes, got %+v", plan)
+	}
+
+	// A second pull rewrites nothing; a removed portal article is only
+	// reported until pruning.
+	res, err = helpcenter.WriteDir(dir, remote, false, false)
+	if err != nil || len(res.Written) != 0 || res.Unchanged != len(want) {
+		t.Fatalf("second pull = %+v, %v", res, err)
+	}
+	remote.Articles = remote.Articles[1:]
+	res, _ = helpcenter.WriteDir(dir, remote, false, false)
+	if !reflect.DeepEqual(res.Stale, []string{"billing-en/invoices.md"}) {
+		t.Fatalf("stale = %v", res.Stale)
+	}
+	res, _ = helpcenter.WriteDir(dir, remote, true, false)
+	if !reflect.DeepEqual(res.Removed, []string{"billing-en/invoices.md"}) {
+		t.Fatalf("removed = %v", res.Removed)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "billing-en", "invoices.md")); !os.IsNotExist(err) {
+		t.Fatal("pruned file still exists")
+	}
+}
+
+func TestDiffPlansChanges(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := helpcenter.WriteDir(dir, portalTree(), false, false); err != nil {
+		t.Fatal(err)
+	}
+	// Edit refunds, move invoices first, add an article and a category,
+	// and delete the legacy category.
+	writeFile(t, filepath.Join(dir, "billing-en", "refunds.md"),
+		"---\ntitle: Refunds\nslug: refunds\nstatus: published\nposition: 5\nlocale: en\n---\n\nHow to get a refund.\n\nStep two.\n")
+	writeFile(t, filepath.Join(dir, "billing-en", "taxes.md"), "---\ntitle: Taxes\nposition: 3\n---\nVAT.\n")
+	writeFile(t, filepath.Join(dir, "api", "_category.yaml"), "name: API\nlocale: en\n")
+	writeFile(t, filepath.Join(dir, "api", "tokens.md"), "---\ntitle: Tokens\nstatus: published\n---\nUse a token.\n")
+	if err := os.RemoveAll(filepath.Join(dir, "legacy")); err != nil {
+		t.Fatal(err)
+	}
+
+	local, err := helpcenter.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan, err := helpcenter.Diff(portalTree(), local, helpcenter.DiffOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, c := range plan.Changes {
+		got = append(got, string(c.Action)+" "+string(c.Kind)+" "+c.Key+" "+strings.Join(c.Fields, ",")+strings.Join(c.Order, ","))
+	}
+	want := []string{
+		"create category api (en) ",
+		"create article tokens ",
+		"update article refunds content",
+		"create article taxes ",
+		"reorder category billing (en) invoices,taxes,refunds",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("changes =\n%s", strings.Join(got, "\n"))
+	}
+	if !reflect.DeepEqual(plan.Untracked, []string{"category legacy (en)", "article old"}) {
+		t.Fatalf("untracked = %v", plan.Untracked)
+	}
+	if diff := plan.Changes[2].Diff; !strings.Contains(diff, "-Step one.\n+Step two.\n") {
+		t.Fatalf("diff = %q", diff)
+	}
+
+	plan, err = helpcenter.Diff(portalTree(), local, helpcenter.DiffOptions{Prune: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := len(plan.Changes)
+	if n < 2 || plan.Changes[n-2].Key != "old" || plan.Changes[n-1].Key != "legacy (en)" || plan.Changes[n-1].Action != helpcenter.ActionDelete {
+		t.Fatalf("expected article then category deletes last, got %+v", plan.Changes)
+	}
+	if s := plan.Summary(); s[helpcenter.ActionDelete] != 2 || s[helpcenter.ActionCreate] != 3 {
+		t.Fatalf("summary = %v", s)
+	}
+}
+
+func TestParseArticleErrors(t *testing.T) {
+	tests := map[string]string{
+		"no front matter":                      "missing front matter",
+		"---\ntitle: x\n":                      "not closed",
+		"---\nslug: x\n---\nbody":              "needs a title",
+		"---\ntitle: x\nstatus: live\n---\n":   "invalid status",
+		"---\ntitle: x\nauthor: me\n---\nbody": "field author not found",
+	}
+	for data, want := range tests {
+		if _, err := helpcenter.ParseArticle([]byte(data)); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ParseArticle(%q) = %v, want error containing %q", data, err, want)
+		}
+	}
+
+	a, err := helpcenter.ParseArticle([]byte("---\r\ntitle: Hi\r\nstatus: Published\r\n---\r\n\r\nLine\r\n"))
+	if err != nil || a.Status != helpcenter.StatusPublished || a.Content != "Line" {
+		t.Fatalf("ParseArticle CRLF = %+v, %v", a, err)
+	}
+}
+
+func TestReadDirRejectsDuplicatesAndNesting(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "a.md"), "---\ntitle: A\nslug: same\n---\n")
+	writeFile(t, filepath.Join(dir, "faq", "b.md"), "---\ntitle: B\nslug: same\n---\n")
+	if _, err := helpcenter.ReadDir(dir); err == nil || !strings.Contains(err.Error(), `slug "same"`) {
+		t.Fatalf("expected duplicate slug error, got %v", err)
+	}
+
+	dir = t.TempDir()
+	writeFile(t, filepath.Join(dir, "faq", "deep", "c.md"), "---\ntitle: C\n---\n")
+	if _, err := helpcenter.ReadDir(dir); err == nil || !strings.Contains(err.Error(), "nested") {
+		t.Fatalf("expected nesting error, got %v", err)
+	}
+
+	// New categories need a locale to be created.
+	dir = t.TempDir()
+	writeFile(t, filepath.Join(dir, "faq", "c.md"), "---\ntitle: C\n---\n")
+	local, err := helpcenter.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := helpcenter.Diff(&helpcenter.Tree{}, local, helpcenter.DiffOptions{}); err == nil || !strings.Contains(err.Error(), "need a locale") {
+		t.Fatalf("expected locale error, got %v", err)
+	}
+}
+
+func TestLineDiff(t *testing.T) {
+	before := "a\nb\nc\nd\ne\nf\ng\nh\nj\nk"
+	after := "a\nb\nc\nD\ne\nf\ng\nh\nj\nk\nl"
+	want := "@@\n b\n c\n-d\n+D\n e\n f\n@@\n j\n k\n+l\n"
+	if got := helpcenter.LineDiff(before, after); got != want {
+		t.Fatalf("LineDiff =\n%s\nwant\n%s", got, want)
+	}
+	if got := helpcenter.LineDiff("", "x"); got != "@@\n+x\n" {
+		t.Fatalf("LineDiff from empty = %q", got)
+	}
+}
@@ -0,0 +1,306 @@
+package helpcenter
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Kind names what a Change touches.
+type Kind string
+
+const (
+	KindCategory Kind = "category"
+	KindArticle  Kind = "article"
+)
+
+// Action is what a Change does.
+type Action string
+
+const (
+	ActionCreate  Action = "create"
+	ActionUpdate  Action = "update"
+	ActionReorder Action = "reorder"
+	ActionDelete  Action = "delete"
+)
+
+// Change is one planned operation. Reorder changes list the article slugs
+// of one category in their new order.
+type Change struct {
+	Kind   Kind     `json:"kind"`
+	Action Action   `json:"action"`
+	Key    string   `json:"key"`
+	ID     int      `json:"id,omitempty"`
+	Path   string   `json:"path,omitempty"`
+	Fields []string `json:"fields,omitempty"`
+	Order  []string `json:"order,omitempty"`
+	// Diff is a line diff of the article content for updates.
+	Diff string `json:"diff,omitempty"`
+
+	category *Category
+	article  *Article
+}
+
+// Plan turns the portal into the directory. Changes run in order:
+// categories, articles, reorders, then deletes.
+type Plan struct {
+	Changes []Change `json:"changes"`
+	// Untracked lists portal articles and categories missing from the
+	// directory. They are deleted only when pruning.
+	Untracked []string `json:"untracked,omitempty"`
+
+	// categoryIDs and articleIDs resolve local categories and article
+	// slugs to portal IDs; Apply adds the ones it creates.
+	categoryIDs map[*Category]int
+	articleIDs  map[string]int
+}
+
+// Summary counts changes by action.
+func (p Plan) Summary() map[Action]int {
+	out := map[Action]int{ActionCreate: 0, ActionUpdate: 0, ActionReorder: 0, ActionDelete: 0}
+	for _, c := range p.Changes {
+		out[c.Action]++
+	}
+	return out
+}
+
+// Empty reports whether the plan has no changes.
+func (p Plan) Empty() bool {
+	return len(p.Changes) == 0
+}
+
+// DiffOptions controls planning.
+type DiffOptions struct {
+	// Prune plans deletes for portal articles and categories that are not
+	// in the directory.
+	Prune bool
+}
+
+// Diff plans the changes that make remote (the portal) match local (the
+// directory).
+func Diff(remote, local *Tree, opts DiffOptions) (Plan, error) {
+	plan := Plan{categoryIDs: map[*Category]int{}, articleIDs: map[string]int{}}
+	var articleDeletes, categoryDeletes []Change
+
+	// Categories, by slug and locale. A local category without a locale
+	// matches the only remote category with its slug.
+	matched := map[*Category]*Category{}
+	used := map[*Category]bool{}
+	for _, lc := range local.Categories {
+		rc, err := matchCategory(remote.Categories, lc)
+		if err != nil {
+			return Plan{}, err
+		}
+		if rc == nil {
+			if lc.Locale == "" {
+				return Plan{}, fmt.Errorf("%s: new categories need a locale in %s", lc.Dir, CategoryFile)
+			}
+			plan.Changes = append(plan.Changes, Change{Kind: KindCategory, Action: ActionCreate, Key: lc.Key(), Path: lc.Dir, category: lc})
+			continue
+		}
+		matched[lc] = rc
+		used[rc] = true
+		plan.categoryIDs[lc] = rc.ID
+		var fields []string
+		fields = appendIfChanged(fields, "name", rc.Name, lc.Name)
+		fields = appendIfChanged(fields, "description", rc.Description, lc.Description)
+		if lc.Position != 0 && lc.Position != rc.Position {
+			fields = append(fields, "position")
+		}
+		if len(fields) > 0 {
+			plan.Changes = append(plan.Changes, Change{Kind: KindCategory, Action: ActionUpdate, Key: rc.Key(), ID: rc.ID, Path: lc.Dir, Fields: fields, category: lc})
+		}
+	}
+	for _, rc := range remote.Categories {
+		if used[rc] {
+			continue
+		}
+		if opts.Prune {
+			categoryDeletes = append(categoryDeletes, Change{Kind: KindCategory, Action: ActionDelete, Key: rc.Key(), ID: rc.ID, category: rc})
+		} else {
+			plan.Untracked = append(plan.Untracked, "category "+rc.Key())
+		}
+	}
+
+	// Articles, by slug.
+	remoteArticles := map[string]*Article{}
+	for _, ra := range remote.Articles {
+		remoteArticles[ra.Slug] = ra
+	}
+	inPlace := map[*Article]bool{} // remote articles staying in their category
+	for _, la := range local.Articles {
+		ra := remoteArticles[la.Slug]
+		if ra == nil {
+			plan.Changes = append(plan.Changes, Change{Kind: KindArticle, Action: ActionCreate, Key: la.Slug, Path: la.Path, article: la})
+			continue
+		}
+		delete(remoteArticles, la.Slug)
+		plan.articleIDs[la.Slug] = ra.ID
+		var fields []string
+		fields = appendIfChanged(fields, "title", ra.Title, la.Title)
+		fields = appendIfChanged(fields, "description", ra.Description, la.Description)
+		contentChanged := ra.Content != la.Content
+		if contentChanged {
+			fields = append(fields, "content")
+		}
+		if la.Status != "" && la.Status != ra.Status {
+			fields = append(fields, "status")
+		}
+		if la.Locale != "" && la.Locale != ra.Locale {
+			fields = append(fields, "locale")
+		}
+		// matched has no entry for a new category, so its articles move.
+		if target := matched[la.Category]; la.Category != nil && target == nil || target != ra.Category {
+			fields = append(fields, "category")
+		} else {
+			inPlace[ra] = true
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		c := Change{Kind: KindArticle, Action: ActionUpdate, Key: la.Slug, ID: ra.ID, Path: la.Path, Fields: fields, article: la}
+		if contentChanged {
+			c.Diff = LineDiff(ra.Content, la.Content)
+		}
+		plan.Changes = append(plan.Changes, c)
+	}
+	leftover := make([]string, 0, len(remoteArticles))
+	for slug := range remoteArticles {
+		leftover = append(leftover, slug)
+	}
+	sort.Strings(leftover)
+	for _, slug := range leftover {
+		ra := remoteArticles[slug]
+		if opts.Prune {
+			articleDeletes = append(articleDeletes, Change{Kind: KindArticle, Action: ActionDelete, Key: slug, ID: ra.ID})
+		} else {
+			plan.Untracked = append(plan.Untracked, "article "+slug)
+		}
+	}
+
+	plan.Changes = append(plan.Changes, reorders(remote, local, matched, inPlace)...)
+	// Articles go before categories so none is left orphaned midway.
+	plan.Changes = append(plan.Changes, articleDeletes...)
+	plan.Changes = append(plan.Changes, categoryDeletes...)
+	return plan, nil
+}
+
+func matchCategory(remote []*Category, lc *Category) (*Category, error) {
+	var found []*Category
+	for _, rc := range remote {
+		if rc.Slug == lc.Slug && (lc.Locale == "" || rc.Locale == lc.Locale) {
+			found = append(found, rc)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return nil, nil
+	case 1:
+		return found[0], nil
+	default:
+		return nil, fmt.Errorf("%s: the portal has category %q in several locales; set locale in %s", lc.Dir, lc.Slug, CategoryFile)
+	}
+}
+
+// reorders plans a reorder for each category whose articles, sorted by
+// front matter position, are not already in that order. Articles that
+// are new or moved into a category are appended after the existing ones.
+func reorders(remote, local *Tree, matched map[*Category]*Category, inPlace map[*Article]bool) []Change {
+	var out []Change
+	groups := map[*Category][]*Article{}
+	var order []*Category
+	for _, la := range local.Articles {
+		if _, ok := groups[la.Category]; !ok {
+			order = append(order, la.Category)
+		}
+		groups[la.Category] = append(groups[la.Category], la)
+	}
+	for _, lc := range order {
+		articles := groups[lc]
+		if len(articles) < 2 {
+			continue
+		}
+		desired := make([]*Article, len(articles))
+		copy(desired, articles)
+		sortByPosition(desired)
+
+		var current []*Article
+		for _, ra := range remote.Articles {
+			if lc != nil && matched[lc] == nil {
+				break // a new category has no articles yet
+			}
+			if inPlace[ra] && ra.Category == matched[lc] {
+				current = append(current, ra)
+			}
+		}
+		sortByPosition(current)
+		predicted := make([]string, 0, len(desired))
+		seen := map[string]bool{}
+		for _, ra := range current {
+			predicted = append(predicted, ra.Slug)
+			seen[ra.Slug] = true
+		}
+		want := make([]string, 0, len(desired))
+		for _, la := range desired {
+			want = append(want, la.Slug)
+			if !seen[la.Slug] {
+				predicted = append(predicted, la.Slug)
+			}
+		}
+		if slices.Equal(want, predicted) {
+			continue
+		}
+		key := "(no category)"
+		if lc != nil {
+			key = lc.Key()
+		}
+		out = append(out, Change{Kind: KindCategory, Action: ActionReorder, Key: key, Order: want})
+	}
+	return out
+}
+
+func sortByPosition(articles []*Article) {
+	sort.SliceStable(articles, func(i, j int) bool {
+		if articles[i].Position != articles[j].Position {
+			return articles[i].Position < articles[j].Position
+		}
+		return articles[i].Slug < articles[j].Slug
+	})
+}
+
+func appendIfChanged(fields []string, name, cur, want string) []string {
+	if cur != want {
+		return append(fields, name)
+	}
+	return fields
+}
+
+// Text renders the plan for humans, with content diffs indented under
+// their article.
+func (p Plan) Text() string {
+	var b strings.Builder
+	for _, c := range p.Changes {
+		symbol := map[Action]string{ActionCreate: "+", ActionUpdate: "~", ActionReorder: "*", ActionDelete: "-"}[c.Action]
+		_, _ = fmt.Fprintf(&b, "%s %s %q", symbol, c.Kind, c.Key)
+		switch {
+		case len(c.Fields) > 0:
+			_, _ = fmt.Fprintf(&b, " (%s)", strings.Join(c.Fields, ", "))
+		case c.Action == ActionReorder:
+			_, _ = fmt.Fprintf(&b, " (%s)", strings.Join(c.Order, ", "))
+		}
+		b.WriteString("\n")
+		for _, line := range strings.Split(strings.TrimRight(c.Diff, "\n"), "\n") {
+			if line != "" {
+				_, _ = fmt.Fprintf(&b, "    %s\n", line)
+			}
+		}
+	}
+	for _, u := range p.Untracked {
+		_, _ = fmt.Fprintf(&b, "  %s is not in the directory (kept; use --prune to delete)\n", u)
+	}
+	s := p.Summary()
+	_, _ = fmt.Fprintf(&b, "\nPlan: %d to create, %d to update, %d to reorder, %d to delete.\n",
+		s[ActionCreate], s[ActionUpdate], s[ActionReorder], s[ActionDelete])
+	return b.String()
+}
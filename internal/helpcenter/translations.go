@@ -0,0 +1,247 @@
+package helpcenter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+)
+
+// NormalizeLocale writes a locale the way Chatwoot stores it: "zh-tw" and
+// "zh_tw" become "zh_TW", "EN" becomes "en".
+func NormalizeLocale(locale string) string {
+	parts := strings.FieldsFunc(strings.TrimSpace(locale), func(r rune) bool { return r == '-' || r == '_' })
+	if len(parts) == 0 {
+		return ""
+	}
+	parts[0] = strings.ToLower(parts[0])
+	for i := 1; i < len(parts); i++ {
+		switch len(parts[i]) {
+		case 4: // script, e.g. Hant
+			parts[i] = strings.ToUpper(parts[i][:1]) + strings.ToLower(parts[i][1:])
+		default: // region, e.g. TW
+			parts[i] = strings.ToUpper(parts[i])
+		}
+	}
+	return strings.Join(parts, "_")
+}
+
+// TranslationOptions controls the translation report.
+type TranslationOptions struct {
+	// Source is the locale translations are compared against. Empty picks
+	// the locale with the most articles.
+	Source string
+	// Locales are the locales every article should exist in. Empty means
+	// every locale seen in the portal.
+	Locales []string
+}
+
+// TranslatedArticle is one locale's version of an article.
+type TranslatedArticle struct {
+	ID        int       `json:"id"`
+	Title     string    `json:"title"`
+	Slug      string    `json:"slug"`
+	Status    string    `json:"status"`
+	UpdatedAt time.Time `json:"updated_at,omitzero"`
+	// Duplicates lists other articles in the same locale and group.
+	Duplicates []int `json:"duplicates,omitempty"`
+}
+
+// StaleTranslation is a translation last updated before its source.
+type StaleTranslation struct {
+	Locale string `json:"locale"`
+	// BehindSeconds is how long after the translation the source was edited.
+	BehindSeconds int64 `json:"behind_seconds"`
+}
+
+// TranslationGroup is one article across locales.
+type TranslationGroup struct {
+	Key      string                       `json:"key"`
+	Articles map[string]TranslatedArticle `json:"articles"`
+	Missing  []string                     `json:"missing,omitempty"`
+	Stale    []StaleTranslation           `json:"stale,omitempty"`
+}
+
+// OK reports whether the group exists and is current in every locale.
+func (g TranslationGroup) OK() bool {
+	return len(g.Missing) == 0 && len(g.Stale) == 0
+}
+
+// TranslationSummary counts groups by state.
+type TranslationSummary struct {
+	Groups   int `json:"groups"`
+	Complete int `json:"complete"`
+	Missing  int `json:"missing"` // missing translations, not groups
+	Stale    int `json:"stale"`
+}
+
+// TranslationReport pairs a portal's articles across locales.
+type TranslationReport struct {
+	Source  string             `json:"source_locale"`
+	Locales []string           `json:"locales"`
+	Groups  []TranslationGroup `json:"groups"`
+	Summary TranslationSummary `json:"summary"`
+}
+
+// Translations pairs articles across locales and flags missing and stale
+// translations.
+//
+// Articles linked through associated_article_id (Chatwoot's translation
+// link) share the key of the article they point to. Unlinked articles pair
+// by slug with any locale suffix removed, so "refunds" and
+// "refunds-zh-tw" match. Titles are not compared: they differ by language.
+func Translations(articles []api.Article, opts TranslationOptions) *TranslationReport {
+	byID := make(map[int]api.Article, len(articles))
+	linked := map[int]bool{}
+	for _, a := range articles {
+		a.Locale = NormalizeLocale(a.Locale)
+		byID[a.ID] = a
+		if a.AssociatedArticleID != 0 {
+			linked[a.ID] = true
+			linked[a.AssociatedArticleID] = true
+		}
+	}
+
+	counts := map[string]int{}
+	groups := map[string]map[string][]api.Article{}
+	var keys []string
+	for _, a := range articles {
+		a = byID[a.ID]
+		if a.Locale == "" {
+			continue // nothing to pair on
+		}
+		counts[a.Locale]++
+		key := translationKey(a, byID, linked)
+		if groups[key] == nil {
+			groups[key] = map[string][]api.Article{}
+			keys = append(keys, key)
+		}
+		groups[key][a.Locale] = append(groups[key][a.Locale], a)
+	}
+
+	report := &TranslationReport{Source: NormalizeLocale(opts.Source)}
+	for _, l := range opts.Locales {
+		if l = NormalizeLocale(l); l != "" {
+			report.Locales = append(report.Locales, l)
+		}
+	}
+	if len(report.Locales) == 0 {
+		for l := range counts {
+			report.Locales = append(report.Locales, l)
+		}
+		sort.Strings(report.Locales)
+	}
+	if report.Source == "" {
+		for _, l := range report.Locales {
+			if report.Source == "" || counts[l] > counts[report.Source] {
+				report.Source = l
+			}
+		}
+	}
+	if report.Source == "" {
+		return report
+	}
+	if !containsString(report.Locales, report.Source) {
+		report.Locales = append([]string{report.Source}, report.Locales...)
+	}
+
+	sort.Strings(keys)
+	for _, key := range keys {
+		g := TranslationGroup{Key: key, Articles: map[string]TranslatedArticle{}}
+		for locale, list := range groups[key] {
+			g.Articles[locale] = pickTranslation(list)
+		}
+		source, hasSource := g.Articles[report.Source]
+		for _, locale := range report.Locales {
+			t, ok := g.Articles[locale]
+			switch {
+			case !ok:
+				g.Missing = append(g.Missing, locale)
+			case hasSource && locale != report.Source && t.UpdatedAt.Before(source.UpdatedAt):
+				g.Stale = append(g.Stale, StaleTranslation{Locale: locale, BehindSeconds: int64(source.UpdatedAt.Sub(t.UpdatedAt).Seconds())})
+			}
+		}
+		report.Groups = append(report.Groups, g)
+		report.Summary.Groups++
+		report.Summary.Missing += len(g.Missing)
+		report.Summary.Stale += len(g.Stale)
+		if g.OK() {
+			report.Summary.Complete++
+		}
+	}
+	return report
+}
+
+// translationKey follows associated_article_id to the source article, or
+// falls back to the slug without its locale suffix.
+func translationKey(a api.Article, byID map[int]api.Article, linked map[int]bool) string {
+	if linked[a.ID] {
+		root := a
+		for range 10 { // guard against cycles
+			next, ok := byID[root.AssociatedArticleID]
+			if root.AssociatedArticleID == 0 || !ok {
+				break
+			}
+			root = next
+		}
+		if root.AssociatedArticleID != 0 {
+			// The source is not in this portal listing; key on its ID.
+			return fmt.Sprintf("#%d", root.AssociatedArticleID)
+		}
+		return slugStem(root.Slug, root.Locale)
+	}
+	return slugStem(a.Slug, a.Locale)
+}
+
+// slugStem drops a trailing locale from a slug: "refunds-zh-tw" with
+// locale zh_TW is "refunds".
+func slugStem(slug, locale string) string {
+	if slug == "" || locale == "" {
+		return slug
+	}
+	lower := strings.ToLower(slug)
+	variants := []string{
+		strings.ToLower(locale),
+		strings.ReplaceAll(strings.ToLower(locale), "_", "-"),
+		strings.ToLower(strings.SplitN(locale, "_", 2)[0]),
+	}
+	for _, v := range variants {
+		for _, sep := range []string{"-", "_", "."} {
+			if suffix := sep + v; strings.HasSuffix(lower, suffix) && len(lower) > len(suffix) {
+				return slug[:len(slug)-len(suffix)]
+			}
+		}
+	}
+	return slug
+}
+
+// pickTranslation returns the most recently updated article, listing the
+// others as duplicates.
+func pickTranslation(list []api.Article) TranslatedArticle {
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].UpdatedAt != list[j].UpdatedAt {
+			return list[i].UpdatedAt > list[j].UpdatedAt
+		}
+		return list[i].ID < list[j].ID
+	})
+	a := list[0]
+	t := TranslatedArticle{ID: a.ID, Title: a.Title, Slug: a.Slug, Status: a.Status}
+	if a.UpdatedAt > 0 {
+		t.UpdatedAt = a.UpdatedAtTime().UTC()
+	}
+	for _, d := range list[1:] {
+		t.Duplicates = append(t.Duplicates, d.ID)
+	}
+	return t
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
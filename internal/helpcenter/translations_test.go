@@ -0,0 +1,77 @@
+package helpcenter_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+	"github.com/chatwoot/chatwoot-cli/internal/helpcenter"
+)
+
+func TestNormalizeLocale(t *testing.T) {
+	tests := map[string]string{
+		"en":         "en",
+		"EN":         "en",
+		"zh-tw":      "zh_TW",
+		"zh_tw":      "zh_TW",
+		" pt-BR ":    "pt_BR",
+		"zh-hant-tw": "zh_Hant_TW",
+		"":           "",
+	}
+	for in, want := range tests {
+		if got := helpcenter.NormalizeLocale(in); got != want {
+			t.Errorf("NormalizeLocale(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestTranslationsPairsAndFlags(t *testing.T) {
+	articles := []api.Article{
+		{ID: 1, Title: "Refunds", Slug: "refunds", Locale: "en", UpdatedAt: 2000},
+		{ID: 2, Title: "退款", Slug: "tui-kuan", Locale: "zh_TW", AssociatedArticleID: 1, UpdatedAt: 1000},
+		{ID: 3, Title: "Invoices", Slug: "invoices", Locale: "en", UpdatedAt: 1000},
+		{ID: 4, Title: "發票", Slug: "invoices-zh-tw", Locale: "zh-TW", UpdatedAt: 1500},
+		{ID: 5, Title: "Shipping", Slug: "shipping", Locale: "en", UpdatedAt: 1000},
+		{ID: 6, Title: "帳號", Slug: "account-zh_TW", Locale: "zh_TW", UpdatedAt: 1000},
+		{ID: 7, Title: "Draft", Slug: "draft"},
+	}
+	report := helpcenter.Translations(articles, helpcenter.TranslationOptions{})
+	if report.Source != "en" || !reflect.DeepEqual(report.Locales, []string{"en", "zh_TW"}) {
+		t.Fatalf("source = %q, locales = %v", report.Source, report.Locales)
+	}
+
+	byKey := map[string]helpcenter.TranslationGroup{}
+	var keys []string
+	for _, g := range report.Groups {
+		byKey[g.Key] = g
+		keys = append(keys, g.Key)
+	}
+	if !reflect.DeepEqual(keys, []string{"account", "invoices", "refunds", "shipping"}) {
+		t.Fatalf("keys = %v", keys)
+	}
+	if g := byKey["refunds"]; g.Articles["zh_TW"].ID != 2 || len(g.Stale) != 1 || g.Stale[0].BehindSeconds != 1000 {
+		t.Fatalf("refunds = %+v", g)
+	}
+	if g := byKey["invoices"]; !g.OK() || g.Articles["zh_TW"].ID != 4 {
+		t.Fatalf("invoices = %+v", g)
+	}
+	if g := byKey["shipping"]; !reflect.DeepEqual(g.Missing, []string{"zh_TW"}) {
+		t.Fatalf("shipping missing = %v", g.Missing)
+	}
+	if g := byKey["account"]; !reflect.DeepEqual(g.Missing, []string{"en"}) || len(g.Stale) != 0 {
+		t.Fatalf("account = %+v", g)
+	}
+	want := helpcenter.TranslationSummary{Groups: 4, Complete: 1, Missing: 2, Stale: 1}
+	if report.Summary != want {
+		t.Fatalf("summary = %+v", report.Summary)
+	}
+
+	// An explicit source and locale list adds locales nobody has written yet.
+	report = helpcenter.Translations(articles, helpcenter.TranslationOptions{Source: "zh-tw", Locales: []string{"zh_CN"}})
+	if report.Source != "zh_TW" || !reflect.DeepEqual(report.Locales, []string{"zh_TW", "zh_CN"}) {
+		t.Fatalf("source = %q, locales = %v", report.Source, report.Locales)
+	}
+	if report.Summary.Missing != 5 {
+		t.Fatalf("summary = %+v", report.Summary)
+	}
+}
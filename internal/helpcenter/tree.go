@@ -0,0 +1,427 @@
+// Package helpcenter maps a help center portal to a directory of Markdown
+// files so articles can be written and reviewed in Git.
+//
+// Each sub-directory is a category, described by an optional _category.yaml.
+// Each .md file is an article whose front matter holds its title, slug,
+// status, position and locale; the body is the article content. Articles in
+// the root directory have no category. Names starting with "_" or "." are
+// not articles.
+//
+// Categories are matched by slug and locale, articles by slug, so renaming
+// a file keeps the article as long as its slug is unchanged.
+package helpcenter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+)
+
+// CategoryFile holds a category's metadata inside its directory.
+const CategoryFile = "_category.yaml"
+
+// Article statuses as written in front matter.
+const (
+	StatusDraft     = "draft"
+	StatusPublished = "published"
+	StatusArchived  = "archived"
+)
+
+// statusCodes are the values the API accepts for each status.
+var statusCodes = map[string]int{StatusDraft: 0, StatusPublished: 1, StatusArchived: 2}
+
+// Category is a category directory.
+type Category struct {
+	ID          int    `yaml:"-"`
+	Dir         string `yaml:"-"` // relative to the root
+	Name        string `yaml:"name"`
+	Slug        string `yaml:"slug"`
+	Locale      string `yaml:"locale,omitempty"`
+	Description string `yaml:"description,omitempty"`
+	Position    int    `yaml:"position,omitempty"`
+}
+
+// Key identifies the category in plans and messages.
+func (c *Category) Key() string {
+	if c == nil {
+		return ""
+	}
+	if c.Locale == "" {
+		return c.Slug
+	}
+	return c.Slug + " (" + c.Locale + ")"
+}
+
+// Article is a Markdown file. Empty Status and Locale are left unmanaged.
+type Article struct {
+	ID          int       `yaml:"-"`
+	Path        string    `yaml:"-"` // relative to the root
+	Category    *Category `yaml:"-"` // nil for the root directory
+	Title       string    `yaml:"title"`
+	Slug        string    `yaml:"slug"`
+	Status      string    `yaml:"status,omitempty"`
+	Position    int       `yaml:"position,omitempty"`
+	Locale      string    `yaml:"locale,omitempty"`
+	Description string    `yaml:"description,omitempty"`
+	Content     string    `yaml:"-"`
+}
+
+// Tree is a portal's categories and articles.
+type Tree struct {
+	Categories []*Category
+	Articles   []*Article
+}
+
+// FromPortal builds a tree from the API, laying categories out as
+// directories named by slug. Articles whose category is unknown go to the
+// root.
+func FromPortal(categories []api.Category, articles []api.Article) *Tree {
+	t := &Tree{}
+	byID := map[int]*Category{}
+	slugCount := map[string]int{}
+	for _, c := range categories {
+		slugCount[c.Slug]++
+	}
+	for _, c := range categories {
+		dir := c.Slug
+		if slugCount[c.Slug] > 1 && c.Locale != "" {
+			dir = c.Slug + "-" + c.Locale
+		}
+		cat := &Category{
+			ID:          c.ID,
+			Dir:         dir,
+			Name:        c.Name,
+			Slug:        c.Slug,
+			Locale:      c.Locale,
+			Description: c.Description,
+			Position:    c.Position,
+		}
+		byID[c.ID] = cat
+		t.Categories = append(t.Categories, cat)
+	}
+	for _, a := range articles {
+		art := &Article{
+			ID:          a.ID,
+			Category:    byID[a.CategoryID],
+			Title:       a.Title,
+			Slug:        a.Slug,
+			Status:      a.Status,
+			Position:    a.Position,
+			Locale:      a.Locale,
+			Description: a.Description,
+			Content:     normalizeContent(a.Content),
+		}
+		if art.Slug == "" {
+			art.Slug = fmt.Sprintf("article-%d", a.ID)
+		}
+		art.Path = art.Slug + ".md"
+		if art.Category != nil {
+			art.Path = filepath.Join(art.Category.Dir, art.Path)
+		}
+		t.Articles = append(t.Articles, art)
+	}
+	t.sort()
+	return t
+}
+
+func (t *Tree) sort() {
+	sort.SliceStable(t.Categories, func(i, j int) bool { return t.Categories[i].Dir < t.Categories[j].Dir })
+	sort.SliceStable(t.Articles, func(i, j int) bool { return t.Articles[i].Path < t.Articles[j].Path })
+}
+
+// ReadDir reads a tree from dir.
+func ReadDir(dir string) (*Tree, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	t := &Tree{}
+	for _, e := range entries {
+		if ignored(e.Name()) {
+			continue
+		}
+		if !e.IsDir() {
+			if isArticleFile(e.Name()) {
+				art, err := readArticle(dir, e.Name(), nil)
+				if err != nil {
+					return nil, err
+				}
+				t.Articles = append(t.Articles, art)
+			}
+			continue
+		}
+		cat, err := readCategory(dir, e.Name())
+		if err != nil {
+			return nil, err
+		}
+		t.Categories = append(t.Categories, cat)
+		files, err := os.ReadDir(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range files {
+			switch {
+			case ignored(f.Name()):
+			case f.IsDir():
+				return nil, fmt.Errorf("%s: nested directories are not supported; categories are one level deep", filepath.Join(e.Name(), f.Name()))
+			case isArticleFile(f.Name()):
+				art, err := readArticle(dir, filepath.Join(e.Name(), f.Name()), cat)
+				if err != nil {
+					return nil, err
+				}
+				t.Articles = append(t.Articles, art)
+			}
+		}
+	}
+	if err := t.validate(); err != nil {
+		return nil, err
+	}
+	t.sort()
+	return t, nil
+}
+
+func ignored(name string) bool {
+	return strings.HasPrefix(name, "_") || strings.HasPrefix(name, ".")
+}
+
+func isArticleFile(name string) bool {
+	return strings.EqualFold(filepath.Ext(name), ".md")
+}
+
+func readCategory(root, dir string) (*Category, error) {
+	cat := &Category{}
+	data, err := os.ReadFile(filepath.Join(root, dir, CategoryFile))
+	switch {
+	case err == nil:
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(cat); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%s: %w", filepath.Join(dir, CategoryFile), err)
+		}
+	case !os.IsNotExist(err):
+		return nil, err
+	}
+	cat.Dir = dir
+	if cat.Slug == "" {
+		cat.Slug = dir
+	}
+	if cat.Name == "" {
+		cat.Name = dir
+	}
+	return cat, nil
+}
+
+func readArticle(root, rel string, cat *Category) (*Article, error) {
+	data, err := os.ReadFile(filepath.Join(root, rel))
+	if err != nil {
+		return nil, err
+	}
+	art, err := ParseArticle(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", rel, err)
+	}
+	art.Path = rel
+	art.Category = cat
+	if art.Slug == "" {
+		art.Slug = strings.TrimSuffix(filepath.Base(rel), filepath.Ext(rel))
+	}
+	return art, nil
+}
+
+// ParseArticle decodes a Markdown file with YAML front matter.
+func ParseArticle(data []byte) (*Article, error) {
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	if !strings.HasPrefix(text, "---\n") {
+		return nil, fmt.Errorf("missing front matter (the file must start with ---)")
+	}
+	rest := text[len("---\n"):]
+	end := strings.Index(rest, "\n---\n")
+	var front, body string
+	switch {
+	case end >= 0:
+		front, body = rest[:end], rest[end+len("\n---\n"):]
+	case strings.HasSuffix(rest, "\n---"):
+		front = strings.TrimSuffix(rest, "\n---")
+	default:
+		return nil, fmt.Errorf("front matter is not closed with ---")
+	}
+
+	art := &Article{}
+	dec := yaml.NewDecoder(strings.NewReader(front))
+	dec.KnownFields(true)
+	if err := dec.Decode(art); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("front matter: %w", err)
+	}
+	if strings.TrimSpace(art.Title) == "" {
+		return nil, fmt.Errorf("front matter needs a title")
+	}
+	art.Status = strings.ToLower(strings.TrimSpace(art.Status))
+	if _, ok := statusCodes[art.Status]; art.Status != "" && !ok {
+		return nil, fmt.Errorf("invalid status %q (use draft, published or archived)", art.Status)
+	}
+	art.Content = normalizeContent(body)
+	return art, nil
+}
+
+// Markdown renders the article as front matter followed by its content.
+func (a *Article) Markdown() ([]byte, error) {
+	front, err := yaml.Marshal(a)
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	b.WriteString("---\n")
+	b.Write(front)
+	b.WriteString("---\n\n")
+	if a.Content != "" {
+		b.WriteString(a.Content)
+		b.WriteString("\n")
+	}
+	return b.Bytes(), nil
+}
+
+// normalizeContent drops the blank lines around a body so files and API
+// content compare equal regardless of trailing newlines.
+func normalizeContent(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.Trim(s, "\n")
+}
+
+func (t *Tree) validate() error {
+	slugs := map[string]string{}
+	for _, a := range t.Articles {
+		if prev, ok := slugs[a.Slug]; ok {
+			return fmt.Errorf("%s and %s both use slug %q", prev, a.Path, a.Slug)
+		}
+		slugs[a.Slug] = a.Path
+	}
+	keys := map[string]string{}
+	for _, c := range t.Categories {
+		key := c.Slug + "\x00" + c.Locale
+		if prev, ok := keys[key]; ok {
+			return fmt.Errorf("%s and %s are both category %q", prev, c.Dir, c.Key())
+		}
+		keys[key] = c.Dir
+	}
+	return nil
+}
+
+// PullResult reports what WriteDir changed.
+type PullResult struct {
+	Written   []string `json:"written"`
+	Unchanged int      `json:"unchanged"`
+	Removed   []string `json:"removed,omitempty"`
+	// Stale lists local files the portal no longer has; they are removed
+	// only when pruning.
+	Stale []string `json:"stale,omitempty"`
+}
+
+// WriteDir writes the tree to dir, leaving files whose content is already
+// current untouched. With prune, local articles and category files missing
+// from the tree are deleted. With dryRun nothing is written.
+func WriteDir(dir string, t *Tree, prune, dryRun bool) (*PullResult, error) {
+	res := &PullResult{Written: []string{}}
+	want := map[string][]byte{}
+	for _, c := range t.Categories {
+		data, err := yaml.Marshal(c)
+		if err != nil {
+			return nil, err
+		}
+		want[filepath.Join(c.Dir, CategoryFile)] = data
+	}
+	for _, a := range t.Articles {
+		data, err := a.Markdown()
+		if err != nil {
+			return nil, err
+		}
+		want[a.Path] = data
+	}
+
+	paths := make([]string, 0, len(want))
+	for p := range want {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, rel := range paths {
+		path := filepath.Join(dir, rel)
+		if cur, err := os.ReadFile(path); err == nil && bytes.Equal(cur, want[rel]) {
+			res.Unchanged++
+			continue
+		}
+		res.Written = append(res.Written, rel)
+		if dryRun {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(path, want[rel], 0o644); err != nil {
+			return nil, err
+		}
+	}
+
+	local, err := existingFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, rel := range local {
+		if _, ok := want[rel]; ok {
+			continue
+		}
+		if !prune {
+			res.Stale = append(res.Stale, rel)
+			continue
+		}
+		res.Removed = append(res.Removed, rel)
+		if dryRun {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, rel)); err != nil {
+			return nil, err
+		}
+		// Drop the category directory once nothing is left in it.
+		if sub := filepath.Dir(rel); sub != "." {
+			_ = os.Remove(filepath.Join(dir, sub))
+		}
+	}
+	return res, nil
+}
+
+// existingFiles lists the article and category files under dir.
+func existingFiles(dir string) ([]string, error) {
+	var out []string
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		switch {
+		case !e.IsDir() && !ignored(e.Name()) && isArticleFile(e.Name()):
+			out = append(out, e.Name())
+		case e.IsDir() && !ignored(e.Name()):
+			files, err := os.ReadDir(filepath.Join(dir, e.Name()))
+			if err != nil {
+				return nil, err
+			}
+			for _, f := range files {
+				if !f.IsDir() && (f.Name() == CategoryFile || (!ignored(f.Name()) && isArticleFile(f.Name()))) {
+					out = append(out, filepath.Join(e.Name(), f.Name()))
+				}
+			}
+		}
+	}
+	sort.Strings(out)
+	return out, nil
+}
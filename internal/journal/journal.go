@@ -0,0 +1,260 @@
+// Package journal keeps a local log of the conversation and contact changes
+// cw commands make, with the state before each change, so they can be
+// listed and undone.
+package journal
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+)
+
+// FileName is the journal file name under the config directory.
+const FileName = "journal.json"
+
+// Version is the on-disk format version of the journal file.
+const Version = 1
+
+// MaxOperations is how many operations the journal keeps; older ones are
+// dropped as new ones are added.
+const MaxOperations = 200
+
+// MaxChanges is how many changes one operation holds. A command that makes
+// more, such as a long-running daemon, is recorded as several operations.
+const MaxChanges = 1000
+
+// Lock timing for Update. Updates only hold the lock while reading and
+// writing the file, so an older lock was left behind by a killed process.
+const (
+	lockRetryInterval = 50 * time.Millisecond
+	lockWait          = 10 * time.Second
+	lockStale         = time.Minute
+)
+
+// Operation is one command's changes.
+type Operation struct {
+	ID        string       `json:"id"`
+	BaseURL   string       `json:"base_url"`
+	AccountID int          `json:"account_id"`
+	Command   string       `json:"command"`
+	CreatedAt time.Time    `json:"created_at"`
+	Changes   []api.Change `json:"changes"`
+	UndoOf    string       `json:"undo_of,omitempty"`
+	UndoneBy  string       `json:"undone_by,omitempty"`
+	UndoneAt  time.Time    `json:"undone_at,omitzero"`
+}
+
+// Undone reports whether the operation has been undone.
+func (o Operation) Undone() bool {
+	return !o.UndoneAt.IsZero()
+}
+
+// Journal is the content of the journal file, oldest operation first.
+type Journal struct {
+	Version    int         `json:"version"`
+	Operations []Operation `json:"operations"`
+}
+
+// Add appends op with a new ID, marks the operation it undoes, drops the
+// oldest operations beyond MaxOperations and returns the stored copy.
+func (j *Journal) Add(op Operation) Operation {
+	op.ID = newID(j)
+	if op.UndoOf != "" {
+		if undone, err := j.Find(op.UndoOf); err == nil {
+			undone.UndoneBy, undone.UndoneAt = op.ID, op.CreatedAt
+		}
+	}
+	j.Operations = append(j.Operations, op)
+	if n := len(j.Operations) - MaxOperations; n > 0 {
+		j.Operations = append([]Operation(nil), j.Operations[n:]...)
+	}
+	return op
+}
+
+// Find returns the operation with the given ID or unique ID prefix.
+func (j *Journal) Find(id string) (*Operation, error) {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return nil, fmt.Errorf("empty operation ID")
+	}
+	var match *Operation
+	for i := range j.Operations {
+		if j.Operations[i].ID == id {
+			return &j.Operations[i], nil
+		}
+		if strings.HasPrefix(j.Operations[i].ID, id) {
+			if match != nil {
+				return nil, fmt.Errorf("operation ID %q is ambiguous", id)
+			}
+			match = &j.Operations[i]
+		}
+	}
+	if match == nil {
+		return nil, fmt.Errorf("operation %q not found", id)
+	}
+	return match, nil
+}
+
+// Latest returns the most recent operation on the account that has not been
+// undone and is not itself an undo, so repeated undos step further back.
+func (j *Journal) Latest(baseURL string, accountID int) (*Operation, error) {
+	for i := len(j.Operations) - 1; i >= 0; i-- {
+		op := &j.Operations[i]
+		if op.BaseURL == baseURL && op.AccountID == accountID && !op.Undone() && op.UndoOf == "" {
+			return op, nil
+		}
+	}
+	return nil, fmt.Errorf("no operations to undo")
+}
+
+// Recent returns up to limit operations on the account, newest first. A
+// limit of 0 returns all of them.
+func (j *Journal) Recent(baseURL string, accountID int, limit int) []Operation {
+	ops := []Operation{}
+	for i := len(j.Operations) - 1; i >= 0; i-- {
+		op := j.Operations[i]
+		if op.BaseURL != baseURL || op.AccountID != accountID {
+			continue
+		}
+		ops = append(ops, op)
+		if limit > 0 && len(ops) == limit {
+			break
+		}
+	}
+	return ops
+}
+
+// Store reads and writes a journal file.
+type Store struct {
+	path string
+}
+
+// NewStore returns a store for the journal file at path.
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// DefaultPath returns the journal file path under dir.
+func DefaultPath(dir string) string {
+	return filepath.Join(dir, FileName)
+}
+
+// Path returns the journal file path.
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Load reads the journal. A missing file is an empty journal.
+func (s *Store) Load() (*Journal, error) {
+	j := &Journal{Version: Version}
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return j, nil
+		}
+		return nil, fmt.Errorf("read journal file: %w", err)
+	}
+	if err := json.Unmarshal(data, j); err != nil {
+		return nil, fmt.Errorf("parse journal file %s: %w", s.path, err)
+	}
+	if j.Version > Version {
+		return nil, fmt.Errorf("journal file version %d is newer than supported version %d", j.Version, Version)
+	}
+	j.Version = Version
+	return j, nil
+}
+
+// Update loads the journal under a lock file, calls fn, and saves the
+// journal if fn succeeds. The lock keeps concurrent cw processes from
+// overwriting each other's operations.
+func (s *Store) Update(fn func(j *Journal) error) error {
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	j, err := s.Load()
+	if err != nil {
+		return err
+	}
+	if err := fn(j); err != nil {
+		return err
+	}
+	return s.save(j)
+}
+
+func (s *Store) lock() (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return nil, fmt.Errorf("create journal dir: %w", err)
+	}
+	lockPath := s.path + ".lock"
+	deadline := time.Now().Add(lockWait)
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err == nil {
+			_, _ = fmt.Fprintf(f, "%d\n", os.Getpid())
+			_ = f.Close()
+			return func() { _ = os.Remove(lockPath) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("lock journal file: %w", err)
+		}
+		if info, statErr := os.Stat(lockPath); statErr == nil && time.Since(info.ModTime()) > lockStale {
+			_ = os.Remove(lockPath)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("journal file is locked by another process (remove %s if no cw process is running)", lockPath)
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+func (s *Store) save(j *Journal) error {
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("write journal file: %w", err)
+	}
+	tmpName := tmp.Name()
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpName)
+		return fmt.Errorf("write journal file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmpName)
+		return fmt.Errorf("write journal file: %w", err)
+	}
+	if err := os.Rename(tmpName, s.path); err != nil {
+		_ = os.Remove(tmpName)
+		return fmt.Errorf("write journal file: %w", err)
+	}
+	return nil
+}
+
+// newID returns a short random ID not yet used in j.
+func newID(j *Journal) string {
+	for {
+		buf := make([]byte, 4)
+		if _, err := rand.Read(buf); err != nil {
+			return fmt.Sprintf("%08x", time.Now().UnixNano()&0xffffffff)
+		}
+		id := hex.EncodeToString(buf)
+		if _, err := j.Find(id); err != nil {
+			return id
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package journal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+)
+
+const testBaseURL = "https://chat.example.com"
+
+var testNow = time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+
+func testOp(command string) Operation {
+	return Operation{
+		BaseURL:   testBaseURL,
+		AccountID: 1,
+		Command:   command,
+		CreatedAt: testNow,
+		Changes: []api.Change{{
+			Resource: api.ResourceConversation,
+			ID:       7,
+			Before:   map[string]any{"labels": []any{"billing"}},
+			After:    map[string]any{"labels": []any{"oops"}},
+		}},
+	}
+}
+
+func TestStoreRoundTrip(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "cw", FileName))
+	j, err := store.Load()
+	if err != nil || len(j.Operations) != 0 {
+		t.Fatalf("missing file: %+v, %v", j, err)
+	}
+
+	var added Operation
+	if err := store.Update(func(j *Journal) error {
+		added = j.Add(testOp("cw co bulk add-label"))
+		return nil
+	}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if len(added.ID) != 8 {
+		t.Errorf("id = %q", added.ID)
+	}
+
+	j, err = store.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	op, err := j.Find(added.ID[:3])
+	if err != nil {
+		t.Fatalf("Find by prefix: %v", err)
+	}
+	if op.Command != "cw co bulk add-label" || len(op.Changes) != 1 || op.Changes[0].ID != 7 {
+		t.Errorf("loaded %+v", op)
+	}
+	if _, err := os.Stat(store.Path() + ".lock"); !os.IsNotExist(err) {
+		t.Errorf("lock file should be removed, stat err = %v", err)
+	}
+}
+
+func TestLoadRejectsNewerVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	if err := os.WriteFile(path, []byte(`{"version": 99, "operations": []}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewStore(path).Load(); err == nil || !strings.Contains(err.Error(), "newer than supported") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestAddMarksUndoneAndTrims(t *testing.T) {
+	j := &Journal{Version: Version}
+	first := j.Add(testOp("cw close 7"))
+
+	undo := testOp("cw undo")
+	undo.UndoOf = first.ID
+	undo.CreatedAt = testNow.Add(time.Minute)
+	undo = j.Add(undo)
+
+	op, _ := j.Find(first.ID)
+	if !op.Undone() || op.UndoneBy != undo.ID || !op.UndoneAt.Equal(undo.CreatedAt) {
+		t.Errorf("first = %+v, want undone by %s", op, undo.ID)
+	}
+
+	for range MaxOperations {
+		j.Add(testOp("cw assign 7"))
+	}
+	if len(j.Operations) != MaxOperations {
+		t.Errorf("kept %d operations, want %d", len(j.Operations), MaxOperations)
+	}
+	if _, err := j.Find(first.ID); err == nil {
+		t.Error("oldest operation should have been dropped")
+	}
+}
+
+func TestLatestAndRecent(t *testing.T) {
+	j := &Journal{Version: Version}
+	older := j.Add(testOp("cw labels-add"))
+	undone := j.Add(testOp("cw close 7"))
+	undo := testOp("cw undo")
+	undo.UndoOf = undone.ID
+	j.Add(undo)
+	other := testOp("cw assign 7")
+	other.AccountID = 2
+	j.Add(other)
+
+	op, err := j.Latest(testBaseURL, 1)
+	if err != nil || op.ID != older.ID {
+		t.Fatalf("Latest = %+v, %v; want %s", op, err, older.ID)
+	}
+	if _, err := j.Latest(testBaseURL, 3); err == nil {
+		t.Error("expected no operations for another account")
+	}
+
+	recent := j.Recent(testBaseURL, 1, 2)
+	if len(recent) != 2 || recent[0].UndoOf != undone.ID || recent[1].ID != undone.ID {
+		t.Errorf("Recent = %+v", recent)
+	}
+	if all := j.Recent(testBaseURL, 1, 0); len(all) != 3 {
+		t.Errorf("Recent(0) returned %d operations, want 3", len(all))
+	}
+}
+
+func TestFindErrors(t *testing.T) {
+	j := &Journal{Operations: []Operation{{ID: "abc123"}, {ID: "abd456"}}}
+	for id, want := range map[string]string{
+		"":    "empty operation ID",
+		"ab":  "ambiguous",
+		"zzz": "not found",
+	} {
+		if _, err := j.Find(id); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Find(%q) err = %v, want %q", id, err, want)
+		}
+	}
+}
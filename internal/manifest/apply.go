@@ -0,0 +1,200 @@
+package manifest
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+)
+
+// Result is the outcome of applying one Change.
+type Result struct {
+	Change
+	Skipped bool   `json:"skipped,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Apply executes the plan in order. It keeps going after failures and
+// returns one Result per change; the error count is the number of failures.
+func Apply(ctx context.Context, client *api.Client, plan Plan) ([]Result, int) {
+	results := make([]Result, 0, len(plan.Changes))
+	failed := 0
+	refs := newTargetRefs(client)
+	for _, c := range plan.Changes {
+		if err := ctx.Err(); err != nil {
+			results = append(results, Result{Change: c, Error: err.Error()})
+			failed++
+			continue
+		}
+		if c.Action == ActionUpdate && c.Desired == nil {
+			results = append(results, Result{Change: c, Skipped: true})
+			continue
+		}
+		id, err := applyChange(ctx, client, refs, c)
+		r := Result{Change: c}
+		if id != 0 {
+			r.ID = id
+		}
+		if err != nil {
+			r.Error = err.Error()
+			failed++
+		}
+		results = append(results, r)
+	}
+	return results, failed
+}
+
+func applyChange(ctx context.Context, client *api.Client, refs *targetRefs, c Change) (int, error) {
+	if c.Action == ActionDelete {
+		return c.ID, deleteResource(ctx, client, c.Kind, c.ID)
+	}
+
+	switch want := c.Desired.(type) {
+	case Label:
+		if c.Action == ActionCreate {
+			show := want.ShowOnSidebar != nil && *want.ShowOnSidebar
+			l, err := client.Labels().Create(ctx, want.Title, want.Description, want.Color, show)
+			if err != nil {
+				return 0, err
+			}
+			return l.ID, nil
+		}
+		_, err := client.Labels().Update(ctx, c.ID, "", want.Description, want.Color, want.ShowOnSidebar)
+		return c.ID, err
+
+	case Team:
+		if c.Action == ActionCreate {
+			t, err := client.Teams().Create(ctx, want.Name, want.Description)
+			if err != nil {
+				return 0, err
+			}
+			return t.ID, nil
+		}
+		_, err := client.Teams().Update(ctx, c.ID, "", want.Description)
+		return c.ID, err
+
+	case Inbox:
+		settings := api.InboxSettings{
+			GreetingEnabled:      want.GreetingEnabled,
+			GreetingMessage:      want.GreetingMessage,
+			EnableAutoAssignment: want.EnableAutoAssignment,
+		}
+		if c.Action == ActionCreate {
+			if want.ChannelType == "" {
+				return 0, fmt.Errorf("channel_type is required to create inbox %q", want.Name)
+			}
+			in, err := client.Inboxes().Create(ctx, api.CreateInboxRequest{Name: want.Name, ChannelType: want.ChannelType, InboxSettings: settings})
+			if err != nil {
+				return 0, err
+			}
+			return in.ID, nil
+		}
+		_, err := client.Inboxes().Update(ctx, c.ID, api.UpdateInboxRequest{InboxSettings: settings})
+		return c.ID, err
+
+	case AgentBot:
+		if c.Action == ActionCreate {
+			b, err := client.AgentBots().Create(ctx, want.Name, want.OutgoingURL)
+			if err != nil {
+				return 0, err
+			}
+			return b.ID, nil
+		}
+		_, err := client.AgentBots().Update(ctx, c.ID, "", want.OutgoingURL)
+		return c.ID, err
+
+	case CannedResponse:
+		if c.Action == ActionCreate {
+			cr, err := client.CannedResponses().Create(ctx, want.ShortCode, want.Content)
+			if err != nil {
+				return 0, err
+			}
+			return cr.ID, nil
+		}
+		_, err := client.CannedResponses().Update(ctx, c.ID, want.ShortCode, want.Content)
+		return c.ID, err
+
+	case CustomAttribute:
+		if c.Action == ActionCreate {
+			if want.Name == "" || want.Type == "" {
+				return 0, fmt.Errorf("name and type are required to create custom attribute %q", want.Key)
+			}
+			a, err := client.CustomAttributes().Create(ctx, want.Name, want.Key, want.Model, want.Type)
+			if err != nil {
+				return 0, err
+			}
+			return a.ID, nil
+		}
+		_, err := client.CustomAttributes().Update(ctx, c.ID, want.Name)
+		return c.ID, err
+
+	case CustomFilter:
+		if c.Action == ActionCreate {
+			f, err := client.CustomFilters().Create(ctx, want.Name, want.Type, want.Query)
+			if err != nil {
+				return 0, err
+			}
+			return f.ID, nil
+		}
+		_, err := client.CustomFilters().Update(ctx, c.ID, "", want.Query)
+		return c.ID, err
+
+	case Webhook:
+		if c.Action == ActionCreate {
+			w, err := client.Webhooks().Create(ctx, want.URL, want.Subscriptions)
+			if err != nil {
+				return 0, err
+			}
+			return w.ID, nil
+		}
+		_, err := client.Webhooks().Update(ctx, c.ID, "", want.Subscriptions)
+		return c.ID, err
+
+	case AutomationRule:
+		conditions, actions, err := mapRuleRefs(want.Conditions, want.Actions, refs.mapper(ctx))
+		if err != nil {
+			return 0, err
+		}
+		if c.Action == ActionCreate {
+			r, err := client.AutomationRules().Create(ctx, want.Name, want.EventName, conditions, actions)
+			if err != nil {
+				return 0, err
+			}
+			// Create always yields an active rule.
+			if want.Active != nil && !*want.Active {
+				if _, err := client.AutomationRules().Update(ctx, r.ID, "", nil, nil, want.Active); err != nil {
+					return r.ID, fmt.Errorf("created but failed to deactivate: %w", err)
+				}
+			}
+			return r.ID, nil
+		}
+		_, err = client.AutomationRules().Update(ctx, c.ID, "", conditions, actions, want.Active)
+		return c.ID, err
+	}
+
+	return 0, fmt.Errorf("unsupported change for %s %q", c.Kind, c.Key)
+}
+
+func deleteResource(ctx context.Context, client *api.Client, kind Kind, id int) error {
+	switch kind {
+	case KindLabel:
+		return client.Labels().Delete(ctx, id)
+	case KindTeam:
+		return client.Teams().Delete(ctx, id)
+	case KindInbox:
+		return client.Inboxes().Delete(ctx, id)
+	case KindAgentBot:
+		return client.AgentBots().Delete(ctx, id)
+	case KindCannedResponse:
+		return client.CannedResponses().Delete(ctx, id)
+	case KindCustomAttribute:
+		return client.CustomAttributes().Delete(ctx, id)
+	case KindCustomFilter:
+		return client.CustomFilters().Delete(ctx, id)
+	case KindWebhook:
+		return client.Webhooks().Delete(ctx, id)
+	case KindAutomationRule:
+		return client.AutomationRules().Delete(ctx, id)
+	}
+	return fmt.Errorf("unsupported kind %q", kind)
+}
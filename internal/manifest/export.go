@@ -0,0 +1,185 @@
+package manifest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+)
+
+// customAttributeModels and customFilterTypes are listed separately because
+// the API only returns one model/type per request.
+var (
+	customAttributeModels = []string{"conversation", "contact"}
+	customFilterTypes     = []string{"conversation", "contact", "report"}
+)
+
+// Export reads the current configuration of the client's account. Every kind
+// is populated (possibly empty), and IDs are kept for diffing.
+func Export(ctx context.Context, client *api.Client) (*Manifest, error) {
+	m := &Manifest{Version: Version}
+
+	labels, err := client.Labels().List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list labels: %w", err)
+	}
+	m.Labels = make([]Label, 0, len(labels))
+	for _, l := range labels {
+		show := l.ShowOnSidebar
+		m.Labels = append(m.Labels, Label{ID: l.ID, Title: l.Title, Description: l.Description, Color: l.Color, ShowOnSidebar: &show})
+	}
+
+	teams, err := client.Teams().List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list teams: %w", err)
+	}
+	m.Teams = make([]Team, 0, len(teams))
+	for _, t := range teams {
+		m.Teams = append(m.Teams, Team{ID: t.ID, Name: t.Name, Description: t.Description})
+	}
+
+	inboxes, err := client.Inboxes().List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list inboxes: %w", err)
+	}
+	m.Inboxes = make([]Inbox, 0, len(inboxes))
+	for _, in := range inboxes {
+		greeting, autoAssign := in.GreetingEnabled, in.EnableAutoAssignment
+		m.Inboxes = append(m.Inboxes, Inbox{
+			ID:                   in.ID,
+			Name:                 in.Name,
+			ChannelType:          in.ChannelType,
+			GreetingEnabled:      &greeting,
+			GreetingMessage:      in.GreetingMessage,
+			EnableAutoAssignment: &autoAssign,
+		})
+	}
+
+	bots, err := client.AgentBots().List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list agent bots: %w", err)
+	}
+	m.AgentBots = make([]AgentBot, 0, len(bots))
+	for _, b := range bots {
+		if b.AccountID == 0 {
+			continue // system bots are not account configuration
+		}
+		m.AgentBots = append(m.AgentBots, AgentBot{ID: b.ID, Name: b.Name, OutgoingURL: b.OutgoingURL})
+	}
+
+	canned, err := client.CannedResponses().List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list canned responses: %w", err)
+	}
+	m.CannedResponses = make([]CannedResponse, 0, len(canned))
+	for _, c := range canned {
+		m.CannedResponses = append(m.CannedResponses, CannedResponse{ID: c.ID, ShortCode: c.ShortCode, Content: c.Content})
+	}
+
+	m.CustomAttributes = []CustomAttribute{}
+	for _, model := range customAttributeModels {
+		attrs, err := client.CustomAttributes().List(ctx, model)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s custom attributes: %w", model, err)
+		}
+		for _, a := range attrs {
+			m.CustomAttributes = append(m.CustomAttributes, CustomAttribute{
+				ID:    a.ID,
+				Key:   a.AttributeKey,
+				Model: normalizeAttributeModel(a.AttributeModel, model),
+				Name:  a.AttributeDisplayName,
+				Type:  a.AttributeDisplayType,
+			})
+		}
+	}
+
+	m.CustomFilters = []CustomFilter{}
+	for _, filterType := range customFilterTypes {
+		filters, err := client.CustomFilters().List(ctx, filterType)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s custom filters: %w", filterType, err)
+		}
+		for _, f := range filters {
+			if f.FilterType != "" && f.FilterType != filterType {
+				continue
+			}
+			m.CustomFilters = append(m.CustomFilters, CustomFilter{ID: f.ID, Name: f.Name, Type: filterType, Query: f.Query})
+		}
+	}
+
+	webhooks, err := client.Webhooks().List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	m.Webhooks = make([]Webhook, 0, len(webhooks))
+	for _, w := range webhooks {
+		subs := append([]string(nil), w.Subscriptions...)
+		sort.Strings(subs)
+		m.Webhooks = append(m.Webhooks, Webhook{ID: w.ID, URL: w.URL, Subscriptions: subs})
+	}
+
+	agents, err := client.Agents().List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list agents: %w", err)
+	}
+	m.Agents = make([]Agent, 0, len(agents))
+	for _, a := range agents {
+		m.Agents = append(m.Agents, Agent{ID: a.ID, Email: a.Email})
+	}
+
+	rules, err := client.AutomationRules().List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list automation rules: %w", err)
+	}
+	names := m.refNames()
+	m.AutomationRules = make([]AutomationRule, 0, len(rules))
+	for _, r := range rules {
+		conditions, actions, _ := mapRuleRefs(r.Conditions, r.Actions, namedRefs(names))
+		active := r.Active
+		m.AutomationRules = append(m.AutomationRules, AutomationRule{
+			ID:         r.ID,
+			Name:       r.Name,
+			EventName:  r.EventName,
+			Conditions: conditions,
+			Actions:    actions,
+			Active:     &active,
+		})
+	}
+
+	return m, nil
+}
+
+// normalizeAttributeModel maps API model names ("contact_attribute") to the
+// short form used in manifests ("contact").
+func normalizeAttributeModel(model, fallback string) string {
+	model = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(model)), "_attribute")
+	switch model {
+	case "contact", "1":
+		return "contact"
+	case "conversation", "0":
+		return "conversation"
+	default:
+		return fallback
+	}
+}
+
+// refNames maps the IDs of the manifest's labels, teams, inboxes, and agents
+// to the natural keys automation rules reference them by.
+func (m *Manifest) refNames() map[string]map[int]string {
+	names := map[string]map[int]string{refLabel: {}, refTeam: {}, refInbox: {}, refAgent: {}}
+	for _, l := range m.Labels {
+		names[refLabel][l.ID] = l.Title
+	}
+	for _, t := range m.Teams {
+		names[refTeam][t.ID] = t.Name
+	}
+	for _, in := range m.Inboxes {
+		names[refInbox][in.ID] = in.Name
+	}
+	for _, a := range m.Agents {
+		names[refAgent][a.ID] = a.Email
+	}
+	return names
+}
@@ -0,0 +1,186 @@
+// Package manifest describes account configuration (labels, teams, inboxes,
+// canned responses, custom attributes, custom filters, automation rules,
+// webhooks, and agent bots) as a single YAML/JSON document that can be
+// exported from one account, diffed against another, and applied.
+//
+// Resources are matched by natural key rather than ID so a manifest exported
+// from one account can be applied to another. A resource kind that is absent
+// from the manifest is left alone; empty or omitted fields are left unchanged.
+package manifest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Version is the manifest format version written by Export.
+const Version = 1
+
+// Manifest is the declarative configuration of one account.
+//
+// A nil slice means the kind is unmanaged; an empty slice means the kind is
+// managed and should contain nothing (relevant when pruning).
+type Manifest struct {
+	Version          int               `json:"version" yaml:"version"`
+	Labels           []Label           `json:"labels" yaml:"labels"`
+	Teams            []Team            `json:"teams" yaml:"teams"`
+	Inboxes          []Inbox           `json:"inboxes" yaml:"inboxes"`
+	AgentBots        []AgentBot        `json:"agent_bots" yaml:"agent_bots"`
+	CannedResponses  []CannedResponse  `json:"canned_responses" yaml:"canned_responses"`
+	CustomAttributes []CustomAttribute `json:"custom_attributes" yaml:"custom_attributes"`
+	CustomFilters    []CustomFilter    `json:"custom_filters" yaml:"custom_filters"`
+	Webhooks         []Webhook         `json:"webhooks" yaml:"webhooks"`
+	AutomationRules  []AutomationRule  `json:"automation_rules" yaml:"automation_rules"`
+
+	// Agents are not managed. Export lists them so automation rules can
+	// reference agents by email.
+	Agents []Agent `json:"-" yaml:"-"`
+}
+
+// Agent is an account member that automation rules may reference.
+type Agent struct {
+	ID    int
+	Email string
+}
+
+// Label is keyed by title (case-insensitive).
+type Label struct {
+	ID            int    `json:"-" yaml:"-"`
+	Title         string `json:"title" yaml:"title"`
+	Description   string `json:"description,omitempty" yaml:"description,omitempty"`
+	Color         string `json:"color,omitempty" yaml:"color,omitempty"`
+	ShowOnSidebar *bool  `json:"show_on_sidebar,omitempty" yaml:"show_on_sidebar,omitempty"`
+}
+
+// Team is keyed by name.
+type Team struct {
+	ID          int    `json:"-" yaml:"-"`
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+// Inbox is keyed by name. The channel type is only used on create.
+type Inbox struct {
+	ID                   int    `json:"-" yaml:"-"`
+	Name                 string `json:"name" yaml:"name"`
+	ChannelType          string `json:"channel_type,omitempty" yaml:"channel_type,omitempty"`
+	GreetingEnabled      *bool  `json:"greeting_enabled,omitempty" yaml:"greeting_enabled,omitempty"`
+	GreetingMessage      string `json:"greeting_message,omitempty" yaml:"greeting_message,omitempty"`
+	EnableAutoAssignment *bool  `json:"enable_auto_assignment,omitempty" yaml:"enable_auto_assignment,omitempty"`
+}
+
+// AgentBot is keyed by name.
+type AgentBot struct {
+	ID          int    `json:"-" yaml:"-"`
+	Name        string `json:"name" yaml:"name"`
+	OutgoingURL string `json:"outgoing_url,omitempty" yaml:"outgoing_url,omitempty"`
+}
+
+// CannedResponse is keyed by short code.
+type CannedResponse struct {
+	ID        int    `json:"-" yaml:"-"`
+	ShortCode string `json:"short_code" yaml:"short_code"`
+	Content   string `json:"content" yaml:"content"`
+}
+
+// CustomAttribute is keyed by model and attribute key. Only the display name
+// can be changed in place.
+type CustomAttribute struct {
+	ID    int    `json:"-" yaml:"-"`
+	Key   string `json:"key" yaml:"key"`
+	Model string `json:"model" yaml:"model"`
+	Name  string `json:"name" yaml:"name"`
+	Type  string `json:"type" yaml:"type"`
+}
+
+// CustomFilter is keyed by filter type and name.
+type CustomFilter struct {
+	ID    int            `json:"-" yaml:"-"`
+	Name  string         `json:"name" yaml:"name"`
+	Type  string         `json:"type" yaml:"type"`
+	Query map[string]any `json:"query,omitempty" yaml:"query,omitempty"`
+}
+
+// Webhook is keyed by URL.
+type Webhook struct {
+	ID            int      `json:"-" yaml:"-"`
+	URL           string   `json:"url" yaml:"url"`
+	Subscriptions []string `json:"subscriptions,omitempty" yaml:"subscriptions,omitempty"`
+}
+
+// AutomationRule is keyed by name. Teams and inboxes in conditions and
+// actions are referenced by name, agents by email, and labels by title, so
+// a rule can be applied to an account whose IDs differ.
+type AutomationRule struct {
+	ID         int              `json:"-" yaml:"-"`
+	Name       string           `json:"name" yaml:"name"`
+	EventName  string           `json:"event_name" yaml:"event_name"`
+	Conditions []map[string]any `json:"conditions,omitempty" yaml:"conditions,omitempty"`
+	Actions    []map[string]any `json:"actions,omitempty" yaml:"actions,omitempty"`
+	Active     *bool            `json:"active,omitempty" yaml:"active,omitempty"`
+}
+
+// Load reads a YAML or JSON manifest from path.
+func Load(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read manifest: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse decodes a YAML or JSON manifest. JSON is valid YAML, so one decoder handles both.
+func Parse(data []byte) (*Manifest, error) {
+	var m Manifest
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&m); err != nil {
+		return nil, fmt.Errorf("parse manifest: %w", err)
+	}
+	if m.Version == 0 {
+		m.Version = Version
+	}
+	if m.Version > Version {
+		return nil, fmt.Errorf("manifest version %d is newer than supported version %d", m.Version, Version)
+	}
+	return &m, nil
+}
+
+// Encode renders the manifest as "yaml" or "json".
+func Encode(m *Manifest, format string) ([]byte, error) {
+	switch strings.ToLower(format) {
+	case "", "yaml", "yml":
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(m); err != nil {
+			return nil, err
+		}
+		if err := enc.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case "json":
+		data, err := json.MarshalIndent(m, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	default:
+		return nil, fmt.Errorf("unsupported manifest format %q (use yaml or json)", format)
+	}
+}
+
+// FormatForPath infers the manifest format from a file extension.
+func FormatForPath(path string) string {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return "json"
+	}
+	return "yaml"
+}
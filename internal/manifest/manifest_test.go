@@ -0,0 +1,168 @@
+package manifest_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/chatwoot/chatwoot-cli/internal/manifest"
+)
+
+func boolPtr(v bool) *bool { return &v }
+
+func TestParseYAMLAndJSON(t *testing.T) {
+	yamlDoc := `
+version: 1
+labels:
+  - title: vip
+    color: "#FF0000"
+automation_rules:
+  - name: Route billing
+    event_name: conversation_created
+    conditions:
+      - attribute_key: content
+        filter_operator: contains
+        values: [refund]
+    actions:
+      - action_name: assign_team
+        action_params: [3]
+`
+	m, err := manifest.Parse([]byte(yamlDoc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Labels) != 1 || m.Labels[0].Color != "#FF0000" {
+		t.Fatalf("unexpected labels: %+v", m.Labels)
+	}
+	if m.Teams != nil {
+		t.Fatal("expected omitted kind to stay nil (unmanaged)")
+	}
+	if len(m.AutomationRules) != 1 || len(m.AutomationRules[0].Actions) != 1 {
+		t.Fatalf("unexpected rules: %+v", m.AutomationRules)
+	}
+
+	jsonDoc := `{"version": 1, "teams": [], "webhooks": [{"url": "https://example.com/hook", "subscriptions": ["message_created"]}]}`
+	m, err = manifest.Parse([]byte(jsonDoc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Teams == nil || len(m.Teams) != 0 {
+		t.Fatalf("expected empty managed teams, got %#v", m.Teams)
+	}
+	if len(m.Webhooks) != 1 {
+		t.Fatalf("unexpected webhooks: %+v", m.Webhooks)
+	}
+
+	if _, err := manifest.Parse([]byte("labels:\n  - titel: typo\n")); err == nil {
+		t.Fatal("expected unknown field error")
+	}
+	if _, err := manifest.Parse([]byte("version: 99\n")); err == nil || !strings.Contains(err.Error(), "newer") {
+		t.Fatalf("expected version error, got %v", err)
+	}
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	in := &manifest.Manifest{
+		Version:         manifest.Version,
+		Labels:          []manifest.Label{{ID: 9, Title: "vip", ShowOnSidebar: boolPtr(true)}},
+		CannedResponses: []manifest.CannedResponse{{ShortCode: "hi", Content: "Hello {{contact.name}}"}},
+	}
+	for _, format := range []string{"yaml", "json"} {
+		data, err := manifest.Encode(in, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(data), "9") {
+			t.Fatalf("%s: IDs must not be exported: %s", format, data)
+		}
+		out, err := manifest.Parse(data)
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if out.Labels[0].Title != "vip" || !*out.Labels[0].ShowOnSidebar || out.CannedResponses[0].Content != "Hello {{contact.name}}" {
+			t.Fatalf("%s: round trip mismatch: %+v", format, out)
+		}
+	}
+	if got := manifest.FormatForPath("x.JSON"); got != "json" {
+		t.Fatalf("FormatForPath = %q", got)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	current := &manifest.Manifest{
+		Labels: []manifest.Label{
+			{ID: 1, Title: "VIP", Color: "#ff0000", ShowOnSidebar: boolPtr(true)},
+			{ID: 2, Title: "old"},
+		},
+		Teams: []manifest.Team{{ID: 5, Name: "Support", Description: "L1"}},
+		CustomAttributes: []manifest.CustomAttribute{
+			{ID: 7, Key: "plan", Model: "contact", Name: "Plan", Type: "text"},
+		},
+		Webhooks: []manifest.Webhook{{ID: 3, URL: "https://example.com/hook", Subscriptions: []string{"b", "a"}}},
+		AutomationRules: []manifest.AutomationRule{{
+			ID: 4, Name: "Route", EventName: "conversation_created",
+			Actions: []map[string]any{{"action_name": "assign_team", "action_params": []any{"Support"}}},
+		}},
+	}
+	desired := &manifest.Manifest{
+		Labels: []manifest.Label{
+			{Title: "vip", Color: "#FF0000"}, // same, different case
+			{Title: "new"},
+		},
+		Teams: []manifest.Team{{Name: "Support", Description: "Tier 1"}},
+		CustomAttributes: []manifest.CustomAttribute{
+			{Key: "plan", Model: "contact_attribute", Name: "Plan", Type: "list"},
+		},
+		Webhooks: []manifest.Webhook{{URL: "https://example.com/hook", Subscriptions: []string{"a", "b"}}},
+		AutomationRules: []manifest.AutomationRule{{
+			Name: "Route", EventName: "conversation_created",
+			Actions: []map[string]any{{"action_name": "assign_team", "action_params": []any{"support"}}},
+		}},
+	}
+
+	plan, err := manifest.Diff(current, desired, manifest.DiffOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, c := range plan.Changes {
+		got = append(got, string(c.Action)+" "+string(c.Kind)+" "+c.Key+" "+strings.Join(c.Fields, ","))
+	}
+	want := []string{
+		"create label new ",
+		"update team Support description",
+		"update custom_attribute contact/plan ",
+	}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("plan mismatch:\n got %q\nwant %q", got, want)
+	}
+	if len(plan.Changes[2].Warnings) != 1 || plan.Changes[2].Desired != nil {
+		t.Fatalf("expected warning-only change, got %+v", plan.Changes[2])
+	}
+
+	pruned, err := manifest.Diff(current, desired, manifest.DiffOptions{Prune: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := pruned.Changes[len(pruned.Changes)-1]
+	if last.Action != manifest.ActionDelete || last.Key != "old" || last.ID != 2 {
+		t.Fatalf("expected label delete last, got %+v", last)
+	}
+	if s := pruned.Summary(); s[manifest.ActionDelete] != 1 || s[manifest.ActionCreate] != 1 {
+		t.Fatalf("unexpected summary: %v", s)
+	}
+
+	for _, params := range [][]any{{"Sales"}, {3}} {
+		bad := &manifest.Manifest{AutomationRules: []manifest.AutomationRule{{
+			Name: "Route", EventName: "conversation_created",
+			Actions: []map[string]any{{"action_name": "assign_team", "action_params": params}},
+		}}}
+		if _, err := manifest.Diff(current, bad, manifest.DiffOptions{}); err == nil || !strings.Contains(err.Error(), "team") {
+			t.Fatalf("expected unresolved team error for %v, got %v", params, err)
+		}
+	}
+
+	dup := &manifest.Manifest{Teams: []manifest.Team{{Name: "A"}, {Name: "A"}}}
+	if _, err := manifest.Diff(current, dup, manifest.DiffOptions{}); err == nil {
+		t.Fatal("expected duplicate key error")
+	}
+}
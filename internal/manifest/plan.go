@@ -0,0 +1,332 @@
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Kind names a resource kind in a manifest.
+type Kind string
+
+const (
+	KindLabel           Kind = "label"
+	KindTeam            Kind = "team"
+	KindInbox           Kind = "inbox"
+	KindAgentBot        Kind = "agent_bot"
+	KindCannedResponse  Kind = "canned_response"
+	KindCustomAttribute Kind = "custom_attribute"
+	KindCustomFilter    Kind = "custom_filter"
+	KindWebhook         Kind = "webhook"
+	KindAutomationRule  Kind = "automation_rule"
+)
+
+// Action is what a Change does.
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+// Change is one planned operation. Desired holds the manifest item for
+// creates and updates.
+type Change struct {
+	Kind     Kind     `json:"kind"`
+	Action   Action   `json:"action"`
+	Key      string   `json:"key"`
+	ID       int      `json:"id,omitempty"`
+	Fields   []string `json:"fields,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+	Desired  any      `json:"-"`
+}
+
+// Plan is the ordered list of changes that turns current into desired.
+// Creates and updates run in dependency order; deletes run last, in reverse.
+type Plan struct {
+	Changes []Change `json:"changes"`
+}
+
+// Summary counts changes by action.
+func (p Plan) Summary() map[Action]int {
+	out := map[Action]int{ActionCreate: 0, ActionUpdate: 0, ActionDelete: 0}
+	for _, c := range p.Changes {
+		out[c.Action]++
+	}
+	return out
+}
+
+// Empty reports whether the plan has no changes.
+func (p Plan) Empty() bool {
+	return len(p.Changes) == 0
+}
+
+// DiffOptions controls planning.
+type DiffOptions struct {
+	// Prune plans deletes for resources present in the account but missing
+	// from a managed kind in the manifest.
+	Prune bool
+}
+
+// Diff plans the changes needed to make current match desired.
+func Diff(current, desired *Manifest, opts DiffOptions) (Plan, error) {
+	var upserts, deletes []Change
+	add := func(u, d []Change, err error) error {
+		if err != nil {
+			return err
+		}
+		upserts = append(upserts, u...)
+		deletes = append(deletes, d...)
+		return nil
+	}
+
+	if err := add(diffKind(KindLabel, current.Labels, desired.Labels, opts,
+		func(l Label) string { return strings.ToLower(strings.TrimSpace(l.Title)) },
+		func(l Label) int { return l.ID },
+		func(cur, want Label) ([]string, []string) {
+			var fields []string
+			fields = appendIfStringChanged(fields, "description", cur.Description, want.Description)
+			fields = appendIfColorChanged(fields, cur.Color, want.Color)
+			fields = appendIfBoolChanged(fields, "show_on_sidebar", cur.ShowOnSidebar, want.ShowOnSidebar)
+			return fields, nil
+		},
+	)); err != nil {
+		return Plan{}, err
+	}
+
+	if err := add(diffKind(KindTeam, current.Teams, desired.Teams, opts,
+		func(t Team) string { return strings.TrimSpace(t.Name) },
+		func(t Team) int { return t.ID },
+		func(cur, want Team) ([]string, []string) {
+			return appendIfStringChanged(nil, "description", cur.Description, want.Description), nil
+		},
+	)); err != nil {
+		return Plan{}, err
+	}
+
+	if err := add(diffKind(KindInbox, current.Inboxes, desired.Inboxes, opts,
+		func(i Inbox) string { return strings.TrimSpace(i.Name) },
+		func(i Inbox) int { return i.ID },
+		func(cur, want Inbox) ([]string, []string) {
+			var fields, warnings []string
+			fields = appendIfBoolChanged(fields, "greeting_enabled", cur.GreetingEnabled, want.GreetingEnabled)
+			fields = appendIfStringChanged(fields, "greeting_message", cur.GreetingMessage, want.GreetingMessage)
+			fields = appendIfBoolChanged(fields, "enable_auto_assignment", cur.EnableAutoAssignment, want.EnableAutoAssignment)
+			if want.ChannelType != "" && cur.ChannelType != "" && want.ChannelType != cur.ChannelType {
+				warnings = append(warnings, fmt.Sprintf("channel_type %s cannot be changed to %s in place", cur.ChannelType, want.ChannelType))
+			}
+			return fields, warnings
+		},
+	)); err != nil {
+		return Plan{}, err
+	}
+
+	if err := add(diffKind(KindAgentBot, current.AgentBots, desired.AgentBots, opts,
+		func(b AgentBot) string { return strings.TrimSpace(b.Name) },
+		func(b AgentBot) int { return b.ID },
+		func(cur, want AgentBot) ([]string, []string) {
+			return appendIfStringChanged(nil, "outgoing_url", cur.OutgoingURL, want.OutgoingURL), nil
+		},
+	)); err != nil {
+		return Plan{}, err
+	}
+
+	if err := add(diffKind(KindCannedResponse, current.CannedResponses, desired.CannedResponses, opts,
+		func(c CannedResponse) string { return strings.TrimSpace(c.ShortCode) },
+		func(c CannedResponse) int { return c.ID },
+		func(cur, want CannedResponse) ([]string, []string) {
+			return appendIfStringChanged(nil, "content", cur.Content, want.Content), nil
+		},
+	)); err != nil {
+		return Plan{}, err
+	}
+
+	if err := add(diffKind(KindCustomAttribute, current.CustomAttributes, desired.CustomAttributes, opts,
+		func(a CustomAttribute) string {
+			return normalizeAttributeModel(a.Model, a.Model) + "/" + strings.TrimSpace(a.Key)
+		},
+		func(a CustomAttribute) int { return a.ID },
+		func(cur, want CustomAttribute) ([]string, []string) {
+			var warnings []string
+			if want.Type != "" && cur.Type != "" && want.Type != cur.Type {
+				warnings = append(warnings, fmt.Sprintf("type %s cannot be changed to %s in place", cur.Type, want.Type))
+			}
+			return appendIfStringChanged(nil, "name", cur.Name, want.Name), warnings
+		},
+	)); err != nil {
+		return Plan{}, err
+	}
+
+	if err := add(diffKind(KindCustomFilter, current.CustomFilters, desired.CustomFilters, opts,
+		func(f CustomFilter) string { return strings.TrimSpace(f.Type) + "/" + strings.TrimSpace(f.Name) },
+		func(f CustomFilter) int { return f.ID },
+		func(cur, want CustomFilter) ([]string, []string) {
+			if want.Query != nil && !jsonEqual(cur.Query, want.Query) {
+				return []string{"query"}, nil
+			}
+			return nil, nil
+		},
+	)); err != nil {
+		return Plan{}, err
+	}
+
+	if err := add(diffKind(KindWebhook, current.Webhooks, desired.Webhooks, opts,
+		func(w Webhook) string { return strings.TrimSpace(w.URL) },
+		func(w Webhook) int { return w.ID },
+		func(cur, want Webhook) ([]string, []string) {
+			if want.Subscriptions != nil && !sameStringSet(cur.Subscriptions, want.Subscriptions) {
+				return []string{"subscriptions"}, nil
+			}
+			return nil, nil
+		},
+	)); err != nil {
+		return Plan{}, err
+	}
+
+	known := ruleRefKeys(current, desired)
+	for _, r := range desired.AutomationRules {
+		if err := checkRuleRefs(r, known); err != nil {
+			return Plan{}, fmt.Errorf("%s %q: %w", KindAutomationRule, strings.TrimSpace(r.Name), err)
+		}
+	}
+	if err := add(diffKind(KindAutomationRule, current.AutomationRules, desired.AutomationRules, opts,
+		func(r AutomationRule) string { return strings.TrimSpace(r.Name) },
+		func(r AutomationRule) int { return r.ID },
+		func(cur, want AutomationRule) ([]string, []string) {
+			var fields, warnings []string
+			if want.EventName != "" && cur.EventName != "" && want.EventName != cur.EventName {
+				warnings = append(warnings, fmt.Sprintf("event_name %s cannot be changed to %s in place", cur.EventName, want.EventName))
+			}
+			curConditions, curActions := foldRuleRefs(cur)
+			wantConditions, wantActions := foldRuleRefs(want)
+			if want.Conditions != nil && !jsonEqual(curConditions, wantConditions) {
+				fields = append(fields, "conditions")
+			}
+			if want.Actions != nil && !jsonEqual(curActions, wantActions) {
+				fields = append(fields, "actions")
+			}
+			fields = appendIfBoolChanged(fields, "active", cur.Active, want.Active)
+			return fields, warnings
+		},
+	)); err != nil {
+		return Plan{}, err
+	}
+
+	slices.Reverse(deletes)
+	return Plan{Changes: append(upserts, deletes...)}, nil
+}
+
+// diffKind plans creates/updates for desired items and, when pruning, deletes
+// for current items not in desired. A nil desired slice leaves the kind alone.
+func diffKind[T any](
+	kind Kind,
+	current, desired []T,
+	opts DiffOptions,
+	key func(T) string,
+	id func(T) int,
+	changed func(cur, want T) (fields, warnings []string),
+) ([]Change, []Change, error) {
+	if desired == nil {
+		return nil, nil, nil
+	}
+
+	byKey := make(map[string]T, len(current))
+	for _, item := range current {
+		byKey[key(item)] = item
+	}
+
+	var upserts []Change
+	seen := make(map[string]bool, len(desired))
+	for i, want := range desired {
+		k := key(want)
+		if k == "" || strings.HasSuffix(k, "/") || strings.HasPrefix(k, "/") {
+			return nil, nil, fmt.Errorf("%s #%d: missing key field", kind, i+1)
+		}
+		if seen[k] {
+			return nil, nil, fmt.Errorf("%s %q appears more than once in the manifest", kind, k)
+		}
+		seen[k] = true
+
+		cur, ok := byKey[k]
+		if !ok {
+			upserts = append(upserts, Change{Kind: kind, Action: ActionCreate, Key: k, Desired: want})
+			continue
+		}
+		fields, warnings := changed(cur, want)
+		if len(fields) == 0 && len(warnings) == 0 {
+			continue
+		}
+		c := Change{Kind: kind, Action: ActionUpdate, Key: k, ID: id(cur), Fields: fields, Warnings: warnings, Desired: want}
+		if len(fields) == 0 {
+			// Nothing can be updated; keep the change for its warnings only.
+			c.Desired = nil
+		}
+		upserts = append(upserts, c)
+	}
+
+	var deletes []Change
+	if opts.Prune {
+		for _, item := range current {
+			k := key(item)
+			if seen[k] {
+				continue
+			}
+			deletes = append(deletes, Change{Kind: kind, Action: ActionDelete, Key: k, ID: id(item)})
+		}
+	}
+	return upserts, deletes, nil
+}
+
+func appendIfStringChanged(fields []string, name, cur, want string) []string {
+	if want != "" && want != cur {
+		return append(fields, name)
+	}
+	return fields
+}
+
+func appendIfColorChanged(fields []string, cur, want string) []string {
+	if want != "" && !strings.EqualFold(want, cur) {
+		return append(fields, "color")
+	}
+	return fields
+}
+
+func appendIfBoolChanged(fields []string, name string, cur, want *bool) []string {
+	if want != nil && (cur == nil || *cur != *want) {
+		return append(fields, name)
+	}
+	return fields
+}
+
+func sameStringSet(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	x := append([]string(nil), a...)
+	y := append([]string(nil), b...)
+	sort.Strings(x)
+	sort.Strings(y)
+	return slices.Equal(x, y)
+}
+
+// jsonEqual compares values after a JSON round-trip so YAML ints and API
+// float64s compare equal.
+func jsonEqual(a, b any) bool {
+	return reflect.DeepEqual(jsonNormalize(a), jsonNormalize(b))
+}
+
+func jsonNormalize(v any) any {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var out any
+	if err := json.Unmarshal(data, &out); err != nil {
+		return v
+	}
+	return out
+}
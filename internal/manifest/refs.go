@@ -0,0 +1,300 @@
+package manifest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"strconv"
+	"strings"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+)
+
+// Automation rules point at teams, inboxes, and agents by ID, and at labels by
+// title. IDs differ between accounts, so Export rewrites them to natural keys
+// (team and inbox name, agent email), Diff checks that every reference names
+// a resource in the target account or the manifest, and Apply translates them
+// back to the target account's IDs.
+const (
+	refLabel = "label"
+	refTeam  = "team"
+	refInbox = "inbox"
+	refAgent = "agent"
+)
+
+// ruleConditionRefs maps condition attribute keys to the resource their
+// values point at.
+var ruleConditionRefs = map[string]string{
+	"inbox_id":    refInbox,
+	"team_id":     refTeam,
+	"assignee_id": refAgent,
+	"labels":      refLabel,
+}
+
+// ruleActionRefs maps action names to the resource their params point at.
+var ruleActionRefs = map[string]string{
+	"assign_team":  refTeam,
+	"assign_agent": refAgent,
+	"add_label":    refLabel,
+	"remove_label": refLabel,
+}
+
+// refMapper translates one reference.
+type refMapper func(resource string, ref any) (any, error)
+
+// mapRuleRefs returns copies of conditions and actions with every team, inbox,
+// agent, and label reference passed through fn.
+func mapRuleRefs(conditions, actions []map[string]any, fn refMapper) ([]map[string]any, []map[string]any, error) {
+	var outConditions, outActions []map[string]any
+	if conditions != nil {
+		outConditions = make([]map[string]any, 0, len(conditions))
+	}
+	for _, cond := range conditions {
+		c := maps.Clone(cond)
+		key, _ := c["attribute_key"].(string)
+		if resource, ok := ruleConditionRefs[key]; ok {
+			values, err := mapRefList(c["values"], resource, fn)
+			if err != nil {
+				return nil, nil, fmt.Errorf("condition %s: %w", key, err)
+			}
+			c["values"] = values
+		}
+		outConditions = append(outConditions, c)
+	}
+
+	if actions != nil {
+		outActions = make([]map[string]any, 0, len(actions))
+	}
+	for _, act := range actions {
+		a := maps.Clone(act)
+		name, _ := a["action_name"].(string)
+		if resource, ok := ruleActionRefs[name]; ok {
+			params, err := mapRefList(a["action_params"], resource, fn)
+			if err != nil {
+				return nil, nil, fmt.Errorf("action %s: %w", name, err)
+			}
+			a["action_params"] = params
+		} else if name == "send_email_to_team" {
+			params, _ := a["action_params"].([]any)
+			out := make([]any, 0, len(params))
+			for _, p := range params {
+				if pm, ok := p.(map[string]any); ok {
+					pm = maps.Clone(pm)
+					teams, err := mapRefList(pm["team_ids"], refTeam, fn)
+					if err != nil {
+						return nil, nil, fmt.Errorf("action %s: %w", name, err)
+					}
+					pm["team_ids"] = teams
+					p = pm
+				}
+				out = append(out, p)
+			}
+			a["action_params"] = out
+		}
+		outActions = append(outActions, a)
+	}
+	return outConditions, outActions, nil
+}
+
+// mapRefList maps every entry of a values/params list. Entries may be bare
+// references or {"id": ..., "name": ...} objects as stored by the UI; the
+// string "nil" (no team, no assignee) is kept as-is.
+func mapRefList(v any, resource string, fn refMapper) ([]any, error) {
+	list, ok := v.([]any)
+	if !ok {
+		if v == nil {
+			return nil, nil
+		}
+		list = []any{v}
+	}
+	out := make([]any, 0, len(list))
+	for _, entry := range list {
+		if m, ok := entry.(map[string]any); ok {
+			entry = m["id"]
+		}
+		if s, ok := entry.(string); ok && s == "nil" {
+			out = append(out, entry)
+			continue
+		}
+		mapped, err := fn(resource, entry)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, mapped)
+	}
+	return out, nil
+}
+
+// refID parses a numeric reference. Label references are titles, so only
+// JSON/YAML numbers count as label IDs.
+func refID(resource string, v any) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, n > 0
+	case int64:
+		return int(n), n > 0
+	case float64:
+		return int(n), n > 0 && n == float64(int(n))
+	case json.Number:
+		i, err := n.Int64()
+		return int(i), err == nil && i > 0
+	case string:
+		if resource == refLabel {
+			return 0, false
+		}
+		i, err := strconv.Atoi(strings.TrimSpace(n))
+		return i, err == nil && i > 0
+	}
+	return 0, false
+}
+
+// refKey normalizes a natural key for lookups.
+func refKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// namedRefs rewrites IDs to natural keys using names (resource -> ID -> key).
+// IDs with no match are kept, so Diff reports them.
+func namedRefs(names map[string]map[int]string) refMapper {
+	return func(resource string, ref any) (any, error) {
+		if id, ok := refID(resource, ref); ok {
+			if name, ok := names[resource][id]; ok {
+				return name, nil
+			}
+		}
+		return ref, nil
+	}
+}
+
+// checkRuleRefs returns an error for the first reference in rule that is not
+// the natural key of a resource in known (resource -> key set).
+func checkRuleRefs(rule AutomationRule, known map[string]map[string]bool) error {
+	_, _, err := mapRuleRefs(rule.Conditions, rule.Actions, func(resource string, ref any) (any, error) {
+		name, ok := ref.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s %v must be referenced by %s", resource, ref, refKeyField(resource))
+		}
+		if !known[resource][refKey(name)] {
+			return nil, fmt.Errorf("%s %q not found in the target account or the manifest", resource, name)
+		}
+		return ref, nil
+	})
+	return err
+}
+
+// foldRuleRefs returns the rule's conditions and actions with references
+// normalized by refKey, so references differing only in case compare equal.
+func foldRuleRefs(rule AutomationRule) ([]map[string]any, []map[string]any) {
+	conditions, actions, _ := mapRuleRefs(rule.Conditions, rule.Actions, func(_ string, ref any) (any, error) {
+		if name, ok := ref.(string); ok {
+			return refKey(name), nil
+		}
+		return ref, nil
+	})
+	return conditions, actions
+}
+
+func refKeyField(resource string) string {
+	switch resource {
+	case refLabel:
+		return "title"
+	case refAgent:
+		return "email"
+	default:
+		return "name"
+	}
+}
+
+// ruleRefKeys collects the natural keys a desired rule may reference: the
+// target account's resources plus those the manifest creates.
+func ruleRefKeys(current, desired *Manifest) map[string]map[string]bool {
+	known := map[string]map[string]bool{refLabel: {}, refTeam: {}, refInbox: {}, refAgent: {}}
+	for _, m := range []*Manifest{current, desired} {
+		for _, l := range m.Labels {
+			known[refLabel][refKey(l.Title)] = true
+		}
+		for _, t := range m.Teams {
+			known[refTeam][refKey(t.Name)] = true
+		}
+		for _, in := range m.Inboxes {
+			known[refInbox][refKey(in.Name)] = true
+		}
+	}
+	for _, a := range current.Agents {
+		known[refAgent][refKey(a.Email)] = true
+	}
+	return known
+}
+
+// targetRefs resolves natural keys to the target account's IDs. Tables are
+// loaded on first use, after earlier changes in the plan have created the
+// teams and inboxes a rule may reference.
+type targetRefs struct {
+	client *api.Client
+	tables map[string]map[string]int
+}
+
+func newTargetRefs(client *api.Client) *targetRefs {
+	return &targetRefs{client: client, tables: map[string]map[string]int{}}
+}
+
+// mapper returns a refMapper that translates names to IDs. Labels are
+// referenced by title in the API too, so they pass through.
+func (r *targetRefs) mapper(ctx context.Context) refMapper {
+	return func(resource string, ref any) (any, error) {
+		if resource == refLabel {
+			return ref, nil
+		}
+		name, ok := ref.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s %v must be referenced by %s", resource, ref, refKeyField(resource))
+		}
+		table, err := r.table(ctx, resource)
+		if err != nil {
+			return nil, err
+		}
+		id, ok := table[refKey(name)]
+		if !ok {
+			return nil, fmt.Errorf("%s %q not found in the target account", resource, name)
+		}
+		return id, nil
+	}
+}
+
+func (r *targetRefs) table(ctx context.Context, resource string) (map[string]int, error) {
+	if table, ok := r.tables[resource]; ok {
+		return table, nil
+	}
+	table := map[string]int{}
+	switch resource {
+	case refTeam:
+		teams, err := r.client.Teams().List(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list teams: %w", err)
+		}
+		for _, t := range teams {
+			table[refKey(t.Name)] = t.ID
+		}
+	case refInbox:
+		inboxes, err := r.client.Inboxes().List(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list inboxes: %w", err)
+		}
+		for _, in := range inboxes {
+			table[refKey(in.Name)] = in.ID
+		}
+	case refAgent:
+		agents, err := r.client.Agents().List(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list agents: %w", err)
+		}
+		for _, a := range agents {
+			table[refKey(a.Email)] = a.ID
+		}
+	default:
+		return nil, fmt.Errorf("unknown reference %q", resource)
+	}
+	r.tables[resource] = table
+	return table, nil
+}
@@ -0,0 +1,238 @@
+// Package mcp implements the tool-serving side of the Model Context Protocol
+// over stdio: newline-delimited JSON-RPC 2.0 messages on stdin and stdout.
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/chatwoot/chatwoot-cli/internal/schema"
+)
+
+// LatestProtocolVersion is the newest protocol revision the server speaks.
+const LatestProtocolVersion = "2025-06-18"
+
+// supportedVersions are the protocol revisions the server accepts from a
+// client's initialize request.
+var supportedVersions = map[string]bool{
+	"2024-11-05":          true,
+	"2025-03-26":          true,
+	LatestProtocolVersion: true,
+}
+
+// JSON-RPC error codes.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+)
+
+// maxMessageSize bounds a single incoming message.
+const maxMessageSize = 10 << 20
+
+// Tool describes a callable tool.
+type Tool struct {
+	Name        string           `json:"name"`
+	Title       string           `json:"title,omitempty"`
+	Description string           `json:"description"`
+	InputSchema *schema.Schema   `json:"inputSchema"`
+	Annotations *ToolAnnotations `json:"annotations,omitempty"`
+}
+
+// ToolAnnotations are hints about a tool's behavior.
+type ToolAnnotations struct {
+	ReadOnlyHint    bool `json:"readOnlyHint"`
+	DestructiveHint bool `json:"destructiveHint"`
+}
+
+// Content is a block of tool output.
+type Content struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// CallResult is the outcome of a tool call. A tool that ran but failed
+// reports IsError rather than a protocol error, so the model can see why.
+type CallResult struct {
+	Content           []Content `json:"content"`
+	StructuredContent any       `json:"structuredContent,omitempty"`
+	IsError           bool      `json:"isError,omitempty"`
+}
+
+// TextResult returns a result holding a single text block.
+func TextResult(text string) *CallResult {
+	return &CallResult{Content: []Content{{Type: "text", Text: text}}}
+}
+
+// ErrorResult returns a failed result holding a single text block.
+func ErrorResult(format string, args ...any) *CallResult {
+	r := TextResult(fmt.Sprintf(format, args...))
+	r.IsError = true
+	return r
+}
+
+// Error is a JSON-RPC error. Handlers return it to fail a request with a
+// specific code; any other error is reported as an internal error.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string { return e.Message }
+
+// ErrUnknownTool is returned by handlers for a tool they don't serve.
+var ErrUnknownTool = errors.New("unknown tool")
+
+// Handler lists and runs the server's tools.
+type Handler interface {
+	ListTools(ctx context.Context) []Tool
+	CallTool(ctx context.Context, name string, arguments json.RawMessage) (*CallResult, error)
+}
+
+// Server answers MCP requests with a Handler.
+type Server struct {
+	Name         string
+	Version      string
+	Instructions string
+	Handler      Handler
+}
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Serve reads requests from r and writes responses to w, one per line,
+// until r is exhausted or ctx is done. Requests are handled one at a time,
+// in order.
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	reader := bufio.NewReaderSize(r, 64*1024)
+	enc := json.NewEncoder(w)
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil
+		}
+		line, err := readLine(reader)
+		if len(bytes.TrimSpace(line)) > 0 {
+			if resp := s.handle(ctx, line); resp != nil {
+				if werr := enc.Encode(resp); werr != nil {
+					return werr
+				}
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func readLine(r *bufio.Reader) ([]byte, error) {
+	var line []byte
+	for {
+		chunk, isPrefix, err := r.ReadLine()
+		line = append(line, chunk...)
+		if len(line) > maxMessageSize {
+			return nil, fmt.Errorf("message exceeds %d bytes", maxMessageSize)
+		}
+		if err != nil || !isPrefix {
+			return line, err
+		}
+	}
+}
+
+func (s *Server) handle(ctx context.Context, line []byte) *response {
+	var req request
+	if err := json.Unmarshal(line, &req); err != nil {
+		return &response{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &Error{Code: CodeParseError, Message: "parse error: " + err.Error()}}
+	}
+	// Notifications (no id) never get a response.
+	if len(req.ID) == 0 {
+		return nil
+	}
+	resp := &response{JSONRPC: "2.0", ID: req.ID}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		resp.Error = &Error{Code: CodeInvalidRequest, Message: "invalid request"}
+		return resp
+	}
+
+	result, err := s.dispatch(ctx, req)
+	if err != nil {
+		var rpcErr *Error
+		if !errors.As(err, &rpcErr) {
+			rpcErr = &Error{Code: CodeInternalError, Message: err.Error()}
+		}
+		resp.Error = rpcErr
+		return resp
+	}
+	resp.Result = result
+	return resp
+}
+
+func (s *Server) dispatch(ctx context.Context, req request) (any, error) {
+	switch req.Method {
+	case "initialize":
+		var params struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		}
+		if len(req.Params) > 0 {
+			if err := json.Unmarshal(req.Params, &params); err != nil {
+				return nil, &Error{Code: CodeInvalidParams, Message: "invalid initialize params: " + err.Error()}
+			}
+		}
+		version := LatestProtocolVersion
+		if supportedVersions[params.ProtocolVersion] {
+			version = params.ProtocolVersion
+		}
+		return map[string]any{
+			"protocolVersion": version,
+			"capabilities":    map[string]any{"tools": map[string]any{"listChanged": false}},
+			"serverInfo":      map[string]any{"name": s.Name, "version": s.Version},
+			"instructions":    s.Instructions,
+		}, nil
+	case "ping":
+		return map[string]any{}, nil
+	case "tools/list":
+		tools := s.Handler.ListTools(ctx)
+		if tools == nil {
+			tools = []Tool{}
+		}
+		return map[string]any{"tools": tools}, nil
+	case "tools/call":
+		var params struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil || params.Name == "" {
+			return nil, &Error{Code: CodeInvalidParams, Message: "tools/call requires a tool name"}
+		}
+		result, err := s.Handler.CallTool(ctx, params.Name, params.Arguments)
+		if errors.Is(err, ErrUnknownTool) {
+			return nil, &Error{Code: CodeInvalidParams, Message: fmt.Sprintf("unknown tool %q", params.Name)}
+		}
+		if err != nil {
+			return nil, err
+		}
+		return result, nil
+	default:
+		return nil, &Error{Code: CodeMethodNotFound, Message: fmt.Sprintf("method %q not found", req.Method)}
+	}
+}
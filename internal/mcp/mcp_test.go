@@ -0,0 +1,108 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/chatwoot/chatwoot-cli/internal/schema"
+)
+
+type echoHandler struct{}
+
+func (echoHandler) ListTools(context.Context) []Tool {
+	return []Tool{{
+		Name:        "echo",
+		Description: "Echo the text back",
+		InputSchema: schema.Object("", map[string]*schema.Schema{"text": schema.String("Text to echo")}, "text"),
+		Annotations: &ToolAnnotations{ReadOnlyHint: true},
+	}}
+}
+
+func (echoHandler) CallTool(_ context.Context, name string, arguments json.RawMessage) (*CallResult, error) {
+	if name != "echo" {
+		return nil, ErrUnknownTool
+	}
+	var args struct {
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(arguments, &args); err != nil || args.Text == "" {
+		return ErrorResult("text is required"), nil
+	}
+	return TextResult(args.Text), nil
+}
+
+func serve(t *testing.T, input string) []map[string]any {
+	t.Helper()
+	s := &Server{Name: "test", Version: "1.0", Handler: echoHandler{}}
+	var out bytes.Buffer
+	if err := s.Serve(context.Background(), strings.NewReader(input), &out); err != nil {
+		t.Fatalf("Serve: %v", err)
+	}
+	var responses []map[string]any
+	dec := json.NewDecoder(&out)
+	for dec.More() {
+		var resp map[string]any
+		if err := dec.Decode(&resp); err != nil {
+			t.Fatalf("invalid response: %v", err)
+		}
+		responses = append(responses, resp)
+	}
+	return responses
+}
+
+func TestServe_Lifecycle(t *testing.T) {
+	responses := serve(t, strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"c","version":"1"}}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":"three","method":"tools/call","params":{"name":"echo","arguments":{"text":"hi"}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"echo","arguments":{}}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"ping"}`,
+	}, "\n"))
+
+	if len(responses) != 5 {
+		t.Fatalf("expected 5 responses (none for the notification), got %d: %v", len(responses), responses)
+	}
+	init := responses[0]["result"].(map[string]any)
+	if init["protocolVersion"] != "2025-03-26" {
+		t.Errorf("protocolVersion = %v, want the client's supported version", init["protocolVersion"])
+	}
+	tools := responses[1]["result"].(map[string]any)["tools"].([]any)
+	tool := tools[0].(map[string]any)
+	if tool["name"] != "echo" || tool["inputSchema"].(map[string]any)["type"] != "object" {
+		t.Errorf("unexpected tool %v", tool)
+	}
+	if responses[2]["id"] != "three" {
+		t.Errorf("id not echoed: %v", responses[2]["id"])
+	}
+	call := responses[2]["result"].(map[string]any)
+	if text := call["content"].([]any)[0].(map[string]any)["text"]; text != "hi" {
+		t.Errorf("text = %v", text)
+	}
+	if failed := responses[3]["result"].(map[string]any); failed["isError"] != true {
+		t.Errorf("expected isError result, got %v", failed)
+	}
+}
+
+func TestServe_Errors(t *testing.T) {
+	responses := serve(t, strings.Join([]string{
+		`not json`,
+		`{"jsonrpc":"2.0","id":1,"method":"resources/list"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"nope"}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"initialize","params":{"protocolVersion":"1999-01-01"}}`,
+	}, "\n"))
+
+	wantCodes := []float64{CodeParseError, CodeMethodNotFound, CodeInvalidParams}
+	for i, code := range wantCodes {
+		rpcErr, ok := responses[i]["error"].(map[string]any)
+		if !ok || rpcErr["code"] != code {
+			t.Errorf("response %d: error = %v, want code %v", i, responses[i]["error"], code)
+		}
+	}
+	if v := responses[3]["result"].(map[string]any)["protocolVersion"]; v != LatestProtocolVersion {
+		t.Errorf("unsupported client version should get %s, got %v", LatestProtocolVersion, v)
+	}
+}
@@ -0,0 +1,485 @@
+// Package metrics polls Chatwoot reports and exposes them as Prometheus
+// metrics.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+)
+
+// csatPageSize is the number of CSAT responses Chatwoot returns per page.
+const csatPageSize = 25
+
+// maxCSATPages bounds CSAT pagination per refresh.
+const maxCSATPages = 40
+
+// requestsPerRefresh is roughly how many API calls one refresh makes. When
+// fewer requests than this remain in the rate limit window, the next refresh
+// waits for the window to reset.
+const requestsPerRefresh = 8
+
+// Source is the subset of the Chatwoot API the exporter reads from.
+// NewAPISource adapts *api.Client.
+type Source interface {
+	ConversationMetrics(ctx context.Context) (*api.ConversationMetrics, error)
+	AgentMetrics(ctx context.Context) ([]api.AgentMetrics, error)
+	SummaryByInbox(ctx context.Context, since, until string) ([]api.SummaryReportEntry, error)
+	SummaryByTeam(ctx context.Context, since, until string) ([]api.SummaryReportEntry, error)
+	Inboxes(ctx context.Context) ([]api.Inbox, error)
+	Teams(ctx context.Context) ([]api.Team, error)
+	CSAT(ctx context.Context, since string, page int) ([]api.CSATResponse, error)
+	// RateLimit returns the rate limit headers of the last response, if any.
+	RateLimit() *api.RateLimitInfo
+}
+
+type apiSource struct {
+	client *api.Client
+}
+
+// NewAPISource wraps an API client for use by the exporter.
+func NewAPISource(client *api.Client) Source {
+	return apiSource{client: client}
+}
+
+func (s apiSource) ConversationMetrics(ctx context.Context) (*api.ConversationMetrics, error) {
+	return s.client.Reports().ConversationMetrics(ctx)
+}
+
+func (s apiSource) AgentMetrics(ctx context.Context) ([]api.AgentMetrics, error) {
+	return s.client.Reports().AgentMetrics(ctx, "")
+}
+
+func (s apiSource) SummaryByInbox(ctx context.Context, since, until string) ([]api.SummaryReportEntry, error) {
+	return s.client.Reports().SummaryByInbox(ctx, since, until, nil)
+}
+
+func (s apiSource) SummaryByTeam(ctx context.Context, since, until string) ([]api.SummaryReportEntry, error) {
+	return s.client.Reports().SummaryByTeam(ctx, since, until, nil)
+}
+
+func (s apiSource) Inboxes(ctx context.Context) ([]api.Inbox, error) {
+	return s.client.Inboxes().List(ctx)
+}
+
+func (s apiSource) Teams(ctx context.Context) ([]api.Team, error) {
+	return s.client.Teams().List(ctx)
+}
+
+func (s apiSource) CSAT(ctx context.Context, since string, page int) ([]api.CSATResponse, error) {
+	return s.client.CSAT().List(ctx, api.CSATListParams{Page: page, Since: since})
+}
+
+func (s apiSource) RateLimit() *api.RateLimitInfo {
+	return s.client.LastRateLimit()
+}
+
+// Sections of a refresh. Each is fetched independently; a failed section
+// keeps serving its last good values.
+const (
+	SectionConversations = "conversations"
+	SectionAgents        = "agents"
+	SectionInboxes       = "inboxes"
+	SectionTeams         = "teams"
+	SectionCSAT          = "csat"
+)
+
+var sections = []string{SectionConversations, SectionAgents, SectionInboxes, SectionTeams, SectionCSAT}
+
+// Exporter polls a Source on a schedule and serves the cached result on
+// every scrape, so scrapes never hit the Chatwoot API directly.
+type Exporter struct {
+	Source Source
+	// Interval between refreshes.
+	Interval time.Duration
+	// Window is the period summary and CSAT metrics cover, ending now.
+	Window time.Duration
+	Now    func() time.Time
+
+	mu          sync.RWMutex
+	cached      map[string][]Family
+	inboxNames  map[int]string
+	teamNames   map[int]string
+	agentNames  map[int]string
+	refreshes   float64
+	errorCounts map[string]float64
+	scrapes     float64
+	lastRefresh time.Time
+	lastOK      bool
+	rateLimit   *api.RateLimitInfo
+}
+
+func (e *Exporter) now() time.Time {
+	if e.Now != nil {
+		return e.Now()
+	}
+	return time.Now()
+}
+
+// Refresh fetches every section and updates the cache. It returns the
+// joined errors of failed sections; sections that succeeded are updated
+// regardless. A rate limit error stops the refresh early.
+func (e *Exporter) Refresh(ctx context.Context) error {
+	now := e.now()
+	since := strconv.FormatInt(now.Add(-e.Window).Unix(), 10)
+	until := strconv.FormatInt(now.Unix(), 10)
+
+	// Name lookups are best effort: on failure the previous names are used.
+	inboxNames, teamNames := e.names()
+	if inboxes, err := e.Source.Inboxes(ctx); err == nil {
+		inboxNames = make(map[int]string, len(inboxes))
+		for _, in := range inboxes {
+			inboxNames[in.ID] = in.Name
+		}
+	}
+	if teams, err := e.Source.Teams(ctx); err == nil {
+		teamNames = make(map[int]string, len(teams))
+		for _, t := range teams {
+			teamNames[t.ID] = t.Name
+		}
+	}
+
+	fresh := map[string][]Family{}
+	var (
+		errs       []error
+		agentNames map[int]string
+	)
+	for _, section := range sections {
+		var (
+			fams []Family
+			err  error
+		)
+		switch section {
+		case SectionConversations:
+			fams, err = e.conversationFamilies(ctx)
+		case SectionAgents:
+			fams, agentNames, err = e.agentFamilies(ctx)
+		case SectionInboxes:
+			fams, err = e.summaryFamilies(ctx, "inbox", inboxNames, e.Source.SummaryByInbox, since, until)
+		case SectionTeams:
+			fams, err = e.summaryFamilies(ctx, "team", teamNames, e.Source.SummaryByTeam, since, until)
+		case SectionCSAT:
+			fams, err = e.csatFamilies(ctx, now, agentNames)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", section, err))
+			e.countError(section)
+			var rl *api.RateLimitError
+			if errors.As(err, &rl) || ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+		fresh[section] = fams
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.cached == nil {
+		e.cached = map[string][]Family{}
+	}
+	for section, fams := range fresh {
+		e.cached[section] = fams
+	}
+	e.inboxNames, e.teamNames = inboxNames, teamNames
+	if agentNames != nil {
+		e.agentNames = agentNames
+	}
+	e.refreshes++
+	e.lastRefresh = now
+	e.lastOK = len(errs) == 0
+	e.rateLimit = e.Source.RateLimit()
+	return errors.Join(errs...)
+}
+
+func (e *Exporter) names() (map[int]string, map[int]string) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.inboxNames, e.teamNames
+}
+
+func (e *Exporter) countError(section string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.errorCounts == nil {
+		e.errorCounts = map[string]float64{}
+	}
+	e.errorCounts[section]++
+}
+
+func (e *Exporter) conversationFamilies(ctx context.Context) ([]Family, error) {
+	m, err := e.Source.ConversationMetrics(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return []Family{
+		gauge("chatwoot_conversations_open", "Open conversations in the account.", value(float64(m.Open))),
+		gauge("chatwoot_conversations_unattended", "Open conversations without a first response.", value(float64(m.Unattended))),
+		gauge("chatwoot_conversations_unassigned", "Open conversations without an assigned agent.", value(float64(m.Unassigned))),
+	}, nil
+}
+
+func (e *Exporter) agentFamilies(ctx context.Context) ([]Family, map[int]string, error) {
+	agents, err := e.Source.AgentMetrics(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	open := gauge("chatwoot_agent_conversations_open", "Open conversations assigned to the agent.")
+	unattended := gauge("chatwoot_agent_conversations_unattended", "Unattended conversations assigned to the agent.")
+	online := gauge("chatwoot_agent_online", "1 if the agent's availability is online.")
+	names := make(map[int]string, len(agents))
+	for _, a := range agents {
+		names[a.ID] = a.Name
+		labels := agentLabels(a.ID, a.Name)
+		open.Samples = append(open.Samples, value(float64(a.Metric.Open), labels...))
+		unattended.Samples = append(unattended.Samples, value(float64(a.Metric.Unattended), labels...))
+		online.Samples = append(online.Samples, value(boolValue(a.Availability == "online"), labels...))
+	}
+	return []Family{open, unattended, online}, names, nil
+}
+
+type summaryFunc func(ctx context.Context, since, until string) ([]api.SummaryReportEntry, error)
+
+func (e *Exporter) summaryFamilies(ctx context.Context, kind string, names map[int]string, fetch summaryFunc, since, until string) ([]Family, error) {
+	entries, err := fetch(ctx, since, until)
+	if err != nil {
+		return nil, err
+	}
+	prefix := "chatwoot_" + kind + "_"
+	window := fmt.Sprintf(" per %s over the report window.", kind)
+	conversations := gauge(prefix+"conversations", "Conversations"+window)
+	resolved := gauge(prefix+"resolved_conversations", "Resolved conversations"+window)
+	firstResponse := gauge(prefix+"avg_first_response_time_seconds", "Average first response time"+window)
+	resolution := gauge(prefix+"avg_resolution_time_seconds", "Average resolution time"+window)
+	reply := gauge(prefix+"avg_reply_time_seconds", "Average reply time"+window)
+	for _, entry := range entries {
+		labels := []Label{{Name: kind + "_id", Value: strconv.Itoa(entry.ID)}, {Name: kind, Value: names[entry.ID]}}
+		conversations.Samples = append(conversations.Samples, value(float64(entry.ConversationsCount), labels...))
+		resolved.Samples = append(resolved.Samples, value(float64(entry.ResolvedConversationsCount), labels...))
+		if entry.AvgFirstResponseTime != nil {
+			firstResponse.Samples = append(firstResponse.Samples, value(float64(*entry.AvgFirstResponseTime), labels...))
+		}
+		if entry.AvgResolutionTime != nil {
+			resolution.Samples = append(resolution.Samples, value(float64(*entry.AvgResolutionTime), labels...))
+		}
+		if entry.AvgReplyTime != nil {
+			reply.Samples = append(reply.Samples, value(float64(*entry.AvgReplyTime), labels...))
+		}
+	}
+	return []Family{conversations, resolved, firstResponse, resolution, reply}, nil
+}
+
+func (e *Exporter) csatFamilies(ctx context.Context, now time.Time, agentNames map[int]string) ([]Family, error) {
+	since := now.Add(-e.Window).UTC().Format("2006-01-02")
+	var responses []api.CSATResponse
+	for page := 1; page <= maxCSATPages; page++ {
+		batch, err := e.Source.CSAT(ctx, since, page)
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, batch...)
+		if len(batch) < csatPageSize {
+			break
+		}
+	}
+	if agentNames == nil {
+		e.mu.RLock()
+		agentNames = e.agentNames
+		e.mu.RUnlock()
+	}
+
+	cutoff := now.Add(-e.Window)
+	byRating := map[int]int{}
+	var total, sum float64
+	type agg struct{ n, sum float64 }
+	byAgent := map[int]*agg{}
+	for _, r := range responses {
+		// The API filters by day; trim to the exact window.
+		if r.CreatedAt > 0 && r.CreatedAtTime().Before(cutoff) {
+			continue
+		}
+		byRating[r.Rating]++
+		total++
+		sum += float64(r.Rating)
+		if r.AssignedAgentID != nil {
+			a := byAgent[*r.AssignedAgentID]
+			if a == nil {
+				a = &agg{}
+				byAgent[*r.AssignedAgentID] = a
+			}
+			a.n++
+			a.sum += float64(r.Rating)
+		}
+	}
+
+	counts := gauge("chatwoot_csat_responses", "CSAT responses by rating over the report window.")
+	for rating := 1; rating <= 5; rating++ {
+		counts.Samples = append(counts.Samples, value(float64(byRating[rating]), Label{Name: "rating", Value: strconv.Itoa(rating)}))
+	}
+	fams := []Family{counts}
+	if total > 0 {
+		fams = append(fams, gauge("chatwoot_csat_average_rating", "Average CSAT rating over the report window.", value(sum/total)))
+	}
+	agentAvg := gauge("chatwoot_agent_csat_average_rating", "Average CSAT rating per assigned agent over the report window.")
+	agentCount := gauge("chatwoot_agent_csat_responses", "CSAT responses per assigned agent over the report window.")
+	for id, a := range byAgent {
+		labels := agentLabels(id, agentNames[id])
+		agentAvg.Samples = append(agentAvg.Samples, value(a.sum/a.n, labels...))
+		agentCount.Samples = append(agentCount.Samples, value(a.n, labels...))
+	}
+	sortSamples(agentAvg.Samples)
+	sortSamples(agentCount.Samples)
+	return append(fams, agentAvg, agentCount), nil
+}
+
+func agentLabels(id int, name string) []Label {
+	return []Label{{Name: "agent_id", Value: strconv.Itoa(id)}, {Name: "agent", Value: name}}
+}
+
+// sortSamples orders samples by their first label, numerically when both
+// values are numbers.
+func sortSamples(samples []Sample) {
+	key := func(s Sample) string {
+		if len(s.Labels) == 0 {
+			return ""
+		}
+		return s.Labels[0].Value
+	}
+	sort.SliceStable(samples, func(i, j int) bool {
+		a, b := key(samples[i]), key(samples[j])
+		ai, aerr := strconv.Atoi(a)
+		bi, berr := strconv.Atoi(b)
+		if aerr == nil && berr == nil {
+			return ai < bi
+		}
+		return a < b
+	})
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// Families returns the cached metrics plus the exporter's own metrics.
+func (e *Exporter) Families() []Family {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	var out []Family
+	for _, section := range sections {
+		out = append(out, e.cached[section]...)
+	}
+
+	out = append(out,
+		gauge("chatwoot_up", "1 if the last refresh of every section succeeded.", value(boolValue(e.lastOK))),
+		counter("chatwoot_exporter_refreshes_total", "Refreshes of the report data.", value(e.refreshes)),
+		counter("chatwoot_exporter_scrapes_total", "Scrapes served from the cache.", value(e.scrapes)),
+		gauge("chatwoot_exporter_window_seconds", "Period covered by summary and CSAT metrics.", value(e.Window.Seconds())),
+	)
+	errs := counter("chatwoot_exporter_refresh_errors_total", "Failed report fetches by section.")
+	for _, section := range sections {
+		errs.Samples = append(errs.Samples, value(e.errorCounts[section], Label{Name: "section", Value: section}))
+	}
+	out = append(out, errs)
+	if !e.lastRefresh.IsZero() {
+		out = append(out, gauge("chatwoot_exporter_last_refresh_timestamp_seconds", "Unix time of the last refresh.", value(float64(e.lastRefresh.Unix()))))
+	}
+	if rl := e.rateLimit; rl != nil {
+		if rl.Remaining != nil {
+			out = append(out, gauge("chatwoot_api_rate_limit_remaining", "Requests left in the API rate limit window.", value(float64(*rl.Remaining))))
+		}
+		if rl.Limit != nil {
+			out = append(out, gauge("chatwoot_api_rate_limit_limit", "Requests allowed per API rate limit window.", value(float64(*rl.Limit))))
+		}
+	}
+	return out
+}
+
+// NextDelay returns how long to wait before the next refresh. It is the
+// interval, extended when the API asked us to back off or the rate limit
+// window has too few requests left for another refresh.
+func (e *Exporter) NextDelay(refreshErr error) time.Duration {
+	delay := e.Interval
+	var rl *api.RateLimitError
+	if errors.As(refreshErr, &rl) && rl.RetryAfter > delay {
+		delay = rl.RetryAfter
+	}
+	e.mu.RLock()
+	info := e.rateLimit
+	e.mu.RUnlock()
+	if info != nil && info.Remaining != nil && *info.Remaining < requestsPerRefresh && info.ResetAt != nil {
+		if wait := info.ResetAt.Sub(e.now()); wait > delay {
+			delay = wait
+		}
+	}
+	return delay
+}
+
+// Run refreshes until ctx is done, calling onRefresh after each attempt.
+func (e *Exporter) Run(ctx context.Context, onRefresh func(error, time.Duration)) {
+	for {
+		err := e.Refresh(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		delay := e.NextDelay(err)
+		if onRefresh != nil {
+			onRefresh(err, delay)
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// ServeHTTP writes the cached metrics.
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	e.mu.Lock()
+	e.scrapes++
+	e.mu.Unlock()
+
+	openMetrics := WantsOpenMetrics(r.Header.Get("Accept"))
+	if openMetrics {
+		w.Header().Set("Content-Type", ContentTypeOpenMetrics)
+	} else {
+		w.Header().Set("Content-Type", ContentTypeText)
+	}
+	if r.Method == http.MethodHead {
+		return
+	}
+	_ = Write(w, e.Families(), openMetrics)
+}
+
+// Handler returns a mux serving the exporter at /metrics.
+func (e *Exporter) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", e)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = fmt.Fprintln(w, "Chatwoot metrics exporter: see /metrics")
+	})
+	return mux
+}
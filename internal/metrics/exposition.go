@@ -0,0 +1,128 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Content types for the two supported exposition formats.
+const (
+	ContentTypeText        = "text/plain; version=0.0.4; charset=utf-8"
+	ContentTypeOpenMetrics = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+)
+
+// Metric types.
+const (
+	typeGauge   = "gauge"
+	typeCounter = "counter"
+)
+
+// Label is a metric label pair.
+type Label struct {
+	Name  string
+	Value string
+}
+
+// Sample is one labelled value of a metric family.
+type Sample struct {
+	Labels []Label
+	Value  float64
+}
+
+// Family is a named metric with its samples. Counter names end in _total.
+type Family struct {
+	Name    string
+	Help    string
+	Type    string
+	Samples []Sample
+}
+
+func gauge(name, help string, samples ...Sample) Family {
+	return Family{Name: name, Help: help, Type: typeGauge, Samples: samples}
+}
+
+func counter(name, help string, samples ...Sample) Family {
+	return Family{Name: name, Help: help, Type: typeCounter, Samples: samples}
+}
+
+func value(v float64, labels ...Label) Sample {
+	return Sample{Labels: labels, Value: v}
+}
+
+// Write renders families in the Prometheus text format, or in OpenMetrics
+// when openMetrics is set. Families are sorted by name so output is stable.
+func Write(w io.Writer, families []Family, openMetrics bool) error {
+	sorted := append([]Family(nil), families...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	var b strings.Builder
+	for _, f := range sorted {
+		if len(f.Samples) == 0 {
+			continue
+		}
+		meta := f.Name
+		if openMetrics && f.Type == typeCounter {
+			meta = strings.TrimSuffix(f.Name, "_total")
+		}
+		fmt.Fprintf(&b, "# HELP %s %s\n", meta, escapeHelp(f.Help))
+		fmt.Fprintf(&b, "# TYPE %s %s\n", meta, f.Type)
+		for _, s := range f.Samples {
+			b.WriteString(f.Name)
+			writeLabels(&b, s.Labels)
+			b.WriteByte(' ')
+			b.WriteString(formatValue(s.Value))
+			b.WriteByte('\n')
+		}
+	}
+	if openMetrics {
+		b.WriteString("# EOF\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeLabels(b *strings.Builder, labels []Label) {
+	if len(labels) == 0 {
+		return
+	}
+	b.WriteByte('{')
+	for i, l := range labels {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(l.Name)
+		b.WriteString(`="`)
+		b.WriteString(escapeLabel(l.Value))
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string  { return helpEscaper.Replace(s) }
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
+
+// WantsOpenMetrics reports whether an Accept header asks for OpenMetrics.
+func WantsOpenMetrics(accept string) bool {
+	return strings.Contains(accept, "application/openmetrics-text")
+}
@@ -0,0 +1,211 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+)
+
+var testNow = time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+type fakeSource struct {
+	convErr   error
+	csatCalls int
+	rateLimit *api.RateLimitInfo
+	open      int
+}
+
+func (f *fakeSource) ConversationMetrics(context.Context) (*api.ConversationMetrics, error) {
+	if f.convErr != nil {
+		return nil, f.convErr
+	}
+	return &api.ConversationMetrics{Open: f.open, Unattended: 2, Unassigned: 1}, nil
+}
+
+func (f *fakeSource) AgentMetrics(context.Context) ([]api.AgentMetrics, error) {
+	a := api.AgentMetrics{ID: 7, Name: `Ann "A"`, Availability: "online"}
+	a.Metric.Open = 4
+	return []api.AgentMetrics{a}, nil
+}
+
+func (f *fakeSource) SummaryByInbox(_ context.Context, since, until string) ([]api.SummaryReportEntry, error) {
+	frt := api.FlexFloat(90)
+	return []api.SummaryReportEntry{{ID: 3, ConversationsCount: 12, ResolvedConversationsCount: 10, AvgFirstResponseTime: &frt}}, nil
+}
+
+func (f *fakeSource) SummaryByTeam(context.Context, string, string) ([]api.SummaryReportEntry, error) {
+	return []api.SummaryReportEntry{{ID: 2, ConversationsCount: 5}}, nil
+}
+
+func (f *fakeSource) Inboxes(context.Context) ([]api.Inbox, error) {
+	return []api.Inbox{{ID: 3, Name: "Support"}}, nil
+}
+
+func (f *fakeSource) Teams(context.Context) ([]api.Team, error) {
+	return []api.Team{{ID: 2, Name: "Billing"}}, nil
+}
+
+func (f *fakeSource) CSAT(_ context.Context, since string, page int) ([]api.CSATResponse, error) {
+	f.csatCalls++
+	if page > 1 {
+		return nil, nil
+	}
+	agent := 7
+	return []api.CSATResponse{
+		{ID: 1, Rating: 5, AssignedAgentID: &agent, CreatedAt: float64(testNow.Add(-time.Hour).Unix())},
+		{ID: 2, Rating: 3, AssignedAgentID: &agent, CreatedAt: float64(testNow.Add(-2 * time.Hour).Unix())},
+		{ID: 3, Rating: 1, CreatedAt: float64(testNow.Add(-48 * time.Hour).Unix())}, // outside window
+	}, nil
+}
+
+func (f *fakeSource) RateLimit() *api.RateLimitInfo { return f.rateLimit }
+
+func newTestExporter(src Source) *Exporter {
+	return &Exporter{Source: src, Interval: time.Minute, Window: 24 * time.Hour, Now: func() time.Time { return testNow }}
+}
+
+func render(t *testing.T, e *Exporter, openMetrics bool) string {
+	t.Helper()
+	var b strings.Builder
+	if err := Write(&b, e.Families(), openMetrics); err != nil {
+		t.Fatal(err)
+	}
+	return b.String()
+}
+
+func TestExporterRefreshAndRender(t *testing.T) {
+	e := newTestExporter(&fakeSource{open: 9})
+	if err := e.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	out := render(t, e, false)
+	for _, want := range []string{
+		"# TYPE chatwoot_conversations_open gauge\nchatwoot_conversations_open 9\n",
+		`chatwoot_agent_conversations_open{agent_id="7",agent="Ann \"A\""} 4`,
+		`chatwoot_agent_online{agent_id="7",agent="Ann \"A\""} 1`,
+		`chatwoot_inbox_conversations{inbox_id="3",inbox="Support"} 12`,
+		`chatwoot_inbox_avg_first_response_time_seconds{inbox_id="3",inbox="Support"} 90`,
+		`chatwoot_team_conversations{team_id="2",team="Billing"} 5`,
+		`chatwoot_csat_responses{rating="5"} 1`,
+		`chatwoot_csat_responses{rating="1"} 0`,
+		"chatwoot_csat_average_rating 4\n",
+		`chatwoot_agent_csat_average_rating{agent_id="7",agent="Ann \"A\""} 4`,
+		"# TYPE chatwoot_exporter_refreshes_total counter\nchatwoot_exporter_refreshes_total 1\n",
+		"chatwoot_up 1\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "avg_resolution_time_seconds{") {
+		t.Errorf("metrics without data should not be emitted:\n%s", out)
+	}
+
+	om := render(t, e, true)
+	if !strings.HasSuffix(om, "# EOF\n") || !strings.Contains(om, "# TYPE chatwoot_exporter_refreshes counter\n") {
+		t.Errorf("unexpected OpenMetrics output:\n%s", om)
+	}
+}
+
+func TestExporterKeepsLastGoodValuesOnError(t *testing.T) {
+	src := &fakeSource{open: 9}
+	e := newTestExporter(src)
+	if err := e.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	src.open = 20
+	src.convErr = errors.New("boom")
+	if err := e.Refresh(context.Background()); err == nil || !strings.Contains(err.Error(), "conversations: boom") {
+		t.Fatalf("expected section error, got %v", err)
+	}
+	out := render(t, e, false)
+	for _, want := range []string{
+		"chatwoot_conversations_open 9\n",
+		"chatwoot_up 0\n",
+		`chatwoot_exporter_refresh_errors_total{section="conversations"} 1`,
+		`chatwoot_exporter_refresh_errors_total{section="csat"} 0`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q\n%s", want, out)
+		}
+	}
+}
+
+func TestExporterRateLimit(t *testing.T) {
+	src := &fakeSource{convErr: &api.RateLimitError{RetryAfter: 5 * time.Minute}}
+	e := newTestExporter(src)
+	err := e.Refresh(context.Background())
+	if err == nil || src.csatCalls != 0 {
+		t.Fatalf("rate limit should stop the refresh, err=%v csatCalls=%d", err, src.csatCalls)
+	}
+	if d := e.NextDelay(err); d != 5*time.Minute {
+		t.Fatalf("NextDelay = %s, want Retry-After", d)
+	}
+
+	remaining, limit := 2, 60
+	reset := testNow.Add(10 * time.Minute)
+	src.convErr = nil
+	src.rateLimit = &api.RateLimitInfo{Remaining: &remaining, Limit: &limit, ResetAt: &reset}
+	err = e.Refresh(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := e.NextDelay(err); d != 10*time.Minute {
+		t.Fatalf("NextDelay = %s, want wait until reset", d)
+	}
+	if out := render(t, e, false); !strings.Contains(out, "chatwoot_api_rate_limit_remaining 2\n") {
+		t.Errorf("missing rate limit gauge:\n%s", out)
+	}
+
+	remaining = 50
+	if d := e.NextDelay(nil); d != time.Minute {
+		t.Fatalf("NextDelay = %s, want interval", d)
+	}
+}
+
+func TestExporterServeHTTP(t *testing.T) {
+	e := newTestExporter(&fakeSource{open: 1})
+	if err := e.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(e.Handler())
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/metrics", nil)
+	req.Header.Set("Accept", "application/openmetrics-text; version=1.0.0")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.Header.Get("Content-Type") != ContentTypeOpenMetrics {
+		t.Fatalf("Content-Type = %q", resp.Header.Get("Content-Type"))
+	}
+
+	resp, err = http.Get(srv.URL + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.Header.Get("Content-Type") != ContentTypeText {
+		t.Fatalf("Content-Type = %q", resp.Header.Get("Content-Type"))
+	}
+	if out := render(t, e, false); !strings.Contains(out, "chatwoot_exporter_scrapes_total 2\n") {
+		t.Errorf("scrapes should be counted:\n%s", out)
+	}
+
+	resp, err = http.Post(srv.URL+"/metrics", "text/plain", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("POST status = %d", resp.StatusCode)
+	}
+}
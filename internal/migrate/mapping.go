@@ -0,0 +1,138 @@
+package migrate
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+// MappingVersion is the on-disk format version of mapping files.
+const MappingVersion = 1
+
+// Endpoint identifies one side of a migration.
+type Endpoint struct {
+	BaseURL   string `json:"base_url"`
+	AccountID int    `json:"account_id"`
+}
+
+// Mapping records source ID -> target ID per resource so an interrupted run
+// can resume without creating duplicates. It is saved after every change.
+type Mapping struct {
+	Version int                       `json:"version"`
+	Source  Endpoint                  `json:"source"`
+	Target  Endpoint                  `json:"target"`
+	IDs     map[string]map[string]int `json:"ids"`
+
+	path    string
+	persist bool
+	mu      sync.Mutex
+}
+
+// LoadMapping opens the mapping file at path, or starts a new one if it does
+// not exist. It fails if the file was written for different accounts.
+func LoadMapping(path string, source, target Endpoint) (*Mapping, error) {
+	m := &Mapping{
+		Version: MappingVersion,
+		Source:  source,
+		Target:  target,
+		IDs:     map[string]map[string]int{},
+		path:    path,
+		persist: path != "",
+	}
+	if path == "" {
+		return m, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return m, nil
+		}
+		return nil, fmt.Errorf("read mapping file: %w", err)
+	}
+	var existing Mapping
+	if err := json.Unmarshal(data, &existing); err != nil {
+		return nil, fmt.Errorf("parse mapping file %s: %w", path, err)
+	}
+	if existing.Version > MappingVersion {
+		return nil, fmt.Errorf("mapping file version %d is newer than supported version %d", existing.Version, MappingVersion)
+	}
+	if existing.Source != source || existing.Target != target {
+		return nil, fmt.Errorf("mapping file %s was written for %s#%d -> %s#%d; use a different --mapping file",
+			path, existing.Source.BaseURL, existing.Source.AccountID, existing.Target.BaseURL, existing.Target.AccountID)
+	}
+	if existing.IDs != nil {
+		m.IDs = existing.IDs
+	}
+	return m, nil
+}
+
+// Path returns the mapping file path ("" for in-memory mappings).
+func (m *Mapping) Path() string {
+	return m.path
+}
+
+// Lookup returns the target ID recorded for a source resource.
+func (m *Mapping) Lookup(resource string, sourceID int) (int, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	id, ok := m.IDs[resource][strconv.Itoa(sourceID)]
+	return id, ok
+}
+
+// Set records a source -> target ID and saves the mapping.
+func (m *Mapping) Set(resource string, sourceID, targetID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.IDs[resource] == nil {
+		m.IDs[resource] = map[string]int{}
+	}
+	m.IDs[resource][strconv.Itoa(sourceID)] = targetID
+	return m.saveLocked()
+}
+
+// Count returns how many IDs are recorded for a resource.
+func (m *Mapping) Count(resource string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.IDs[resource])
+}
+
+// setPersist turns saving on or off (dry runs keep the mapping in memory).
+func (m *Mapping) setPersist(persist bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.persist = persist && m.path != ""
+}
+
+func (m *Mapping) saveLocked() error {
+	if !m.persist {
+		return nil
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(m.path)
+	tmp, err := os.CreateTemp(dir, filepath.Base(m.path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("write mapping file: %w", err)
+	}
+	tmpName := tmp.Name()
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpName)
+		return fmt.Errorf("write mapping file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmpName)
+		return fmt.Errorf("write mapping file: %w", err)
+	}
+	if err := os.Rename(tmpName, m.path); err != nil {
+		_ = os.Remove(tmpName)
+		return fmt.Errorf("write mapping file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,446 @@
+// Package migrate copies account resources (labels, custom attribute
+// definitions, canned responses, portals with categories and articles, and
+// automation rules) from one Chatwoot account to another.
+//
+// Resources that already exist in the target (matched by title, key, short
+// code, slug, or name) are linked rather than duplicated. IDs that point at
+// other resources — teams, inboxes, and agents in automation rules, categories
+// on articles — are remapped via a Mapping that is persisted after every
+// change so interrupted runs can resume.
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+)
+
+// Kind is a resource kind that can be migrated.
+type Kind string
+
+const (
+	KindLabels           Kind = "labels"
+	KindCustomAttributes Kind = "custom_attributes"
+	KindCannedResponses  Kind = "canned_responses"
+	KindPortals          Kind = "portals"
+	KindAutomationRules  Kind = "automation_rules"
+)
+
+// AllKinds lists every kind in migration order. Labels come before automation
+// rules, which reference them by title.
+var AllKinds = []Kind{KindLabels, KindCustomAttributes, KindCannedResponses, KindPortals, KindAutomationRules}
+
+// ParseKinds validates kind names; an empty list selects every kind.
+// The result is always in migration order.
+func ParseKinds(names []string) ([]Kind, error) {
+	if len(names) == 0 {
+		return AllKinds, nil
+	}
+	want := map[Kind]bool{}
+	for _, name := range names {
+		k := Kind(strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), "-", "_"))
+		valid := false
+		for _, known := range AllKinds {
+			if k == known {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return nil, fmt.Errorf("unknown kind %q (valid: %s)", name, joinKinds(AllKinds))
+		}
+		want[k] = true
+	}
+	var out []Kind
+	for _, k := range AllKinds {
+		if want[k] {
+			out = append(out, k)
+		}
+	}
+	return out, nil
+}
+
+func joinKinds(kinds []Kind) string {
+	parts := make([]string, len(kinds))
+	for i, k := range kinds {
+		parts[i] = string(k)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Status is the outcome for one resource.
+type Status string
+
+const (
+	// StatusCreated means the resource was created in the target.
+	StatusCreated Status = "created"
+	// StatusExists means a matching resource already existed and was linked.
+	StatusExists Status = "exists"
+	// StatusMapped means a previous run already migrated the resource.
+	StatusMapped Status = "mapped"
+	// StatusPlanned means the resource would be created (dry run).
+	StatusPlanned Status = "planned"
+	// StatusFailed means the resource could not be migrated.
+	StatusFailed Status = "failed"
+)
+
+// Item reports what happened to one source resource.
+type Item struct {
+	Resource string   `json:"resource"`
+	Key      string   `json:"key"`
+	SourceID int      `json:"source_id"`
+	TargetID int      `json:"target_id,omitempty"`
+	Status   Status   `json:"status"`
+	Warnings []string `json:"warnings,omitempty"`
+	Error    string   `json:"error,omitempty"`
+}
+
+// Options controls a migration run.
+type Options struct {
+	Kinds  []Kind
+	DryRun bool
+	// OnItem, if set, is called as each resource is processed.
+	OnItem func(Item)
+}
+
+// Migrator copies resources from Source to Target.
+type Migrator struct {
+	source  *api.Client
+	target  *api.Client
+	mapping *Mapping
+	opts    Options
+	refs    *references
+	items   []Item
+}
+
+// New returns a Migrator. In dry-run mode nothing is written to the target
+// and the mapping file is left untouched.
+func New(source, target *api.Client, mapping *Mapping, opts Options) *Migrator {
+	if len(opts.Kinds) == 0 {
+		opts.Kinds = AllKinds
+	}
+	mapping.setPersist(!opts.DryRun)
+	return &Migrator{
+		source:  source,
+		target:  target,
+		mapping: mapping,
+		opts:    opts,
+		refs:    newReferences(source, target),
+	}
+}
+
+// Run migrates the selected kinds in order. Per-resource failures are
+// recorded in the returned items; the error is only set when a kind could not
+// be listed at all.
+func (m *Migrator) Run(ctx context.Context) ([]Item, error) {
+	for _, kind := range m.opts.Kinds {
+		if err := ctx.Err(); err != nil {
+			return m.items, err
+		}
+		var err error
+		switch kind {
+		case KindLabels:
+			err = m.migrateLabels(ctx)
+		case KindCustomAttributes:
+			err = m.migrateCustomAttributes(ctx)
+		case KindCannedResponses:
+			err = m.migrateCannedResponses(ctx)
+		case KindPortals:
+			err = m.migratePortals(ctx)
+		case KindAutomationRules:
+			err = m.migrateAutomationRules(ctx)
+		}
+		if err != nil {
+			return m.items, fmt.Errorf("%s: %w", kind, err)
+		}
+	}
+	return m.items, nil
+}
+
+func (m *Migrator) record(item Item) {
+	m.items = append(m.items, item)
+	if m.opts.OnItem != nil {
+		m.opts.OnItem(item)
+	}
+}
+
+// migrateOne handles the shared mapped/exists/create flow for one resource.
+// existingID is the ID of a matching target resource (0 if none).
+func (m *Migrator) migrateOne(resource, key string, sourceID, existingID int, create func() (int, []string, error)) {
+	item := Item{Resource: resource, Key: key, SourceID: sourceID}
+
+	if id, ok := m.mapping.Lookup(resource, sourceID); ok {
+		item.TargetID = id
+		item.Status = StatusMapped
+		m.record(item)
+		return
+	}
+	if existingID != 0 {
+		item.TargetID = existingID
+		item.Status = StatusExists
+		if err := m.mapping.Set(resource, sourceID, existingID); err != nil {
+			item.Status, item.Error = StatusFailed, err.Error()
+		}
+		m.record(item)
+		return
+	}
+	if m.opts.DryRun {
+		item.Status = StatusPlanned
+		// Record a placeholder so dependents (e.g. articles of a planned
+		// category) plan cleanly instead of failing the lookup.
+		_ = m.mapping.Set(resource, sourceID, 0)
+		m.record(item)
+		return
+	}
+
+	id, warnings, err := create()
+	item.Warnings = warnings
+	item.TargetID = id
+	item.Status = StatusCreated
+	if err != nil {
+		item.Status, item.Error = StatusFailed, err.Error()
+	}
+	// Record anything that was created, even if a follow-up step failed, so a
+	// resumed run does not create it twice.
+	if id != 0 {
+		if err := m.mapping.Set(resource, sourceID, id); err != nil {
+			item.Status, item.Error = StatusFailed, fmt.Sprintf("created target %d but failed to save mapping: %v", id, err)
+		}
+	}
+	m.record(item)
+}
+
+func (m *Migrator) migrateLabels(ctx context.Context) error {
+	src, err := m.source.Labels().List(ctx)
+	if err != nil {
+		return err
+	}
+	dst, err := m.target.Labels().List(ctx)
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]int, len(dst))
+	for _, l := range dst {
+		existing[strings.ToLower(l.Title)] = l.ID
+	}
+	for _, l := range src {
+		m.migrateOne("label", l.Title, l.ID, existing[strings.ToLower(l.Title)], func() (int, []string, error) {
+			created, err := m.target.Labels().Create(ctx, l.Title, l.Description, l.Color, l.ShowOnSidebar)
+			if err != nil {
+				return 0, nil, err
+			}
+			return created.ID, nil, nil
+		})
+	}
+	return nil
+}
+
+func (m *Migrator) migrateCustomAttributes(ctx context.Context) error {
+	for _, model := range []string{"conversation", "contact"} {
+		src, err := m.source.CustomAttributes().List(ctx, model)
+		if err != nil {
+			return err
+		}
+		dst, err := m.target.CustomAttributes().List(ctx, model)
+		if err != nil {
+			return err
+		}
+		existing := make(map[string]int, len(dst))
+		for _, a := range dst {
+			existing[a.AttributeKey] = a.ID
+		}
+		for _, a := range src {
+			m.migrateOne("custom_attribute", model+"/"+a.AttributeKey, a.ID, existing[a.AttributeKey], func() (int, []string, error) {
+				created, err := m.target.CustomAttributes().Create(ctx, a.AttributeDisplayName, a.AttributeKey, model, a.AttributeDisplayType)
+				if err != nil {
+					return 0, nil, err
+				}
+				return created.ID, nil, nil
+			})
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) migrateCannedResponses(ctx context.Context) error {
+	src, err := m.source.CannedResponses().List(ctx)
+	if err != nil {
+		return err
+	}
+	dst, err := m.target.CannedResponses().List(ctx)
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]int, len(dst))
+	for _, c := range dst {
+		existing[c.ShortCode] = c.ID
+	}
+	for _, c := range src {
+		m.migrateOne("canned_response", c.ShortCode, c.ID, existing[c.ShortCode], func() (int, []string, error) {
+			created, err := m.target.CannedResponses().Create(ctx, c.ShortCode, c.Content)
+			if err != nil {
+				return 0, nil, err
+			}
+			return created.ID, nil, nil
+		})
+	}
+	return nil
+}
+
+func (m *Migrator) migratePortals(ctx context.Context) error {
+	src, err := m.source.Portals().List(ctx)
+	if err != nil {
+		return err
+	}
+	dst, err := m.target.Portals().List(ctx)
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]int, len(dst))
+	for _, p := range dst {
+		existing[p.Slug] = p.ID
+	}
+	for _, p := range src {
+		m.migrateOne("portal", p.Slug, p.ID, existing[p.Slug], func() (int, []string, error) {
+			created, err := m.target.Portals().Create(ctx, p.Name, p.Slug)
+			if err != nil {
+				return 0, nil, err
+			}
+			return created.ID, nil, nil
+		})
+		if id, ok := m.mapping.Lookup("portal", p.ID); !ok || (id == 0 && !m.opts.DryRun) {
+			continue // portal failed; its children cannot be migrated
+		}
+		// A portal that does not exist yet (dry run) has no target children to list.
+		targetExists := existing[p.Slug] != 0 || !m.opts.DryRun
+		if err := m.migratePortalContent(ctx, p.Slug, targetExists); err != nil {
+			return fmt.Errorf("portal %s: %w", p.Slug, err)
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) migratePortalContent(ctx context.Context, slug string, targetExists bool) error {
+	srcCategories, err := m.source.Portals().Categories(ctx, slug)
+	if err != nil {
+		return err
+	}
+	existingCategories := map[string]int{}
+	existingArticles := map[string]int{}
+	if targetExists {
+		dstCategories, err := m.target.Portals().Categories(ctx, slug)
+		if err != nil {
+			return err
+		}
+		for _, c := range dstCategories {
+			existingCategories[c.Slug] = c.ID
+		}
+		dstArticles, err := m.target.Portals().Articles(ctx, slug)
+		if err != nil {
+			return err
+		}
+		for _, a := range dstArticles {
+			existingArticles[articleKey(a)] = a.ID
+		}
+	}
+
+	for _, c := range srcCategories {
+		m.migrateOne("category", slug+"/"+c.Slug, c.ID, existingCategories[c.Slug], func() (int, []string, error) {
+			params := map[string]any{"name": c.Name, "slug": c.Slug, "position": c.Position}
+			if c.Description != "" {
+				params["description"] = c.Description
+			}
+			created, err := m.target.Portals().CreateCategory(ctx, slug, params)
+			if err != nil {
+				return 0, nil, err
+			}
+			return created.ID, nil, nil
+		})
+	}
+
+	srcArticles, err := m.source.Portals().Articles(ctx, slug)
+	if err != nil {
+		return err
+	}
+	for _, a := range srcArticles {
+		m.migrateOne("article", slug+"/"+articleKey(a), a.ID, existingArticles[articleKey(a)], func() (int, []string, error) {
+			params := map[string]any{"title": a.Title, "content": a.Content}
+			if a.Slug != "" {
+				params["slug"] = a.Slug
+			}
+			if a.Status != "" {
+				params["status"] = a.Status
+			}
+			if a.CategoryID != 0 {
+				categoryID, ok := m.mapping.Lookup("category", a.CategoryID)
+				if !ok || categoryID == 0 {
+					return 0, nil, fmt.Errorf("category %d was not migrated", a.CategoryID)
+				}
+				params["category_id"] = categoryID
+			}
+			created, err := m.target.Portals().CreateArticle(ctx, slug, params)
+			if err != nil {
+				return 0, nil, err
+			}
+			return created.ID, nil, nil
+		})
+	}
+	return nil
+}
+
+// articleKey matches articles by slug, falling back to title.
+func articleKey(a api.Article) string {
+	if a.Slug != "" {
+		return a.Slug
+	}
+	return a.Title
+}
+
+func (m *Migrator) migrateAutomationRules(ctx context.Context) error {
+	src, err := m.source.AutomationRules().List(ctx)
+	if err != nil {
+		return err
+	}
+	dst, err := m.target.AutomationRules().List(ctx)
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]int, len(dst))
+	for _, r := range dst {
+		existing[r.Name] = r.ID
+	}
+	for _, r := range src {
+		rule := r
+		var (
+			conditions, actions []map[string]any
+			warnings            []string
+			remapErr            error
+		)
+		// Remap up front so dry runs surface unresolvable references too.
+		if _, ok := m.mapping.Lookup("automation_rule", rule.ID); !ok && existing[rule.Name] == 0 {
+			conditions, actions, warnings, remapErr = RemapRule(rule, func(resource string, id int) (int, error) {
+				return m.refs.resolve(ctx, resource, id)
+			})
+			if remapErr != nil {
+				m.record(Item{Resource: "automation_rule", Key: rule.Name, SourceID: rule.ID, Status: StatusFailed, Error: remapErr.Error()})
+				continue
+			}
+		}
+		m.migrateOne("automation_rule", rule.Name, rule.ID, existing[rule.Name], func() (int, []string, error) {
+			created, err := m.target.AutomationRules().Create(ctx, rule.Name, rule.EventName, conditions, actions)
+			if err != nil {
+				return 0, warnings, err
+			}
+			if !rule.Active {
+				inactive := false
+				if _, err := m.target.AutomationRules().Update(ctx, created.ID, "", nil, nil, &inactive); err != nil {
+					return created.ID, warnings, fmt.Errorf("created rule %d but failed to deactivate it: %w", created.ID, err)
+				}
+			}
+			return created.ID, warnings, nil
+		})
+	}
+	return nil
+}
@@ -0,0 +1,292 @@
+package migrate_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+	"github.com/chatwoot/chatwoot-cli/internal/migrate"
+)
+
+// fakeAccount serves canned GET responses and records mutating requests.
+type fakeAccount struct {
+	mu        sync.Mutex
+	routes    map[string]string
+	mutations []string
+	bodies    map[string][]map[string]any
+}
+
+func newFakeAccount(t *testing.T, routes map[string]string) (*fakeAccount, *api.Client) {
+	t.Helper()
+	t.Setenv("CHATWOOT_TESTING", "1")
+	f := &fakeAccount{routes: routes, bodies: map[string][]map[string]any{}}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Method + " " + r.URL.Path
+		if r.Method != http.MethodGet {
+			data, _ := io.ReadAll(r.Body)
+			var body map[string]any
+			_ = json.Unmarshal(data, &body)
+			f.mu.Lock()
+			f.mutations = append(f.mutations, key)
+			f.bodies[key] = append(f.bodies[key], body)
+			f.mu.Unlock()
+		}
+		resp, ok := f.routes[key]
+		if !ok {
+			if r.Method == http.MethodGet {
+				resp = "[]"
+			} else {
+				http.Error(w, `{"error":"unexpected"}`, http.StatusNotFound)
+				return
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, resp)
+	}))
+	t.Cleanup(srv.Close)
+	return f, api.New(srv.URL, "token", 1)
+}
+
+func TestParseKinds(t *testing.T) {
+	kinds, err := migrate.ParseKinds(nil)
+	if err != nil || len(kinds) != len(migrate.AllKinds) {
+		t.Fatalf("expected all kinds, got %v, %v", kinds, err)
+	}
+	kinds, err = migrate.ParseKinds([]string{"automation-rules", "Labels"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(kinds) != 2 || kinds[0] != migrate.KindLabels || kinds[1] != migrate.KindAutomationRules {
+		t.Fatalf("expected migration order, got %v", kinds)
+	}
+	if _, err := migrate.ParseKinds([]string{"webhooks"}); err == nil || !strings.Contains(err.Error(), "unknown kind") {
+		t.Fatalf("expected unknown kind error, got %v", err)
+	}
+}
+
+func TestRemapRule(t *testing.T) {
+	rule := api.AutomationRule{
+		Conditions: []map[string]any{
+			{"attribute_key": "team_id", "filter_operator": "equal_to", "values": []any{float64(3)}},
+			{"attribute_key": "content", "filter_operator": "contains", "values": []any{"refund"}},
+		},
+		Actions: []map[string]any{
+			{"action_name": "assign_agent", "action_params": []any{map[string]any{"id": float64(7), "name": "Ann"}}},
+			{"action_name": "send_email_to_team", "action_params": []any{map[string]any{"message": "hi", "team_ids": []any{float64(3)}}}},
+			{"action_name": "send_webhook_event", "action_params": []any{"https://example.com"}},
+		},
+	}
+	ids := map[string]map[int]int{"team": {3: 30}, "agent": {7: 70}}
+	resolve := func(resource string, id int) (int, error) {
+		mapped, ok := ids[resource][id]
+		if !ok {
+			t.Fatalf("unexpected lookup %s %d", resource, id)
+		}
+		return mapped, nil
+	}
+
+	conditions, actions, warnings, err := migrate.RemapRule(rule, resolve)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := conditions[0]["values"].([]any)[0]; got != 30 {
+		t.Fatalf("team condition = %v, want 30", got)
+	}
+	if got := conditions[1]["values"].([]any)[0]; got != "refund" {
+		t.Fatalf("content condition changed: %v", got)
+	}
+	if got := actions[0]["action_params"].([]any)[0].(map[string]any)["id"]; got != 70 {
+		t.Fatalf("agent action = %v, want 70", got)
+	}
+	if got := actions[1]["action_params"].([]any)[0].(map[string]any)["team_ids"].([]any)[0]; got != 30 {
+		t.Fatalf("email team = %v, want 30", got)
+	}
+	if len(warnings) != 1 {
+		t.Fatalf("expected webhook warning, got %v", warnings)
+	}
+	if rule.Conditions[0]["values"].([]any)[0] != float64(3) {
+		t.Fatal("source rule was modified")
+	}
+
+	rule.Actions = []map[string]any{{"action_name": "send_attachment", "action_params": []any{float64(1)}}}
+	if _, _, _, err := migrate.RemapRule(rule, resolve); err == nil {
+		t.Fatal("expected send_attachment to fail")
+	}
+}
+
+func TestLoadMappingRejectsOtherAccounts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "map.json")
+	src := migrate.Endpoint{BaseURL: "https://a.example.com", AccountID: 1}
+	dst := migrate.Endpoint{BaseURL: "https://b.example.com", AccountID: 2}
+
+	m, err := migrate.LoadMapping(path, src, dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Set("label", 5, 50); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := migrate.LoadMapping(path, src, dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id, ok := reloaded.Lookup("label", 5); !ok || id != 50 {
+		t.Fatalf("Lookup = %d, %v; want 50, true", id, ok)
+	}
+
+	other := migrate.Endpoint{BaseURL: "https://b.example.com", AccountID: 3}
+	if _, err := migrate.LoadMapping(path, src, other); err == nil || !strings.Contains(err.Error(), "--mapping") {
+		t.Fatalf("expected endpoint mismatch error, got %v", err)
+	}
+}
+
+func sourceRoutes() map[string]string {
+	return map[string]string{
+		"GET /api/v1/accounts/1/labels":                       `{"payload": [{"id": 1, "title": "vip", "color": "#f00"}, {"id": 2, "title": "billing"}]}`,
+		"GET /api/v1/accounts/1/canned_responses":             `[{"id": 11, "short_code": "hi", "content": "Hello"}]`,
+		"GET /api/v1/accounts/1/portals":                      `{"payload": [{"id": 4, "name": "Help", "slug": "help"}]}`,
+		"GET /api/v1/accounts/1/portals/help/categories":      `[{"id": 41, "name": "Billing", "slug": "billing"}]`,
+		"GET /api/v1/accounts/1/portals/help/articles":        `[{"id": 42, "title": "Refunds", "slug": "refunds", "content": "...", "category_id": 41}]`,
+		"GET /api/v1/accounts/1/teams":                        `[{"id": 3, "name": "Support"}]`,
+		"GET /api/v1/accounts/1/automation_rules":             `{"payload": [{"id": 9, "name": "Route", "event_name": "conversation_created", "active": false, "conditions": [{"attribute_key": "team_id", "filter_operator": "equal_to", "values": [3]}], "actions": [{"action_name": "assign_team", "action_params": [3]}]}]}`,
+		"GET /api/v1/accounts/1/custom_attribute_definitions": `[]`,
+	}
+}
+
+// emptyRoutes returns list responses for an empty account plus extra routes.
+func emptyRoutes(extra map[string]string) map[string]string {
+	routes := map[string]string{
+		"GET /api/v1/accounts/1/labels":           `{"payload": []}`,
+		"GET /api/v1/accounts/1/portals":          `{"payload": []}`,
+		"GET /api/v1/accounts/1/automation_rules": `{"payload": []}`,
+	}
+	for k, v := range extra {
+		routes[k] = v
+	}
+	return routes
+}
+
+func TestRunCreatesRemapsAndResumes(t *testing.T) {
+	_, source := newFakeAccount(t, sourceRoutes())
+	target, targetClient := newFakeAccount(t, emptyRoutes(map[string]string{
+		"GET /api/v1/accounts/1/labels":                   `{"payload": [{"id": 100, "title": "VIP"}]}`,
+		"GET /api/v1/accounts/1/teams":                    `[{"id": 300, "name": "support"}]`,
+		"POST /api/v1/accounts/1/labels":                  `{"id": 101, "title": "billing"}`,
+		"POST /api/v1/accounts/1/canned_responses":        `{"id": 111, "short_code": "hi"}`,
+		"POST /api/v1/accounts/1/portals":                 `{"id": 104, "slug": "help"}`,
+		"POST /api/v1/accounts/1/portals/help/categories": `{"id": 141, "slug": "billing"}`,
+		"POST /api/v1/accounts/1/portals/help/articles":   `{"id": 142, "title": "Refunds"}`,
+		"POST /api/v1/accounts/1/automation_rules":        `{"id": 109, "name": "Route"}`,
+		"PATCH /api/v1/accounts/1/automation_rules/109":   `{"payload": {"id": 109, "name": "Route", "active": false}}`,
+	}))
+
+	path := filepath.Join(t.TempDir(), "map.json")
+	src := migrate.Endpoint{BaseURL: source.BaseURL, AccountID: 1}
+	dst := migrate.Endpoint{BaseURL: targetClient.BaseURL, AccountID: 1}
+	mapping, err := migrate.LoadMapping(path, src, dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	items, err := migrate.New(source, targetClient, mapping, migrate.Options{}).Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	statuses := map[string]migrate.Status{}
+	for _, item := range items {
+		statuses[item.Resource+"/"+item.Key] = item.Status
+		if item.Error != "" {
+			t.Errorf("%s %s failed: %s", item.Resource, item.Key, item.Error)
+		}
+	}
+	if statuses["label/vip"] != migrate.StatusExists || statuses["label/billing"] != migrate.StatusCreated {
+		t.Fatalf("unexpected label statuses: %v", statuses)
+	}
+	if statuses["article/help/refunds"] != migrate.StatusCreated || statuses["automation_rule/Route"] != migrate.StatusCreated {
+		t.Fatalf("unexpected statuses: %v", statuses)
+	}
+
+	article := target.bodies["POST /api/v1/accounts/1/portals/help/articles"][0]
+	if article["category_id"] != float64(141) {
+		t.Fatalf("article category_id = %v, want 141", article["category_id"])
+	}
+	rule := target.bodies["POST /api/v1/accounts/1/automation_rules"][0]
+	if got := rule["actions"].([]any)[0].(map[string]any)["action_params"].([]any)[0]; got != float64(300) {
+		t.Fatalf("rule team = %v, want 300", got)
+	}
+	if target.bodies["PATCH /api/v1/accounts/1/automation_rules/109"][0]["active"] != false {
+		t.Fatal("expected inactive source rule to be deactivated in the target")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"automation_rule"`) {
+		t.Fatalf("mapping file missing rules:\n%s", data)
+	}
+
+	// A second run resumes from the mapping and creates nothing.
+	created := len(target.mutations)
+	mapping, err = migrate.LoadMapping(path, src, dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	items, err = migrate.New(source, targetClient, mapping, migrate.Options{}).Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, item := range items {
+		if item.Status != migrate.StatusMapped {
+			t.Errorf("%s %s: status %s, want mapped", item.Resource, item.Key, item.Status)
+		}
+	}
+	if len(target.mutations) != created {
+		t.Fatalf("resume made new requests: %v", target.mutations[created:])
+	}
+}
+
+func TestRunDryRunWritesNothing(t *testing.T) {
+	_, source := newFakeAccount(t, sourceRoutes())
+	target, targetClient := newFakeAccount(t, emptyRoutes(nil))
+	path := filepath.Join(t.TempDir(), "map.json")
+	mapping, err := migrate.LoadMapping(path,
+		migrate.Endpoint{BaseURL: source.BaseURL, AccountID: 1},
+		migrate.Endpoint{BaseURL: targetClient.BaseURL, AccountID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	items, err := migrate.New(source, targetClient, mapping, migrate.Options{DryRun: true}).Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(target.mutations) != 0 {
+		t.Fatalf("dry run mutated target: %v", target.mutations)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("dry run wrote mapping file: %v", err)
+	}
+
+	var ruleItem migrate.Item
+	for _, item := range items {
+		if item.Resource == "automation_rule" {
+			ruleItem = item
+		} else if item.Status != migrate.StatusPlanned {
+			t.Errorf("%s %s: status %s, want planned", item.Resource, item.Key, item.Status)
+		}
+	}
+	// The target has no "Support" team, so the rule cannot be remapped.
+	if ruleItem.Status != migrate.StatusFailed || !strings.Contains(ruleItem.Error, `"support"`) {
+		t.Fatalf("expected unresolved team failure, got %+v", ruleItem)
+	}
+}
@@ -0,0 +1,256 @@
+package migrate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+)
+
+// Reference resources are not copied; they are matched between accounts by
+// natural key (team and inbox name, agent email).
+const (
+	refTeam  = "team"
+	refInbox = "inbox"
+	refAgent = "agent"
+)
+
+// conditionRefs maps automation condition attribute keys to the resource
+// their values point at.
+var conditionRefs = map[string]string{
+	"inbox_id":    refInbox,
+	"team_id":     refTeam,
+	"assignee_id": refAgent,
+}
+
+// Resolver maps a source reference ID to the target account's ID.
+type Resolver func(resource string, id int) (int, error)
+
+// RemapRule returns copies of the rule's conditions and actions with team,
+// inbox, and agent IDs translated by resolve. Actions that cannot be carried
+// across accounts (uploaded attachments) produce an error.
+func RemapRule(rule api.AutomationRule, resolve Resolver) (conditions, actions []map[string]any, warnings []string, err error) {
+	for _, cond := range rule.Conditions {
+		c := cloneMap(cond)
+		key, _ := c["attribute_key"].(string)
+		if resource, ok := conditionRefs[key]; ok {
+			values, err := remapValues(c["values"], resource, resolve)
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("condition %s: %w", key, err)
+			}
+			c["values"] = values
+		}
+		conditions = append(conditions, c)
+	}
+
+	for _, act := range rule.Actions {
+		a := cloneMap(act)
+		name, _ := a["action_name"].(string)
+		switch name {
+		case "assign_team":
+			params, err := remapValues(a["action_params"], refTeam, resolve)
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("action %s: %w", name, err)
+			}
+			a["action_params"] = params
+		case "assign_agent":
+			params, err := remapValues(a["action_params"], refAgent, resolve)
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("action %s: %w", name, err)
+			}
+			a["action_params"] = params
+		case "send_email_to_team":
+			params, _ := a["action_params"].([]any)
+			out := make([]any, 0, len(params))
+			for _, p := range params {
+				pm, ok := p.(map[string]any)
+				if !ok {
+					out = append(out, p)
+					continue
+				}
+				pm = cloneMap(pm)
+				teamIDs, err := remapValues(pm["team_ids"], refTeam, resolve)
+				if err != nil {
+					return nil, nil, nil, fmt.Errorf("action %s: %w", name, err)
+				}
+				pm["team_ids"] = teamIDs
+				out = append(out, pm)
+			}
+			a["action_params"] = out
+		case "send_attachment":
+			return nil, nil, nil, fmt.Errorf("action %s references uploaded files and cannot be migrated", name)
+		case "send_webhook_event":
+			warnings = append(warnings, "send_webhook_event URL copied as-is")
+		}
+		actions = append(actions, a)
+	}
+	return conditions, actions, warnings, nil
+}
+
+// remapValues translates every numeric ID in a values/params list. Entries
+// may be bare IDs or {"id": ..., "name": ...} objects as stored by the UI.
+// Non-numeric entries (e.g. "nil" for "no team") pass through unchanged.
+func remapValues(v any, resource string, resolve Resolver) ([]any, error) {
+	list, ok := v.([]any)
+	if !ok {
+		if v == nil {
+			return nil, nil
+		}
+		list = []any{v}
+	}
+	out := make([]any, 0, len(list))
+	for _, entry := range list {
+		if m, ok := entry.(map[string]any); ok {
+			id, ok := toID(m["id"])
+			if !ok {
+				out = append(out, entry)
+				continue
+			}
+			mapped, err := resolve(resource, id)
+			if err != nil {
+				return nil, err
+			}
+			m = cloneMap(m)
+			m["id"] = mapped
+			out = append(out, m)
+			continue
+		}
+		id, ok := toID(entry)
+		if !ok {
+			out = append(out, entry)
+			continue
+		}
+		mapped, err := resolve(resource, id)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, mapped)
+	}
+	return out, nil
+}
+
+func toID(v any) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, n > 0
+	case int64:
+		return int(n), n > 0
+	case float64:
+		return int(n), n > 0 && n == float64(int(n))
+	case json.Number:
+		i, err := n.Int64()
+		return int(i), err == nil && i > 0
+	case string:
+		i, err := strconv.Atoi(strings.TrimSpace(n))
+		return i, err == nil && i > 0
+	}
+	return 0, false
+}
+
+func cloneMap(m map[string]any) map[string]any {
+	out := make(map[string]any, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
+// references lazily builds source ID -> target ID tables for teams, inboxes,
+// and agents by matching names (teams, inboxes) or emails (agents).
+type references struct {
+	source, target *api.Client
+
+	mu     sync.Mutex
+	tables map[string]map[int]int
+	names  map[string]map[int]string
+}
+
+func newReferences(source, target *api.Client) *references {
+	return &references{
+		source: source,
+		target: target,
+		tables: map[string]map[int]int{},
+		names:  map[string]map[int]string{},
+	}
+}
+
+func (r *references) resolve(ctx context.Context, resource string, id int) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	table, ok := r.tables[resource]
+	if !ok {
+		var err error
+		table, err = r.load(ctx, resource)
+		if err != nil {
+			return 0, err
+		}
+		r.tables[resource] = table
+	}
+	if mapped, ok := table[id]; ok {
+		return mapped, nil
+	}
+	if name, ok := r.names[resource][id]; ok {
+		return 0, fmt.Errorf("%s %q (id %d) has no match in the target account", resource, name, id)
+	}
+	return 0, fmt.Errorf("%s %d not found in the source account", resource, id)
+}
+
+func (r *references) load(ctx context.Context, resource string) (map[int]int, error) {
+	srcKeys, err := referenceKeys(ctx, r.source, resource)
+	if err != nil {
+		return nil, fmt.Errorf("list source %ss: %w", resource, err)
+	}
+	dstKeys, err := referenceKeys(ctx, r.target, resource)
+	if err != nil {
+		return nil, fmt.Errorf("list target %ss: %w", resource, err)
+	}
+	byKey := make(map[string]int, len(dstKeys))
+	for id, key := range dstKeys {
+		byKey[key] = id
+	}
+	table := make(map[int]int, len(srcKeys))
+	for id, key := range srcKeys {
+		if dst, ok := byKey[key]; ok {
+			table[id] = dst
+		}
+	}
+	r.names[resource] = srcKeys
+	return table, nil
+}
+
+func referenceKeys(ctx context.Context, client *api.Client, resource string) (map[int]string, error) {
+	out := map[int]string{}
+	switch resource {
+	case refTeam:
+		teams, err := client.Teams().List(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range teams {
+			out[t.ID] = strings.ToLower(strings.TrimSpace(t.Name))
+		}
+	case refInbox:
+		inboxes, err := client.Inboxes().List(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, in := range inboxes {
+			out[in.ID] = strings.ToLower(strings.TrimSpace(in.Name))
+		}
+	case refAgent:
+		agents, err := client.Agents().List(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range agents {
+			out[a.ID] = strings.ToLower(strings.TrimSpace(a.Email))
+		}
+	default:
+		return nil, fmt.Errorf("unknown reference %q", resource)
+	}
+	return out, nil
+}
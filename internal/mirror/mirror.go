@@ -0,0 +1,437 @@
+// Package mirror keeps a durable local copy of conversations, messages,
+// contacts, and labels so read commands can answer without the API.
+//
+// Unlike internal/cache, mirrored data never expires. It is refreshed
+// incrementally by `cw sync` and read by commands run with --offline.
+// Each server/account pair gets its own directory under the cache dir.
+package mirror
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+)
+
+// StateVersion is the on-disk format version written to state.json.
+const StateVersion = 1
+
+// ErrNotFound is returned when a resource is not present in the mirror.
+var ErrNotFound = errors.New("not found in offline mirror")
+
+// ErrNotSynced is returned by reads when the mirror has never been synced.
+var ErrNotSynced = errors.New("offline mirror is empty; run 'cw sync' first")
+
+// State records sync progress and high-water marks.
+type State struct {
+	Version   int    `json:"version"`
+	BaseURL   string `json:"base_url"`
+	AccountID int    `json:"account_id"`
+	// LastSyncAt is when the last successful sync finished.
+	LastSyncAt time.Time `json:"last_sync_at,omitempty"`
+	// LastActivityAt is the highest conversation last_activity_at seen.
+	LastActivityAt int64 `json:"last_activity_at,omitempty"`
+	// ContactsActivityAt is the highest contact last_activity_at seen.
+	ContactsActivityAt int64 `json:"contacts_activity_at,omitempty"`
+}
+
+// MessageGap is a range of message IDs, After < ID < Before, that a sync
+// stopped short of mirroring. The next sync fills it before fetching newer
+// messages.
+type MessageGap struct {
+	After  int `json:"after"`
+	Before int `json:"before"`
+}
+
+// Stats summarizes mirror contents.
+type Stats struct {
+	Conversations int `json:"conversations"`
+	Messages      int `json:"messages"`
+	Contacts      int `json:"contacts"`
+	Labels        int `json:"labels"`
+}
+
+// Mirror reads and writes the mirror for one server/account.
+// It is safe for concurrent use; message files are per conversation.
+type Mirror struct {
+	dir       string
+	baseURL   string
+	accountID int
+
+	mu sync.Mutex
+}
+
+// Open returns the mirror rooted under cacheDir for baseURL and accountID.
+// Nothing is created on disk until the first write.
+func Open(cacheDir, baseURL string, accountID int) *Mirror {
+	hash := sha1.Sum([]byte(baseURL))
+	suffix := hex.EncodeToString(hash[:6]) // matches cache key scheme
+	return &Mirror{
+		dir:       filepath.Join(cacheDir, "mirror", fmt.Sprintf("%s_%d", suffix, accountID)),
+		baseURL:   baseURL,
+		accountID: accountID,
+	}
+}
+
+// Dir returns the mirror directory.
+func (m *Mirror) Dir() string {
+	return m.dir
+}
+
+// Synced reports whether a sync has completed at least once.
+func (m *Mirror) Synced() bool {
+	st, err := m.State()
+	return err == nil && !st.LastSyncAt.IsZero()
+}
+
+// State loads sync state. A missing state file returns a zero State.
+func (m *Mirror) State() (State, error) {
+	var st State
+	ok, err := m.readJSON("state.json", &st)
+	if err != nil {
+		return State{}, err
+	}
+	if !ok {
+		return State{Version: StateVersion, BaseURL: m.baseURL, AccountID: m.accountID}, nil
+	}
+	if st.Version > StateVersion {
+		return State{}, fmt.Errorf("offline mirror format v%d is newer than supported v%d", st.Version, StateVersion)
+	}
+	return st, nil
+}
+
+// SaveState writes sync state.
+func (m *Mirror) SaveState(st State) error {
+	st.Version = StateVersion
+	st.BaseURL = m.baseURL
+	st.AccountID = m.accountID
+	return m.writeJSON("state.json", st)
+}
+
+// Conversations returns mirrored conversations, most recent activity first.
+func (m *Mirror) Conversations() ([]api.Conversation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.loadConversations()
+}
+
+// Conversation returns one mirrored conversation by ID (display ID).
+func (m *Mirror) Conversation(id int) (*api.Conversation, error) {
+	convs, err := m.Conversations()
+	if err != nil {
+		return nil, err
+	}
+	for i := range convs {
+		if convs[i].ID == id {
+			return &convs[i], nil
+		}
+	}
+	return nil, fmt.Errorf("conversation %d: %w", id, ErrNotFound)
+}
+
+// PutConversations upserts conversations by ID.
+func (m *Mirror) PutConversations(convs []api.Conversation) error {
+	if len(convs) == 0 {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	existing, err := m.loadConversations()
+	if err != nil {
+		return err
+	}
+	byID := make(map[int]int, len(existing))
+	for i, c := range existing {
+		byID[c.ID] = i
+	}
+	for _, c := range convs {
+		if i, ok := byID[c.ID]; ok {
+			existing[i] = c
+			continue
+		}
+		byID[c.ID] = len(existing)
+		existing = append(existing, c)
+	}
+	sortConversations(existing)
+	return m.writeJSON("conversations.json", existing)
+}
+
+func (m *Mirror) loadConversations() ([]api.Conversation, error) {
+	var convs []api.Conversation
+	if _, err := m.readJSON("conversations.json", &convs); err != nil {
+		return nil, err
+	}
+	sortConversations(convs)
+	return convs, nil
+}
+
+func sortConversations(convs []api.Conversation) {
+	sort.SliceStable(convs, func(i, j int) bool {
+		if convs[i].LastActivityAt != convs[j].LastActivityAt {
+			return convs[i].LastActivityAt > convs[j].LastActivityAt
+		}
+		return convs[i].ID > convs[j].ID
+	})
+}
+
+// Messages returns mirrored messages for a conversation, oldest first.
+func (m *Mirror) Messages(conversationID int) ([]api.Message, error) {
+	var msgs []api.Message
+	if _, err := m.readJSON(messagesFile(conversationID), &msgs); err != nil {
+		return nil, err
+	}
+	return msgs, nil
+}
+
+// LastMessageID returns the highest mirrored message ID for a conversation.
+func (m *Mirror) LastMessageID(conversationID int) (int, error) {
+	msgs, err := m.Messages(conversationID)
+	if err != nil {
+		return 0, err
+	}
+	if len(msgs) == 0 {
+		return 0, nil
+	}
+	return msgs[len(msgs)-1].ID, nil
+}
+
+// PutMessages upserts messages for a conversation and returns how many were new.
+func (m *Mirror) PutMessages(conversationID int, msgs []api.Message) (int, error) {
+	if len(msgs) == 0 {
+		return 0, nil
+	}
+	existing, err := m.Messages(conversationID)
+	if err != nil {
+		return 0, err
+	}
+	byID := make(map[int]int, len(existing))
+	for i, msg := range existing {
+		byID[msg.ID] = i
+	}
+	added := 0
+	for _, msg := range msgs {
+		if i, ok := byID[msg.ID]; ok {
+			existing[i] = msg
+			continue
+		}
+		byID[msg.ID] = len(existing)
+		existing = append(existing, msg)
+		added++
+	}
+	sort.SliceStable(existing, func(i, j int) bool { return existing[i].ID < existing[j].ID })
+	return added, m.writeJSON(messagesFile(conversationID), existing)
+}
+
+// MessageGap returns the unmirrored message range of a conversation, if any.
+func (m *Mirror) MessageGap(conversationID int) (MessageGap, bool, error) {
+	var gap MessageGap
+	ok, err := m.readJSON(messageGapFile(conversationID), &gap)
+	return gap, ok, err
+}
+
+// SetMessageGap records the unmirrored message range of a conversation. A
+// zero gap removes it.
+func (m *Mirror) SetMessageGap(conversationID int, gap MessageGap) error {
+	if gap != (MessageGap{}) {
+		return m.writeJSON(messageGapFile(conversationID), gap)
+	}
+	err := os.Remove(filepath.Join(m.dir, messageGapFile(conversationID)))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("write offline mirror: %w", err)
+	}
+	return nil
+}
+
+// MessageGaps returns the IDs of conversations with a message gap.
+func (m *Mirror) MessageGaps() ([]int, error) {
+	entries, err := os.ReadDir(filepath.Join(m.dir, "message_gaps"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("read offline mirror: %w", err)
+	}
+	var ids []int
+	for _, e := range entries {
+		id, err := strconv.Atoi(strings.TrimSuffix(e.Name(), ".json"))
+		if err != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids, nil
+}
+
+// Contacts returns mirrored contacts ordered by ID.
+func (m *Mirror) Contacts() ([]api.Contact, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var contacts []api.Contact
+	if _, err := m.readJSON("contacts.json", &contacts); err != nil {
+		return nil, err
+	}
+	return contacts, nil
+}
+
+// Contact returns one mirrored contact by ID.
+func (m *Mirror) Contact(id int) (*api.Contact, error) {
+	contacts, err := m.Contacts()
+	if err != nil {
+		return nil, err
+	}
+	for i := range contacts {
+		if contacts[i].ID == id {
+			return &contacts[i], nil
+		}
+	}
+	return nil, fmt.Errorf("contact %d: %w", id, ErrNotFound)
+}
+
+// PutContacts upserts contacts by ID.
+func (m *Mirror) PutContacts(contacts []api.Contact) error {
+	if len(contacts) == 0 {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var existing []api.Contact
+	if _, err := m.readJSON("contacts.json", &existing); err != nil {
+		return err
+	}
+	byID := make(map[int]int, len(existing))
+	for i, c := range existing {
+		byID[c.ID] = i
+	}
+	for _, c := range contacts {
+		if i, ok := byID[c.ID]; ok {
+			existing[i] = c
+			continue
+		}
+		byID[c.ID] = len(existing)
+		existing = append(existing, c)
+	}
+	sort.SliceStable(existing, func(i, j int) bool { return existing[i].ID < existing[j].ID })
+	return m.writeJSON("contacts.json", existing)
+}
+
+// Labels returns the mirrored account labels.
+func (m *Mirror) Labels() ([]api.Label, error) {
+	var labels []api.Label
+	if _, err := m.readJSON("labels.json", &labels); err != nil {
+		return nil, err
+	}
+	return labels, nil
+}
+
+// SetLabels replaces the mirrored account labels.
+func (m *Mirror) SetLabels(labels []api.Label) error {
+	if labels == nil {
+		labels = []api.Label{}
+	}
+	return m.writeJSON("labels.json", labels)
+}
+
+// Stats counts mirrored resources.
+func (m *Mirror) Stats() (Stats, error) {
+	var s Stats
+	convs, err := m.Conversations()
+	if err != nil {
+		return s, err
+	}
+	s.Conversations = len(convs)
+	contacts, err := m.Contacts()
+	if err != nil {
+		return s, err
+	}
+	s.Contacts = len(contacts)
+	labels, err := m.Labels()
+	if err != nil {
+		return s, err
+	}
+	s.Labels = len(labels)
+
+	entries, err := os.ReadDir(filepath.Join(m.dir, "messages"))
+	if err != nil && !os.IsNotExist(err) {
+		return s, err
+	}
+	for _, e := range entries {
+		id, err := strconv.Atoi(strings.TrimSuffix(e.Name(), ".json"))
+		if err != nil {
+			continue
+		}
+		msgs, err := m.Messages(id)
+		if err != nil {
+			return s, err
+		}
+		s.Messages += len(msgs)
+	}
+	return s, nil
+}
+
+// Clear removes the mirror directory.
+func (m *Mirror) Clear() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return os.RemoveAll(m.dir)
+}
+
+func messagesFile(conversationID int) string {
+	return filepath.Join("messages", strconv.Itoa(conversationID)+".json")
+}
+
+func messageGapFile(conversationID int) string {
+	return filepath.Join("message_gaps", strconv.Itoa(conversationID)+".json")
+}
+
+func (m *Mirror) readJSON(name string, dst any) (bool, error) {
+	data, err := os.ReadFile(filepath.Join(m.dir, name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("read offline mirror: %w", err)
+	}
+	if err := json.Unmarshal(data, dst); err != nil {
+		return false, fmt.Errorf("parse offline mirror %s: %w", name, err)
+	}
+	return true, nil
+}
+
+// writeJSON writes atomically (temp file + rename) so readers never see partial files.
+func (m *Mirror) writeJSON(name string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(m.dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("create offline mirror dir: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("write offline mirror: %w", err)
+	}
+	tmpName := tmp.Name()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpName)
+		return fmt.Errorf("write offline mirror: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmpName)
+		return fmt.Errorf("write offline mirror: %w", err)
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		_ = os.Remove(tmpName)
+		return fmt.Errorf("write offline mirror: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package mirror_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+	"github.com/chatwoot/chatwoot-cli/internal/mirror"
+)
+
+func TestMirror_ConversationsUpsertAndOrder(t *testing.T) {
+	m := mirror.Open(t.TempDir(), "https://example.com", 1)
+
+	if err := m.PutConversations([]api.Conversation{
+		{ID: 1, Status: "open", LastActivityAt: 100},
+		{ID: 2, Status: "open", LastActivityAt: 300},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.PutConversations([]api.Conversation{
+		{ID: 1, Status: "resolved", LastActivityAt: 500},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	convs, err := m.Conversations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(convs) != 2 {
+		t.Fatalf("expected 2 conversations, got %d", len(convs))
+	}
+	if convs[0].ID != 1 || convs[0].Status != "resolved" {
+		t.Fatalf("expected updated conversation 1 first, got %+v", convs[0])
+	}
+
+	if _, err := m.Conversation(99); !errors.Is(err, mirror.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestMirror_MessagesDedupeAndLastID(t *testing.T) {
+	m := mirror.Open(t.TempDir(), "https://example.com", 1)
+
+	added, err := m.PutMessages(7, []api.Message{{ID: 12}, {ID: 10}})
+	if err != nil || added != 2 {
+		t.Fatalf("added=%d err=%v", added, err)
+	}
+	added, err = m.PutMessages(7, []api.Message{{ID: 12, Content: "edited"}, {ID: 15}})
+	if err != nil || added != 1 {
+		t.Fatalf("added=%d err=%v", added, err)
+	}
+
+	msgs, err := m.Messages(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != 3 || msgs[0].ID != 10 || msgs[1].Content != "edited" || msgs[2].ID != 15 {
+		t.Fatalf("unexpected messages: %+v", msgs)
+	}
+	last, err := m.LastMessageID(7)
+	if err != nil || last != 15 {
+		t.Fatalf("last=%d err=%v", last, err)
+	}
+	if last, _ := m.LastMessageID(8); last != 0 {
+		t.Fatalf("expected 0 for unknown conversation, got %d", last)
+	}
+}
+
+func TestMirror_MessageGaps(t *testing.T) {
+	m := mirror.Open(t.TempDir(), "https://example.com", 1)
+
+	if _, ok, err := m.MessageGap(7); ok || err != nil {
+		t.Fatalf("ok=%v err=%v", ok, err)
+	}
+	if err := m.SetMessageGap(7, mirror.MessageGap{After: 10, Before: 40}); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.SetMessageGap(9, mirror.MessageGap{Before: 5}); err != nil {
+		t.Fatal(err)
+	}
+	gap, ok, err := m.MessageGap(7)
+	if err != nil || !ok || gap != (mirror.MessageGap{After: 10, Before: 40}) {
+		t.Fatalf("gap=%+v ok=%v err=%v", gap, ok, err)
+	}
+	if ids, err := m.MessageGaps(); err != nil || len(ids) != 2 || ids[0] != 7 || ids[1] != 9 {
+		t.Fatalf("ids=%v err=%v", ids, err)
+	}
+
+	if err := m.SetMessageGap(7, mirror.MessageGap{}); err != nil {
+		t.Fatal(err)
+	}
+	if ids, err := m.MessageGaps(); err != nil || len(ids) != 1 || ids[0] != 9 {
+		t.Fatalf("ids=%v err=%v", ids, err)
+	}
+}
+
+func TestMirror_StateAndStats(t *testing.T) {
+	dir := t.TempDir()
+	m := mirror.Open(dir, "https://example.com", 1)
+	if m.Synced() {
+		t.Fatal("new mirror should not be synced")
+	}
+
+	st, err := m.State()
+	if err != nil {
+		t.Fatal(err)
+	}
+	st.LastSyncAt = time.Now()
+	st.LastActivityAt = 42
+	if err := m.SaveState(st); err != nil {
+		t.Fatal(err)
+	}
+	_ = m.PutConversations([]api.Conversation{{ID: 1}})
+	_, _ = m.PutMessages(1, []api.Message{{ID: 1}, {ID: 2}})
+	_ = m.PutContacts([]api.Contact{{ID: 5, Name: "Jane"}})
+	_ = m.SetLabels([]api.Label{{ID: 1, Title: "vip"}})
+
+	reopened := mirror.Open(dir, "https://example.com", 1)
+	if !reopened.Synced() {
+		t.Fatal("expected mirror to be synced")
+	}
+	got, _ := reopened.State()
+	if got.LastActivityAt != 42 || got.AccountID != 1 {
+		t.Fatalf("unexpected state: %+v", got)
+	}
+	stats, err := reopened.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats != (mirror.Stats{Conversations: 1, Messages: 2, Contacts: 1, Labels: 1}) {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+
+	// Different accounts are isolated.
+	other := mirror.Open(dir, "https://example.com", 2)
+	if other.Synced() {
+		t.Fatal("expected other account mirror to be empty")
+	}
+
+	if err := reopened.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(reopened.Dir()); !os.IsNotExist(err) {
+		t.Fatalf("expected mirror dir removed, got %v", err)
+	}
+}
@@ -13,6 +13,7 @@ type Formatter struct {
 	out       io.Writer
 	errOut    io.Writer
 	tabWriter *tabwriter.Writer
+	table     *Table
 }
 
 // NewFormatter creates a new Formatter
@@ -25,8 +26,11 @@ func NewFormatter(ctx context.Context, out, errOut io.Writer) *Formatter {
 	}
 }
 
-// Output writes data as JSON or text based on context format.
+// Output writes data as JSON, CSV or XLSX based on context format.
 func (f *Formatter) Output(data any) error {
+	if IsTabular(f.ctx) {
+		return WriteTabular(f.ctx, f.out, data)
+	}
 	if IsJSON(f.ctx) {
 		query := GetQuery(f.ctx)
 		light := IsLight(f.ctx)
@@ -51,11 +55,16 @@ func (f *Formatter) Output(data any) error {
 	return nil
 }
 
-// StartTable writes table headers. Returns true if in text mode.
+// StartTable writes table headers. Returns true if in text, CSV or XLSX
+// mode. CSV and XLSX rows are buffered until EndTable.
 func (f *Formatter) StartTable(headers []string) bool {
 	if IsJSON(f.ctx) {
 		return false
 	}
+	if IsTabular(f.ctx) {
+		f.table = &Table{Headers: headers}
+		return true
+	}
 
 	for i, h := range headers {
 		if i > 0 {
@@ -69,6 +78,14 @@ func (f *Formatter) StartTable(headers []string) bool {
 
 // Row writes a single row to the table.
 func (f *Formatter) Row(columns ...string) {
+	if f.table != nil {
+		row := make([]any, len(columns))
+		for i, col := range columns {
+			row[i] = ansiPattern.ReplaceAllString(col, "")
+		}
+		f.table.Rows = append(f.table.Rows, row)
+		return
+	}
 	for i, col := range columns {
 		if i > 0 {
 			_, _ = fmt.Fprint(f.tabWriter, "\t")
@@ -80,6 +97,11 @@ func (f *Formatter) Row(columns ...string) {
 
 // EndTable flushes the table output.
 func (f *Formatter) EndTable() error {
+	if f.table != nil {
+		t := f.table
+		f.table = nil
+		return WriteTable(f.ctx, f.out, t)
+	}
 	return f.tabWriter.Flush()
 }
 
@@ -153,3 +153,37 @@ func TestFormatter_Row_MultipleColumns(t *testing.T) {
 		t.Errorf("output should contain header 'ID', got: %s", output)
 	}
 }
+
+func TestFormatter_Table_CSV(t *testing.T) {
+	var buf bytes.Buffer
+	ctx := WithMode(context.Background(), CSV)
+	f := NewFormatter(ctx, &buf, &buf)
+
+	if !f.StartTable([]string{"ID", "NAME"}) {
+		t.Fatal("StartTable should accept rows in CSV mode")
+	}
+	f.Row("1", "\x1b[32mtest\x1b[0m")
+	f.Row("2", "a,b")
+	if err := f.EndTable(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := buf.String(), "ID,NAME\n1,test\n2,\"a,b\"\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestFormatter_Output_CSV(t *testing.T) {
+	var buf bytes.Buffer
+	ctx := WithMode(context.Background(), CSV)
+	ctx = WithFields(ctx, []string{"name"})
+	f := NewFormatter(ctx, &buf, &buf)
+
+	data := map[string]any{"items": []map[string]string{{"name": "a", "other": "x"}, {"name": "b"}}}
+	if err := f.Output(data); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := buf.String(), "name\na\nb\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -19,6 +19,10 @@ const (
 	JSONL
 	// Agent outputs agent-friendly structured JSON
 	Agent
+	// CSV outputs comma-separated rows with a header row
+	CSV
+	// XLSX outputs a single-sheet Excel workbook
+	XLSX
 )
 
 type (
@@ -38,8 +42,12 @@ func Parse(s string) (Mode, error) {
 		return JSONL, nil
 	case "agent":
 		return Agent, nil
+	case "csv":
+		return CSV, nil
+	case "xlsx":
+		return XLSX, nil
 	default:
-		return Text, fmt.Errorf("invalid output format: %q (use 'text', 'json', 'jsonl', 'ndjson', or 'agent')", s)
+		return Text, fmt.Errorf("invalid output format: %q (use 'text', 'json', 'jsonl', 'ndjson', 'agent', 'csv', or 'xlsx')", s)
 	}
 }
 
@@ -72,6 +80,12 @@ func IsAgent(ctx context.Context) bool {
 	return ModeFromContext(ctx) == Agent
 }
 
+// IsTabular returns true if the context is set to CSV or XLSX output
+func IsTabular(ctx context.Context) bool {
+	mode := ModeFromContext(ctx)
+	return mode == CSV || mode == XLSX
+}
+
 // WithCompact adds the compact flag to the context
 func WithCompact(ctx context.Context, compact bool) context.Context {
 	return context.WithValue(ctx, compactKey{}, compact)
@@ -124,6 +138,10 @@ func (m Mode) String() string {
 		return "jsonl"
 	case Agent:
 		return "agent"
+	case CSV:
+		return "csv"
+	case XLSX:
+		return "xlsx"
 	default:
 		return "text"
 	}
@@ -0,0 +1,407 @@
+package outfmt
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type (
+	fieldsKey     struct{}
+	outputFileKey struct{}
+)
+
+// WithFields sets the columns selected for CSV and XLSX output.
+func WithFields(ctx context.Context, fields []string) context.Context {
+	return context.WithValue(ctx, fieldsKey{}, fields)
+}
+
+// GetFields retrieves the columns selected for CSV and XLSX output.
+func GetFields(ctx context.Context) []string {
+	if fields, ok := ctx.Value(fieldsKey{}).([]string); ok {
+		return fields
+	}
+	return nil
+}
+
+// tableOutput tracks where a command's CSV or XLSX table goes and whether it
+// has been written: the output holds one table, so a second write is an error
+// rather than a silently replaced file or a corrupt workbook.
+type tableOutput struct {
+	path    string
+	written bool
+}
+
+// WithOutputFile directs CSV and XLSX output to a file instead of stdout (an
+// empty path keeps stdout). It starts a new command's output, which may hold
+// a single table.
+func WithOutputFile(ctx context.Context, path string) context.Context {
+	return context.WithValue(ctx, outputFileKey{}, &tableOutput{path: path})
+}
+
+// OutputFile returns the file CSV and XLSX output is written to, if any.
+func OutputFile(ctx context.Context) string {
+	if out, ok := ctx.Value(outputFileKey{}).(*tableOutput); ok {
+		return out.path
+	}
+	return ""
+}
+
+// Table is command output laid out as rows and columns for CSV and XLSX.
+// Cells hold strings, json.Number, bool, or nil for an empty cell.
+type Table struct {
+	Headers []string
+	Rows    [][]any
+}
+
+var ansiPattern = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// TableFromData lays out JSON-shaped data as a table. Lists (including
+// {"items": [...]} envelopes) become one row per item; any other object
+// becomes a single row. Without fields, nested objects are flattened into
+// dotted columns in the order their keys first appear. With fields, exactly
+// those (dotted) paths become the columns.
+func TableFromData(v any, fields []string) (*Table, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	root, err := decodeOrdered(dec)
+	if err != nil {
+		return nil, err
+	}
+	records := tableRecords(root)
+
+	if len(fields) > 0 {
+		t := &Table{Headers: fields}
+		for _, rec := range records {
+			row := make([]any, len(fields))
+			for i, field := range fields {
+				row[i] = cellValue(lookupPath(rec, field))
+			}
+			t.Rows = append(t.Rows, row)
+		}
+		return t, nil
+	}
+
+	var headers []string
+	seen := map[string]bool{}
+	flat := make([]map[string]any, 0, len(records))
+	for _, rec := range records {
+		cells := map[string]any{}
+		flattenRecord("", rec, cells, func(col string) {
+			if !seen[col] {
+				seen[col] = true
+				headers = append(headers, col)
+			}
+		})
+		flat = append(flat, cells)
+	}
+	headers = dropNullParents(headers, flat)
+	t := &Table{Headers: headers}
+	for _, cells := range flat {
+		row := make([]any, len(headers))
+		for i, col := range headers {
+			row[i] = cells[col]
+		}
+		t.Rows = append(t.Rows, row)
+	}
+	return t, nil
+}
+
+// WriteTabular writes data as CSV or XLSX, depending on the context mode,
+// using the fields selected in the context as columns.
+func WriteTabular(ctx context.Context, w io.Writer, v any) error {
+	t, err := TableFromData(v, GetFields(ctx))
+	if err != nil {
+		return err
+	}
+	return WriteTable(ctx, w, t)
+}
+
+// WriteTable writes a table as CSV or XLSX, depending on the context mode.
+// When an output file is set in the context, the table is written there
+// instead of w. Only one table may be written per WithOutputFile context.
+func WriteTable(ctx context.Context, w io.Writer, t *Table) error {
+	mode := ModeFromContext(ctx)
+	out, _ := ctx.Value(outputFileKey{}).(*tableOutput)
+	if out != nil {
+		if out.written {
+			return fmt.Errorf("--output %s holds a single table, but this command writes more than one; use --output json", mode)
+		}
+		out.written = true
+	}
+	if out == nil || out.path == "" {
+		return writeTable(w, mode, t)
+	}
+	path := out.path
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	if err := writeTable(f, mode, t); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func writeTable(w io.Writer, mode Mode, t *Table) error {
+	if mode == XLSX {
+		return WriteXLSX(w, t)
+	}
+	return WriteCSV(w, t)
+}
+
+// WriteCSV writes a table as RFC 4180 CSV with a header row.
+func WriteCSV(w io.Writer, t *Table) error {
+	cw := csv.NewWriter(w)
+	record := make([]string, 0, len(t.Headers))
+	for _, h := range t.Headers {
+		record = append(record, escapeFormula(h))
+	}
+	if err := cw.Write(record); err != nil {
+		return err
+	}
+	for _, row := range t.Rows {
+		record = record[:0]
+		for _, cell := range row {
+			s := formatCell(cell)
+			if _, ok := cell.(string); ok {
+				s = escapeFormula(s)
+			}
+			record = append(record, s)
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// phoneLike matches phone numbers such as "+1 (555) 123-4567", which start
+// with a formula character but cannot call functions.
+var phoneLike = regexp.MustCompile(`^\+?[0-9 ()-]+$`)
+
+// escapeFormula prefixes text that a spreadsheet would evaluate as a formula
+// with a single quote, so values like "=HYPERLINK(...)" from customer input
+// open as plain text. Numbers ("-5") and phone numbers ("+15551234567") are
+// left as they are.
+func escapeFormula(s string) string {
+	if s == "" || !strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return s
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil || phoneLike.MatchString(s) {
+		return s
+	}
+	return "'" + s
+}
+
+func formatCell(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case json.Number:
+		return val.String()
+	case bool:
+		return strconv.FormatBool(val)
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+// orderedObject is a decoded JSON object that remembers its key order, so
+// columns follow the order of the underlying struct fields.
+type orderedObject struct {
+	keys   []string
+	values map[string]any
+}
+
+func (o *orderedObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(o.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func decodeOrdered(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		return tok, nil
+	}
+	switch delim {
+	case '{':
+		obj := &orderedObject{values: map[string]any{}}
+		for dec.More() {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			key, _ := keyTok.(string)
+			value, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			if _, dup := obj.values[key]; !dup {
+				obj.keys = append(obj.keys, key)
+			}
+			obj.values[key] = value
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return obj, nil
+	case '[':
+		arr := []any{}
+		for dec.More() {
+			value, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, value)
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return arr, nil
+	default:
+		return nil, fmt.Errorf("unexpected JSON delimiter %q", delim)
+	}
+}
+
+func tableRecords(root any) []any {
+	switch v := root.(type) {
+	case []any:
+		return v
+	case *orderedObject:
+		for _, key := range []string{"items", "results"} {
+			if items, ok := v.values[key].([]any); ok {
+				return items
+			}
+		}
+		return []any{v}
+	case nil:
+		return nil
+	default:
+		return []any{v}
+	}
+}
+
+func flattenRecord(prefix string, v any, cells map[string]any, addColumn func(string)) {
+	obj, ok := v.(*orderedObject)
+	if !ok {
+		col := strings.TrimSuffix(prefix, ".")
+		if col == "" {
+			col = "value"
+		}
+		addColumn(col)
+		cells[col] = cellValue(v)
+		return
+	}
+	for _, key := range obj.keys {
+		flattenRecord(prefix+key+".", obj.values[key], cells, addColumn)
+	}
+}
+
+// dropNullParents removes a column like "sender" that only ever held null
+// when other rows flattened the same key into "sender.name" and friends.
+func dropNullParents(headers []string, rows []map[string]any) []string {
+	out := headers[:0:0]
+	for _, h := range headers {
+		if !hasChildColumn(headers, h) || !allNull(rows, h) {
+			out = append(out, h)
+		}
+	}
+	return out
+}
+
+func hasChildColumn(headers []string, parent string) bool {
+	for _, h := range headers {
+		if strings.HasPrefix(h, parent+".") {
+			return true
+		}
+	}
+	return false
+}
+
+func allNull(rows []map[string]any, col string) bool {
+	for _, row := range rows {
+		if row[col] != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func lookupPath(v any, path string) any {
+	for _, seg := range strings.Split(path, ".") {
+		if seg == "" {
+			continue
+		}
+		obj, ok := v.(*orderedObject)
+		if !ok {
+			return nil
+		}
+		v = obj.values[seg]
+	}
+	return v
+}
+
+// cellValue converts a decoded JSON value into a table cell. Lists of
+// scalars are joined with ", "; nested objects and lists of objects are kept
+// as compact JSON.
+func cellValue(v any) any {
+	switch val := v.(type) {
+	case nil, json.Number, bool:
+		return val
+	case string:
+		return ansiPattern.ReplaceAllString(val, "")
+	case []any:
+		parts := make([]string, 0, len(val))
+		for _, item := range val {
+			switch item.(type) {
+			case *orderedObject, []any:
+				data, _ := json.Marshal(val)
+				return string(data)
+			}
+			parts = append(parts, formatCell(item))
+		}
+		return strings.Join(parts, ", ")
+	default:
+		data, _ := json.Marshal(val)
+		return string(data)
+	}
+}
@@ -0,0 +1,170 @@
+package outfmt
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type tabularSender struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+type tabularItem struct {
+	ID     int            `json:"id"`
+	Status string         `json:"status"`
+	Labels []string       `json:"labels"`
+	Sender *tabularSender `json:"sender"`
+	Closed bool           `json:"closed"`
+}
+
+func TestTableFromData_FlattensInFieldOrder(t *testing.T) {
+	data := map[string]any{
+		"items": []tabularItem{
+			{ID: 1, Status: "open", Labels: []string{"vip", "billing"}, Sender: &tabularSender{Name: "Ann", Email: "ann@example.com"}},
+			{ID: 2, Status: "resolved", Closed: true},
+		},
+		"has_more": false,
+	}
+	table, err := TableFromData(data, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantHeaders := []string{"id", "status", "labels", "sender.name", "sender.email", "closed"}
+	if !reflect.DeepEqual(table.Headers, wantHeaders) {
+		t.Fatalf("headers = %v, want %v", table.Headers, wantHeaders)
+	}
+	wantRows := [][]any{
+		{json.Number("1"), "open", "vip, billing", "Ann", "ann@example.com", false},
+		{json.Number("2"), "resolved", nil, nil, nil, true},
+	}
+	if !reflect.DeepEqual(table.Rows, wantRows) {
+		t.Fatalf("rows = %#v, want %#v", table.Rows, wantRows)
+	}
+}
+
+func TestTableFromData_Fields(t *testing.T) {
+	data := []tabularItem{{ID: 7, Status: "open", Sender: &tabularSender{Name: "Bo"}}}
+	table, err := TableFromData(data, []string{"sender.name", "id", "missing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(table.Headers, []string{"sender.name", "id", "missing"}) {
+		t.Fatalf("headers = %v", table.Headers)
+	}
+	if !reflect.DeepEqual(table.Rows, [][]any{{"Bo", json.Number("7"), nil}}) {
+		t.Fatalf("rows = %#v", table.Rows)
+	}
+}
+
+func TestTableFromData_SingleObject(t *testing.T) {
+	table, err := TableFromData(map[string]any{"conversations_count": 12, "breakdown": []map[string]int{{"a": 1}}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(table.Rows) != 1 {
+		t.Fatalf("expected a single row, got %d", len(table.Rows))
+	}
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, table); err != nil {
+		t.Fatal(err)
+	}
+	want := "breakdown,conversations_count\n\"[{\"\"a\"\":1}]\",12\n"
+	if buf.String() != want {
+		t.Fatalf("csv = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestWriteCSV_EscapesFormulas(t *testing.T) {
+	table := &Table{
+		Headers: []string{"=name", "note"},
+		Rows: [][]any{
+			{"=HYPERLINK(\"http://evil\")", "+1"},
+			{"-2", "@SUM(A1)"},
+			{"\tcmd", "\rcmd"},
+			{json.Number("-5"), "a=b"},
+			{"+15551234567", "+1 (555) 123-4567"},
+			{"-12.5", "+3"},
+			{"-2+cmd|' /C calc'!A0", "+ 1"},
+		},
+	}
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, table); err != nil {
+		t.Fatal(err)
+	}
+	want := "'=name,note\n" +
+		"\"'=HYPERLINK(\"\"http://evil\"\")\",+1\n" +
+		"-2,'@SUM(A1)\n" +
+		"'\tcmd,\"'\rcmd\"\n" +
+		"-5,a=b\n" +
+		"+15551234567,+1 (555) 123-4567\n" +
+		"-12.5,+3\n" +
+		"'-2+cmd|' /C calc'!A0,+ 1\n"
+	if buf.String() != want {
+		t.Fatalf("csv = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestWriteTabular_CSVToFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.csv")
+	ctx := WithMode(context.Background(), CSV)
+	ctx = WithOutputFile(ctx, path)
+	ctx = WithFields(ctx, []string{"id", "status"})
+
+	var stdout bytes.Buffer
+	if err := WriteTabular(ctx, &stdout, []tabularItem{{ID: 1, Status: "open, pending"}}); err != nil {
+		t.Fatal(err)
+	}
+	if stdout.Len() != 0 {
+		t.Fatalf("expected nothing on stdout, got %q", stdout.String())
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), "id,status\n1,\"open, pending\"\n"; got != want {
+		t.Fatalf("file = %q, want %q", got, want)
+	}
+}
+
+func TestWriteTabular_RejectsSecondTable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.csv")
+	ctx := WithMode(context.Background(), CSV)
+	ctx = WithOutputFile(ctx, path)
+
+	if err := WriteTabular(ctx, io.Discard, []tabularItem{{ID: 1, Status: "open"}}); err != nil {
+		t.Fatal(err)
+	}
+	err := WriteTabular(ctx, io.Discard, []tabularItem{{ID: 2, Status: "resolved"}})
+	if err == nil || !strings.Contains(err.Error(), "single table") {
+		t.Fatalf("expected single-table error, got %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "1,open") || strings.Contains(string(data), "resolved") {
+		t.Fatalf("file should keep the first table: %q", data)
+	}
+}
+
+func TestParse_TabularModes(t *testing.T) {
+	for input, want := range map[string]Mode{"csv": CSV, "xlsx": XLSX} {
+		mode, err := Parse(input)
+		if err != nil || mode != want || mode.String() != input {
+			t.Errorf("Parse(%q) = %v, %v", input, mode, err)
+		}
+		ctx := WithMode(context.Background(), mode)
+		if !IsTabular(ctx) || IsJSON(ctx) {
+			t.Errorf("%s: IsTabular=%v IsJSON=%v", input, IsTabular(ctx), IsJSON(ctx))
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package outfmt
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`
+
+	xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`
+
+	xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets></workbook>`
+
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`
+)
+
+// xlsxEpoch is the fixed modification time stamped on every part, so the
+// same table always produces the same bytes.
+var xlsxEpoch = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// WriteXLSX writes a table as a single-sheet XLSX workbook. The header row
+// is frozen; strings are stored inline and JSON numbers as numeric cells.
+func WriteXLSX(w io.Writer, t *Table) error {
+	zw := zip.NewWriter(w)
+	parts := []struct {
+		name string
+		data []byte
+	}{
+		{"[Content_Types].xml", []byte(xlsxContentTypes)},
+		{"_rels/.rels", []byte(xlsxRootRels)},
+		{"xl/workbook.xml", []byte(xlsxWorkbook)},
+		{"xl/_rels/workbook.xml.rels", []byte(xlsxWorkbookRels)},
+		{"xl/worksheets/sheet1.xml", xlsxSheet(t)},
+	}
+	for _, part := range parts {
+		fw, err := zw.CreateHeader(&zip.FileHeader{Name: part.name, Method: zip.Deflate, Modified: xlsxEpoch})
+		if err != nil {
+			return err
+		}
+		if _, err := fw.Write(part.data); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func xlsxSheet(t *Table) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	buf.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	buf.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+	buf.WriteString(`<sheetData>`)
+
+	header := make([]any, len(t.Headers))
+	for i, h := range t.Headers {
+		header[i] = h
+	}
+	writeXLSXRow(&buf, 1, header)
+	for i, row := range t.Rows {
+		writeXLSXRow(&buf, i+2, row)
+	}
+
+	buf.WriteString(`</sheetData></worksheet>`)
+	return buf.Bytes()
+}
+
+func writeXLSXRow(buf *bytes.Buffer, rowNum int, cells []any) {
+	fmt.Fprintf(buf, `<row r="%d">`, rowNum)
+	for col, cell := range cells {
+		ref := xlsxColumn(col) + strconv.Itoa(rowNum)
+		switch v := cell.(type) {
+		case nil:
+			continue
+		case json.Number:
+			fmt.Fprintf(buf, `<c r="%s"><v>%s</v></c>`, ref, v.String())
+		case bool:
+			b := 0
+			if v {
+				b = 1
+			}
+			fmt.Fprintf(buf, `<c r="%s" t="b"><v>%d</v></c>`, ref, b)
+		default:
+			// Inline strings are never evaluated, so text that looks like a
+			// formula needs no escaping here.
+			fmt.Fprintf(buf, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">`, ref)
+			_ = xml.EscapeText(buf, []byte(formatCell(v)))
+			buf.WriteString(`</t></is></c>`)
+		}
+	}
+	buf.WriteString(`</row>`)
+}
+
+// xlsxColumn converts a zero-based column index to its spreadsheet letters
+// (0 → A, 25 → Z, 26 → AA).
+func xlsxColumn(i int) string {
+	var name []byte
+	for i >= 0 {
+		name = append([]byte{byte('A' + i%26)}, name...)
+		i = i/26 - 1
+	}
+	return string(name)
+}
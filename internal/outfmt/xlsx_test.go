@@ -0,0 +1,105 @@
+package outfmt
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+)
+
+func readZipEntry(t *testing.T, data []byte, name string) string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("invalid zip: %v", err)
+	}
+	for _, f := range zr.File {
+		if f.Name != name {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = rc.Close() }()
+		body, err := io.ReadAll(rc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(body)
+	}
+	t.Fatalf("zip missing %s", name)
+	return ""
+}
+
+func TestWriteXLSX(t *testing.T) {
+	table := &Table{
+		Headers: []string{"id", "name", "vip"},
+		Rows: [][]any{
+			{json.Number("1"), "Ann & <Bo>", true},
+			{json.Number("2.5"), nil, false},
+		},
+	}
+	var buf bytes.Buffer
+	if err := WriteXLSX(&buf, table); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, part := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels"} {
+		readZipEntry(t, buf.Bytes(), part)
+	}
+	sheet := readZipEntry(t, buf.Bytes(), "xl/worksheets/sheet1.xml")
+	for _, want := range []string{
+		`<c r="A1" t="inlineStr"><is><t xml:space="preserve">id</t></is></c>`,
+		`<c r="A2"><v>1</v></c>`,
+		`<t xml:space="preserve">Ann &amp; &lt;Bo&gt;</t>`,
+		`<c r="C2" t="b"><v>1</v></c>`,
+		`<row r="3"><c r="A3"><v>2.5</v></c><c r="C3" t="b"><v>0</v></c></row>`,
+		`state="frozen"`,
+	} {
+		if !strings.Contains(sheet, want) {
+			t.Errorf("sheet missing %q:\n%s", want, sheet)
+		}
+	}
+
+	var again bytes.Buffer
+	if err := WriteXLSX(&again, table); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), again.Bytes()) {
+		t.Error("XLSX output should be deterministic")
+	}
+}
+
+func TestWriteXLSX_FormulasStayText(t *testing.T) {
+	table := &Table{
+		Headers: []string{"note"},
+		Rows:    [][]any{{"=HYPERLINK(\"http://evil\")"}, {json.Number("-5")}},
+	}
+	var buf bytes.Buffer
+	if err := WriteXLSX(&buf, table); err != nil {
+		t.Fatal(err)
+	}
+	sheet := readZipEntry(t, buf.Bytes(), "xl/worksheets/sheet1.xml")
+	if strings.Contains(sheet, "<f>") {
+		t.Fatalf("sheet should not contain formulas:\n%s", sheet)
+	}
+	for _, want := range []string{
+		`<c r="A2" t="inlineStr"><is><t xml:space="preserve">=HYPERLINK(&#34;http://evil&#34;)</t></is></c>`,
+		`<c r="A3"><v>-5</v></c>`,
+	} {
+		if !strings.Contains(sheet, want) {
+			t.Errorf("sheet missing %q:\n%s", want, sheet)
+		}
+	}
+}
+
+func TestXLSXColumn(t *testing.T) {
+	for i, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"} {
+		if got := xlsxColumn(i); got != want {
+			t.Errorf("xlsxColumn(%d) = %q, want %q", i, got, want)
+		}
+	}
+}
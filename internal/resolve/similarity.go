@@ -0,0 +1,101 @@
+package resolve
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// foldAccents maps common accented Latin letters to their ASCII base.
+var foldAccents = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ä", "a", "ã", "a", "å", "a", "ā", "a",
+	"ç", "c", "č", "c", "ć", "c",
+	"é", "e", "è", "e", "ê", "e", "ë", "e", "ē", "e", "ě", "e",
+	"í", "i", "ì", "i", "î", "i", "ï", "i",
+	"ñ", "n", "ń", "n", "ň", "n",
+	"ó", "o", "ò", "o", "ô", "o", "ö", "o", "õ", "o", "ø", "o",
+	"ú", "u", "ù", "u", "û", "u", "ü", "u", "ů", "u",
+	"ý", "y", "ÿ", "y",
+	"š", "s", "ś", "s", "ß", "ss",
+	"ž", "z", "ź", "z", "ż", "z",
+	"ł", "l", "ř", "r", "đ", "d",
+)
+
+// NormalizeName lowercases a person or company name, folds accents and
+// punctuation, and returns its words.
+func NormalizeName(name string) []string {
+	name = foldAccents.Replace(strings.ToLower(name))
+	return strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// NameSimilarity scores how alike two names are, from 0 (unrelated) to 1
+// (same words, ignoring case, accents, punctuation and word order).
+//
+// The score is the Jaro-Winkler similarity of the normalized names, taken in
+// both their original and sorted word order so "Doe, Jane" matches
+// "Jane Doe".
+func NameSimilarity(a, b string) float64 {
+	wa, wb := NormalizeName(a), NormalizeName(b)
+	if len(wa) == 0 || len(wb) == 0 {
+		return 0
+	}
+	best := jaroWinkler(strings.Join(wa, " "), strings.Join(wb, " "))
+	sort.Strings(wa)
+	sort.Strings(wb)
+	return max(best, jaroWinkler(strings.Join(wa, " "), strings.Join(wb, " ")))
+}
+
+func jaroWinkler(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	if string(ra) == string(rb) {
+		return 1
+	}
+	if len(ra) == 0 || len(rb) == 0 {
+		return 0
+	}
+
+	window := max(len(ra), len(rb))/2 - 1
+	window = max(window, 0)
+	matchedA := make([]bool, len(ra))
+	matchedB := make([]bool, len(rb))
+	matches := 0
+	for i := range ra {
+		lo, hi := max(0, i-window), min(len(rb), i+window+1)
+		for j := lo; j < hi; j++ {
+			if !matchedB[j] && ra[i] == rb[j] {
+				matchedA[i], matchedB[j] = true, true
+				matches++
+				break
+			}
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	transpositions := 0
+	j := 0
+	for i := range ra {
+		if !matchedA[i] {
+			continue
+		}
+		for !matchedB[j] {
+			j++
+		}
+		if ra[i] != rb[j] {
+			transpositions++
+		}
+		j++
+	}
+
+	m := float64(matches)
+	jaro := (m/float64(len(ra)) + m/float64(len(rb)) + (m-float64(transpositions)/2)/m) / 3
+
+	prefix := 0
+	for prefix < min(4, len(ra), len(rb)) && ra[prefix] == rb[prefix] {
+		prefix++
+	}
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
@@ -0,0 +1,39 @@
+package resolve_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/chatwoot/chatwoot-cli/internal/resolve"
+)
+
+func TestNormalizeName(t *testing.T) {
+	got := resolve.NormalizeName("  José-María O'Brien ")
+	want := []string{"jose", "maria", "o", "brien"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("NormalizeName = %q, want %q", got, want)
+	}
+}
+
+func TestNameSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		min, max float64
+	}{
+		{"Jane Doe", "jane doe", 1, 1},
+		{"Doe, Jane", "Jane Doe", 1, 1},
+		{"Renée Müller", "Renee Muller", 1, 1},
+		{"Jonathan Smith", "Jonathon Smith", 0.9, 0.99},
+		{"Jane Doe", "John Smith", 0, 0.7},
+		{"", "Jane", 0, 0},
+	}
+	for _, tt := range tests {
+		got := resolve.NameSimilarity(tt.a, tt.b)
+		if got < tt.min || got > tt.max {
+			t.Errorf("NameSimilarity(%q, %q) = %.3f, want [%.2f, %.2f]", tt.a, tt.b, got, tt.min, tt.max)
+		}
+		if rev := resolve.NameSimilarity(tt.b, tt.a); rev != got {
+			t.Errorf("NameSimilarity is not symmetric for %q/%q: %.3f vs %.3f", tt.a, tt.b, got, rev)
+		}
+	}
+}
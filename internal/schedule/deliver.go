@@ -0,0 +1,242 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+)
+
+// MaxAttempts is how many times a message is tried before it is marked
+// failed.
+const MaxAttempts = 5
+
+// ClaimTimeout is how long an item may stay "sending" before another worker
+// treats the attempt as interrupted and retries it.
+const ClaimTimeout = 5 * time.Minute
+
+// Sender is the API surface the worker needs.
+type Sender interface {
+	Conversation(ctx context.Context, id int) (*api.Conversation, error)
+	// RecentMessages returns the latest messages in a conversation.
+	RecentMessages(ctx context.Context, conversationID int) ([]api.Message, error)
+	// Send creates the message using item.IdempotencyKey.
+	Send(ctx context.Context, item Item) (*api.Message, error)
+}
+
+type apiSender struct {
+	client *api.Client
+}
+
+// NewAPISender wraps an API client for use by the worker.
+func NewAPISender(client *api.Client) Sender {
+	return apiSender{client: client}
+}
+
+func (s apiSender) Conversation(ctx context.Context, id int) (*api.Conversation, error) {
+	return s.client.Conversations().Get(ctx, id)
+}
+
+func (s apiSender) RecentMessages(ctx context.Context, conversationID int) ([]api.Message, error) {
+	return s.client.Messages().List(ctx, conversationID)
+}
+
+// Send sets the item's idempotency key for this one request. The worker
+// sends one message at a time, so restoring the previous key is safe.
+func (s apiSender) Send(ctx context.Context, item Item) (*api.Message, error) {
+	previous := s.client.IdempotencyKey
+	s.client.IdempotencyKey = item.IdempotencyKey
+	defer func() { s.client.IdempotencyKey = previous }()
+	return s.client.Messages().Create(ctx, item.ConversationID, item.Content, item.Private, "outgoing")
+}
+
+// Account identifies the Chatwoot account a worker delivers for. Items
+// scheduled under other profiles are left alone.
+type Account struct {
+	BaseURL   string
+	AccountID int
+}
+
+func (a Account) owns(item Item) bool {
+	return item.BaseURL == a.BaseURL && item.AccountID == a.AccountID
+}
+
+// Result is the outcome of one delivery attempt.
+type Result struct {
+	Item
+	// Retry is set when a failed attempt will be tried again on a later run.
+	Retry bool `json:"retry,omitempty"`
+}
+
+// Worker delivers due messages from a store.
+type Worker struct {
+	Store   *Store
+	Sender  Sender
+	Account Account
+	Now     func() time.Time
+}
+
+// RunOnce delivers every message that is due, one at a time. Each item is
+// claimed in the queue file before it is sent and its outcome recorded
+// right after, so an interrupted run loses at most one attempt; the next run
+// checks the conversation before retrying such an item.
+func (w *Worker) RunOnce(ctx context.Context) ([]Result, error) {
+	var results []Result
+	tried := map[string]bool{}
+	for ctx.Err() == nil {
+		item, ok, err := w.claim(tried)
+		if err != nil || !ok {
+			return results, err
+		}
+		tried[item.ID] = true
+		res := w.deliver(ctx, item)
+		if err := w.record(res.Item); err != nil {
+			return results, err
+		}
+		results = append(results, res)
+	}
+	return results, ctx.Err()
+}
+
+func (w *Worker) now() time.Time {
+	if w.Now != nil {
+		return w.Now()
+	}
+	return time.Now()
+}
+
+// claim marks the earliest due item as sending and returns a copy.
+func (w *Worker) claim(skip map[string]bool) (Item, bool, error) {
+	var (
+		claimed Item
+		found   bool
+	)
+	now := w.now()
+	err := w.Store.Update(func(q *Queue) error {
+		var next *Item
+		for i := range q.Items {
+			it := &q.Items[i]
+			if skip[it.ID] || !w.Account.owns(*it) || it.SendAt.After(now) {
+				continue
+			}
+			due := it.Status == StatusPending ||
+				(it.Status == StatusSending && now.Sub(it.LastAttemptAt) > ClaimTimeout)
+			if due && (next == nil || it.SendAt.Before(next.SendAt)) {
+				next = it
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		next.Status = StatusSending
+		next.Attempts++
+		next.LastAttemptAt = now
+		claimed, found = *next, true
+		return nil
+	})
+	return claimed, found, err
+}
+
+// record writes the outcome of an attempt back to the queue.
+func (w *Worker) record(item Item) error {
+	return w.Store.Update(func(q *Queue) error {
+		stored, err := q.Find(item.ID)
+		if err != nil {
+			return nil // removed while sending
+		}
+		if stored.Status == StatusCanceled && item.Status != StatusSent {
+			return nil
+		}
+		*stored = item
+		return nil
+	})
+}
+
+func (w *Worker) deliver(ctx context.Context, item Item) Result {
+	done := func(status Status, reason string) Result {
+		item.Status, item.Error, item.DoneAt = status, reason, w.now()
+		return Result{Item: item}
+	}
+
+	if item.Attempts > 1 {
+		// An earlier attempt may have reached Chatwoot before failing or
+		// being interrupted; look for it instead of sending twice.
+		if msg, err := w.findSent(ctx, item); err == nil && msg != nil {
+			item.MessageID = msg.ID
+			return done(StatusSent, "")
+		}
+	}
+
+	if item.SkipIfResolved {
+		conv, err := w.Sender.Conversation(ctx, item.ConversationID)
+		if err != nil {
+			return w.failed(item, fmt.Errorf("get conversation: %w", err))
+		}
+		if conv.Status == "resolved" {
+			return done(StatusSkipped, "conversation was resolved")
+		}
+	}
+	if item.SkipIfReplied {
+		messages, err := w.Sender.RecentMessages(ctx, item.ConversationID)
+		if err != nil {
+			return w.failed(item, fmt.Errorf("list messages: %w", err))
+		}
+		for _, m := range messages {
+			if m.MessageType == api.MessageTypeIncoming && !m.CreatedAtTime().Before(item.CreatedAt.Truncate(time.Second)) {
+				return done(StatusSkipped, "customer replied")
+			}
+		}
+	}
+
+	msg, err := w.Sender.Send(ctx, item)
+	if err != nil {
+		return w.failed(item, err)
+	}
+	item.MessageID = msg.ID
+	return done(StatusSent, "")
+}
+
+// findSent returns a message matching item that was created after it was
+// scheduled.
+func (w *Worker) findSent(ctx context.Context, item Item) (*api.Message, error) {
+	messages, err := w.Sender.RecentMessages(ctx, item.ConversationID)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(messages) - 1; i >= 0; i-- {
+		m := messages[i]
+		if m.MessageType == api.MessageTypeOutgoing && m.Private == item.Private &&
+			m.Content == item.Content && !m.CreatedAtTime().Before(item.CreatedAt.Truncate(time.Second)) {
+			return &m, nil
+		}
+	}
+	return nil, nil
+}
+
+// failed returns the item to the queue for another attempt, or marks it
+// failed for errors that will not go away or after MaxAttempts.
+func (w *Worker) failed(item Item, err error) Result {
+	item.Error = err.Error()
+	if permanent(err) || item.Attempts >= MaxAttempts {
+		item.Status, item.DoneAt = StatusFailed, w.now()
+		return Result{Item: item}
+	}
+	item.Status = StatusPending
+	return Result{Item: item, Retry: true}
+}
+
+// permanent reports whether err is a client error that a retry will not fix.
+func permanent(err error) bool {
+	var apiErr *api.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.StatusCode {
+	case http.StatusRequestTimeout, http.StatusConflict, http.StatusTooManyRequests:
+		return false
+	}
+	return apiErr.StatusCode >= 400 && apiErr.StatusCode < 500
+}
@@ -0,0 +1,261 @@
+// Package schedule keeps messages to be sent later in a local queue file and
+// delivers them when they are due.
+package schedule
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// FileName is the queue file name under the config directory.
+const FileName = "scheduled-messages.json"
+
+// Version is the on-disk format version of the queue file.
+const Version = 1
+
+// Lock timing for Update. Updates only hold the lock while reading and
+// writing the file, so an older lock was left behind by a killed process.
+const (
+	lockRetryInterval = 50 * time.Millisecond
+	lockWait          = 10 * time.Second
+	lockStale         = time.Minute
+)
+
+// Status is the delivery state of a scheduled message.
+type Status string
+
+const (
+	StatusPending  Status = "pending"
+	StatusSending  Status = "sending"
+	StatusSent     Status = "sent"
+	StatusSkipped  Status = "skipped"
+	StatusFailed   Status = "failed"
+	StatusCanceled Status = "canceled"
+)
+
+// Item is one scheduled message.
+type Item struct {
+	ID             string    `json:"id"`
+	BaseURL        string    `json:"base_url"`
+	AccountID      int       `json:"account_id"`
+	ConversationID int       `json:"conversation_id"`
+	Content        string    `json:"content"`
+	Private        bool      `json:"private,omitempty"`
+	SendAt         time.Time `json:"send_at"`
+	CreatedAt      time.Time `json:"created_at"`
+	SkipIfResolved bool      `json:"skip_if_resolved,omitempty"`
+	SkipIfReplied  bool      `json:"skip_if_replied,omitempty"`
+	IdempotencyKey string    `json:"idempotency_key"`
+	Status         Status    `json:"status"`
+	Attempts       int       `json:"attempts,omitempty"`
+	LastAttemptAt  time.Time `json:"last_attempt_at,omitzero"`
+	Error          string    `json:"error,omitempty"`
+	MessageID      int       `json:"message_id,omitempty"`
+	DoneAt         time.Time `json:"done_at,omitzero"`
+}
+
+// Done reports whether the item will not be sent (again).
+func (i Item) Done() bool {
+	switch i.Status {
+	case StatusSent, StatusSkipped, StatusFailed, StatusCanceled:
+		return true
+	}
+	return false
+}
+
+// Queue is the content of the queue file.
+type Queue struct {
+	Version int    `json:"version"`
+	Items   []Item `json:"items"`
+}
+
+// Add appends item as pending with a new ID and returns the stored copy.
+func (q *Queue) Add(item Item) Item {
+	item.ID = newID(q)
+	item.Status = StatusPending
+	q.Items = append(q.Items, item)
+	return item
+}
+
+// Find returns the item with the given ID or unique ID prefix.
+func (q *Queue) Find(id string) (*Item, error) {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return nil, fmt.Errorf("empty scheduled message ID")
+	}
+	var match *Item
+	for i := range q.Items {
+		if q.Items[i].ID == id {
+			return &q.Items[i], nil
+		}
+		if strings.HasPrefix(q.Items[i].ID, id) {
+			if match != nil {
+				return nil, fmt.Errorf("scheduled message ID %q is ambiguous", id)
+			}
+			match = &q.Items[i]
+		}
+	}
+	if match == nil {
+		return nil, fmt.Errorf("scheduled message %q not found", id)
+	}
+	return match, nil
+}
+
+// Cancel marks a pending item canceled. An item that is being sent can
+// only be canceled once its attempt has timed out.
+func (q *Queue) Cancel(id string, now time.Time) (Item, error) {
+	item, err := q.Find(id)
+	if err != nil {
+		return Item{}, err
+	}
+	switch {
+	case item.Done():
+		return *item, fmt.Errorf("scheduled message %s is already %s", item.ID, item.Status)
+	case item.Status == StatusSending && now.Sub(item.LastAttemptAt) <= ClaimTimeout:
+		return *item, fmt.Errorf("scheduled message %s is being sent", item.ID)
+	}
+	item.Status, item.DoneAt = StatusCanceled, now
+	return *item, nil
+}
+
+// Sorted returns the items ordered by send time.
+func (q *Queue) Sorted() []Item {
+	items := append([]Item(nil), q.Items...)
+	sort.SliceStable(items, func(i, j int) bool { return items[i].SendAt.Before(items[j].SendAt) })
+	return items
+}
+
+// Store reads and writes a queue file.
+type Store struct {
+	path string
+}
+
+// NewStore returns a store for the queue file at path.
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// DefaultPath returns the queue file path under dir.
+func DefaultPath(dir string) string {
+	return filepath.Join(dir, FileName)
+}
+
+// Path returns the queue file path.
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Load reads the queue. A missing file is an empty queue.
+func (s *Store) Load() (*Queue, error) {
+	q := &Queue{Version: Version}
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return q, nil
+		}
+		return nil, fmt.Errorf("read queue file: %w", err)
+	}
+	if err := json.Unmarshal(data, q); err != nil {
+		return nil, fmt.Errorf("parse queue file %s: %w", s.path, err)
+	}
+	if q.Version > Version {
+		return nil, fmt.Errorf("queue file version %d is newer than supported version %d", q.Version, Version)
+	}
+	q.Version = Version
+	return q, nil
+}
+
+// Update loads the queue under a lock file, calls fn, and saves the queue if
+// fn succeeds. The lock keeps a running worker and a new schedule command
+// from overwriting each other's changes.
+func (s *Store) Update(fn func(q *Queue) error) error {
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	q, err := s.Load()
+	if err != nil {
+		return err
+	}
+	if err := fn(q); err != nil {
+		return err
+	}
+	return s.save(q)
+}
+
+func (s *Store) lock() (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return nil, fmt.Errorf("create queue dir: %w", err)
+	}
+	lockPath := s.path + ".lock"
+	deadline := time.Now().Add(lockWait)
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err == nil {
+			_, _ = fmt.Fprintf(f, "%d\n", os.Getpid())
+			_ = f.Close()
+			return func() { _ = os.Remove(lockPath) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("lock queue file: %w", err)
+		}
+		if info, statErr := os.Stat(lockPath); statErr == nil && time.Since(info.ModTime()) > lockStale {
+			_ = os.Remove(lockPath)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("queue file is locked by another process (remove %s if no cw process is running)", lockPath)
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+func (s *Store) save(q *Queue) error {
+	data, err := json.MarshalIndent(q, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("write queue file: %w", err)
+	}
+	tmpName := tmp.Name()
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpName)
+		return fmt.Errorf("write queue file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmpName)
+		return fmt.Errorf("write queue file: %w", err)
+	}
+	if err := os.Rename(tmpName, s.path); err != nil {
+		_ = os.Remove(tmpName)
+		return fmt.Errorf("write queue file: %w", err)
+	}
+	return nil
+}
+
+// newID returns a short random ID not yet used in q.
+func newID(q *Queue) string {
+	for {
+		buf := make([]byte, 4)
+		if _, err := rand.Read(buf); err != nil {
+			return fmt.Sprintf("%08x", time.Now().UnixNano()&0xffffffff)
+		}
+		id := hex.EncodeToString(buf)
+		if _, err := q.Find(id); err != nil {
+			return id
+		}
+	}
+}
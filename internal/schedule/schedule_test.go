@@ -0,0 +1,257 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+)
+
+var (
+	testAccount = Account{BaseURL: "https://chat.example.com", AccountID: 1}
+	testNow     = time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+)
+
+type fakeSender struct {
+	mu        sync.Mutex
+	status    string
+	messages  []api.Message
+	sendErr   error
+	sent      []Item
+	nextMsgID int
+}
+
+func (f *fakeSender) Conversation(_ context.Context, id int) (*api.Conversation, error) {
+	return &api.Conversation{ID: id, Status: f.status}, nil
+}
+
+func (f *fakeSender) RecentMessages(context.Context, int) ([]api.Message, error) {
+	return f.messages, nil
+}
+
+func (f *fakeSender) Send(_ context.Context, item Item) (*api.Message, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.sendErr != nil {
+		return nil, f.sendErr
+	}
+	f.sent = append(f.sent, item)
+	f.nextMsgID++
+	return &api.Message{ID: 100 + f.nextMsgID, Content: item.Content}, nil
+}
+
+func newTestStore(t *testing.T, items ...Item) *Store {
+	t.Helper()
+	store := NewStore(filepath.Join(t.TempDir(), "cw", FileName))
+	err := store.Update(func(q *Queue) error {
+		for _, it := range items {
+			if it.BaseURL == "" {
+				it.BaseURL, it.AccountID = testAccount.BaseURL, testAccount.AccountID
+			}
+			if it.CreatedAt.IsZero() {
+				it.CreatedAt = testNow.Add(-time.Hour)
+			}
+			q.Add(it)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("seed queue: %v", err)
+	}
+	return store
+}
+
+func loadItems(t *testing.T, store *Store) []Item {
+	t.Helper()
+	q, err := store.Load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	return q.Sorted()
+}
+
+func runWorker(t *testing.T, store *Store, sender Sender) []Result {
+	t.Helper()
+	w := &Worker{Store: store, Sender: sender, Account: testAccount, Now: func() time.Time { return testNow }}
+	results, err := w.RunOnce(context.Background())
+	if err != nil {
+		t.Fatalf("RunOnce: %v", err)
+	}
+	return results
+}
+
+func TestStoreRoundTripAndCancel(t *testing.T) {
+	store := newTestStore(t,
+		Item{ConversationID: 1, Content: "later", SendAt: testNow.Add(2 * time.Hour)},
+		Item{ConversationID: 2, Content: "sooner", SendAt: testNow.Add(time.Hour)},
+	)
+	items := loadItems(t, store)
+	if len(items) != 2 || items[0].Content != "sooner" || items[0].Status != StatusPending || len(items[0].ID) != 8 {
+		t.Fatalf("unexpected items %+v", items)
+	}
+
+	err := store.Update(func(q *Queue) error {
+		_, err := q.Cancel(items[1].ID[:4], testNow)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	err = store.Update(func(q *Queue) error {
+		_, err := q.Cancel(items[1].ID, testNow)
+		return err
+	})
+	if err == nil || !strings.Contains(err.Error(), "already canceled") {
+		t.Fatalf("expected already canceled error, got %v", err)
+	}
+	if _, err := os.Stat(store.Path() + ".lock"); !os.IsNotExist(err) {
+		t.Fatalf("lock file should be removed, stat err = %v", err)
+	}
+}
+
+func TestStoreBreaksStaleLock(t *testing.T) {
+	store := newTestStore(t)
+	lock := store.Path() + ".lock"
+	if err := os.WriteFile(lock, []byte("1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * lockStale)
+	if err := os.Chtimes(lock, old, old); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Update(func(*Queue) error { return nil }); err != nil {
+		t.Fatalf("update with stale lock: %v", err)
+	}
+}
+
+func TestWorkerSendsDueMessagesOnly(t *testing.T) {
+	store := newTestStore(t,
+		Item{ConversationID: 1, Content: "due", SendAt: testNow.Add(-time.Minute), IdempotencyKey: "cwcli_a"},
+		Item{ConversationID: 2, Content: "future", SendAt: testNow.Add(time.Minute)},
+		Item{ConversationID: 3, Content: "other account", SendAt: testNow.Add(-time.Minute), BaseURL: "https://other.example.com", AccountID: 1},
+	)
+	sender := &fakeSender{status: "open"}
+	results := runWorker(t, store, sender)
+
+	if len(results) != 1 || results[0].Status != StatusSent || results[0].MessageID != 101 {
+		t.Fatalf("unexpected results %+v", results)
+	}
+	if len(sender.sent) != 1 || sender.sent[0].IdempotencyKey != "cwcli_a" {
+		t.Fatalf("expected one send with the stored idempotency key, got %+v", sender.sent)
+	}
+	statuses := map[string]Status{}
+	for _, it := range loadItems(t, store) {
+		statuses[it.Content] = it.Status
+	}
+	want := map[string]Status{"due": StatusSent, "future": StatusPending, "other account": StatusPending}
+	for k, v := range want {
+		if statuses[k] != v {
+			t.Errorf("%s: status %s, want %s", k, statuses[k], v)
+		}
+	}
+
+	// A second run has nothing to do.
+	if results := runWorker(t, store, sender); len(results) != 0 {
+		t.Fatalf("second run should be empty, got %+v", results)
+	}
+}
+
+func TestWorkerSkipConditions(t *testing.T) {
+	store := newTestStore(t,
+		Item{ConversationID: 1, Content: "resolved", SendAt: testNow, SkipIfResolved: true},
+	)
+	results := runWorker(t, store, &fakeSender{status: "resolved"})
+	if len(results) != 1 || results[0].Status != StatusSkipped || results[0].Error != "conversation was resolved" {
+		t.Fatalf("unexpected results %+v", results)
+	}
+
+	store = newTestStore(t,
+		Item{ConversationID: 1, Content: "nudge", SendAt: testNow, SkipIfReplied: true},
+	)
+	sender := &fakeSender{messages: []api.Message{
+		{ID: 1, MessageType: api.MessageTypeIncoming, CreatedAt: testNow.Add(-2 * time.Hour).Unix()},
+		{ID: 2, MessageType: api.MessageTypeOutgoing, CreatedAt: testNow.Add(-30 * time.Minute).Unix()},
+	}}
+	if results := runWorker(t, store, sender); results[0].Status != StatusSent {
+		t.Fatalf("older incoming message should not skip, got %+v", results)
+	}
+
+	store = newTestStore(t,
+		Item{ConversationID: 1, Content: "nudge", SendAt: testNow, SkipIfReplied: true},
+	)
+	sender.messages = append(sender.messages, api.Message{ID: 3, MessageType: api.MessageTypeIncoming, CreatedAt: testNow.Add(-10 * time.Minute).Unix()})
+	if results := runWorker(t, store, sender); results[0].Status != StatusSkipped || results[0].Error != "customer replied" {
+		t.Fatalf("expected skip after customer reply, got %+v", results)
+	}
+}
+
+func TestWorkerRetriesWithoutDoubleSending(t *testing.T) {
+	store := newTestStore(t, Item{ConversationID: 1, Content: "hello", SendAt: testNow})
+	sender := &fakeSender{sendErr: errors.New("connection reset")}
+
+	results := runWorker(t, store, sender)
+	if len(results) != 1 || !results[0].Retry || results[0].Status != StatusPending || results[0].Attempts != 1 {
+		t.Fatalf("expected retryable failure, got %+v", results)
+	}
+
+	// The failed request actually reached Chatwoot: the retry finds the
+	// message instead of sending it again.
+	sender.sendErr = nil
+	sender.messages = []api.Message{{ID: 77, Content: "hello", MessageType: api.MessageTypeOutgoing, CreatedAt: testNow.Unix()}}
+	results = runWorker(t, store, sender)
+	if len(results) != 1 || results[0].Status != StatusSent || results[0].MessageID != 77 {
+		t.Fatalf("expected message to be recognised as sent, got %+v", results)
+	}
+	if len(sender.sent) != 0 {
+		t.Fatalf("message was sent twice: %+v", sender.sent)
+	}
+}
+
+func TestWorkerReclaimsInterruptedSend(t *testing.T) {
+	store := newTestStore(t, Item{ConversationID: 1, Content: "hello", SendAt: testNow.Add(-time.Hour)})
+	err := store.Update(func(q *Queue) error {
+		q.Items[0].Status = StatusSending
+		q.Items[0].Attempts = 1
+		q.Items[0].LastAttemptAt = testNow.Add(-time.Minute)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sender := &fakeSender{}
+	if results := runWorker(t, store, sender); len(results) != 0 {
+		t.Fatalf("recent claim should be left alone, got %+v", results)
+	}
+
+	_ = store.Update(func(q *Queue) error {
+		q.Items[0].LastAttemptAt = testNow.Add(-2 * ClaimTimeout)
+		return nil
+	})
+	results := runWorker(t, store, sender)
+	if len(results) != 1 || results[0].Status != StatusSent || results[0].Attempts != 2 || len(sender.sent) != 1 {
+		t.Fatalf("stale claim should be retried, got %+v sent=%d", results, len(sender.sent))
+	}
+}
+
+func TestWorkerPermanentAndExhaustedFailures(t *testing.T) {
+	store := newTestStore(t, Item{ConversationID: 1, Content: "hello", SendAt: testNow})
+	results := runWorker(t, store, &fakeSender{sendErr: &api.APIError{StatusCode: 404, Body: "not found"}})
+	if results[0].Status != StatusFailed || results[0].Retry {
+		t.Fatalf("404 should fail permanently, got %+v", results)
+	}
+
+	store = newTestStore(t, Item{ConversationID: 1, Content: "hello", SendAt: testNow})
+	sender := &fakeSender{sendErr: &api.APIError{StatusCode: 503}}
+	for range MaxAttempts {
+		results = runWorker(t, store, sender)
+	}
+	if results[0].Status != StatusFailed || results[0].Attempts != MaxAttempts {
+		t.Fatalf("expected failure after %d attempts, got %+v", MaxAttempts, results)
+	}
+}
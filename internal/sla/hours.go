@@ -0,0 +1,146 @@
+package sla
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+)
+
+// Schedule is a weekly set of business hours in one time zone. A nil
+// *Schedule means the clock always runs.
+type Schedule struct {
+	loc  *time.Location
+	days [7]window // indexed by time.Weekday
+}
+
+// window is one day's open interval in minutes after midnight.
+type window struct {
+	open, close int
+}
+
+func (w window) closed() bool {
+	return w.close <= w.open
+}
+
+// NewSchedule builds a schedule from Chatwoot working hours. Days missing from
+// hours are closed. It returns nil when no day is open, so an inbox with
+// misconfigured hours does not stop the clock forever.
+func NewSchedule(loc *time.Location, hours []api.WorkingHour) (*Schedule, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	s := &Schedule{loc: loc}
+	anyOpen := false
+	for _, h := range hours {
+		if h.DayOfWeek < 0 || h.DayOfWeek > 6 {
+			return nil, fmt.Errorf("invalid day_of_week %d", h.DayOfWeek)
+		}
+		var w window
+		switch {
+		case h.ClosedAllDay:
+		case h.OpenAllDay:
+			w = window{open: 0, close: 24 * 60}
+		default:
+			w = window{open: h.OpenHour*60 + h.OpenMinutes, close: h.CloseHour*60 + h.CloseMinutes}
+			// 23:59 is how Chatwoot spells "until midnight".
+			if w.close == 24*60-1 {
+				w.close = 24 * 60
+			}
+		}
+		s.days[h.DayOfWeek] = w
+		if !w.closed() {
+			anyOpen = true
+		}
+	}
+	if !anyOpen {
+		return nil, nil
+	}
+	return s, nil
+}
+
+// ScheduleFromInbox returns the inbox's business hours, or nil when working
+// hours are disabled.
+func ScheduleFromInbox(inbox *api.Inbox) (*Schedule, error) {
+	if inbox == nil || !inbox.WorkingHoursEnabled || len(inbox.WorkingHours) == 0 {
+		return nil, nil
+	}
+	loc := time.UTC
+	if inbox.Timezone != "" {
+		l, err := time.LoadLocation(inbox.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("inbox %d: unknown timezone %q", inbox.ID, inbox.Timezone)
+		}
+		loc = l
+	}
+	s, err := NewSchedule(loc, inbox.WorkingHours)
+	if err != nil {
+		return nil, fmt.Errorf("inbox %d: %w", inbox.ID, err)
+	}
+	return s, nil
+}
+
+// bounds returns the open interval of t's day.
+func (s *Schedule) bounds(t time.Time) (open, close time.Time, ok bool) {
+	w := s.days[t.Weekday()]
+	if w.closed() {
+		return time.Time{}, time.Time{}, false
+	}
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, w.open, 0, 0, s.loc), time.Date(y, m, d, 0, w.close, 0, 0, s.loc), true
+}
+
+func nextDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d+1, 0, 0, 0, 0, t.Location())
+}
+
+// Add returns the time at which d of business time has elapsed after t.
+func (s *Schedule) Add(t time.Time, d time.Duration) time.Time {
+	if s == nil {
+		return t.Add(d)
+	}
+	t = t.In(s.loc)
+	for {
+		if open, close, ok := s.bounds(t); ok && t.Before(close) {
+			if t.Before(open) {
+				t = open
+			}
+			avail := close.Sub(t)
+			if d <= avail {
+				return t.Add(d)
+			}
+			d -= avail
+		}
+		t = nextDay(t)
+	}
+}
+
+// Between returns the business time elapsed from a to b (zero if b is not
+// after a).
+func (s *Schedule) Between(a, b time.Time) time.Duration {
+	if !b.After(a) {
+		return 0
+	}
+	if s == nil {
+		return b.Sub(a)
+	}
+	var total time.Duration
+	for t := a.In(s.loc); t.Before(b); t = nextDay(t) {
+		open, close, ok := s.bounds(t)
+		if !ok {
+			continue
+		}
+		from, to := open, close
+		if t.After(from) {
+			from = t
+		}
+		if b.Before(to) {
+			to = b
+		}
+		if to.After(from) {
+			total += to.Sub(from)
+		}
+	}
+	return total
+}
@@ -0,0 +1,202 @@
+// Package sla computes first-response and next-response deadlines for
+// conversations from their message history, using per inbox, priority or
+// label policies and the inbox's working hours.
+package sla
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+)
+
+// Version is the policy file format version.
+const Version = 1
+
+// DefaultAtRisk is the warning window used when neither the policy nor the
+// file sets at_risk.
+const DefaultAtRisk = 15 * time.Minute
+
+// PolicySet is the parsed policy file. Policies are tried in order and the
+// first match applies.
+type PolicySet struct {
+	Version  int           `yaml:"version" json:"version"`
+	AtRisk   time.Duration `yaml:"at_risk,omitempty" json:"at_risk,omitempty"`
+	Policies []Policy      `yaml:"policies" json:"policies"`
+}
+
+// Policy holds the response targets for conversations it matches.
+type Policy struct {
+	Name  string `yaml:"name" json:"name"`
+	Match Match  `yaml:"match,omitempty" json:"match,omitempty"`
+	// FirstResponse is measured from conversation creation to the first agent
+	// reply; NextResponse from a customer message to the following agent reply.
+	// Zero disables that target.
+	FirstResponse time.Duration `yaml:"first_response,omitempty" json:"first_response,omitempty"`
+	NextResponse  time.Duration `yaml:"next_response,omitempty" json:"next_response,omitempty"`
+	// AtRisk overrides the file-level warning window.
+	AtRisk time.Duration `yaml:"at_risk,omitempty" json:"at_risk,omitempty"`
+	// BusinessHours counts only the inbox's working hours (when the inbox has
+	// them enabled). Defaults to true.
+	BusinessHours *bool `yaml:"business_hours,omitempty" json:"business_hours,omitempty"`
+}
+
+// Match selects conversations. All non-empty fields must hold; an empty
+// match applies to every conversation.
+type Match struct {
+	InboxIDs []int `yaml:"inbox_ids,omitempty" json:"inbox_ids,omitempty"`
+	// Priorities match the conversation priority; "none" matches unset.
+	Priorities []string `yaml:"priorities,omitempty" json:"priorities,omitempty"`
+	// Labels match when the conversation has any of them.
+	Labels []string `yaml:"labels,omitempty" json:"labels,omitempty"`
+}
+
+var validPriorities = []string{"urgent", "high", "medium", "low", "none"}
+
+// Load reads and validates a policy file (YAML or JSON).
+func Load(path string) (*PolicySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read policy: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse decodes and validates a policy file. Unknown fields are rejected.
+func Parse(data []byte) (*PolicySet, error) {
+	var ps PolicySet
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&ps); err != nil {
+		return nil, fmt.Errorf("parse policy: %w", err)
+	}
+	if ps.Version == 0 {
+		ps.Version = Version
+	}
+	if ps.Version > Version {
+		return nil, fmt.Errorf("policy version %d is newer than supported version %d", ps.Version, Version)
+	}
+	if err := ps.Validate(); err != nil {
+		return nil, err
+	}
+	return &ps, nil
+}
+
+// Validate checks names, durations and priorities.
+func (ps *PolicySet) Validate() error {
+	if len(ps.Policies) == 0 {
+		return fmt.Errorf("policy file has no policies")
+	}
+	if ps.AtRisk < 0 {
+		return fmt.Errorf("at_risk must be positive")
+	}
+	seen := map[string]bool{}
+	for i := range ps.Policies {
+		p := &ps.Policies[i]
+		p.Name = strings.TrimSpace(p.Name)
+		if p.Name == "" {
+			return fmt.Errorf("policy %d: name is required", i+1)
+		}
+		if seen[p.Name] {
+			return fmt.Errorf("policy %q: duplicate name", p.Name)
+		}
+		seen[p.Name] = true
+		if err := p.validate(); err != nil {
+			return fmt.Errorf("policy %q: %w", p.Name, err)
+		}
+	}
+	return nil
+}
+
+func (p *Policy) validate() error {
+	if p.FirstResponse < 0 || p.NextResponse < 0 || p.AtRisk < 0 {
+		return fmt.Errorf("durations must be positive")
+	}
+	if p.FirstResponse == 0 && p.NextResponse == 0 {
+		return fmt.Errorf("set first_response and/or next_response")
+	}
+	for _, pr := range p.Match.Priorities {
+		if !oneOf(pr, validPriorities) {
+			return fmt.Errorf("invalid priority %q (valid: %s)", pr, strings.Join(validPriorities, ", "))
+		}
+	}
+	return nil
+}
+
+// For returns the first policy matching the conversation, or nil.
+func (ps *PolicySet) For(conv *api.Conversation) *Policy {
+	for i := range ps.Policies {
+		if ps.Policies[i].Match.matches(conv) {
+			return &ps.Policies[i]
+		}
+	}
+	return nil
+}
+
+// atRisk returns the warning window for p.
+func (ps *PolicySet) atRisk(p *Policy) time.Duration {
+	switch {
+	case p.AtRisk > 0:
+		return p.AtRisk
+	case ps.AtRisk > 0:
+		return ps.AtRisk
+	default:
+		return DefaultAtRisk
+	}
+}
+
+// UsesBusinessHours reports whether the policy counts only working hours.
+func (p *Policy) UsesBusinessHours() bool {
+	return p.BusinessHours == nil || *p.BusinessHours
+}
+
+func (m Match) matches(conv *api.Conversation) bool {
+	if len(m.InboxIDs) > 0 {
+		found := false
+		for _, id := range m.InboxIDs {
+			if id == conv.InboxID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(m.Priorities) > 0 {
+		priority := "none"
+		if conv.Priority != nil && *conv.Priority != "" {
+			priority = *conv.Priority
+		}
+		if !oneOf(priority, m.Priorities) {
+			return false
+		}
+	}
+	if len(m.Labels) > 0 {
+		found := false
+		for _, l := range conv.Labels {
+			if oneOf(l, m.Labels) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func oneOf(v string, set []string) bool {
+	for _, s := range set {
+		if strings.EqualFold(strings.TrimSpace(v), strings.TrimSpace(s)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,256 @@
+package sla
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+)
+
+// Targets a conversation can be waiting on.
+const (
+	TargetFirstResponse = "first_response"
+	TargetNextResponse  = "next_response"
+)
+
+// States of a pending target.
+const (
+	StateOK       = "ok"
+	StateAtRisk   = "at_risk"
+	StateBreached = "breached"
+)
+
+// Status is the pending response target of one conversation.
+type Status struct {
+	ConversationID int    `json:"conversation_id"`
+	InboxID        int    `json:"inbox_id"`
+	Priority       string `json:"priority,omitempty"`
+	Policy         string `json:"policy"`
+	Target         string `json:"target"`
+	State          string `json:"state"`
+	// Since is when the clock started: conversation creation for the first
+	// response, the oldest unanswered customer message for the next.
+	Since    time.Time `json:"since"`
+	Deadline time.Time `json:"deadline"`
+	// Remaining is business time left before the deadline; negative once
+	// breached.
+	Remaining        time.Duration `json:"-"`
+	RemainingSeconds int64         `json:"remaining_seconds"`
+	BusinessHours    bool          `json:"business_hours"`
+}
+
+// Breached reports whether the deadline has passed.
+func (s Status) Breached() bool {
+	return s.State == StateBreached
+}
+
+// Key identifies one deadline; it changes when the conversation starts
+// waiting on a new target.
+func (s Status) Key() string {
+	return s.Target + "@" + s.Since.UTC().Format(time.RFC3339)
+}
+
+// Evaluate computes the pending target for conv from its messages. ok is
+// false when no policy applies or nobody is waiting on a reply.
+func (ps *PolicySet) Evaluate(conv *api.Conversation, messages []api.Message, sched *Schedule, now time.Time) (Status, bool) {
+	p := ps.For(conv)
+	if p == nil {
+		return Status{}, false
+	}
+
+	msgs := make([]api.Message, 0, len(messages))
+	for _, m := range messages {
+		if m.Private || (m.MessageType != api.MessageTypeIncoming && m.MessageType != api.MessageTypeOutgoing) {
+			continue
+		}
+		msgs = append(msgs, m)
+	}
+	sort.SliceStable(msgs, func(i, j int) bool { return msgs[i].CreatedAt < msgs[j].CreatedAt })
+
+	var lastReply int64
+	if conv.FirstReplyCreatedAt != nil {
+		lastReply = *conv.FirstReplyCreatedAt
+	}
+	var waitingSince int64
+	for _, m := range msgs {
+		switch {
+		case m.MessageType == api.MessageTypeOutgoing:
+			lastReply = max(lastReply, m.CreatedAt)
+			waitingSince = 0
+		case m.CreatedAt > lastReply && waitingSince == 0:
+			waitingSince = m.CreatedAt
+		}
+	}
+
+	st := Status{
+		ConversationID: conv.ID,
+		InboxID:        conv.InboxID,
+		Policy:         p.Name,
+		BusinessHours:  p.UsesBusinessHours() && sched != nil,
+	}
+	if conv.Priority != nil {
+		st.Priority = *conv.Priority
+	}
+	var target time.Duration
+	switch {
+	case lastReply == 0:
+		st.Target, target = TargetFirstResponse, p.FirstResponse
+		st.Since = conv.CreatedAtTime()
+		if conv.CreatedAt == 0 && waitingSince > 0 {
+			st.Since = time.Unix(waitingSince, 0)
+		}
+	case waitingSince > 0:
+		st.Target, target = TargetNextResponse, p.NextResponse
+		st.Since = time.Unix(waitingSince, 0)
+	default:
+		return Status{}, false
+	}
+	if target == 0 {
+		return Status{}, false
+	}
+
+	if !st.BusinessHours {
+		sched = nil
+	}
+	st.Deadline = sched.Add(st.Since, target)
+	return ps.reassess(st, p, sched, now), true
+}
+
+// reassess recomputes the remaining time and state of st at now.
+func (ps *PolicySet) reassess(st Status, p *Policy, sched *Schedule, now time.Time) Status {
+	if now.Before(st.Deadline) {
+		st.Remaining = sched.Between(now, st.Deadline)
+	} else {
+		st.Remaining = -sched.Between(st.Deadline, now)
+	}
+	st.RemainingSeconds = int64(st.Remaining / time.Second)
+	switch {
+	case !now.Before(st.Deadline):
+		st.State = StateBreached
+	case st.Remaining <= ps.atRisk(p):
+		st.State = StateAtRisk
+	default:
+		st.State = StateOK
+	}
+	return st
+}
+
+// Client is the subset of the Chatwoot API the checker reads from.
+// NewAPIClient adapts *api.Client.
+type Client interface {
+	GetConversation(ctx context.Context, id int) (*api.Conversation, error)
+	ListMessages(ctx context.Context, conversationID int) ([]api.Message, error)
+	GetInbox(ctx context.Context, id int) (*api.Inbox, error)
+}
+
+type apiClient struct {
+	client *api.Client
+}
+
+// NewAPIClient wraps an API client for use by the checker.
+func NewAPIClient(client *api.Client) Client {
+	return apiClient{client: client}
+}
+
+func (c apiClient) GetConversation(ctx context.Context, id int) (*api.Conversation, error) {
+	return c.client.Conversations().Get(ctx, id)
+}
+
+func (c apiClient) ListMessages(ctx context.Context, conversationID int) ([]api.Message, error) {
+	return c.client.Messages().List(ctx, conversationID)
+}
+
+func (c apiClient) GetInbox(ctx context.Context, id int) (*api.Inbox, error) {
+	return c.client.Inboxes().Get(ctx, id)
+}
+
+// Checker evaluates conversations against a policy set, caching inbox
+// working hours.
+type Checker struct {
+	policies *PolicySet
+	client   Client
+	now      func() time.Time
+
+	mu        sync.Mutex
+	schedules map[int]*Schedule
+}
+
+// NewChecker returns a checker. now defaults to time.Now.
+func NewChecker(policies *PolicySet, client Client, now func() time.Time) *Checker {
+	if now == nil {
+		now = time.Now
+	}
+	return &Checker{policies: policies, client: client, now: now, schedules: map[int]*Schedule{}}
+}
+
+// Check evaluates an already-fetched conversation. Only open conversations
+// have a pending target.
+func (c *Checker) Check(ctx context.Context, conv *api.Conversation) (Status, bool, error) {
+	if conv.Status != "open" || c.policies.For(conv) == nil {
+		return Status{}, false, nil
+	}
+	messages, err := c.client.ListMessages(ctx, conv.ID)
+	if err != nil {
+		return Status{}, false, err
+	}
+	sched, err := c.schedule(ctx, conv.InboxID)
+	if err != nil {
+		return Status{}, false, err
+	}
+	st, ok := c.policies.Evaluate(conv, messages, sched, c.now())
+	return st, ok, nil
+}
+
+// CheckID fetches and evaluates a conversation.
+func (c *Checker) CheckID(ctx context.Context, id int) (Status, bool, error) {
+	conv, err := c.client.GetConversation(ctx, id)
+	if err != nil {
+		return Status{}, false, err
+	}
+	return c.Check(ctx, conv)
+}
+
+// Reassess recomputes remaining time and state without refetching. Deadlines
+// only move when messages arrive, so a timer can call this cheaply.
+func (c *Checker) Reassess(st Status) Status {
+	var p *Policy
+	for i := range c.policies.Policies {
+		if c.policies.Policies[i].Name == st.Policy {
+			p = &c.policies.Policies[i]
+			break
+		}
+	}
+	if p == nil {
+		return st
+	}
+	var sched *Schedule
+	if st.BusinessHours {
+		c.mu.Lock()
+		sched = c.schedules[st.InboxID]
+		c.mu.Unlock()
+	}
+	return c.policies.reassess(st, p, sched, c.now())
+}
+
+func (c *Checker) schedule(ctx context.Context, inboxID int) (*Schedule, error) {
+	c.mu.Lock()
+	sched, ok := c.schedules[inboxID]
+	c.mu.Unlock()
+	if ok {
+		return sched, nil
+	}
+	inbox, err := c.client.GetInbox(ctx, inboxID)
+	if err != nil {
+		return nil, err
+	}
+	sched, err = ScheduleFromInbox(inbox)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.schedules[inboxID] = sched
+	c.mu.Unlock()
+	return sched, nil
+}
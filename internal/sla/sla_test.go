@@ -0,0 +1,274 @@
+package sla_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+	"github.com/chatwoot/chatwoot-cli/internal/sla"
+)
+
+const testPolicy = `
+at_risk: 20m
+policies:
+  - name: vip
+    match:
+      labels: [vip]
+    first_response: 15m
+    next_response: 30m
+  - name: urgent
+    match:
+      priorities: [urgent]
+      inbox_ids: [1]
+    first_response: 30m
+    at_risk: 5m
+  - name: default
+    first_response: 1h
+    next_response: 4h
+`
+
+func strPtr(s string) *string { return &s }
+
+// Monday 2026-03-02 10:00 UTC.
+var monday = time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+
+func weekdayHours(open, close int) []api.WorkingHour {
+	hours := []api.WorkingHour{{DayOfWeek: 0, ClosedAllDay: true}, {DayOfWeek: 6, ClosedAllDay: true}}
+	for d := 1; d <= 5; d++ {
+		hours = append(hours, api.WorkingHour{DayOfWeek: d, OpenHour: open, CloseHour: close})
+	}
+	return hours
+}
+
+func TestParsePolicy(t *testing.T) {
+	ps, err := sla.Parse([]byte(testPolicy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ps.Policies[0].FirstResponse != 15*time.Minute || !ps.Policies[0].UsesBusinessHours() {
+		t.Fatalf("unexpected policy: %+v", ps.Policies[0])
+	}
+
+	for doc, want := range map[string]string{
+		"policies: []":           "no policies",
+		"policies:\n  - name: x": "first_response",
+		"policies:\n  - name: x\n    first_response: -1m":                                    "positive",
+		"policies:\n  - name: x\n    match: {priorities: [p1]}\n    first_response: 1h":      "invalid priority",
+		"policies:\n  - name: x\n    match: {label: [a]}\n    first_response: 1h":            "label",
+		"policies:\n  - name: x\n    first_response: 1h\n  - name: x\n    next_response: 1h": "duplicate",
+		"version: 2\npolicies:\n  - name: x\n    first_response: 1h":                         "newer",
+	} {
+		if _, err := sla.Parse([]byte(doc)); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Parse(%q) error = %v, want %q", doc, err, want)
+		}
+	}
+}
+
+func TestPolicyFor(t *testing.T) {
+	ps, err := sla.Parse([]byte(testPolicy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		conv api.Conversation
+		want string
+	}{
+		{api.Conversation{InboxID: 1, Labels: []string{"VIP"}, Priority: strPtr("urgent")}, "vip"},
+		{api.Conversation{InboxID: 1, Priority: strPtr("urgent")}, "urgent"},
+		{api.Conversation{InboxID: 2, Priority: strPtr("urgent")}, "default"},
+		{api.Conversation{InboxID: 1}, "default"},
+	}
+	for _, tt := range tests {
+		if got := ps.For(&tt.conv); got == nil || got.Name != tt.want {
+			t.Errorf("For(%+v) = %v, want %s", tt.conv, got, tt.want)
+		}
+	}
+}
+
+func TestScheduleAddAndBetween(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("tzdata not available")
+	}
+	sched, err := sla.NewSchedule(ny, weekdayHours(9, 17))
+	if err != nil || sched == nil {
+		t.Fatalf("NewSchedule: %v %v", sched, err)
+	}
+
+	// Friday 16:30 New York + 1h of business time = Monday 09:30.
+	friday := time.Date(2026, 3, 6, 16, 30, 0, 0, ny)
+	got := sched.Add(friday, time.Hour)
+	if want := time.Date(2026, 3, 9, 9, 30, 0, 0, ny); !got.Equal(want) {
+		t.Fatalf("Add = %s, want %s", got, want)
+	}
+	if between := sched.Between(friday, got); between != time.Hour {
+		t.Fatalf("Between = %s, want 1h", between)
+	}
+	// Before opening, the clock starts at opening time.
+	early := time.Date(2026, 3, 9, 6, 0, 0, 0, ny)
+	if got := sched.Add(early, 30*time.Minute); !got.Equal(time.Date(2026, 3, 9, 9, 30, 0, 0, ny)) {
+		t.Fatalf("Add before opening = %s", got)
+	}
+	// A full weekday contributes 8h.
+	if got := sched.Between(friday.Add(-24*time.Hour), friday); got != 8*time.Hour {
+		t.Fatalf("Between one day = %s", got)
+	}
+
+	var always *sla.Schedule
+	if got := always.Add(friday, time.Hour); !got.Equal(friday.Add(time.Hour)) {
+		t.Fatalf("nil schedule Add = %s", got)
+	}
+
+	if s, err := sla.NewSchedule(time.UTC, []api.WorkingHour{{DayOfWeek: 1, ClosedAllDay: true}}); s != nil || err != nil {
+		t.Fatalf("schedule with no open day = %v, %v; want nil", s, err)
+	}
+	if _, err := sla.ScheduleFromInbox(&api.Inbox{ID: 4, WorkingHoursEnabled: true, Timezone: "Mars/Olympus", WorkingHours: weekdayHours(9, 17)}); err == nil {
+		t.Fatal("expected unknown timezone error")
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	ps, err := sla.Parse([]byte(testPolicy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	conv := &api.Conversation{ID: 7, InboxID: 2, Status: "open", CreatedAt: monday.Add(-50 * time.Minute).Unix()}
+	msg := func(id, typ int, ago time.Duration, private bool) api.Message {
+		return api.Message{ID: id, MessageType: typ, Private: private, CreatedAt: monday.Add(-ago).Unix()}
+	}
+
+	// Unanswered: first response due 1h after creation, 10m left -> at risk.
+	st, ok := ps.Evaluate(conv, []api.Message{
+		msg(1, api.MessageTypeIncoming, 50*time.Minute, false),
+		msg(2, api.MessageTypeOutgoing, 40*time.Minute, true),
+		msg(3, api.MessageTypeActivity, 30*time.Minute, false),
+	}, nil, monday)
+	if !ok || st.Target != sla.TargetFirstResponse || st.State != sla.StateAtRisk || st.Remaining != 10*time.Minute || st.RemainingSeconds != 600 {
+		t.Fatalf("first response: %+v %v", st, ok)
+	}
+
+	// Answered, then two customer messages: next response runs from the first.
+	st, ok = ps.Evaluate(conv, []api.Message{
+		msg(5, api.MessageTypeIncoming, 4*time.Hour+time.Minute, false),
+		msg(4, api.MessageTypeIncoming, 5*time.Hour, false),
+		msg(3, api.MessageTypeOutgoing, 6*time.Hour, false),
+	}, nil, monday)
+	if !ok || st.Target != sla.TargetNextResponse || !st.Breached() || st.Remaining != -time.Hour {
+		t.Fatalf("next response: %+v %v", st, ok)
+	}
+
+	// Agent replied last: nothing pending.
+	if st, ok := ps.Evaluate(conv, []api.Message{
+		msg(1, api.MessageTypeIncoming, 40*time.Minute, false),
+		msg(2, api.MessageTypeOutgoing, 30*time.Minute, false),
+	}, nil, monday); ok {
+		t.Fatalf("expected nothing pending, got %+v", st)
+	}
+
+	// The first reply can fall outside the fetched page.
+	reply := monday.Add(-2 * time.Hour).Unix()
+	answered := *conv
+	answered.FirstReplyCreatedAt = &reply
+	st, ok = ps.Evaluate(&answered, []api.Message{msg(9, api.MessageTypeIncoming, time.Hour, false)}, nil, monday)
+	if !ok || st.Target != sla.TargetNextResponse || st.State != sla.StateOK {
+		t.Fatalf("next response after off-page reply: %+v %v", st, ok)
+	}
+
+	// Policies without a next_response target ignore follow-ups.
+	urgent := api.Conversation{ID: 8, InboxID: 1, Priority: strPtr("urgent"), FirstReplyCreatedAt: &reply}
+	if _, ok := ps.Evaluate(&urgent, []api.Message{msg(9, api.MessageTypeIncoming, time.Hour, false)}, nil, monday); ok {
+		t.Fatal("urgent policy has no next_response target")
+	}
+}
+
+func TestEvaluateBusinessHours(t *testing.T) {
+	ps, err := sla.Parse([]byte(testPolicy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sched, err := sla.NewSchedule(time.UTC, weekdayHours(9, 17))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Created Friday 16:30 UTC; with 1h of business time the deadline is
+	// Monday 09:30, so at Monday 09:10 there are 20 minutes left.
+	conv := &api.Conversation{ID: 7, InboxID: 2, Status: "open", CreatedAt: time.Date(2026, 2, 27, 16, 30, 0, 0, time.UTC).Unix()}
+	now := time.Date(2026, 3, 2, 9, 10, 0, 0, time.UTC)
+	st, ok := ps.Evaluate(conv, nil, sched, now)
+	if !ok || !st.BusinessHours || st.Remaining != 20*time.Minute || st.State != sla.StateAtRisk {
+		t.Fatalf("business hours: %+v %v", st, ok)
+	}
+	if want := time.Date(2026, 3, 2, 9, 30, 0, 0, time.UTC); !st.Deadline.Equal(want) {
+		t.Fatalf("deadline = %s, want %s", st.Deadline, want)
+	}
+
+	calendar, err := sla.Parse([]byte("policies:\n  - name: all\n    first_response: 1h\n    business_hours: false\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st, _ := calendar.Evaluate(conv, nil, sched, now); st.BusinessHours || !st.Breached() {
+		t.Fatalf("business_hours: false should use wall-clock time: %+v", st)
+	}
+}
+
+type fakeClient struct {
+	conv       *api.Conversation
+	messages   []api.Message
+	inbox      *api.Inbox
+	inboxCalls int
+}
+
+func (f *fakeClient) GetConversation(_ context.Context, id int) (*api.Conversation, error) {
+	if f.conv == nil || f.conv.ID != id {
+		return nil, errors.New("not found")
+	}
+	c := *f.conv
+	return &c, nil
+}
+
+func (f *fakeClient) ListMessages(context.Context, int) ([]api.Message, error) {
+	return f.messages, nil
+}
+
+func (f *fakeClient) GetInbox(context.Context, int) (*api.Inbox, error) {
+	f.inboxCalls++
+	return f.inbox, nil
+}
+
+func TestChecker(t *testing.T) {
+	ps, err := sla.Parse([]byte(testPolicy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &fakeClient{
+		conv:  &api.Conversation{ID: 7, InboxID: 2, Status: "open", CreatedAt: monday.Add(-30 * time.Minute).Unix()},
+		inbox: &api.Inbox{ID: 2},
+	}
+	now := monday
+	checker := sla.NewChecker(ps, client, func() time.Time { return now })
+
+	st, ok, err := checker.CheckID(context.Background(), 7)
+	if err != nil || !ok || st.State != sla.StateOK || st.BusinessHours {
+		t.Fatalf("CheckID = %+v %v %v", st, ok, err)
+	}
+
+	now = monday.Add(45 * time.Minute)
+	if st = checker.Reassess(st); !st.Breached() || st.Remaining != -15*time.Minute {
+		t.Fatalf("Reassess = %+v", st)
+	}
+
+	if _, _, err := checker.CheckID(context.Background(), 7); err != nil || client.inboxCalls != 1 {
+		t.Fatalf("inbox should be fetched once, got %d calls (%v)", client.inboxCalls, err)
+	}
+
+	client.conv.Status = "snoozed"
+	if _, ok, _ := checker.CheckID(context.Background(), 7); ok {
+		t.Fatal("only open conversations have pending deadlines")
+	}
+	if _, _, err := checker.CheckID(context.Background(), 99); err == nil {
+		t.Fatal("expected error for missing conversation")
+	}
+}
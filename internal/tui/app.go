@@ -0,0 +1,155 @@
+package tui
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// Terminal control sequences.
+const (
+	enterScreen = "\x1b[?1049h\x1b[?25l" // alternate screen, hide cursor
+	leaveScreen = "\x1b[?25h\x1b[?1049l"
+	cursorHome  = "\x1b[H"
+	clearLine   = "\x1b[K"
+)
+
+// sizePollInterval is how often the terminal size is checked for resizes.
+const sizePollInterval = 250 * time.Millisecond
+
+// Options configures Run.
+type Options struct {
+	// In delivers raw key bytes; the caller puts the terminal in raw mode.
+	In  io.Reader
+	Out io.Writer
+	// Size returns the terminal size in cells.
+	Size func() (width, height int)
+	// Status is the initial conversation status filter.
+	Status string
+	Color  bool
+}
+
+// Run draws the TUI and processes keys and live events until the user
+// quits, input ends, or ctx is done.
+func Run(ctx context.Context, backend Backend, opts Options) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	model := NewModel(backend, backend.Live(ctx), opts.Status)
+	model.SetColor(opts.Color)
+	width, height := opts.Size()
+	model.SetSize(width, height)
+
+	msgs := make(chan Msg, 64)
+	inputErr := make(chan error, 1)
+	go readKeys(ctx, opts.In, msgs, inputErr)
+
+	run := func(cmds []Cmd) {
+		for _, c := range cmds {
+			if c == nil {
+				continue
+			}
+			go func() {
+				if msg := c(ctx); msg != nil {
+					select {
+					case msgs <- msg:
+					case <-ctx.Done():
+					}
+				}
+			}()
+		}
+	}
+
+	out := bufio.NewWriter(opts.Out)
+	_, _ = out.WriteString(enterScreen)
+	defer func() {
+		_, _ = out.WriteString(leaveScreen)
+		_ = out.Flush()
+	}()
+	draw := func() error {
+		_, _ = out.WriteString(cursorHome)
+		for i, line := range model.View() {
+			if i > 0 {
+				_, _ = out.WriteString("\r\n")
+			}
+			_, _ = out.WriteString(line)
+			_, _ = out.WriteString(clearLine)
+		}
+		return out.Flush()
+	}
+
+	run(model.Init())
+	ticker := time.NewTicker(sizePollInterval)
+	defer ticker.Stop()
+	dirty, lastDraw := true, time.Time{}
+	for {
+		if dirty {
+			if err := draw(); err != nil {
+				return err
+			}
+			dirty, lastDraw = false, time.Now()
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-inputErr:
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		case msg := <-msgs:
+			run(model.Update(msg))
+			// Apply whatever else is ready before redrawing, so pasted
+			// text or a burst of events costs one frame.
+			for drained := false; !drained && !model.Quit(); {
+				select {
+				case msg := <-msgs:
+					run(model.Update(msg))
+				default:
+					drained = true
+				}
+			}
+			dirty = true
+		case <-ticker.C:
+			if w, h := opts.Size(); w != width || h != height {
+				width, height = w, h
+				model.SetSize(w, h)
+				_, _ = out.WriteString("\x1b[2J")
+				dirty = true
+			} else if time.Since(lastDraw) >= time.Minute {
+				dirty = true // refresh conversation ages
+			}
+		}
+		if model.Quit() {
+			return nil
+		}
+	}
+}
+
+// readKeys decodes key presses from in until it fails or ctx is done.
+func readKeys(ctx context.Context, in io.Reader, msgs chan<- Msg, errs chan<- error) {
+	buf := make([]byte, 4096)
+	var pending []byte
+	for {
+		n, err := in.Read(buf)
+		if n > 0 {
+			var keys []Key
+			keys, pending = ParseKeys(append(pending, buf[:n]...))
+			pending = append([]byte(nil), pending...)
+			for _, k := range keys {
+				select {
+				case msgs <- k:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+		if err != nil {
+			errs <- err
+			return
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package tui
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func waitFor(t *testing.T, out *syncBuffer, want string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(out.String(), want) {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %q in output:\n%s", want, out.String())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestRunDrawsAndQuits(t *testing.T) {
+	b := newFakeBackend()
+	b.live = make(chan LiveEvent, 1)
+	in, keys := io.Pipe()
+	out := &syncBuffer{}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- Run(context.Background(), b, Options{
+			In:     in,
+			Out:    out,
+			Size:   func() (int, int) { return 100, 20 },
+			Status: "open",
+		})
+	}()
+
+	waitFor(t, out, "我想申請退款")
+	b.live <- LiveEvent{Status: "live"}
+	waitFor(t, out, "● live")
+	_, _ = keys.Write([]byte("j"))
+	waitFor(t, out, "Where is my order?")
+	_, _ = keys.Write([]byte("q"))
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Run returned %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not quit")
+	}
+	if s := out.String(); !strings.HasPrefix(s, enterScreen) || !strings.HasSuffix(s, leaveScreen) {
+		t.Fatalf("screen not entered and restored: %q...%q", s[:min(len(s), 20)], s[max(0, len(s)-20):])
+	}
+}
@@ -0,0 +1,42 @@
+package tui
+
+import (
+	"context"
+	"time"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+)
+
+// Backend is everything the TUI reads from and writes to Chatwoot. The
+// command layer implements it with the API client, so the interface stays
+// free of flags and output formats.
+type Backend interface {
+	// Conversations lists conversations with the given status ("all" for
+	// every status), most recently active first.
+	Conversations(ctx context.Context, status string) ([]api.Conversation, error)
+	Messages(ctx context.Context, conversationID int) ([]api.Message, error)
+	CannedResponses(ctx context.Context) ([]api.CannedResponse, error)
+
+	// Send posts a reply, or a private note when private is set.
+	Send(ctx context.Context, conversationID int, content string, private bool) (*api.Message, error)
+	// Assign assigns the conversation to an agent given by ID, email or
+	// name, and returns the agent ID.
+	Assign(ctx context.Context, conversationID int, agent string) (int, error)
+	AddLabels(ctx context.Context, conversationID int, labels []string) ([]string, error)
+	// Snooze snoozes the conversation for a duration such as "2h".
+	Snooze(ctx context.Context, conversationID int, duration string) (time.Time, error)
+	SetStatus(ctx context.Context, conversationID int, status string) error
+
+	// Live streams account events until ctx is done. The channel is
+	// closed when streaming stops for good.
+	Live(ctx context.Context) <-chan LiveEvent
+}
+
+// LiveEvent is one update from the real-time stream. Exactly one field is
+// set.
+type LiveEvent struct {
+	Message      *api.Message
+	Conversation *api.Conversation
+	// Status describes the connection, e.g. "live" or "reconnecting".
+	Status string
+}
@@ -0,0 +1,119 @@
+package tui
+
+import "unicode/utf8"
+
+// KeyType identifies a key press.
+type KeyType int
+
+// Key types. KeyRune carries the typed character in Key.Rune and KeyCtrl
+// carries the letter held with Ctrl.
+const (
+	KeyRune KeyType = iota
+	KeyCtrl
+	KeyEnter
+	KeyNewline // Ctrl-J or Alt-Enter
+	KeyBackspace
+	KeyDelete
+	KeyTab
+	KeyEsc
+	KeyUp
+	KeyDown
+	KeyLeft
+	KeyRight
+	KeyHome
+	KeyEnd
+	KeyPgUp
+	KeyPgDn
+)
+
+// Key is one key press read from the terminal.
+type Key struct {
+	Type KeyType
+	Rune rune
+}
+
+// csiKeys maps the final byte of "ESC [ x" and "ESC O x" sequences.
+var csiKeys = map[byte]KeyType{
+	'A': KeyUp, 'B': KeyDown, 'C': KeyRight, 'D': KeyLeft, 'H': KeyHome, 'F': KeyEnd,
+}
+
+// tildeKeys maps "ESC [ n ~" sequences.
+var tildeKeys = map[string]KeyType{
+	"1": KeyHome, "7": KeyHome, "4": KeyEnd, "8": KeyEnd,
+	"3": KeyDelete, "5": KeyPgUp, "6": KeyPgDn,
+}
+
+// ParseKeys decodes raw terminal input. Bytes that may start an incomplete
+// UTF-8 character are returned as rest, to be prefixed to the next read.
+// Multi-byte input, such as Chinese typed through an IME, becomes one
+// KeyRune per character.
+func ParseKeys(buf []byte) (keys []Key, rest []byte) {
+	for len(buf) > 0 {
+		b := buf[0]
+		switch {
+		case b == 0x1b:
+			k, n, ok := parseEscape(buf)
+			if ok {
+				keys = append(keys, k)
+			}
+			buf = buf[n:]
+			continue
+		case b == '\r':
+			keys = append(keys, Key{Type: KeyEnter})
+		case b == '\n':
+			keys = append(keys, Key{Type: KeyNewline})
+		case b == '\t':
+			keys = append(keys, Key{Type: KeyTab})
+		case b == 0x7f || b == 0x08:
+			keys = append(keys, Key{Type: KeyBackspace})
+		case b < 0x20:
+			keys = append(keys, Key{Type: KeyCtrl, Rune: rune('a' + b - 1)})
+		case b < utf8.RuneSelf:
+			keys = append(keys, Key{Type: KeyRune, Rune: rune(b)})
+		default:
+			if !utf8.FullRune(buf) {
+				return keys, buf
+			}
+			r, n := utf8.DecodeRune(buf)
+			if r != utf8.RuneError {
+				keys = append(keys, Key{Type: KeyRune, Rune: r})
+			}
+			buf = buf[n:]
+			continue
+		}
+		buf = buf[1:]
+	}
+	return keys, nil
+}
+
+// parseEscape decodes a sequence starting with ESC and returns the key, the
+// bytes consumed, and whether the sequence was a key we know.
+func parseEscape(buf []byte) (Key, int, bool) {
+	if len(buf) == 1 {
+		return Key{Type: KeyEsc}, 1, true
+	}
+	switch buf[1] {
+	case '\r':
+		return Key{Type: KeyNewline}, 2, true
+	case '[', 'O':
+		if len(buf) < 3 {
+			return Key{Type: KeyEsc}, 1, true
+		}
+		if k, ok := csiKeys[buf[2]]; ok {
+			return Key{Type: k}, 3, true
+		}
+		// Parameters run until a final byte in 0x40-0x7E.
+		for i := 2; i < len(buf); i++ {
+			if buf[i] >= 0x40 && buf[i] <= 0x7e {
+				if buf[i] == '~' {
+					if k, ok := tildeKeys[string(buf[2:i])]; ok {
+						return Key{Type: k}, i + 1, true
+					}
+				}
+				return Key{}, i + 1, false
+			}
+		}
+		return Key{}, len(buf), false
+	}
+	return Key{Type: KeyEsc}, 1, true
+}
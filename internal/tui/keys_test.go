@@ -0,0 +1,36 @@
+package tui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseKeys(t *testing.T) {
+	keys, rest := ParseKeys([]byte("j\x1b[B\r\x1b[5~\x03\x7f\x1b\x1b[1;5C退"))
+	want := []Key{
+		{Type: KeyRune, Rune: 'j'},
+		{Type: KeyDown},
+		{Type: KeyEnter},
+		{Type: KeyPgUp},
+		{Type: KeyCtrl, Rune: 'c'},
+		{Type: KeyBackspace},
+		{Type: KeyEsc},
+		// ESC [1;5C (Ctrl-Right) is unknown and skipped.
+		{Type: KeyRune, Rune: '退'},
+	}
+	if !reflect.DeepEqual(keys, want) || len(rest) != 0 {
+		t.Fatalf("ParseKeys = %+v, rest %q", keys, rest)
+	}
+}
+
+func TestParseKeysSplitRune(t *testing.T) {
+	b := []byte("a款")
+	keys, rest := ParseKeys(b[:2])
+	if !reflect.DeepEqual(keys, []Key{{Type: KeyRune, Rune: 'a'}}) || len(rest) != 1 {
+		t.Fatalf("first half = %+v, rest %q", keys, rest)
+	}
+	keys, rest = ParseKeys(append(rest, b[2:]...))
+	if !reflect.DeepEqual(keys, []Key{{Type: KeyRune, Rune: '款'}}) || len(rest) != 0 {
+		t.Fatalf("second half = %+v, rest %q", keys, rest)
+	}
+}
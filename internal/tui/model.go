@@ -0,0 +1,684 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+)
+
+// Msg is an input to Model.Update: a Key, a LiveEvent, or the result of a
+// Cmd.
+type Msg any
+
+// Cmd is work the model asks the app to run off the UI loop. Its result is
+// fed back to Update.
+type Cmd func(ctx context.Context) Msg
+
+type mode int
+
+const (
+	modeNormal mode = iota
+	modeCompose
+	modePrompt
+	modeHelp
+)
+
+type promptKind int
+
+const (
+	promptAssign promptKind = iota
+	promptLabel
+	promptSnooze
+)
+
+var promptLabels = map[promptKind]string{
+	promptAssign: "Assign to (agent name, email or ID)",
+	promptLabel:  "Add labels (comma-separated)",
+	promptSnooze: "Snooze for (e.g. 30m, 2h, 24h)",
+}
+
+// statusFilters is the order the f key cycles through.
+var statusFilters = []string{"open", "pending", "snoozed", "resolved", "all"}
+
+type conversationsMsg struct {
+	items []api.Conversation
+	err   error
+}
+
+type messagesMsg struct {
+	conversationID int
+	items          []api.Message
+	err            error
+}
+
+type cannedMsg struct {
+	items []api.CannedResponse
+	err   error
+}
+
+type sentMsg struct {
+	conversationID int
+	message        *api.Message
+	private        bool
+	err            error
+}
+
+type actionMsg struct {
+	notice string
+	err    error
+}
+
+type liveClosedMsg struct{}
+
+// Model is the TUI state. Update changes it in response to keys, live
+// events and finished commands; View renders it.
+type Model struct {
+	backend Backend
+	live    <-chan LiveEvent
+	color   bool
+	now     func() time.Time
+
+	width, height int
+
+	status        string
+	conversations []api.Conversation
+	selected      int
+	listTop       int
+	unread        map[int]bool
+	loading       bool
+
+	messages       []api.Message
+	messagesFor    int
+	scroll         int // message lines scrolled up from the bottom
+	loadingMessage bool
+
+	mode    mode
+	prompt  promptKind
+	input   []rune
+	cursor  int
+	private bool
+	sending bool
+
+	canned []api.CannedResponse
+
+	notice    string
+	noticeErr bool
+	liveState string
+	quit      bool
+}
+
+// NewModel returns a model listing conversations with the given status.
+func NewModel(backend Backend, live <-chan LiveEvent, status string) *Model {
+	if status == "" {
+		status = "open"
+	}
+	return &Model{
+		backend:   backend,
+		live:      live,
+		now:       time.Now,
+		width:     80,
+		height:    24,
+		status:    status,
+		unread:    map[int]bool{},
+		liveState: "connecting",
+	}
+}
+
+// Init returns the commands that load the first screen.
+func (m *Model) Init() []Cmd {
+	m.loading = true
+	cmds := []Cmd{m.loadConversations(), m.loadCanned()}
+	if m.live != nil {
+		cmds = append(cmds, m.listen())
+	}
+	return cmds
+}
+
+// Quit reports whether the user asked to leave.
+func (m *Model) Quit() bool { return m.quit }
+
+// SetSize sets the terminal size in cells.
+func (m *Model) SetSize(width, height int) {
+	m.width, m.height = width, height
+}
+
+func (m *Model) selectedConversation() *api.Conversation {
+	if m.selected < 0 || m.selected >= len(m.conversations) {
+		return nil
+	}
+	return &m.conversations[m.selected]
+}
+
+func (m *Model) setNotice(format string, args ...any) {
+	m.notice, m.noticeErr = fmt.Sprintf(format, args...), false
+}
+
+func (m *Model) setError(err error) {
+	m.notice, m.noticeErr = err.Error(), true
+}
+
+// Update applies msg and returns follow-up commands.
+func (m *Model) Update(msg Msg) []Cmd {
+	switch msg := msg.(type) {
+	case Key:
+		return m.handleKey(msg)
+	case LiveEvent:
+		return append(m.applyLive(msg), m.listen())
+	case liveClosedMsg:
+		m.liveState = "offline"
+	case conversationsMsg:
+		m.loading = false
+		if msg.err != nil {
+			m.setError(fmt.Errorf("load conversations: %w", msg.err))
+			return nil
+		}
+		return m.setConversations(msg.items)
+	case messagesMsg:
+		if msg.conversationID != m.currentID() {
+			return nil // the user moved on
+		}
+		m.loadingMessage = false
+		if msg.err != nil {
+			m.setError(fmt.Errorf("load messages: %w", msg.err))
+			return nil
+		}
+		m.messages, m.messagesFor, m.scroll = sortMessages(msg.items), msg.conversationID, 0
+		delete(m.unread, msg.conversationID)
+	case cannedMsg:
+		if msg.err != nil {
+			m.setError(fmt.Errorf("load canned responses: %w", msg.err))
+			return nil
+		}
+		m.canned = msg.items
+		if m.canned == nil {
+			m.canned = []api.CannedResponse{}
+		}
+	case sentMsg:
+		m.sending = false
+		if msg.err != nil {
+			m.setError(fmt.Errorf("send: %w", msg.err))
+			return nil // keep the draft for another try
+		}
+		m.mode, m.input, m.cursor = modeNormal, nil, 0
+		if msg.message != nil && msg.conversationID == m.messagesFor {
+			m.upsertMessage(*msg.message)
+		}
+		if msg.private {
+			m.setNotice("Added private note to #%d", msg.conversationID)
+		} else {
+			m.setNotice("Sent reply to #%d", msg.conversationID)
+		}
+	case actionMsg:
+		if msg.err != nil {
+			m.setError(msg.err)
+			return nil
+		}
+		m.notice, m.noticeErr = msg.notice, false
+		return []Cmd{m.loadConversations()}
+	}
+	return nil
+}
+
+func (m *Model) currentID() int {
+	if c := m.selectedConversation(); c != nil {
+		return c.ID
+	}
+	return 0
+}
+
+// setConversations replaces the list, keeping the selection on the same
+// conversation when it is still there.
+func (m *Model) setConversations(items []api.Conversation) []Cmd {
+	prev := m.currentID()
+	m.conversations = items
+	m.selected = 0
+	for i, c := range items {
+		if c.ID == prev {
+			m.selected = i
+		}
+	}
+	return m.selectionChanged()
+}
+
+// selectionChanged loads messages when the selected conversation differs
+// from the one on screen.
+func (m *Model) selectionChanged() []Cmd {
+	id := m.currentID()
+	if id == 0 {
+		m.messages, m.messagesFor = nil, 0
+		return nil
+	}
+	if id == m.messagesFor {
+		return nil
+	}
+	m.loadingMessage = true
+	return []Cmd{m.loadMessages(id)}
+}
+
+func (m *Model) handleKey(k Key) []Cmd {
+	if k.Type == KeyCtrl && k.Rune == 'c' {
+		m.quit = true
+		return nil
+	}
+	switch m.mode {
+	case modeCompose, modePrompt:
+		return m.handleInputKey(k)
+	case modeHelp:
+		m.mode = modeNormal
+		return nil
+	}
+
+	m.notice = ""
+	switch k.Type {
+	case KeyDown:
+		return m.move(1)
+	case KeyUp:
+		return m.move(-1)
+	case KeyHome:
+		return m.move(-len(m.conversations))
+	case KeyEnd:
+		return m.move(len(m.conversations))
+	case KeyPgUp:
+		m.scrollMessages(1)
+	case KeyPgDn:
+		m.scrollMessages(-1)
+	case KeyEnter:
+		return m.handleCommandKey('r')
+	case KeyCtrl:
+		switch k.Rune {
+		case 'u':
+			m.scrollMessages(1)
+		case 'd':
+			m.scrollMessages(-1)
+		case 'r':
+			return m.handleCommandKey('R')
+		}
+	case KeyRune:
+		return m.handleCommandKey(k.Rune)
+	}
+	return nil
+}
+
+// scrollMessages moves the message pane half a screen back (dir 1) or
+// forward (dir -1).
+func (m *Model) scrollMessages(dir int) {
+	m.scroll = max(0, m.scroll+dir*max(1, m.bodyHeight()/2))
+}
+
+func (m *Model) handleCommandKey(r rune) []Cmd {
+	switch r {
+	case 'j':
+		return m.move(1)
+	case 'k':
+		return m.move(-1)
+	case 'g':
+		return m.move(-len(m.conversations))
+	case 'G':
+		return m.move(len(m.conversations))
+	case 'R':
+		m.loading = true
+		m.messagesFor = 0
+		return []Cmd{m.loadConversations()}
+	case 'q':
+		m.quit = true
+		return nil
+	case '?':
+		m.mode = modeHelp
+		return nil
+	case 'f':
+		for i, s := range statusFilters {
+			if s == m.status {
+				m.status = statusFilters[(i+1)%len(statusFilters)]
+				break
+			}
+		}
+		m.loading = true
+		return []Cmd{m.loadConversations()}
+	}
+
+	conv := m.selectedConversation()
+	if conv == nil {
+		return nil
+	}
+	switch r {
+	case 'r', 'p':
+		m.mode, m.private, m.input, m.cursor = modeCompose, r == 'p', nil, 0
+	case 'a':
+		m.startPrompt(promptAssign)
+	case 'l':
+		m.startPrompt(promptLabel)
+	case 's':
+		m.startPrompt(promptSnooze)
+	case 'x':
+		id, status := conv.ID, "resolved"
+		if conv.Status == "resolved" {
+			status = "open"
+		}
+		return []Cmd{func(ctx context.Context) Msg {
+			if err := m.backend.SetStatus(ctx, id, status); err != nil {
+				return actionMsg{err: fmt.Errorf("set status of #%d: %w", id, err)}
+			}
+			if status == "open" {
+				return actionMsg{notice: fmt.Sprintf("Reopened #%d", id)}
+			}
+			return actionMsg{notice: fmt.Sprintf("Resolved #%d", id)}
+		}}
+	}
+	return nil
+}
+
+func (m *Model) startPrompt(kind promptKind) {
+	m.mode, m.prompt, m.input, m.cursor = modePrompt, kind, nil, 0
+}
+
+func (m *Model) move(delta int) []Cmd {
+	if len(m.conversations) == 0 {
+		return nil
+	}
+	m.selected = min(max(m.selected+delta, 0), len(m.conversations)-1)
+	m.scroll = 0
+	return m.selectionChanged()
+}
+
+// handleInputKey edits the compose box or prompt line.
+func (m *Model) handleInputKey(k Key) []Cmd {
+	switch k.Type {
+	case KeyEsc:
+		m.mode, m.input, m.cursor = modeNormal, nil, 0
+		m.notice = ""
+	case KeyEnter:
+		return m.submit()
+	case KeyNewline:
+		if m.mode == modeCompose {
+			m.insert('\n')
+		}
+	case KeyTab:
+		if m.mode == modeCompose {
+			m.expandCanned()
+		}
+	case KeyBackspace:
+		if m.cursor > 0 {
+			m.input = append(m.input[:m.cursor-1], m.input[m.cursor:]...)
+			m.cursor--
+		}
+	case KeyDelete:
+		if m.cursor < len(m.input) {
+			m.input = append(m.input[:m.cursor], m.input[m.cursor+1:]...)
+		}
+	case KeyLeft:
+		m.cursor = max(0, m.cursor-1)
+	case KeyRight:
+		m.cursor = min(len(m.input), m.cursor+1)
+	case KeyHome:
+		m.cursor = 0
+	case KeyEnd:
+		m.cursor = len(m.input)
+	case KeyCtrl:
+		switch k.Rune {
+		case 'p':
+			if m.mode == modeCompose {
+				m.private = !m.private
+			}
+		case 'u':
+			m.input, m.cursor = m.input[m.cursor:], 0
+		case 'a':
+			m.cursor = 0
+		case 'e':
+			m.cursor = len(m.input)
+		}
+	case KeyRune:
+		m.insert(k.Rune)
+	}
+	return nil
+}
+
+func (m *Model) insert(r rune) {
+	m.input = append(m.input[:m.cursor], append([]rune{r}, m.input[m.cursor:]...)...)
+	m.cursor++
+}
+
+// submit sends the compose box or runs the prompt's action.
+func (m *Model) submit() []Cmd {
+	conv := m.selectedConversation()
+	text := strings.TrimSpace(string(m.input))
+	if conv == nil || text == "" || m.sending {
+		return nil
+	}
+	id := conv.ID
+
+	if m.mode == modeCompose {
+		m.sending = true
+		private := m.private
+		return []Cmd{func(ctx context.Context) Msg {
+			msg, err := m.backend.Send(ctx, id, text, private)
+			return sentMsg{conversationID: id, message: msg, private: private, err: err}
+		}}
+	}
+
+	kind := m.prompt
+	m.mode, m.input, m.cursor = modeNormal, nil, 0
+	switch kind {
+	case promptAssign:
+		return []Cmd{func(ctx context.Context) Msg {
+			agentID, err := m.backend.Assign(ctx, id, text)
+			if err != nil {
+				return actionMsg{err: fmt.Errorf("assign #%d: %w", id, err)}
+			}
+			return actionMsg{notice: fmt.Sprintf("Assigned #%d to agent %d", id, agentID)}
+		}}
+	case promptLabel:
+		var labels []string
+		for _, l := range strings.Split(text, ",") {
+			if l = strings.TrimSpace(l); l != "" {
+				labels = append(labels, l)
+			}
+		}
+		return []Cmd{func(ctx context.Context) Msg {
+			all, err := m.backend.AddLabels(ctx, id, labels)
+			if err != nil {
+				return actionMsg{err: fmt.Errorf("label #%d: %w", id, err)}
+			}
+			return actionMsg{notice: fmt.Sprintf("Labels on #%d: %s", id, strings.Join(all, ", "))}
+		}}
+	case promptSnooze:
+		return []Cmd{func(ctx context.Context) Msg {
+			until, err := m.backend.Snooze(ctx, id, text)
+			if err != nil {
+				return actionMsg{err: fmt.Errorf("snooze #%d: %w", id, err)}
+			}
+			return actionMsg{notice: fmt.Sprintf("Snoozed #%d until %s", id, until.Format("Jan 2 15:04"))}
+		}}
+	}
+	return nil
+}
+
+// expandCanned replaces "/short_code" before the cursor with the canned
+// response's content. A unique prefix is enough. The slash need not follow
+// a space, since Chinese text has none between words.
+func (m *Model) expandCanned() {
+	start := m.cursor
+	for start > 0 && m.input[start-1] != '/' && !unicode.IsSpace(m.input[start-1]) {
+		start--
+	}
+	if start > 0 && m.input[start-1] == '/' {
+		start--
+	}
+	word := string(m.input[start:m.cursor])
+	if !strings.HasPrefix(word, "/") || len(word) < 2 {
+		m.setNotice("Type /short_code then Tab to insert a canned response")
+		return
+	}
+	if m.canned == nil {
+		m.setNotice("Canned responses are still loading")
+		return
+	}
+	code := strings.ToLower(word[1:])
+	var matches []api.CannedResponse
+	for _, c := range m.canned {
+		sc := strings.ToLower(c.ShortCode)
+		if sc == code {
+			matches = []api.CannedResponse{c}
+			break
+		}
+		if strings.HasPrefix(sc, code) {
+			matches = append(matches, c)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		m.setError(fmt.Errorf("no canned response %s", word))
+	case 1:
+		content := []rune(matches[0].Content)
+		rest := append([]rune(nil), m.input[m.cursor:]...)
+		m.input = append(append(m.input[:start], content...), rest...)
+		m.cursor = start + len(content)
+		m.setNotice("Inserted /%s", matches[0].ShortCode)
+	default:
+		codes := make([]string, 0, len(matches))
+		for _, c := range matches {
+			codes = append(codes, "/"+c.ShortCode)
+		}
+		sort.Strings(codes)
+		m.setNotice("Matches: %s", strings.Join(codes, " "))
+	}
+}
+
+// applyLive folds a real-time event into the list and message pane.
+func (m *Model) applyLive(ev LiveEvent) []Cmd {
+	switch {
+	case ev.Status != "":
+		m.liveState = ev.Status
+	case ev.Message != nil:
+		msg := *ev.Message
+		if msg.ConversationID == m.messagesFor {
+			m.upsertMessage(msg)
+		}
+		for i := range m.conversations {
+			c := &m.conversations[i]
+			if c.ID != msg.ConversationID {
+				continue
+			}
+			c.LastActivityAt = max(c.LastActivityAt, msg.CreatedAt)
+			if msg.MessageType != api.MessageTypeActivity && !msg.Private {
+				c.LastNonActivityMessage = &api.LastNonActivityMessage{Content: msg.Content}
+			}
+			if c.ID != m.currentID() && msg.MessageType == api.MessageTypeIncoming {
+				m.unread[c.ID] = true
+			}
+		}
+		m.sortConversations()
+	case ev.Conversation != nil:
+		conv := *ev.Conversation
+		keep := m.status == "all" || conv.Status == m.status
+		for i := range m.conversations {
+			if m.conversations[i].ID != conv.ID {
+				continue
+			}
+			if conv.Meta == nil {
+				conv.Meta = m.conversations[i].Meta
+			}
+			if conv.LastNonActivityMessage == nil {
+				conv.LastNonActivityMessage = m.conversations[i].LastNonActivityMessage
+			}
+			if keep {
+				m.conversations[i] = conv
+			} else {
+				m.removeConversation(i)
+			}
+			m.sortConversations()
+			return m.selectionChanged()
+		}
+		if keep {
+			m.conversations = append(m.conversations, conv)
+			m.sortConversations()
+			return m.selectionChanged()
+		}
+	}
+	return nil
+}
+
+func (m *Model) removeConversation(i int) {
+	m.conversations = append(m.conversations[:i], m.conversations[i+1:]...)
+	if i < m.selected || m.selected >= len(m.conversations) {
+		m.selected = max(0, m.selected-1)
+	}
+}
+
+// sortConversations orders by last activity, newest first, keeping the
+// selection on the same conversation.
+func (m *Model) sortConversations() {
+	id := m.currentID()
+	sort.SliceStable(m.conversations, func(i, j int) bool {
+		return m.conversations[i].LastActivityAt > m.conversations[j].LastActivityAt
+	})
+	for i, c := range m.conversations {
+		if c.ID == id {
+			m.selected = i
+		}
+	}
+}
+
+func (m *Model) upsertMessage(msg api.Message) {
+	for i := range m.messages {
+		if m.messages[i].ID == msg.ID {
+			m.messages[i] = msg
+			return
+		}
+	}
+	m.messages = sortMessages(append(m.messages, msg))
+}
+
+func sortMessages(msgs []api.Message) []api.Message {
+	sort.SliceStable(msgs, func(i, j int) bool {
+		if msgs[i].CreatedAt != msgs[j].CreatedAt {
+			return msgs[i].CreatedAt < msgs[j].CreatedAt
+		}
+		return msgs[i].ID < msgs[j].ID
+	})
+	return msgs
+}
+
+func (m *Model) loadConversations() Cmd {
+	status := m.status
+	return func(ctx context.Context) Msg {
+		items, err := m.backend.Conversations(ctx, status)
+		return conversationsMsg{items: items, err: err}
+	}
+}
+
+func (m *Model) loadMessages(id int) Cmd {
+	return func(ctx context.Context) Msg {
+		items, err := m.backend.Messages(ctx, id)
+		return messagesMsg{conversationID: id, items: items, err: err}
+	}
+}
+
+func (m *Model) loadCanned() Cmd {
+	return func(ctx context.Context) Msg {
+		items, err := m.backend.CannedResponses(ctx)
+		return cannedMsg{items: items, err: err}
+	}
+}
+
+func (m *Model) listen() Cmd {
+	ch := m.live
+	if ch == nil {
+		return nil
+	}
+	return func(ctx context.Context) Msg {
+		select {
+		case ev, ok := <-ch:
+			if !ok {
+				return liveClosedMsg{}
+			}
+			return ev
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
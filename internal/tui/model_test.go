@@ -0,0 +1,267 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+)
+
+type fakeBackend struct {
+	mu            sync.Mutex
+	conversations []api.Conversation
+	messages      map[int][]api.Message
+	canned        []api.CannedResponse
+	live          chan LiveEvent
+	calls         []string
+	sendErr       error
+}
+
+func (f *fakeBackend) record(call string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, call)
+}
+
+func (f *fakeBackend) Calls() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.calls...)
+}
+
+func (f *fakeBackend) Conversations(_ context.Context, status string) ([]api.Conversation, error) {
+	f.record("list " + status)
+	return append([]api.Conversation(nil), f.conversations...), nil
+}
+
+func (f *fakeBackend) Messages(_ context.Context, id int) ([]api.Message, error) {
+	return append([]api.Message(nil), f.messages[id]...), nil
+}
+
+func (f *fakeBackend) CannedResponses(context.Context) ([]api.CannedResponse, error) {
+	return f.canned, nil
+}
+
+func (f *fakeBackend) Send(_ context.Context, id int, content string, private bool) (*api.Message, error) {
+	if f.sendErr != nil {
+		return nil, f.sendErr
+	}
+	kind := "reply"
+	if private {
+		kind = "note"
+	}
+	f.record(kind + " " + content)
+	return &api.Message{ID: 99, ConversationID: id, Content: content, Private: private, MessageType: api.MessageTypeOutgoing, CreatedAt: 1700000500}, nil
+}
+
+func (f *fakeBackend) Assign(_ context.Context, id int, agent string) (int, error) {
+	f.record("assign " + agent)
+	return 7, nil
+}
+
+func (f *fakeBackend) AddLabels(_ context.Context, id int, labels []string) ([]string, error) {
+	f.record("labels " + strings.Join(labels, ","))
+	return labels, nil
+}
+
+func (f *fakeBackend) Snooze(_ context.Context, id int, duration string) (time.Time, error) {
+	f.record("snooze " + duration)
+	return time.Unix(1700007200, 0), nil
+}
+
+func (f *fakeBackend) SetStatus(_ context.Context, id int, status string) error {
+	f.record("status " + status)
+	return nil
+}
+
+func (f *fakeBackend) Live(context.Context) <-chan LiveEvent { return f.live }
+
+func newFakeBackend() *fakeBackend {
+	return &fakeBackend{
+		conversations: []api.Conversation{
+			{ID: 1, Status: "open", LastActivityAt: 1700000400, Meta: map[string]any{"sender": map[string]any{"name": "王小明"}},
+				LastNonActivityMessage: &api.LastNonActivityMessage{Content: "我想申請退款"}},
+			{ID: 2, Status: "open", LastActivityAt: 1700000000, Meta: map[string]any{"sender": map[string]any{"name": "Alice"}}},
+		},
+		messages: map[int][]api.Message{
+			1: {{ID: 10, ConversationID: 1, Content: "我想申請退款", MessageType: api.MessageTypeIncoming, CreatedAt: 1700000400, Sender: &api.MessageSender{Name: "王小明"}}},
+			2: {{ID: 20, ConversationID: 2, Content: "Where is my order?", MessageType: api.MessageTypeIncoming, CreatedAt: 1700000000}},
+		},
+		canned: []api.CannedResponse{
+			{ShortCode: "refund", Content: "退款將在 5 個工作天內處理。"},
+			{ShortCode: "thanks", Content: "Thanks for reaching out!"},
+			{ShortCode: "thanks_zh", Content: "謝謝您的來信！"},
+		},
+	}
+}
+
+// runCmds runs cmds synchronously, feeding results back until none remain.
+func runCmds(t *testing.T, m *Model, cmds []Cmd) {
+	t.Helper()
+	for len(cmds) > 0 {
+		c := cmds[0]
+		cmds = cmds[1:]
+		if c == nil {
+			continue
+		}
+		if msg := c(context.Background()); msg != nil {
+			cmds = append(cmds, m.Update(msg)...)
+		}
+	}
+}
+
+func typeText(t *testing.T, m *Model, s string) {
+	t.Helper()
+	for _, r := range s {
+		runCmds(t, m, m.Update(Key{Type: KeyRune, Rune: r}))
+	}
+}
+
+func newTestModel(t *testing.T, b *fakeBackend) *Model {
+	t.Helper()
+	m := NewModel(b, nil, "open")
+	m.now = func() time.Time { return time.Unix(1700000600, 0) }
+	m.SetSize(100, 20)
+	runCmds(t, m, m.Init())
+	return m
+}
+
+func screen(m *Model) string {
+	return strings.Join(m.View(), "\n")
+}
+
+func TestModelLoadsAndRendersCJK(t *testing.T) {
+	m := newTestModel(t, newFakeBackend())
+	lines := m.View()
+	if len(lines) != 20 {
+		t.Fatalf("View returned %d lines", len(lines))
+	}
+	for i, l := range lines {
+		plain := strings.NewReplacer(styleReverse, "", styleDim, "", styleBold, "", styleReset, "").Replace(l)
+		if w := StringWidth(plain); w != 100 {
+			t.Fatalf("line %d is %d cells wide: %q", i, w, plain)
+		}
+	}
+	out := screen(m)
+	for _, want := range []string{"#1 王小明", "3m", "我想申請退款", "#1 王小明 · open · unassigned"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("screen missing %q:\n%s", want, out)
+		}
+	}
+
+	runCmds(t, m, m.Update(Key{Type: KeyRune, Rune: 'j'}))
+	if !strings.Contains(screen(m), "Where is my order?") {
+		t.Fatalf("messages for #2 not shown:\n%s", screen(m))
+	}
+}
+
+func TestModelComposeExpandsCannedAndSends(t *testing.T) {
+	b := newFakeBackend()
+	m := newTestModel(t, b)
+
+	runCmds(t, m, m.Update(Key{Type: KeyRune, Rune: 'r'}))
+	typeText(t, m, "您好，/ref")
+	runCmds(t, m, m.Update(Key{Type: KeyTab}))
+	if got := string(m.input); got != "您好，退款將在 5 個工作天內處理。" {
+		t.Fatalf("expanded input = %q", got)
+	}
+
+	// An ambiguous prefix lists the matches and leaves the text alone.
+	typeText(t, m, " /th")
+	runCmds(t, m, m.Update(Key{Type: KeyTab}))
+	if !strings.Contains(m.notice, "/thanks /thanks_zh") || !strings.HasSuffix(string(m.input), "/th") {
+		t.Fatalf("notice = %q, input = %q", m.notice, string(m.input))
+	}
+	typeText(t, m, "anks_")
+	runCmds(t, m, m.Update(Key{Type: KeyTab}))
+	runCmds(t, m, m.Update(Key{Type: KeyEnter}))
+
+	calls := b.Calls()
+	if len(calls) != 2 || calls[1] != "reply 您好，退款將在 5 個工作天內處理。 謝謝您的來信！" {
+		t.Fatalf("calls = %q", calls)
+	}
+	if m.mode != modeNormal || !strings.Contains(screen(m), "Sent reply to #1") {
+		t.Fatalf("compose not closed:\n%s", screen(m))
+	}
+	if last := m.messages[len(m.messages)-1]; last.ID != 99 {
+		t.Fatalf("sent message not shown: %+v", last)
+	}
+}
+
+func TestModelKeepsDraftWhenSendFails(t *testing.T) {
+	b := newFakeBackend()
+	b.sendErr = errors.New("boom")
+	m := newTestModel(t, b)
+
+	runCmds(t, m, m.Update(Key{Type: KeyRune, Rune: 'p'}))
+	typeText(t, m, "check refund")
+	runCmds(t, m, m.Update(Key{Type: KeyEnter}))
+	if m.mode != modeCompose || string(m.input) != "check refund" || !m.noticeErr {
+		t.Fatalf("mode = %v, input = %q, notice = %q", m.mode, string(m.input), m.notice)
+	}
+}
+
+func TestModelActions(t *testing.T) {
+	b := newFakeBackend()
+	m := newTestModel(t, b)
+
+	steps := []struct {
+		key   rune
+		input string
+		call  string
+	}{
+		{'a', "alice@example.com", "assign alice@example.com"},
+		{'l', "billing, vip", "labels billing,vip"},
+		{'s', "2h", "snooze 2h"},
+		{'x', "", "status resolved"},
+	}
+	for _, step := range steps {
+		runCmds(t, m, m.Update(Key{Type: KeyRune, Rune: step.key}))
+		if step.input != "" {
+			if !strings.Contains(screen(m), promptLabels[m.prompt]) {
+				t.Fatalf("prompt not shown for %q:\n%s", step.key, screen(m))
+			}
+			typeText(t, m, step.input)
+			runCmds(t, m, m.Update(Key{Type: KeyEnter}))
+		}
+		calls := b.Calls()
+		// Each action reloads the list afterwards.
+		if n := len(calls); n < 2 || calls[n-2] != step.call || calls[n-1] != "list open" {
+			t.Fatalf("after %q calls = %q", step.key, calls)
+		}
+	}
+	if !strings.Contains(m.notice, "Resolved #1") {
+		t.Fatalf("notice = %q", m.notice)
+	}
+}
+
+func TestModelAppliesLiveEvents(t *testing.T) {
+	m := newTestModel(t, newFakeBackend())
+
+	// A new message on #2 marks it unread and moves it to the top.
+	runCmds(t, m, m.applyLive(LiveEvent{Message: &api.Message{ID: 21, ConversationID: 2, Content: "Any update?", MessageType: api.MessageTypeIncoming, CreatedAt: 1700000550}}))
+	if m.conversations[0].ID != 2 || !m.unread[2] || m.currentID() != 1 {
+		t.Fatalf("order = %d,%d unread = %v selected = %d", m.conversations[0].ID, m.conversations[1].ID, m.unread, m.currentID())
+	}
+
+	// A message on the open conversation appears in the pane.
+	runCmds(t, m, m.applyLive(LiveEvent{Message: &api.Message{ID: 11, ConversationID: 1, Content: "還在嗎？", MessageType: api.MessageTypeIncoming, CreatedAt: 1700000560}}))
+	if !strings.Contains(screen(m), "還在嗎？") {
+		t.Fatalf("live message not shown:\n%s", screen(m))
+	}
+
+	// Resolving #1 elsewhere drops it from the open list.
+	runCmds(t, m, m.applyLive(LiveEvent{Conversation: &api.Conversation{ID: 1, Status: "resolved"}}))
+	if len(m.conversations) != 1 || m.currentID() != 2 {
+		t.Fatalf("conversations = %+v", m.conversations)
+	}
+
+	runCmds(t, m, m.applyLive(LiveEvent{Status: "reconnecting"}))
+	if !strings.Contains(screen(m), "● reconnecting") {
+		t.Fatalf("live state not shown:\n%s", screen(m))
+	}
+}
@@ -0,0 +1,378 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chatwoot/chatwoot-cli/internal/api"
+)
+
+const (
+	styleReverse = "\x1b[7m"
+	styleBold    = "\x1b[1m"
+	styleDim     = "\x1b[2m"
+	styleRed     = "\x1b[31m"
+	styleGreen   = "\x1b[32m"
+	styleYellow  = "\x1b[33m"
+	styleReset   = "\x1b[0m"
+)
+
+// composeHeight is the border line plus visible input lines.
+const composeHeight = 4
+
+const normalHints = "j/k move  r reply  p note  Tab canned  a assign  l label  s snooze  x resolve  f filter  R refresh  ? help  q quit"
+
+var helpLines = []string{
+	"Keys",
+	"",
+	"  j, k, ↑, ↓       select conversation",
+	"  g, G             first / last conversation",
+	"  PgUp, PgDn       scroll messages (also Ctrl-U, Ctrl-D)",
+	"  r, Enter         reply",
+	"  p                private note",
+	"  a                assign to an agent",
+	"  l                add labels",
+	"  s                snooze",
+	"  x                resolve, or reopen a resolved conversation",
+	"  f                cycle status filter",
+	"  R, Ctrl-R        refresh",
+	"  q, Ctrl-C        quit",
+	"",
+	"Compose box",
+	"",
+	"  Enter            send",
+	"  Ctrl-J, Alt-Enter new line",
+	"  /code Tab        insert canned response (a unique prefix is enough)",
+	"  Ctrl-P           switch between reply and private note",
+	"  Esc              cancel",
+	"",
+	"Press any key to close.",
+}
+
+// SetColor turns ANSI colors on or off. Selection is always shown in
+// reverse video.
+func (m *Model) SetColor(on bool) { m.color = on }
+
+func (m *Model) style(s, code string) string {
+	if code != styleReverse && !m.color {
+		return s
+	}
+	return code + s + styleReset
+}
+
+func (m *Model) bodyHeight() int {
+	h := m.height - 2
+	if m.mode == modeCompose {
+		h -= composeHeight
+	}
+	return max(h, 1)
+}
+
+// View renders the screen as exactly height lines of width cells.
+func (m *Model) View() []string {
+	if m.width < 40 || m.height < 10 {
+		lines := make([]string, max(m.height, 1))
+		lines[0] = Pad("Terminal too small for cw tui", m.width)
+		return lines
+	}
+
+	lines := []string{m.header()}
+	bodyH := m.bodyHeight()
+	listW := min(max(m.width/3, 24), 48)
+	paneW := m.width - listW - 1
+	left := m.listLines(listW, bodyH)
+	right := m.paneLines(paneW, bodyH)
+	for i := range bodyH {
+		lines = append(lines, left[i]+m.style("│", styleDim)+right[i])
+	}
+	if m.mode == modeCompose {
+		lines = append(lines, m.composeLines()...)
+	}
+	return append(lines, m.statusLine())
+}
+
+func (m *Model) header() string {
+	title := fmt.Sprintf(" cw tui · %s · %d conversations", m.status, len(m.conversations))
+	if m.loading {
+		title += " · loading…"
+	}
+	state := "● " + m.liveState + " "
+	return m.style(Pad(title, m.width-StringWidth(state))+state, styleReverse)
+}
+
+func (m *Model) listLines(width, height int) []string {
+	lines := make([]string, 0, height)
+	visible := max(height/2, 1)
+	if m.selected < m.listTop {
+		m.listTop = m.selected
+	}
+	if m.selected >= m.listTop+visible {
+		m.listTop = m.selected - visible + 1
+	}
+	m.listTop = max(0, min(m.listTop, len(m.conversations)-visible))
+
+	if len(m.conversations) == 0 && !m.loading {
+		lines = append(lines, Pad(" No "+m.status+" conversations", width))
+	}
+	for i := m.listTop; i < len(m.conversations) && len(lines)+2 <= height; i++ {
+		c := m.conversations[i]
+		marker := " "
+		if m.unread[c.ID] || (c.Unread > 0 && c.ID != m.messagesFor) {
+			marker = "●"
+		}
+		age := formatAge(m.now().Sub(c.LastActivityAtTime()))
+		name := fmt.Sprintf("%s#%d %s", marker, c.ID, contactName(c))
+		first := Pad(name, width-StringWidth(age)-1) + " " + age
+		preview := ""
+		if c.LastNonActivityMessage != nil {
+			preview = strings.Join(strings.Fields(Sanitize(c.LastNonActivityMessage.Content)), " ")
+		}
+		second := Pad("  "+preview, width)
+		if i == m.selected {
+			lines = append(lines, m.style(first, styleReverse), m.style(second, styleReverse))
+		} else {
+			lines = append(lines, first, m.style(second, styleDim))
+		}
+	}
+	for len(lines) < height {
+		lines = append(lines, strings.Repeat(" ", width))
+	}
+	return lines
+}
+
+func (m *Model) paneLines(width, height int) []string {
+	var lines []string
+	conv := m.selectedConversation()
+	switch {
+	case m.mode == modeHelp:
+		for _, l := range helpLines {
+			lines = append(lines, " "+l)
+		}
+	case conv == nil:
+		lines = append(lines, "")
+	default:
+		lines = append(lines, m.style(Pad(" "+conversationTitle(*conv), width), styleBold), m.style(strings.Repeat("─", width), styleDim))
+		avail := height - len(lines)
+		var body []string
+		if m.loadingMessage && m.messagesFor != conv.ID {
+			body = []string{" Loading messages…"}
+		}
+		if m.messagesFor == conv.ID {
+			for _, msg := range m.messages {
+				body = append(body, m.messageLines(msg, width)...)
+			}
+		}
+		m.scroll = min(m.scroll, max(0, len(body)-avail))
+		end := len(body) - m.scroll
+		lines = append(lines, body[max(0, end-avail):end]...)
+	}
+
+	out := make([]string, 0, height)
+	for _, l := range lines {
+		if len(out) == height {
+			break
+		}
+		if strings.Contains(l, "\x1b[") {
+			out = append(out, l) // already padded and styled
+		} else {
+			out = append(out, Pad(l, width))
+		}
+	}
+	for len(out) < height {
+		out = append(out, strings.Repeat(" ", width))
+	}
+	return out
+}
+
+// messageLines wraps one message to the pane width.
+func (m *Model) messageLines(msg api.Message, width int) []string {
+	if msg.MessageType == api.MessageTypeActivity {
+		var out []string
+		for _, l := range Wrap(" · "+Sanitize(msg.Content), width) {
+			out = append(out, m.style(Pad(l, width), styleDim))
+		}
+		return out
+	}
+
+	name := "Contact"
+	if msg.MessageType == api.MessageTypeOutgoing || msg.MessageType == api.MessageTypeTemplate {
+		name = "Agent"
+	}
+	if msg.Sender != nil && msg.Sender.Name != "" {
+		name = Sanitize(msg.Sender.Name)
+	}
+	code := ""
+	switch {
+	case msg.Private:
+		name += " [note]"
+		code = styleYellow
+	case msg.MessageType == api.MessageTypeIncoming:
+		code = styleGreen
+	}
+	content := Sanitize(msg.Content)
+	if n := len(msg.Attachments); n > 0 {
+		content += fmt.Sprintf(" [%d attachment(s)]", n)
+	}
+
+	head := fmt.Sprintf(" %s %s: ", m.formatClock(msg.CreatedAtTime()), name)
+	var out []string
+	for i, l := range Wrap(head+content, width) {
+		l = Pad(l, width)
+		if i == 0 && code != "" {
+			// Color the name only; the message text stays plain.
+			if strings.HasPrefix(l, head) {
+				l = m.style(head, code) + l[len(head):]
+			} else {
+				l = m.style(l, code)
+			}
+		}
+		out = append(out, l)
+	}
+	return append(out, "")
+}
+
+func (m *Model) composeLines() []string {
+	conv := m.selectedConversation()
+	label := "Reply"
+	code := styleBold
+	if m.private {
+		label, code = "Private note", styleYellow
+	}
+	if conv != nil {
+		label = fmt.Sprintf("%s to #%d", label, conv.ID)
+	}
+	if m.sending {
+		label += " (sending…)"
+	}
+	border := "─ " + label + " ─ Enter send · Ctrl-J newline · /code Tab canned · Ctrl-P note · Esc cancel "
+	lines := []string{m.style(Pad(border, m.width), code)}
+
+	rows, cl, cc := layoutInput(m.input, m.cursor, m.width-1)
+	top := max(0, cl-(composeHeight-2))
+	for i := top; i < top+composeHeight-1; i++ {
+		if i >= len(rows) {
+			lines = append(lines, strings.Repeat(" ", m.width))
+			continue
+		}
+		if i == cl {
+			lines = append(lines, m.cursorLine(rows[i], cc, m.width))
+		} else {
+			lines = append(lines, Pad(string(rows[i]), m.width))
+		}
+	}
+	return lines
+}
+
+func (m *Model) statusLine() string {
+	switch {
+	case m.mode == modePrompt:
+		label := promptLabels[m.prompt] + ": "
+		rows, cl, cc := layoutInput(m.input, m.cursor, 1<<30)
+		avail := m.width - StringWidth(label)
+		row := rows[cl]
+		// Keep the cursor on screen for long input.
+		for StringWidth(string(row[:cc])) >= avail-1 && cc > 0 {
+			row, cc = row[1:], cc-1
+		}
+		return m.style(label, styleBold) + m.cursorLine(row, cc, avail)
+	case m.notice != "":
+		code := ""
+		if m.noticeErr {
+			code = styleRed
+		}
+		return m.style(Pad(" "+m.notice, m.width), code)
+	}
+	return m.style(Pad(" "+normalHints, m.width), styleDim)
+}
+
+// cursorLine renders row with a reverse-video cursor at rune index cc,
+// padded to width cells.
+func (m *Model) cursorLine(row []rune, cc, width int) string {
+	at := " "
+	var after []rune
+	if cc < len(row) {
+		at, after = string(row[cc]), row[cc+1:]
+	}
+	before := string(row[:cc])
+	used := StringWidth(before) + StringWidth(at)
+	rest := Pad(string(after), max(0, width-used))
+	return before + styleReverse + at + styleReset + rest
+}
+
+// layoutInput hard-wraps input to width cells, breaking at newlines, and
+// returns the rows with the cursor's row and rune index in that row.
+func layoutInput(input []rune, cursor, width int) (rows [][]rune, cl, cc int) {
+	var row []rune
+	used := 0
+	for i, r := range input {
+		if i == cursor {
+			cl, cc = len(rows), len(row)
+		}
+		if r == '\n' {
+			rows = append(rows, row)
+			row, used = nil, 0
+			continue
+		}
+		w := RuneWidth(r)
+		if used+w > width && len(row) > 0 {
+			if i == cursor {
+				cl, cc = len(rows)+1, 0
+			}
+			rows = append(rows, row)
+			row, used = nil, 0
+		}
+		row = append(row, r)
+		used += w
+	}
+	if cursor >= len(input) {
+		cl, cc = len(rows), len(row)
+	}
+	return append(rows, row), cl, cc
+}
+
+func contactName(c api.Conversation) string {
+	if sender, ok := c.Meta["sender"].(map[string]any); ok {
+		if name, ok := sender["name"].(string); ok && strings.TrimSpace(name) != "" {
+			return Sanitize(name)
+		}
+	}
+	if c.ContactID > 0 {
+		return fmt.Sprintf("Contact %d", c.ContactID)
+	}
+	return "Unknown contact"
+}
+
+func conversationTitle(c api.Conversation) string {
+	parts := []string{fmt.Sprintf("#%d %s", c.ID, contactName(c)), c.Status}
+	if c.AssigneeID != nil {
+		parts = append(parts, fmt.Sprintf("agent %d", *c.AssigneeID))
+	} else {
+		parts = append(parts, "unassigned")
+	}
+	if len(c.Labels) > 0 {
+		parts = append(parts, Sanitize(strings.Join(c.Labels, ", ")))
+	}
+	return strings.Join(parts, " · ")
+}
+
+func (m *Model) formatClock(t time.Time) string {
+	now := m.now()
+	if t.YearDay() == now.YearDay() && t.Year() == now.Year() {
+		return t.Format("15:04")
+	}
+	return t.Format("Jan 2 15:04")
+}
+
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
@@ -0,0 +1,154 @@
+package tui
+
+import (
+	"strings"
+	"unicode"
+)
+
+// wideRanges are East Asian wide and fullwidth blocks, plus emoji, which
+// terminals draw two cells wide.
+var wideRanges = [][2]rune{
+	{0x1100, 0x115F},   // Hangul Jamo
+	{0x2E80, 0x303E},   // CJK radicals, punctuation
+	{0x3041, 0x33FF},   // Hiragana, Katakana, Bopomofo, CJK compatibility
+	{0x3400, 0x4DBF},   // CJK extension A
+	{0x4E00, 0x9FFF},   // CJK unified ideographs
+	{0xA000, 0xA4CF},   // Yi
+	{0xAC00, 0xD7A3},   // Hangul syllables
+	{0xF900, 0xFAFF},   // CJK compatibility ideographs
+	{0xFE30, 0xFE4F},   // CJK compatibility forms
+	{0xFF00, 0xFF60},   // Fullwidth forms
+	{0xFFE0, 0xFFE6},   // Fullwidth signs
+	{0x1F300, 0x1F64F}, // Pictographs, emoticons
+	{0x1F900, 0x1F9FF}, // Supplemental pictographs
+	{0x20000, 0x2FFFD}, // CJK extensions B-F
+	{0x30000, 0x3FFFD}, // CJK extension G
+}
+
+// RuneWidth returns the number of terminal cells r occupies: 0 for controls
+// and combining marks, 2 for wide characters such as 中 or 한, 1 otherwise.
+func RuneWidth(r rune) int {
+	switch {
+	case r < 0x20 || r == 0x7F:
+		return 0
+	case r < 0x300:
+		return 1
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		return 0
+	}
+	for _, rg := range wideRanges {
+		if r < rg[0] {
+			break
+		}
+		if r <= rg[1] {
+			return 2
+		}
+	}
+	return 1
+}
+
+// StringWidth returns the number of terminal cells s occupies.
+func StringWidth(s string) int {
+	n := 0
+	for _, r := range s {
+		n += RuneWidth(r)
+	}
+	return n
+}
+
+// Truncate shortens s to at most width cells, ending in "…" when cut. A wide
+// character is never split.
+func Truncate(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	if StringWidth(s) <= width {
+		return s
+	}
+	var b strings.Builder
+	used := 0
+	for _, r := range s {
+		w := RuneWidth(r)
+		if used+w > width-1 {
+			break
+		}
+		b.WriteRune(r)
+		used += w
+	}
+	b.WriteString("…")
+	return b.String()
+}
+
+// Pad truncates or right-pads s with spaces to exactly width cells.
+func Pad(s string, width int) string {
+	s = Truncate(s, width)
+	if gap := width - StringWidth(s); gap > 0 {
+		s += strings.Repeat(" ", gap)
+	}
+	return s
+}
+
+// Wrap breaks s into lines of at most width cells. Lines break at spaces
+// where possible; text without spaces, such as Chinese, breaks between
+// characters.
+func Wrap(s string, width int) []string {
+	if width <= 0 {
+		return nil
+	}
+	var lines []string
+	for _, para := range strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n") {
+		var (
+			line      []rune
+			used      int
+			lastSpace = -1
+		)
+		for _, r := range para {
+			if r == '\t' {
+				r = ' '
+			}
+			w := RuneWidth(r)
+			if used+w > width && len(line) > 0 {
+				if r == ' ' {
+					lines = append(lines, string(line))
+					line, used, lastSpace = nil, 0, -1
+					continue
+				}
+				if lastSpace > 0 {
+					lines = append(lines, string(line[:lastSpace]))
+					line = append([]rune(nil), line[lastSpace+1:]...)
+				} else {
+					lines = append(lines, string(line))
+					line = nil
+				}
+				used, lastSpace = 0, -1
+				for i, lr := range line {
+					used += RuneWidth(lr)
+					if lr == ' ' {
+						lastSpace = i
+					}
+				}
+			}
+			if r == ' ' {
+				lastSpace = len(line)
+			}
+			line = append(line, r)
+			used += w
+		}
+		lines = append(lines, string(line))
+	}
+	return lines
+}
+
+// Sanitize drops control characters other than newline and tab, so text
+// from conversations cannot move the cursor or restyle the screen.
+func Sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\t' {
+			return r
+		}
+		if r < 0x20 || (r >= 0x7F && r < 0xA0) {
+			return -1
+		}
+		return r
+	}, s)
+}
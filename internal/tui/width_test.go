@@ -0,0 +1,55 @@
+package tui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStringWidth(t *testing.T) {
+	tests := map[string]int{
+		"abc":      3,
+		"退款":       4,
+		"a中b":      4,
+		"é":       1, // combining accent
+		"한국어":      6,
+		"\x1b[31m": 4, // ESC is zero width, "[31m" is not
+	}
+	for s, want := range tests {
+		if got := StringWidth(s); got != want {
+			t.Errorf("StringWidth(%q) = %d, want %d", s, got, want)
+		}
+	}
+}
+
+func TestTruncateAndPad(t *testing.T) {
+	if got := Truncate("退款政策說明", 7); got != "退款政…" {
+		t.Fatalf("Truncate = %q", got)
+	}
+	if got := Pad("退款", 6); got != "退款  " {
+		t.Fatalf("Pad = %q", got)
+	}
+	if got := Pad("hello world", 5); got != "hell…" {
+		t.Fatalf("Pad truncating = %q", got)
+	}
+}
+
+func TestWrap(t *testing.T) {
+	if got := Wrap("the quick brown fox", 10); !reflect.DeepEqual(got, []string{"the quick", "brown fox"}) {
+		t.Fatalf("Wrap words = %q", got)
+	}
+	if got := Wrap("我想申請退款謝謝", 6); !reflect.DeepEqual(got, []string{"我想申", "請退款", "謝謝"}) {
+		t.Fatalf("Wrap CJK = %q", got)
+	}
+	if got := Wrap("line one\nline two", 20); !reflect.DeepEqual(got, []string{"line one", "line two"}) {
+		t.Fatalf("Wrap newline = %q", got)
+	}
+	if got := Wrap("supercalifragilistic", 8); !reflect.DeepEqual(got, []string{"supercal", "ifragili", "stic"}) {
+		t.Fatalf("Wrap long word = %q", got)
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	if got := Sanitize("hi\x1b[2Jthere\r\n\tok\u0085"); got != "hi[2Jthere\n\tok" {
+		t.Fatalf("Sanitize = %q", got)
+	}
+}